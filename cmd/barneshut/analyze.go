@@ -0,0 +1,68 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: The "analyze" command, which runs the analyses of nbody/analysis.go on saved snapshots.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// RunAnalyze is the entry point of the "analyze" command.
+// It loads every snapshot of a directory and prints the selected analyses without re-simulating.
+// Input:
+//   - args: command line arguments following "analyze".
+// Output:
+//   - None (results are printed to standard output).
+func RunAnalyze(args []string) {
+	flags := flag.NewFlagSet("analyze", flag.ExitOnError)
+	dir := flags.String("in", "snapshots", "directory containing snapshot files")
+	energy := flags.Bool("energy", false, "print kinetic, potential, and total energy")
+	profile := flags.Bool("profile", false, "print the radial mass profile around the center of mass")
+	bins := flags.Int("bins", 10, "number of radial bins for -profile and -tails")
+	groups := flags.Bool("groups", false, "run friends-of-friends group finding")
+	link := flags.Float64("link", 0.01, "linking length for -groups, as a fraction of the universe width")
+	minMembers := flags.Int("min-members", 10, "smallest group reported by -groups")
+	instability := flags.Bool("instability", false, "print the growth of ring and clump instabilities of a disk")
+	rings := flags.Int("rings", 10, "number of rings for -instability")
+	sectors := flags.Int("sectors", 16, "number of sectors per ring for -instability")
+	bar := flags.Bool("bar", false, "print the m=2 Fourier amplitude (bar strength) of the disk")
+	tails := flags.Bool("tails", false, "tag stars by galaxy of origin and print the fraction in tidal tails")
+	flags.Parse(args)
+
+	if !*energy && !*profile && !*groups && !*instability && !*bar && !*tails {
+		fmt.Println("Nothing to do: select at least one of -energy, -profile, -groups, -instability, -bar, -tails.")
+		os.Exit(1)
+	}
+
+	universes, generations, err := nbody.ReadSnapshotDir(*dir)
+	CheckConfig(err)
+	fmt.Println("Loaded", len(universes), "snapshots from", *dir)
+
+	if *energy {
+		nbody.PrintEnergy(universes, generations)
+	}
+
+	if *profile {
+		nbody.PrintRadialProfiles(universes, generations, *bins)
+	}
+
+	if *groups {
+		nbody.PrintGroups(universes, generations, *link, *minMembers)
+	}
+
+	if *instability {
+		nbody.PrintInstability(universes, generations, *rings, *sectors)
+	}
+
+	if *bar {
+		nbody.PrintBarStrength(universes, generations, *rings)
+	}
+
+	if *tails {
+		nbody.PrintTails(universes, generations, *bins)
+	}
+}
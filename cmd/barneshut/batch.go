@@ -0,0 +1,199 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Headless batch runs (option "-batch") with a JSON summary and distinct exit codes.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/simulation"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+// Exit codes of a run. Command line parse errors of the flag package also exit with 2.
+const (
+	exitFailure   = 1 // any other failure, such as a file that cannot be written
+	exitConfig    = 2 // invalid command line options or event file
+	exitNumerical = 3 // the simulation produced non-finite values
+
+	exitInterrupted = 130 // the run was stopped with Ctrl+C, as shells report a process killed by SIGINT
+)
+
+// batchMode is set by -batch.
+var batchMode = false
+
+// A ConfigError is an error in the command line options or the event file of a run.
+type ConfigError struct {
+	err error
+}
+
+func (e ConfigError) Error() string { return e.err.Error() }
+
+
+// CheckConfig stops the run if err, an error in the configuration, is not nil.
+// In batch mode it panics with a ConfigError, which BatchSummary.Finish turns into exit code exitConfig;
+// otherwise it prints the error and exits with exitConfig.
+func CheckConfig(err error) {
+	if err == nil {
+		return
+	}
+	if batchMode {
+		panic(ConfigError{err})
+	}
+	fmt.Println("Error:", err)
+	os.Exit(exitConfig)
+}
+
+
+// Conservation holds the drifts of conserved quantities between the first and the last generation of a run,
+// each relative to its natural scale: the energy to the initial total energy, the momentum to the sum of m|v|,
+// and the angular momentum to the sum of m|r x v| around the initial center of mass.
+// The potential energy is softened like the forces of the run.
+type Conservation struct {
+	Energy          float64 `json:"energy"`
+	Momentum        float64 `json:"momentum"`
+	AngularMomentum float64 `json:"angular_momentum"`
+}
+
+
+// MeasureConservation computes the Conservation of a run from its first and last universe.
+// Input:
+//   - first, last: pointers to the first and last Universe.
+//   - softening: softening length of the run.
+// Output:
+//   - the Conservation.
+func MeasureConservation(first, last *nbody.Universe, softening float64) Conservation {
+	var c Conservation
+
+	e0 := nbody.KineticEnergy(first) + nbody.SoftenedPotentialEnergy(first, softening)
+	e1 := nbody.KineticEnergy(last) + nbody.SoftenedPotentialEnergy(last, softening)
+	if e0 != 0 {
+		c.Energy = math.Abs((e1 - e0) / e0)
+	}
+
+	// the drifts of the momentum are relative to the scales of the first universe
+	center := nbody.CenterOfMass(first.Stars)
+	c.Momentum, c.AngularMomentum = simulation.MomentumDrift(simulation.MeasureMomentum(first, center), simulation.MeasureMomentum(last, center))
+
+	return c
+}
+
+
+// BatchSummary is the JSON summary printed at the end of a batch run.
+type BatchSummary struct {
+	Status         string             `json:"status"` // "ok", "interrupted", "config-error", "numerical-failure", or "failure"
+	ExitCode       int                `json:"exit_code"`
+	Error          string             `json:"error,omitempty"`
+	Scenario       string             `json:"scenario"`
+	Seed           int64              `json:"seed"`
+	NumStars       int                `json:"num_stars"`
+	Generations    int                `json:"generations"`
+	LastGeneration *int               `json:"last_generation,omitempty"` // set when the run was interrupted
+	Conservation   *Conservation      `json:"conservation,omitempty"`
+	Warnings       []string           `json:"warnings,omitempty"`
+	Runtimes       map[string]float64 `json:"runtimes_seconds"`
+	Outputs        []string           `json:"outputs"`
+
+	start time.Time
+	path  string // file receiving a copy of the summary, "" before the output paths are known
+}
+
+
+// NewBatchSummary starts the summary of a batch run of a scenario.
+func NewBatchSummary(scenario string) *BatchSummary {
+	return &BatchSummary{
+		Scenario: scenario,
+		Seed:     nbody.Seed,
+		Runtimes: make(map[string]float64),
+		Outputs:  []string{},
+		start:    time.Now(),
+	}
+}
+
+
+// Time adds the time since start to the runtime of a phase.
+func (s *BatchSummary) Time(phase string, start time.Time) {
+	s.Runtimes[phase] += time.Since(start).Seconds()
+}
+
+
+// Simulated records the outcome of the simulation from its first and last universe: it panics with a
+// NumericalError if the last universe has non-finite values, and otherwise measures the Conservation.
+// Input:
+//   - first, last: pointers to the first and last Universe of the run.
+//   - options: the Options of the run.
+// Output:
+//   - None (the summary is updated).
+func (s *BatchSummary) Simulated(first, last *nbody.Universe, options simulation.Options) {
+	if err := nbody.CheckFinite(last, options.NumGens); err != nil {
+		panic(err)
+	}
+	c := MeasureConservation(first, last, options.Softening)
+	s.Conservation = &c
+}
+
+
+// Wrote adds output files or directories to the summary, skipping empty names.
+func (s *BatchSummary) Wrote(paths ...string) {
+	for _, path := range paths {
+		if path != "" {
+			s.Outputs = append(s.Outputs, path)
+		}
+	}
+}
+
+
+// Interrupted records that the run was stopped with Ctrl+C after a generation; its outputs cover the
+// generations until then, and Finish reports the status "interrupted".
+func (s *BatchSummary) Interrupted(generation int) {
+	s.LastGeneration = &generation
+}
+
+
+// Finish completes the summary with the outcome of the run, writes it next to the other outputs, and prints it
+// to standard output. recovered is the value recovered from a panic of the run, or nil if it succeeded.
+// Output:
+//   - the exit code of the run.
+func (s *BatchSummary) Finish(recovered any) int {
+	s.Status, s.ExitCode = "ok", 0
+	if s.LastGeneration != nil {
+		s.Status, s.ExitCode = "interrupted", exitInterrupted
+	}
+	if recovered != nil {
+		err, ok := recovered.(error)
+		if !ok {
+			err = fmt.Errorf("%v", recovered)
+		}
+		s.Error = err.Error()
+
+		var configErr ConfigError
+		var numericalErr nbody.NumericalError
+		switch {
+		case errors.As(err, &configErr):
+			s.Status, s.ExitCode = "config-error", exitConfig
+		case errors.As(err, &numericalErr):
+			s.Status, s.ExitCode = "numerical-failure", exitNumerical
+		default:
+			s.Status, s.ExitCode = "failure", exitFailure
+		}
+	}
+	s.Runtimes["total"] = time.Since(s.start).Seconds()
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	nbody.Check(err)
+	if s.path != "" {
+		if err := os.WriteFile(s.path, append(data, '\n'), 0644); err != nil && s.ExitCode == 0 {
+			s.Status, s.ExitCode, s.Error = "failure", exitFailure, err.Error()
+			data, err = json.MarshalIndent(s, "", "  ")
+			nbody.Check(err)
+		}
+	}
+	fmt.Println(string(data))
+
+	return s.ExitCode
+}
@@ -0,0 +1,94 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for batch runs in batch.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type CheckFiniteTestCases struct {
+	id       string
+	universe *nbody.Universe
+	expected int
+}
+
+
+// ReadCheckFinite reads test data for the CheckFinite function from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of CheckFiniteTestCases structs containing the universe and the index of the first bad star.
+func ReadCheckFinite(fileName string) []CheckFiniteTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []CheckFiniteTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 3 {
+			continue
+		}
+
+		u := &nbody.Universe{Width: 1e23}
+		for _, star := range strings.Split(parts[1], ";") {
+			var v [4]float64
+			for i, field := range strings.Split(star, ",") {
+				v[i], err = strconv.ParseFloat(strings.TrimSpace(field), 64)
+				nbody.Check(err)
+			}
+			u.Stars = append(u.Stars, nbody.NewStar(nbody.OrderedPair{X: v[0], Y: v[1]}, nbody.OrderedPair{X: v[2], Y: v[3]}, 1, 1))
+		}
+
+		expected, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+		nbody.Check(err)
+
+		tests = append(tests, CheckFiniteTestCases{
+			id:       strings.TrimSpace(parts[0]),
+			universe: u,
+			expected: expected,
+		})
+	}
+
+	return tests
+}
+
+
+// TestCheckFinite tests that CheckFinite reports the first star with a non-finite value as a NumericalError.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestCheckFinite(t *testing.T) {
+	tests := ReadCheckFinite("../../Tests/CheckFinite.txt")
+
+	for _, test := range tests {
+		err := nbody.CheckFinite(test.universe, 7)
+
+		if test.expected < 0 {
+			if err != nil {
+				t.Errorf("TestCheckFinite(test %v) = %v, want nil", test.id, err)
+			}
+			continue
+		}
+
+		var numericalErr nbody.NumericalError
+		want := fmt.Sprintf("generation 7: star %d ", test.expected)
+		if !errors.As(err, &numericalErr) || !strings.HasPrefix(err.Error(), want) {
+			t.Errorf("TestCheckFinite(test %v) = %v, want a NumericalError starting with %q", test.id, err, want)
+		}
+	}
+}
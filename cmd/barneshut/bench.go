@@ -0,0 +1,210 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: The "bench" command, which times steps over a grid of star counts and theta values
+// and compares them with direct summation.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"BarnesHut/simulation"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A BenchResult is the mean time of one step for a number of stars, by theta.
+type BenchResult struct {
+	numStars int
+	direct   time.Duration             // direct summation
+	tree     map[float64]time.Duration // Barnes-Hut (tree build, force, and integration) for every theta
+}
+
+
+// RunBench is the entry point of the "bench" command.
+// It times a few steps of a galaxy for every number of stars and theta, and of direct summation,
+// and prints the step times, the speedups, and optionally an estimate for a larger target size.
+// Input:
+//   - args: command line arguments following "bench".
+// Output:
+//   - None (the table is printed).
+func RunBench(args []string) {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	sizesText := flags.String("n", "1000,2000,4000,8000", "comma-separated numbers of stars")
+	thetasText := flags.String("theta", "0.3,0.5,0.7,1.0", "comma-separated values of theta")
+	steps := flags.Int("steps", 3, "number of timed steps for every cell of the table")
+	target := flags.Int("target", 0, "also estimate the step time for this number of stars (0 for none)")
+	flags.IntVar(&nbody.NumWorkers, "workers", nbody.NumWorkers, "number of goroutines used for forces and parallel tree builds")
+	treeBuild := flags.String("tree-build", "serial", "quadtree construction: serial, partitioned, flat, or linear")
+	leafSize := flags.Int("leaf-size", 1, "largest number of stars in a leaf of the quadtree")
+	treeWalk := flags.String("tree-walk", "stack", "walk of the quadtree for the forces: stack or recursive")
+	mac := flags.String("mac", "bh", "opening criterion of the force walk: bh (s/d < theta), bmax, or salmon-warren")
+	macTolerance := flags.Float64("mac-tolerance", 0, "acceleration error in m/s^2 accepted per node by -mac salmon-warren")
+	flags.Parse(args)
+
+	sizes, err := ParseIntList(*sizesText)
+	nbody.Check(err)
+	thetas, err := ParseFloatList(*thetasText)
+	nbody.Check(err)
+	if *steps < 1 {
+		nbody.Check(fmt.Errorf("number of steps must be at least 1, got %d", *steps))
+	}
+	options := simulation.DefaultOptions(*steps, 2e14, 0).WithTreeBuild(*treeBuild).WithLeafSize(*leafSize).WithTreeWalk(*treeWalk).
+		WithMAC(*mac, *macTolerance)
+	nbody.Check(options.Validate())
+
+	fmt.Printf("Timing %d steps for every number of stars and theta on %d workers (tree build %s, leaf size %d, %s walk).\n",
+		*steps, nbody.NumWorkers, options.TreeBuild, options.LeafSize, options.TreeWalk)
+
+	results := make([]BenchResult, len(sizes))
+	for i, n := range sizes {
+		results[i] = BenchSize(n, thetas, options)
+	}
+
+	PrintBench(os.Stdout, results, thetas, *target)
+}
+
+
+// ParseIntList parses a comma-separated list of positive integers such as "1000,2000".
+func ParseIntList(list string) ([]int, error) {
+	var values []int
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		v, err := strconv.Atoi(field)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("invalid positive integer %q in list %q", field, list)
+		}
+		values = append(values, v)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("empty list %q", list)
+	}
+	return values, nil
+}
+
+
+// ParseFloatList parses a comma-separated list of non-negative numbers such as "0.3,0.5".
+func ParseFloatList(list string) ([]float64, error) {
+	var values []float64
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid non-negative number %q in list %q", field, list)
+		}
+		values = append(values, v)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("empty list %q", list)
+	}
+	return values, nil
+}
+
+
+// BenchSize times steps of a galaxy of numStars stars, with direct summation and with the tree for every theta.
+// Every timing starts from the same initial universe.
+// Input:
+//   - numStars: number of stars.
+//   - thetas: values of theta.
+//   - options: the Options of the timed steps, whose numGens is the number of timed steps.
+// Output:
+//   - the BenchResult with the mean time of one step.
+func BenchSize(numStars int, thetas []float64, options simulation.Options) BenchResult {
+	g := nbody.InitializeGalaxy(numStars, 4e21, 5e22, 5e22)
+	initial := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+	result := BenchResult{numStars: numStars, tree: make(map[float64]time.Duration)}
+
+	steps := options.NumGens
+	u := initial
+	start := time.Now()
+	for i := 0; i < steps; i++ {
+		u = simulation.IntegrateUniverse(u, simulation.DirectAccelerations(u, options), options)
+	}
+	result.direct = time.Since(start) / time.Duration(steps)
+
+	for _, theta := range thetas {
+		options = options.WithTheta(theta)
+		u = initial
+		start = time.Now()
+		for i := 0; i < steps; i++ {
+			var err error
+			u, err = simulation.UpdateUniverse(u, quadtree.BuildQuadTree(u, options.Build()), options)
+			nbody.Check(err)
+		}
+		result.tree[theta] = time.Since(start) / time.Duration(steps)
+	}
+
+	return result
+}
+
+
+// PrintBench prints one line per number of stars with the step time of direct summation and of the tree
+// for every theta, the tree times followed by their speedup over direct summation.
+// If target is positive, a last line estimates the step times for target stars, extrapolating the
+// power law N^p through the two largest measured sizes.
+// Input:
+//   - w: writer receiving the table.
+//   - results: BenchResult of every number of stars.
+//   - thetas: values of theta, in the order of the columns.
+//   - target: number of stars to estimate (0 for none).
+// Output:
+//   - None.
+func PrintBench(w io.Writer, results []BenchResult, thetas []float64, target int) {
+	fmt.Fprintf(w, "%8s %12s", "N", "direct")
+	for _, theta := range thetas {
+		fmt.Fprintf(w, " %20s", fmt.Sprintf("theta=%g", theta))
+	}
+	fmt.Fprintln(w)
+
+	for _, r := range results {
+		fmt.Fprintf(w, "%8d %12v", r.numStars, r.direct.Round(time.Microsecond))
+		for _, theta := range thetas {
+			t := r.tree[theta]
+			fmt.Fprintf(w, " %20s", fmt.Sprintf("%v (%.1fx)", t.Round(time.Microsecond), r.direct.Seconds()/t.Seconds()))
+		}
+		fmt.Fprintln(w)
+	}
+
+	if target <= 0 {
+		return
+	}
+	if len(results) < 2 {
+		fmt.Fprintln(w, "Estimating a target size needs at least two numbers of stars.")
+		return
+	}
+
+	sorted := append([]BenchResult(nil), results...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].numStars < sorted[j].numStars })
+	a, b := sorted[len(sorted)-2], sorted[len(sorted)-1]
+
+	fmt.Fprintf(w, "%8s %12s", fmt.Sprintf("~%d", target), fmt.Sprintf("%.3gs", Extrapolate(a.numStars, a.direct, b.numStars, b.direct, target)))
+	for _, theta := range thetas {
+		t := Extrapolate(a.numStars, a.tree[theta], b.numStars, b.tree[theta], target)
+		fmt.Fprintf(w, " %20s", fmt.Sprintf("%.3gs", t))
+	}
+	fmt.Fprintln(w)
+}
+
+
+// Extrapolate estimates the time in seconds for n stars from the times t1 and t2 measured for n1 and n2 stars,
+// assuming the time grows like a power of the number of stars.
+func Extrapolate(n1 int, t1 time.Duration, n2 int, t2 time.Duration, n int) float64 {
+	if n1 == n2 || t1 <= 0 || t2 <= 0 {
+		return t2.Seconds() * float64(n) / float64(n2)
+	}
+	p := math.Log(t2.Seconds()/t1.Seconds()) / math.Log(float64(n2)/float64(n1))
+	return t2.Seconds() * math.Pow(float64(n)/float64(n2), p)
+}
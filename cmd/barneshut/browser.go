@@ -0,0 +1,95 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Running the simulation in a web page: the players of the WebAssembly build (see wasm.go).
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/scenarios"
+	"fmt"
+	"image"
+	"image/draw"
+	"strings"
+)
+
+// BrowserScenarios are the built-in scenarios a web page can run. The jupiter scenario reads its moons from
+// Data/jupiterMoons.txt, which a page does not have.
+var BrowserScenarios = []string{"galaxy", "collision", "disk", "cluster", "solar", "kepler"}
+
+
+// NewBrowserPlayer returns a LivePlayer of a built-in scenario or of a configuration file, which a web page
+// advances and draws frame by frame. It reads no file and no command line.
+// Input:
+//   - name: the scenario, one of BrowserScenarios, run if config is "".
+//   - config: the text of a configuration file (see ScenarioFromConfig), or "".
+//   - seed: seed of the random number generator, so the same seed gives the same run.
+//   - canvasWidth: width of the frames in pixels (0 for the scenario's).
+//   - speed: generations advanced per frame (0 for the scenario's frequency).
+// Output:
+//   - pointer to the LivePlayer, or an error if the scenario or the configuration is invalid.
+func NewBrowserPlayer(name, config string, seed int64, canvasWidth, speed int) (player *LivePlayer, err error) {
+	// invalid settings deep in a scenario stop it with a panic (see CheckConfig)
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			player, err = nil, fmt.Errorf("%v", recovered)
+		}
+	}()
+
+	nbody.SetSeed(seed)
+	var scenario scenarios.Scenario
+	if config != "" {
+		doc, err := scenarios.ParseTOML(strings.NewReader(config))
+		if err != nil {
+			return nil, fmt.Errorf("configuration: %w", err)
+		}
+		if scenario, err = scenarios.ScenarioFromConfig(doc); err != nil {
+			return nil, fmt.Errorf("configuration: %w", err)
+		}
+	} else {
+		known := false
+		for _, s := range BrowserScenarios {
+			known = known || s == name
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown scenario %q (use %s)", name, strings.Join(BrowserScenarios, ", "))
+		}
+		if scenario, err = scenarios.LoadScenario(name); err != nil {
+			return nil, err
+		}
+	}
+
+	if canvasWidth == 0 {
+		canvasWidth = scenario.CanvasWidth
+	}
+	if speed == 0 {
+		speed = min(scenario.Frequency, liveMaxSpeed)
+	}
+	if canvasWidth < 1 {
+		return nil, fmt.Errorf("canvas width must be positive, got %d", canvasWidth)
+	}
+	return NewLivePlayer(scenario.InitialUniverse, scenario.Options().WithEventLog(nbody.StatusOutput), canvasWidth, scenario.ScalingFactor, speed)
+}
+
+
+// RGBA returns the frame of the current generation as RGBA pixels, as a page copies them into its canvas.
+func (player *LivePlayer) RGBA() *image.RGBA {
+	if rgba, ok := player.frame.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+	bounds := player.frame.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Rect, player.frame, bounds.Min, draw.Src)
+	return rgba
+}
+
+
+// Positions returns the x and y coordinates of every star of the current generation, one after the other.
+func (player *LivePlayer) Positions() []float64 {
+	stars := player.sim.Universe.Stars
+	positions := make([]float64, 0, 2*len(stars))
+	for _, s := range stars {
+		positions = append(positions, s.Position.X, s.Position.Y)
+	}
+	return positions
+}
@@ -0,0 +1,124 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the players of the WebAssembly build in browser.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type NewBrowserPlayerTestCases struct {
+	id          string
+	scenario    string
+	config      string
+	canvasWidth int
+	speed       int
+	isError     bool
+	numStars    int
+	frameWidth  int
+	frameHeight int
+	playerSpeed int
+}
+
+
+// ReadNewBrowserPlayer reads test data for NewBrowserPlayer from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of NewBrowserPlayerTestCases structs containing the scenarios and the expected players.
+func ReadNewBrowserPlayer(fileName string) []NewBrowserPlayerTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []NewBrowserPlayerTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 6 {
+			continue
+		}
+
+		test := NewBrowserPlayerTestCases{id: strings.TrimSpace(parts[0]), scenario: strings.TrimSpace(parts[1])}
+		if config := strings.TrimSpace(parts[2]); config != "-" {
+			test.config = strings.Join(strings.Split(config, ";"), "\n")
+		}
+		test.canvasWidth, err = strconv.Atoi(strings.TrimSpace(parts[3]))
+		nbody.Check(err)
+		test.speed, err = strconv.Atoi(strings.TrimSpace(parts[4]))
+		nbody.Check(err)
+
+		expected := strings.TrimSpace(parts[5])
+		if expected == "error" {
+			test.isError = true
+		} else {
+			fields := strings.Split(expected, ",")
+			if len(fields) != 4 {
+				continue
+			}
+			values := make([]int, len(fields))
+			for i, field := range fields {
+				values[i], err = strconv.Atoi(strings.TrimSpace(field))
+				nbody.Check(err)
+			}
+			test.numStars, test.frameWidth, test.frameHeight, test.playerSpeed = values[0], values[1], values[2], values[3]
+		}
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestNewBrowserPlayer tests the players started by a web page: their stars, frames, and speed, that invalid
+// scenarios and configurations are reported as errors, and that a seed repeats a run.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestNewBrowserPlayer(t *testing.T) {
+	tests := ReadNewBrowserPlayer("../../Tests/NewBrowserPlayer.txt")
+	oldBatch := batchMode
+	batchMode = true
+	defer func() { batchMode = oldBatch }()
+
+	for _, test := range tests {
+		player, err := NewBrowserPlayer(test.scenario, test.config, 7, test.canvasWidth, test.speed)
+		if test.isError {
+			if err == nil {
+				t.Errorf("TestNewBrowserPlayer(test %v) returned no error, want one", test.id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestNewBrowserPlayer(test %v) failed: %v", test.id, err)
+			continue
+		}
+
+		rgba := player.RGBA()
+		positions := player.Positions()
+		if len(positions) != 2*test.numStars || rgba.Rect.Dx() != test.frameWidth || rgba.Rect.Dy() != test.frameHeight || player.speed != test.playerSpeed {
+			t.Errorf("TestNewBrowserPlayer(test %v) = %d stars, %dx%d frames, speed %d, want %d, %dx%d, %d", test.id,
+				len(positions)/2, rgba.Rect.Dx(), rgba.Rect.Dy(), player.speed, test.numStars, test.frameWidth, test.frameHeight, test.playerSpeed)
+		}
+
+		player.StepOnce()
+		again, err := NewBrowserPlayer(test.scenario, test.config, 7, test.canvasWidth, test.speed)
+		nbody.Check(err)
+		again.StepOnce()
+		if !reflect.DeepEqual(again.Positions(), player.Positions()) {
+			t.Errorf("TestNewBrowserPlayer(test %v) differs in a second run with the same seed", test.id)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: The "compare" command, which measures the error of the tree forces against direct summation.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"BarnesHut/scenarios"
+	"BarnesHut/simulation"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// RunCompare is the entry point of the "compare" command.
+// It loads a scenario, a configuration file, or an initial universe, optionally runs it for some generations so
+// the stars are no longer in their initial arrangement, and prints the maximum and mean relative force error of
+// the tree and its speedup over direct summation for every theta, and the theta it recommends for an error.
+// Input:
+//   - args: command line arguments following "compare".
+// Output:
+//   - None (the table is printed).
+func RunCompare(args []string) {
+	command, named := "galaxy", false
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		command, named = args[0], true
+		args = args[1:]
+	}
+
+	flags := flag.NewFlagSet("compare", flag.ExitOnError)
+	thetasText := flags.String("theta", "0.1,0.3,0.5,0.7,1.0", "comma-separated values of theta")
+	numGens := flags.Int("gens", 0, "generations to run the scenario (with its own theta) before comparing")
+	softening := flags.Float64("softening", 0, "Plummer softening length in meters (0 for none)")
+	forceLawName := flags.String("force-law", "newton", "law of the force between stars: newton, yukawa:alpha,lambda (range lambda in m), mond[:a0] (MOND-like, a0 in m/s^2), or repulsive")
+	initialFile := flags.String("initial", "", "compare the forces of this universe (.csv, .json, or .tipsy) instead of the scenario's")
+	configFile := flags.String("config", "", "compare the forces of the scenario of this TOML file")
+	maxError := flags.Float64("max-error", 0, "recommend the largest theta whose mean relative error is at most this (0 for none)")
+	flags.IntVar(&nbody.NumWorkers, "workers", nbody.NumWorkers, "number of goroutines used for forces and parallel tree builds")
+	treeBuild := flags.String("tree-build", "serial", "quadtree construction: serial, partitioned, flat, or linear")
+	mac := flags.String("mac", "bh", "opening criterion of the force walk: bh (s/d < theta), bmax, or salmon-warren")
+	macTolerance := flags.Float64("mac-tolerance", 0, "acceleration error in m/s^2 accepted per node by -mac salmon-warren")
+	flags.Parse(args)
+
+	thetas, err := ParseFloatList(*thetasText)
+	nbody.Check(err)
+	law, err := nbody.ParseForceLaw(*forceLawName)
+	nbody.Check(err)
+	if *numGens < 0 || *softening < 0 || *maxError < 0 {
+		nbody.Check(fmt.Errorf("number of generations, softening, and maximum error must not be negative"))
+	}
+
+	var scenario scenarios.Scenario
+	if *configFile != "" {
+		if named {
+			nbody.Check(fmt.Errorf("give either a scenario command or -config, not both"))
+		}
+		scenario, err = scenarios.LoadConfig(*configFile)
+		nbody.Check(err)
+		command = scenarios.ConfigName(*configFile)
+	} else {
+		scenario, err = scenarios.LoadScenario(command)
+		CheckConfig(err)
+	}
+	if *initialFile != "" {
+		scenario.InitialUniverse, err = nbody.LoadInitialUniverse(*initialFile)
+		nbody.Check(err)
+		scenario.Halos = nil
+		command = *initialFile
+	}
+
+	u := scenario.InitialUniverse
+	options := scenario.Options()
+	options.NumGens = *numGens
+	options = options.WithSoftening(*softening).WithForceLaw(law).WithTreeBuild(*treeBuild).WithMAC(*mac, *macTolerance)
+	nbody.Check(options.Validate())
+	for i := 0; i < *numGens; i++ {
+		u, err = simulation.UpdateUniverse(u, quadtree.BuildQuadTree(u, options.Build()), options)
+		nbody.Check(err)
+	}
+
+	fmt.Printf("Comparing the tree forces of %d stars of %q after %d generations with direct summation (opening criterion %s).\n",
+		len(u.Stars), command, *numGens, options.MAC)
+
+	start := time.Now()
+	direct := simulation.DirectAccelerations(u, options)
+	directTime := time.Since(start)
+
+	results := make([]simulation.ForceErrors, len(thetas))
+	for i, theta := range thetas {
+		results[i] = simulation.CompareForces(u, direct, options.WithTheta(theta))
+	}
+
+	simulation.PrintForceErrors(os.Stdout, results, directTime)
+	if *maxError > 0 {
+		if best, ok := simulation.PickTheta(results, *maxError); ok {
+			fmt.Printf("Largest theta with a mean error of at most %g: %g (mean error %.3e, %.1fx faster than direct summation)\n",
+				*maxError, best.Theta, best.Mean, simulation.Speedup(directTime, best.TreeTime))
+		} else {
+			fmt.Printf("No theta has a mean error of at most %g; try smaller values.\n", *maxError)
+		}
+	}
+}
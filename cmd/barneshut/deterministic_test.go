@@ -0,0 +1,141 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for deterministic snapshots of whole runs (see nbody/deterministic.go).
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/render"
+	"BarnesHut/scenarios"
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestMain runs every test of the package in deterministic mode, so results are stable between runs.
+func TestMain(m *testing.M) {
+	nbody.SetDeterministic()
+	os.Exit(m.Run())
+}
+
+type DeterministicSnapshotsTestCases struct {
+	id        string
+	command   string
+	seed      int64
+	numGens   int
+	frequency int
+	treeBuild string
+	forceMode string
+	workers   [2]int
+}
+
+
+// ReadDeterministicSnapshots reads test data for runs compared through their snapshots from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of DeterministicSnapshotsTestCases structs containing the scenarios, run settings, and workers.
+func ReadDeterministicSnapshots(fileName string) []DeterministicSnapshotsTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []DeterministicSnapshotsTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 7 {
+			continue
+		}
+
+		var test DeterministicSnapshotsTestCases
+		test.id = strings.TrimSpace(parts[0])
+		test.command = strings.TrimSpace(parts[1])
+		test.seed, err = strconv.ParseInt(strings.TrimSpace(parts[2]), 10, 64)
+		nbody.Check(err)
+		test.numGens, err = strconv.Atoi(strings.TrimSpace(parts[3]))
+		nbody.Check(err)
+		test.frequency, err = strconv.Atoi(strings.TrimSpace(parts[4]))
+		nbody.Check(err)
+		modes := strings.Fields(parts[5])
+		test.treeBuild, test.forceMode = modes[0], modes[1]
+		for i, field := range strings.Fields(parts[6]) {
+			test.workers[i], err = strconv.Atoi(field)
+			nbody.Check(err)
+		}
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// SeededSnapshots generates a scenario from a seed, simulates it with a number of workers, and saves the JSON
+// snapshots of every frequency-th generation into dir.
+func SeededSnapshots(test DeterministicSnapshotsTestCases, workers int, dir string) {
+	oldWorkers := nbody.NumWorkers
+	defer func() {
+		nbody.NumWorkers = oldWorkers
+	}()
+	nbody.NumWorkers = workers
+
+	nbody.SetSeed(test.seed)
+	scenario, err := scenarios.LoadScenario(test.command)
+	nbody.Check(err)
+	scenario.NumGens = test.numGens
+	selector := &render.FrameSelector{Frequency: test.frequency, FinalGeneration: test.numGens}
+	options := scenario.Options().WithTreeBuild(test.treeBuild).WithForceMode(test.forceMode)
+	SimulateSnapshots(scenario.InitialUniverse, options, selector, dir, nil)
+}
+
+
+// TestDeterministicSnapshots checks that a scenario run twice from the same seed, with different numbers of
+// workers, writes identical snapshot files: the parallel tree builds and forces split the stars into fixed chunks
+// and combine their results in order.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if two snapshot files differ.
+func TestDeterministicSnapshots(t *testing.T) {
+	tests := ReadDeterministicSnapshots("../../Tests/DeterministicSnapshots.txt")
+
+	oldFormat, oldStatus := snapshotFormat, nbody.StatusOutput
+	snapshotFormat, nbody.StatusOutput = "json", io.Discard
+	defer func() {
+		snapshotFormat, nbody.StatusOutput = oldFormat, oldStatus
+		nbody.SetDeterministic()
+	}()
+
+	for _, test := range tests {
+		var dirs [2]string
+		for k, workers := range test.workers {
+			dirs[k] = t.TempDir()
+			SeededSnapshots(test, workers, dirs[k])
+		}
+
+		want, err := filepath.Glob(filepath.Join(dirs[0], "snapshot_*"))
+		nbody.Check(err)
+		if len(want) != test.numGens/test.frequency+1 {
+			t.Errorf("TestDeterministicSnapshots(test %v) saved %d snapshots, want %d", test.id, len(want), test.numGens/test.frequency+1)
+		}
+		for _, name := range want {
+			first, err := os.ReadFile(name)
+			nbody.Check(err)
+			second, err := os.ReadFile(filepath.Join(dirs[1], filepath.Base(name)))
+			if err != nil || !bytes.Equal(first, second) {
+				t.Errorf("TestDeterministicSnapshots(test %v): %s differs between %d and %d workers", test.id, filepath.Base(name), test.workers[0], test.workers[1])
+				break
+			}
+		}
+	}
+}
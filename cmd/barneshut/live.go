@@ -0,0 +1,161 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Watching a simulation in a desktop window while it runs (option "-live").
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/render"
+	"BarnesHut/simulation"
+	"fmt"
+	"image"
+)
+
+// liveWindow opens the desktop window of -live and shows the frames of a LivePlayer until the window is closed.
+// It is set by live_window.go, which is only compiled with the "live" build tag because it needs the ebiten
+// game library and a display; without the tag it is nil and -live is refused (see CheckLive).
+var liveWindow func(player *LivePlayer) error
+
+// liveMaxSpeed is the largest number of generations a LivePlayer advances per frame.
+const liveMaxSpeed = 1 << 12
+
+
+// CheckLive returns an error if -live is given but this program was built without a live window.
+func CheckLive(live bool) error {
+	if live && liveWindow == nil {
+		return fmt.Errorf("-live needs the window of the live build: go get github.com/hajimehoshi/ebiten/v2, then go build -tags live ./cmd/barneshut")
+	}
+	return nil
+}
+
+
+// A LivePlayer runs a Simulation for a window: every frame of the window advances it by speed generations and
+// draws the new universe, unless it is paused or the run has reached generation numGens. The generations are
+// advanced in place, since only the current one is drawn.
+type LivePlayer struct {
+	sim           *simulation.Simulation
+	numGens       int // the generation the run stops at
+	speed         int // generations advanced per frame
+	paused        bool
+	canvasWidth   int
+	scalingFactor float64
+	frame         image.Image // the drawing of the current generation
+	err           error       // the error that stopped the run, if any
+}
+
+
+// NewLivePlayer returns a LivePlayer of a copy of a universe, showing its first generation.
+// Input:
+//   - initialUniverse: pointer to the initial Universe (not modified).
+//   - options: the Options of the run; the run stops at generation options.NumGens.
+//   - canvasWidth, scalingFactor: drawing parameters as for DrawToCanvas.
+//   - speed: number of generations advanced per frame, at least 1.
+// Output:
+//   - pointer to the LivePlayer, or an error if the Options are invalid.
+func NewLivePlayer(initialUniverse *nbody.Universe, options simulation.Options, canvasWidth int, scalingFactor float64, speed int) (*LivePlayer, error) {
+	if speed < 1 || speed > liveMaxSpeed {
+		return nil, fmt.Errorf("live speed must be between 1 and %d generations per frame, got %d", liveMaxSpeed, speed)
+	}
+	options.Retain = func(generation int) bool {
+		return false
+	}
+	sim, err := simulation.NewSimulation(initialUniverse, options)
+	if err != nil {
+		return nil, err
+	}
+
+	player := &LivePlayer{
+		sim:           sim,
+		numGens:       options.NumGens,
+		speed:         speed,
+		canvasWidth:   canvasWidth,
+		scalingFactor: scalingFactor,
+	}
+	player.draw()
+	return player, nil
+}
+
+
+// draw draws the current generation.
+func (player *LivePlayer) draw() {
+	player.frame = render.DrawToCanvas(player.sim.Universe, player.canvasWidth, player.scalingFactor)
+}
+
+
+// Advance advances the simulation by speed generations, fewer at the end of the run, and draws the last one.
+// It does nothing while the player is paused or done.
+func (player *LivePlayer) Advance() {
+	if player.paused {
+		return
+	}
+	player.advance(player.speed)
+}
+
+
+// StepOnce advances the simulation by one generation, also while the player is paused.
+func (player *LivePlayer) StepOnce() {
+	player.advance(1)
+}
+
+
+// advance advances the simulation by at most n generations and draws the last one.
+func (player *LivePlayer) advance(n int) {
+	if player.Done() {
+		return
+	}
+	n = min(n, player.numGens-player.sim.Generation())
+	player.err = player.sim.Run(n)
+	player.draw()
+}
+
+
+// TogglePause pauses a running player and resumes a paused one.
+func (player *LivePlayer) TogglePause() {
+	player.paused = !player.paused
+}
+
+
+// Faster doubles the generations advanced per frame, up to liveMaxSpeed.
+func (player *LivePlayer) Faster() {
+	player.speed = min(2*player.speed, liveMaxSpeed)
+}
+
+
+// Slower halves the generations advanced per frame, down to one.
+func (player *LivePlayer) Slower() {
+	player.speed = max(player.speed/2, 1)
+}
+
+
+// Done reports whether the run has reached its last generation or was stopped by an error.
+func (player *LivePlayer) Done() bool {
+	return player.err != nil || player.sim.Generation() >= player.numGens
+}
+
+
+// Frame returns the drawing of the current generation.
+func (player *LivePlayer) Frame() image.Image {
+	return player.frame
+}
+
+
+// Err returns the error that stopped the run, or nil.
+func (player *LivePlayer) Err() error {
+	return player.err
+}
+
+
+// Status returns a line describing the state of the player, for the title of the window.
+func (player *LivePlayer) Status() string {
+	state := fmt.Sprintf("%d generations per frame", player.speed)
+	switch {
+	case player.err != nil:
+		state = "stopped: " + player.err.Error()
+	case player.Done():
+		state = "done"
+	case player.paused:
+		state = "paused"
+	}
+	return fmt.Sprintf("Barnes-Hut: generation %d of %d, %s", player.sim.Generation(), player.numGens, state)
+}
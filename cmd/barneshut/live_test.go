@@ -0,0 +1,116 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the LivePlayer of the option "-live" in live.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/simulation"
+	"bufio"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type LivePlayerTestCases struct {
+	id         string
+	numStars   int
+	numGens    int
+	speed      int
+	keys       string
+	generation int
+	endSpeed   int
+	paused     bool
+}
+
+
+// ReadLivePlayer reads test data for the LivePlayer from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of LivePlayerTestCases structs containing the keys and the expected states.
+func ReadLivePlayer(fileName string) []LivePlayerTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []LivePlayerTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 8 {
+			continue
+		}
+
+		test := LivePlayerTestCases{id: strings.TrimSpace(parts[0]), keys: strings.TrimSpace(parts[4])}
+		test.numStars, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		test.numGens, err = strconv.Atoi(strings.TrimSpace(parts[2]))
+		nbody.Check(err)
+		test.speed, err = strconv.Atoi(strings.TrimSpace(parts[3]))
+		nbody.Check(err)
+		test.generation, err = strconv.Atoi(strings.TrimSpace(parts[5]))
+		nbody.Check(err)
+		test.endSpeed, err = strconv.Atoi(strings.TrimSpace(parts[6]))
+		nbody.Check(err)
+		test.paused, err = strconv.ParseBool(strings.TrimSpace(parts[7]))
+		nbody.Check(err)
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestLivePlayer tests that the keys of the live window pause, step, and change the speed of the LivePlayer, and
+// that the generations it advances in place match those of BarnesHut.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestLivePlayer(t *testing.T) {
+	tests := ReadLivePlayer("../../Tests/LivePlayer.txt")
+
+	for _, test := range tests {
+		options := simulation.DefaultOptions(test.numGens, 2e14, 0.5)
+		nbody.SetDeterministic()
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		initial := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+		expected := simulation.BarnesHut(initial, options)[test.generation]
+
+		player, err := NewLivePlayer(initial, options, 100, 5e11, test.speed)
+		nbody.Check(err)
+		for _, key := range test.keys {
+			switch key {
+			case 'a':
+				player.Advance()
+			case 'p':
+				player.TogglePause()
+			case 's':
+				player.StepOnce()
+			case '+':
+				player.Faster()
+			case '-':
+				player.Slower()
+			}
+		}
+
+		if player.sim.Generation() != test.generation || player.speed != test.endSpeed || player.paused != test.paused {
+			t.Errorf("TestLivePlayer(test %v) = generation %d, speed %d, paused %v, want %d, %d, %v", test.id,
+				player.sim.Generation(), player.speed, player.paused, test.generation, test.endSpeed, test.paused)
+		}
+		if !reflect.DeepEqual(player.sim.Snapshot(), expected) {
+			t.Errorf("TestLivePlayer(test %v) differs from BarnesHut at generation %d", test.id, test.generation)
+		}
+		if player.Frame() == nil {
+			t.Errorf("TestLivePlayer(test %v) has no frame", test.id)
+		}
+	}
+}
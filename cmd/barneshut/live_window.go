@@ -0,0 +1,92 @@
+//go:build live
+
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: The desktop window of "-live", drawn with the ebiten game library (built with -tags live).
+
+package main
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+func init() {
+	liveWindow = RunLiveWindow
+}
+
+
+// liveGame is the ebiten.Game of the window: it advances the player once per tick and shows its frame.
+type liveGame struct {
+	player *LivePlayer
+	image  *ebiten.Image // the frame of the player, uploaded when it changes
+	shown  image.Image   // the frame of the player image was made from
+	title  string
+}
+
+
+// RunLiveWindow opens a window of the size of the frames of player and runs it until the window is closed.
+// Keys: space pauses and resumes, + or the up arrow doubles the generations per frame, - or the down arrow
+// halves them, and the period (or the right arrow) advances one generation while paused.
+// Input:
+//   - player: pointer to the LivePlayer.
+// Output:
+//   - an error if the window could not be opened, or the error that stopped the run.
+func RunLiveWindow(player *LivePlayer) error {
+	bounds := player.Frame().Bounds()
+	ebiten.SetWindowSize(bounds.Dx(), bounds.Dy())
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+	game := &liveGame{player: player}
+	if err := ebiten.RunGame(game); err != nil {
+		return err
+	}
+	return player.Err()
+}
+
+
+// Update handles the keys and advances the player.
+func (game *liveGame) Update() error {
+	player := game.player
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeySpace):
+		player.TogglePause()
+	case inpututil.IsKeyJustPressed(ebiten.KeyEqual), inpututil.IsKeyJustPressed(ebiten.KeyKPAdd), inpututil.IsKeyJustPressed(ebiten.KeyArrowUp):
+		player.Faster()
+	case inpututil.IsKeyJustPressed(ebiten.KeyMinus), inpututil.IsKeyJustPressed(ebiten.KeyKPSubtract), inpututil.IsKeyJustPressed(ebiten.KeyArrowDown):
+		player.Slower()
+	case inpututil.IsKeyJustPressed(ebiten.KeyPeriod), inpututil.IsKeyJustPressed(ebiten.KeyArrowRight):
+		if player.paused {
+			player.StepOnce()
+		}
+	}
+	player.Advance()
+
+	if title := player.Status(); title != game.title {
+		ebiten.SetWindowTitle(title)
+		game.title = title
+	}
+	return nil
+}
+
+
+// Draw shows the frame of the player, scaled to the window.
+func (game *liveGame) Draw(screen *ebiten.Image) {
+	frame := game.player.Frame()
+	if frame != game.shown {
+		if game.image != nil {
+			game.image.Deallocate()
+		}
+		game.image = ebiten.NewImageFromImage(frame)
+		game.shown = frame
+	}
+	screen.DrawImage(game.image, nil)
+}
+
+
+// Layout keeps the logical screen at the size of the frames; ebiten scales it to the window.
+func (game *liveGame) Layout(outsideWidth, outsideHeight int) (int, int) {
+	bounds := game.player.Frame().Bounds()
+	return bounds.Dx(), bounds.Dy()
+}
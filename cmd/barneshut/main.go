@@ -0,0 +1,624 @@
+//go:build !(js && wasm)
+
+// Author: Yu-Lun Chen
+// Date: 2025-10-24
+// Description: Main code for running and visualizing the universe simulation.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"BarnesHut/render"
+	"BarnesHut/scenarios"
+	"BarnesHut/simulation"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// main is the entry point of the Barnes-Hut simulation program
+func main() {
+	// read parameters from command line
+	// the command should be: ./BarnesHut "jupiter/galaxy/collision"
+	// as mention on cogniterra
+	if len(os.Args) < 2 {
+		fmt.Println("Usage: ./BarnesHut [jupiter|galaxy|collision|disk|cluster|solar|kepler] [-gens n] [-dt s] [-theta t] [-width m] [-height m] [-canvas pixels] [-frequency n] [-scale f] [-toomre-q q] [-king-w0 w] [-kepler-e e] [-tracers n] [-central-mass kg [-central-scale m]] [-potential point:mass|log:v0,rc|nfw:mass,scale] [-friction drag:gamma|chandrasekhar[:lnL] [-friction-mass kg]] [-galaxies n] [-positions x0,y0,...] [-separation m] [-impact m] [-approach deg] [-push v] [-push-angle deg] [-color-origin] [-initial file.csv|json|tipsy] [-out-dir dir] [-name template] [-snapshots dir [-snapshot-format text|json|tipsy|hdf5]] [-stream | -live] [-web addr] [-adaptive-frames f] [-format gif|webp|png] [-png-frames dir] [-delta-gif] [-gif-colors n] [-gif-quantizer auto|median-cut|plan9|websafe] [-gif-dither] [-brightness solid|linear|log|asinh] [-camera fixed|auto] [-track-star i | -track-galaxy g] [-overlays time,scale,legend] [-age-colors t] [-integrator verlet|euler] [-substeps k] [-adaptive-dt eta [-block-levels k]] [-softening eps] [-force-law newton|yukawa:alpha,lambda|mond[:a0]|repulsive] [-adaptive-softening k [-softening-interval n]] [-boundary open|reflect] [-remove-escaped f] [-merge k] [-finite-check abort|clamp|off] [-events file] [-energy-log n] [-trajectories n [-trajectory-stars i,j,...] [-trajectory-galaxy g] [-trajectory-region x0,y0,x1,y1]] [-momentum-check f] [-checkpoint-every n] [-resume file] [-workers n] [-tree-build serial|partitioned|flat|linear] [-leaf-size k] [-tree-walk stack|recursive] [-tree-reuse] [-morton] [-mac bh|bmax|salmon-warren] [-mac-tolerance a] [-force tree|direct [-layout aos|soa]] [-max-mem size] [-cpuprofile file] [-memprofile file] [-debug-tree gens] [-trace-star i -trace-gen g] [-progress] [-deterministic] [-seed n] [-batch]")
+		fmt.Println("       ./BarnesHut -config file.toml [the options of the scenarios]")
+		fmt.Println("       ./BarnesHut simulate [jupiter|galaxy|collision|disk|cluster|solar|kepler | -config file.toml] [the options of the scenarios]")
+		fmt.Println("       ./BarnesHut render -in dir [-out-dir dir] [-name template] [-canvas pixels] [-scale f] [-fps f] [-every n] [-format gif|webp|png] [-png-frames dir] [-delta-gif] [-gif-colors n] [-gif-quantizer auto|median-cut|plan9|websafe] [-gif-dither] [-brightness solid|linear|log|asinh] [-camera fixed|auto] [-track-star i | -track-galaxy g] [-overlays time,scale,legend] [-age-colors t] [-color-origin] [-workers n]")
+		fmt.Println("       ./BarnesHut analyze -in dir [-energy] [-profile] [-groups] [-instability] [-bar] [-tails]")
+		fmt.Println("       ./BarnesHut quadtree [-stars n] [-canvas pixels] [-hold frames] [-deterministic]")
+		fmt.Println("       ./BarnesHut profile [jupiter|galaxy|collision|disk|cluster|solar|kepler] [-gens n] [-frames n] [-theta t]")
+		fmt.Println("       ./BarnesHut selftest [-workers n] [-tree-build serial|partitioned|flat|linear] [-mac bh|bmax|salmon-warren] [-mac-tolerance a]")
+		fmt.Println("       ./BarnesHut bench [-n 1000,2000,...] [-theta 0.3,0.5,...] [-steps k] [-target n] [-workers n] [-tree-build serial|partitioned|flat|linear] [-leaf-size k] [-tree-walk stack|recursive] [-mac bh|bmax|salmon-warren] [-mac-tolerance a]")
+		fmt.Println("       ./BarnesHut serve [-addr :8080] [-dir runs] [-queue n] [-workers n]")
+		fmt.Println("       ./BarnesHut compare [jupiter|galaxy|collision|disk|cluster|solar|kepler] [-config file.toml] [-initial file.csv|json|tipsy] [-theta 0.1,0.3,...] [-max-error e] [-gens n] [-softening eps] [-force-law newton|yukawa:alpha,lambda|mond[:a0]|repulsive] [-workers n] [-tree-build serial|partitioned|flat|linear] [-mac bh|bmax|salmon-warren] [-mac-tolerance a]")
+		os.Exit(1)
+	}
+
+	command := os.Args[1]
+
+	// analyze works on saved snapshots and does not run a simulation
+	if command == "analyze" {
+		RunAnalyze(os.Args[2:])
+		return
+	}
+
+	// render draws saved snapshots and does not run a simulation
+	if command == "render" {
+		RunRender(os.Args[2:])
+		return
+	}
+
+	// quadtree only animates the construction of a single tree
+	if command == "quadtree" {
+		RunQuadTreeDemo(os.Args[2:])
+		return
+	}
+
+	// profile times a short segment of a simulation command
+	if command == "profile" {
+		RunProfile(os.Args[2:])
+		return
+	}
+
+	// selftest runs short canonical problems and reports their errors
+	if command == "selftest" {
+		RunSelfTest(os.Args[2:])
+		return
+	}
+
+	// bench times steps over a grid of star counts and theta values
+	if command == "bench" {
+		RunBench(os.Args[2:])
+		return
+	}
+
+	// compare measures the error of the tree forces against direct summation
+	if command == "compare" {
+		RunCompare(os.Args[2:])
+		return
+	}
+
+	// serve runs the configuration files posted to its REST API
+	if command == "serve" {
+		RunServe(os.Args[2:])
+		return
+	}
+
+	// simulate runs a scenario like its command but only saves the snapshots: ./BarnesHut simulate galaxy [options]
+	// a run of a configuration file has no scenario command: ./BarnesHut [simulate] -config file [options]
+	args := os.Args[2:]
+	simulateOnly := command == "simulate"
+	if simulateOnly {
+		command = ""
+		if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+			command, args = args[0], args[1:]
+		}
+	} else if strings.HasPrefix(command, "-") {
+		command, args = "", os.Args[1:]
+	}
+
+	// options shared by all simulation commands
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	configFile := flags.String("config", "", "TOML file defining the scenario (width, generations, dt, theta, output, galaxies, stars) instead of a scenario command")
+	initialFile := flags.String("initial", "", "file with the initial universe (.csv, a .json snapshot, or a .tipsy file) replacing the scenario's stars and width")
+	outDir := flags.String("out-dir", ".", "directory receiving the animation, snapshots, and debug dumps")
+	nameTemplate := flags.String("name", "galaxy", "name of the output files; {scenario} and {seed} are replaced")
+	snapshotDir := flags.String("snapshots", "", "directory to save a snapshot every frequency generations (relative to -out-dir; simulate: default snapshots)")
+	flags.StringVar(&snapshotFormat, "snapshot-format", snapshotFormat, "format of the snapshots: text, json (every field, reloadable with -initial; the default of simulate), tipsy (for pynbody and other N-body tools), or hdf5 (for h5py)")
+	streaming := flags.Bool("stream", false, "save and draw every selected generation while the simulation runs instead of keeping all generations in memory")
+	webAddr := flags.String("web", "", "serve a page at this address, e.g. :8080, showing the stars of every frequency-th generation while the simulation runs")
+	live := flags.Bool("live", false, "show the simulation in a desktop window while it runs, frequency generations per frame, instead of writing an animation (needs go build -tags live)")
+	debugTree := flags.String("debug-tree", "", "comma-separated generations whose quadtree is printed, e.g. 0,10,20")
+	adaptiveFrames := flags.Float64("adaptive-frames", 0, "save/draw a frame when a star moved this fraction of the width since the last frame (0 uses the fixed frequency)")
+	maxFrameGap := flags.Int("max-frame-gap", 0, "with -adaptive-frames, also save/draw a frame after this many generations without one (0 for no limit)")
+	format := flags.String("format", "gif", "animation format: gif, webp, or png (numbered PNG frames only, in -png-frames or name.frames)")
+	pngFrames := flags.String("png-frames", "", "directory receiving every frame as a numbered PNG file, in addition to the animation (relative to -out-dir)")
+	flags.IntVar(&nbody.NumWorkers, "workers", nbody.NumWorkers, "number of goroutines used for encoding and parallel tree builds")
+	treeBuild := flags.String("tree-build", "serial", "quadtree construction: serial, partitioned (one goroutine per level-2 quadrant), flat (lock-free, all goroutines share one tree), or linear (pointer-free, sorted by Morton code)")
+	leafSize := flags.Int("leaf-size", 1, "largest number of stars in a leaf of the quadtree before it is subdivided; close leaves pull with each of their stars")
+	treeWalk := flags.String("tree-walk", "stack", "walk of the quadtree for the forces: stack (a loop over an explicit stack) or recursive")
+	treeReuse := flags.Bool("tree-reuse", false, "refit the quadtree of the previous step, moving only the stars that left their leaves, instead of building it again")
+	morton := flags.Bool("morton", false, "build the quadtree and walk the forces in Morton (Z-curve) order of the stars, for cache-friendly memory access")
+	mac := flags.String("mac", "bh", "opening criterion of the force walk: bh (s/d < theta), bmax, or salmon-warren")
+	macTolerance := flags.Float64("mac-tolerance", 0, "acceleration error in m/s^2 accepted per node by -mac salmon-warren")
+	forceMode := flags.String("force", "tree", "force computation: tree (Barnes-Hut) or direct (all pairs, O(N^2), for validation)")
+	layout := flags.String("layout", "aos", "memory layout of the stars for -force direct: aos (the stars) or soa (parallel arrays of positions and masses)")
+	maxMemText := flags.String("max-mem", "", "memory cap, e.g. 2GB; above it the run thins, streams, and spools to disk")
+	cpuProfile := flags.String("cpuprofile", "", "write a pprof CPU profile of the run to this file")
+	memProfile := flags.String("memprofile", "", "write a pprof heap profile at the end of the run to this file")
+	flags.IntVar(&render.GIFColors, "gif-colors", render.GIFColors, "largest number of colors of a GIF frame palette (2 to 256)")
+	flags.StringVar(&render.GIFQuantizer, "gif-quantizer", render.GIFQuantizer, "GIF palettes: auto (exact colors or Plan9), median-cut (adapted to each frame), plan9, or websafe")
+	flags.BoolVar(&render.GIFDither, "gif-dither", render.GIFDither, "dither GIF frames with Floyd-Steinberg error diffusion")
+	flags.StringVar(&render.Brightness, "brightness", render.Brightness, "drawing of the stars: solid discs, or added up per pixel and shown linear, log, or asinh")
+	flags.StringVar(&render.Camera, "camera", render.Camera, "part of the universe drawn: fixed (all of it) or auto (centered on the center of mass and zoomed to the stars)")
+	overlayList := flags.String("overlays", "", "annotations drawn on every frame, a comma separated list of time, scale, and legend")
+	trackStar := flags.Int("track-star", -1, "ID of a star, its index in the initial universe, e.g. a central black hole, kept at the center of every frame")
+	trackGalaxy := flags.Int("track-galaxy", -1, "galaxy whose center of mass is kept at the center of every frame (0 for the first)")
+	flags.Float64Var(&render.AgeColorTime, "age-colors", render.AgeColorTime, "color stars by age, from blue when created to red at this age in seconds (0 keeps their colors)")
+	deltaGIF := flags.Bool("delta-gif", false, "store only the changed pixels of each GIF frame (much smaller files)")
+	traceStar := flags.Int("trace-star", -1, "index of a star whose force walk is traced (requires -trace-gen)")
+	traceGeneration := flags.Int("trace-gen", 0, "generation in which -trace-star is traced")
+	integrator := flags.String("integrator", "verlet", "time integrator: verlet or euler")
+	substeps := flags.Int("substeps", 1, "integration steps per stored generation, each 1/k of the time step")
+	adaptiveStep := flags.Float64("adaptive-dt", 0, "choose every step as eta * sqrt(softening / largest acceleration), at most the scenario's step (0 for a fixed step)")
+	blockLevels := flags.Int("block-levels", 0, "integrate every star with its own step, the scenario's step divided by 2^k with k up to this number of levels and eta of -adaptive-dt (0 for one step for all stars)")
+	softening := flags.Float64("softening", 0, "Plummer softening length in meters (0 for none)")
+	forceLawName := flags.String("force-law", "newton", "law of the force between stars: newton, yukawa:alpha,lambda (range lambda in m), mond[:a0] (MOND-like, a0 in m/s^2), or repulsive")
+	adaptiveSoftening := flags.Int("adaptive-softening", 0, "set every star's softening to the distance to its k-th nearest neighbor (0 for a fixed softening)")
+	softeningInterval := flags.Int("softening-interval", 10, "generations between updates of -adaptive-softening")
+	boundary := flags.String("boundary", "open", "what happens at the edge of the universe: open (stars leave) or reflect")
+	removeEscaped := flags.Float64("remove-escaped", 0, "remove stars farther than this fraction of the width outside the universe (0 keeps them)")
+	merge := flags.Float64("merge", 0, "merge colliding stars, closer than k times the sum of their radii; 1e10 merges the black holes of the collision scenario when they meet (0 for none)")
+	finiteCheck := flags.String("finite-check", "abort", "what a run does with a NaN or infinite position, velocity, or acceleration: abort (stop and name the star), clamp (zero the acceleration or remove the star), or off")
+	eventsFile := flags.String("events", "", "file of scripted events (add-star, add-galaxy, supernova, supernovae) applied at given generations")
+	checkpointEvery := flags.Int("checkpoint-every", 0, "write a checkpoint of the run every n generations, next to the animation (0 for none)")
+	resumeFile := flags.String("resume", "", "continue a run from its checkpoint; give the scenario and options of the interrupted run")
+	energyLog := flags.Int("energy-log", 0, "record the kinetic and potential energy every n generations, write them next to the animation, and print the drift (0 for none)")
+	trajectories := flags.Int("trajectories", 0, "write the position and velocity of the stars every n generations to a CSV file next to the animation (0 for none)")
+	trajectoryStars := flags.String("trajectory-stars", "", "comma separated IDs of the stars written with -trajectories, their indices in the initial universe (default: all)")
+	trajectoryGalaxy := flags.Int("trajectory-galaxy", -1, "write only the stars of this galaxy with -trajectories (0 for the first)")
+	trajectoryRegion := flags.String("trajectory-region", "", "write only the stars starting in this rectangle x0,y0,x1,y1 (in m) with -trajectories")
+	momentumCheck := flags.Float64("momentum-check", 0, "track the linear and angular momentum of every saved generation and warn when one drifts by more than this fraction (0 for none)")
+	progress := flags.Bool("progress", false, "print the progress of the simulation to standard error")
+	deterministicRun := flags.Bool("deterministic", false, "fixed random seed, so repeated runs give identical output")
+	seedFlag := flags.Int64("seed", 0, "seed of the random number generator, so runs with the same seed and options are identical (default: from the clock)")
+	flags.Float64Var(&scenarios.ToomreQ, "toomre-q", scenarios.ToomreQ, "Toomre stability parameter of the disk command")
+	flags.Float64Var(&scenarios.KingW0, "king-w0", scenarios.KingW0, "central potential W0 of the King model of the cluster command")
+	flags.Float64Var(&scenarios.KeplerE, "kepler-e", scenarios.KeplerE, "eccentricity of the orbit of the kepler command")
+	centralMass := flags.Float64("central-mass", 0, "fixed analytic mass at the center of the universe in kg, pulling every star (0 for none)")
+	centralScale := flags.Float64("central-scale", 0, "Hernquist scale radius of -central-mass in m (0 for a point mass)")
+	frictionName := flags.String("friction", "", "friction slowing the heaviest stars down, such as black holes: drag:gamma (gamma in 1/s) or chandrasekhar[:lnL] (dynamical friction, lnL the Coulomb logarithm)")
+	frictionMass := flags.Float64("friction-mass", nbody.BlackHoleMass, "mass in kg from which stars feel -friction (that of the black holes of the scenarios)")
+	potential := flags.String("potential", "", "fixed external potential at the center of the universe: point:mass (kg), log:v0,rc (logarithmic halo, m/s and m), or nfw:mass,scale (NFW halo, kg and m)")
+	flags.IntVar(&nbody.NumTracers, "tracers", nbody.NumTracers, "massless tracers added to every galaxy of the galaxy and collision commands")
+	colorOrigin := flags.Bool("color-origin", false, "color the stars of every galaxy differently")
+	scenarios.AddScenarioFlags(flags)
+	scenarios.AddCollisionFlags(flags)
+	flags.BoolVar(&batchMode, "batch", false, "no progress messages; print a JSON summary and exit with 2 for configuration errors, 3 for numerical failures")
+	flags.Parse(args)
+
+	// a configuration file replaces the built-in scenario, and its name replaces the command in output names
+	scenarioCommand := command
+	if *configFile != "" {
+		command = scenarios.ConfigName(*configFile)
+	}
+
+	if *deterministicRun {
+		nbody.SetDeterministic()
+	}
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			nbody.SetSeed(*seedFlag)
+		}
+	})
+
+	// a batch run reports its outcome, also a failure, in the summary and the exit code
+	var summary *BatchSummary
+	if batchMode {
+		nbody.StatusOutput = io.Discard
+		summary = NewBatchSummary(command)
+		defer func() {
+			os.Exit(summary.Finish(recover()))
+		}()
+	}
+
+	if *format != "gif" && *format != "webp" && *format != "png" {
+		CheckConfig(fmt.Errorf("unknown format %q (use gif, webp, or png)", *format))
+	}
+
+	CheckConfig(scenarios.CheckKingW0(scenarios.KingW0))
+	CheckConfig(scenarios.CheckKeplerEccentricity(scenarios.KeplerE))
+	CheckConfig(nbody.CheckTracers(nbody.NumTracers))
+	CheckConfig(simulation.CheckCentralMass(*centralMass, *centralScale))
+	CheckConfig(scenarios.ApplyCollisionFlags(flags))
+	CheckConfig(nbody.CheckSnapshotFormat(snapshotFormat))
+	if simulateOnly {
+		CheckConfig(ApplySimulateFlags(flags))
+	}
+	CheckConfig(render.CheckBrightness(render.Brightness))
+	CheckConfig(render.CheckCamera(render.Camera))
+	CheckConfig(CheckLive(*live))
+	var err error
+	render.SelectedOverlays, err = render.ParseOverlays(*overlayList)
+	CheckConfig(err)
+	CheckConfig(render.CheckAgeColors(render.AgeColorTime))
+	CheckConfig(render.CheckGIFOptions(render.GIFColors, render.GIFQuantizer))
+
+	maxMem := int64(0)
+	if *maxMemText != "" {
+		var err error
+		maxMem, err = ParseByteSize(*maxMemText)
+		CheckConfig(err)
+	}
+
+	debugTrees, err := quadtree.ParseGenerationList(*debugTree)
+	CheckConfig(err)
+
+	// the profiles cover everything from loading the scenario to writing the animation
+	stopProfiles, err := StartProfiles(*cpuProfile, *memProfile)
+	CheckConfig(err)
+	defer func() {
+		nbody.Check(stopProfiles())
+		if summary != nil {
+			summary.Wrote(*cpuProfile, *memProfile)
+		}
+	}()
+
+	scenario, err := LoadRunScenario(scenarioCommand, *configFile, *initialFile, flags, *substeps)
+	CheckConfig(err)
+
+	// a resumed run starts from the universe and random state of its checkpoint, and keeps the seed in its name
+	firstGeneration := 0
+	var checkpoint simulation.Checkpoint
+	if *resumeFile != "" {
+		checkpoint, err = simulation.LoadCheckpoint(*resumeFile)
+		CheckConfig(err)
+		scenario.InitialUniverse, firstGeneration = checkpoint.Restore()
+		if summary != nil {
+			summary.Seed = nbody.Seed
+		}
+		fmt.Fprintln(nbody.StatusOutput, "Resuming from generation", firstGeneration)
+	}
+	// -track-star and -trajectory-stars name the stars by their IDs
+	scenario.InitialUniverse.AssignStarIDs()
+	fmt.Fprintf(nbody.StatusOutput, "Random seed %d; repeat the run with -seed %d\n", nbody.Seed, nbody.Seed)
+
+	paths, err := NewOutputPaths(*outDir, *nameTemplate, command, nbody.Seed)
+	nbody.Check(err)
+	if simulateOnly && *snapshotDir == "" {
+		*snapshotDir = "snapshots"
+	}
+	*snapshotDir = paths.Dir(*snapshotDir)
+	if summary != nil {
+		summary.path = paths.File(".summary.json")
+		summary.NumStars = len(scenario.InitialUniverse.Stars)
+	}
+
+	// the debug dumps go to a file next to the animation
+	var debugOutput io.Writer
+	if *debugTree != "" || *traceStar >= 0 {
+		debugFile, err := os.Create(paths.File(".debug.txt"))
+		nbody.Check(err)
+		defer debugFile.Close()
+		debugOutput = debugFile
+		if summary != nil {
+			summary.Wrote(debugFile.Name())
+		}
+	}
+
+	// the stars of a checkpoint already have their colors
+	if *colorOrigin && *resumeFile == "" {
+		nbody.ColorByOrigin(scenario.InitialUniverse)
+	}
+	if *colorOrigin {
+		_, render.LegendGalaxies = nbody.TagOrigins(scenario.InitialUniverse)
+	}
+
+	render.CurrentTrack, err = render.NewCameraTrack(scenario.InitialUniverse, *trackStar, *trackGalaxy)
+	CheckConfig(err)
+
+	potentials, err := simulation.ParsePotential(*potential, scenario.InitialUniverse.Center())
+	CheckConfig(err)
+	friction, err := simulation.ParseFriction(*frictionName, *frictionMass)
+	CheckConfig(err)
+	forceLaw, err := nbody.ParseForceLaw(*forceLawName)
+	CheckConfig(err)
+	options := scenario.Options().WithHalos(simulation.CentralMass(scenario.InitialUniverse.Center(), *centralMass, *centralScale)...).
+		WithPotentials(potentials...).WithFriction(friction).WithIntegrator(*integrator).WithSoftening(*softening).
+		WithTreeBuild(*treeBuild).WithLeafSize(*leafSize).WithTreeWalk(*treeWalk).WithTreeReuse(*treeReuse).WithMorton(*morton).
+		WithMAC(*mac, *macTolerance).WithForceMode(*forceMode).WithLayout(*layout).WithForceLaw(forceLaw).
+		WithEventLog(nbody.StatusOutput).WithDebug(debugOutput, debugTrees, *traceStar, *traceGeneration)
+	options.Substeps = *substeps
+	options.AdaptiveStep = *adaptiveStep
+	options.BlockLevels = *blockLevels
+	options.AdaptiveSoftening = *adaptiveSoftening
+	options.SofteningInterval = *softeningInterval
+	options.Boundary = *boundary
+	options.RemoveEscaped = *removeEscaped
+	options.MergeScale = *merge
+	options.FiniteCheck = *finiteCheck
+	options.FirstGeneration = firstGeneration
+	if *eventsFile != "" {
+		events, err := simulation.ReadEvents(*eventsFile)
+		CheckConfig(err)
+		options.Events = events
+	}
+	if *progress {
+		options = options.WithProgress(simulation.ProgressPrinter(os.Stderr, 100))
+	}
+	CheckConfig(options.Validate())
+	CheckConfig(checkpoint.CheckTimeStep(options.TimeStep))
+	if *checkpointEvery < 0 {
+		CheckConfig(fmt.Errorf("checkpoint interval must not be negative, got %d", *checkpointEvery))
+	}
+	if *checkpointEvery > 0 {
+		checkpoints := simulation.NewCheckpointWriter(paths.File(".checkpoint"), *checkpointEvery, firstGeneration, options.TimeStep)
+		options = options.WithHook(checkpoints.Hook())
+		// reported when the run ends, so a full disk does not stop the run
+		defer func() { nbody.Check(checkpoints.Err()) }()
+	}
+
+	// Ctrl+C stops the run after the current generation: the frames computed so far are drawn, and a checkpoint
+	// of the last generation is written so the run can be continued with -resume. A second Ctrl+C exits at once.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	context.AfterFunc(ctx, stop)
+	last := &simulation.LastGeneration{TimeStep: options.TimeStep}
+	options = options.WithContext(ctx).WithHook(last.Hook())
+	defer func() {
+		if ctx.Err() == nil {
+			return
+		}
+		fileName := paths.File(".checkpoint")
+		generation, err := last.WriteCheckpoint(fileName)
+		nbody.Check(err)
+		fmt.Fprintf(nbody.StatusOutput, "Interrupted after generation %d; continue with -resume %s\n", generation, fileName)
+		if summary != nil {
+			summary.Interrupted(generation)
+			summary.Wrote(fileName)
+		}
+	}()
+
+	if *momentumCheck < 0 {
+		CheckConfig(fmt.Errorf("momentum drift threshold must not be negative, got %v", *momentumCheck))
+	}
+	if *momentumCheck > 0 {
+		monitor := simulation.NewMomentumMonitor(scenario.Frequency, options.NumGens, *momentumCheck)
+		options = options.WithHook(monitor.Hook())
+		defer func() {
+			file, err := os.Create(paths.File(".momentum.txt"))
+			nbody.Check(err)
+			defer file.Close()
+			nbody.Check(monitor.WriteReport(file))
+			linear, angular := monitor.MaxDrift()
+			fmt.Fprintf(nbody.StatusOutput, "Largest momentum drift: linear %e, angular %e (series in %s)\n", linear, angular, file.Name())
+			if summary != nil {
+				summary.Warnings = append(summary.Warnings, monitor.Warnings...)
+				summary.Wrote(file.Name())
+			}
+		}()
+	}
+	if *energyLog < 0 {
+		CheckConfig(fmt.Errorf("energy log interval must not be negative, got %d", *energyLog))
+	}
+	if *energyLog > 0 {
+		var records []simulation.EnergyRecord
+		options = options.WithHook(simulation.EnergyRecorder(*energyLog, options.NumGens, options, &records))
+		// written when the run ends, also after a failure, so the series shows how it went wrong
+		defer func() {
+			file, err := os.Create(paths.File(".energy.txt"))
+			nbody.Check(err)
+			defer file.Close()
+			nbody.Check(simulation.WriteEnergySeries(file, records))
+			fmt.Fprintf(nbody.StatusOutput, "Relative energy drift: %e (series in %s)\n", simulation.EnergyDrift(records), file.Name())
+			if summary != nil {
+				summary.Wrote(file.Name())
+			}
+		}()
+	}
+	if *trajectories < 0 {
+		CheckConfig(fmt.Errorf("trajectory interval must not be negative, got %d", *trajectories))
+	}
+	if *trajectories > 0 {
+		ids, err := simulation.TrajectoryStars(scenario.InitialUniverse, *trajectoryStars, *trajectoryGalaxy, *trajectoryRegion)
+		CheckConfig(err)
+		file, err := os.Create(paths.File(".trajectories.csv"))
+		nbody.Check(err)
+		writer := simulation.NewTrajectoryWriter(file, ids, *trajectories, options.NumGens)
+		options = options.WithHook(writer.Hook())
+		// closed when the run ends, also after a failure, so the histories lead up to it
+		defer func() {
+			nbody.Check(writer.Close())
+			nbody.Check(file.Close())
+			fmt.Fprintf(nbody.StatusOutput, "Trajectories in %s\n", file.Name())
+			if summary != nil {
+				summary.Wrote(file.Name())
+			}
+		}()
+	} else if *trajectoryStars != "" || *trajectoryGalaxy >= 0 || *trajectoryRegion != "" {
+		CheckConfig(fmt.Errorf("-trajectory-stars, -trajectory-galaxy, and -trajectory-region require -trajectories"))
+	}
+	if scenarioCommand == "solar" && *configFile == "" && *initialFile == "" {
+		monitor := &scenarios.PeriodMonitor{}
+		options = options.WithHook(monitor.Hook())
+		defer func() {
+			file, err := os.Create(paths.File(".periods.txt"))
+			nbody.Check(err)
+			defer file.Close()
+			nbody.Check(monitor.WriteReport(file))
+			nbody.Check(monitor.WriteReport(nbody.StatusOutput))
+			if summary != nil {
+				summary.Wrote(file.Name())
+			}
+		}()
+	}
+	if scenarioCommand == "kepler" && *configFile == "" && *initialFile == "" {
+		monitor := scenarios.NewKeplerMonitor(scenarios.DefaultKeplerOrbit(scenarios.KeplerE))
+		options = options.WithHook(monitor.Hook(options))
+		defer func() {
+			file, err := os.Create(paths.File(".kepler.txt"))
+			nbody.Check(err)
+			defer file.Close()
+			nbody.Check(monitor.WriteReport(file))
+			nbody.Check(monitor.WriteReport(nbody.StatusOutput))
+			if summary != nil {
+				summary.Wrote(file.Name())
+			}
+		}()
+	}
+	if summary != nil {
+		summary.Generations = options.NumGens
+	}
+
+	numGens := options.NumGens
+	canvasWidth, frequency, scalingFactor := scenario.CanvasWidth, scenario.Frequency, scenario.ScalingFactor
+	initialUniverse := scenario.InitialUniverse
+
+	// send the frames to the browsers watching the run, also when it runs headless
+	if *webAddr != "" {
+		viewer := NewWebViewer(frequency, numGens)
+		url, err := viewer.Start(*webAddr)
+		CheckConfig(err)
+		fmt.Fprintf(nbody.StatusOutput, "Watch the simulation at %s\n", url)
+		options = options.WithHook(viewer.Hook())
+		defer viewer.Close()
+	}
+
+	// simulate saves the generations that would be drawn, for render and analyze, and draws nothing
+	if simulateOnly {
+		selector := &render.FrameSelector{Frequency: frequency, Threshold: *adaptiveFrames, MaxGap: *maxFrameGap, FinalGeneration: numGens}
+		nbody.Check(os.MkdirAll(*snapshotDir, 0755))
+		nbody.Check(WriteDrawingDefaults(*snapshotDir, DrawingDefaults{Scenario: command, Seed: nbody.Seed, Canvas: canvasWidth, Scale: scalingFactor}))
+		numSnapshots := SimulateSnapshots(initialUniverse, options, selector, *snapshotDir, summary)
+		fmt.Fprintf(nbody.StatusOutput, "%d snapshots saved to %s; draw them with: ./BarnesHut render -in %s\n", numSnapshots, *snapshotDir, *snapshotDir)
+		if summary != nil {
+			summary.Wrote(*snapshotDir)
+		}
+		return
+	}
+
+	output := render.AnimationOutput{Format: *format, Delta: *deltaGIF, FileName: paths.File("")}
+	if *pngFrames != "" {
+		output.PNGDir = paths.Dir(*pngFrames)
+	} else if *format == "png" {
+		output.PNGDir = paths.File(".frames")
+	}
+	if output.PNGDir != "" {
+		nbody.Check(os.MkdirAll(output.PNGDir, 0755))
+	}
+
+	// show the frames in a window instead of writing them
+	if *live {
+		player, err := NewLivePlayer(initialUniverse, options, canvasWidth, scalingFactor, min(frequency, liveMaxSpeed))
+		nbody.Check(err)
+		nbody.Check(liveWindow(player))
+		return
+	}
+
+	// save and draw the frames while the simulation runs
+	if *streaming {
+		selector := &render.FrameSelector{Frequency: frequency, Threshold: *adaptiveFrames, MaxGap: *maxFrameGap, FinalGeneration: numGens}
+		RunStreaming(initialUniverse, options, selector, canvasWidth, scalingFactor, *snapshotDir, output, summary)
+		if summary != nil {
+			summary.Wrote(append(output.Paths(), *snapshotDir)...)
+		}
+		return
+	}
+
+	// keep only the generations that are drawn, unless the frames are chosen from the motion of all generations
+	if *adaptiveFrames == 0 {
+		options = options.WithSaveInterval(frequency)
+	}
+	savedGenerations := simulation.SavedGenerations(options.FirstGeneration, numGens, options.SaveInterval)
+
+	// fall back to thinning, streaming, and spooling when the kept generations would not fit in -max-mem
+	numFrames := numGens/frequency + 1
+	canvasHeight := render.FixedView(initialUniverse.Bounds()).CanvasHeight(canvasWidth)
+	plan := PlanMemory(maxMem, len(initialUniverse.Stars), len(savedGenerations)-1, numFrames, canvasWidth, canvasHeight)
+	if plan.thin {
+		fmt.Fprintf(nbody.StatusOutput, "Keeping everything would need about %s, more than -max-mem; using %s (about %s).\n",
+			FormatBytes(PlanMemory(0, len(initialUniverse.Stars), len(savedGenerations)-1, numFrames, canvasWidth, canvasHeight).estimate),
+			plan.Describe(), FormatBytes(plan.estimate))
+
+		selector := &render.FrameSelector{Frequency: frequency, Threshold: *adaptiveFrames, MaxGap: *maxFrameGap, FinalGeneration: numGens}
+		RunLowMemory(plan, initialUniverse, options, selector, canvasWidth, scalingFactor, *snapshotDir, output, summary)
+		if summary != nil {
+			summary.Wrote(append(output.Paths(), *snapshotDir)...)
+		}
+		return
+	}
+
+	// === Run Simulation ===
+	start := time.Now()
+	timePoints := simulation.BarnesHut(initialUniverse, options)
+	// an interrupted run kept fewer generations
+	savedGenerations = savedGenerations[:len(timePoints)]
+	if summary != nil {
+		summary.Time("simulate", start)
+		summary.Simulated(initialUniverse, timePoints[len(timePoints)-1], options)
+	}
+
+	if ctx.Err() != nil {
+		fmt.Fprintln(nbody.StatusOutput, "Simulation interrupted; drawing the generations kept so far.")
+	} else {
+		fmt.Fprintln(nbody.StatusOutput, "Simulation run.")
+	}
+
+	// choose the savedGenerations that are saved and drawn
+	frames := render.SavedFrameIndices(savedGenerations, frequency)
+	if *adaptiveFrames > 0 {
+		frames = render.AdaptiveFrameIndices(timePoints, *adaptiveFrames, *maxFrameGap)
+		fmt.Fprintln(nbody.StatusOutput, "Selected", len(frames), "frames from the motion of the stars.")
+	}
+
+	if *snapshotDir != "" {
+		nbody.Check(nbody.WriteSnapshots(timePoints, savedGenerations, frames, *snapshotDir, snapshotFormat))
+		fmt.Fprintln(nbody.StatusOutput, "Snapshots saved to", *snapshotDir)
+	}
+
+	fmt.Fprintln(nbody.StatusOutput, "Now drawing images.")
+
+	start = time.Now()
+	nbody.Check(render.DrawAnimation(timePoints, frames, canvasWidth, scalingFactor, output))
+	if summary != nil {
+		summary.Time("render", start)
+		summary.Wrote(append(output.Paths(), *snapshotDir)...)
+	}
+}
+
+
+// LoadRunScenario returns the scenario a simulation command starts from: the built-in scenario of a command, or
+// the scenario of a configuration file, with the universe of an initial file in place of its stars and the
+// overrides of the command line applied.
+// Input:
+//   - command: the scenario command, or "" for a configuration file.
+//   - configFile: the TOML file of -config, or "" for a scenario command. Its name never selects a scenario.
+//   - initialFile: the file of -initial, or "".
+//   - flags: the parsed command line options (see ApplyOverrides).
+//   - substeps: the number of steps of every generation, on which the velocities of the kepler orbit depend.
+// Output:
+//   - the scenario, or an error for an unknown command, a command given with -config, or an invalid file.
+func LoadRunScenario(command, configFile, initialFile string, flags *flag.FlagSet, substeps int) (scenarios.Scenario, error) {
+	var scenario scenarios.Scenario
+	var err error
+	if configFile != "" {
+		if command != "" {
+			return scenario, fmt.Errorf("give either a scenario command or -config, not both")
+		}
+		scenario, err = scenarios.LoadConfig(configFile)
+		if err != nil {
+			return scenario, err
+		}
+	} else if command == "" {
+		return scenario, fmt.Errorf("give a scenario command (jupiter, galaxy, collision, disk, cluster, solar, or kepler) or -config")
+	} else {
+		scenario, err = scenarios.LoadScenario(command)
+		if err != nil {
+			return scenario, err
+		}
+	}
+	if initialFile != "" {
+		u, err := nbody.LoadInitialUniverse(initialFile)
+		if err != nil {
+			return scenario, err
+		}
+		// the universe replaces the galaxies of the scenario, and with them their analytic halos
+		scenario.InitialUniverse, scenario.Width, scenario.Halos = u, u.Width, nil
+	}
+	if err := scenario.ApplyOverrides(flags); err != nil {
+		return scenario, err
+	}
+	// the bodies of the kepler orbit start with a velocity that depends on the step, which -dt or -substeps may change
+	if command == "kepler" && configFile == "" && initialFile == "" && substeps >= 1 {
+		scenario.InitialUniverse = scenarios.DefaultKeplerOrbit(scenarios.KeplerE).Universe(scenario.InitialUniverse.Width, scenario.Time/float64(substeps))
+	}
+	return scenario, nil
+}
@@ -0,0 +1,105 @@
+//go:build !(js && wasm)
+
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for loading the scenario of a run in main.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/scenarios"
+	"bufio"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type LoadRunScenarioTestCases struct {
+	id       string
+	command  string
+	config   string
+	fileName string
+	expected string
+}
+
+
+// ReadLoadRunScenario reads test data for the LoadRunScenario function from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of LoadRunScenarioTestCases structs containing the command, the configuration and its file name,
+// and the expected number of stars.
+func ReadLoadRunScenario(fileName string) []LoadRunScenarioTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []LoadRunScenarioTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+			if parts[i] == "-" {
+				parts[i] = ""
+			}
+		}
+
+		tests = append(tests, LoadRunScenarioTestCases{
+			id:       parts[0],
+			command:  parts[1],
+			config:   strings.ReplaceAll(parts[2], ";", "\n"),
+			fileName: parts[3],
+			expected: parts[4],
+		})
+	}
+
+	return tests
+}
+
+
+// TestLoadRunScenario tests the LoadRunScenario function using data from a file: a configuration file named like a
+// scenario command must run its own stars, not those of the command.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestLoadRunScenario(t *testing.T) {
+	tests := ReadLoadRunScenario("../../Tests/LoadRunScenario.txt")
+
+	for _, test := range tests {
+		configFile := ""
+		if test.config != "" {
+			configFile = filepath.Join(t.TempDir(), test.fileName)
+			nbody.Check(os.WriteFile(configFile, []byte(test.config), 0o644))
+		}
+		flags := flag.NewFlagSet(test.command, flag.ContinueOnError)
+		scenarios.AddScenarioFlags(flags)
+		nbody.Check(flags.Parse(nil))
+
+		scenario, err := LoadRunScenario(test.command, configFile, "", flags, 1)
+		if test.expected == "error" {
+			if err == nil {
+				t.Errorf("TestLoadRunScenario(test %v) gave no error, want one", test.id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestLoadRunScenario(test %v) error: %v", test.id, err)
+			continue
+		}
+		if result := strconv.Itoa(len(scenario.InitialUniverse.Stars)); result != test.expected {
+			t.Errorf("TestLoadRunScenario(test %v) = %v stars, want %v", test.id, result, test.expected)
+		}
+	}
+}
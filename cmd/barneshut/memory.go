@@ -0,0 +1,244 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Memory estimates and a low-memory way of running the simulation (option "-max-mem").
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/render"
+	"BarnesHut/simulation"
+	"fmt"
+	"image"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// MemoryPlan records which memory saving measures a run uses.
+type MemoryPlan struct {
+	thin     bool  // keep only the generations that are saved and drawn
+	stream   bool  // encode every frame as soon as it is drawn instead of keeping all images
+	spool    bool  // keep the saved generations on disk instead of in memory
+	estimate int64 // estimated peak memory of the plan in bytes
+}
+
+
+// ParseByteSize parses a memory size such as "512MB", "2GB", or "1048576" (bytes).
+// The suffixes KB, MB, GB, and TB are powers of 1024.
+func ParseByteSize(s string) (int64, error) {
+	units := []struct {
+		suffix string
+		factor int64
+	}{{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}}
+
+	text := strings.ToUpper(strings.TrimSpace(s))
+	factor := int64(1)
+	for _, u := range units {
+		if strings.HasSuffix(text, u.suffix) {
+			text = strings.TrimSpace(strings.TrimSuffix(text, u.suffix))
+			factor = u.factor
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid memory size %q (use e.g. 512MB or 2GB)", s)
+	}
+	return int64(value * float64(factor)), nil
+}
+
+
+// FormatBytes prints a number of bytes with a binary suffix, e.g. "1.5 GB".
+func FormatBytes(n int64) string {
+	value := float64(n)
+	for _, suffix := range []string{"B", "KB", "MB", "GB"} {
+		if value < 1024 {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+		value /= 1024
+	}
+	return fmt.Sprintf("%.1f TB", value)
+}
+
+
+// UniverseBytes estimates the memory held by one Universe with numStars stars.
+func UniverseBytes(numStars int) int64 {
+	perStar := int64(unsafe.Sizeof(nbody.Star{})) + int64(unsafe.Sizeof(&nbody.Star{}))
+	return int64(unsafe.Sizeof(nbody.Universe{})) + int64(numStars)*perStar
+}
+
+
+// FrameBytes estimates the memory held by one drawn RGBA frame.
+func FrameBytes(canvasWidth, canvasHeight int) int64 {
+	return int64(canvasWidth) * int64(canvasHeight) * 4
+}
+
+
+// PlanMemory chooses the memory saving measures needed to stay below maxMem.
+// Measures are added from the cheapest to the most expensive until the estimate fits:
+// first snapshot thinning, then streaming encoding, then disk spooling.
+// Input:
+//   - maxMem: the memory cap in bytes (0 or less means no cap).
+//   - numStars, numGens, numFrames, canvasWidth, canvasHeight: size of the run; numGens counts the generations
+//     BarnesHut keeps.
+// Output:
+//   - the MemoryPlan; its estimate may still exceed maxMem if even spooling is not enough.
+func PlanMemory(maxMem int64, numStars, numGens, numFrames, canvasWidth, canvasHeight int) MemoryPlan {
+	universe := UniverseBytes(numStars)
+	frame := FrameBytes(canvasWidth, canvasHeight)
+
+	plan := MemoryPlan{estimate: int64(numGens+1)*universe + int64(numFrames)*frame}
+	if maxMem <= 0 || plan.estimate <= maxMem {
+		return plan
+	}
+
+	plan.thin = true
+	plan.estimate = int64(numFrames)*universe + int64(numFrames)*frame
+	if plan.estimate <= maxMem {
+		return plan
+	}
+
+	plan.stream = true
+	plan.estimate = int64(numFrames)*universe + frame
+	if plan.estimate <= maxMem {
+		return plan
+	}
+
+	// only the current and the next generation stay in memory
+	plan.spool = true
+	plan.estimate = 2*universe + frame
+	return plan
+}
+
+
+// Describe returns a one-line summary of the measures of a plan.
+func (plan MemoryPlan) Describe() string {
+	var measures []string
+	if plan.thin {
+		measures = append(measures, "snapshot thinning")
+	}
+	if plan.stream {
+		measures = append(measures, "streaming encoding")
+	}
+	if plan.spool {
+		measures = append(measures, "disk spooling")
+	}
+	if len(measures) == 0 {
+		return "keeping everything in memory"
+	}
+	return strings.Join(measures, ", ")
+}
+
+
+// RunLowMemory runs the simulation and writes the animation following a MemoryPlan that uses thinning.
+// Only the generations chosen by selector are kept, in memory or (when spooling) as snapshot files,
+// and with streaming every frame is encoded right after it is drawn.
+// If options.ctx is cancelled, the generations kept so far are drawn.
+// Input:
+//   - plan: the MemoryPlan (plan.thin is assumed).
+//   - initialUniverse, options: simulation parameters as for BarnesHut.
+//   - selector: chooses the generations that are kept and drawn.
+//   - canvasWidth, scalingFactor: drawing parameters as for AnimateSystem.
+//   - snapshotDir: directory for saved snapshots ("" if none are requested).
+//   - output: how the animation is written.
+//   - summary: summary of a batch run receiving the runtimes and the conservation errors, or nil.
+// Output:
+//   - None (the animation and snapshots are written to disk).
+func RunLowMemory(plan MemoryPlan, initialUniverse *nbody.Universe, options simulation.Options,
+	selector *render.FrameSelector, canvasWidth int, scalingFactor float64, snapshotDir string, output render.AnimationOutput, summary *BatchSummary) {
+
+	spoolDir := snapshotDir
+	if plan.spool && spoolDir == "" {
+		dir, err := os.MkdirTemp("", "barneshut-spool-")
+		nbody.Check(err)
+		defer os.RemoveAll(dir)
+		spoolDir = dir
+	}
+	if spoolDir != "" {
+		nbody.Check(os.MkdirAll(spoolDir, 0755))
+	}
+
+	var kept []int
+	var universes []*nbody.Universe
+	var last *nbody.Universe
+
+	// the generations that are not selected are advanced in place
+	options.Retain = func(generation int) bool {
+		return len(kept) > 0 && kept[len(kept)-1] == generation
+	}
+
+	start := time.Now()
+	err := simulation.RunGenerations(initialUniverse, options.WithHook(func(generation int, u *nbody.Universe) {
+		last = u
+		if !selector.Select(generation, u) {
+			return
+		}
+		kept = append(kept, generation)
+
+		if spoolDir != "" {
+			nbody.Check(nbody.WriteSnapshot(u, generation, nbody.SnapshotFileName(spoolDir, generation, snapshotFormat)))
+		}
+		if !plan.spool {
+			universes = append(universes, u)
+		}
+	}))
+	if simulation.Interrupted(err) {
+		fmt.Fprintln(nbody.StatusOutput, "Simulation interrupted; drawing the generations kept so far.")
+	} else {
+		nbody.Check(err)
+	}
+	if summary != nil {
+		summary.Time("simulate", start)
+		summary.Simulated(initialUniverse, last, options)
+		defer summary.Time("render", time.Now())
+	}
+
+	fmt.Fprintln(nbody.StatusOutput, "Simulation run, kept", len(kept), "generations. Now drawing images.")
+
+	var stream *render.GIFStreamWriter
+	var file *os.File
+	if plan.stream && output.Format == "gif" {
+		var err error
+		stream, file, err = output.CreateGIFStream()
+		nbody.Check(err)
+		defer file.Close()
+	} else if plan.stream && output.Format != "png" {
+		fmt.Fprintln(nbody.StatusOutput, "Streaming encoding is only available for GIF; keeping the", output.Format, "frames in memory.")
+	}
+
+	var images []image.Image
+	for i, generation := range kept {
+		var u *nbody.Universe
+		if plan.spool {
+			var err error
+			u, _, err = nbody.ReadSnapshot(nbody.SnapshotFileName(spoolDir, generation, snapshotFormat))
+			nbody.Check(err)
+		} else {
+			u = universes[i]
+			universes[i] = nil
+		}
+
+		fmt.Fprintln(nbody.StatusOutput, generation)
+		img := render.DrawToCanvas(u, canvasWidth, scalingFactor)
+		nbody.Check(output.WritePNG(img, i))
+		if stream != nil {
+			nbody.Check(stream.WriteImage(img))
+		} else if output.Format != "png" {
+			images = append(images, img)
+		}
+	}
+
+	if stream != nil {
+		nbody.Check(stream.Close())
+		fmt.Fprintln(nbody.StatusOutput, "GIF drawn.")
+		return
+	}
+
+	// the PNG files are already written
+	output.PNGDir = ""
+	nbody.Check(render.WriteAnimation(images, output))
+}
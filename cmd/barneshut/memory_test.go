@@ -0,0 +1,185 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the memory cap of a run in memory.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type ByteSizeTestCases struct {
+	id       string
+	size     string
+	expected int64
+	valid    bool
+}
+
+type PlanMemoryTestCases struct {
+	id        string
+	numStars  int
+	numGens   int
+	numFrames int
+	width     int
+	height    int
+	cap       string
+	expected  string
+}
+
+
+// ReadByteSize reads test data for ParseByteSize from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of ByteSizeTestCases structs containing the sizes and the expected bytes.
+func ReadByteSize(fileName string) []ByteSizeTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []ByteSizeTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 3 {
+			continue
+		}
+
+		test := ByteSizeTestCases{id: strings.TrimSpace(parts[0]), size: strings.TrimSpace(parts[1])}
+		if expected := strings.TrimSpace(parts[2]); expected != "error" {
+			test.expected, err = strconv.ParseInt(expected, 10, 64)
+			nbody.Check(err)
+			test.valid = true
+		}
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestParseByteSize tests that ParseByteSize reads the sizes with their suffixes and rejects invalid ones.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestParseByteSize(t *testing.T) {
+	tests := ReadByteSize("../../Tests/ByteSize.txt")
+
+	for _, test := range tests {
+		result, err := ParseByteSize(test.size)
+		switch {
+		case !test.valid && err == nil:
+			t.Errorf("TestParseByteSize(test %v) = %d, want an error", test.id, result)
+		case test.valid && err != nil:
+			t.Errorf("TestParseByteSize(test %v) failed: %v", test.id, err)
+		case test.valid && result != test.expected:
+			t.Errorf("TestParseByteSize(test %v) = %d, want %d", test.id, result, test.expected)
+		}
+	}
+}
+
+
+// ReadPlanMemory reads test data for PlanMemory from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of PlanMemoryTestCases structs containing the runs, their caps, and the expected plans.
+func ReadPlanMemory(fileName string) []PlanMemoryTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []PlanMemoryTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 8 {
+			continue
+		}
+
+		var values [5]int
+		for i := range values {
+			values[i], err = strconv.Atoi(strings.TrimSpace(parts[i+1]))
+			nbody.Check(err)
+		}
+
+		tests = append(tests, PlanMemoryTestCases{
+			id:        strings.TrimSpace(parts[0]),
+			numStars:  values[0],
+			numGens:   values[1],
+			numFrames: values[2],
+			width:     values[3],
+			height:    values[4],
+			cap:       strings.TrimSpace(parts[6]),
+			expected:  strings.TrimSpace(parts[7]),
+		})
+	}
+
+	return tests
+}
+
+
+// TestPlanMemory tests that PlanMemory chooses the cheapest plan whose estimate fits the cap, at the boundary of
+// every plan, and that it reports the estimate of that plan.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestPlanMemory(t *testing.T) {
+	tests := ReadPlanMemory("../../Tests/PlanMemory.txt")
+
+	for _, test := range tests {
+		universe, frame := UniverseBytes(test.numStars), FrameBytes(test.width, test.height)
+		frames := int64(test.numFrames)
+		estimates := map[string]int64{
+			"full":      int64(test.numGens+1)*universe + frames*frame,
+			"thinned":   frames * (universe + frame),
+			"streaming": frames*universe + frame,
+			"spooling":  2*universe + frame,
+		}
+
+		var maxMem int64
+		switch name, below := strings.CutSuffix(test.cap, "-1"); {
+		case test.cap == "none":
+		case estimates[name] > 0:
+			maxMem = estimates[name]
+			if below {
+				maxMem--
+			}
+		default:
+			var err error
+			maxMem, err = strconv.ParseInt(test.cap, 10, 64)
+			nbody.Check(err)
+		}
+
+		plan := PlanMemory(maxMem, test.numStars, test.numGens, test.numFrames, test.width, test.height)
+		result := "full"
+		switch {
+		case plan.spool:
+			result = "spooling"
+		case plan.stream:
+			result = "streaming"
+		case plan.thin:
+			result = "thinned"
+		}
+		if result != test.expected || plan.estimate != estimates[test.expected] {
+			t.Errorf("TestPlanMemory(test %v) = %s estimating %d bytes, want %s estimating %d",
+				test.id, result, plan.estimate, test.expected, estimates[test.expected])
+		}
+		// every measure keeps the cheaper ones
+		if plan.spool && !plan.stream || plan.stream && !plan.thin {
+			t.Errorf("TestPlanMemory(test %v) = %+v, want the cheaper measures too", test.id, plan)
+		}
+	}
+}
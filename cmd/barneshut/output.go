@@ -0,0 +1,61 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Where the files of a run are written (options "-out-dir" and "-name").
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// OutputPaths decides where the files of a run are written.
+// All files go into dir and are named after name: the animation is name + ".out.gif" (or ".out.webp"),
+// and the debug dumps are name + ".debug.txt".
+type OutputPaths struct {
+	dir  string
+	name string
+}
+
+
+// NewOutputPaths expands the name template of a run and creates the output directory.
+// The template may contain {scenario}, replaced by the simulation command, and {seed},
+// replaced by the seed of the random number generator, e.g. "{scenario}-{seed}".
+// Input:
+//   - dir: output directory; it is created if it does not exist.
+//   - template: name template.
+//   - scenario: name of the simulation command.
+//   - seed: seed of nbody.RNG.
+// Output:
+//   - the OutputPaths, or an error if the directory cannot be created.
+func NewOutputPaths(dir, template, scenario string, seed int64) (OutputPaths, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return OutputPaths{}, err
+	}
+	return OutputPaths{dir: dir, name: ExpandName(template, scenario, seed)}, nil
+}
+
+
+// ExpandName replaces {scenario} and {seed} in a name template.
+func ExpandName(template, scenario string, seed int64) string {
+	r := strings.NewReplacer("{scenario}", scenario, "{seed}", strconv.FormatInt(seed, 10))
+	return r.Replace(template)
+}
+
+
+// File returns the path of a file named name + suffix in the output directory.
+func (p OutputPaths) File(suffix string) string {
+	return filepath.Join(p.dir, p.name+suffix)
+}
+
+
+// Dir returns the path of a directory given on the command line: relative paths are
+// placed inside the output directory, absolute paths and "" are returned unchanged.
+func (p OutputPaths) Dir(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(p.dir, path)
+}
@@ -0,0 +1,77 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the output names in output.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type ExpandNameTestCases struct {
+	id       string
+	template string
+	scenario string
+	seed     int64
+	expected string
+}
+
+
+// ReadExpandName reads test data for the ExpandName function from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of ExpandNameTestCases structs containing the template, scenario, seed, and expected name.
+func ReadExpandName(fileName string) []ExpandNameTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []ExpandNameTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+
+		seed, err := strconv.ParseInt(strings.TrimSpace(parts[3]), 10, 64)
+		nbody.Check(err)
+
+		tests = append(tests, ExpandNameTestCases{
+			id:       strings.TrimSpace(parts[0]),
+			template: strings.TrimSpace(parts[1]),
+			scenario: strings.TrimSpace(parts[2]),
+			seed:     seed,
+			expected: strings.TrimSpace(parts[4]),
+		})
+	}
+
+	return tests
+}
+
+
+// TestExpandName tests the ExpandName function using data from a file.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestExpandName(t *testing.T) {
+	tests := ReadExpandName("../../Tests/ExpandName.txt")
+
+	for _, test := range tests {
+		result := ExpandName(test.template, test.scenario, test.seed)
+
+		if result != test.expected {
+			t.Errorf("TestExpandName(test %v) = %v, want %v", test.id, result, test.expected)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: The "profile" command, which times a short segment of a run and reports where the time goes,
+// and the pprof profiles of a run (options "-cpuprofile" and "-memprofile").
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"BarnesHut/render"
+	"BarnesHut/scenarios"
+	"BarnesHut/simulation"
+	"flag"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"time"
+)
+
+// PhaseTimes holds the time measured for each phase of a profiled segment.
+type PhaseTimes struct {
+	treeBuild time.Duration
+	force     time.Duration
+	integrate time.Duration
+	render    time.Duration
+	encode    time.Duration
+
+	generations int
+	frames      int
+}
+
+
+// RunProfile is the entry point of the "profile" command.
+// It runs a few generations of a scenario with every phase timed, scales the times up to the full run,
+// and prints the share of each phase together with suggestions.
+// Input:
+//   - args: command line arguments following "profile".
+// Output:
+//   - None (the report is printed).
+func RunProfile(args []string) {
+	command := "galaxy"
+	if len(args) > 0 && len(args[0]) > 0 && args[0][0] != '-' {
+		command = args[0]
+		args = args[1:]
+	}
+
+	flags := flag.NewFlagSet("profile", flag.ExitOnError)
+	numGens := flags.Int("gens", 20, "number of generations to time")
+	numFrames := flags.Int("frames", 3, "number of frames to draw and encode")
+	theta := flags.Float64("theta", 0, "theta used for the segment (0 keeps the scenario's theta)")
+	flags.IntVar(&nbody.NumWorkers, "workers", nbody.NumWorkers, "number of goroutines used for encoding and parallel tree builds")
+	treeBuild := flags.String("tree-build", "serial", "quadtree construction: serial, partitioned, flat, or linear")
+	leafSize := flags.Int("leaf-size", 1, "largest number of stars in a leaf of the quadtree")
+	flags.Parse(args)
+
+	scenario, err := scenarios.LoadScenario(command)
+	CheckConfig(err)
+	if *theta > 0 {
+		scenario.Theta = *theta
+	}
+	options := scenario.Options().WithTreeBuild(*treeBuild).WithLeafSize(*leafSize)
+	nbody.Check(options.Validate())
+
+	fmt.Printf("Profiling %d generations and %d frames of %q (%d stars).\n",
+		*numGens, *numFrames, command, len(scenario.InitialUniverse.Stars))
+
+	times := ProfileSegment(scenario, options, *numGens, *numFrames)
+	PrintProfile(os.Stdout, times, scenario, options)
+}
+
+
+// ProfileSegment runs generations of a scenario and draws and encodes some of them, timing every phase.
+// The generations are computed like UpdateUniverse, but ComputeAccelerations and IntegrateUniverse are timed separately.
+// Input:
+//   - scenario: the Scenario to profile.
+//   - options: the Options of the run of the scenario.
+//   - numGens: number of generations to run.
+//   - numFrames: number of the generations to draw and encode (spread evenly over the segment).
+// Output:
+//   - the measured PhaseTimes.
+func ProfileSegment(scenario scenarios.Scenario, options simulation.Options, numGens, numFrames int) PhaseTimes {
+	var times PhaseTimes
+	times.generations = numGens
+
+	currentUniverse := nbody.CopyUniverse(scenario.InitialUniverse)
+	kept := []*nbody.Universe{currentUniverse}
+
+	// e.g. 3 frames of 20 generations are generations 0, 10, and 20
+	frameGap := numGens + 1
+	if numFrames > 1 {
+		frameGap = max(numGens/(numFrames-1), 1)
+	}
+
+	for i := 1; i <= numGens; i++ {
+		start := time.Now()
+		tree := quadtree.BuildQuadTree(currentUniverse, options.Build())
+		times.treeBuild += time.Since(start)
+
+		start = time.Now()
+		accelerations, err := simulation.ComputeAccelerations(currentUniverse, tree, options)
+		nbody.Check(err)
+		times.force += time.Since(start)
+
+		start = time.Now()
+		newUniverse := simulation.IntegrateUniverse(currentUniverse, accelerations, options)
+		times.integrate += time.Since(start)
+
+		currentUniverse = newUniverse
+		if i%frameGap == 0 && len(kept) < numFrames {
+			kept = append(kept, currentUniverse)
+		}
+	}
+
+	if numFrames <= 0 {
+		return times
+	}
+
+	start := time.Now()
+	images := make([]image.Image, len(kept))
+	for i, u := range kept {
+		images[i] = render.DrawToCanvas(u, scenario.CanvasWidth, scenario.ScalingFactor)
+	}
+	times.render = time.Since(start)
+	times.frames = len(images)
+
+	start = time.Now()
+	nbody.Check(render.EncodeGIF(io.Discard, render.PaletteFrames(images, 1), nbody.NumWorkers))
+	times.encode = time.Since(start)
+
+	return times
+}
+
+
+// PrintProfile prints the breakdown of a profiled segment, scaled up to the full run of the scenario,
+// followed by suggestions for the phases that dominate.
+// Input:
+//   - w: writer receiving the report.
+//   - times: PhaseTimes measured by ProfileSegment.
+//   - scenario: the profiled Scenario, whose numGens and frequency give the size of the full run.
+//   - options: the Options of the profiled run.
+// Output:
+//   - None.
+func PrintProfile(w io.Writer, times PhaseTimes, scenario scenarios.Scenario, options simulation.Options) {
+	// per generation phases scale with the number of generations, per frame phases with the number of frames
+	genScale := float64(scenario.NumGens) / float64(max(times.generations, 1))
+	frameScale := 0.0
+	if times.frames > 0 {
+		frameScale = float64(scenario.NumGens/scenario.Frequency+1) / float64(times.frames)
+	}
+
+	names := []string{"tree build", "force", "integrate", "render", "encode"}
+	measured := []time.Duration{times.treeBuild, times.force, times.integrate, times.render, times.encode}
+	scales := []float64{genScale, genScale, genScale, frameScale, frameScale}
+
+	estimated := make([]float64, len(names))
+	total := 0.0
+	for i := range names {
+		estimated[i] = measured[i].Seconds() * scales[i]
+		total += estimated[i]
+	}
+
+	fmt.Fprintf(w, "%-12s %12s %14s %7s\n", "phase", "measured", "full run (est)", "share")
+	share := make(map[string]float64)
+	for i, name := range names {
+		if total > 0 {
+			share[name] = estimated[i] / total
+		}
+		fmt.Fprintf(w, "%-12s %12v %13.1fs %6.1f%%\n", name, measured[i].Round(time.Microsecond), estimated[i], 100*share[name])
+	}
+	fmt.Fprintf(w, "%-12s %12s %13.1fs\n", "total", "", total)
+
+	suggestions := ProfileSuggestions(share, options, len(scenario.InitialUniverse.Stars))
+	if len(suggestions) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Suggestions:")
+	for _, s := range suggestions {
+		fmt.Fprintln(w, "  -", s)
+	}
+}
+
+
+// ProfileSuggestions turns the share of each phase into advice for speeding up the run.
+// Input:
+//   - share: fraction of the estimated full run spent in each phase, keyed by phase name.
+//   - options: the Options of the run (theta and tree build).
+//   - numStars: number of stars in the run.
+// Output:
+//   - slice of suggestions, possibly empty.
+func ProfileSuggestions(share map[string]float64, options simulation.Options, numStars int) []string {
+	var suggestions []string
+
+	theta := options.Theta
+	if share["force"] > 0.6 {
+		if theta < 0.5 {
+			suggestions = append(suggestions, fmt.Sprintf("theta is small (%g) and force dominates; a theta around 0.5-0.7 is usually accurate enough and much faster", theta))
+		} else {
+			suggestions = append(suggestions, "force dominates; fewer stars or a larger theta make every generation cheaper")
+		}
+	}
+
+	if share["tree build"] > 0.2 {
+		if options.TreeBuild == "serial" && nbody.NumWorkers > 1 {
+			suggestions = append(suggestions, "the tree build is significant; try -tree-build partitioned or -tree-build flat")
+		} else if numStars < 10000 {
+			suggestions = append(suggestions, "the tree build is significant for so few stars; the parallel builds only pay off for large N")
+		}
+	}
+
+	if share["integrate"] > 0.2 {
+		suggestions = append(suggestions, "integration, which copies the universe every generation, is significant; -max-mem keeps only the drawn generations")
+	}
+
+	if share["render"]+share["encode"] > 0.3 {
+		suggestions = append(suggestions, "drawing and encoding dominate; draw fewer frames (-adaptive-frames) or use -delta-gif or -format webp")
+	}
+
+	return suggestions
+}
+
+
+// StartProfiles starts the pprof profiles of a run, to be read with go tool pprof. The CPU profile samples the
+// whole run, and the heap profile records the memory in use and allocated when the run ends.
+// Input:
+//   - cpuFile: file receiving the CPU profile ("" for none).
+//   - memFile: file receiving the heap profile ("" for none).
+// Output:
+//   - the function that stops the CPU profile and writes the heap profile when the run ends, and an error if
+//     a profile cannot be started.
+func StartProfiles(cpuFile, memFile string) (func() error, error) {
+	var cpu *os.File
+	if cpuFile != "" {
+		var err error
+		cpu, err = os.Create(cpuFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := pprof.StartCPUProfile(cpu); err != nil {
+			cpu.Close()
+			return nil, err
+		}
+	}
+
+	return func() error {
+		if cpu != nil {
+			pprof.StopCPUProfile()
+			if err := cpu.Close(); err != nil {
+				return err
+			}
+		}
+		if memFile == "" {
+			return nil
+		}
+		mem, err := os.Create(memFile)
+		if err != nil {
+			return err
+		}
+		defer mem.Close()
+		// the heap profile is as of the last garbage collection
+		runtime.GC()
+		return pprof.WriteHeapProfile(mem)
+	}, nil
+}
@@ -0,0 +1,369 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the "profile" command in profile.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/scenarios"
+	"BarnesHut/simulation"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ProfileSegmentTestCases struct {
+	id        string
+	numGens   int
+	numFrames int
+	expected  int
+}
+
+type PrintProfileTestCases struct {
+	id          string
+	times       PhaseTimes
+	numGens     int
+	frequency   int
+	theta       float64
+	treeBuild   string
+	workers     int
+	numStars    int
+	estimated   []string
+	share       []string
+	suggestions []string
+}
+
+type StartProfilesTestCases struct {
+	id       string
+	cpuFile  string
+	memFile  string
+	expected string
+}
+
+
+// ReadProfileSegment reads test data for ProfileSegment from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of ProfileSegmentTestCases structs containing the segments and their expected number of frames.
+func ReadProfileSegment(fileName string) []ProfileSegmentTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []ProfileSegmentTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		values := ReadIntFields(strings.Join(parts[1:], "|"), "|")
+		tests = append(tests, ProfileSegmentTestCases{
+			id:        strings.TrimSpace(parts[0]),
+			numGens:   values[0],
+			numFrames: values[1],
+			expected:  values[2],
+		})
+	}
+
+	return tests
+}
+
+
+// TestProfileSegment tests that ProfileSegment times the requested generations, draws and encodes the expected
+// number of frames, and only times rendering and encoding when it draws frames.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestProfileSegment(t *testing.T) {
+	tests := ReadProfileSegment("../../Tests/ProfileSegment.txt")
+
+	nbody.SetDeterministic()
+	g := nbody.InitializeGalaxy(50, 4e21, 5e22, 5e22)
+	scenario := scenarios.Scenario{Width: 1e23, NumGens: 100, Time: 2e14, Theta: 0.5, CanvasWidth: 40, Frequency: 10,
+		ScalingFactor: 1e11, InitialUniverse: nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)}
+
+	for _, test := range tests {
+		times := ProfileSegment(scenario, scenario.Options(), test.numGens, test.numFrames)
+		if times.generations != test.numGens || times.frames != test.expected {
+			t.Errorf("TestProfileSegment(test %v) = %d generations and %d frames, want %d and %d",
+				test.id, times.generations, times.frames, test.numGens, test.expected)
+		}
+		if drawn := times.render > 0 && times.encode > 0; drawn != (test.expected > 0) {
+			t.Errorf("TestProfileSegment(test %v) timed rendering %v and encoding %v, want them timed: %v",
+				test.id, times.render, times.encode, test.expected > 0)
+		}
+		if computed := times.treeBuild > 0 && times.force > 0 && times.integrate > 0; computed != (test.numGens > 0) {
+			t.Errorf("TestProfileSegment(test %v) timed the tree build %v, force %v, and integration %v, want them timed: %v",
+				test.id, times.treeBuild, times.force, times.integrate, test.numGens > 0)
+		}
+	}
+}
+
+
+// ReadPrintProfile reads test data for PrintProfile from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of PrintProfileTestCases structs containing the measured times and the expected report.
+func ReadPrintProfile(fileName string) []PrintProfileTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []PrintProfileTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 8 {
+			continue
+		}
+
+		measured := ReadIntFields(strings.Join(strings.Fields(parts[1]), ","), ",")
+		segment := ReadIntFields(strings.Join(strings.Fields(parts[2]), ","), ",")
+		full := ReadIntFields(strings.Join(strings.Fields(parts[3]), ","), ",")
+		run := strings.Fields(parts[4])
+
+		test := PrintProfileTestCases{
+			id: strings.TrimSpace(parts[0]),
+			times: PhaseTimes{
+				treeBuild:   time.Duration(measured[0]) * time.Millisecond,
+				force:       time.Duration(measured[1]) * time.Millisecond,
+				integrate:   time.Duration(measured[2]) * time.Millisecond,
+				render:      time.Duration(measured[3]) * time.Millisecond,
+				encode:      time.Duration(measured[4]) * time.Millisecond,
+				generations: segment[0],
+				frames:      segment[1],
+			},
+			numGens:   full[0],
+			frequency: full[1],
+			treeBuild: run[1],
+			estimated: strings.Fields(parts[5]),
+			share:     strings.Fields(parts[6]),
+		}
+		test.theta, err = strconv.ParseFloat(run[0], 64)
+		nbody.Check(err)
+		test.workers, err = strconv.Atoi(run[2])
+		nbody.Check(err)
+		test.numStars, err = strconv.Atoi(run[3])
+		nbody.Check(err)
+		if suggestions := strings.TrimSpace(parts[7]); suggestions != "none" {
+			for _, s := range strings.Split(suggestions, ";") {
+				test.suggestions = append(test.suggestions, strings.TrimSpace(s))
+			}
+		}
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestPrintProfile tests that PrintProfile scales the measured times of every phase up to the full run, prints
+// their shares of the total, and prints the suggestions of ProfileSuggestions for the phases that dominate.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestPrintProfile(t *testing.T) {
+	tests := ReadPrintProfile("../../Tests/PrintProfile.txt")
+	oldWorkers := nbody.NumWorkers
+	defer func() {
+		nbody.NumWorkers = oldWorkers
+	}()
+
+	for _, test := range tests {
+		nbody.NumWorkers = test.workers
+		scenario := scenarios.Scenario{NumGens: test.numGens, Frequency: test.frequency, Theta: test.theta,
+			InitialUniverse: &nbody.Universe{Stars: make([]*nbody.Star, test.numStars)}}
+		options := simulation.DefaultOptions(test.numGens, 1, test.theta).WithTreeBuild(test.treeBuild)
+
+		var report bytes.Buffer
+		PrintProfile(&report, test.times, scenario, options)
+		lines := strings.Split(strings.TrimSuffix(report.String(), "\n"), "\n")
+
+		// the header, a line per phase, and the total
+		if len(lines) < 7 {
+			t.Errorf("TestPrintProfile(test %v) printed %d lines, want at least 7:\n%s", test.id, len(lines), report.String())
+			continue
+		}
+		for i, name := range []string{"tree build", "force", "integrate", "render", "encode"} {
+			fields := strings.Fields(strings.TrimPrefix(lines[i+1], name))
+			if !strings.HasPrefix(lines[i+1], name) || len(fields) != 3 {
+				t.Errorf("TestPrintProfile(test %v) line %q, want the %s phase", test.id, lines[i+1], name)
+				continue
+			}
+			estimated, share := strings.TrimSuffix(fields[1], "s"), strings.TrimSuffix(fields[2], "%")
+			if estimated != test.estimated[i] || share != test.share[i] {
+				t.Errorf("TestPrintProfile(test %v) %s = %ss and %s%%, want %ss and %s%%",
+					test.id, name, estimated, share, test.estimated[i], test.share[i])
+			}
+		}
+
+		suggestions := lines[7:]
+		if len(suggestions) > 0 {
+			suggestions = suggestions[1:]
+		}
+		if len(suggestions) != len(test.suggestions) {
+			t.Errorf("TestPrintProfile(test %v) suggests %q, want %d suggestions", test.id, suggestions, len(test.suggestions))
+			continue
+		}
+		for i, s := range suggestions {
+			if !strings.HasPrefix(s, "  - ") || !strings.Contains(s, test.suggestions[i]) {
+				t.Errorf("TestPrintProfile(test %v) suggestion %q, want one containing %q", test.id, s, test.suggestions[i])
+			}
+		}
+	}
+}
+
+
+// ReadStartProfiles reads test data for StartProfiles from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of StartProfilesTestCases structs containing the profile files and the expected results.
+func ReadStartProfiles(fileName string) []StartProfilesTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []StartProfilesTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		tests = append(tests, StartProfilesTestCases{
+			id:       strings.TrimSpace(parts[0]),
+			cpuFile:  strings.TrimSpace(parts[1]),
+			memFile:  strings.TrimSpace(parts[2]),
+			expected: strings.TrimSpace(parts[3]),
+		})
+	}
+
+	return tests
+}
+
+
+// profilePath returns the path of a profile file of the fixtures in dir: "" for none, and a file in a
+// subdirectory that does not exist for missing.
+func profilePath(dir, kind, name string) string {
+	switch kind {
+	case "file":
+		return filepath.Join(dir, name)
+	case "missing":
+		return filepath.Join(dir, "missing", name)
+	}
+	return ""
+}
+
+
+// readProfile returns the uncompressed contents of a pprof profile, and false if it is not gzipped.
+func readProfile(fileName string) ([]byte, bool) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+
+	r, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, false
+	}
+	data, err := io.ReadAll(r)
+	return data, err == nil
+}
+
+
+// TestStartProfiles tests that the profiles of -cpuprofile and -memprofile are written as pprof profiles of CPU
+// time and of the heap when the run ends, that files that cannot be created are errors when the profile starts or
+// ends, and that StartProfiles fails while another CPU profile is running.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestStartProfiles(t *testing.T) {
+	tests := ReadStartProfiles("../../Tests/StartProfiles.txt")
+
+	for _, test := range tests {
+		dir := t.TempDir()
+		cpuFile, memFile := profilePath(dir, test.cpuFile, "cpu.prof"), profilePath(dir, test.memFile, "mem.prof")
+
+		if test.expected == "busy" {
+			nbody.Check(pprof.StartCPUProfile(io.Discard))
+		}
+		stop, err := StartProfiles(cpuFile, memFile)
+		if test.expected == "busy" {
+			pprof.StopCPUProfile()
+		}
+		if (err != nil) != (test.expected == "start" || test.expected == "busy") {
+			t.Errorf("TestStartProfiles(test %v) = error %v when starting, want %s", test.id, err, test.expected)
+		}
+
+		if err == nil {
+			// a run long enough to be sampled
+			nbody.SetDeterministic()
+			g := nbody.InitializeGalaxy(200, 4e21, 5e22, 5e22)
+			simulation.BarnesHut(nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23), simulation.DefaultOptions(10, 2e14, 0.5))
+
+			if err := stop(); (err != nil) != (test.expected == "stop") {
+				t.Errorf("TestStartProfiles(test %v) = error %v when stopping, want %s", test.id, err, test.expected)
+			}
+		}
+
+		// the CPU profile was stopped
+		if err := pprof.StartCPUProfile(io.Discard); err != nil {
+			t.Errorf("TestStartProfiles(test %v) left the CPU profile running: %v", test.id, err)
+		} else {
+			pprof.StopCPUProfile()
+		}
+
+		if test.expected != "ok" {
+			continue
+		}
+		// the sample types of the profiles are in their string tables
+		for _, profile := range []struct{ file, sample string }{{cpuFile, "cpu"}, {memFile, "inuse_space"}} {
+			if profile.file == "" {
+				continue
+			}
+			if data, ok := readProfile(profile.file); !ok || !bytes.Contains(data, []byte(profile.sample)) {
+				t.Errorf("TestStartProfiles(test %v) %s is not a pprof profile with %q samples", test.id, filepath.Base(profile.file), profile.sample)
+			}
+		}
+	}
+}
+
+
+// ReadIntFields parses a list of integers separated by sep, such as "1,2,3".
+// Input: text (string) - the list; sep (string) - the separator.
+// Output: slice of the integers.
+func ReadIntFields(text, sep string) []int {
+	var values []int
+	for _, field := range strings.Split(text, sep) {
+		v, err := strconv.Atoi(strings.TrimSpace(field))
+		nbody.Check(err)
+		values = append(values, v)
+	}
+	return values
+}
@@ -0,0 +1,207 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Educational animation of how stars are inserted into the quadtree one by one.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"BarnesHut/render"
+	"canvas"
+	"flag"
+	"fmt"
+	"image"
+	"os"
+)
+
+// RunQuadTreeDemo is the entry point of the "quadtree" command.
+// It scatters a few stars in a square universe and animates their insertion into the quadtree.
+// Input:
+//   - args: command line arguments following "quadtree".
+// Output:
+//   - None (writes quadtree.out.gif).
+func RunQuadTreeDemo(args []string) {
+	flags := flag.NewFlagSet("quadtree", flag.ExitOnError)
+	numStars := flags.Int("stars", 20, "number of stars to insert")
+	canvasWidth := flags.Int("canvas", 600, "width of the square canvas in pixels")
+	hold := flags.Int("hold", 30, "number of GIF frames each insertion step is shown for")
+	deterministicRun := flags.Bool("deterministic", false, "fixed random seed, so repeated runs give identical output")
+	flags.Parse(args)
+
+	if *deterministicRun {
+		nbody.SetDeterministic()
+	}
+
+	width := 100.0
+	stars := make([]*nbody.Star, *numStars)
+	for i := range stars {
+		stars[i] = &nbody.Star{
+			Position: nbody.OrderedPair{X: nbody.RNG.Float64() * width, Y: nbody.RNG.Float64() * width},
+			Mass:     1.0,
+			Red:      255,
+			Green:    255,
+			Blue:     255,
+		}
+	}
+
+	steps := AnimateQuadTreeConstruction(stars, width, *canvasWidth)
+	fmt.Println("Drew", len(steps), "insertion steps. Now generating GIF.")
+
+	file, err := os.Create("quadtree.out.gif")
+	nbody.Check(err)
+	defer file.Close()
+
+	// repeat each step so the animation is slow enough to follow; the frames are encoded as they are written
+	stream := render.NewGIFStreamWriter(file, 1)
+	for _, img := range steps {
+		for i := 0; i < *hold; i++ {
+			nbody.Check(stream.WriteImage(img))
+		}
+	}
+	nbody.Check(stream.Close())
+	fmt.Println("GIF drawn.")
+}
+
+
+// AnimateQuadTreeConstruction inserts stars into an empty quadtree with one star per leaf, one at a time, and draws
+// the tree after every insertion.
+// Sectors created by the current insertion are highlighted in red, and the star just inserted is drawn in yellow.
+// A final frame marks the center of mass of every internal node in green.
+// Input:
+//   - stars: slice of Star pointers to insert, in order.
+//   - width: width of the square universe.
+//   - canvasWidth: width of the square canvas in pixels.
+// Output:
+//   - slice of images, one per inserted star plus the final center of mass frame.
+func AnimateQuadTreeConstruction(stars []*nbody.Star, width float64, canvasWidth int) []image.Image {
+	images := make([]image.Image, 0, len(stars)+1)
+	root := &quadtree.Node{Sector: quadtree.Quadrant{X: 0, Y: 0, Width: width}}
+	var inserted []*nbody.Star
+
+	for _, s := range stars {
+		if !nbody.IsInsideUniverse(s, width, width) {
+			continue
+		}
+
+		before := make(map[quadtree.Quadrant]bool)
+		for _, q := range CollectSectors(root) {
+			before[q] = true
+		}
+
+		quadtree.InsertStar(root, s, 0, 1)
+		inserted = append(inserted, s)
+
+		var newSectors []quadtree.Quadrant
+		for _, q := range CollectSectors(root) {
+			if !before[q] {
+				newSectors = append(newSectors, q)
+			}
+		}
+
+		images = append(images, DrawQuadTreeStep(root, inserted, s, newSectors, width, canvasWidth, false))
+	}
+
+	quadtree.ComputeCenterAndMass(root)
+	images = append(images, DrawQuadTreeStep(root, inserted, nil, nil, width, canvasWidth, true))
+
+	return images
+}
+
+
+// CollectSectors returns the sectors of every node in the subtree rooted at node.
+func CollectSectors(node *quadtree.Node) []quadtree.Quadrant {
+	if node == nil {
+		return nil
+	}
+
+	sectors := []quadtree.Quadrant{node.Sector}
+	for _, child := range node.Children {
+		sectors = append(sectors, CollectSectors(child)...)
+	}
+	return sectors
+}
+
+
+// DrawQuadTreeStep draws the sectors and stars of a partially built quadtree on a square canvas.
+// Input:
+//   - root: root of the quadtree.
+//   - stars: stars inserted so far.
+//   - current: the star inserted in this step (may be nil).
+//   - newSectors: sectors created in this step, highlighted in red.
+//   - width: width of the universe.
+//   - canvasWidth: width of the canvas in pixels.
+//   - showCenters: whether to mark the center of mass of internal nodes.
+// Output:
+//   - the drawn image.
+func DrawQuadTreeStep(root *quadtree.Node, stars []*nbody.Star, current *nbody.Star, newSectors []quadtree.Quadrant, width float64, canvasWidth int, showCenters bool) image.Image {
+	c := canvas.CreateNewCanvas(canvasWidth, canvasWidth)
+	scale := float64(canvasWidth) / width
+
+	c.SetFillColor(canvas.MakeColor(0, 0, 0))
+	c.ClearRect(0, 0, canvasWidth, canvasWidth)
+	c.Fill()
+
+	// every sector of the tree in gray
+	c.SetLineWidth(1)
+	c.SetStrokeColor(canvas.MakeColor(90, 90, 90))
+	for _, q := range CollectSectors(root) {
+		DrawSector(&c, q, scale)
+	}
+
+	// sectors created by this insertion in red
+	c.SetLineWidth(2)
+	c.SetStrokeColor(canvas.MakeColor(255, 60, 60))
+	for _, q := range newSectors {
+		DrawSector(&c, q, scale)
+	}
+
+	starRadius := float64(canvasWidth) / 120.0
+	for _, s := range stars {
+		if s == current {
+			c.SetFillColor(canvas.MakeColor(255, 255, 0))
+		} else {
+			c.SetFillColor(canvas.MakeColor(s.Red, s.Green, s.Blue))
+		}
+		c.Circle(s.Position.X*scale, s.Position.Y*scale, starRadius)
+		c.Fill()
+	}
+
+	if showCenters {
+		DrawCenters(&c, root, scale, starRadius/2.0)
+	}
+
+	return c.GetImage()
+}
+
+
+// DrawSector strokes the outline of a quadrant on the canvas.
+func DrawSector(c *canvas.Canvas, q quadtree.Quadrant, scale float64) {
+	x0, y0 := q.X*scale, q.Y*scale
+	x1, y1 := (q.X+q.Width)*scale, (q.Y+q.Width)*scale
+
+	c.MoveTo(x0, y0)
+	c.LineTo(x1, y0)
+	c.LineTo(x1, y1)
+	c.LineTo(x0, y1)
+	c.LineTo(x0, y0)
+	c.Stroke()
+}
+
+
+// DrawCenters recursively marks the center of mass (the dummy star) of every internal node in green.
+func DrawCenters(c *canvas.Canvas, node *quadtree.Node, scale, r float64) {
+	if node == nil || quadtree.IsLeaf(node) {
+		return
+	}
+
+	if node.Star != nil {
+		c.SetFillColor(canvas.MakeColor(0, 255, 0))
+		c.Circle(node.Star.Position.X*scale, node.Star.Position.Y*scale, r)
+		c.Fill()
+	}
+
+	for _, child := range node.Children {
+		DrawCenters(c, child, scale, r)
+	}
+}
@@ -0,0 +1,150 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the animation of the quadtree construction in quadtree_demo.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"bufio"
+	"image"
+	"image/color"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type QuadTreeConstructionTestCases struct {
+	id          string
+	canvasWidth int
+	stars       []*nbody.Star
+	numFrames   int
+	highlighted []int
+	numSectors  int
+	centers     bool
+}
+
+
+// ReadQuadTreeConstruction reads test data for AnimateQuadTreeConstruction from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of QuadTreeConstructionTestCases structs containing the stars and the expected animation.
+func ReadQuadTreeConstruction(fileName string) []QuadTreeConstructionTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []QuadTreeConstructionTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 7 {
+			continue
+		}
+
+		test := QuadTreeConstructionTestCases{id: strings.TrimSpace(parts[0])}
+		test.canvasWidth, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		if stars := strings.TrimSpace(parts[2]); stars != "none" {
+			for _, field := range strings.Split(stars, ";") {
+				position := strings.Split(field, ",")
+				x, err := strconv.ParseFloat(strings.TrimSpace(position[0]), 64)
+				nbody.Check(err)
+				y, err := strconv.ParseFloat(strings.TrimSpace(position[1]), 64)
+				nbody.Check(err)
+				test.stars = append(test.stars, &nbody.Star{Position: nbody.OrderedPair{X: x, Y: y}, Mass: 1, Red: 255, Green: 255, Blue: 255})
+			}
+		}
+		test.numFrames, err = strconv.Atoi(strings.TrimSpace(parts[3]))
+		nbody.Check(err)
+		if highlighted := strings.TrimSpace(parts[4]); highlighted != "none" {
+			test.highlighted = ReadIntFields(strings.Join(strings.Fields(highlighted), ","), ",")
+		}
+		test.numSectors, err = strconv.Atoi(strings.TrimSpace(parts[5]))
+		nbody.Check(err)
+		test.centers, err = strconv.ParseBool(strings.TrimSpace(parts[6]))
+		nbody.Check(err)
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// hasColor reports whether any pixel of img is exactly the color c.
+func hasColor(img image.Image, c color.RGBA) bool {
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if color.RGBAModel.Convert(img.At(x, y)) == c {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+
+// TestAnimateQuadTreeConstruction tests that the animation of the quadtree construction has one frame per star
+// inside the universe plus the final one, that every insertion frame shows the inserted star in yellow, that only
+// the insertions subdividing a sector highlight new sectors in red, and that the final frame marks the centers of
+// mass of the internal nodes in green. It also tests that CollectSectors returns every sector of the final tree.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestAnimateQuadTreeConstruction(t *testing.T) {
+	tests := ReadQuadTreeConstruction("../../Tests/QuadTreeConstruction.txt")
+	yellow, red, green := color.RGBA{255, 255, 0, 255}, color.RGBA{255, 60, 60, 255}, color.RGBA{0, 255, 0, 255}
+
+	for _, test := range tests {
+		images := AnimateQuadTreeConstruction(test.stars, 100, test.canvasWidth)
+		if len(images) != test.numFrames {
+			t.Errorf("TestAnimateQuadTreeConstruction(test %v) drew %d frames, want %d", test.id, len(images), test.numFrames)
+			continue
+		}
+
+		var highlighted []int
+		for i, img := range images {
+			if img.Bounds() != image.Rect(0, 0, test.canvasWidth, test.canvasWidth) {
+				t.Errorf("TestAnimateQuadTreeConstruction(test %v, frame %d) is %v, want a %d pixel square",
+					test.id, i, img.Bounds(), test.canvasWidth)
+			}
+			final := i == len(images)-1
+			if hasColor(img, yellow) == final {
+				t.Errorf("TestAnimateQuadTreeConstruction(test %v, frame %d) shows an inserted star: %v, want %v",
+					test.id, i, !final, final)
+			}
+			if hasColor(img, red) {
+				highlighted = append(highlighted, i)
+			}
+			if final && hasColor(img, green) != test.centers {
+				t.Errorf("TestAnimateQuadTreeConstruction(test %v) marks centers of mass: %v, want %v",
+					test.id, !test.centers, test.centers)
+			}
+		}
+		if !reflect.DeepEqual(highlighted, test.highlighted) {
+			t.Errorf("TestAnimateQuadTreeConstruction(test %v) highlights new sectors in frames %v, want %v",
+				test.id, highlighted, test.highlighted)
+		}
+
+		// the tree the animation built, with the stars inside the universe
+		root := &quadtree.Node{Sector: quadtree.Quadrant{X: 0, Y: 0, Width: 100}}
+		for _, s := range test.stars {
+			if nbody.IsInsideUniverse(s, 100, 100) {
+				quadtree.InsertStar(root, s, 0, 1)
+			}
+		}
+		if sectors := CollectSectors(root); len(sectors) != test.numSectors || sectors[0] != root.Sector {
+			t.Errorf("TestAnimateQuadTreeConstruction(test %v) has %d sectors starting with %v, want %d starting with the root",
+				test.id, len(sectors), sectors[0], test.numSectors)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Drawing saved snapshots as an animation without re-simulating (command "render"), and the
+// drawing options the simulate command leaves next to its snapshots for it.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/render"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// drawingFileName is the file of the DrawingDefaults in a snapshot directory.
+const drawingFileName = "drawing.json"
+
+// DrawingDefaults are the drawing options of the run that wrote a snapshot directory, so render draws the
+// snapshots as the run would have unless it is given other options. The scaling factors of the scenarios
+// differ by orders of magnitude, so a directory without them is drawn with the sizes of the stars only.
+type DrawingDefaults struct {
+	Scenario string  `json:"scenario"`
+	Seed     int64   `json:"seed"`
+	Canvas   int     `json:"canvas"`
+	Scale    float64 `json:"scale"`
+}
+
+
+// WriteDrawingDefaults writes the drawing options of a run into its snapshot directory.
+// Input:
+//   - dir: the snapshot directory.
+//   - defaults: the drawing options of the run.
+// Output:
+//   - an error if the file cannot be written.
+func WriteDrawingDefaults(dir string, defaults DrawingDefaults) error {
+	file, err := os.Create(filepath.Join(dir, drawingFileName))
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	err = json.NewEncoder(w).Encode(defaults)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+
+// ReadDrawingDefaults reads the drawing options written by WriteDrawingDefaults.
+// Input:
+//   - dir: the snapshot directory.
+// Output:
+//   - the drawing options, a canvas of 1000 pixels and a scaling factor of 1 for a directory without them, and
+//     an error if the file cannot be read or its options are not positive.
+func ReadDrawingDefaults(dir string) (DrawingDefaults, error) {
+	defaults := DrawingDefaults{Canvas: 1000, Scale: 1}
+
+	file, err := os.Open(filepath.Join(dir, drawingFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return defaults, nil
+	}
+	if err != nil {
+		return DrawingDefaults{}, err
+	}
+	defer file.Close()
+
+	if err := json.NewDecoder(bufio.NewReader(file)).Decode(&defaults); err != nil {
+		return DrawingDefaults{}, fmt.Errorf("%s: %w", file.Name(), err)
+	}
+	if defaults.Canvas <= 0 || defaults.Scale <= 0 {
+		return DrawingDefaults{}, fmt.Errorf("%s: needs a positive canvas and scale", file.Name())
+	}
+	return defaults, nil
+}
+
+
+// ApplySimulateFlags checks the options of the simulate command and makes JSON its snapshot format unless
+// -snapshot-format is given, since JSON snapshots keep every field of the stars (galaxies, ages, the simulated
+// time) for render and analyze.
+// Input:
+//   - flags: the parsed options of the run.
+// Output:
+//   - an error if an option draws the run, or the snapshot format is one render and analyze cannot read.
+func ApplySimulateFlags(flags *flag.FlagSet) error {
+	var err error
+	formatGiven := false
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "snapshot-format":
+			formatGiven = true
+		case "live", "stream", "format", "png-frames", "delta-gif":
+			if err == nil {
+				err = fmt.Errorf("simulate only saves snapshots, so -%s does not apply: draw them with render, or run without simulate", f.Name)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if !formatGiven {
+		snapshotFormat = "json"
+	}
+	if snapshotFormat == "hdf5" {
+		return fmt.Errorf("simulate needs snapshots render and analyze can read: use -snapshot-format text, json, or tipsy")
+	}
+	return nil
+}
+
+
+// RunRender is the entry point of the "render" command.
+// It draws every snapshot of a directory, e.g. one written by simulate, as an animation, so the visuals of a run
+// can be changed without running the physics again.
+// Input:
+//   - args: command line arguments following "render".
+// Output:
+//   - None (the animation is written to disk).
+func RunRender(args []string) {
+	flags := flag.NewFlagSet("render", flag.ExitOnError)
+	dir := flags.String("in", "snapshots", "directory containing snapshot files")
+	outDir := flags.String("out-dir", ".", "directory receiving the animation")
+	nameTemplate := flags.String("name", "galaxy", "name of the output files; {scenario} and {seed} are replaced by those of the run")
+	canvasWidth := flags.Int("canvas", 0, "width of the frames in pixels (default: the run's)")
+	scalingFactor := flags.Float64("scale", 0, "scaling factor of the star discs (default: the run's)")
+	format := flags.String("format", "gif", "animation format: gif, webp, or png (numbered PNG frames only, in -png-frames or name.frames)")
+	pngFrames := flags.String("png-frames", "", "directory receiving every frame as a numbered PNG file, in addition to the animation (relative to -out-dir)")
+	deltaGIF := flags.Bool("delta-gif", false, "store only the changed pixels of each GIF frame (much smaller files)")
+	fps := flags.Float64("fps", 0, "frames per second of the animation (0 for the shortest delay of the format, as a run)")
+	every := flags.Int("every", 1, "draw every n-th snapshot")
+	flags.IntVar(&render.GIFColors, "gif-colors", render.GIFColors, "largest number of colors of a GIF frame palette (2 to 256)")
+	flags.StringVar(&render.GIFQuantizer, "gif-quantizer", render.GIFQuantizer, "GIF palettes: auto (exact colors or Plan9), median-cut (adapted to each frame), plan9, or websafe")
+	flags.BoolVar(&render.GIFDither, "gif-dither", render.GIFDither, "dither GIF frames with Floyd-Steinberg error diffusion")
+	flags.StringVar(&render.Brightness, "brightness", render.Brightness, "drawing of the stars: solid discs, or added up per pixel and shown linear, log, or asinh")
+	flags.StringVar(&render.Camera, "camera", render.Camera, "part of the universe drawn: fixed (all of it) or auto (centered on the center of mass and zoomed to the stars)")
+	overlayList := flags.String("overlays", "", "annotations drawn on every frame, a comma separated list of time, scale, and legend")
+	trackStar := flags.Int("track-star", -1, "ID of a star, its index in the first snapshot, kept at the center of every frame")
+	trackGalaxy := flags.Int("track-galaxy", -1, "galaxy whose center of mass is kept at the center of every frame (0 for the first)")
+	flags.Float64Var(&render.AgeColorTime, "age-colors", render.AgeColorTime, "color stars by age, from blue when created to red at this age in seconds (0 keeps their colors)")
+	colorOrigin := flags.Bool("color-origin", false, "color the stars of every galaxy differently, by the galaxy they are in in the first snapshot")
+	flags.IntVar(&nbody.NumWorkers, "workers", nbody.NumWorkers, "number of goroutines drawing and encoding the frames")
+	flags.Parse(args)
+
+	if *format != "gif" && *format != "webp" && *format != "png" {
+		CheckConfig(fmt.Errorf("unknown format %q (use gif, webp, or png)", *format))
+	}
+	if *canvasWidth < 0 || *scalingFactor < 0 || *fps < 0 {
+		CheckConfig(fmt.Errorf("-canvas, -scale, and -fps must not be negative"))
+	}
+	if *every < 1 {
+		CheckConfig(fmt.Errorf("-every must be at least 1, got %d", *every))
+	}
+	CheckConfig(render.CheckGIFOptions(render.GIFColors, render.GIFQuantizer))
+	CheckConfig(render.CheckBrightness(render.Brightness))
+	CheckConfig(render.CheckCamera(render.Camera))
+	CheckConfig(render.CheckAgeColors(render.AgeColorTime))
+	var err error
+	render.SelectedOverlays, err = render.ParseOverlays(*overlayList)
+	CheckConfig(err)
+
+	defaults, err := ReadDrawingDefaults(*dir)
+	CheckConfig(err)
+	if *canvasWidth == 0 {
+		*canvasWidth = defaults.Canvas
+	}
+	if *scalingFactor == 0 {
+		*scalingFactor = defaults.Scale
+	}
+
+	universes, _, err := nbody.ReadSnapshotDir(*dir)
+	CheckConfig(err)
+	fmt.Println("Loaded", len(universes), "snapshots from", *dir)
+
+	// colors and targets come from the first snapshot and follow the stars by their IDs
+	if *colorOrigin {
+		render.LegendGalaxies = ColorSnapshotsByOrigin(universes)
+	}
+	render.CurrentTrack, err = render.NewCameraTrack(universes[0], *trackStar, *trackGalaxy)
+	CheckConfig(err)
+
+	paths, err := NewOutputPaths(*outDir, *nameTemplate, defaults.Scenario, defaults.Seed)
+	nbody.Check(err)
+	output := render.AnimationOutput{Format: *format, Delta: *deltaGIF, FileName: paths.File(""), FPS: *fps}
+	if *pngFrames != "" {
+		output.PNGDir = paths.Dir(*pngFrames)
+	} else if *format == "png" {
+		output.PNGDir = paths.File(".frames")
+	}
+	if output.PNGDir != "" {
+		nbody.Check(os.MkdirAll(output.PNGDir, 0755))
+	}
+
+	indices := ReplayIndices(len(universes), *every)
+	nbody.Check(render.DrawAnimation(universes, indices, *canvasWidth, *scalingFactor, output))
+	fmt.Println("Animation written to", output.Path())
+}
+
+
+// ReplayIndices returns the snapshots render draws: every n-th one from the first, and the last one, so the
+// animation always shows how the run ends.
+// Input:
+//   - numSnapshots: number of snapshots, at least 1.
+//   - every: n, at least 1.
+// Output:
+//   - the indices of the drawn snapshots, in order.
+func ReplayIndices(numSnapshots, every int) []int {
+	indices := make([]int, 0, numSnapshots/every+1)
+	for i := 0; i < numSnapshots; i += every {
+		indices = append(indices, i)
+	}
+	if indices[len(indices)-1] != numSnapshots-1 {
+		indices = append(indices, numSnapshots-1)
+	}
+	return indices
+}
+
+
+// ColorSnapshotsByOrigin colors the stars of every snapshot of a run with the color of the galaxy they are in
+// in the first snapshot (see ColorByOrigin), following the stars by their IDs, so every star keeps its color
+// in every frame as in a run with -color-origin. Stars that are not in the first snapshot keep their colors.
+// Input:
+//   - universes: the snapshots, in order, at least one.
+// Output:
+//   - the number of galaxies, for the legend of the frames.
+func ColorSnapshotsByOrigin(universes []*nbody.Universe) int {
+	first := universes[0]
+	nbody.ColorByOrigin(first)
+	_, numGalaxies := nbody.TagOrigins(first)
+
+	colors := make(map[int64][3]uint8, len(first.Stars))
+	for _, s := range first.Stars {
+		colors[s.ID] = [3]uint8{s.Red, s.Green, s.Blue}
+	}
+	for _, u := range universes[1:] {
+		for _, s := range u.Stars {
+			if c, ok := colors[s.ID]; ok {
+				s.Red, s.Green, s.Blue = c[0], c[1], c[2]
+			}
+		}
+	}
+	return numGalaxies
+}
@@ -0,0 +1,311 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for rendering saved snapshots in replay.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/render"
+	"BarnesHut/simulation"
+	"bufio"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type RenderSnapshotsTestCases struct {
+	id        string
+	numStars  int
+	numGens   int
+	frequency int
+	format    string
+	defaults  DrawingDefaults
+}
+
+
+// ReadRenderSnapshots reads test data for the simulate and render commands from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of RenderSnapshotsTestCases structs containing the runs, snapshot formats, and drawing options.
+func ReadRenderSnapshots(fileName string) []RenderSnapshotsTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []RenderSnapshotsTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 6 {
+			continue
+		}
+
+		var nums [3]int
+		for i, part := range parts[1:4] {
+			nums[i], err = strconv.Atoi(strings.TrimSpace(part))
+			nbody.Check(err)
+		}
+		drawing := strings.Fields(parts[5])
+		canvas, err := strconv.Atoi(drawing[0])
+		nbody.Check(err)
+		scale, err := strconv.ParseFloat(drawing[1], 64)
+		nbody.Check(err)
+
+		tests = append(tests, RenderSnapshotsTestCases{
+			id:        strings.TrimSpace(parts[0]),
+			numStars:  nums[0],
+			numGens:   nums[1],
+			frequency: nums[2],
+			format:    strings.TrimSpace(parts[4]),
+			defaults:  DrawingDefaults{Scenario: "galaxy", Seed: int64(nums[0]), Canvas: canvas, Scale: scale},
+		})
+	}
+
+	return tests
+}
+
+
+// TestRenderSnapshots tests that the frames drawn from the snapshots of a simulated run, with the drawing options
+// saved next to them, are the frames of the run drawn directly.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestRenderSnapshots(t *testing.T) {
+	tests := ReadRenderSnapshots("../../Tests/RenderSnapshots.txt")
+
+	oldFormat, oldStatus := snapshotFormat, nbody.StatusOutput
+	nbody.StatusOutput = io.Discard
+	defer func() {
+		snapshotFormat, nbody.StatusOutput = oldFormat, oldStatus
+	}()
+
+	for _, test := range tests {
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		u := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+		options := simulation.DefaultOptions(test.numGens, 2e14, 0.5)
+
+		timePoints := simulation.BarnesHut(u, options)
+		expected := render.AnimateFrames(timePoints, render.FrameIndices(len(timePoints), test.frequency), test.defaults.Canvas, test.defaults.Scale)
+
+		// simulate
+		snapshotFormat = test.format
+		dir := t.TempDir()
+		nbody.Check(WriteDrawingDefaults(dir, test.defaults))
+		selector := &render.FrameSelector{Frequency: test.frequency, FinalGeneration: test.numGens}
+		numSnapshots := SimulateSnapshots(u, options, selector, dir, nil)
+		if numSnapshots != len(expected) {
+			t.Errorf("TestRenderSnapshots(test %v) saved %d snapshots, want %d", test.id, numSnapshots, len(expected))
+		}
+
+		// render
+		defaults, err := ReadDrawingDefaults(dir)
+		if err != nil || defaults != test.defaults {
+			t.Errorf("TestRenderSnapshots(test %v) read drawing options %+v, %v, want %+v", test.id, defaults, err, test.defaults)
+			continue
+		}
+		universes, _, err := nbody.ReadSnapshotDir(dir)
+		if err != nil {
+			t.Errorf("TestRenderSnapshots(test %v) error = %v, want nil", test.id, err)
+			continue
+		}
+		indices := make([]int, len(universes))
+		for i := range indices {
+			indices[i] = i
+		}
+		images := render.AnimateFrames(universes, indices, defaults.Canvas, defaults.Scale)
+
+		if len(images) != len(expected) {
+			t.Errorf("TestRenderSnapshots(test %v) drew %d frames, want %d", test.id, len(images), len(expected))
+			continue
+		}
+		for i := range images {
+			if !reflect.DeepEqual(images[i], expected[i]) {
+				t.Errorf("TestRenderSnapshots(test %v) frame %d differs from the frame of the run", test.id, i)
+			}
+		}
+	}
+}
+
+
+type ReplayIndicesTestCases struct {
+	id           string
+	numSnapshots int
+	every        int
+	expected     []int
+}
+
+
+// ReadReplayIndices reads test data for the ReplayIndices function from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of ReplayIndicesTestCases structs containing the numbers of snapshots, intervals, and expected indices.
+func ReadReplayIndices(fileName string) []ReplayIndicesTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []ReplayIndicesTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 3 {
+			continue
+		}
+
+		inputs := strings.Fields(parts[1])
+		numSnapshots, err := strconv.Atoi(inputs[0])
+		nbody.Check(err)
+		every, err := strconv.Atoi(inputs[1])
+		nbody.Check(err)
+
+		var expected []int
+		for _, field := range strings.Fields(parts[2]) {
+			index, err := strconv.Atoi(field)
+			nbody.Check(err)
+			expected = append(expected, index)
+		}
+
+		tests = append(tests, ReplayIndicesTestCases{
+			id:           strings.TrimSpace(parts[0]),
+			numSnapshots: numSnapshots,
+			every:        every,
+			expected:     expected,
+		})
+	}
+
+	return tests
+}
+
+
+// TestReplayIndices tests that render draws every n-th snapshot and the last one.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestReplayIndices(t *testing.T) {
+	tests := ReadReplayIndices("../../Tests/ReplayIndices.txt")
+
+	for _, test := range tests {
+		result := ReplayIndices(test.numSnapshots, test.every)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("TestReplayIndices(test %v) = %v, want %v", test.id, result, test.expected)
+		}
+	}
+}
+
+
+type ColorSnapshotsByOriginTestCases struct {
+	id       string
+	numStars []int
+	laterIDs []int64
+	expected []string
+}
+
+
+// ReadColorSnapshotsByOrigin reads test data for the ColorSnapshotsByOrigin function from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of ColorSnapshotsByOriginTestCases structs containing the galaxies, the stars of the later
+// snapshot, and their expected galaxies.
+func ReadColorSnapshotsByOrigin(fileName string) []ColorSnapshotsByOriginTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []ColorSnapshotsByOriginTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		var numStars []int
+		for _, field := range strings.Fields(parts[1]) {
+			n, err := strconv.Atoi(field)
+			nbody.Check(err)
+			numStars = append(numStars, n)
+		}
+		var laterIDs []int64
+		for _, field := range strings.Fields(parts[2]) {
+			id, err := strconv.ParseInt(field, 10, 64)
+			nbody.Check(err)
+			laterIDs = append(laterIDs, id)
+		}
+
+		tests = append(tests, ColorSnapshotsByOriginTestCases{
+			id:       strings.TrimSpace(parts[0]),
+			numStars: numStars,
+			laterIDs: laterIDs,
+			expected: strings.Fields(parts[3]),
+		})
+	}
+
+	return tests
+}
+
+
+// TestColorSnapshotsByOrigin tests that the stars of later snapshots get the color of their galaxy in the first
+// snapshot by their IDs, whatever their order, and that black holes and new stars keep their colors.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestColorSnapshotsByOrigin(t *testing.T) {
+	tests := ReadColorSnapshotsByOrigin("../../Tests/ColorSnapshotsByOrigin.txt")
+
+	for _, test := range tests {
+		var galaxies []nbody.Galaxy
+		for g, n := range test.numStars {
+			galaxies = append(galaxies, nbody.InitializeGalaxy(n, 4e21, float64(2+3*g)*1e22, 5e22))
+		}
+		first := nbody.InitializeUniverse(galaxies, 1e23)
+
+		// the later snapshot has copies of some stars of the first one, before it is colored
+		original := nbody.CopyUniverse(first)
+		later := nbody.NewUniverse(first.Width)
+		for _, id := range test.laterIDs {
+			if index := original.StarIndex(id); index >= 0 {
+				later.Stars = append(later.Stars, original.Stars[index])
+				continue
+			}
+			s := nbody.NewStar(nbody.OrderedPair{X: 5e22, Y: 5e22}, nbody.OrderedPair{}, 1, 1)
+			s.ID, s.Red, s.Green, s.Blue = id, 1, 2, 3
+			later.Stars = append(later.Stars, s)
+		}
+		colors := make([][3]uint8, len(later.Stars))
+		for i, s := range later.Stars {
+			colors[i] = [3]uint8{s.Red, s.Green, s.Blue}
+		}
+
+		if numGalaxies := ColorSnapshotsByOrigin([]*nbody.Universe{first, later}); numGalaxies != len(test.numStars) {
+			t.Errorf("TestColorSnapshotsByOrigin(test %v) found %d galaxies, want %d", test.id, numGalaxies, len(test.numStars))
+		}
+
+		for i, s := range later.Stars {
+			want := colors[i]
+			if g, err := strconv.Atoi(test.expected[i]); err == nil {
+				c := nbody.OriginColors[g%len(nbody.OriginColors)]
+				want = [3]uint8{c.R, c.G, c.B}
+			}
+			if got := [3]uint8{s.Red, s.Green, s.Blue}; got != want {
+				t.Errorf("TestColorSnapshotsByOrigin(test %v) star %d has color %v, want %v", test.id, s.ID, got, want)
+			}
+		}
+	}
+}
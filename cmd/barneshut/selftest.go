@@ -0,0 +1,262 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: The "selftest" command, which runs short canonical problems and checks the measured errors.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/simulation"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// A SelfTest is one canonical problem of the "selftest" command.
+// run returns the measured error, which must not exceed tolerance, computing the forces with the tree and force
+// settings of the given Options (see Options.WithForceSettings).
+type SelfTest struct {
+	name      string
+	tolerance float64
+	run       func(settings simulation.Options) float64
+}
+
+
+// SelfTests returns the problems run by the "selftest" command:
+//   - a circular two-body orbit, which must close after one period and conserve its energy.
+//   - a Plummer model in virial equilibrium, whose half-mass radius must stay put for three crossing times.
+//   - two Plummer models colliding head-on, which must conserve the total momentum. Forces from accepted
+//     tree nodes are not exactly equal and opposite, so a small drift is allowed.
+func SelfTests() []SelfTest {
+	return []SelfTest{
+		{name: "two-body orbit closes (position error / separation)", tolerance: 1e-3, run: TwoBodyOrbitError},
+		{name: "two-body energy (relative drift)", tolerance: 1e-4, run: TwoBodyEnergyError},
+		{name: "Plummer model stays in equilibrium (half-mass radius change)", tolerance: 0.25, run: PlummerRadiusChange},
+		{name: "collision conserves momentum (drift / sum of m|v|)", tolerance: 5e-3, run: CollisionMomentumError},
+	}
+}
+
+
+// RunSelfTest is the entry point of the "selftest" command.
+// It runs every SelfTest in deterministic mode, prints the measured errors, and exits with status 1
+// if any test failed.
+// Input:
+//   - args: command line arguments following "selftest".
+// Output:
+//   - None (the report is printed).
+func RunSelfTest(args []string) {
+	flags := flag.NewFlagSet("selftest", flag.ExitOnError)
+	flags.IntVar(&nbody.NumWorkers, "workers", nbody.NumWorkers, "number of goroutines used for forces and parallel tree builds")
+	treeBuild := flags.String("tree-build", "serial", "quadtree construction: serial, partitioned, flat, or linear")
+	mac := flags.String("mac", "bh", "opening criterion of the force walk: bh (s/d < theta), bmax, or salmon-warren")
+	macTolerance := flags.Float64("mac-tolerance", 0, "acceleration error in m/s^2 accepted per node by -mac salmon-warren")
+	flags.Parse(args)
+
+	settings := simulation.DefaultOptions(0, 1, 0).WithTreeBuild(*treeBuild).WithMAC(*mac, *macTolerance)
+	nbody.Check(settings.Validate())
+	nbody.SetDeterministic()
+
+	if !PrintSelfTests(os.Stdout, SelfTests(), settings) {
+		os.Exit(1)
+	}
+}
+
+
+// PrintSelfTests runs the tests with the tree and force settings of settings and writes one line per test with the
+// measured error, the tolerance, and the verdict.
+// Output:
+//   - true if every test passed.
+func PrintSelfTests(w io.Writer, tests []SelfTest, settings simulation.Options) bool {
+	passed := 0
+	for _, test := range tests {
+		measured := test.run(settings)
+		verdict := "FAIL"
+		if measured <= test.tolerance {
+			verdict = "PASS"
+			passed++
+		}
+		fmt.Fprintf(w, "%-4s %-62s error %.3e (tolerance %.2g)\n", verdict, test.name, measured, test.tolerance)
+	}
+
+	fmt.Fprintf(w, "%d of %d self-tests passed.\n", passed, len(tests))
+	return passed == len(tests)
+}
+
+
+
+
+//// Two-body orbit ////
+
+// twoBodyOrbit returns two suns one AU apart on a circular orbit around their center of mass,
+// the Options of one orbital period in 2000 steps, and the period.
+func twoBodyOrbit() (*nbody.Universe, simulation.Options) {
+	const au = 1.495978707e11
+	u := nbody.NewUniverse(4 * au)
+
+	// each star moves at half the relative speed sqrt(G (m1 + m2) / d)
+	v := 0.5 * math.Sqrt(nbody.G*2*nbody.SolarMass/au)
+	u.Stars = append(u.Stars,
+		nbody.NewStar(nbody.OrderedPair{X: 1.5 * au, Y: 2 * au}, nbody.OrderedPair{X: 0, Y: -v}, nbody.SolarMass, 696340000),
+		nbody.NewStar(nbody.OrderedPair{X: 2.5 * au, Y: 2 * au}, nbody.OrderedPair{X: 0, Y: v}, nbody.SolarMass, 696340000))
+
+	period := 2 * math.Pi * math.Sqrt(au*au*au/(nbody.G*2*nbody.SolarMass))
+	const steps = 2000
+	return u, simulation.DefaultOptions(steps, period/steps, 0.5)
+}
+
+
+// TwoBodyOrbitError runs the two-body orbit for one period and returns how far the first star is from
+// its starting point, relative to the separation.
+func TwoBodyOrbitError(settings simulation.Options) float64 {
+	u, options := twoBodyOrbit()
+	options = options.WithForceSettings(settings)
+	timePoints := simulation.BarnesHut(u, options)
+
+	first, last := timePoints[0].Stars[0], timePoints[options.NumGens].Stars[0]
+	_, _, d := nbody.Distance(first.Position, last.Position)
+	_, _, separation := nbody.Distance(timePoints[0].Stars[0].Position, timePoints[0].Stars[1].Position)
+	return d / separation
+}
+
+
+// TwoBodyEnergyError runs the two-body orbit for one period and returns the largest relative change
+// of the total energy.
+func TwoBodyEnergyError(settings simulation.Options) float64 {
+	u, options := twoBodyOrbit()
+	options = options.WithForceSettings(settings)
+	timePoints := simulation.BarnesHut(u, options)
+
+	initial := nbody.KineticEnergy(timePoints[0]) + nbody.PotentialEnergy(timePoints[0])
+	worst := 0.0
+	for _, p := range timePoints {
+		e := nbody.KineticEnergy(p) + nbody.PotentialEnergy(p)
+		worst = math.Max(worst, math.Abs((e-initial)/initial))
+	}
+	return worst
+}
+
+
+
+
+//// Plummer model ////
+
+// plummerRadius is the scale radius a of the self-test Plummer models (one parsec).
+const plummerRadius = 3.0856775814913673e16
+
+
+// PlummerModel returns numStars suns drawn from the projected Plummer profile
+// Sigma(R) ~ (1 + R^2 / a^2)^-2, truncated at 3a, with isotropic velocities scaled so that the model
+// is in virial equilibrium (2 K = -W, with the potential softened by softening) and at rest as a whole.
+// Input:
+//   - numStars: number of stars.
+//   - a: scale radius.
+//   - center: center of the model.
+//   - softening: softening length used for the potential.
+// Output:
+//   - the stars.
+func PlummerModel(numStars int, a float64, center nbody.OrderedPair, softening float64) []*nbody.Star {
+	stars := make([]*nbody.Star, numStars)
+
+	var mean nbody.OrderedPair
+	for i := range stars {
+		// the mass inside R is R^2 / (R^2 + a^2); invert it, keeping 90% of the mass
+		m := 0.9 * nbody.RNG.Float64()
+		R := a * math.Sqrt(m/(1-m))
+		angle := nbody.RNG.Float64() * 2 * math.Pi
+		position := nbody.OrderedPair{X: center.X + R*math.Cos(angle), Y: center.Y + R*math.Sin(angle)}
+		velocity := nbody.OrderedPair{X: nbody.RNG.NormFloat64(), Y: nbody.RNG.NormFloat64()}
+		mean.X += velocity.X / float64(numStars)
+		mean.Y += velocity.Y / float64(numStars)
+		stars[i] = nbody.NewStar(position, velocity, nbody.SolarMass, 696340000)
+	}
+
+	for _, s := range stars {
+		s.Velocity.X -= mean.X
+		s.Velocity.Y -= mean.Y
+	}
+	u := &nbody.Universe{Stars: stars}
+	scale := math.Sqrt(-nbody.SoftenedPotentialEnergy(u, softening) / (2 * nbody.KineticEnergy(u)))
+	for _, s := range stars {
+		s.Velocity.X *= scale
+		s.Velocity.Y *= scale
+	}
+
+	return stars
+}
+
+
+// HalfMassRadius returns the radius around the center of mass that holds half of the stars.
+func HalfMassRadius(stars []*nbody.Star) float64 {
+	return nbody.EnclosingRadius(stars, nbody.CenterOfMass(stars), 0.5)
+}
+
+
+// plummerOptions returns the Options of a run of three crossing times a / sigma of a Plummer model
+// of numStars suns, with a softening of a / 10, in 300 steps.
+func plummerOptions(numStars int) simulation.Options {
+	softening := plummerRadius / 10
+	// from the virial theorem, sigma^2 is about G M / (4 a) for a Plummer model
+	sigma := math.Sqrt(nbody.G * float64(numStars) * nbody.SolarMass / (4 * plummerRadius))
+	crossing := plummerRadius / sigma
+
+	return simulation.DefaultOptions(300, 3*crossing/300, 0.5).WithSoftening(softening)
+}
+
+
+// PlummerRadiusChange runs a Plummer model of 200 stars for three crossing times and returns
+// the relative change of its half-mass radius.
+func PlummerRadiusChange(settings simulation.Options) float64 {
+	width := 20 * plummerRadius
+	options := plummerOptions(200).WithForceSettings(settings)
+
+	u := nbody.NewUniverse(width)
+	u.Stars = PlummerModel(200, plummerRadius, nbody.OrderedPair{X: width / 2, Y: width / 2}, options.Softening)
+	timePoints := simulation.BarnesHut(u, options)
+
+	initial := HalfMassRadius(timePoints[0].Stars)
+	final := HalfMassRadius(timePoints[options.NumGens].Stars)
+	return math.Abs(final/initial - 1)
+}
+
+
+// CollisionMomentumError runs two Plummer models of 100 stars colliding head-on and returns the change
+// of the total momentum, relative to the sum of m|v| of the initial stars.
+func CollisionMomentumError(settings simulation.Options) float64 {
+	width := 40 * plummerRadius
+	options := plummerOptions(100).WithForceSettings(settings)
+
+	u := nbody.NewUniverse(width)
+	left := PlummerModel(100, plummerRadius, nbody.OrderedPair{X: width/2 - 4*plummerRadius, Y: width / 2}, options.Softening)
+	right := PlummerModel(100, plummerRadius, nbody.OrderedPair{X: width/2 + 4*plummerRadius, Y: width/2 + plummerRadius}, options.Softening)
+
+	// approach at about the velocity dispersion
+	speed := math.Sqrt(nbody.G * 100 * nbody.SolarMass / (4 * plummerRadius))
+	for _, s := range left {
+		s.Velocity.X += speed
+	}
+	for _, s := range right {
+		s.Velocity.X -= speed
+	}
+	u.Stars = append(left, right...)
+
+	timePoints := simulation.BarnesHut(u, options)
+
+	momentum := func(u *nbody.Universe) nbody.OrderedPair {
+		var p nbody.OrderedPair
+		for _, s := range u.Stars {
+			p.X += s.Mass * s.Velocity.X
+			p.Y += s.Mass * s.Velocity.Y
+		}
+		return p
+	}
+
+	scale := 0.0
+	for _, s := range timePoints[0].Stars {
+		scale += s.Mass * math.Hypot(s.Velocity.X, s.Velocity.Y)
+	}
+
+	initial, final := momentum(timePoints[0]), momentum(timePoints[options.NumGens])
+	return math.Hypot(final.X-initial.X, final.Y-initial.Y) / scale
+}
@@ -0,0 +1,21 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing function for the self-tests in selftest.go.
+
+package main
+
+import (
+	"BarnesHut/simulation"
+	"bytes"
+	"testing"
+)
+
+// TestSelfTests runs every self-test of the "selftest" command, which must pass.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf with the report if a self-test fails.
+func TestSelfTests(t *testing.T) {
+	var report bytes.Buffer
+	if !PrintSelfTests(&report, SelfTests(), simulation.DefaultOptions(0, 1, 0)) {
+		t.Errorf("TestSelfTests() failed:\n%s", report.String())
+	}
+}
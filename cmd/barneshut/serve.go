@@ -0,0 +1,458 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: A REST API running simulations submitted as configuration files (command "serve").
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/render"
+	"BarnesHut/scenarios"
+	"BarnesHut/simulation"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxConfigBytes bounds the size of a submitted configuration file.
+const maxConfigBytes = 1 << 20
+
+// States of a ServedRun.
+const (
+	runQueued    = "queued"
+	runRunning   = "running"
+	runDone      = "done"
+	runFailed    = "failed"
+	runCancelled = "cancelled"
+)
+
+
+// RunServe runs the "serve" command: an HTTP server running the configuration files posted to it one after the
+// other, with the endpoints of RunServer.Handler.
+// ./BarnesHut serve [-addr :8080] [-dir runs] [-queue n] [-workers n]
+func RunServe(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "address the API listens at")
+	dir := flags.String("dir", "runs", "directory receiving a subdirectory with the snapshots and the GIF of every run")
+	queue := flags.Int("queue", 16, "largest number of runs waiting to start; more are refused")
+	flags.IntVar(&nbody.NumWorkers, "workers", nbody.NumWorkers, "number of goroutines used for forces, parallel tree builds, and encoding")
+	flags.Parse(args)
+	if *queue < 1 {
+		CheckConfig(fmt.Errorf("-queue must be at least 1, got %d", *queue))
+	}
+
+	server, err := NewRunServer(*dir, *queue)
+	CheckConfig(err)
+	go server.Work()
+	fmt.Printf("Serving the simulation API at %s, runs in %s\n", *addr, *dir)
+	nbody.Check(http.ListenAndServe(*addr, server.Handler()))
+}
+
+
+// A RunServer queues the runs submitted to it and runs them one at a time, since the random number generator
+// drawing the galaxies and events of a run from its seed is shared by the whole program. Everything else a run
+// uses is its own: its Options, and the directory where it writes its JSON snapshots and its GIF for the API to
+// read. The server leaves the settings of the command line, such as -snapshot-format, unchanged.
+type RunServer struct {
+	dir    string
+	queue  chan *ServedRun
+	mu     sync.Mutex
+	runs   map[string]*ServedRun
+	nextID int
+}
+
+
+// A ServedRun is a run of a RunServer; its exported fields are the status returned by the API.
+type ServedRun struct {
+	ID          string     `json:"id"`
+	State       string     `json:"state"` // queued, running, done, failed, or cancelled
+	Error       string     `json:"error,omitempty"`
+	Seed        int64      `json:"seed"`
+	NumStars    int        `json:"num_stars,omitempty"`
+	Generation  int        `json:"generation"` // generations simulated so far
+	Generations int        `json:"generations,omitempty"`
+	Submitted   time.Time  `json:"submitted"`
+	Started     *time.Time `json:"started,omitempty"`
+	Finished    *time.Time `json:"finished,omitempty"`
+
+	config scenarios.TOMLTable
+	dir    string
+	cancel context.CancelFunc
+}
+
+
+// NewRunServer returns a RunServer writing its runs to dir, created if needed. The numbers of its runs continue
+// after the runs already in dir, so a restarted server does not overwrite them.
+// Input:
+//   - dir: directory of the runs.
+//   - queueSize: largest number of runs waiting to start.
+// Output:
+//   - pointer to the RunServer, or an error if dir cannot be created or read.
+func NewRunServer(dir string, queueSize int) (*RunServer, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	s := &RunServer{dir: dir, queue: make(chan *ServedRun, queueSize), runs: make(map[string]*ServedRun), nextID: 1}
+	for _, entry := range entries {
+		if id, err := strconv.Atoi(entry.Name()); err == nil && id >= s.nextID {
+			s.nextID = id + 1
+		}
+	}
+	return s, nil
+}
+
+
+// Handler returns the endpoints of the API:
+//   - POST /runs: queue a run of the configuration file in the body (see ScenarioFromConfig), with the random
+//     seed of the query parameter "seed" if given, and return its status.
+//   - GET /runs: the status of every run, in submission order.
+//   - GET /runs/{id}: the status of a run, with the generations simulated so far.
+//   - DELETE /runs/{id}: cancel a queued or running run; a running run keeps the frames and snapshots so far.
+//   - GET /runs/{id}/snapshots: the generations whose snapshots are saved so far.
+//   - GET /runs/{id}/snapshots/{generation}: the snapshot of a generation as JSON (see WriteUniverseJSON).
+//   - GET /runs/{id}/animation.gif: the GIF of a run that has ended.
+func (s *RunServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/runs", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			s.submit(w, r)
+		case http.MethodGet:
+			s.list(w, r)
+		default:
+			writeJSONError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed on /runs", r.Method))
+		}
+	})
+	mux.HandleFunc("/runs/", s.route)
+	return mux
+}
+
+
+// route sends a request for /runs/{id}/... to the handler of its method and path, with a copy of the status of
+// the run, or answers 404 if there is no such run or path.
+func (s *RunServer) route(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/runs/"), "/")
+
+	s.mu.Lock()
+	run, ok := s.runs[parts[0]]
+	var status ServedRun
+	if ok {
+		status = *run
+	}
+	s.mu.Unlock()
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, fmt.Errorf("no run %q", parts[0]))
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, status)
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		s.cancel(w, status)
+	case len(parts) == 2 && parts[1] == "snapshots" && r.Method == http.MethodGet:
+		s.snapshots(w, status)
+	case len(parts) == 3 && parts[1] == "snapshots" && r.Method == http.MethodGet:
+		s.snapshot(w, r, status, parts[2])
+	case len(parts) == 2 && parts[1] == "animation.gif" && r.Method == http.MethodGet:
+		s.animation(w, r, status)
+	default:
+		writeJSONError(w, http.StatusNotFound, fmt.Errorf("no endpoint %s %s", r.Method, r.URL.Path))
+	}
+}
+
+
+// Work runs the queued runs one after the other, until the program ends.
+func (s *RunServer) Work() {
+	for run := range s.queue {
+		s.execute(run)
+	}
+}
+
+
+// submit queues a run of the posted configuration file.
+func (s *RunServer) submit(w http.ResponseWriter, r *http.Request) {
+	doc, err := scenarios.ParseTOML(io.LimitReader(r.Body, maxConfigBytes))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, fmt.Errorf("configuration: %w", err))
+		return
+	}
+	seed := time.Now().UnixNano()
+	if text := r.URL.Query().Get("seed"); text != "" {
+		seed, err = strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, fmt.Errorf("seed %q is not an integer", text))
+			return
+		}
+	}
+
+	s.mu.Lock()
+	run := &ServedRun{
+		ID:        strconv.Itoa(s.nextID),
+		State:     runQueued,
+		Seed:      seed,
+		Submitted: time.Now(),
+		config:    doc,
+	}
+	run.dir = filepath.Join(s.dir, run.ID)
+	select {
+	case s.queue <- run:
+	default:
+		s.mu.Unlock()
+		writeJSONError(w, http.StatusServiceUnavailable, errors.New("too many runs are waiting; try again later"))
+		return
+	}
+	s.nextID++
+	s.runs[run.ID] = run
+	status := *run
+	s.mu.Unlock()
+
+	w.Header().Set("Location", "/runs/"+run.ID)
+	writeJSON(w, http.StatusCreated, status)
+}
+
+
+// list returns the status of every run.
+func (s *RunServer) list(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	runs := make([]ServedRun, 0, len(s.runs))
+	for _, run := range s.runs {
+		runs = append(runs, *run)
+	}
+	s.mu.Unlock()
+	sort.Slice(runs, func(i, j int) bool {
+		a, _ := strconv.Atoi(runs[i].ID)
+		b, _ := strconv.Atoi(runs[j].ID)
+		return a < b
+	})
+	writeJSON(w, http.StatusOK, runs)
+}
+
+
+// cancel cancels a queued or running run and returns its status.
+func (s *RunServer) cancel(w http.ResponseWriter, run ServedRun) {
+	s.mu.Lock()
+	current := s.runs[run.ID]
+	switch current.State {
+	case runQueued:
+		// the worker skips it when its turn comes
+		current.State = runCancelled
+		now := time.Now()
+		current.Finished = &now
+	case runRunning:
+		// the run stops after its current generation and writes what it has
+		current.cancel()
+	default:
+		s.mu.Unlock()
+		writeJSONError(w, http.StatusConflict, fmt.Errorf("run %s has already ended (%s)", run.ID, run.State))
+		return
+	}
+	status := *current
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, status)
+}
+
+
+// snapshots returns the generations of the snapshots a run has saved so far.
+func (s *RunServer) snapshots(w http.ResponseWriter, run ServedRun) {
+	generations, err := SnapshotGenerations(run.dir)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string][]int{"generations": generations})
+}
+
+
+// snapshot returns the snapshot of a generation.
+func (s *RunServer) snapshot(w http.ResponseWriter, r *http.Request, run ServedRun, text string) {
+	generation, err := strconv.Atoi(text)
+	if err != nil || generation < 0 {
+		writeJSONError(w, http.StatusBadRequest, fmt.Errorf("generation %q is not a non-negative integer", text))
+		return
+	}
+	fileName := runSnapshot(run.dir, generation)
+	if _, err := os.Stat(fileName); err != nil {
+		writeJSONError(w, http.StatusNotFound, fmt.Errorf("run %s has no snapshot of generation %d", run.ID, generation))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	http.ServeFile(w, r, fileName)
+}
+
+
+// animation returns the GIF of a run that has ended.
+func (s *RunServer) animation(w http.ResponseWriter, r *http.Request, run ServedRun) {
+	if run.State == runQueued || run.State == runRunning {
+		writeJSONError(w, http.StatusConflict, fmt.Errorf("run %s is %s; its GIF is written when it ends", run.ID, run.State))
+		return
+	}
+	fileName := runOutput(run.dir).Path()
+	if _, err := os.Stat(fileName); err != nil {
+		writeJSONError(w, http.StatusNotFound, fmt.Errorf("run %s has no GIF (%s)", run.ID, run.State))
+		return
+	}
+	w.Header().Set("Content-Type", "image/gif")
+	http.ServeFile(w, r, fileName)
+}
+
+
+// execute runs a queued run, streaming its frames to its GIF and its generations to its snapshots, and records
+// how it ended. A failure of the run, such as an invalid configuration, fails the
+// run instead of the server.
+func (s *RunServer) execute(run *ServedRun) {
+	s.mu.Lock()
+	if run.State != runQueued {
+		s.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	run.cancel = cancel
+	run.State = runRunning
+	now := time.Now()
+	run.Started = &now
+	s.mu.Unlock()
+
+	err := s.simulate(ctx, run)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now = time.Now()
+	run.Finished = &now
+	switch {
+	case err != nil:
+		run.State, run.Error = runFailed, err.Error()
+	case ctx.Err() != nil:
+		run.State = runCancelled
+	default:
+		run.State = runDone
+	}
+}
+
+
+// simulate builds the scenario of a run and runs it, reporting its progress in run. Like RunStreaming, it saves
+// and draws every selected generation while the simulation runs, but into the directory of the run and without
+// printing anything; its events and mergers are not logged.
+// Output:
+//   - the error of the configuration, or the failure of the run.
+func (s *RunServer) simulate(ctx context.Context, run *ServedRun) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%v", recovered)
+		}
+	}()
+
+	// the galaxies of the configuration are drawn from the seed of the run
+	nbody.SetSeed(run.Seed)
+	scenario, err := scenarios.ScenarioFromConfig(run.config)
+	if err != nil {
+		return err
+	}
+	options := scenario.Options().WithEventLog(io.Discard).WithContext(ctx).WithProgress(func(generation, total int, stats simulation.ProgressStats) {
+		s.mu.Lock()
+		run.Generation = generation
+		s.mu.Unlock()
+	})
+	if err := options.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	run.NumStars, run.Generations = len(scenario.InitialUniverse.Stars), scenario.NumGens
+	s.mu.Unlock()
+
+	if err := os.MkdirAll(run.dir, 0755); err != nil {
+		return err
+	}
+	output := runOutput(run.dir)
+	file, err := os.Create(output.Path())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	stream := render.NewGIFStreamWriter(file, output.GIFDelay())
+
+	selector := &render.FrameSelector{Frequency: scenario.Frequency, FinalGeneration: scenario.NumGens}
+	snapshots, results := StreamGenerations(scenario.InitialUniverse, options, selector)
+	for snapshot := range snapshots {
+		// after a failure the remaining generations are received but not written
+		if err == nil {
+			err = nbody.WriteUniverseJSON(snapshot.universe, snapshot.generation, runSnapshot(run.dir, snapshot.generation))
+		}
+		if err == nil {
+			err = stream.WriteImage(render.DrawToCanvas(snapshot.universe, scenario.CanvasWidth, scenario.ScalingFactor))
+		}
+	}
+	result := <-results
+	if closeErr := stream.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && !simulation.Interrupted(result.err) {
+		err = result.err
+	}
+	return err
+}
+
+
+// runOutput returns the animation written by a run into its directory.
+func runOutput(dir string) render.AnimationOutput {
+	return render.AnimationOutput{Format: "gif", FileName: filepath.Join(dir, "run")}
+}
+
+
+// runSnapshot returns the name of the JSON snapshot of a generation written by a run into its directory.
+func runSnapshot(dir string, generation int) string {
+	return filepath.Join(dir, fmt.Sprintf("snapshot_%08d.json", generation))
+}
+
+
+// SnapshotGenerations returns the generations of the snapshot files of a directory, in order.
+func SnapshotGenerations(dir string) ([]int, error) {
+	fileNames, err := filepath.Glob(filepath.Join(dir, "snapshot_*.json"))
+	if err != nil {
+		return nil, err
+	}
+	generations := make([]int, 0, len(fileNames))
+	for _, fileName := range fileNames {
+		text := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(fileName), "snapshot_"), ".json")
+		generation, err := strconv.Atoi(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: not a snapshot file name", fileName)
+		}
+		generations = append(generations, generation)
+	}
+	sort.Ints(generations)
+	return generations, nil
+}
+
+
+// writeJSON answers a request with a value encoded as JSON.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+
+// writeJSONError answers a request with an error, as {"error": "..."}.
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,167 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the REST API of the command "serve" in serve.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type RunServerTestCases struct {
+	id          string
+	config      string
+	statusCode  int
+	state       string
+	generations []int
+}
+
+
+// ReadRunServer reads test data for the RunServer from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of RunServerTestCases structs containing the configuration files and the expected runs.
+func ReadRunServer(fileName string) []RunServerTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []RunServerTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+
+		test := RunServerTestCases{
+			id:     strings.TrimSpace(parts[0]),
+			config: strings.Join(strings.Split(parts[1], ";"), "\n"),
+			state:  strings.TrimSpace(parts[3]),
+		}
+		test.statusCode, err = strconv.Atoi(strings.TrimSpace(parts[2]))
+		nbody.Check(err)
+		if text := strings.TrimSpace(parts[4]); text != "-" {
+			for _, field := range strings.Split(text, ",") {
+				generation, err := strconv.Atoi(strings.TrimSpace(field))
+				nbody.Check(err)
+				test.generations = append(test.generations, generation)
+			}
+		}
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// GetJSON fetches a URL and decodes its JSON answer into v.
+// Output:
+//   - the status code of the answer.
+func GetJSON(url string, v any) int {
+	response, err := http.Get(url)
+	nbody.Check(err)
+	defer response.Body.Close()
+	nbody.Check(json.NewDecoder(response.Body).Decode(v))
+	return response.StatusCode
+}
+
+
+// TestRunServer tests that the configuration files posted to the API are run, that the status of a run follows
+// it until it ends, and that its snapshots and its GIF can be fetched.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestRunServer(t *testing.T) {
+	tests := ReadRunServer("../../Tests/RunServer.txt")
+	runServer, err := NewRunServer(t.TempDir(), len(tests))
+	nbody.Check(err)
+	go runServer.Work()
+	server := httptest.NewServer(runServer.Handler())
+	defer server.Close()
+
+	var run ServedRun
+	if code := GetJSON(server.URL+"/runs/1", &run); code != http.StatusNotFound {
+		t.Errorf("TestRunServer: status of a run never submitted answered %d, want %d", code, http.StatusNotFound)
+	}
+
+	for _, test := range tests {
+		response, err := http.Post(server.URL+"/runs?seed=1", "application/toml", strings.NewReader(test.config))
+		nbody.Check(err)
+		var submitted ServedRun
+		nbody.Check(json.NewDecoder(response.Body).Decode(&submitted))
+		response.Body.Close()
+		if response.StatusCode != test.statusCode {
+			t.Errorf("TestRunServer(test %v) posting the configuration answered %d, want %d", test.id, response.StatusCode, test.statusCode)
+			continue
+		}
+		if response.StatusCode != http.StatusCreated {
+			continue
+		}
+
+		// the runs are small, so they end quickly
+		runURL := server.URL + "/runs/" + submitted.ID
+		run := ServedRun{State: runQueued}
+		for deadline := time.Now().Add(30 * time.Second); time.Now().Before(deadline) && (run.State == runQueued || run.State == runRunning); time.Sleep(5 * time.Millisecond) {
+			GetJSON(runURL, &run)
+		}
+		if run.State != test.state {
+			t.Errorf("TestRunServer(test %v) ended %s (%s), want %s", test.id, run.State, run.Error, test.state)
+			continue
+		}
+		if run.State == runFailed {
+			if run.Error == "" {
+				t.Errorf("TestRunServer(test %v) failed without an error", test.id)
+			}
+			continue
+		}
+
+		var list struct {
+			Generations []int `json:"generations"`
+		}
+		GetJSON(runURL+"/snapshots", &list)
+		if !reflect.DeepEqual(list.Generations, test.generations) {
+			t.Errorf("TestRunServer(test %v) has snapshots of generations %v, want %v", test.id, list.Generations, test.generations)
+		}
+		if run.Generation != run.Generations {
+			t.Errorf("TestRunServer(test %v) is at generation %d of %d when done", test.id, run.Generation, run.Generations)
+		}
+
+		var snapshot struct {
+			Generation int     `json:"generation"`
+			Stars      []*nbody.Star `json:"stars"`
+		}
+		last := test.generations[len(test.generations)-1]
+		if code := GetJSON(fmt.Sprintf("%s/snapshots/%d", runURL, last), &snapshot); code != http.StatusOK || snapshot.Generation != last || len(snapshot.Stars) != run.NumStars {
+			t.Errorf("TestRunServer(test %v) snapshot of generation %d answered %d with generation %d and %d stars, want %d stars", test.id, last, code, snapshot.Generation, len(snapshot.Stars), run.NumStars)
+		}
+
+		response, err = http.Get(runURL + "/animation.gif")
+		nbody.Check(err)
+		gif, err := io.ReadAll(response.Body)
+		nbody.Check(err)
+		response.Body.Close()
+		if response.StatusCode != http.StatusOK || !bytes.HasPrefix(gif, []byte("GIF8")) {
+			t.Errorf("TestRunServer(test %v) GIF answered %d with %d bytes", test.id, response.StatusCode, len(gif))
+		}
+	}
+}
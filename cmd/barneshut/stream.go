@@ -0,0 +1,185 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Streaming runs (option "-stream" and command "simulate"), which save and draw every selected
+// generation while the simulation runs instead of keeping the generations in memory.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/render"
+	"BarnesHut/simulation"
+	"fmt"
+	"image"
+	"os"
+	"time"
+)
+
+// streamBuffer is the number of selected generations the simulation may run ahead of the drawing.
+const streamBuffer = 4
+
+// snapshotFormat is the format of the snapshots written by a run, set from the -snapshot-format option
+// (see nbody.CheckSnapshotFormat).
+var snapshotFormat = "text"
+
+// A Snapshot is one selected generation of a streaming run.
+type Snapshot struct {
+	generation int
+	universe   *nbody.Universe
+}
+
+// StreamResult is the outcome of a streaming run: its last generation, selected or not, and its error.
+type StreamResult struct {
+	last *nbody.Universe
+	err  error
+}
+
+
+// StreamGenerations runs the simulation on its own goroutine and sends every generation chosen by selector
+// on the returned channel as soon as it is computed. The channel is closed when the run ends; then the
+// result channel receives the StreamResult. The universes sent are copies the receiver may keep.
+// Input:
+//   - initialUniverse, options: simulation parameters as for BarnesHut.
+//   - selector: chooses the generations that are sent.
+// Output:
+//   - the channel of Snapshot values and the channel of the StreamResult.
+func StreamGenerations(initialUniverse *nbody.Universe, options simulation.Options, selector *render.FrameSelector) (<-chan Snapshot, <-chan StreamResult) {
+	snapshots := make(chan Snapshot, streamBuffer)
+	results := make(chan StreamResult, 1)
+
+	// the generations that are not selected are advanced in place; the selected ones may still be
+	// compared with later generations by the selector
+	lastSelected := -1
+	options.Retain = func(generation int) bool {
+		return generation == lastSelected
+	}
+
+	go func() {
+		var last *nbody.Universe
+		err := simulation.RunGenerations(initialUniverse, options.WithHook(func(generation int, u *nbody.Universe) {
+			last = u
+			if selector.Select(generation, u) {
+				lastSelected = generation
+				// the run still changes the softening of the current universe, so the receiver gets a copy
+				snapshots <- Snapshot{generation: generation, universe: nbody.CopyUniverse(u)}
+			}
+		}))
+		close(snapshots)
+		results <- StreamResult{last: last, err: err}
+	}()
+
+	return snapshots, results
+}
+
+
+// RunStreaming runs the simulation and writes the animation while it runs: every selected generation is
+// saved as a snapshot (if snapshotDir is set), drawn, and, for GIF output, encoded right away, so only
+// a few generations and one frame are in memory however long the run is. Drawing overlaps the simulation.
+// WebP animations are written at the end from the frames kept in memory; PNG frames are written as they are drawn. If options.ctx is cancelled, the frames drawn so far are written as a shorter animation.
+// Input:
+//   - initialUniverse, options: simulation parameters as for BarnesHut.
+//   - selector: chooses the generations that are saved and drawn.
+//   - canvasWidth, scalingFactor: drawing parameters as for AnimateSystem.
+//   - snapshotDir: directory for saved snapshots ("" if none are requested).
+//   - output: how the animation is written.
+//   - summary: summary of a batch run receiving the runtimes and the conservation errors, or nil.
+// Output:
+//   - None (the animation and snapshots are written to disk).
+func RunStreaming(initialUniverse *nbody.Universe, options simulation.Options, selector *render.FrameSelector,
+	canvasWidth int, scalingFactor float64, snapshotDir string, output render.AnimationOutput, summary *BatchSummary) {
+
+	if snapshotDir != "" {
+		nbody.Check(os.MkdirAll(snapshotDir, 0755))
+	}
+
+	var stream *render.GIFStreamWriter
+	if output.Format == "gif" {
+		var file *os.File
+		var err error
+		stream, file, err = output.CreateGIFStream()
+		nbody.Check(err)
+		defer file.Close()
+	}
+
+	start := time.Now()
+	snapshots, results := StreamGenerations(initialUniverse, options, selector)
+
+	var images []image.Image
+	numFrames := 0
+	for s := range snapshots {
+		if snapshotDir != "" {
+			nbody.Check(nbody.WriteSnapshot(s.universe, s.generation, nbody.SnapshotFileName(snapshotDir, s.generation, snapshotFormat)))
+		}
+
+		fmt.Fprintln(nbody.StatusOutput, s.generation)
+		img := render.DrawToCanvas(s.universe, canvasWidth, scalingFactor)
+		nbody.Check(output.WritePNG(img, numFrames))
+		numFrames++
+		if stream != nil {
+			nbody.Check(stream.WriteImage(img))
+		} else if output.Format != "png" {
+			images = append(images, img)
+		}
+	}
+	result := <-results
+	if simulation.Interrupted(result.err) {
+		fmt.Fprintln(nbody.StatusOutput, "Simulation interrupted; writing the", numFrames, "frames drawn so far.")
+	} else {
+		nbody.Check(result.err)
+	}
+
+	if summary != nil {
+		// simulating and drawing overlap, so the whole run counts as simulation
+		summary.Time("simulate", start)
+		summary.Simulated(initialUniverse, result.last, options)
+		defer summary.Time("render", time.Now())
+	}
+
+	if stream != nil {
+		nbody.Check(stream.Close())
+		fmt.Fprintln(nbody.StatusOutput, "GIF drawn.")
+		return
+	}
+
+	// the PNG files are already written
+	output.PNGDir = ""
+	nbody.Check(render.WriteAnimation(images, output))
+}
+
+
+// SimulateSnapshots runs the simulation for the simulate command and saves every selected generation as a
+// snapshot as soon as it is computed, without drawing it; render draws the snapshots later. Like RunStreaming,
+// it keeps only a few generations in memory however long the run is. If options.ctx is cancelled, the snapshots
+// saved so far are kept.
+// Input:
+//   - initialUniverse, options: simulation parameters as for BarnesHut.
+//   - selector: chooses the generations that are saved.
+//   - snapshotDir: directory for the snapshots, created if it does not exist.
+//   - summary: summary of a batch run receiving the runtime and the conservation errors, or nil.
+// Output:
+//   - the number of snapshots saved.
+func SimulateSnapshots(initialUniverse *nbody.Universe, options simulation.Options, selector *render.FrameSelector, snapshotDir string, summary *BatchSummary) int {
+	nbody.Check(os.MkdirAll(snapshotDir, 0755))
+
+	start := time.Now()
+	snapshots, results := StreamGenerations(initialUniverse, options, selector)
+
+	numSnapshots := 0
+	for s := range snapshots {
+		nbody.Check(nbody.WriteSnapshot(s.universe, s.generation, nbody.SnapshotFileName(snapshotDir, s.generation, snapshotFormat)))
+		fmt.Fprintln(nbody.StatusOutput, s.generation)
+		numSnapshots++
+	}
+	result := <-results
+	if simulation.Interrupted(result.err) {
+		fmt.Fprintln(nbody.StatusOutput, "Simulation interrupted; keeping the", numSnapshots, "snapshots saved so far.")
+	} else {
+		nbody.Check(result.err)
+	}
+
+	if summary != nil {
+		summary.Time("simulate", start)
+		summary.Simulated(initialUniverse, result.last, options)
+	}
+	return numSnapshots
+}
@@ -0,0 +1,103 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for streaming runs in stream.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/render"
+	"BarnesHut/simulation"
+	"bufio"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type StreamGenerationsTestCases struct {
+	id        string
+	numStars  int
+	numGens   int
+	frequency int
+}
+
+
+// ReadStreamGenerations reads test data for StreamGenerations from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of StreamGenerationsTestCases structs containing the size of the run and the frequency.
+func ReadStreamGenerations(fileName string) []StreamGenerationsTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []StreamGenerationsTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		var nums [3]int
+		for i, part := range parts[1:] {
+			nums[i], err = strconv.Atoi(strings.TrimSpace(part))
+			nbody.Check(err)
+		}
+
+		tests = append(tests, StreamGenerationsTestCases{
+			id:        strings.TrimSpace(parts[0]),
+			numStars:  nums[0],
+			numGens:   nums[1],
+			frequency: nums[2],
+		})
+	}
+
+	return tests
+}
+
+
+// TestStreamGenerations tests that streaming yields the selected generations of the same run as BarnesHut.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestStreamGenerations(t *testing.T) {
+	tests := ReadStreamGenerations("../../Tests/StreamGenerations.txt")
+
+	for _, test := range tests {
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		u := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+		options := simulation.DefaultOptions(test.numGens, 2e14, 0.5)
+
+		timePoints := simulation.BarnesHut(u, options)
+		expected := render.FrameIndices(len(timePoints), test.frequency)
+
+		selector := &render.FrameSelector{Frequency: test.frequency, FinalGeneration: test.numGens}
+		snapshots, results := StreamGenerations(u, options, selector)
+		var generations []int
+		for s := range snapshots {
+			generations = append(generations, s.generation)
+			if !reflect.DeepEqual(s.universe, timePoints[s.generation]) {
+				t.Errorf("TestStreamGenerations(test %v) generation %d differs from BarnesHut", test.id, s.generation)
+			}
+		}
+		result := <-results
+
+		if result.err != nil {
+			t.Errorf("TestStreamGenerations(test %v) error = %v, want nil", test.id, result.err)
+		}
+		if !reflect.DeepEqual(generations, expected) {
+			t.Errorf("TestStreamGenerations(test %v) = %v, want %v", test.id, generations, expected)
+		}
+		if !reflect.DeepEqual(result.last, timePoints[test.numGens]) {
+			t.Errorf("TestStreamGenerations(test %v) last generation differs from BarnesHut", test.id)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the block time steps in simulation/timestep.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/simulation"
+	"math"
+	"testing"
+)
+
+// TestBlockStep runs the two-body orbit of the self-test with ten times fewer, larger steps and checks that
+// block time steps bring the first star back to its starting point and conserve the energy.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if the orbit does not close.
+func TestBlockStep(t *testing.T) {
+	u, options := twoBodyOrbit()
+	options.NumGens /= 10
+	options.TimeStep *= 10
+	options.Softening = 1
+	options.AdaptiveStep = 0.1
+	options.BlockLevels = 4
+
+	timePoints := simulation.BarnesHut(u, options)
+	first, last := timePoints[0], timePoints[options.NumGens]
+
+	_, _, d := nbody.Distance(first.Stars[0].Position, last.Stars[0].Position)
+	_, _, separation := nbody.Distance(first.Stars[0].Position, first.Stars[1].Position)
+	if d/separation > 1e-4 {
+		t.Errorf("TestBlockStep() orbit error = %v, want at most 1e-4", d/separation)
+	}
+
+	e0 := nbody.KineticEnergy(first) + nbody.PotentialEnergy(first)
+	e1 := nbody.KineticEnergy(last) + nbody.PotentialEnergy(last)
+	if math.Abs((e1-e0)/e0) > 1e-6 {
+		t.Errorf("TestBlockStep() energy error = %v, want at most 1e-6", math.Abs((e1-e0)/e0))
+	}
+	if math.Abs(last.Time-float64(options.NumGens)*options.TimeStep) > 1e-6*last.Time {
+		t.Errorf("TestBlockStep() time = %v, want %v", last.Time, float64(options.NumGens)*options.TimeStep)
+	}
+}
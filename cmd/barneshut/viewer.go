@@ -0,0 +1,512 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: A web page showing a running simulation, its stars sent over a WebSocket (option "-web").
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/render"
+	"BarnesHut/simulation"
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// viewerBuffer is the number of frames waiting for a browser; a browser that falls further behind skips frames
+// instead of slowing the simulation down.
+const viewerBuffer = 4
+
+// viewerWriteTimeout bounds the time a frame may take to reach a browser before the connection is dropped.
+const viewerWriteTimeout = 10 * time.Second
+
+// webSocketGUID is the key suffix of the WebSocket handshake (RFC 6455, section 1.3).
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes (RFC 6455, section 5.2).
+const (
+	opText  = 0x1
+	opClose = 0x8
+)
+
+
+// A WebViewer serves the page of -web and sends every browser showing it the stars of the generations of a run:
+// every interval-th generation and the last one, as the animation draws them. A run that is not watched is not
+// slowed down: frames are encoded once, and a browser that cannot keep up misses some.
+type WebViewer struct {
+	interval        int
+	finalGeneration int
+
+	mu      sync.Mutex
+	clients map[*viewerClient]bool
+	last    []byte // the last frame sent, for the browsers that connect later
+	closed  bool
+	writers sync.WaitGroup // the goroutines writing to the browsers
+	server  *http.Server
+
+	warned bool // a generation with non-finite values was reported, only set by the hook
+}
+
+
+// viewerClient is a browser connected to a WebViewer, with the frames waiting to be written to it.
+type viewerClient struct {
+	conn   net.Conn
+	frames chan []byte
+}
+
+
+// ViewerFrame is the JSON message of one generation: the part of the universe in view (see View) and the
+// positions and colors of its stars.
+type ViewerFrame struct {
+	Generation int               `json:"generation"`
+	Time       string            `json:"time"`           // simulated time, e.g. "127 Myr" (see FormatTime)
+	View       ViewerCoordinates `json:"view"`           // x, y, width, and height of the view
+	Positions  ViewerCoordinates `json:"positions"`      // x and y of every star
+	Colors     []uint8           `json:"colors"`         // red, green, and blue of every star, in base64
+	Done       bool              `json:"done,omitempty"` // the run has ended; no frame follows
+}
+
+
+// ViewerCoordinates are the coordinates of a ViewerFrame. JSON has no NaN or infinity, so the non-finite
+// coordinates of a run with -finite-check off are sent as null, which the page does not draw.
+type ViewerCoordinates []float64
+
+
+// MarshalJSON writes the coordinates as a JSON array, with null for every non-finite coordinate.
+func (c ViewerCoordinates) MarshalJSON() ([]byte, error) {
+	if c == nil {
+		return []byte("null"), nil
+	}
+	data := []byte{'['}
+	for i, x := range c {
+		if i > 0 {
+			data = append(data, ',')
+		}
+		if nbody.IsFinite(x) {
+			data = strconv.AppendFloat(data, x, 'g', -1, 64)
+		} else {
+			data = append(data, "null"...)
+		}
+	}
+	return append(data, ']'), nil
+}
+
+
+// NewWebViewer returns a WebViewer of a run whose last generation is finalGeneration.
+// Input:
+//   - interval: number of generations between two frames.
+//   - finalGeneration: the last generation of the run, always sent.
+// Output:
+//   - pointer to the WebViewer.
+func NewWebViewer(interval, finalGeneration int) *WebViewer {
+	return &WebViewer{
+		interval:        interval,
+		finalGeneration: finalGeneration,
+		clients:         make(map[*viewerClient]bool),
+	}
+}
+
+
+// Start serves the page of the viewer at addr (such as ":8080") in the background.
+// Output:
+//   - the URL of the page, and an error if the server cannot listen at addr.
+func (v *WebViewer) Start(addr string) (string, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("-web %s: %w", addr, err)
+	}
+	v.server = &http.Server{Handler: v.Handler()}
+	go v.server.Serve(listener)
+
+	// a server listening on every interface is reached locally at localhost
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	if err != nil {
+		return "", err
+	}
+	if ip := net.ParseIP(host); ip == nil || ip.IsUnspecified() {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port) + "/", nil
+}
+
+
+// Handler returns the handler of the viewer: the page at "/" and its WebSocket at "/ws".
+func (v *WebViewer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, viewerPage)
+	})
+	mux.HandleFunc("/ws", v.serveWebSocket)
+	return mux
+}
+
+
+// Hook returns the hook for Options.WithHook that sends the generations of the viewer to every browser.
+// The first generation with a non-finite star is reported on simulation.WarningOutput; its frame is sent
+// without that star (see ViewerCoordinates). A frame that cannot be encoded is skipped with a warning.
+func (v *WebViewer) Hook() func(generation int, u *nbody.Universe) {
+	return func(generation int, u *nbody.Universe) {
+		if generation%v.interval != 0 && generation != v.finalGeneration {
+			return
+		}
+		if err := nbody.CheckFinite(u, generation); err != nil && !v.warned {
+			fmt.Fprintf(simulation.WarningOutput, "Warning: web viewer: %v; such stars are not shown\n", err)
+			v.warned = true
+		}
+		frame, err := json.Marshal(NewViewerFrame(generation, u))
+		if err != nil {
+			fmt.Fprintf(simulation.WarningOutput, "Warning: web viewer: generation %d not sent: %v\n", generation, err)
+			return
+		}
+		v.Broadcast(frame)
+	}
+}
+
+
+// NewViewerFrame returns the message of a generation.
+func NewViewerFrame(generation int, u *nbody.Universe) ViewerFrame {
+	view := render.UniverseView(u)
+	frame := ViewerFrame{
+		Generation: generation,
+		Time:       render.FormatTime(u.Time),
+		View:       []float64{view.X, view.Y, view.Width, view.Height},
+		Positions:  make([]float64, 0, 2*len(u.Stars)),
+		Colors:     make([]uint8, 0, 3*len(u.Stars)),
+	}
+	for _, s := range u.Stars {
+		r, g, b := render.StarColor(s)
+		frame.Positions = append(frame.Positions, s.Position.X, s.Position.Y)
+		frame.Colors = append(frame.Colors, r, g, b)
+	}
+	return frame
+}
+
+
+// Broadcast queues a frame for every browser, skipping the browsers whose queue is full, and keeps it for the
+// browsers connecting later.
+func (v *WebViewer) Broadcast(frame []byte) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.closed {
+		return
+	}
+	v.last = frame
+	for client := range v.clients {
+		select {
+		case client.frames <- frame:
+		default:
+		}
+	}
+}
+
+
+// Close tells every browser that the run has ended, waits for the frames queued for them to be written (or to
+// time out), and stops the server.
+func (v *WebViewer) Close() {
+	done, err := json.Marshal(ViewerFrame{Done: true})
+	nbody.Check(err)
+	v.Broadcast(done)
+
+	v.mu.Lock()
+	v.closed = true
+	for client := range v.clients {
+		v.removeLocked(client)
+	}
+	v.mu.Unlock()
+
+	v.writers.Wait()
+	if v.server != nil {
+		v.server.Close()
+	}
+}
+
+
+// removeLocked forgets a browser and ends its writer; v.mu must be held.
+func (v *WebViewer) removeLocked(client *viewerClient) {
+	if v.clients[client] {
+		delete(v.clients, client)
+		close(client.frames)
+	}
+}
+
+
+// remove forgets a browser and ends its writer.
+func (v *WebViewer) remove(client *viewerClient) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.removeLocked(client)
+}
+
+
+// serveWebSocket accepts the WebSocket of a browser and sends it the last frame, then every new one.
+func (v *WebViewer) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, rw, err := AcceptWebSocket(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	client := &viewerClient{conn: conn, frames: make(chan []byte, viewerBuffer)}
+	v.mu.Lock()
+	if v.closed {
+		v.mu.Unlock()
+		conn.Close()
+		return
+	}
+	v.clients[client] = true
+	if v.last != nil {
+		client.frames <- v.last
+	}
+	v.writers.Add(1)
+	v.mu.Unlock()
+
+	go v.write(client, rw.Writer)
+
+	// the page sends nothing but a close; any error or close ends the connection
+	for {
+		opcode, _, err := ReadWebSocketFrame(rw.Reader)
+		if err != nil || opcode == opClose {
+			v.remove(client)
+			return
+		}
+	}
+}
+
+
+// write writes the frames queued for a browser until its queue is closed, then closes the connection.
+func (v *WebViewer) write(client *viewerClient, w *bufio.Writer) {
+	defer v.writers.Done()
+	defer client.conn.Close()
+
+	failed := false
+	for frame := range client.frames {
+		if failed {
+			continue
+		}
+		client.conn.SetWriteDeadline(time.Now().Add(viewerWriteTimeout))
+		if err := WriteWebSocketFrame(w, opText, frame); err != nil || w.Flush() != nil {
+			failed = true
+			v.remove(client)
+		}
+	}
+	if !failed {
+		client.conn.SetWriteDeadline(time.Now().Add(viewerWriteTimeout))
+		if WriteWebSocketFrame(w, opClose, nil) == nil {
+			w.Flush()
+		}
+	}
+}
+
+
+// AcceptWebSocket answers the WebSocket handshake of a request (RFC 6455, section 4.2) and takes over its
+// connection.
+// Output:
+//   - the connection and its buffered reader and writer, or an error if the request is not a WebSocket handshake.
+func AcceptWebSocket(w http.ResponseWriter, r *http.Request) (net.Conn, *bufio.ReadWriter, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if r.Method != http.MethodGet || key == "" || !headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") {
+		return nil, nil, errors.New("not a WebSocket handshake")
+	}
+	if version := r.Header.Get("Sec-WebSocket-Version"); version != "13" {
+		return nil, nil, fmt.Errorf("unsupported WebSocket version %q (need 13)", version)
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the connection cannot be taken over")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", WebSocketAccept(key))
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, rw, nil
+}
+
+
+// WebSocketAccept returns the Sec-WebSocket-Accept answering a Sec-WebSocket-Key.
+func WebSocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+
+// headerContains reports whether a comma-separated header has a token, ignoring case.
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+
+// WriteWebSocketFrame writes an unfragmented, unmasked frame, as a server sends them.
+// Input:
+//   - w: the writer of the connection.
+//   - opcode: the opcode of the frame, such as opText.
+//   - payload: the payload.
+// Output:
+//   - the error of the writer, if any.
+func WriteWebSocketFrame(w io.Writer, opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+
+// ReadWebSocketFrame reads a frame and unmasks its payload, as browsers mask every frame they send.
+// Input:
+//   - r: the reader of the connection.
+// Output:
+//   - the opcode and the payload of the frame, or an error if it cannot be read.
+func ReadWebSocketFrame(r io.Reader) (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode := header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+
+	n := uint64(header[1] & 0x7F)
+	switch n {
+	case 126:
+		var size [2]byte
+		if _, err := io.ReadFull(r, size[:]); err != nil {
+			return 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(size[:]))
+	case 127:
+		var size [8]byte
+		if _, err := io.ReadFull(r, size[:]); err != nil {
+			return 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(size[:])
+	}
+	// the page only sends short control frames
+	if n > 1<<16 {
+		return 0, nil, fmt.Errorf("WebSocket frame of %d bytes is too long", n)
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return opcode, payload, nil
+}
+
+
+// viewerPage is the page of the viewer: a canvas drawing the stars of every frame received over the WebSocket
+// in the view of the frame, under a line with the generation and the simulated time.
+const viewerPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Barnes-Hut</title>
+<style>
+  body { margin: 0; background: #000; color: #ccc; font: 14px sans-serif; }
+  #status { padding: 6px 10px; }
+  canvas { display: block; margin: 0 auto; background: #000; }
+</style>
+</head>
+<body>
+<div id="status">Connecting...</div>
+<canvas id="universe"></canvas>
+<script>
+const status = document.getElementById("status");
+const canvas = document.getElementById("universe");
+const context = canvas.getContext("2d");
+let frame = null;
+
+// fit the canvas in the window with the proportions of the view
+function draw() {
+  if (frame === null || frame.view.includes(null)) {
+    return;
+  }
+  const [x, y, width, height] = frame.view;
+  const scale = Math.min(window.innerWidth / width, (window.innerHeight - status.offsetHeight) / height);
+  canvas.width = Math.max(1, Math.floor(width * scale));
+  canvas.height = Math.max(1, Math.floor(height * scale));
+  context.fillStyle = "#000";
+  context.fillRect(0, 0, canvas.width, canvas.height);
+  const size = Math.max(1, Math.round(canvas.width / 500));
+  const p = frame.positions, c = Uint8Array.from(atob(frame.colors), (ch) => ch.charCodeAt(0));
+  for (let i = 0; 2 * i < p.length; i++) {
+    // the coordinates of a star that is no longer finite are null
+    if (p[2 * i] === null || p[2 * i + 1] === null) {
+      continue;
+    }
+    context.fillStyle = "rgb(" + c[3 * i] + "," + c[3 * i + 1] + "," + c[3 * i + 2] + ")";
+    context.fillRect((p[2 * i] - x) * scale - size / 2, (p[2 * i + 1] - y) * scale - size / 2, size, size);
+  }
+}
+
+const socket = new WebSocket((location.protocol === "https:" ? "wss://" : "ws://") + location.host + "/ws");
+socket.onmessage = (event) => {
+  const message = JSON.parse(event.data);
+  if (message.done) {
+    status.textContent += " (run ended)";
+    return;
+  }
+  frame = message;
+  status.textContent = "generation " + frame.generation + ", t = " + frame.time + ", " + (frame.positions.length / 2) + " stars";
+  requestAnimationFrame(draw);
+};
+socket.onclose = () => {
+  if (!status.textContent.endsWith("(run ended)")) {
+    status.textContent += " (disconnected)";
+  }
+};
+window.onresize = () => requestAnimationFrame(draw);
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,334 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the web viewer of the option "-web" in viewer.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/simulation"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type WebSocketAcceptTestCases struct {
+	id     string
+	key    string
+	accept string
+}
+
+type WebSocketFrameTestCases struct {
+	id     string
+	length int
+	opcode byte
+	masked bool
+}
+
+type WebViewerTestCases struct {
+	id          string
+	numStars    int
+	numGens     int
+	interval    int
+	generations []int
+	nonFinite   int // the star whose position is NaN, or -1
+}
+
+
+// ReadWebSocketAccept reads test data for WebSocketAccept from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of WebSocketAcceptTestCases structs containing the keys and the expected answers.
+func ReadWebSocketAccept(fileName string) []WebSocketAcceptTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []WebSocketAcceptTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 3 {
+			continue
+		}
+
+		tests = append(tests, WebSocketAcceptTestCases{
+			id:     strings.TrimSpace(parts[0]),
+			key:    strings.TrimSpace(parts[1]),
+			accept: strings.TrimSpace(parts[2]),
+		})
+	}
+
+	return tests
+}
+
+
+// TestWebSocketAccept tests the answer of the server to the key of a WebSocket handshake.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestWebSocketAccept(t *testing.T) {
+	for _, test := range ReadWebSocketAccept("../../Tests/WebSocketAccept.txt") {
+		if result := WebSocketAccept(test.key); result != test.accept {
+			t.Errorf("TestWebSocketAccept(test %v) = %q, want %q", test.id, result, test.accept)
+		}
+	}
+}
+
+
+// ReadWebSocketFrameTests reads test data for WriteWebSocketFrame and ReadWebSocketFrame from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of WebSocketFrameTestCases structs containing the frames.
+func ReadWebSocketFrameTests(fileName string) []WebSocketFrameTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []WebSocketFrameTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		test := WebSocketFrameTestCases{id: strings.TrimSpace(parts[0])}
+		test.length, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		opcode, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+		nbody.Check(err)
+		test.opcode = byte(opcode)
+		test.masked, err = strconv.ParseBool(strings.TrimSpace(parts[3]))
+		nbody.Check(err)
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// MaskFrame returns an unmasked frame written by WriteWebSocketFrame masked with a key, as a browser sends it.
+func MaskFrame(frame []byte, key [4]byte) []byte {
+	header := 2
+	switch frame[1] {
+	case 126:
+		header = 4
+	case 127:
+		header = 10
+	}
+	masked := append([]byte(nil), frame[:header]...)
+	masked[1] |= 0x80
+	masked = append(masked, key[:]...)
+	for i, b := range frame[header:] {
+		masked = append(masked, b^key[i%4])
+	}
+	return masked
+}
+
+
+// TestWebSocketFrame tests that the frames written by WriteWebSocketFrame, and the same frames masked, are read
+// back by ReadWebSocketFrame.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestWebSocketFrame(t *testing.T) {
+	for _, test := range ReadWebSocketFrameTests("../../Tests/WebSocketFrame.txt") {
+		payload := make([]byte, test.length)
+		for i := range payload {
+			payload[i] = byte(i * 7)
+		}
+		var buffer bytes.Buffer
+		nbody.Check(WriteWebSocketFrame(&buffer, test.opcode, payload))
+		frame := buffer.Bytes()
+		if test.masked {
+			frame = MaskFrame(frame, [4]byte{0x12, 0x34, 0x56, 0x78})
+		}
+
+		opcode, result, err := ReadWebSocketFrame(bytes.NewReader(frame))
+		if err != nil {
+			t.Errorf("TestWebSocketFrame(test %v) failed: %v", test.id, err)
+			continue
+		}
+		if opcode != test.opcode || !bytes.Equal(result, payload) {
+			t.Errorf("TestWebSocketFrame(test %v) = opcode %d and %d bytes, want opcode %d and the %d bytes written", test.id, opcode, len(result), test.opcode, len(payload))
+		}
+	}
+}
+
+
+// ReadWebViewer reads test data for the WebViewer from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of WebViewerTestCases structs containing the runs and the generations of their frames.
+func ReadWebViewer(fileName string) []WebViewerTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []WebViewerTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 6 {
+			continue
+		}
+
+		test := WebViewerTestCases{id: strings.TrimSpace(parts[0]), nonFinite: -1}
+		test.numStars, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		test.numGens, err = strconv.Atoi(strings.TrimSpace(parts[2]))
+		nbody.Check(err)
+		test.interval, err = strconv.Atoi(strings.TrimSpace(parts[3]))
+		nbody.Check(err)
+		for _, field := range strings.Split(parts[4], ",") {
+			generation, err := strconv.Atoi(strings.TrimSpace(field))
+			nbody.Check(err)
+			test.generations = append(test.generations, generation)
+		}
+		if nonFinite := strings.TrimSpace(parts[5]); nonFinite != "-" {
+			test.nonFinite, err = strconv.Atoi(nonFinite)
+			nbody.Check(err)
+		}
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// DialWebSocket opens a WebSocket to the viewer served at url, as a browser does.
+// Output:
+//   - the connection and its reader, and the Sec-WebSocket-Accept of the answer.
+func DialWebSocket(url string) (net.Conn, *bufio.Reader, string) {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	nbody.Check(err)
+	request, err := http.NewRequest(http.MethodGet, url+"/ws", nil)
+	nbody.Check(err)
+	request.Header.Set("Connection", "Upgrade")
+	request.Header.Set("Upgrade", "websocket")
+	request.Header.Set("Sec-WebSocket-Version", "13")
+	request.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	nbody.Check(request.Write(conn))
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, request)
+	nbody.Check(err)
+	if response.StatusCode != http.StatusSwitchingProtocols {
+		return conn, reader, ""
+	}
+	return conn, reader, response.Header.Get("Sec-WebSocket-Accept")
+}
+
+
+// TestWebViewer tests that a browser connected to the viewer of a run receives the frames of its generations,
+// each with every star, and then the end of the run. A NaN star is sent as null with a single warning.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestWebViewer(t *testing.T) {
+	var warnings bytes.Buffer
+	simulation.WarningOutput = &warnings
+	defer func() { simulation.WarningOutput = os.Stderr }()
+
+	for _, test := range ReadWebViewer("../../Tests/WebViewer.txt") {
+		warnings.Reset()
+		viewer := NewWebViewer(test.interval, test.numGens)
+		server := httptest.NewServer(viewer.Handler())
+
+		conn, reader, accept := DialWebSocket(server.URL)
+		if accept != WebSocketAccept("dGhlIHNhbXBsZSBub25jZQ==") {
+			t.Errorf("TestWebViewer(test %v) handshake answered %q", test.id, accept)
+			conn.Close()
+			server.Close()
+			continue
+		}
+		// the frames are only sent to the browsers connected when they are computed
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+			viewer.mu.Lock()
+			connected := len(viewer.clients)
+			viewer.mu.Unlock()
+			if connected > 0 {
+				break
+			}
+		}
+
+		nbody.SetDeterministic()
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		initial := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+		numStars := len(initial.Stars)
+		options := simulation.DefaultOptions(test.numGens, 2e14, 0.5).WithHook(viewer.Hook())
+		if test.nonFinite >= 0 {
+			initial.Stars[test.nonFinite].Position.X = math.NaN()
+			options.FiniteCheck = "off"
+		}
+		finished := make(chan struct{})
+		go func() {
+			simulation.BarnesHut(initial, options)
+			viewer.Close()
+			close(finished)
+		}()
+
+		var generations []int
+		done := false
+		for {
+			opcode, payload, err := ReadWebSocketFrame(reader)
+			if err != nil || opcode == opClose {
+				break
+			}
+			var frame ViewerFrame
+			nbody.Check(json.Unmarshal(payload, &frame))
+			if frame.Done {
+				done = true
+				continue
+			}
+			generations = append(generations, frame.Generation)
+			if len(frame.Positions) != 2*numStars || len(frame.Colors) != 3*numStars || len(frame.View) != 4 {
+				t.Errorf("TestWebViewer(test %v, generation %d) has %d coordinates and %d color values, want %d and %d", test.id, frame.Generation, len(frame.Positions), len(frame.Colors), 2*numStars, 3*numStars)
+			}
+			if hasNull := bytes.Contains(payload, []byte("null")); hasNull != (test.nonFinite >= 0) {
+				t.Errorf("TestWebViewer(test %v, generation %d) has null coordinates %v, want %v", test.id, frame.Generation, hasNull, test.nonFinite >= 0)
+			}
+		}
+		conn.Close()
+		server.Close()
+		<-finished
+
+		if !reflect.DeepEqual(generations, test.generations) || !done {
+			t.Errorf("TestWebViewer(test %v) received generations %v (end of run %v), want %v", test.id, generations, done, test.generations)
+		}
+		numWarnings := strings.Count(warnings.String(), "Warning: web viewer:")
+		want := 0
+		if test.nonFinite >= 0 {
+			want = 1
+		}
+		if numWarnings != want {
+			t.Errorf("TestWebViewer(test %v) wrote %d warnings, want %d", test.id, numWarnings, want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+//go:build js && wasm
+
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: The WebAssembly build: a JavaScript API running the simulation in a web page (see wasm/index.html).
+
+package main
+
+import (
+	"BarnesHut/nbody"
+	"encoding/binary"
+	"io"
+	"math"
+	"syscall/js"
+)
+
+// main of the WebAssembly build defines globalThis.barnesHut for the page and waits for its calls.
+//
+//	barnesHut.scenarios: the names of BrowserScenarios.
+//	barnesHut.start({scenario, config, seed, canvas, speed}): a player (see playerObject), or {error} if the
+//	    scenario or the configuration file is invalid. Every field is optional: the galaxy scenario, a random seed,
+//	    and the scenario's canvas width and frequency by default.
+func main() {
+	// errors are returned to the page instead of ending the program, and there is no terminal to report to
+	batchMode = true
+	nbody.StatusOutput = io.Discard
+
+	scenarios := make([]any, len(BrowserScenarios))
+	for i, name := range BrowserScenarios {
+		scenarios[i] = name
+	}
+	js.Global().Set("barnesHut", js.ValueOf(map[string]any{
+		"scenarios": scenarios,
+		"start":     js.FuncOf(startPlayer),
+	}))
+	select {}
+}
+
+
+// startPlayer is barnesHut.start.
+func startPlayer(this js.Value, args []js.Value) any {
+	spec := js.Undefined()
+	if len(args) > 0 {
+		spec = args[0]
+	}
+	name := "galaxy"
+	config := ""
+	seed := int64(js.Global().Get("Date").Call("now").Float())
+	canvasWidth, speed := 0, 0
+	if spec.Type() == js.TypeObject {
+		if v := spec.Get("scenario"); v.Type() == js.TypeString {
+			name = v.String()
+		}
+		if v := spec.Get("config"); v.Type() == js.TypeString {
+			config = v.String()
+		}
+		if v := spec.Get("seed"); v.Type() == js.TypeNumber {
+			seed = int64(v.Float())
+		}
+		if v := spec.Get("canvas"); v.Type() == js.TypeNumber {
+			canvasWidth = v.Int()
+		}
+		if v := spec.Get("speed"); v.Type() == js.TypeNumber {
+			speed = v.Int()
+		}
+	}
+
+	player, err := NewBrowserPlayer(name, config, seed, canvasWidth, speed)
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+	return playerObject(player, seed)
+}
+
+
+// playerObject returns the JavaScript object of a player:
+//
+//	advance(): advance by the generations per frame, unless paused or done; step(): advance one generation.
+//	togglePause(), faster(), slower(): the controls of -live.
+//	frame(): the current frame as {width, height, data}, where data is a Uint8ClampedArray of RGBA pixels that
+//	    new ImageData(data, width) takes.
+//	positions(): a Float64Array with the x and y of every star.
+//	generation(), done(), status(), error(): the state of the run; error() is null unless a step failed.
+//	seed: the seed of the run.
+func playerObject(player *LivePlayer, seed int64) map[string]any {
+	action := func(f func()) js.Func {
+		return js.FuncOf(func(this js.Value, args []js.Value) any {
+			f()
+			return nil
+		})
+	}
+	value := func(f func() any) js.Func {
+		return js.FuncOf(func(this js.Value, args []js.Value) any {
+			return f()
+		})
+	}
+
+	return map[string]any{
+		"seed":        float64(seed),
+		"advance":     action(player.Advance),
+		"step":        action(player.StepOnce),
+		"togglePause": action(player.TogglePause),
+		"faster":      action(player.Faster),
+		"slower":      action(player.Slower),
+		"frame": value(func() any {
+			rgba := player.RGBA()
+			data := js.Global().Get("Uint8ClampedArray").New(len(rgba.Pix))
+			js.CopyBytesToJS(data, rgba.Pix)
+			return map[string]any{"width": rgba.Rect.Dx(), "height": rgba.Rect.Dy(), "data": data}
+		}),
+		"positions": value(func() any {
+			positions := player.Positions()
+			bytes := make([]byte, 8*len(positions))
+			for i, x := range positions {
+				binary.LittleEndian.PutUint64(bytes[8*i:], math.Float64bits(x))
+			}
+			buffer := js.Global().Get("Uint8Array").New(len(bytes))
+			js.CopyBytesToJS(buffer, bytes)
+			return js.Global().Get("Float64Array").New(buffer.Get("buffer"))
+		}),
+		"generation": value(func() any { return player.sim.Generation() }),
+		"done":       value(func() any { return player.Done() }),
+		"status":     value(func() any { return player.Status() }),
+		"error": value(func() any {
+			if player.Err() == nil {
+				return nil
+			}
+			return player.Err().Error()
+		}),
+	}
+}
@@ -0,0 +1,587 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Analyses (energy, radial profiles, group finding, disk instabilities, bar strength) that run on saved snapshots.
+
+package nbody
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+//// Energy ////
+
+// KineticEnergy computes the total kinetic energy of all stars in the universe.
+// Input:
+//   - u: pointer to the Universe.
+// Output:
+//   - sum of 0.5 * m * v^2 over all stars.
+func KineticEnergy(u *Universe) float64 {
+	energy := 0.0
+	for _, s := range u.Stars {
+		v2 := s.Velocity.X*s.Velocity.X + s.Velocity.Y*s.Velocity.Y
+		energy += 0.5 * s.Mass * v2
+	}
+	return energy
+}
+
+
+// PotentialEnergy computes the total gravitational potential energy by direct summation over all pairs.
+// Input:
+//   - u: pointer to the Universe.
+// Output:
+//   - sum of -G * m_i * m_j / r_ij over all pairs i < j (pairs at zero distance are skipped).
+func PotentialEnergy(u *Universe) float64 {
+	energy := 0.0
+	for i := 0; i < len(u.Stars); i++ {
+		for j := i + 1; j < len(u.Stars); j++ {
+			_, _, d := Distance(u.Stars[i].Position, u.Stars[j].Position)
+			if d != 0 {
+				energy -= G * u.Stars[i].Mass * u.Stars[j].Mass / d
+			}
+		}
+	}
+	return energy
+}
+
+
+// SoftenedPotentialEnergy computes the potential energy like PotentialEnergy, but with the Plummer
+// softened potential -G * m_i * m_j / sqrt(r_ij^2 + eps^2) that matches the force of a softened run.
+func SoftenedPotentialEnergy(u *Universe, softening float64) float64 {
+	energy := 0.0
+	for i := 0; i < len(u.Stars); i++ {
+		for j := i + 1; j < len(u.Stars); j++ {
+			_, _, d := Distance(u.Stars[i].Position, u.Stars[j].Position)
+			if d != 0 || softening > 0 {
+				energy -= G * u.Stars[i].Mass * u.Stars[j].Mass / math.Sqrt(d*d+softening*softening)
+			}
+		}
+	}
+	return energy
+}
+
+
+// PrintEnergy prints the energy of every snapshot and the relative drift of the total energy.
+func PrintEnergy(universes []*Universe, generations []int) {
+	fmt.Println("# energy")
+	fmt.Println("# generation kinetic potential total")
+
+	var firstTotal, lastTotal float64
+	for i, u := range universes {
+		kinetic := KineticEnergy(u)
+		potential := PotentialEnergy(u)
+		total := kinetic + potential
+		fmt.Printf("%d %e %e %e\n", generations[i], kinetic, potential, total)
+
+		if i == 0 {
+			firstTotal = total
+		}
+		lastTotal = total
+	}
+
+	if firstTotal != 0 {
+		fmt.Printf("# relative energy drift: %e\n", (lastTotal-firstTotal)/math.Abs(firstTotal))
+	}
+}
+
+
+
+
+//// Radial profiles ////
+
+// CenterOfMass computes the mass-weighted average position of a slice of stars.
+// Input:
+//   - stars: slice of Star pointers.
+// Output:
+//   - OrderedPair representing the center of mass (the origin if the total mass is zero).
+func CenterOfMass(stars []*Star) OrderedPair {
+	var c OrderedPair
+	totalMass := 0.0
+
+	for _, s := range stars {
+		c.X += s.Mass * s.Position.X
+		c.Y += s.Mass * s.Position.Y
+		totalMass += s.Mass
+	}
+
+	if totalMass == 0 {
+		return OrderedPair{}
+	}
+	c.X /= totalMass
+	c.Y /= totalMass
+
+	return c
+}
+
+
+// RadialProfile bins the mass of a universe in equal-width annuli around a center.
+// Input:
+//   - u: pointer to the Universe.
+//   - center: center of the annuli.
+//   - numBins: number of annuli.
+//   - rMax: outer radius of the last annulus; stars beyond it are ignored.
+// Output:
+//   - slice of numBins surface densities (mass divided by annulus area).
+func RadialProfile(u *Universe, center OrderedPair, numBins int, rMax float64) []float64 {
+	mass := make([]float64, numBins)
+	binWidth := rMax / float64(numBins)
+
+	for _, s := range u.Stars {
+		_, _, r := Distance(s.Position, center)
+		bin := int(r / binWidth)
+		if bin < numBins {
+			mass[bin] += s.Mass
+		}
+	}
+
+	density := make([]float64, numBins)
+	for i := range mass {
+		rIn := float64(i) * binWidth
+		rOut := rIn + binWidth
+		density[i] = mass[i] / (math.Pi * (rOut*rOut - rIn*rIn))
+	}
+
+	return density
+}
+
+
+// PrintRadialProfiles prints the radial surface density profile of every snapshot.
+// The bins extend to half the universe width around the center of mass of each snapshot.
+func PrintRadialProfiles(universes []*Universe, generations []int, numBins int) {
+	fmt.Println("# radial profile (surface density per annulus, rMax = width / 2)")
+	fmt.Println("# generation density_0 ... density_n")
+
+	for i, u := range universes {
+		profile := RadialProfile(u, CenterOfMass(u.Stars), numBins, u.Width/2.0)
+		fmt.Print(generations[i])
+		for _, d := range profile {
+			fmt.Printf(" %e", d)
+		}
+		fmt.Println()
+	}
+}
+
+
+
+
+//// Group finding ////
+
+// FindGroups links stars closer than linkingLength into groups (friends-of-friends).
+// Input:
+//   - u: pointer to the Universe.
+//   - linkingLength: maximum distance between two linked stars.
+// Output:
+//   - slice of groups, each group being a slice of star indices; groups are listed by their smallest index.
+func FindGroups(u *Universe, linkingLength float64) [][]int {
+	n := len(u.Stars)
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+
+	find := func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			_, _, d := Distance(u.Stars[i].Position, u.Stars[j].Position)
+			if d <= linkingLength {
+				rootI, rootJ := find(i), find(j)
+				if rootI != rootJ {
+					parent[rootJ] = rootI
+				}
+			}
+		}
+	}
+
+	groupIndex := make(map[int]int)
+	var groups [][]int
+	for i := 0; i < n; i++ {
+		root := find(i)
+		idx, ok := groupIndex[root]
+		if !ok {
+			idx = len(groups)
+			groupIndex[root] = idx
+			groups = append(groups, nil)
+		}
+		groups[idx] = append(groups[idx], i)
+	}
+
+	return groups
+}
+
+
+// PrintGroups prints, for every snapshot, the number of groups and the size, mass, and center of the large ones.
+func PrintGroups(universes []*Universe, generations []int, linkFraction float64, minMembers int) {
+	fmt.Println("# friends-of-friends groups")
+
+	for i, u := range universes {
+		groups := FindGroups(u, linkFraction*u.Width)
+
+		var large [][]int
+		for _, g := range groups {
+			if len(g) >= minMembers {
+				large = append(large, g)
+			}
+		}
+
+		fmt.Printf("generation %d: %d groups with at least %d members\n", generations[i], len(large), minMembers)
+		for _, g := range large {
+			members := make([]*Star, len(g))
+			mass := 0.0
+			for k, idx := range g {
+				members[k] = u.Stars[idx]
+				mass += u.Stars[idx].Mass
+			}
+			c := CenterOfMass(members)
+			fmt.Printf("  members %d, mass %e, center (%e, %e)\n", len(g), mass, c.X, c.Y)
+		}
+	}
+}
+
+
+
+
+//// Disk instabilities ////
+
+// EnclosingRadius returns the radius around center that contains the given fraction of the stars.
+func EnclosingRadius(stars []*Star, center OrderedPair, fraction float64) float64 {
+	if len(stars) == 0 {
+		return 0
+	}
+
+	radii := make([]float64, len(stars))
+	for i, s := range stars {
+		_, _, radii[i] = Distance(s.Position, center)
+	}
+	sort.Float64s(radii)
+
+	k := int(fraction*float64(len(radii))) - 1
+	k = max(0, min(k, len(radii)-1))
+	return radii[k]
+}
+
+
+// PolarCounts counts the stars of a disk in a polar grid of equal-width rings and equal sectors around a center.
+// Input:
+//   - stars: slice of Star pointers.
+//   - center: center of the grid.
+//   - rMax: outer radius of the last ring; stars beyond it are ignored.
+//   - numRings, numSectors: size of the grid.
+// Output:
+//   - counts[ring][sector].
+func PolarCounts(stars []*Star, center OrderedPair, rMax float64, numRings, numSectors int) [][]int {
+	counts := make([][]int, numRings)
+	for i := range counts {
+		counts[i] = make([]int, numSectors)
+	}
+
+	for _, s := range stars {
+		dx, dy, r := Distance(s.Position, center)
+		ring := int(r / rMax * float64(numRings))
+		if ring >= numRings {
+			continue
+		}
+		angle := math.Atan2(dy, dx) + math.Pi
+		sector := min(int(angle/(2*math.Pi)*float64(numSectors)), numSectors-1)
+		counts[ring][sector]++
+	}
+
+	return counts
+}
+
+
+// InstabilityAmplitudes measures how far a disk has departed from a smooth, axisymmetric one.
+//   - ring: RMS relative deviation of the surface density of every ring from the geometric mean of its
+//     two neighbors, which is zero for a smooth exponential profile and grows as rings form.
+//   - clump: RMS relative deviation of the cell counts from the mean of their ring, which grows as the
+//     disk fragments into clumps.
+//
+// Both include the Poisson noise of a finite number of stars, so a freshly initialized disk starts at the
+// noise level and growth is measured relative to it.
+// Input:
+//   - counts: star counts of a polar grid of equal-width rings, as returned by PolarCounts.
+// Output:
+//   - the ring and clump amplitudes.
+func InstabilityAmplitudes(counts [][]int) (float64, float64) {
+	numRings := len(counts)
+
+	// surface density of every ring, in stars per unit area of a grid with rings of width 1
+	density := make([]float64, numRings)
+	for i, ring := range counts {
+		total := 0
+		for _, n := range ring {
+			total += n
+		}
+		density[i] = float64(total) / float64(2*i+1)
+	}
+
+	ringSum, ringTerms := 0.0, 0
+	for i := 1; i < numRings-1; i++ {
+		smooth := math.Sqrt(density[i-1] * density[i+1])
+		if smooth > 0 {
+			d := density[i]/smooth - 1
+			ringSum += d * d
+			ringTerms++
+		}
+	}
+
+	clumpSum, clumpTerms := 0.0, 0
+	for _, ring := range counts {
+		total := 0
+		for _, n := range ring {
+			total += n
+		}
+		if total == 0 {
+			continue
+		}
+		mean := float64(total) / float64(len(ring))
+		for _, n := range ring {
+			d := float64(n)/mean - 1
+			clumpSum += d * d
+			clumpTerms++
+		}
+	}
+
+	ring, clump := 0.0, 0.0
+	if ringTerms > 0 {
+		ring = math.Sqrt(ringSum / float64(ringTerms))
+	}
+	if clumpTerms > 0 {
+		clump = math.Sqrt(clumpSum / float64(clumpTerms))
+	}
+	return ring, clump
+}
+
+
+// PrintInstability prints the ring and clump amplitudes of every snapshot (see InstabilityAmplitudes),
+// the smallest Toomre Q measured in the rings, and the number of e-foldings the amplitudes grew since
+// the first snapshot. The grid extends to the radius containing 90% of the disk stars of the first snapshot.
+func PrintInstability(universes []*Universe, generations []int, numRings, numSectors int) {
+	fmt.Println("# disk instabilities (rMax encloses 90% of the first snapshot's disk)")
+	fmt.Println("# generation ring clump minQ ring_efolds clump_efolds")
+	if len(universes) == 0 {
+		return
+	}
+
+	first := universes[0]
+	rMax := EnclosingRadius(DiskStars(first.Stars), CenterOfMass(first.Stars), 0.9)
+	ring0, clump0 := 0.0, 0.0
+
+	for i, u := range universes {
+		center := CenterOfMass(u.Stars)
+		ring, clump := InstabilityAmplitudes(PolarCounts(DiskStars(u.Stars), center, rMax, numRings, numSectors))
+		if i == 0 {
+			ring0, clump0 = ring, clump
+		}
+
+		minQ := 0.0
+		for _, q := range MeasureToomreQ(u, center, rMax, numRings) {
+			if q > 0 && (minQ == 0 || q < minQ) {
+				minQ = q
+			}
+		}
+
+		fmt.Printf("%d %e %e %.3f %.2f %.2f\n", generations[i], ring, clump, minQ, EFolds(ring, ring0), EFolds(clump, clump0))
+	}
+}
+
+
+// EFolds returns the number of e-foldings ln(a/a0) by which an amplitude grew from a0 to a, or 0 if either is 0.
+func EFolds(a, a0 float64) float64 {
+	if a <= 0 || a0 <= 0 {
+		return 0
+	}
+	return math.Log(a / a0)
+}
+
+
+
+// FourierAmplitude computes the m-th azimuthal Fourier mode of the surface density of a set of stars,
+// A_m = |sum of mass * exp(i m phi)| / sum of mass, over the stars within rMax of a center.
+// A_2 measures the strength of a bar (or of two-armed spirals): 0 for an axisymmetric disk, 1 for
+// stars lying on one line through the center.
+// Input:
+//   - stars: slice of Star pointers.
+//   - center: center of the disk.
+//   - m: order of the mode.
+//   - rMax: stars farther than rMax from the center are ignored.
+// Output:
+//   - the amplitude A_m, and the phase of the mode in radians (the angle of a bar's major axis for m = 2).
+func FourierAmplitude(stars []*Star, center OrderedPair, m int, rMax float64) (float64, float64) {
+	re, im, totalMass := 0.0, 0.0, 0.0
+
+	for _, s := range stars {
+		dx, dy, r := Distance(s.Position, center)
+		if r > rMax || r == 0 {
+			continue
+		}
+		phi := float64(m) * math.Atan2(dy, dx)
+		re += s.Mass * math.Cos(phi)
+		im += s.Mass * math.Sin(phi)
+		totalMass += s.Mass
+	}
+
+	if totalMass == 0 {
+		return 0, 0
+	}
+	return math.Hypot(re, im) / totalMass, math.Atan2(im, re) / float64(m)
+}
+
+
+// PrintBarStrength prints, for every snapshot, the m=2 Fourier amplitude of the disk stars (see DiskStars)
+// within the radius holding 90% of the first snapshot's disk, the angle of the bar in degrees,
+// and the largest amplitude of any of numRings equal-width rings with the radius of that ring.
+// The columns are meant to be plotted against the generation to see when a bar forms.
+func PrintBarStrength(universes []*Universe, generations []int, numRings int) {
+	fmt.Println("# bar strength (m=2 Fourier amplitude, rMax encloses 90% of the first snapshot's disk)")
+	fmt.Println("# generation A2 angle_deg A2_max_ring r_max_ring")
+	if len(universes) == 0 {
+		return
+	}
+
+	first := universes[0]
+	rMax := EnclosingRadius(DiskStars(first.Stars), CenterOfMass(first.Stars), 0.9)
+	ringWidth := rMax / float64(numRings)
+
+	for i, u := range universes {
+		center := CenterOfMass(u.Stars)
+		disk := DiskStars(u.Stars)
+		a2, phase := FourierAmplitude(disk, center, 2, rMax)
+
+		// split the disk into rings to find where the bar is strongest
+		rings := make([][]*Star, numRings)
+		for _, s := range disk {
+			_, _, r := Distance(s.Position, center)
+			if ring := int(r / ringWidth); ring < numRings {
+				rings[ring] = append(rings[ring], s)
+			}
+		}
+		ringMax, ringRadius := 0.0, 0.0
+		for k, ring := range rings {
+			if len(ring) < minRingStars {
+				continue
+			}
+			if a, _ := FourierAmplitude(ring, center, 2, rMax); a > ringMax {
+				ringMax, ringRadius = a, (float64(k)+0.5)*ringWidth
+			}
+		}
+
+		fmt.Printf("%d %.4f %.1f %.4f %e\n", generations[i], a2, phase*180/math.Pi, ringMax, ringRadius)
+	}
+}
+
+
+// minRingStars is the smallest number of disk stars in a ring for MeasureToomreQ to estimate Q there.
+const minRingStars = 10
+
+
+// DiskStars returns the stars of a disk without the point masses, such as central black holes,
+// that are more than 100 times heavier than the median star.
+func DiskStars(stars []*Star) []*Star {
+	if len(stars) == 0 {
+		return nil
+	}
+
+	masses := make([]float64, len(stars))
+	for i, s := range stars {
+		masses[i] = s.Mass
+	}
+	sort.Float64s(masses)
+	limit := 100 * masses[len(masses)/2]
+
+	var disk []*Star
+	for _, s := range stars {
+		if s.Mass <= limit {
+			disk = append(disk, s)
+		}
+	}
+	return disk
+}
+
+
+// MeasureToomreQ estimates the Toomre parameter Q = sigma_R kappa / (3.36 G Sigma) of a disk in
+// equal-width rings around a center. Sigma and the radial velocity dispersion sigma_R are measured from
+// the disk stars (see DiskStars) in each ring, and kappa is computed from the mass enclosed by the
+// middle of the ring as for InitializeDisk.
+// Input:
+//   - u: pointer to the Universe.
+//   - center: center of the disk.
+//   - rMax: outer radius of the last ring.
+//   - numRings: number of rings.
+// Output:
+//   - slice of numRings values of Q, 0 for rings with fewer than minRingStars disk stars.
+func MeasureToomreQ(u *Universe, center OrderedPair, rMax float64, numRings int) []float64 {
+	ringWidth := rMax / float64(numRings)
+	disk := DiskStars(u.Stars)
+
+	// velocities are measured relative to the bulk motion of the whole system
+	var bulk OrderedPair
+	totalMass := 0.0
+	for _, s := range u.Stars {
+		bulk.X += s.Mass * s.Velocity.X
+		bulk.Y += s.Mass * s.Velocity.Y
+		totalMass += s.Mass
+	}
+	if totalMass > 0 {
+		bulk.X /= totalMass
+		bulk.Y /= totalMass
+	}
+
+	count := make([]int, numRings)
+	mass := make([]float64, numRings)
+	sumVR := make([]float64, numRings)
+	sumVR2 := make([]float64, numRings)
+
+	for _, s := range disk {
+		dx, dy, R := Distance(s.Position, center)
+		ring := int(R / ringWidth)
+		if ring >= numRings || R == 0 {
+			continue
+		}
+		vR := ((s.Velocity.X-bulk.X)*dx + (s.Velocity.Y-bulk.Y)*dy) / R
+		count[ring]++
+		mass[ring] += s.Mass
+		sumVR[ring] += vR
+		sumVR2[ring] += vR * vR
+	}
+
+	// the enclosed mass includes every star, also the black hole
+	radii := make([]float64, len(u.Stars))
+	for i, s := range u.Stars {
+		_, _, radii[i] = Distance(s.Position, center)
+	}
+
+	q := make([]float64, numRings)
+	for i := range q {
+		if count[i] < minRingStars {
+			continue
+		}
+
+		R := (float64(i) + 0.5) * ringWidth
+		enclosed := 0.0
+		for k, s := range u.Stars {
+			if radii[k] < R {
+				enclosed += s.Mass
+			}
+		}
+
+		rIn, rOut := float64(i)*ringWidth, float64(i+1)*ringWidth
+		sigma := mass[i] / (math.Pi * (rOut*rOut - rIn*rIn))
+		kappa := math.Sqrt(G*enclosed/(R*R*R) + 2*math.Pi*G*sigma/R)
+
+		n := float64(count[i])
+		meanVR := sumVR[i] / n
+		sigmaR := math.Sqrt(math.Max(sumVR2[i]/n-meanVR*meanVR, 0))
+
+		q[i] = sigmaR * kappa / (3.36 * G * sigma)
+	}
+
+	return q
+}
@@ -0,0 +1,186 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the snapshot analyses in analysis.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package nbody
+
+import (
+	"bufio"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type FindGroupsTestCases struct {
+	id            string
+	universe      *Universe
+	linkingLength float64
+	expected      []int
+}
+
+
+// ReadFindGroups reads test data for the FindGroups function from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of FindGroupsTestCases structs containing star positions, linking length, and expected group sizes.
+func ReadFindGroups(fileName string) []FindGroupsTestCases {
+	file, err := os.Open(fileName)
+	Check(err)
+	defer file.Close()
+
+	var tests []FindGroupsTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		linkingLength, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		Check(err)
+
+		u := &Universe{}
+		for _, point := range strings.Split(parts[2], ",") {
+			fields := strings.Fields(point)
+			x, err := strconv.ParseFloat(fields[0], 64)
+			Check(err)
+			y, err := strconv.ParseFloat(fields[1], 64)
+			Check(err)
+			u.Stars = append(u.Stars, &Star{Position: OrderedPair{x, y}, Mass: 1})
+		}
+
+		var expected []int
+		for _, field := range strings.Fields(parts[3]) {
+			size, err := strconv.Atoi(field)
+			Check(err)
+			expected = append(expected, size)
+		}
+
+		tests = append(tests, FindGroupsTestCases{
+			id:            strings.TrimSpace(parts[0]),
+			universe:      u,
+			linkingLength: linkingLength,
+			expected:      expected,
+		})
+	}
+
+	return tests
+}
+
+
+// TestFindGroups tests the FindGroups function using data from a file.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestFindGroups(t *testing.T) {
+	tests := ReadFindGroups("../Tests/FindGroups.txt")
+
+	for _, test := range tests {
+		groups := FindGroups(test.universe, test.linkingLength)
+
+		sizes := make([]int, len(groups))
+		for i, g := range groups {
+			sizes[i] = len(g)
+		}
+
+		if len(sizes) != len(test.expected) {
+			t.Errorf("TestFindGroups(test %v) = %v, want %v", test.id, sizes, test.expected)
+			continue
+		}
+		for i := range sizes {
+			if sizes[i] != test.expected[i] {
+				t.Errorf("TestFindGroups(test %v) = %v, want %v", test.id, sizes, test.expected)
+				break
+			}
+		}
+	}
+}
+
+
+type FourierAmplitudeTestCases struct {
+	id                string
+	m                 int
+	stars             []*Star
+	expectedAmplitude float64
+	expectedPhase     float64
+}
+
+
+// ReadFourierAmplitude reads test data for the FourierAmplitude function from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of FourierAmplitudeTestCases structs containing the order, star positions, and expected amplitude and phase.
+func ReadFourierAmplitude(fileName string) []FourierAmplitudeTestCases {
+	file, err := os.Open(fileName)
+	Check(err)
+	defer file.Close()
+
+	var tests []FourierAmplitudeTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		m, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		Check(err)
+
+		var stars []*Star
+		for _, point := range strings.Split(parts[2], ",") {
+			fields := strings.Fields(point)
+			x, err := strconv.ParseFloat(fields[0], 64)
+			Check(err)
+			y, err := strconv.ParseFloat(fields[1], 64)
+			Check(err)
+			stars = append(stars, &Star{Position: OrderedPair{x, y}, Mass: 1})
+		}
+
+		expected := strings.Fields(parts[3])
+		amplitude, err := strconv.ParseFloat(expected[0], 64)
+		Check(err)
+		phase, err := strconv.ParseFloat(expected[1], 64)
+		Check(err)
+
+		tests = append(tests, FourierAmplitudeTestCases{
+			id:                strings.TrimSpace(parts[0]),
+			m:                 m,
+			stars:             stars,
+			expectedAmplitude: amplitude,
+			expectedPhase:     phase,
+		})
+	}
+
+	return tests
+}
+
+
+// TestFourierAmplitude tests the FourierAmplitude function using data from a file.
+// The phase is only compared when the expected amplitude is not zero.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestFourierAmplitude(t *testing.T) {
+	tests := ReadFourierAmplitude("../Tests/FourierAmplitude.txt")
+
+	for _, test := range tests {
+		amplitude, phase := FourierAmplitude(test.stars, OrderedPair{0, 0}, test.m, 10)
+		degrees := phase * 180 / math.Pi
+
+		if math.Abs(amplitude-test.expectedAmplitude) > 1e-5 ||
+			(test.expectedAmplitude != 0 && math.Abs(degrees-test.expectedPhase) > 1e-3) {
+			t.Errorf("TestFourierAmplitude(test %v) = (%v, %v), want (%v, %v)", test.id, amplitude, degrees, test.expectedAmplitude, test.expectedPhase)
+		}
+	}
+}
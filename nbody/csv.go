@@ -0,0 +1,235 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Initial universes read from CSV files, e.g. exported from a spreadsheet (option "-initial").
+
+package nbody
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// A CSV universe has the width of the universe on its first line, a header naming the columns, and one star per line:
+//
+//	# a binary star (comment lines start with "#")
+//	units: position=AU velocity=km/s mass=Msun radius=Rsun
+//	width,10
+//	x,y,vx,vy,mass,radius,r,g,b
+//	4,5,0,-15,1,1,255,255,0
+//	6,5,0,15,1,1,255,128,0
+//
+// The optional "units:" line is the unit declaration of the other input files (see ParseUnitsLine); without it,
+// everything is in SI units. The columns may come in any order; x, y, vx, vy, mass, and radius are required,
+// while the colors r, g, and b (0 to 255) are optional and default to white. Fields may be surrounded by spaces.
+// Stars of mass 0 are tracers (see IsTracer). The optional column pinned is 1 for stars that never move (see
+// Star.Pinned) and 0 for the others, and the optional column galaxy the number of the galaxy of the star (see
+// Star.Galaxy), from 1, or 0 for none. A rectangular universe has its height after the width: width,10,5.
+
+// csvColumns are the columns of a CSV universe, and csvRequired tells which must be present.
+var csvColumns = []string{"x", "y", "vx", "vy", "mass", "radius", "r", "g", "b", "pinned", "galaxy"}
+var csvRequired = map[string]bool{"x": true, "y": true, "vx": true, "vy": true, "mass": true, "radius": true}
+
+
+// ReadUniverseCSV reads a universe in the CSV format described above.
+// Input:
+//   - r: reader of the file.
+// Output:
+//   - the Universe in SI units, or an error naming the offending line.
+func ReadUniverseCSV(r io.Reader) (*Universe, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	units := SIUnits()
+	var u *Universe
+	column := map[string]int{} // index of every column, once the header is read
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+
+		switch {
+		// the unit declaration comes before the data
+		case u == nil && len(record) == 1 && IsUnitsLine(record[0]):
+			units, err = ParseUnitsLine(record[0])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+
+		case u == nil:
+			if (len(record) != 2 && len(record) != 3) || record[0] != "width" {
+				return nil, fmt.Errorf("line %d: expected width,<value> or width,<value>,<height> before the stars", line)
+			}
+			width, err := strconv.ParseFloat(record[1], 64)
+			if err != nil || width <= 0 {
+				return nil, fmt.Errorf("line %d: width must be a positive number, got %q", line, record[1])
+			}
+			u = NewUniverse(width * units.Position)
+			if len(record) == 3 {
+				height, err := strconv.ParseFloat(record[2], 64)
+				if err != nil || height <= 0 {
+					return nil, fmt.Errorf("line %d: height must be a positive number, got %q", line, record[2])
+				}
+				u.Height = height * units.Position
+			}
+
+		case len(column) == 0:
+			for i, name := range record {
+				if !isCSVColumn(name) {
+					return nil, fmt.Errorf("line %d: unknown column %q (use %s)", line, name, strings.Join(csvColumns, ", "))
+				}
+				if _, exists := column[name]; exists {
+					return nil, fmt.Errorf("line %d: duplicate column %q", line, name)
+				}
+				column[name] = i
+			}
+			for _, name := range csvColumns {
+				if _, exists := column[name]; csvRequired[name] && !exists {
+					return nil, fmt.Errorf("line %d: missing column %q", line, name)
+				}
+			}
+
+		default:
+			s, err := parseCSVStar(record, column, units)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			// numbered at the end, as AddStars would go through all stars for every star
+			u.Stars = append(u.Stars, s)
+		}
+	}
+
+	if u == nil || len(column) == 0 {
+		return nil, errors.New("expected width,<value> and a header of columns")
+	}
+	if len(u.Stars) == 0 {
+		return nil, errors.New("no stars")
+	}
+	u.AssignStarIDs()
+	return u, nil
+}
+
+
+// isCSVColumn reports whether name is a column of a CSV universe.
+func isCSVColumn(name string) bool {
+	for _, c := range csvColumns {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+
+// parseCSVStar parses one star of a CSV universe.
+// Input:
+//   - record: the fields of the line.
+//   - column: the index of every column in the header.
+//   - units: the unit declaration of the file.
+// Output:
+//   - the Star in SI units, or an error for a missing or invalid field.
+func parseCSVStar(record []string, column map[string]int, units Units) (*Star, error) {
+	if len(record) != len(column) {
+		return nil, fmt.Errorf("expected %d fields, got %d", len(column), len(record))
+	}
+
+	values := map[string]float64{}
+	for name, i := range column {
+		v, err := strconv.ParseFloat(record[i], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number, got %q", name, record[i])
+		}
+		values[name] = v
+	}
+
+	if values["mass"] < 0 || values["radius"] < 0 {
+		return nil, fmt.Errorf("mass and radius must not be negative")
+	}
+	s := NewStar(OrderedPair{X: values["x"] * units.Position, Y: values["y"] * units.Position},
+		OrderedPair{X: values["vx"] * units.Velocity, Y: values["vy"] * units.Velocity},
+		values["mass"]*units.Mass, values["radius"]*units.Radius)
+
+	for _, c := range []struct {
+		name  string
+		color *uint8
+	}{{"r", &s.Red}, {"g", &s.Green}, {"b", &s.Blue}} {
+		if _, exists := column[c.name]; !exists {
+			continue
+		}
+		v := values[c.name]
+		if v < 0 || v > 255 || v != float64(int(v)) {
+			return nil, fmt.Errorf("color %s must be an integer from 0 to 255, got %v", c.name, v)
+		}
+		*c.color = uint8(v)
+	}
+
+	if _, exists := column["pinned"]; exists {
+		if v := values["pinned"]; v != 0 && v != 1 {
+			return nil, fmt.Errorf("pinned must be 0 or 1, got %v", v)
+		}
+		s.Pinned = values["pinned"] == 1
+	}
+
+	if _, exists := column["galaxy"]; exists {
+		if v := values["galaxy"]; v < 0 || v > math.MaxInt32 || v != float64(int(v)) {
+			return nil, fmt.Errorf("galaxy must be a non-negative integer, got %v", v)
+		}
+		s.Galaxy = int32(values["galaxy"])
+	}
+
+	return s, nil
+}
+
+
+// LoadUniverseCSV reads a CSV universe file.
+func LoadUniverseCSV(fileName string) (*Universe, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	u, err := ReadUniverseCSV(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	return u, nil
+}
+
+
+// LoadInitialUniverse reads the initial universe given with -initial, in the format of its extension.
+// A JSON universe file (a snapshot of another run, see WriteUniverseJSON) starts the run from that universe,
+// with its simulated time; the new run counts its generations from 0.
+// Input:
+//   - fileName: a ".csv", ".json", or ".tipsy" file (see ReadTipsy).
+// Output:
+//   - the Universe, or an error if the file cannot be read or has an unknown extension.
+func LoadInitialUniverse(fileName string) (*Universe, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return LoadUniverseCSV(fileName)
+	case ".json":
+		u, _, err := ReadUniverseJSON(fileName)
+		return u, err
+	case ".tipsy":
+		return LoadTipsy(fileName)
+	}
+	return nil, fmt.Errorf("%s: unknown initial universe format (use a .csv, .json, or .tipsy file)", fileName)
+}
@@ -0,0 +1,125 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for CSV universes in csv.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package nbody
+
+import (
+	"bufio"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type ReadUniverseCSVTestCases struct {
+	id       string
+	file     string
+	width    float64
+	height   float64
+	stars    []*Star
+	hasError bool
+}
+
+
+// ReadReadUniverseCSV reads test data for ReadUniverseCSV from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of ReadUniverseCSVTestCases structs containing the CSV file and the expected universe or error.
+func ReadReadUniverseCSV(fileName string) []ReadUniverseCSVTestCases {
+	file, err := os.Open(fileName)
+	Check(err)
+	defer file.Close()
+
+	var tests []ReadUniverseCSVTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		test := ReadUniverseCSVTestCases{id: strings.TrimSpace(parts[0])}
+		var lines []string
+		for _, l := range strings.Split(parts[1], ";") {
+			lines = append(lines, strings.TrimSpace(l))
+		}
+		test.file = strings.Join(lines, "\n")
+
+		if strings.TrimSpace(parts[3]) == "error" {
+			test.hasError = true
+			tests = append(tests, test)
+			continue
+		}
+
+		size := strings.Fields(parts[2])
+		test.width, err = strconv.ParseFloat(size[0], 64)
+		Check(err)
+		if len(size) > 1 {
+			test.height, err = strconv.ParseFloat(size[1], 64)
+			Check(err)
+		}
+		for _, text := range strings.Split(parts[3], ";") {
+			var v [9]float64
+			fields := strings.Split(text, ",")
+			for i := range v {
+				v[i], err = strconv.ParseFloat(strings.TrimSpace(fields[i]), 64)
+				Check(err)
+			}
+			test.stars = append(test.stars, &Star{
+				Position: OrderedPair{X: v[0], Y: v[1]},
+				Velocity: OrderedPair{X: v[2], Y: v[3]},
+				Mass:     v[4],
+				Radius:   v[5],
+				Red:      uint8(v[6]),
+				Green:    uint8(v[7]),
+				Blue:     uint8(v[8]),
+				Pinned:   len(fields) > 9 && strings.TrimSpace(fields[9]) == "1",
+				ID:       int64(len(test.stars)),
+			})
+			if len(fields) > 10 {
+				galaxy, err := strconv.Atoi(strings.TrimSpace(fields[10]))
+				Check(err)
+				test.stars[len(test.stars)-1].Galaxy = int32(galaxy)
+			}
+		}
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestReadUniverseCSV tests that CSV universes are read in SI units and that invalid files are rejected.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestReadUniverseCSV(t *testing.T) {
+	tests := ReadReadUniverseCSV("../Tests/ReadUniverseCSV.txt")
+
+	for _, test := range tests {
+		u, err := ReadUniverseCSV(strings.NewReader(test.file))
+
+		if test.hasError {
+			if err == nil {
+				t.Errorf("TestReadUniverseCSV(test %v) = no error, want an error", test.id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestReadUniverseCSV(test %v) = error %v, want none", test.id, err)
+			continue
+		}
+		if u.Width != test.width || u.Height != test.height || !reflect.DeepEqual(u.Stars, test.stars) {
+			t.Errorf("TestReadUniverseCSV(test %v) = width %v, height %v, stars %v, want width %v, height %v, stars %v",
+				test.id, u.Width, u.Height, u.Stars, test.width, test.height, test.stars)
+		}
+	}
+}
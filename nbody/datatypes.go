@@ -0,0 +1,52 @@
+// Author: Yu-Lun Chen
+// Date: 2025-10-24
+// Description: Definition of the universe, galaxy, and star datatypes using in the BarnesHut project.
+
+package nbody
+
+const G = 6.67408e-11 // gravitational constant -- don't change this!
+
+const SolarMass = 1.989e30 // mass of sun -- don't change this!
+
+const BlackHoleMass = 8e36 // mass of black hole -- don't change!
+
+// Universe contains a slice of pointers to stars and a width parameter.
+// We conceptualize the universe as a square -- stars may go outside the universe
+// but the width dictates relative distances when drawing the universe.
+// A universe with a Height is a rectangle of Width by Height instead (see Bounds).
+// Universe, Star, and OrderedPair are exported with JSON tags, so snapshots can be marshaled directly
+// and other programs can build universes themselves (see NewUniverse and NewStar).
+type Universe struct {
+	Stars  []*Star `json:"stars"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height,omitempty"` // height of a rectangular universe, or 0 for a square one
+	Time   float64 `json:"time,omitempty"`   // simulated time since the initial universe, in seconds
+}
+
+// Galaxy is a potentially useful object holding a list of star positions
+type Galaxy []*Star
+
+// Star is analogous to the "Body" object from the jupiter simulations.
+// All fields have a fixed size (no pointers, slices, or strings), so a Star can also be
+// written and read with encoding/binary.
+type Star struct {
+	Position     OrderedPair `json:"position"`
+	Velocity     OrderedPair `json:"velocity"`
+	Acceleration OrderedPair `json:"acceleration"`
+	Mass         float64     `json:"mass"`
+	Radius       float64     `json:"radius"`
+	Softening    float64     `json:"softening,omitempty"` // softening length set by adaptive softening; 0 uses the run's softening
+	Age          float64     `json:"age,omitempty"`       // simulated time since the star was created (0 in the initial universe)
+	Red          uint8       `json:"red"`
+	Blue         uint8       `json:"blue"`
+	Green        uint8       `json:"green"`
+	Pinned       bool        `json:"pinned,omitempty"` // a pinned star pulls on the others but never moves (see IntegrateInPlace)
+	Galaxy       int32       `json:"galaxy,omitempty"` // galaxy the star was created in, numbered from 1 (see AddGalaxy); 0 for none
+	ID           int64       `json:"id"`               // number of the star for the whole run, kept through copies and mergers (see AssignStarIDs)
+}
+
+// OrderedPair represents a point or vector.
+type OrderedPair struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
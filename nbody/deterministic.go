@@ -0,0 +1,89 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: The random number generator of the program and its fixed seed used by tests.
+
+package nbody
+
+import (
+	"math/rand"
+	"time"
+)
+
+// DeterministicSeed is the seed of RNG set by SetDeterministic.
+const DeterministicSeed = 1
+
+// Seed is the seed of RNG, reported in output names (see ExpandName) and set by the -seed option.
+var Seed = time.Now().UnixNano()
+
+// RNG is the only source of random numbers of the program. It is seeded from the clock
+// unless -seed or -deterministic reseeds it. It is not safe for concurrent use,
+// so random initial conditions are always generated on one goroutine.
+// source counts the values RNG drew, so a checkpoint can restore its state (see RandomState).
+var source = newCountingSource(Seed)
+var RNG = rand.New(source)
+
+
+// countingSource is a random source that counts the values drawn from it.
+type countingSource struct {
+	src   rand.Source64
+	draws uint64
+}
+
+func newCountingSource(seed int64) *countingSource {
+	return &countingSource{src: rand.NewSource(seed).(rand.Source64)}
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.draws++
+	return s.src.Uint64()
+}
+
+func (s *countingSource) Seed(v int64) {
+	s.src.Seed(v)
+	s.draws = 0
+}
+
+
+// RandomState returns the state of RNG: its seed and the number of values drawn since it was seeded.
+func RandomState() (int64, uint64) {
+	return Seed, source.draws
+}
+
+
+// SetRandomState restores a state returned by RandomState: RNG is reseeded and the drawn values are skipped,
+// so it continues with the same numbers as the run the state was taken from.
+func SetRandomState(newSeed int64, draws uint64) {
+	Seed = newSeed
+	source = newCountingSource(Seed)
+	for i := uint64(0); i < draws; i++ {
+		source.Int63()
+	}
+	RNG = rand.New(source)
+}
+
+
+// SetSeed reseeds RNG (option "-seed"), so every random galaxy, cluster, and event drawn afterwards repeats
+// exactly in another run with the same seed and arguments.
+func SetSeed(newSeed int64) {
+	SetRandomState(newSeed, 0)
+}
+
+
+// SetDeterministic reseeds RNG with DeterministicSeed (option "-deterministic"), so random galaxies and demos
+// repeat exactly and two runs with the same arguments produce identical output.
+// Nothing else needs switching: every parallel part of the program (tree builds, forces, drawing, GIF and WebP
+// encoding) always writes per-item results that are combined in a fixed order, so its results never depend on
+// scheduling or on -workers: the stars are split into chunks of forceChunk stars whatever the number of workers,
+// and sums over them are added in order (see ParallelSum). TestDeterministicRun checks this for the tree builds,
+// and TestDeterministicSnapshots compares the snapshot files of whole runs.
+//
+// Input: none.
+// Output: none.
+func SetDeterministic() {
+	SetRandomState(DeterministicSeed, 0)
+}
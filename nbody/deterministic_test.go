@@ -0,0 +1,17 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the deterministic mode in deterministic.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package nbody
+
+import (
+	"os"
+	"testing"
+)
+
+// TestMain runs every test of the package in deterministic mode, so results are stable between runs.
+func TestMain(m *testing.M) {
+	SetDeterministic()
+	os.Exit(m.Run())
+}
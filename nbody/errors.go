@@ -0,0 +1,42 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Error handling shared by the packages: fatal checks and numerical errors.
+
+package nbody
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// StatusOutput receives the progress messages of a simulation run; batch runs discard them and the event log,
+// so standard output only carries the JSON summary.
+var StatusOutput io.Writer = os.Stdout
+
+// A NumericalError is a failure of the simulation itself, such as a star at a non-finite position.
+type NumericalError struct {
+	Err error
+}
+
+func (e NumericalError) Error() string { return e.Err.Error() }
+
+
+// CheckFinite returns a NumericalError naming the first star of a universe with a non-finite position, velocity,
+// or acceleration (see FiniteStar).
+func CheckFinite(u *Universe, generation int) error {
+	for i, s := range u.Stars {
+		if !FiniteStar(s) {
+			return NumericalError{fmt.Errorf("generation %d: star %d (ID %d) has position %v, velocity %v, and acceleration %v",
+				generation, i, s.ID, s.Position, s.Velocity, s.Acceleration)}
+		}
+	}
+	return nil
+}
+
+
+func Check(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,83 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Universes shared by the tests and benchmarks of all packages, kept here once so they cannot drift apart.
+
+package nbody
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// TreeBuildTestCases is a universe of Tests/TreeBuild.txt.
+type TreeBuildTestCases struct {
+	ID       string
+	Universe *Universe
+}
+
+// BenchmarkSizes are the numbers of stars of the benchmarks, from 1e2 to 1e5.
+var BenchmarkSizes = []int{100, 1000, 10000, 100000}
+
+
+// ReadTreeBuild reads the universes of Tests/TreeBuild.txt, with which the tests compare tree builds and the
+// ways of saving a universe. It panics if the file cannot be read, like the other readers of test data.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of TreeBuildTestCases structs containing the universes.
+func ReadTreeBuild(fileName string) []TreeBuildTestCases {
+	file, err := os.Open(fileName)
+	Check(err)
+	defer file.Close()
+
+	var tests []TreeBuildTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 3 {
+			continue
+		}
+
+		// the universe is "width" or "width height"
+		size := strings.Fields(parts[1])
+		width, err := strconv.ParseFloat(size[0], 64)
+		Check(err)
+
+		u := &Universe{Width: width}
+		if len(size) > 1 {
+			u.Height, err = strconv.ParseFloat(size[1], 64)
+			Check(err)
+		}
+		for _, star := range strings.Split(parts[2], ",") {
+			fields := strings.Fields(star)
+			var nums [3]float64
+			for i := range nums {
+				nums[i], err = strconv.ParseFloat(fields[i], 64)
+				Check(err)
+			}
+			u.Stars = append(u.Stars, &Star{Position: OrderedPair{nums[0], nums[1]}, Mass: nums[2]})
+		}
+
+		tests = append(tests, TreeBuildTestCases{
+			ID:       strings.TrimSpace(parts[0]),
+			Universe: u,
+		})
+	}
+
+	return tests
+}
+
+
+// BenchmarkUniverse returns a galaxy of n stars like the ones of the bench command, drawn with the same seed
+// every time so that the benchmarks of different versions of the code time the same universe.
+func BenchmarkUniverse(n int) *Universe {
+	SetSeed(DeterministicSeed)
+	g := InitializeGalaxy(n, 4e21, 5e22, 5e22)
+	return InitializeUniverse([]Galaxy{g}, 1e23)
+}
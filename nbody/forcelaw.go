@@ -0,0 +1,121 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Laws of the pairwise force between stars (option "-force-law"): Newtonian gravity, the default, and
+// modified laws for experiments.
+
+package nbody
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A ForceLaw gives the force between two point masses. The tree walk applies it to every pair of stars and to
+// every node it accepts, with the mass of the node at its center of mass (see PointMassForce and ComputeForce),
+// so a law is plugged in without changing the walk. The law of a run is set with Options.WithForceLaw. The force acts along the line between the masses.
+type ForceLaw interface {
+	// Force returns the magnitude of the force on a mass m1 from a mass m2 at distance d > 0, with eps the
+	// softening length of the pair; positive forces attract and negative ones repel. The force must be
+	// proportional to m1, so the acceleration of a star (and of a tracer, pulled as a unit mass) does not
+	// depend on its mass.
+	Force(m1, m2, d, eps float64) float64
+}
+
+// mondA0 is the acceleration scale a0 of the MOND law when -force-law mond gives none, in m/s^2.
+const mondA0 = 1.2e-10
+
+
+// Newtonian is Newtonian gravity G m1 m2 / d^2, with Plummer softening G m1 m2 d / (d^2 + eps^2)^(3/2).
+type Newtonian struct{}
+
+// Force returns the Newtonian force (see ForceLaw).
+func (Newtonian) Force(m1, m2, d, eps float64) float64 {
+	r2 := d*d + eps*eps
+	f := G * m1 * m2 / r2
+	if eps > 0 {
+		f *= d / math.Sqrt(r2)
+	}
+	return f
+}
+
+
+// Yukawa is Newtonian gravity with a Yukawa term of strength alpha and range lambda, the force of the potential
+// -G m1 m2 / d (1 + alpha e^(-d/lambda)): within about lambda gravity is 1 + alpha times stronger (weaker for
+// negative alpha), and far beyond it Newtonian. The softening applies to the Newtonian factor.
+type Yukawa struct {
+	alpha  float64
+	lambda float64 // in m
+}
+
+// Force returns the Yukawa force (see ForceLaw).
+func (y Yukawa) Force(m1, m2, d, eps float64) float64 {
+	return Newtonian{}.Force(m1, m2, d, eps) * (1 + y.alpha*(1+d/y.lambda)*math.Exp(-d/y.lambda))
+}
+
+
+// MOND is a MOND-like law: the Newtonian acceleration a_N of every pair is raised to
+// a_N / 2 + sqrt(a_N^2 / 4 + a_N a0), the "simple" interpolation, which is Newtonian for a_N >> a0 and
+// sqrt(G m2 a0) / d, giving flat rotation curves, for a_N << a0. Unlike real MOND it is applied pair by pair
+// and node by node, so a node does not pull like the sum of its stars.
+type MOND struct {
+	a0 float64 // in m/s^2
+}
+
+// Force returns the MOND-like force (see ForceLaw).
+func (m MOND) Force(m1, m2, d, eps float64) float64 {
+	aN := Newtonian{}.Force(1, m2, d, eps)
+	return m1 * (aN/2 + math.Sqrt(aN*aN/4+aN*m.a0))
+}
+
+
+// Repulsive is Newtonian gravity with the opposite sign, a test force that pushes every pair apart.
+type Repulsive struct{}
+
+// Force returns the repulsive force (see ForceLaw).
+func (Repulsive) Force(m1, m2, d, eps float64) float64 {
+	return -Newtonian{}.Force(m1, m2, d, eps)
+}
+
+
+// ParseForceLaw parses the value of -force-law: "newton", "yukawa:alpha,lambda" (lambda in m), "mond" or
+// "mond:a0" (a0 in m/s^2, 1.2e-10 by default), or "repulsive".
+// Output:
+//   - the ForceLaw, or an error if the name is unknown or its parameters are invalid.
+func ParseForceLaw(text string) (ForceLaw, error) {
+	name, params, hasParams := strings.Cut(text, ":")
+	var values []float64
+	if hasParams {
+		for _, field := range strings.Split(params, ",") {
+			v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil || !IsFinite(v) {
+				return nil, fmt.Errorf("invalid force law %q: %q is not a number", text, field)
+			}
+			values = append(values, v)
+		}
+	}
+
+	switch {
+	case (name == "newton" || name == "repulsive") && hasParams:
+		return nil, fmt.Errorf("invalid force law %q: %s has no parameters", text, name)
+	case name == "newton":
+		return Newtonian{}, nil
+	case name == "repulsive":
+		return Repulsive{}, nil
+	case name == "yukawa":
+		if len(values) != 2 || values[1] <= 0 {
+			return nil, fmt.Errorf("invalid force law %q: expected yukawa:alpha,lambda with a positive range lambda in m", text)
+		}
+		return Yukawa{alpha: values[0], lambda: values[1]}, nil
+	case name == "mond":
+		if !hasParams {
+			return MOND{a0: mondA0}, nil
+		}
+		if len(values) != 1 || values[0] <= 0 {
+			return nil, fmt.Errorf("invalid force law %q: expected mond or mond:a0 with a positive a0 in m/s^2", text)
+		}
+		return MOND{a0: values[0]}, nil
+	}
+	return nil, fmt.Errorf("unknown force law %q (use newton, yukawa:alpha,lambda, mond[:a0], or repulsive)", text)
+}
@@ -0,0 +1,102 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the force laws in forcelaw.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package nbody
+
+import (
+	"bufio"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type ForceLawTestCases struct {
+	id          string
+	law         string
+	m1, m2      float64
+	d, eps      float64
+	expected    float64
+	expectError bool
+}
+
+
+// ReadForceLaw reads test data for the force laws from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of ForceLawTestCases structs containing the laws, the pairs, and the expected forces.
+func ReadForceLaw(fileName string) []ForceLawTestCases {
+	file, err := os.Open(fileName)
+	Check(err)
+	defer file.Close()
+
+	var tests []ForceLawTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		var nums [4]float64
+		for i, field := range strings.Fields(parts[2]) {
+			nums[i], err = strconv.ParseFloat(field, 64)
+			Check(err)
+		}
+
+		test := ForceLawTestCases{
+			id:  strings.TrimSpace(parts[0]),
+			law: strings.TrimSpace(parts[1]),
+			m1:  nums[0],
+			m2:  nums[1],
+			d:   nums[2],
+			eps: nums[3],
+		}
+		if expected := strings.TrimSpace(parts[3]); expected == "error" {
+			test.expectError = true
+		} else {
+			test.expected, err = strconv.ParseFloat(expected, 64)
+			Check(err)
+		}
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestForceLaw tests that ParseForceLaw accepts the valid values of -force-law and rejects the others, and that
+// every law gives the expected force.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestForceLaw(t *testing.T) {
+	tests := ReadForceLaw("../Tests/ForceLaw.txt")
+
+	for _, test := range tests {
+		law, err := ParseForceLaw(test.law)
+
+		if test.expectError {
+			if err == nil {
+				t.Errorf("TestForceLaw(test %v) = %v, want an error", test.id, law)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestForceLaw(test %v) = error %v, want none", test.id, err)
+			continue
+		}
+
+		result := law.Force(test.m1, test.m2, test.d, test.eps)
+		if math.Abs(result-test.expected) > 1e-12*math.Abs(test.expected) {
+			t.Errorf("TestForceLaw(test %v) = %v, want %v", test.id, result, test.expected)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Author: Yu-Lun Chen
+// Date: 2025-10-24
+// Description: Functions on stars and universes shared by the force calculations of the BarnesHut simulation.
+
+package nbody
+
+import "math"
+
+// A force that overflows, e.g. between two stars almost at the same place without softening, or a time step far
+// too large gives a star a NaN or infinite value. It reaches every other star through the tree in the next
+// generation, and the run goes on to draw an animation of empty frames. Options.FiniteCheck decides what a run
+// does about it:
+//
+//	abort  the step fails with a NumericalError naming the star and the generation (the default)
+//	clamp  non-finite accelerations are set to zero, so the star coasts for the step, and stars whose position or
+//	       velocity is no longer finite are removed from the universe, with a warning
+//	off    nothing is checked
+//
+// The checks go once through the accelerations of every force computation and once through the stars of every
+// generation, which costs little next to the tree walks.
+
+// IsFinite reports whether x is neither NaN nor infinite.
+func IsFinite(x float64) bool {
+	return !math.IsNaN(x) && !math.IsInf(x, 0)
+}
+
+
+// FiniteStar reports whether the position, the velocity, and the acceleration of a star are all finite.
+func FiniteStar(s *Star) bool {
+	return IsFinite(s.Position.X) && IsFinite(s.Position.Y) && IsFinite(s.Velocity.X) && IsFinite(s.Velocity.Y) &&
+		IsFinite(s.Acceleration.X) && IsFinite(s.Acceleration.Y)
+}
+
+
+// IsInsideUniverse checks if a star is within the bounds of the universe.
+// Input:
+//   - s: pointer to the Star to check.
+//   - width: width of the universe.
+//   - height: height of the universe (see Bounds).
+// Output:
+//   - Boolean indicating whether the star is inside the universe.
+func IsInsideUniverse(s *Star, width, height float64) bool {
+	return s.Position.X >= 0 && s.Position.X <= width && s.Position.Y >= 0 && s.Position.Y <= height
+}
+
+
+// Bounds returns the width and the height of the universe. A universe without a Height is a square.
+func (u *Universe) Bounds() (float64, float64) {
+	if u.Height > 0 {
+		return u.Width, u.Height
+	}
+	return u.Width, u.Width
+}
+
+
+// Center returns the center of the universe.
+func (u *Universe) Center() OrderedPair {
+	width, height := u.Bounds()
+	return OrderedPair{X: width / 2, Y: height / 2}
+}
+
+
+// RootWidth returns the width of the sector of the root of the quadtree: the square of the larger side of the
+// universe, with its lower corner at the origin, so it covers a rectangular universe too.
+func (u *Universe) RootWidth() float64 {
+	return math.Max(u.Bounds())
+}
+
+
+// PointMassForce computes the force of a point mass (such as the center of mass of a node) on a star,
+// with a force law.
+// Input:
+//   - law: the ForceLaw of the run.
+//   - mass: mass of the star pulled.
+//   - pull: mass of the point mass.
+//   - dX, dY, d: offset and distance from the star to the point mass, d > 0.
+//   - eps: softening length of the pair.
+// Output:
+//   - OrderedPair representing the force vector on the star.
+func PointMassForce(law ForceLaw, mass, pull, dX, dY, d, eps float64) OrderedPair {
+	f := law.Force(mass, pull, d, eps)
+	return OrderedPair{X: f * (dX / d), Y: f * (dY / d)}
+}
+
+
+// ComputeForce calculates the gravitational force of one star on another with a force law.
+// With Newtonian softening the force is G m1 m2 d / (d^2 + eps^2)^(3/2), which stays finite in close encounters.
+// Input:
+//   - b: pointer to the Star pulling.
+//   - b2: pointer to the Star pulled.
+//   - softening: softening length of the run (see PairSoftening).
+//   - law: the ForceLaw of the run.
+// Output:
+//   - OrderedPair representing the force vector on b2.
+func ComputeForce(b, b2 *Star, softening float64, law ForceLaw) OrderedPair{
+	var force OrderedPair
+
+	dX, dY, d := Distance(b.Position, b2.Position)
+	
+	// check if denominator == 0
+	if d == 0.0 {
+		return force
+	}
+	eps := PairSoftening(b2, b, softening)
+	F := law.Force(b2.Mass, b.Mass, d, eps)
+
+	force.X = F * (dX / d)
+	force.Y = F * (dY / d)
+
+	return force
+}
+
+
+// Distance computes the difference in x, y, and Euclidean distance between two points.
+// Input:
+//   - p1: first OrderedPair.
+//   - p2: second OrderedPair.
+// Output:
+//   - delta_x, delta_y, and Euclidean distance between p1 and p2.
+func Distance(p1, p2 OrderedPair) (float64, float64, float64) {
+	// this is the distance formula from days of precalculus long ago ...
+	deltaX := p1.X - p2.X
+	deltaY := p1.Y - p2.Y
+	return deltaX, deltaY, math.Sqrt(deltaX * deltaX + deltaY * deltaY)
+}
+
+
+// CopyUniverse creates a deep copy of the given Universe.
+// Input:
+//   - u: pointer to the Universe to copy.
+// Output:
+//   - Pointer to the new, copied Universe.
+func CopyUniverse(u *Universe) *Universe {
+	newUniverse := &Universe{Width: u.Width, Height: u.Height, Time: u.Time}
+
+	for _, s := range u.Stars {
+		copy_s := &Star{
+			Position: OrderedPair{X: s.Position.X, Y: s.Position.Y},
+			Velocity: OrderedPair{X: s.Velocity.X, Y: s.Velocity.Y},
+			Acceleration: OrderedPair{X: s.Acceleration.X, Y: s.Acceleration.Y},
+			Mass: s.Mass,
+			Radius: s.Radius,
+			Softening: s.Softening,
+			Age: s.Age,
+			Red: s.Red,
+			Blue: s.Blue,
+			Green: s.Green,
+			Pinned: s.Pinned,
+			Galaxy: s.Galaxy,
+			ID: s.ID,
+		}
+		
+		newUniverse.Stars = append(newUniverse.Stars, copy_s)
+	}
+
+	return newUniverse
+}
+
+
+// PairSoftening returns the softening length used between two stars.
+// A star without its own softening length (Star.Softening is 0) uses the softening of the run.
+// If either star has its own length, the lengths are combined as sqrt((eps1^2 + eps2^2) / 2),
+// which is symmetric, so the two stars still pull on each other equally.
+// Input:
+//   - a, b: pointers to the two Stars (b may be the dummy star of a tree node).
+//   - softening: softening length of the run.
+// Output:
+//   - the softening length of the pair.
+func PairSoftening(a, b *Star, softening float64) float64 {
+	if a.Softening == 0 && b.Softening == 0 {
+		return softening
+	}
+
+	epsA, epsB := a.Softening, b.Softening
+	if epsA == 0 {
+		epsA = softening
+	}
+	if epsB == 0 {
+		epsB = softening
+	}
+	return math.Sqrt((epsA*epsA + epsB*epsB) / 2)
+}
@@ -0,0 +1,176 @@
+// Author: Yu-Lun Chen
+// Date: 2025-10-24
+// Description: Testing functions for the subroutines in functions.go.
+// Each txt file contains input testing cases and the expected output for each cases.
+
+package nbody
+
+import (
+	"bufio"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type IsInsideTestCases struct {
+	star Star
+	width float64
+	height float64
+	expected bool
+}
+
+
+type DistanceTestCases struct {
+	id string
+	x1, y1, x2, y2 float64
+	expectedDeltaX, expectedDeltaY, expectedDistance float64
+}
+
+
+// ReadIsInsideUniverse reads test data for the IsInsideUniverse function from a file.
+// Input: file_name (string) - path to the test data file.
+// Output: slice of IsInsideTestCases structs containing star, width, height, and expected result.
+// A line without a height is a square universe.
+func ReadIsInsideUniverse(fileName string) []IsInsideTestCases {
+	file, err := os.Open(fileName)
+	Check(err)
+	defer file.Close()
+
+	var tests []IsInsideTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Fields(line)
+		if len(parts) == 4 {
+			parts = []string{parts[0], parts[1], parts[2], parts[2], parts[3]}
+		}
+		if len(parts) != 5 {
+			continue
+		}
+
+		x, _ := strconv.ParseFloat(parts[0], 64)
+		y, _ := strconv.ParseFloat(parts[1], 64)
+		width, _ := strconv.ParseFloat(parts[2], 64)
+		height, _ := strconv.ParseFloat(parts[3], 64)
+		expected, _ := strconv.ParseBool(parts[4])
+
+		tests = append(tests, IsInsideTestCases{
+			star: Star{
+				Position: OrderedPair{x, y},
+			},
+			width: width,
+			height: height,
+			expected: expected,
+		})
+	}
+
+	return tests
+}
+
+
+// ReadDistance reads test data for the Distance function from a file.
+// Input: file_name (string) - path to the test data file.
+// Output: slice of DistanceTestCases structs containing points and expected deltas/distances.
+func ReadDistance(fileName string) []DistanceTestCases {
+	file, err := os.Open(fileName)
+	Check(err)
+	defer file.Close()
+
+	var tests []DistanceTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 3 {
+			continue
+		}
+
+		id := strings.TrimSpace(parts[0])
+		points := strings.Fields(strings.TrimSpace(parts[1]))
+		expectedParts := strings.Fields(parts[2])
+
+		if len(points) != 4 {
+			continue
+		}
+
+		x1, err := strconv.ParseFloat(points[0], 64)
+		Check(err)
+		y1, err := strconv.ParseFloat(points[1], 64)
+		Check(err)
+		x2, err := strconv.ParseFloat(points[2], 64)
+		Check(err)
+		y2, err := strconv.ParseFloat(points[3], 64)
+		Check(err)
+		expectedDeltaX, err := strconv.ParseFloat(expectedParts[0], 64)
+		Check(err)
+		expectedDeltaY, err := strconv.ParseFloat(expectedParts[1], 64)
+		Check(err)
+		expectedDistance, err := strconv.ParseFloat(expectedParts[2], 64)
+		Check(err)
+
+		tests = append(tests, DistanceTestCases{
+			id:       id,
+			x1:       x1,
+			y1:       y1,
+			x2:       x2,
+			y2:       y2,
+			expectedDeltaX: expectedDeltaX,
+			expectedDeltaY: expectedDeltaY,
+			expectedDistance: expectedDistance,
+		})
+	}
+	return tests
+}
+
+
+// TestIsInsideUniverse tests the IsInsideUniverse function using data from a file.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestIsInsideUniverse(t *testing.T) {
+	tests := ReadIsInsideUniverse("../Tests/IsInsideUniverse.txt")
+
+	for i, test := range tests {
+		result := IsInsideUniverse(&test.star, test.width, test.height)
+		expectedResult := test.expected
+
+		if result != expectedResult {
+			t.Errorf("TestIsInsideUniverse(test %v) = %v, want %v",
+				i, result, expectedResult)
+		}
+	}
+}
+
+
+// TestDistance tests the Distance function using data from a file.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestDistance(t *testing.T) {
+	tests := ReadDistance("../Tests/Distance.txt")
+
+	for _, test := range tests {
+		p1 := OrderedPair{X:test.x1, Y:test.y1}
+		p2 := OrderedPair{X:test.x2, Y:test.y2}
+
+		deltaX, deltaY, distance := Distance(p1, p2)
+
+		if math.Abs(deltaX - test.expectedDeltaX) > 1e-3 ||
+			math.Abs(deltaY - test.expectedDeltaY) > 1e-3 ||
+			math.Abs(distance - test.expectedDistance) > 1e-3 {
+				t.Errorf("TestDistance(test %v) = (deltaX: %v, deltaY: %v, distance: %v), want (x: %v, y:%v, distance: %v)",
+					test.id, deltaX, deltaY, distance, test.expectedDeltaX, test.expectedDeltaY, test.expectedDistance)
+			}
+	}
+}
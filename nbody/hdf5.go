@@ -0,0 +1,418 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Snapshots as HDF5 files, to analyze runs in Python with h5py (option "-snapshot-format hdf5").
+
+package nbody
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+)
+
+// The HDF5 file format is described in https://docs.hdfgroup.org/hdf5/develop/_f_m_t3.html.
+// The writer below is deliberately simple and writes the oldest version of every structure, which every HDF5
+// library reads: a version 0 superblock, a root group with a symbol table (a local heap of names, a B-tree of one
+// node, and one symbol table node), version 1 object headers, and contiguous datasets in little endian. Nothing is
+// ever rewritten, so there is no free space, and all the structures of a file are laid out before it is written.
+//
+// The tests read the files back with ReadHDF5Root, a reader written from the same specification that follows every
+// address as the HDF5 library does. They do not use the HDF5 library, h5py, or a file written by either, so they
+// cannot catch a misreading of the specification shared by the writer and the reader; to check a snapshot against
+// the reference implementation, run h5dump -H snapshot_00000000.h5, or h5py.File(name).visititems(print) in Python.
+//
+// The root group of a snapshot holds one dataset per field of the stars, one row per star in the order of the
+// universe, and the generation, simulated time, and size of the universe as attributes:
+//
+//	/acceleration   float64 (N, 2)  m/s^2
+//	/age            float64 (N)     s
+//	/color          uint8   (N, 3)  red, green, blue
+//	/galaxy         int32   (N)     galaxy the star was created in, or 0 (see Star.Galaxy)
+//	/id             int64   (N)     ID of the star (see Star.ID)
+//	/mass           float64 (N)     kg
+//	/pinned         uint8   (N)     1 for pinned stars
+//	/position       float64 (N, 2)  m
+//	/radius         float64 (N)     m
+//	/softening      float64 (N)     m, or 0 for the softening of the run
+//	/velocity       float64 (N, 2)  m/s
+//	attributes      generation (int64), time (float64, s), width and height (float64, m; height 0 for a square)
+//
+// Datasets with a unit have it as their "units" attribute.
+
+const (
+	hdf5Undefined = math.MaxUint64 // the undefined address
+	hdf5LeafK     = 16             // a symbol table node holds up to 2 * hdf5LeafK entries
+	hdf5NodeK     = 16             // a B-tree node holds up to 2 * hdf5NodeK children
+
+	hdf5SuperblockSize = 96
+	hdf5HeapHeaderSize = 32
+	hdf5BTreeSize      = 24 + (2*hdf5NodeK+1)*8 + 2*hdf5NodeK*8
+	hdf5SymbolNodeSize = 8 + 2*hdf5LeafK*40
+)
+
+// hdf5Signature starts every HDF5 file.
+var hdf5Signature = []byte{0x89, 'H', 'D', 'F', '\r', '\n', 0x1a, '\n'}
+
+// The types of the header messages of an object.
+const (
+	hdf5Dataspace   = 0x0001
+	hdf5Datatype    = 0x0003
+	hdf5Layout      = 0x0008
+	hdf5Attribute   = 0x000C
+	hdf5SymbolTable = 0x0011
+)
+
+// hdf5Dataset is a dataset or an attribute before it is laid out: its name, the encoded datatype, its dimensions
+// (none for a scalar), and its data in little endian.
+type hdf5Dataset struct {
+	name       string
+	datatype   []byte
+	dims       []uint64
+	data       []byte
+	attributes []hdf5Dataset
+}
+
+
+// WriteHDF5 writes one generation of a run to w as an HDF5 file.
+// Input:
+//   - w: destination of the file.
+//   - u: pointer to the Universe.
+//   - generation: generation number of u.
+// Output:
+//   - an error if writing failed.
+func WriteHDF5(w io.Writer, u *Universe, generation int) error {
+	n := uint64(len(u.Stars))
+	float64s := func(name, units string, columns int, field func(s *Star) []float64) hdf5Dataset {
+		var data bytes.Buffer
+		for _, s := range u.Stars {
+			binary.Write(&data, binary.LittleEndian, field(s))
+		}
+		dims := []uint64{n}
+		if columns > 1 {
+			dims = append(dims, uint64(columns))
+		}
+		return hdf5Dataset{name: name, datatype: hdf5Float(8), dims: dims, data: data.Bytes(),
+			attributes: []hdf5Dataset{hdf5StringAttribute("units", units)}}
+	}
+
+	var color, pinned, galaxy, id bytes.Buffer
+	for _, s := range u.Stars {
+		color.Write([]byte{s.Red, s.Green, s.Blue})
+		if s.Pinned {
+			pinned.WriteByte(1)
+		} else {
+			pinned.WriteByte(0)
+		}
+		binary.Write(&galaxy, binary.LittleEndian, s.Galaxy)
+		binary.Write(&id, binary.LittleEndian, s.ID)
+	}
+
+	datasets := []hdf5Dataset{
+		float64s("position", "m", 2, func(s *Star) []float64 { return []float64{s.Position.X, s.Position.Y} }),
+		float64s("velocity", "m/s", 2, func(s *Star) []float64 { return []float64{s.Velocity.X, s.Velocity.Y} }),
+		float64s("acceleration", "m/s^2", 2, func(s *Star) []float64 { return []float64{s.Acceleration.X, s.Acceleration.Y} }),
+		float64s("mass", "kg", 1, func(s *Star) []float64 { return []float64{s.Mass} }),
+		float64s("radius", "m", 1, func(s *Star) []float64 { return []float64{s.Radius} }),
+		float64s("softening", "m", 1, func(s *Star) []float64 { return []float64{s.Softening} }),
+		float64s("age", "s", 1, func(s *Star) []float64 { return []float64{s.Age} }),
+		{name: "color", datatype: hdf5Integer(1, false), dims: []uint64{n, 3}, data: color.Bytes()},
+		{name: "pinned", datatype: hdf5Integer(1, false), dims: []uint64{n}, data: pinned.Bytes()},
+		{name: "galaxy", datatype: hdf5Integer(4, true), dims: []uint64{n}, data: galaxy.Bytes()},
+		{name: "id", datatype: hdf5Integer(8, true), dims: []uint64{n}, data: id.Bytes()},
+	}
+	attributes := []hdf5Dataset{
+		hdf5Scalar("generation", hdf5Integer(8, true), int64(generation)),
+		hdf5Scalar("time", hdf5Float(8), u.Time),
+		hdf5Scalar("width", hdf5Float(8), u.Width),
+		hdf5Scalar("height", hdf5Float(8), u.Height),
+	}
+
+	file, err := EncodeHDF5(datasets, attributes)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(file)
+	return err
+}
+
+
+// WriteHDF5File saves one generation of a run to an HDF5 file.
+// Input:
+//   - u: pointer to the Universe.
+//   - generation: generation number of u.
+//   - fileName: path of the file to create.
+// Output:
+//   - an error if the file cannot be written.
+func WriteHDF5File(u *Universe, generation int, fileName string) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	err = WriteHDF5(file, u, generation)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+
+// EncodeHDF5 returns an HDF5 file whose root group holds the given datasets and attributes.
+// Input:
+//   - datasets: the datasets, with unique names.
+//   - attributes: the attributes of the root group.
+// Output:
+//   - the bytes of the file, and an error if there are more datasets than the one symbol table node holds
+//     (2 * hdf5LeafK) or two datasets have the same name.
+func EncodeHDF5(datasets []hdf5Dataset, attributes []hdf5Dataset) ([]byte, error) {
+	if len(datasets) > 2*hdf5LeafK {
+		return nil, fmt.Errorf("HDF5 root group with %d datasets, at most %d are supported", len(datasets), 2*hdf5LeafK)
+	}
+
+	// the symbol table lists the datasets by name
+	datasets = append([]hdf5Dataset(nil), datasets...)
+	sort.Slice(datasets, func(i, j int) bool { return datasets[i].name < datasets[j].name })
+	for i := 1; i < len(datasets); i++ {
+		if datasets[i].name == datasets[i-1].name {
+			return nil, fmt.Errorf("HDF5 root group with two datasets named %q", datasets[i].name)
+		}
+	}
+
+	// the local heap holds the names, after the empty name of the root group at offset 0
+	heap := make([]byte, 8)
+	nameOffsets := make([]uint64, len(datasets))
+	for i, d := range datasets {
+		nameOffsets[i] = uint64(len(heap))
+		heap = append(heap, hdf5Pad([]byte(d.name+"\x00"))...)
+	}
+
+	// lay out the structures one after the other, then the data
+	rootHeader := hdf5ObjectHeader(hdf5RootMessages(0, 0, attributes))
+	rootAddress := uint64(hdf5SuperblockSize)
+	heapAddress := rootAddress + uint64(len(rootHeader))
+	btreeAddress := heapAddress + hdf5HeapHeaderSize + uint64(len(heap))
+	nodeAddress := btreeAddress + hdf5BTreeSize
+	headerAddresses := make([]uint64, len(datasets))
+	end := nodeAddress + hdf5SymbolNodeSize
+	for i, d := range datasets {
+		headerAddresses[i] = end
+		end += uint64(len(hdf5ObjectHeader(hdf5DatasetMessages(d, 0))))
+	}
+	dataAddresses := make([]uint64, len(datasets))
+	for i, d := range datasets {
+		dataAddresses[i] = hdf5Undefined
+		if len(d.data) > 0 {
+			dataAddresses[i] = end
+			end += uint64(len(hdf5Pad(d.data)))
+		}
+	}
+
+	file := make([]byte, 0, end)
+
+	// superblock, ending with the entry of the root group
+	file = append(file, hdf5Signature...)
+	file = append(file, 0, 0, 0, 0, 0, 8, 8, 0)
+	file = binary.LittleEndian.AppendUint16(file, hdf5LeafK)
+	file = binary.LittleEndian.AppendUint16(file, hdf5NodeK)
+	file = binary.LittleEndian.AppendUint32(file, 0)
+	for _, address := range []uint64{0, hdf5Undefined, end, hdf5Undefined} {
+		file = binary.LittleEndian.AppendUint64(file, address)
+	}
+	file = append(file, hdf5SymbolEntry(0, rootAddress, 1, btreeAddress, heapAddress)...)
+
+	file = append(file, hdf5ObjectHeader(hdf5RootMessages(btreeAddress, heapAddress, attributes))...)
+
+	// local heap, with no free space (offset 1 ends the free list)
+	file = append(file, 'H', 'E', 'A', 'P', 0, 0, 0, 0)
+	for _, v := range []uint64{uint64(len(heap)), 1, heapAddress + hdf5HeapHeaderSize} {
+		file = binary.LittleEndian.AppendUint64(file, v)
+	}
+	file = append(file, heap...)
+
+	// B-tree of one leaf pointing to the symbol table node; its keys are the names before and after the node
+	btree := append([]byte("TREE"), 0, 0)
+	btree = binary.LittleEndian.AppendUint16(btree, 1)
+	btree = binary.LittleEndian.AppendUint64(btree, hdf5Undefined)
+	btree = binary.LittleEndian.AppendUint64(btree, hdf5Undefined)
+	btree = binary.LittleEndian.AppendUint64(btree, 0)
+	btree = binary.LittleEndian.AppendUint64(btree, nodeAddress)
+	lastName := uint64(0)
+	if len(datasets) > 0 {
+		lastName = nameOffsets[len(datasets)-1]
+	}
+	btree = binary.LittleEndian.AppendUint64(btree, lastName)
+	file = append(file, btree...)
+	file = append(file, make([]byte, hdf5BTreeSize-len(btree))...)
+
+	// symbol table node
+	node := append([]byte("SNOD"), 1, 0)
+	node = binary.LittleEndian.AppendUint16(node, uint16(len(datasets)))
+	for i := range datasets {
+		node = append(node, hdf5SymbolEntry(nameOffsets[i], headerAddresses[i], 0, 0, 0)...)
+	}
+	file = append(file, node...)
+	file = append(file, make([]byte, hdf5SymbolNodeSize-len(node))...)
+
+	for i, d := range datasets {
+		file = append(file, hdf5ObjectHeader(hdf5DatasetMessages(d, dataAddresses[i]))...)
+	}
+	for _, d := range datasets {
+		if len(d.data) > 0 {
+			file = append(file, hdf5Pad(d.data)...)
+		}
+	}
+	return file, nil
+}
+
+
+// hdf5RootMessages returns the header messages of the root group: its symbol table and its attributes.
+func hdf5RootMessages(btreeAddress, heapAddress uint64, attributes []hdf5Dataset) [][]byte {
+	table := binary.LittleEndian.AppendUint64(nil, btreeAddress)
+	table = binary.LittleEndian.AppendUint64(table, heapAddress)
+	messages := [][]byte{hdf5Message(hdf5SymbolTable, table)}
+	for _, a := range attributes {
+		messages = append(messages, hdf5Message(hdf5Attribute, hdf5AttributeMessage(a)))
+	}
+	return messages
+}
+
+
+// hdf5DatasetMessages returns the header messages of a dataset whose data starts at dataAddress.
+func hdf5DatasetMessages(d hdf5Dataset, dataAddress uint64) [][]byte {
+	layout := []byte{3, 1} // version 3, contiguous
+	layout = binary.LittleEndian.AppendUint64(layout, dataAddress)
+	layout = binary.LittleEndian.AppendUint64(layout, uint64(len(d.data)))
+
+	messages := [][]byte{
+		hdf5Message(hdf5Dataspace, hdf5DataspaceMessage(d.dims)),
+		hdf5Message(hdf5Datatype, d.datatype),
+		hdf5Message(hdf5Layout, layout),
+	}
+	for _, a := range d.attributes {
+		messages = append(messages, hdf5Message(hdf5Attribute, hdf5AttributeMessage(a)))
+	}
+	return messages
+}
+
+
+// hdf5ObjectHeader returns a version 1 object header holding the given messages.
+func hdf5ObjectHeader(messages [][]byte) []byte {
+	size := 0
+	for _, m := range messages {
+		size += len(m)
+	}
+	header := []byte{1, 0}
+	header = binary.LittleEndian.AppendUint16(header, uint16(len(messages)))
+	header = binary.LittleEndian.AppendUint32(header, 1) // reference count
+	header = binary.LittleEndian.AppendUint32(header, uint32(size))
+	header = append(header, 0, 0, 0, 0) // the messages are aligned to 8 bytes
+	for _, m := range messages {
+		header = append(header, m...)
+	}
+	return header
+}
+
+
+// hdf5Message returns a header message of an object, its data padded to 8 bytes.
+func hdf5Message(kind uint16, data []byte) []byte {
+	data = hdf5Pad(data)
+	message := binary.LittleEndian.AppendUint16(nil, kind)
+	message = binary.LittleEndian.AppendUint16(message, uint16(len(data)))
+	message = append(message, 0, 0, 0, 0)
+	return append(message, data...)
+}
+
+
+// hdf5SymbolEntry returns the entry of an object in a symbol table: cache type 1 keeps the addresses of the
+// B-tree and the local heap of a group.
+func hdf5SymbolEntry(nameOffset, headerAddress uint64, cacheType uint32, btreeAddress, heapAddress uint64) []byte {
+	entry := binary.LittleEndian.AppendUint64(nil, nameOffset)
+	entry = binary.LittleEndian.AppendUint64(entry, headerAddress)
+	entry = binary.LittleEndian.AppendUint32(entry, cacheType)
+	entry = binary.LittleEndian.AppendUint32(entry, 0)
+	entry = binary.LittleEndian.AppendUint64(entry, btreeAddress)
+	return binary.LittleEndian.AppendUint64(entry, heapAddress)
+}
+
+
+// hdf5DataspaceMessage returns a version 1 dataspace of the given dimensions, a scalar if there are none.
+func hdf5DataspaceMessage(dims []uint64) []byte {
+	space := []byte{1, byte(len(dims)), 0, 0, 0, 0, 0, 0}
+	for _, d := range dims {
+		space = binary.LittleEndian.AppendUint64(space, d)
+	}
+	return space
+}
+
+
+// hdf5AttributeMessage returns a version 1 attribute message.
+func hdf5AttributeMessage(a hdf5Dataset) []byte {
+	name := []byte(a.name + "\x00")
+	space := hdf5DataspaceMessage(a.dims)
+	message := []byte{1, 0}
+	message = binary.LittleEndian.AppendUint16(message, uint16(len(name)))
+	message = binary.LittleEndian.AppendUint16(message, uint16(len(a.datatype)))
+	message = binary.LittleEndian.AppendUint16(message, uint16(len(space)))
+	message = append(message, hdf5Pad(name)...)
+	message = append(message, hdf5Pad(a.datatype)...)
+	message = append(message, hdf5Pad(space)...)
+	return append(message, a.data...)
+}
+
+
+// hdf5Integer returns the datatype of little endian integers of the given size in bytes.
+func hdf5Integer(size int, signed bool) []byte {
+	var flags byte
+	if signed {
+		flags = 0x08
+	}
+	datatype := []byte{0x10, flags, 0, 0} // version 1, fixed-point
+	datatype = binary.LittleEndian.AppendUint32(datatype, uint32(size))
+	datatype = binary.LittleEndian.AppendUint16(datatype, 0)
+	return binary.LittleEndian.AppendUint16(datatype, uint16(8*size))
+}
+
+
+// hdf5Float returns the datatype of little endian IEEE 754 floating point numbers of 4 or 8 bytes.
+func hdf5Float(size int) []byte {
+	bits, exponentBits, mantissaBits, bias := 64, 11, 52, uint32(1023)
+	if size == 4 {
+		bits, exponentBits, mantissaBits, bias = 32, 8, 23, 127
+	}
+	// the leading bit of the mantissa is implied, and the sign is the highest bit
+	datatype := []byte{0x11, 0x20, byte(bits - 1), 0}
+	datatype = binary.LittleEndian.AppendUint32(datatype, uint32(size))
+	datatype = binary.LittleEndian.AppendUint16(datatype, 0)
+	datatype = binary.LittleEndian.AppendUint16(datatype, uint16(bits))
+	datatype = append(datatype, byte(mantissaBits), byte(exponentBits), 0, byte(mantissaBits))
+	return binary.LittleEndian.AppendUint32(datatype, bias)
+}
+
+
+// hdf5Scalar returns a scalar attribute holding value, a fixed-size number.
+func hdf5Scalar(name string, datatype []byte, value any) hdf5Dataset {
+	var data bytes.Buffer
+	binary.Write(&data, binary.LittleEndian, value)
+	return hdf5Dataset{name: name, datatype: datatype, data: data.Bytes()}
+}
+
+
+// hdf5StringAttribute returns a scalar attribute holding a null terminated ASCII string.
+func hdf5StringAttribute(name, value string) hdf5Dataset {
+	data := []byte(value + "\x00")
+	datatype := []byte{0x13, 0, 0, 0} // version 1, string, null terminated ASCII
+	datatype = binary.LittleEndian.AppendUint32(datatype, uint32(len(data)))
+	return hdf5Dataset{name: name, datatype: datatype, data: data}
+}
+
+
+// hdf5Pad returns b padded with zeros to a multiple of 8 bytes.
+func hdf5Pad(b []byte) []byte {
+	if len(b)%8 == 0 {
+		return b
+	}
+	return append(b[:len(b):len(b)], make([]byte, 8-len(b)%8)...)
+}
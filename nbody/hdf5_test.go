@@ -0,0 +1,459 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the HDF5 snapshots in hdf5.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package nbody
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// HDF5Object is a dataset or an attribute read by ReadHDF5Root: the class and size of its datatype, its
+// dimensions, its data, and its attributes.
+type HDF5Object struct {
+	class      byte
+	size       int
+	dims       []uint64
+	data       []byte
+	attributes map[string]HDF5Object
+}
+
+type EncodeHDF5TestCases struct {
+	id          string
+	numDatasets int
+	duplicate   bool
+	valid       bool
+}
+
+
+// ReadHDF5Root reads the datasets and the attributes of the root group of an HDF5 file written by EncodeHDF5,
+// following the addresses of the file as an HDF5 library does and checking every structure on the way.
+// Input:
+//   - file: the bytes of the file.
+// Output:
+//   - the datasets and the attributes of the root group by name, or an error for the first invalid structure.
+func ReadHDF5Root(file []byte) (map[string]HDF5Object, map[string]HDF5Object, error) {
+	le := binary.LittleEndian
+	at := func(address, size uint64) ([]byte, error) {
+		if address > uint64(len(file)) || size > uint64(len(file))-address {
+			return nil, fmt.Errorf("%d bytes at %d are outside the file of %d bytes", size, address, len(file))
+		}
+		return file[address : address+size], nil
+	}
+
+	superblock, err := at(0, hdf5SuperblockSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(superblock[:8], hdf5Signature) || superblock[8] != 0 || superblock[13] != 8 || superblock[14] != 8 {
+		return nil, nil, fmt.Errorf("bad superblock %v", superblock[:16])
+	}
+	leafK := uint64(le.Uint16(superblock[16:]))
+	if end := le.Uint64(superblock[40:]); end != uint64(len(file)) {
+		return nil, nil, fmt.Errorf("end of file address %d, file has %d bytes", end, len(file))
+	}
+	rootEntry := superblock[56:]
+	if le.Uint32(rootEntry[16:]) != 1 {
+		return nil, nil, fmt.Errorf("root group entry has cache type %d", le.Uint32(rootEntry[16:]))
+	}
+
+	root, err := readHDF5Header(at, le.Uint64(rootEntry[8:]))
+	if err != nil {
+		return nil, nil, fmt.Errorf("root group: %w", err)
+	}
+	table := root.messages[hdf5SymbolTable]
+	if len(table) != 1 || !bytes.Equal(table[0][:16], rootEntry[24:40]) {
+		return nil, nil, fmt.Errorf("symbol table of the root group does not match its entry in the superblock")
+	}
+	btreeAddress, heapAddress := le.Uint64(table[0]), le.Uint64(table[0][8:])
+
+	heap, err := at(heapAddress, hdf5HeapHeaderSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	if string(heap[:4]) != "HEAP" || heap[4] != 0 || le.Uint64(heap[16:]) != 1 {
+		return nil, nil, fmt.Errorf("bad local heap %v", heap)
+	}
+	names, err := at(le.Uint64(heap[24:]), le.Uint64(heap[8:]))
+	if err != nil {
+		return nil, nil, err
+	}
+	name := func(offset uint64) (string, error) {
+		if offset >= uint64(len(names)) || bytes.IndexByte(names[offset:], 0) < 0 {
+			return "", fmt.Errorf("name at %d is outside the local heap", offset)
+		}
+		return string(names[offset : offset+uint64(bytes.IndexByte(names[offset:], 0))]), nil
+	}
+
+	btree, err := at(btreeAddress, hdf5BTreeSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	if string(btree[:4]) != "TREE" || btree[4] != 0 || btree[5] != 0 || le.Uint16(btree[6:]) != 1 {
+		return nil, nil, fmt.Errorf("bad B-tree %v", btree[:8])
+	}
+	node, err := at(le.Uint64(btree[32:]), 8+2*leafK*40)
+	if err != nil {
+		return nil, nil, err
+	}
+	if string(node[:4]) != "SNOD" || node[4] != 1 {
+		return nil, nil, fmt.Errorf("bad symbol table node %v", node[:8])
+	}
+
+	datasets := map[string]HDF5Object{}
+	var lastName string
+	for i := uint64(0); i < uint64(le.Uint16(node[6:])); i++ {
+		entry := node[8+40*i:]
+		n, err := name(le.Uint64(entry))
+		if err != nil {
+			return nil, nil, err
+		}
+		if n <= lastName {
+			return nil, nil, fmt.Errorf("dataset %q comes after %q in the symbol table", n, lastName)
+		}
+		lastName = n
+
+		header, err := readHDF5Header(at, le.Uint64(entry[8:]))
+		if err != nil {
+			return nil, nil, fmt.Errorf("dataset %s: %w", n, err)
+		}
+		layouts := header.messages[hdf5Layout]
+		if len(layouts) != 1 || layouts[0][0] != 3 || layouts[0][1] != 1 {
+			return nil, nil, fmt.Errorf("dataset %s is not contiguous", n)
+		}
+		object, err := parseHDF5Object(header.messages[hdf5Datatype], header.messages[hdf5Dataspace])
+		if err != nil {
+			return nil, nil, fmt.Errorf("dataset %s: %w", n, err)
+		}
+		address, size := le.Uint64(layouts[0][2:]), le.Uint64(layouts[0][10:])
+		if size != uint64(len(object.data)) {
+			return nil, nil, fmt.Errorf("dataset %s has %d bytes for %d elements", n, size, len(object.data)/object.size)
+		}
+		if size > 0 {
+			if object.data, err = at(address, size); err != nil {
+				return nil, nil, fmt.Errorf("dataset %s: %w", n, err)
+			}
+		}
+		object.attributes = header.attributes
+		datasets[n] = object
+	}
+	if last, err := name(le.Uint64(btree[40:])); err != nil || last != lastName {
+		return nil, nil, fmt.Errorf("last key of the B-tree is %q, last dataset %q", last, lastName)
+	}
+
+	return datasets, root.attributes, nil
+}
+
+
+// HDF5Header holds the messages of an object header by type, and its attributes by name.
+type HDF5Header struct {
+	messages   map[uint16][][]byte
+	attributes map[string]HDF5Object
+}
+
+
+// readHDF5Header reads the version 1 object header at address.
+func readHDF5Header(at func(address, size uint64) ([]byte, error), address uint64) (HDF5Header, error) {
+	le := binary.LittleEndian
+	prefix, err := at(address, 16)
+	if err != nil {
+		return HDF5Header{}, err
+	}
+	if prefix[0] != 1 || le.Uint32(prefix[4:]) != 1 {
+		return HDF5Header{}, fmt.Errorf("bad object header %v", prefix)
+	}
+	chunk, err := at(address+16, uint64(le.Uint32(prefix[8:])))
+	if err != nil {
+		return HDF5Header{}, err
+	}
+
+	header := HDF5Header{messages: map[uint16][][]byte{}, attributes: map[string]HDF5Object{}}
+	count := 0
+	for len(chunk) > 0 {
+		if len(chunk) < 8 || int(le.Uint16(chunk[2:]))+8 > len(chunk) || le.Uint16(chunk[2:])%8 != 0 {
+			return HDF5Header{}, fmt.Errorf("message %d does not fit the header", count)
+		}
+		kind, data := le.Uint16(chunk), chunk[8:8+le.Uint16(chunk[2:])]
+		header.messages[kind] = append(header.messages[kind], data)
+		chunk = chunk[8+len(data):]
+		count++
+
+		if kind == hdf5Attribute {
+			nameSize, typeSize, spaceSize := uint64(le.Uint16(data[2:])), uint64(le.Uint16(data[4:])), uint64(le.Uint16(data[6:]))
+			pad := func(n uint64) uint64 { return (n + 7) / 8 * 8 }
+			typeAt := 8 + pad(nameSize)
+			spaceAt := typeAt + pad(typeSize)
+			dataAt := spaceAt + pad(spaceSize)
+			if data[0] != 1 || dataAt > uint64(len(data)) {
+				return HDF5Header{}, fmt.Errorf("bad attribute message %v", data[:8])
+			}
+			attribute, err := parseHDF5Object([][]byte{data[typeAt : typeAt+typeSize]}, [][]byte{data[spaceAt : spaceAt+spaceSize]})
+			if err != nil {
+				return HDF5Header{}, err
+			}
+			attribute.data = data[dataAt : dataAt+uint64(len(attribute.data))]
+			header.attributes[string(bytes.TrimRight(data[8:8+nameSize], "\x00"))] = attribute
+		}
+	}
+	if count != int(le.Uint16(prefix[2:])) {
+		return HDF5Header{}, fmt.Errorf("object header has %d messages, counts %d", count, le.Uint16(prefix[2:]))
+	}
+	return header, nil
+}
+
+
+// parseHDF5Object reads a datatype and a dataspace message; the data of the object is left zero, of its size.
+func parseHDF5Object(datatypes, dataspaces [][]byte) (HDF5Object, error) {
+	le := binary.LittleEndian
+	if len(datatypes) != 1 || len(dataspaces) != 1 {
+		return HDF5Object{}, fmt.Errorf("expected one datatype and one dataspace")
+	}
+	datatype, space := datatypes[0], dataspaces[0]
+	if datatype[0]>>4 != 1 || space[0] != 1 || len(space) < 8+8*int(space[1]) {
+		return HDF5Object{}, fmt.Errorf("bad datatype %v or dataspace %v", datatype, space)
+	}
+
+	object := HDF5Object{class: datatype[0] & 0x0f, size: int(le.Uint32(datatype[4:]))}
+	elements := uint64(1)
+	for i := 0; i < int(space[1]); i++ {
+		object.dims = append(object.dims, le.Uint64(space[8+8*i:]))
+		elements *= object.dims[i]
+	}
+	object.data = make([]byte, elements*uint64(object.size))
+	return object, nil
+}
+
+
+// TestWriteHDF5 checks that HDF5 snapshots hold every field of every star and the generation, time, and size of
+// the universe, in structures an HDF5 library can follow.
+// The universes of Tests/TreeBuild.txt are used, with every field of the stars and the universe set, square and as
+// rectangles half as high, and an empty universe.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if a file is invalid or a value differs.
+func TestWriteHDF5(t *testing.T) {
+	tests := ReadTreeBuild("../Tests/TreeBuild.txt")
+	tests = append(tests, TreeBuildTestCases{ID: "empty", Universe: NewUniverse(10)})
+
+	for n, test := range tests {
+		u := test.Universe
+		u.Time = 1.5e17
+		for i, s := range u.Stars {
+			s.Velocity = OrderedPair{X: s.Position.Y / 3, Y: -s.Position.X / 7}
+			s.Acceleration = OrderedPair{X: 1e-9 * float64(i), Y: -1e-9}
+			s.Radius = 0.1 * s.Mass
+			s.Softening = 1e19 / float64(i+1)
+			s.Age = 1e15 * float64(i)
+			s.Red, s.Green, s.Blue = uint8(i), uint8(2*i), 255
+			s.Pinned = i%2 == 1
+			s.Galaxy = int32(i % 3)
+			s.ID = int64(5*i + 1)
+		}
+
+		for _, height := range []float64{0, u.Width / 2} {
+			u.Height = height
+			generation := 1000 * n
+
+			fileName := SnapshotFileName(t.TempDir(), generation, "hdf5")
+			Check(WriteSnapshot(u, generation, fileName))
+			if filepath.Ext(fileName) != ".h5" {
+				t.Errorf("TestWriteHDF5(test %v): snapshot file %s, want a .h5 file", test.ID, fileName)
+			}
+			file, err := os.ReadFile(fileName)
+			Check(err)
+
+			datasets, attributes, err := ReadHDF5Root(file)
+			if err != nil {
+				t.Errorf("TestWriteHDF5(test %v): invalid file: %v", test.ID, err)
+				continue
+			}
+			CheckHDF5Snapshot(t, test.ID, datasets, attributes, u, generation)
+		}
+	}
+}
+
+
+// CheckHDF5Snapshot reports the datasets and attributes of an HDF5 snapshot that do not match its universe.
+func CheckHDF5Snapshot(t *testing.T, id string, datasets, attributes map[string]HDF5Object, u *Universe, generation int) {
+	le := binary.LittleEndian
+	n := uint64(len(u.Stars))
+
+	want := map[string]struct {
+		class   byte
+		size    int
+		columns uint64
+		units   string
+		value   func(s *Star) []float64
+	}{
+		"position":     {1, 8, 2, "m", func(s *Star) []float64 { return []float64{s.Position.X, s.Position.Y} }},
+		"velocity":     {1, 8, 2, "m/s", func(s *Star) []float64 { return []float64{s.Velocity.X, s.Velocity.Y} }},
+		"acceleration": {1, 8, 2, "m/s^2", func(s *Star) []float64 { return []float64{s.Acceleration.X, s.Acceleration.Y} }},
+		"mass":         {1, 8, 0, "kg", func(s *Star) []float64 { return []float64{s.Mass} }},
+		"radius":       {1, 8, 0, "m", func(s *Star) []float64 { return []float64{s.Radius} }},
+		"softening":    {1, 8, 0, "m", func(s *Star) []float64 { return []float64{s.Softening} }},
+		"age":          {1, 8, 0, "s", func(s *Star) []float64 { return []float64{s.Age} }},
+		"color":        {0, 1, 3, "", func(s *Star) []float64 { return []float64{float64(s.Red), float64(s.Green), float64(s.Blue)} }},
+		"pinned": {0, 1, 0, "", func(s *Star) []float64 {
+			if s.Pinned {
+				return []float64{1}
+			}
+			return []float64{0}
+		}},
+		"galaxy": {0, 4, 0, "", func(s *Star) []float64 { return []float64{float64(s.Galaxy)} }},
+		"id":     {0, 8, 0, "", func(s *Star) []float64 { return []float64{float64(s.ID)} }},
+	}
+
+	var names []string
+	for name := range datasets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) != len(want) {
+		t.Errorf("TestWriteHDF5(test %v): datasets %v, want %d", id, names, len(want))
+	}
+
+	for name, w := range want {
+		d, exists := datasets[name]
+		dims := []uint64{n}
+		if w.columns > 0 {
+			dims = append(dims, w.columns)
+		}
+		if !exists || d.class != w.class || d.size != w.size || !reflect.DeepEqual(d.dims, dims) {
+			t.Errorf("TestWriteHDF5(test %v): dataset %s = %+v, want class %d, size %d, dims %v", id, name, d, w.class, w.size, dims)
+			continue
+		}
+		if units := d.attributes["units"]; string(bytes.TrimRight(units.data, "\x00")) != w.units || (w.units != "" && units.class != 3) {
+			t.Errorf("TestWriteHDF5(test %v): dataset %s has units %q, want %q", id, name, units.data, w.units)
+		}
+
+		var values []float64
+		for i := 0; i < len(d.data); i += d.size {
+			switch {
+			case d.class == 1:
+				values = append(values, math.Float64frombits(le.Uint64(d.data[i:])))
+			case d.size == 8:
+				values = append(values, float64(int64(le.Uint64(d.data[i:]))))
+			case d.size == 4:
+				values = append(values, float64(int32(le.Uint32(d.data[i:]))))
+			default:
+				values = append(values, float64(d.data[i]))
+			}
+		}
+		var expected []float64
+		for _, s := range u.Stars {
+			expected = append(expected, w.value(s)...)
+		}
+		if !reflect.DeepEqual(values, expected) && len(expected) > 0 {
+			t.Errorf("TestWriteHDF5(test %v): dataset %s = %v, want %v", id, name, values, expected)
+		}
+	}
+
+	for name, value := range map[string]float64{"generation": float64(generation), "time": u.Time, "width": u.Width, "height": u.Height} {
+		a, exists := attributes[name]
+		got := math.NaN()
+		switch {
+		case !exists || len(a.dims) != 0 || a.size != 8:
+		case a.class == 0:
+			got = float64(int64(le.Uint64(a.data)))
+		case a.class == 1:
+			got = math.Float64frombits(le.Uint64(a.data))
+		}
+		if got != value {
+			t.Errorf("TestWriteHDF5(test %v): attribute %s = %v, want %v", id, name, got, value)
+		}
+	}
+}
+
+
+// ReadEncodeHDF5 reads test data for EncodeHDF5 from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of EncodeHDF5TestCases structs containing the root groups and whether they can be written.
+func ReadEncodeHDF5(fileName string) []EncodeHDF5TestCases {
+	file, err := os.Open(fileName)
+	Check(err)
+	defer file.Close()
+
+	var tests []EncodeHDF5TestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		test := EncodeHDF5TestCases{id: strings.TrimSpace(parts[0]), valid: strings.TrimSpace(parts[3]) == "ok"}
+		test.numDatasets, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+		Check(err)
+		test.duplicate, err = strconv.ParseBool(strings.TrimSpace(parts[2]))
+		Check(err)
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestEncodeHDF5 checks that EncodeHDF5 writes root groups of up to 2 * hdf5LeafK datasets that hold every
+// dataset, and returns an error for more datasets or for two datasets of the same name.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestEncodeHDF5(t *testing.T) {
+	tests := ReadEncodeHDF5("../Tests/EncodeHDF5.txt")
+
+	for _, test := range tests {
+		datasets := make([]hdf5Dataset, test.numDatasets)
+		for i := range datasets {
+			var data bytes.Buffer
+			for j := 0; j <= i; j++ {
+				binary.Write(&data, binary.LittleEndian, int32(i))
+			}
+			datasets[i] = hdf5Dataset{name: fmt.Sprintf("d%03d", i), datatype: hdf5Integer(4, true), dims: []uint64{uint64(i + 1)}, data: data.Bytes()}
+		}
+		if test.duplicate {
+			datasets[len(datasets)-1].name = datasets[0].name
+		}
+
+		file, err := EncodeHDF5(datasets, []hdf5Dataset{hdf5Scalar("generation", hdf5Integer(8, true), int64(7))})
+		if !test.valid {
+			if err == nil {
+				t.Errorf("TestEncodeHDF5(test %v) = %d bytes, want an error", test.id, len(file))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestEncodeHDF5(test %v) failed: %v", test.id, err)
+			continue
+		}
+
+		read, attributes, err := ReadHDF5Root(file)
+		if err != nil {
+			t.Errorf("TestEncodeHDF5(test %v): invalid file: %v", test.id, err)
+			continue
+		}
+		if len(read) != len(datasets) || len(attributes) != 1 {
+			t.Errorf("TestEncodeHDF5(test %v) holds %d datasets and %d attributes, want %d and 1", test.id, len(read), len(attributes), len(datasets))
+		}
+		for _, d := range datasets {
+			if r := read[d.name]; !bytes.Equal(r.data, d.data) || !reflect.DeepEqual(r.dims, d.dims) {
+				t.Errorf("TestEncodeHDF5(test %v): dataset %s = %v %v, want %v %v", test.id, d.name, r.dims, r.data, d.dims, d.data)
+			}
+		}
+	}
+}
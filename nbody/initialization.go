@@ -2,23 +2,19 @@
 // Date: 2025-10-24
 // Description: Functions for creation universe or galaxy object used in simulation.
 
-package main
+package nbody
 
-import (
-	"math"
-	"math/rand"
-)
+import "math"
 
 // InitializeUniverse() sets an initial universe given a collection of galaxies and a width.
+// The stars of every galaxy are tagged with its number, from 1 in the order of galaxies (see AddGalaxy).
 // It returns a pointer to the resulting universe.
 func InitializeUniverse(galaxies []Galaxy, w float64) *Universe {
 	var u Universe
-	u.width = w
-	u.stars = make([]*Star, 0, len(galaxies)*len(galaxies[0]))
+	u.Width = w
+	u.Stars = make([]*Star, 0, len(galaxies)*len(galaxies[0]))
 	for i := range galaxies {
-		for _, b := range galaxies[i] {
-			u.stars = append(u.stars, b)
-		}
+		u.AddGalaxy(galaxies[i])
 	}
 	return &u
 }
@@ -32,37 +28,37 @@ func InitializeGalaxy(numOfStars int, r, x, y float64) Galaxy {
 		var s Star
 
 		// First choose distance to center of galaxy
-		dist := (rand.Float64() + 1.0) / 2.0
+		dist := (RNG.Float64() + 1.0) / 2.0
 
 		// multiply by factor of r
 		dist *= r
 
 		// Next choose the angle in radians to represent the rotation
-		angle := rand.Float64() * 2 * math.Pi
+		angle := RNG.Float64() * 2 * math.Pi
 
 		// convert polar coordinates to Cartesian
-		s.position.x = x + dist*math.Cos(angle)
-		s.position.y = y + dist*math.Sin(angle)
+		s.Position.X = x + dist*math.Cos(angle)
+		s.Position.Y = y + dist*math.Sin(angle)
 
 		// set the mass = mass of sun by default
-		s.mass = solarMass
+		s.Mass = SolarMass
 
 		// set the radius equal to radius of sun in m
-		s.radius = 696340000
+		s.Radius = 696340000
 
 		//set the colors
-		s.red = 255
-		s.green = 255
-		s.blue = 255
+		s.Red = 255
+		s.Green = 255
+		s.Blue = 255
 
 		// now spin the galaxy
 
 		// the following is orbital velocity equation
 		//dist := Distance(pos, g[i].position)
-		speed := 0.5 * math.Sqrt(G*blackHoleMass/dist) // approximation of orbital velocity equation: half of true speed to prevent instability
+		speed := 0.5 * math.Sqrt(G*BlackHoleMass/dist) // approximation of orbital velocity equation: half of true speed to prevent instability
 
-		s.velocity.x = speed * math.Cos(angle+math.Pi/2.0)
-		s.velocity.y = speed * math.Sin(angle+math.Pi/2.0)
+		s.Velocity.X = speed * math.Cos(angle+math.Pi/2.0)
+		s.Velocity.Y = speed * math.Sin(angle+math.Pi/2.0)
 
 		//point g[i] at s
 		g[i] = &s
@@ -72,13 +68,31 @@ func InitializeGalaxy(numOfStars int, r, x, y float64) Galaxy {
 	//add a blackhole to the center of the galaxy
 
 	var blackhole Star
-	blackhole.mass = blackHoleMass
-	blackhole.position.x = x
-	blackhole.position.y = y
-	blackhole.blue = 255
-	blackhole.radius = 6963400000 // ten times that of a normal star (to make it visible as large)
+	blackhole.Mass = BlackHoleMass
+	blackhole.Position.X = x
+	blackhole.Position.Y = y
+	blackhole.Blue = 255
+	blackhole.Radius = 6963400000 // ten times that of a normal star (to make it visible as large)
 
 	g = append(g, &blackhole)
 
 	return g
 }
+
+// NewUniverse returns an empty universe of the given width, to which stars can be appended.
+func NewUniverse(width float64) *Universe {
+	return &Universe{Width: width}
+}
+
+// NewStar returns a white star with the given position, velocity, mass, and radius, and zero acceleration.
+func NewStar(position, velocity OrderedPair, mass, radius float64) *Star {
+	return &Star{
+		Position: position,
+		Velocity: velocity,
+		Mass:     mass,
+		Radius:   radius,
+		Red:      255,
+		Blue:     255,
+		Green:    255,
+	}
+}
@@ -0,0 +1,193 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Small helpers for running independent work items on several goroutines.
+
+package nbody
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// NumWorkers is the number of goroutines used by the parallel parts of the program.
+// It is set from the -workers command line option and defaults to the number of CPUs.
+var NumWorkers = runtime.NumCPU()
+
+
+// A WorkGroup runs functions on goroutines and collects the first error, like errgroup.Group:
+//   - at most limit functions run at the same time (Go blocks until one finishes).
+//   - a panic inside a function is recovered and turned into an error, so it is reported by Wait
+//     on the goroutine that started the work instead of crashing the program from a worker.
+//   - after the first error, Failed reports true so long loops can stop early.
+type WorkGroup struct {
+	wg     sync.WaitGroup
+	slots  chan struct{}
+	once   sync.Once
+	err    error
+	failed chan struct{}
+}
+
+
+// NewWorkGroup returns a WorkGroup running at most limit functions at once (at least one).
+func NewWorkGroup(limit int) *WorkGroup {
+	if limit < 1 {
+		limit = 1
+	}
+	return &WorkGroup{
+		slots:  make(chan struct{}, limit),
+		failed: make(chan struct{}),
+	}
+}
+
+
+// Go runs f on a new goroutine as soon as fewer than limit functions are running.
+// Functions are not started any more once the group has failed.
+func (g *WorkGroup) Go(f func() error) {
+	if g.Failed() {
+		return
+	}
+
+	g.slots <- struct{}{}
+	g.wg.Add(1)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				g.fail(fmt.Errorf("worker panicked: %v\n%s", r, debug.Stack()))
+			}
+			<-g.slots
+			g.wg.Done()
+		}()
+
+		if err := f(); err != nil {
+			g.fail(err)
+		}
+	}()
+}
+
+
+// fail records err if it is the first error of the group.
+func (g *WorkGroup) fail(err error) {
+	g.once.Do(func() {
+		g.err = err
+		close(g.failed)
+	})
+}
+
+
+// Failed reports whether a function of the group returned an error or panicked.
+func (g *WorkGroup) Failed() bool {
+	select {
+	case <-g.failed:
+		return true
+	default:
+		return false
+	}
+}
+
+
+// Wait waits for all started functions and returns the first error, or nil.
+func (g *WorkGroup) Wait() error {
+	g.wg.Wait()
+	return g.err
+}
+
+
+// ParallelForErr calls f(i) for every i in [0, n) using at most workers goroutines and waits for all calls.
+// Every call must only write to data owned by its index, so results do not depend on scheduling.
+// After the first error (or panic, turned into an error) no new items are started and that error is returned.
+// Input:
+//   - n: number of work items.
+//   - workers: number of goroutines (values below 1 run everything on the calling goroutine).
+//   - f: the work for one item.
+// Output:
+//   - the first error returned by f, or nil.
+func ParallelForErr(n, workers int, f func(i int) error) error {
+	if workers <= 1 || n <= 1 {
+		for i := 0; i < n; i++ {
+			if err := callRecovered(f, i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if workers > n {
+		workers = n
+	}
+
+	// every worker takes the next item until the items run out or the group fails
+	var next int
+	var mu sync.Mutex
+	take := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		i := next
+		next++
+		return i
+	}
+
+	g := NewWorkGroup(workers)
+	for w := 0; w < workers; w++ {
+		g.Go(func() error {
+			for i := take(); i < n && !g.Failed(); i = take() {
+				if err := f(i); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+
+// callRecovered calls f(i), turning a panic into an error like WorkGroup does.
+func callRecovered(f func(i int) error, i int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("worker panicked: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return f(i)
+}
+
+
+// ParallelFor is ParallelForErr for work that cannot fail. A panic in f is re-raised on the calling goroutine.
+// Input:
+//   - n: number of work items.
+//   - workers: number of goroutines (values below 1 run everything on the calling goroutine).
+//   - f: the work for one item.
+// Output:
+//   - None.
+func ParallelFor(n, workers int, f func(i int)) {
+	Check(ParallelForErr(n, workers, func(i int) error {
+		f(i)
+		return nil
+	}))
+}
+
+
+// ParallelSum adds up f(i) for every i in [0, n), calling f on at most workers goroutines. Floating-point addition
+// is not associative, so the values are added in the order of the items after all calls, never as the workers
+// finish: the sum is bit-identical for every number of workers and every scheduling.
+// Input:
+//   - n: number of work items, e.g. fixed chunks of stars (see forceChunk).
+//   - workers: number of goroutines (values below 1 run everything on the calling goroutine).
+//   - f: the value of one item.
+// Output:
+//   - the sum f(0) + f(1) + ... + f(n-1), added from the left.
+func ParallelSum(n, workers int, f func(i int) float64) float64 {
+	values := make([]float64, n)
+	ParallelFor(n, workers, func(i int) {
+		values[i] = f(i)
+	})
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
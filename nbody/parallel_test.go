@@ -0,0 +1,92 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the parallel helpers in parallel.go.
+
+package nbody
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// TestParallelForErr checks that ParallelForErr visits every item, returns a worker's error,
+// and reports a worker's panic as an error instead of crashing.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if a case fails.
+func TestParallelForErr(t *testing.T) {
+	for _, workers := range []int{1, 4} {
+		visited := make([]bool, 100)
+		err := ParallelForErr(len(visited), workers, func(i int) error {
+			visited[i] = true
+			return nil
+		})
+		if err != nil {
+			t.Errorf("TestParallelForErr(%d workers) = %v, want nil", workers, err)
+		}
+		for i, v := range visited {
+			if !v {
+				t.Errorf("TestParallelForErr(%d workers): item %d was not visited", workers, i)
+				break
+			}
+		}
+
+		failure := errors.New("frame 7 failed")
+		err = ParallelForErr(100, workers, func(i int) error {
+			if i == 7 {
+				return failure
+			}
+			return nil
+		})
+		if err != failure {
+			t.Errorf("TestParallelForErr(%d workers) = %v, want %v", workers, err, failure)
+		}
+
+		err = ParallelForErr(100, workers, func(i int) error {
+			if i == 42 {
+				var stars []*Star
+				_ = stars[i]
+			}
+			return nil
+		})
+		if err == nil || !strings.Contains(err.Error(), "worker panicked") {
+			t.Errorf("TestParallelForErr(%d workers) = %v, want a recovered panic", workers, err)
+		}
+	}
+}
+
+
+// TestParallelSum checks that ParallelSum adds the values in the order of the items, so sums whose rounding depends
+// on the order are bit-identical for every number of workers.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if a sum differs from the serial one.
+func TestParallelSum(t *testing.T) {
+	// values of very different sizes, so adding them in another order rounds differently
+	values := make([]float64, 1000)
+	for i := range values {
+		values[i] = float64(i%7+1) * math.Pow(10, float64(i%31-15))
+		if i%3 == 0 {
+			values[i] = -values[i]
+		}
+	}
+	want := 0.0
+	for _, v := range values {
+		want += v
+	}
+
+	for _, workers := range []int{1, 2, 3, 8, 64} {
+		for repeat := 0; repeat < 5; repeat++ {
+			got := ParallelSum(len(values), workers, func(i int) float64 {
+				return values[i]
+			})
+			if got != want {
+				t.Errorf("TestParallelSum(%d workers) = %v, want %v", workers, got, want)
+				break
+			}
+		}
+	}
+	if got := ParallelSum(0, 4, nil); got != 0 {
+		t.Errorf("TestParallelSum(no items) = %v, want 0", got)
+	}
+}
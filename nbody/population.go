@@ -0,0 +1,158 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Changing the set of stars of a universe while a simulation runs.
+
+package nbody
+
+// Stars are identified by their index in Universe.Stars. Functions that change the population
+// return how the indices moved, so that everything holding an index (such as -trace-star) stays consistent.
+// Every star also has an ID (see Star.ID), which never changes: the stars of an initial universe are numbered by
+// their index, stars added later get IDs no star had before, and a merged star keeps the ID of the heaviest of the
+// merged stars. Whatever follows stars over a run (such as -track-star and -trajectories) uses the IDs.
+
+
+// RemoveStars removes every star for which remove returns true. The remaining stars keep their order.
+// Input:
+//   - remove: predicate called once per star with its index and the star.
+// Output:
+//   - newIndex, where newIndex[i] is the new index of the star that had index i, or -1 if it was removed.
+func (u *Universe) RemoveStars(remove func(i int, s *Star) bool) []int {
+	newIndex := make([]int, len(u.Stars))
+	kept := u.Stars[:0]
+
+	for i, s := range u.Stars {
+		if remove(i, s) {
+			newIndex[i] = -1
+			continue
+		}
+		newIndex[i] = len(kept)
+		kept = append(kept, s)
+	}
+
+	// clear the tail, so removed stars can be garbage collected
+	for i := len(kept); i < len(u.Stars); i++ {
+		u.Stars[i] = nil
+	}
+	u.Stars = kept
+
+	return newIndex
+}
+
+
+// RemapIndex returns the new index of the star that had index i before a population change,
+// or -1 if it was removed or i was not a valid index.
+// Input:
+//   - newIndex: the mapping returned by RemoveStars.
+//   - i: an old index.
+// Output:
+//   - the new index, or -1.
+func RemapIndex(newIndex []int, i int) int {
+	if i < 0 || i >= len(newIndex) {
+		return -1
+	}
+	return newIndex[i]
+}
+
+
+// EscapedStars returns a predicate for RemoveStars selecting the stars that are farther than
+// margin times the width outside the universe rectangle, and so will probably never come back.
+// Input:
+//   - width: width of the universe.
+//   - height: height of the universe (see Bounds).
+//   - margin: distance outside the universe, as a fraction of the width.
+// Output:
+//   - the predicate.
+func EscapedStars(width, height, margin float64) func(i int, s *Star) bool {
+	low := -margin * width
+	highX := width + margin*width
+	highY := height + margin*width
+
+	return func(i int, s *Star) bool {
+		p := s.Position
+		return p.X < low || p.X > highX || p.Y < low || p.Y > highY
+	}
+}
+
+
+// AddGalaxy appends the stars of a galaxy to the universe like AddStars, tagged with the next galaxy number
+// (see Star.Galaxy and NumGalaxies), so the galaxy every star came from is known for the whole run.
+// Input:
+//   - g: the Galaxy to add.
+// Output:
+//   - the indices of the added stars.
+func (u *Universe) AddGalaxy(g Galaxy) []int {
+	galaxy := int32(u.NumGalaxies() + 1)
+	for _, s := range g {
+		s.Galaxy = galaxy
+	}
+	return u.AddStars(g...)
+}
+
+
+// NumGalaxies returns the largest galaxy number of the stars of the universe, or 0 if no star is tagged.
+func (u *Universe) NumGalaxies() int {
+	numGalaxies := 0
+	for _, s := range u.Stars {
+		numGalaxies = max(numGalaxies, int(s.Galaxy))
+	}
+	return numGalaxies
+}
+
+
+// AddStars appends stars to the universe, with IDs following the largest ID of the universe (see NextStarID).
+// Existing stars keep their indices.
+// Input:
+//   - stars: the stars to add.
+// Output:
+//   - the indices of the added stars.
+func (u *Universe) AddStars(stars ...*Star) []int {
+	indices := make([]int, len(stars))
+	id := u.NextStarID()
+	for i, s := range stars {
+		indices[i] = len(u.Stars)
+		s.ID = id + int64(i)
+		u.Stars = append(u.Stars, s)
+	}
+	return indices
+}
+
+
+// NextStarID returns the ID of the next star added to the universe: one more than the largest ID, or 0 for a
+// universe without stars. IDs of removed stars are only given again if they were the largest.
+func (u *Universe) NextStarID() int64 {
+	next := int64(0)
+	for _, s := range u.Stars {
+		next = max(next, s.ID+1)
+	}
+	return next
+}
+
+
+// AssignStarIDs numbers the stars of the universe by their index (see Star.ID), unless every star already has its
+// own ID, as in a snapshot or checkpoint of a run. Universes built star by star or read from files without IDs
+// have stars with the same ID (usually all 0), and are numbered; readers and NewSimulation call it, so every
+// universe of a run has IDs.
+func (u *Universe) AssignStarIDs() {
+	// most universes are numbered already
+	numbered := true
+	for i, s := range u.Stars {
+		if s.ID != int64(i) {
+			numbered = false
+			break
+		}
+	}
+	if numbered {
+		return
+	}
+
+	seen := make(map[int64]bool, len(u.Stars))
+	for _, s := range u.Stars {
+		if seen[s.ID] {
+			for i, s := range u.Stars {
+				s.ID = int64(i)
+			}
+			return
+		}
+		seen[s.ID] = true
+	}
+}
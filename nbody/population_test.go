@@ -0,0 +1,198 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for changing the population of a universe and numbering its stars in population.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package nbody
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type RemoveStarsTestCases struct {
+	id       string
+	universe *Universe
+	margin   float64
+	expected []int
+}
+
+
+// ReadRemoveStars reads test data for the RemoveStars function from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of RemoveStarsTestCases structs containing the universes, margins, and expected index mappings.
+func ReadRemoveStars(fileName string) []RemoveStarsTestCases {
+	file, err := os.Open(fileName)
+	Check(err)
+	defer file.Close()
+
+	var tests []RemoveStarsTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+
+		// the universe is "width" or "width height"
+		size := strings.Fields(parts[1])
+		width, err := strconv.ParseFloat(size[0], 64)
+		Check(err)
+		margin, err := strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+		Check(err)
+
+		u := NewUniverse(width)
+		if len(size) > 1 {
+			u.Height, err = strconv.ParseFloat(size[1], 64)
+			Check(err)
+		}
+		for _, point := range strings.Split(parts[3], ",") {
+			fields := strings.Fields(point)
+			x, err := strconv.ParseFloat(fields[0], 64)
+			Check(err)
+			y, err := strconv.ParseFloat(fields[1], 64)
+			Check(err)
+			u.Stars = append(u.Stars, NewStar(OrderedPair{X: x, Y: y}, OrderedPair{}, 1, 1))
+		}
+
+		var expected []int
+		for _, field := range strings.Fields(parts[4]) {
+			index, err := strconv.Atoi(field)
+			Check(err)
+			expected = append(expected, index)
+		}
+
+		tests = append(tests, RemoveStarsTestCases{
+			id:       strings.TrimSpace(parts[0]),
+			universe: u,
+			margin:   margin,
+			expected: expected,
+		})
+	}
+
+	return tests
+}
+
+
+// TestRemoveStars tests RemoveStars with EscapedStars: the returned mapping and the remaining stars.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestRemoveStars(t *testing.T) {
+	tests := ReadRemoveStars("../Tests/RemoveStars.txt")
+
+	for _, test := range tests {
+		before := append([]*Star(nil), test.universe.Stars...)
+		width, height := test.universe.Bounds()
+		newIndex := test.universe.RemoveStars(EscapedStars(width, height, test.margin))
+
+		for i := range test.expected {
+			if RemapIndex(newIndex, i) != test.expected[i] {
+				t.Errorf("TestRemoveStars(test %v) = %v, want %v", test.id, newIndex, test.expected)
+				break
+			}
+			if j := test.expected[i]; j >= 0 && (j >= len(test.universe.Stars) || test.universe.Stars[j] != before[i]) {
+				t.Errorf("TestRemoveStars(test %v): star %d is not at its new index %d", test.id, i, j)
+			}
+		}
+	}
+}
+
+
+type AssignStarIDsTestCases struct {
+	id       string
+	ids      []int64
+	added    int
+	expected []int64
+}
+
+
+// ReadAssignStarIDs reads test data for the AssignStarIDs and AddStars functions from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of AssignStarIDsTestCases structs containing the IDs before, the added stars, and the expected IDs.
+func ReadAssignStarIDs(fileName string) []AssignStarIDsTestCases {
+	file, err := os.Open(fileName)
+	Check(err)
+	defer file.Close()
+
+	var tests []AssignStarIDsTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		added, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+		Check(err)
+
+		tests = append(tests, AssignStarIDsTestCases{
+			id:       strings.TrimSpace(parts[0]),
+			ids:      parseIDList(parts[1]),
+			added:    added,
+			expected: parseIDList(parts[3]),
+		})
+	}
+
+	return tests
+}
+
+
+// parseIDList parses space-separated star IDs, or "-" for none.
+func parseIDList(text string) []int64 {
+	var ids []int64
+	for _, field := range strings.Fields(text) {
+		if field == "-" {
+			continue
+		}
+		id, err := strconv.ParseInt(field, 10, 64)
+		Check(err)
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+
+// TestAssignStarIDs tests that AssignStarIDs keeps distinct IDs and numbers the stars otherwise, and that AddStars
+// gives the stars it adds the following IDs.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestAssignStarIDs(t *testing.T) {
+	tests := ReadAssignStarIDs("../Tests/AssignStarIDs.txt")
+
+	for _, test := range tests {
+		u := NewUniverse(10)
+		for _, id := range test.ids {
+			s := NewStar(OrderedPair{}, OrderedPair{}, 1, 1)
+			s.ID = id
+			u.Stars = append(u.Stars, s)
+		}
+		u.AssignStarIDs()
+		for i := 0; i < test.added; i++ {
+			u.AddStars(NewStar(OrderedPair{}, OrderedPair{}, 1, 1))
+		}
+
+		var ids []int64
+		for _, s := range u.Stars {
+			ids = append(ids, s.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(test.expected) {
+			t.Errorf("TestAssignStarIDs(test %v) gave IDs %v, want %v", test.id, ids, test.expected)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Selecting stars by ID, by region, and by galaxy, for what follows some of the stars over a run
+// (options "-track-star", "-trajectory-stars", "-trajectory-galaxy", "-trajectory-region").
+
+package nbody
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A StarFilter reports whether a star is selected. Filters look at one star at a time, so a selection made in one
+// generation is kept for the rest of a run through the IDs of the selected stars (see SelectIDs).
+type StarFilter func(s *Star) bool
+
+
+// WithIDs returns a filter selecting the stars with the given IDs (see Star.ID).
+func WithIDs(ids ...int64) StarFilter {
+	selected := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		selected[id] = true
+	}
+	return func(s *Star) bool {
+		return selected[s.ID]
+	}
+}
+
+
+// InRegion returns a filter selecting the stars inside a rectangle, borders included.
+// Input:
+//   - corner0, corner1: two opposite corners of the rectangle, in any order.
+// Output:
+//   - the filter.
+func InRegion(corner0, corner1 OrderedPair) StarFilter {
+	low := OrderedPair{X: min(corner0.X, corner1.X), Y: min(corner0.Y, corner1.Y)}
+	high := OrderedPair{X: max(corner0.X, corner1.X), Y: max(corner0.Y, corner1.Y)}
+	return func(s *Star) bool {
+		p := s.Position
+		return p.X >= low.X && p.X <= high.X && p.Y >= low.Y && p.Y <= high.Y
+	}
+}
+
+
+// InGalaxy returns a filter selecting the stars created in a galaxy (see Star.Galaxy).
+// Input:
+//   - galaxy: the galaxy tag, numbered from 1, or 0 for the stars of no galaxy.
+// Output:
+//   - the filter.
+func InGalaxy(galaxy int32) StarFilter {
+	return func(s *Star) bool {
+		return s.Galaxy == galaxy
+	}
+}
+
+
+// AllOf returns a filter selecting the stars that every one of the given filters selects, or every star if none
+// is given.
+func AllOf(filters ...StarFilter) StarFilter {
+	return func(s *Star) bool {
+		for _, filter := range filters {
+			if !filter(s) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+
+// Select returns the indices of the stars of the universe that a filter selects, in the order of the universe.
+func (u *Universe) Select(filter StarFilter) []int {
+	var indices []int
+	for i, s := range u.Stars {
+		if filter(s) {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+
+// SelectIDs returns the IDs of the stars of the universe that a filter selects, in the order of the universe.
+func (u *Universe) SelectIDs(filter StarFilter) []int64 {
+	var ids []int64
+	for _, s := range u.Stars {
+		if filter(s) {
+			ids = append(ids, s.ID)
+		}
+	}
+	return ids
+}
+
+
+// StarIndex returns the index of the star with an ID, or -1 if no star of the universe has it.
+// It goes through the stars, so code looking up many stars should use WithIDs and Select instead.
+func (u *Universe) StarIndex(id int64) int {
+	for i, s := range u.Stars {
+		if s.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+
+// ParseStarList parses a comma-separated list of star IDs such as "0,5,7" (see Star.ID).
+// Input:
+//   - list: the comma-separated string.
+//   - u: pointer to the Universe the stars must be in.
+// Output:
+//   - the IDs in the order given, and an error if an entry is not the ID of a star of u or is given twice.
+func ParseStarList(list string, u *Universe) ([]int64, error) {
+	var ids []int64
+	seen := make(map[int64]bool)
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil || u.StarIndex(id) < 0 {
+			return nil, fmt.Errorf("invalid star %q in list %q: no star of the universe has this ID", field, list)
+		}
+		if seen[id] {
+			return nil, fmt.Errorf("star %d is given twice in list %q", id, list)
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+
+// ParseRegion parses a rectangle given by two opposite corners as "x0,y0,x1,y1", in meters.
+// Output:
+//   - the two corners, or an error if the text is not four numbers.
+func ParseRegion(text string) (OrderedPair, OrderedPair, error) {
+	fields := strings.Split(text, ",")
+	if len(fields) != 4 {
+		return OrderedPair{}, OrderedPair{}, fmt.Errorf("invalid region %q: expected x0,y0,x1,y1", text)
+	}
+	var v [4]float64
+	for i, field := range fields {
+		var err error
+		if v[i], err = strconv.ParseFloat(strings.TrimSpace(field), 64); err != nil {
+			return OrderedPair{}, OrderedPair{}, fmt.Errorf("invalid region %q: %q is not a number", text, field)
+		}
+	}
+	return OrderedPair{X: v[0], Y: v[1]}, OrderedPair{X: v[2], Y: v[3]}, nil
+}
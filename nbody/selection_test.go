@@ -0,0 +1,140 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for selecting stars in selection.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package nbody
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type SelectTestCases struct {
+	id       string
+	universe *Universe
+	filter   StarFilter
+	expected []int
+}
+
+
+// ReadSelect reads test data for the star filters from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of SelectTestCases structs containing the universes, filters, and expected indices.
+func ReadSelect(fileName string) []SelectTestCases {
+	file, err := os.Open(fileName)
+	Check(err)
+	defer file.Close()
+
+	var tests []SelectTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		u := NewUniverse(10)
+		for _, star := range strings.Split(parts[1], ",") {
+			fields := strings.Fields(star)
+			id, err := strconv.ParseInt(fields[0], 10, 64)
+			Check(err)
+			x, err := strconv.ParseFloat(fields[1], 64)
+			Check(err)
+			y, err := strconv.ParseFloat(fields[2], 64)
+			Check(err)
+			galaxy, err := strconv.Atoi(fields[3])
+			Check(err)
+			s := NewStar(OrderedPair{X: x, Y: y}, OrderedPair{}, 1, 1)
+			s.ID, s.Galaxy = id, int32(galaxy)
+			u.Stars = append(u.Stars, s)
+		}
+
+		// the filter is "all" or some of "ids=...", "galaxy=...", and "region=..."
+		var filters []StarFilter
+		for _, field := range strings.Fields(parts[2]) {
+			name, value, _ := strings.Cut(field, "=")
+			switch name {
+			case "ids":
+				var ids []int64
+				for _, entry := range strings.Split(value, ",") {
+					id, err := strconv.ParseInt(entry, 10, 64)
+					Check(err)
+					ids = append(ids, id)
+				}
+				filters = append(filters, WithIDs(ids...))
+			case "galaxy":
+				galaxy, err := strconv.Atoi(value)
+				Check(err)
+				filters = append(filters, InGalaxy(int32(galaxy)))
+			case "region":
+				corner0, corner1, err := ParseRegion(value)
+				Check(err)
+				filters = append(filters, InRegion(corner0, corner1))
+			}
+		}
+
+		var expected []int
+		for _, field := range strings.Fields(parts[3]) {
+			if field == "-" {
+				continue
+			}
+			index, err := strconv.Atoi(field)
+			Check(err)
+			expected = append(expected, index)
+		}
+
+		tests = append(tests, SelectTestCases{
+			id:       strings.TrimSpace(parts[0]),
+			universe: u,
+			filter:   AllOf(filters...),
+			expected: expected,
+		})
+	}
+
+	return tests
+}
+
+
+// TestSelect tests that Select and SelectIDs give the stars a filter selects, in the order of the universe, and
+// that StarIndex finds every star by its ID.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestSelect(t *testing.T) {
+	tests := ReadSelect("../Tests/Select.txt")
+
+	for _, test := range tests {
+		u := test.universe
+		indices := u.Select(test.filter)
+		if fmt.Sprint(indices) != fmt.Sprint(test.expected) {
+			t.Errorf("TestSelect(test %v) = %v, want %v", test.id, indices, test.expected)
+		}
+
+		var expectedIDs []int64
+		for _, i := range test.expected {
+			expectedIDs = append(expectedIDs, u.Stars[i].ID)
+		}
+		if ids := u.SelectIDs(test.filter); fmt.Sprint(ids) != fmt.Sprint(expectedIDs) {
+			t.Errorf("TestSelect(test %v) gave IDs %v, want %v", test.id, ids, expectedIDs)
+		}
+
+		for i, s := range u.Stars {
+			if index := u.StarIndex(s.ID); index != i {
+				t.Errorf("TestSelect(test %v): StarIndex(%d) = %d, want %d", test.id, s.ID, index, i)
+			}
+		}
+		if index := u.StarIndex(-1); index != -1 {
+			t.Errorf("TestSelect(test %v): StarIndex(-1) = %d, want -1", test.id, index)
+		}
+	}
+}
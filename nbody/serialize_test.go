@@ -0,0 +1,338 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for saving and loading universes in serialize.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package nbody
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestUniverseSaveLoad checks that every format gives back exactly the saved universe.
+// The universes of Tests/TreeBuild.txt are used, with velocities and colors added so every field is checked,
+// square and as rectangles half as high.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if a loaded universe differs.
+func TestUniverseSaveLoad(t *testing.T) {
+	tests := ReadTreeBuild("../Tests/TreeBuild.txt")
+
+	for _, test := range tests {
+		u := test.Universe
+		for i, s := range u.Stars {
+			s.Velocity = OrderedPair{X: s.Position.Y / 3, Y: -s.Position.X / 7}
+			s.Acceleration = OrderedPair{X: 1e-9 * float64(i), Y: -1e-9}
+			s.Radius = 0.1 * s.Mass
+			s.Red, s.Green, s.Blue = uint8(i), uint8(2*i), 255
+			s.Galaxy = int32(i % 3)
+			s.ID = int64(7*i + 2)
+		}
+
+		for _, height := range []float64{0, u.Width / 2} {
+			u.Height = height
+			for _, format := range []string{"json", "binary"} {
+				var buf bytes.Buffer
+				if err := u.Save(&buf, format); err != nil {
+					t.Errorf("TestUniverseSaveLoad(test %v, %s): Save failed: %v", test.ID, format, err)
+					continue
+				}
+
+				loaded, err := LoadUniverse(&buf, format)
+				if err != nil {
+					t.Errorf("TestUniverseSaveLoad(test %v, %s): LoadUniverse failed: %v", test.ID, format, err)
+					continue
+				}
+
+				if loaded.Width != u.Width || loaded.Height != u.Height || len(loaded.Stars) != len(u.Stars) {
+					t.Errorf("TestUniverseSaveLoad(test %v, %s) = %v x %v with %d stars, want %v x %v with %d stars",
+						test.ID, format, loaded.Width, loaded.Height, len(loaded.Stars), u.Width, u.Height, len(u.Stars))
+					continue
+				}
+				for i := range u.Stars {
+					if *loaded.Stars[i] != *u.Stars[i] {
+						t.Errorf("TestUniverseSaveLoad(test %v, %s): star %d = %+v, want %+v", test.ID, format, i, *loaded.Stars[i], *u.Stars[i])
+					}
+				}
+			}
+		}
+	}
+}
+
+
+// TestUniverseJSON checks that a generation written by WriteUniverseJSON is read back exactly, also as a snapshot.
+// The universes of Tests/TreeBuild.txt are used, with every field of the stars and the universe set.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if a loaded universe or generation differs.
+func TestUniverseJSON(t *testing.T) {
+	tests := ReadTreeBuild("../Tests/TreeBuild.txt")
+
+	for n, test := range tests {
+		u := test.Universe
+		u.Time = 1.5e17
+		for i, s := range u.Stars {
+			s.Velocity = OrderedPair{X: s.Position.Y / 3, Y: -s.Position.X / 7}
+			s.Acceleration = OrderedPair{X: 1e-9 * float64(i), Y: -1e-9}
+			s.Radius = 0.1 * s.Mass
+			s.Softening = 1e19 / float64(i+1)
+			s.Age = 1e15 * float64(i)
+			s.Red, s.Green, s.Blue = uint8(i), uint8(2*i), 255
+			s.Galaxy = int32(i % 3)
+			s.ID = int64(7*i + 2)
+		}
+		generation := 1000 * n
+
+		dir := t.TempDir()
+		fileName := filepath.Join(dir, "universe.json")
+		if err := WriteUniverseJSON(u, generation, fileName); err != nil {
+			t.Errorf("TestUniverseJSON(test %v): WriteUniverseJSON failed: %v", test.ID, err)
+			continue
+		}
+		loaded, loadedGeneration, err := ReadUniverseJSON(fileName)
+		if err != nil {
+			t.Errorf("TestUniverseJSON(test %v): ReadUniverseJSON failed: %v", test.ID, err)
+			continue
+		}
+		if loadedGeneration != generation || !reflect.DeepEqual(loaded, u) {
+			t.Errorf("TestUniverseJSON(test %v) = generation %d, universe %+v, want generation %d, universe %+v",
+				test.ID, loadedGeneration, loaded, generation, u)
+		}
+
+		// JSON snapshots are found and read like text snapshots
+		Check(WriteSnapshot(u, generation, SnapshotFileName(dir, generation, "json")))
+		universes, generations, err := ReadSnapshotDir(dir)
+		Check(err)
+		if len(universes) != 1 || generations[0] != generation || !reflect.DeepEqual(universes[0], u) {
+			t.Errorf("TestUniverseJSON(test %v): JSON snapshot read back as generations %v, want [%d]", test.ID, generations, generation)
+		}
+	}
+}
+
+
+type BinaryUniverseTestCases struct {
+	id         string
+	magic      string
+	version    int
+	starSize   int
+	numStars   int
+	cutBytes   int
+	isError    bool
+	generation int
+	height     float64
+	timeStep   float64
+}
+
+
+// ReadBinaryUniverseTests reads test data for ReadBinaryUniverse from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of BinaryUniverseTestCases structs containing the files to build and the expected universes.
+func ReadBinaryUniverseTests(fileName string) []BinaryUniverseTestCases {
+	file, err := os.Open(fileName)
+	Check(err)
+	defer file.Close()
+
+	var tests []BinaryUniverseTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 7 {
+			continue
+		}
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+
+		test := BinaryUniverseTestCases{id: parts[0], magic: parts[1]}
+		var numbers [4]int
+		for i := range numbers {
+			numbers[i], err = strconv.Atoi(parts[2+i])
+			Check(err)
+		}
+		test.version, test.starSize, test.numStars, test.cutBytes = numbers[0], numbers[1], numbers[2], numbers[3]
+
+		if parts[6] == "error" {
+			test.isError = true
+		} else {
+			fields := strings.Fields(parts[6])
+			if len(fields) != 4 {
+				continue
+			}
+			var count int
+			count, err = strconv.Atoi(fields[0])
+			Check(err)
+			if count != test.numStars {
+				continue
+			}
+			test.generation, err = strconv.Atoi(fields[1])
+			Check(err)
+			test.height, err = strconv.ParseFloat(fields[2], 64)
+			Check(err)
+			test.timeStep, err = strconv.ParseFloat(fields[3], 64)
+			Check(err)
+		}
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// BinaryTestStar returns star i of the binary universes of Tests/BinaryUniverse.txt, with every field set.
+// Its ID is not its index, so IDs that are read can be told from IDs assigned by AssignStarIDs.
+func BinaryTestStar(i int) *Star {
+	f := float64(i + 1)
+	return &Star{
+		Position: OrderedPair{X: f, Y: -f}, Velocity: OrderedPair{X: 2 * f, Y: 3 * f},
+		Acceleration: OrderedPair{X: -f / 7, Y: f / 9},
+		Mass:         1e30 * f, Radius: 7e8, Softening: 1e17, Age: 1e15 * f,
+		Red: uint8(i), Blue: 200, Green: uint8(2 * i), Pinned: i%2 == 1, Galaxy: int32(i + 1),
+		ID: int64(10*i + 3),
+	}
+}
+
+
+// TestReadBinaryUniverse tests ReadBinaryUniverse on current, legacy, newer, and damaged binary universes.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestReadBinaryUniverse(t *testing.T) {
+	tests := ReadBinaryUniverseTests("../Tests/BinaryUniverse.txt")
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		buf.WriteString(test.magic)
+		if test.magic == "BHUN" || test.magic == "BHUR" {
+			binary.Write(&buf, binary.LittleEndian, uint64(test.numStars))
+			binary.Write(&buf, binary.LittleEndian, 100.0)
+			if test.magic == "BHUR" {
+				binary.Write(&buf, binary.LittleEndian, 50.0)
+			}
+		} else {
+			binary.Write(&buf, binary.LittleEndian, binaryHeader{
+				Version: uint16(test.version), StarSize: uint16(test.starSize), NumStars: uint64(test.numStars),
+				Generation: 7, Width: 100, Height: 50, TimeStep: 2, Time: 3,
+			})
+		}
+		for i := 0; i < test.numStars; i++ {
+			record := bytes.Repeat([]byte{0xff}, max(test.starSize, binaryStarSize))
+			EncodeStar(record, BinaryTestStar(i))
+			buf.Write(record[:test.starSize])
+		}
+		buf.Truncate(buf.Len() - test.cutBytes)
+
+		u, generation, timeStep, err := ReadBinaryUniverse(&buf)
+		if test.isError {
+			if err == nil {
+				t.Errorf("TestReadBinaryUniverse(test %v) returned no error, want one", test.id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestReadBinaryUniverse(test %v) failed: %v", test.id, err)
+			continue
+		}
+
+		if len(u.Stars) != test.numStars || generation != test.generation || u.Width != 100 || u.Height != test.height || timeStep != test.timeStep {
+			t.Errorf("TestReadBinaryUniverse(test %v) = %d stars, generation %d, %v x %v, time step %v, want %d, %d, 100 x %v, %v",
+				test.id, len(u.Stars), generation, u.Width, u.Height, timeStep, test.numStars, test.generation, test.height, test.timeStep)
+			continue
+		}
+		for i, s := range u.Stars {
+			// the stars of records without an ID are numbered
+			want := *BinaryTestStar(i)
+			if test.starSize < binaryStarSize {
+				want.ID = int64(i)
+			}
+			if *s != want {
+				t.Errorf("TestReadBinaryUniverse(test %v): star %d = %+v, want %+v", test.id, i, *s, want)
+			}
+		}
+	}
+}
+
+
+// TestBinaryUniverse checks that WriteBinaryUniverse and ReadBinaryUniverse give back exactly the saved universe,
+// generation, and time step, and that a universe saved in the legacy format reads the same.
+// The universes of Tests/TreeBuild.txt are used, with every field of the stars and the universe set.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if a loaded universe differs.
+func TestBinaryUniverse(t *testing.T) {
+	tests := ReadTreeBuild("../Tests/TreeBuild.txt")
+
+	for n, test := range tests {
+		u := test.Universe
+		u.Height = u.Width / 2
+		u.Time = 1.5e17
+		for i := range u.Stars {
+			s := BinaryTestStar(i)
+			s.Position = u.Stars[i].Position
+			u.Stars[i] = s
+		}
+
+		var buf bytes.Buffer
+		if err := WriteBinaryUniverse(&buf, u, 1000*n, 2e14); err != nil {
+			t.Errorf("TestBinaryUniverse(test %v): WriteBinaryUniverse failed: %v", test.ID, err)
+			continue
+		}
+		loaded, generation, timeStep, err := ReadBinaryUniverse(&buf)
+		if err != nil || generation != 1000*n || timeStep != 2e14 || !reflect.DeepEqual(loaded, u) {
+			t.Errorf("TestBinaryUniverse(test %v) = %+v, generation %d, time step %v, error %v, want %+v, %d, 2e14",
+				test.ID, loaded, generation, timeStep, err, u, 1000*n)
+		}
+
+		// the legacy format wrote the stars with encoding/binary, in the layout of the records before the ID
+		buf.Reset()
+		buf.Write(rectangleMagic[:])
+		binary.Write(&buf, binary.LittleEndian, uint64(len(u.Stars)))
+		binary.Write(&buf, binary.LittleEndian, [2]float64{u.Width, u.Height})
+		var record [binaryStarSize]byte
+		for _, s := range u.Stars {
+			EncodeStar(record[:], s)
+			buf.Write(record[:binaryMinStarSize])
+		}
+		legacy, err := LoadUniverse(&buf, "binary")
+		u.Time = 0
+		for i, s := range u.Stars {
+			s.ID = int64(i)
+		}
+		if err != nil || !reflect.DeepEqual(legacy, u) {
+			t.Errorf("TestBinaryUniverse(test %v): legacy universe read as %+v, error %v, want %+v", test.ID, legacy, err, u)
+		}
+	}
+}
+
+
+// BenchmarkSaveUniverse times saving and loading a galaxy in the binary and the JSON format for every size of
+// BenchmarkSizes.
+// Run it with: go test -run '^$' -bench SaveUniverse
+func BenchmarkSaveUniverse(b *testing.B) {
+	for _, format := range []string{"binary", "json"} {
+		for _, n := range BenchmarkSizes {
+			b.Run(fmt.Sprintf("%s/N=%d", format, n), func(b *testing.B) {
+				u := BenchmarkUniverse(n)
+				var buf bytes.Buffer
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					buf.Reset()
+					Check(u.Save(&buf, format))
+					_, err := LoadUniverse(&buf, format)
+					Check(err)
+				}
+			})
+		}
+	}
+}
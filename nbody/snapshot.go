@@ -0,0 +1,320 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Reading and writing universe snapshots so that saved runs can be analyzed without re-simulating.
+
+package nbody
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A snapshot file is a plain text file:
+//
+//	# generation
+//	1000
+//	# width
+//	1e+23
+//	# x y vx vy ax ay mass radius red green blue id
+//	<one line per star>
+//
+// The width of a rectangular universe is followed by its height on the same line ("# width height").
+// The id column (see Star.ID) may be left out, as in snapshots written before it existed; the stars are then
+// numbered by their line.
+// Lines starting with "#" and empty lines are ignored by the reader.
+// With -snapshot-format json, snapshots are JSON universe files instead (see WriteUniverseJSON), which also keep
+// the simulated time and the ages and softening lengths of the stars, with -snapshot-format tipsy TIPSY files for
+// other N-body analysis tools (see WriteTipsy), and with -snapshot-format hdf5 HDF5 files for h5py (see WriteHDF5).
+// Files are read in the format of their extension; HDF5 snapshots are only written.
+
+// CheckSnapshotFormat returns an error if name is not a known snapshot format: "text" (the format above), "json",
+// "tipsy", or "hdf5".
+func CheckSnapshotFormat(name string) error {
+	if name != "text" && name != "json" && name != "tipsy" && name != "hdf5" {
+		return fmt.Errorf("unknown snapshot format %q (use text, json, tipsy, or hdf5)", name)
+	}
+	return nil
+}
+
+
+// WriteSnapshot writes a universe to a snapshot file.
+// Input:
+//   - u: pointer to the Universe to save.
+//   - generation: generation number of the universe.
+//   - fileName: path of the file to create.
+// Output:
+//   - an error if the file cannot be written.
+func WriteSnapshot(u *Universe, generation int, fileName string) error {
+	switch filepath.Ext(fileName) {
+	case ".json":
+		return WriteUniverseJSON(u, generation, fileName)
+	case ".tipsy":
+		return WriteTipsyFile(u, fileName)
+	case ".h5":
+		return WriteHDF5File(u, generation, fileName)
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+
+	fmt.Fprintln(w, "# generation")
+	fmt.Fprintln(w, generation)
+	if u.Height > 0 {
+		fmt.Fprintln(w, "# width height")
+		fmt.Fprintln(w, u.Width, u.Height)
+	} else {
+		fmt.Fprintln(w, "# width")
+		fmt.Fprintln(w, u.Width)
+	}
+	fmt.Fprintln(w, "# x y vx vy ax ay mass radius red green blue id")
+
+	for _, s := range u.Stars {
+		fmt.Fprintln(w, s.Position.X, s.Position.Y,
+			s.Velocity.X, s.Velocity.Y,
+			s.Acceleration.X, s.Acceleration.Y,
+			s.Mass, s.Radius,
+			s.Red, s.Green, s.Blue, s.ID)
+	}
+
+	err = w.Flush()
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+
+// ReadSnapshot reads a snapshot file written by WriteSnapshot.
+// Input:
+//   - fileName: path of the snapshot file.
+// Output:
+//   - Pointer to the Universe stored in the file and its generation number, or an error naming the file and the
+//     offending line.
+func ReadSnapshot(fileName string) (*Universe, int, error) {
+	switch filepath.Ext(fileName) {
+	case ".json":
+		return ReadUniverseJSON(fileName)
+	case ".tipsy":
+		u, err := LoadTipsy(fileName)
+		return u, tipsyGeneration(fileName), err
+	}
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+
+	u, generation, err := ReadSnapshotText(file)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", fileName, err)
+	}
+	return u, generation, nil
+}
+
+
+// ReadSnapshotText reads a snapshot in the text format described above.
+// Input:
+//   - r: reader of the snapshot.
+// Output:
+//   - Pointer to the Universe and its generation number, or an error naming the offending line.
+func ReadSnapshotText(r io.Reader) (*Universe, int, error) {
+	scanner := bufio.NewScanner(r)
+	var u *Universe
+	generation := -1
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		switch {
+		case generation < 0:
+			g, err := strconv.Atoi(line)
+			if err != nil || g < 0 {
+				return nil, 0, fmt.Errorf("line %d: expected the generation as a non-negative integer, got %q", lineNumber, line)
+			}
+			generation = g
+
+		case u == nil:
+			size := strings.Fields(line)
+			if len(size) > 2 {
+				return nil, 0, fmt.Errorf("line %d: expected the width, and the height of a rectangular universe, got %q", lineNumber, line)
+			}
+			values := make([]float64, len(size))
+			for i, field := range size {
+				value, err := strconv.ParseFloat(field, 64)
+				if err != nil || value <= 0 {
+					return nil, 0, fmt.Errorf("line %d: expected the width as a positive number, got %q", lineNumber, line)
+				}
+				values[i] = value
+			}
+			u = &Universe{Width: values[0], Stars: []*Star{}}
+			if len(values) == 2 {
+				u.Height = values[1]
+			}
+
+		default:
+			s, err := parseSnapshotStar(line)
+			if err != nil {
+				return nil, 0, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			u.Stars = append(u.Stars, s)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if u == nil {
+		return nil, 0, errors.New("missing generation or width")
+	}
+	u.AssignStarIDs()
+	return u, generation, nil
+}
+
+
+// parseSnapshotStar parses the line of a star in a text snapshot.
+// Output:
+//   - the Star, or an error for a wrong number of fields or an invalid value.
+func parseSnapshotStar(line string) (*Star, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 11 && len(fields) != 12 {
+		return nil, fmt.Errorf("expected 12 fields (x y vx vy ax ay mass radius red green blue id) or 11 without the id, got %d", len(fields))
+	}
+
+	var nums [8]float64
+	for i := range nums {
+		v, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return nil, fmt.Errorf("field %d must be a number, got %q", i+1, fields[i])
+		}
+		nums[i] = v
+	}
+
+	var colors [3]uint8
+	for i := range colors {
+		c, err := strconv.ParseUint(fields[8+i], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("field %d must be a color from 0 to 255, got %q", 9+i, fields[8+i])
+		}
+		colors[i] = uint8(c)
+	}
+
+	var id int64
+	if len(fields) == 12 {
+		var err error
+		if id, err = strconv.ParseInt(fields[11], 10, 64); err != nil {
+			return nil, fmt.Errorf("field 12 must be an integer ID, got %q", fields[11])
+		}
+	}
+
+	return &Star{
+		Position:     OrderedPair{nums[0], nums[1]},
+		Velocity:     OrderedPair{nums[2], nums[3]},
+		Acceleration: OrderedPair{nums[4], nums[5]},
+		Mass:         nums[6],
+		Radius:       nums[7],
+		Red:          colors[0],
+		Green:        colors[1],
+		Blue:         colors[2],
+		ID:           id,
+	}, nil
+}
+
+
+// SnapshotFileName returns the name used for the snapshot of a generation inside a directory,
+// with the extension of a snapshot format (see CheckSnapshotFormat).
+func SnapshotFileName(dir string, generation int, format string) string {
+	extension := map[string]string{"text": ".txt", "json": ".json", "tipsy": ".tipsy", "hdf5": ".h5"}[format]
+	return filepath.Join(dir, fmt.Sprintf("snapshot_%08d%s", generation, extension))
+}
+
+
+// WriteSnapshots saves the selected universes of a simulation into a directory.
+// Input:
+//   - timePoints: slice of Universe pointers returned by BarnesHut.
+//   - generations: generation of every element of timePoints (see SavedGenerations).
+//   - indices: indices in timePoints to save (see SavedFrameIndices and AdaptiveFrameIndices).
+//   - dir: output directory, created if it does not exist.
+//   - format: snapshot format (see CheckSnapshotFormat).
+// Output:
+//   - the first error writing a snapshot, or nil (one snapshot file per saved generation is written).
+func WriteSnapshots(timePoints []*Universe, generations []int, indices []int, dir, format string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	for _, i := range indices {
+		if err := WriteSnapshot(timePoints[i], generations[i], SnapshotFileName(dir, generations[i], format)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+
+// ReadSnapshotDir reads all snapshot files of a directory, ordered by generation.
+// Input:
+//   - dir: directory containing snapshot_*.txt, snapshot_*.json, or snapshot_*.tipsy files.
+// Output:
+//   - slice of Universe pointers and the matching slice of generation numbers, or the error of the first file
+//     that cannot be read.
+func ReadSnapshotDir(dir string) ([]*Universe, []int, error) {
+	fileNames, err := filepath.Glob(filepath.Join(dir, "snapshot_*.txt"))
+	if err != nil {
+		return nil, nil, err
+	}
+	jsonNames, err := filepath.Glob(filepath.Join(dir, "snapshot_*.json"))
+	if err != nil {
+		return nil, nil, err
+	}
+	tipsyNames, err := filepath.Glob(filepath.Join(dir, "snapshot_*.tipsy"))
+	if err != nil {
+		return nil, nil, err
+	}
+	fileNames = append(append(fileNames, jsonNames...), tipsyNames...)
+
+	if len(fileNames) == 0 {
+		return nil, nil, fmt.Errorf("no snapshot files found in %s", dir)
+	}
+
+	universes := make([]*Universe, len(fileNames))
+	generations := make([]int, len(fileNames))
+	for i, name := range fileNames {
+		universes[i], generations[i], err = ReadSnapshot(name)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// file names are zero padded, but sort by the stored generation to be safe
+	order := make([]int, len(fileNames))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return generations[order[a]] < generations[order[b]]
+	})
+
+	sortedUniverses := make([]*Universe, len(order))
+	sortedGenerations := make([]int, len(order))
+	for i, j := range order {
+		sortedUniverses[i] = universes[j]
+		sortedGenerations[i] = generations[j]
+	}
+
+	return sortedUniverses, sortedGenerations, nil
+}
@@ -0,0 +1,124 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for reading snapshots in snapshot.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package nbody
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type ReadSnapshotTextTestCases struct {
+	id         string
+	file       string
+	generation int
+	width      float64
+	height     float64
+	numStars   int
+	ids        []int64 // IDs of the stars, or nil if not checked
+	errorLine  int // line the error must name, 0 for an error without a line, -1 if the snapshot is valid
+}
+
+
+// ReadReadSnapshotText reads test data for ReadSnapshotText from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of ReadSnapshotTextTestCases structs containing the snapshot and the expected universe or error.
+func ReadReadSnapshotText(fileName string) []ReadSnapshotTextTestCases {
+	file, err := os.Open(fileName)
+	Check(err)
+	defer file.Close()
+
+	var tests []ReadSnapshotTextTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 3 {
+			continue
+		}
+
+		test := ReadSnapshotTextTestCases{id: strings.TrimSpace(parts[0]), errorLine: -1}
+		var lines []string
+		for _, l := range strings.Split(parts[1], ";") {
+			lines = append(lines, strings.TrimSpace(l))
+		}
+		test.file = strings.Join(lines, "\n")
+
+		fields := strings.Fields(parts[2])
+		if fields[0] == "error" {
+			test.errorLine = 0
+			if len(fields) > 1 {
+				test.errorLine, err = strconv.Atoi(fields[1])
+				Check(err)
+			}
+		} else {
+			test.generation, err = strconv.Atoi(fields[0])
+			Check(err)
+			test.width, err = strconv.ParseFloat(fields[1], 64)
+			Check(err)
+			test.numStars, err = strconv.Atoi(fields[2])
+			Check(err)
+			for _, field := range fields[3:] {
+				if list, found := strings.CutPrefix(field, "ids="); found {
+					for _, id := range strings.Split(list, ",") {
+						v, err := strconv.ParseInt(id, 10, 64)
+						Check(err)
+						test.ids = append(test.ids, v)
+					}
+					continue
+				}
+				test.height, err = strconv.ParseFloat(field, 64)
+				Check(err)
+			}
+		}
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestReadSnapshotText tests that ReadSnapshotText reads valid snapshots and rejects invalid ones with the line at fault.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestReadSnapshotText(t *testing.T) {
+	tests := ReadReadSnapshotText("../Tests/ReadSnapshotText.txt")
+
+	for _, test := range tests {
+		u, generation, err := ReadSnapshotText(strings.NewReader(test.file))
+
+		if test.errorLine >= 0 {
+			if err == nil {
+				t.Errorf("TestReadSnapshotText(test %v) = no error, want an error", test.id)
+			} else if test.errorLine > 0 && !strings.HasPrefix(err.Error(), "line "+strconv.Itoa(test.errorLine)+":") {
+				t.Errorf("TestReadSnapshotText(test %v) = error %q, want an error on line %d", test.id, err, test.errorLine)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestReadSnapshotText(test %v) = error %v, want none", test.id, err)
+			continue
+		}
+		if generation != test.generation || u.Width != test.width || u.Height != test.height || len(u.Stars) != test.numStars {
+			t.Errorf("TestReadSnapshotText(test %v) = generation %d, width %v, height %v, %d stars, want generation %d, width %v, height %v, %d stars",
+				test.id, generation, u.Width, u.Height, len(u.Stars), test.generation, test.width, test.height, test.numStars)
+			continue
+		}
+		for i, id := range test.ids {
+			if u.Stars[i].ID != id {
+				t.Errorf("TestReadSnapshotText(test %v): star %d has ID %d, want %d", test.id, i, u.Stars[i].ID, id)
+			}
+		}
+	}
+}
@@ -0,0 +1,268 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Tagging stars by their galaxy of origin and tracking which ones end up in tidal tails.
+
+package nbody
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// Origin tags a star with the galaxy it started in and its initial distance from that galaxy's center.
+type Origin struct {
+	Galaxy int     // index of the galaxy, numbered by the order of the central black holes
+	radius float64 // initial distance from the galaxy's black hole
+	center bool    // true for the black hole itself
+}
+
+// OriginColors are the colors of the stars of the first galaxies drawn with -color-origin;
+// later galaxies reuse them cyclically.
+var OriginColors = []color.RGBA{
+	{255, 170, 60, 255},  // orange
+	{90, 200, 255, 255},  // light blue
+	{120, 255, 120, 255}, // green
+	{255, 110, 220, 255}, // pink
+	{255, 255, 110, 255}, // yellow
+}
+
+
+// TagOrigins tags every star of a universe, usually the initial one, with its galaxy of origin.
+// Stars created in a galaxy carry its number (see Star.Galaxy), and galaxy g+1 is galaxy g here; its center is
+// its first point mass found by DiskStars (its central black hole), or else the center of mass of its stars.
+// In a universe without such numbers, the centers of the galaxies are the point masses found by DiskStars, in
+// order. Every other star belongs to the nearest center. Without any black hole or number all stars belong to
+// galaxy 0, centered on the center of mass.
+// Input:
+//   - u: pointer to the Universe.
+// Output:
+//   - slice with the Origin of every star, in the order of u.Stars, and the number of galaxies.
+func TagOrigins(u *Universe) ([]Origin, int) {
+	light := make(map[*Star]bool)
+	for _, s := range DiskStars(u.Stars) {
+		light[s] = true
+	}
+
+	origins := make([]Origin, len(u.Stars))
+	centers := make([]OrderedPair, u.NumGalaxies())
+	found := make([]bool, len(centers)) // whether galaxy g has a center
+	if len(centers) > 0 {
+		members := make([][]*Star, len(centers))
+		for i, s := range u.Stars {
+			if s.Galaxy == 0 {
+				continue
+			}
+			g := int(s.Galaxy) - 1
+			members[g] = append(members[g], s)
+			if !light[s] && !found[g] {
+				origins[i] = Origin{Galaxy: g, center: true}
+				centers[g], found[g] = s.Position, true
+			}
+		}
+		// a galaxy without a black hole, or with all its stars removed (it keeps its number but no center)
+		for g := range centers {
+			if !found[g] && len(members[g]) > 0 {
+				centers[g], found[g] = CenterOfMass(members[g]), true
+			}
+		}
+	} else {
+		for i, s := range u.Stars {
+			if !light[s] {
+				origins[i] = Origin{Galaxy: len(centers), center: true}
+				centers, found = append(centers, s.Position), append(found, true)
+			}
+		}
+		if len(centers) == 0 {
+			centers, found = append(centers, CenterOfMass(u.Stars)), append(found, true)
+		}
+	}
+
+	for i, s := range u.Stars {
+		switch {
+		case origins[i].center:
+		case s.Galaxy > 0:
+			_, _, d := Distance(s.Position, centers[s.Galaxy-1])
+			origins[i] = Origin{Galaxy: int(s.Galaxy) - 1, radius: d}
+		default:
+			best := math.Inf(1)
+			for g, c := range centers {
+				if _, _, d := Distance(s.Position, c); found[g] && d < best {
+					origins[i] = Origin{Galaxy: g, radius: d}
+					best = d
+				}
+			}
+		}
+	}
+
+	return origins, len(centers)
+}
+
+
+// ColorByOrigin colors the stars of every galaxy of a universe with its own color from OriginColors,
+// so the animation shows where the stars of each galaxy end up. Black holes keep their color.
+// Input:
+//   - u: pointer to the Universe, usually the initial one.
+// Output:
+//   - None (modifies the stars in place).
+func ColorByOrigin(u *Universe) {
+	origins, _ := TagOrigins(u)
+	for i, s := range u.Stars {
+		if origins[i].center {
+			continue
+		}
+		c := OriginColors[origins[i].Galaxy%len(OriginColors)]
+		s.Red, s.Green, s.Blue = c.R, c.G, c.B
+	}
+}
+
+
+// TailStars marks the stars of a universe that are not bound to any galaxy: for every black hole
+// (see DiskStars) the energy 1/2 v^2 - G M / d of a star is computed with its velocity v relative to the
+// black hole, its distance d, and the mass M of the black hole and the stars closer to it than d.
+// Stars with a positive energy for every black hole are in tidal tails or have escaped, the others belong
+// to a galaxy body (both galaxies before a merger, the remnant after it).
+// Without black holes the center of mass of all stars is used instead.
+// Input:
+//   - u: pointer to the Universe.
+// Output:
+//   - slice of booleans, true for the stars in tidal tails (or escaped), in the order of u.Stars.
+func TailStars(u *Universe) []bool {
+	disk := DiskStars(u.Stars)
+	light := make(map[*Star]bool)
+	for _, s := range disk {
+		light[s] = true
+	}
+
+	var centers []*Star
+	for _, s := range u.Stars {
+		if !light[s] {
+			centers = append(centers, s)
+		}
+	}
+	if len(centers) == 0 {
+		// a massless center at the center of mass moving with the bulk velocity
+		center := &Star{Position: CenterOfMass(u.Stars)}
+		totalMass := 0.0
+		for _, s := range u.Stars {
+			center.Velocity.X += s.Mass * s.Velocity.X
+			center.Velocity.Y += s.Mass * s.Velocity.Y
+			totalMass += s.Mass
+		}
+		if totalMass > 0 {
+			center.Velocity.X /= totalMass
+			center.Velocity.Y /= totalMass
+		}
+		centers = append(centers, center)
+	}
+
+	bound := make(map[*Star]bool)
+	for _, c := range centers {
+		order := make([]*Star, len(disk))
+		distances := make(map[*Star]float64, len(disk))
+		copy(order, disk)
+		for _, s := range disk {
+			_, _, distances[s] = Distance(s.Position, c.Position)
+		}
+		sort.Slice(order, func(a, b int) bool {
+			return distances[order[a]] < distances[order[b]]
+		})
+
+		enclosed := c.Mass
+		for _, s := range order {
+			d := distances[s]
+			dvx, dvy := s.Velocity.X-c.Velocity.X, s.Velocity.Y-c.Velocity.Y
+			if d > 0 && 0.5*(dvx*dvx+dvy*dvy) < G*enclosed/d {
+				bound[s] = true
+			}
+			enclosed += s.Mass
+		}
+	}
+
+	inTail := make([]bool, len(u.Stars))
+	for i, s := range u.Stars {
+		inTail[i] = light[s] && !bound[s]
+	}
+
+	return inTail
+}
+
+
+// PrintTails tags the stars of the first snapshot by galaxy of origin and prints, for every snapshot,
+// the fraction of each galaxy's stars that are in tidal tails, unbound from every galaxy (see TailStars).
+// For the last snapshot it also prints the tail fraction by initial radius, in numBins bins reaching to
+// the outermost star of each galaxy. Black holes are not counted. Snapshots whose stars do not match
+// the first one (stars were added or removed) are skipped.
+func PrintTails(universes []*Universe, generations []int, numBins int) {
+	fmt.Println("# tidal tails (stars not bound to any black hole)")
+	if len(universes) == 0 {
+		return
+	}
+
+	origins, numGalaxies := TagOrigins(universes[0])
+
+	fmt.Print("# generation")
+	for g := 0; g < numGalaxies; g++ {
+		fmt.Printf(" tail_fraction_%d", g)
+	}
+	fmt.Println()
+
+	var last []bool
+	for i, u := range universes {
+		if len(u.Stars) != len(origins) {
+			fmt.Printf("# generation %d skipped: %d stars instead of %d\n", generations[i], len(u.Stars), len(origins))
+			continue
+		}
+
+		inTail := TailStars(u)
+		total := make([]int, numGalaxies)
+		tail := make([]int, numGalaxies)
+		for k, o := range origins {
+			if o.center {
+				continue
+			}
+			total[o.Galaxy]++
+			if inTail[k] {
+				tail[o.Galaxy]++
+			}
+		}
+
+		fmt.Print(generations[i])
+		for g := range total {
+			fmt.Printf(" %.3f", float64(tail[g])/float64(max(total[g], 1)))
+		}
+		fmt.Println()
+		last = inTail
+	}
+
+	if last == nil {
+		return
+	}
+
+	// tail fraction by initial radius in the last usable snapshot
+	outer := make([]float64, numGalaxies)
+	for _, o := range origins {
+		outer[o.Galaxy] = math.Max(outer[o.Galaxy], o.radius)
+	}
+
+	fmt.Println("# galaxy radius_bin r_in r_out stars tail_fraction (last snapshot)")
+	for g := 0; g < numGalaxies; g++ {
+		total := make([]int, numBins)
+		tail := make([]int, numBins)
+		for k, o := range origins {
+			if o.center || o.Galaxy != g {
+				continue
+			}
+			bin := min(int(o.radius/outer[g]*float64(numBins)), numBins-1)
+			total[bin]++
+			if last[k] {
+				tail[bin]++
+			}
+		}
+		for b := range total {
+			rIn, rOut := outer[g]*float64(b)/float64(numBins), outer[g]*float64(b+1)/float64(numBins)
+			fmt.Printf("%d %d %e %e %d %.3f\n", g, b, rIn, rOut, total[b], float64(tail[b])/float64(max(total[b], 1)))
+		}
+	}
+}
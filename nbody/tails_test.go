@@ -0,0 +1,96 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the origin tagging in tails.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package nbody
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type TagOriginsTestCases struct {
+	id       string
+	universe *Universe
+	expected []string
+}
+
+
+// ReadTagOrigins reads test data for the TagOrigins function from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of TagOriginsTestCases structs containing the stars (with their galaxy numbers) and the expected
+// galaxy of each star.
+func ReadTagOrigins(fileName string) []TagOriginsTestCases {
+	file, err := os.Open(fileName)
+	Check(err)
+	defer file.Close()
+
+	var tests []TagOriginsTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 3 {
+			continue
+		}
+
+		u := &Universe{Width: 100}
+		for _, star := range strings.Split(parts[1], ",") {
+			fields := strings.Fields(star)
+			x, err := strconv.ParseFloat(fields[0], 64)
+			Check(err)
+			y, err := strconv.ParseFloat(fields[1], 64)
+			Check(err)
+			mass, err := strconv.ParseFloat(fields[2], 64)
+			Check(err)
+			s := &Star{Position: OrderedPair{x, y}, Mass: mass}
+			if len(fields) > 3 {
+				galaxy, err := strconv.Atoi(fields[3])
+				Check(err)
+				s.Galaxy = int32(galaxy)
+			}
+			u.Stars = append(u.Stars, s)
+		}
+
+		tests = append(tests, TagOriginsTestCases{
+			id:       strings.TrimSpace(parts[0]),
+			universe: u,
+			expected: strings.Fields(parts[2]),
+		})
+	}
+
+	return tests
+}
+
+
+// TestTagOrigins tests the TagOrigins function using data from a file.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestTagOrigins(t *testing.T) {
+	tests := ReadTagOrigins("../Tests/TagOrigins.txt")
+
+	for _, test := range tests {
+		origins, _ := TagOrigins(test.universe)
+
+		got := make([]string, len(origins))
+		for i, o := range origins {
+			got[i] = strconv.Itoa(o.Galaxy)
+			if o.center {
+				got[i] = "c"
+			}
+		}
+
+		if strings.Join(got, " ") != strings.Join(test.expected, " ") {
+			t.Errorf("TestTagOrigins(test %v) = %v, want %v", test.id, got, test.expected)
+		}
+	}
+}
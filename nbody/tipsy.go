@@ -0,0 +1,274 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Reading and writing universes as TIPSY files, the snapshot format of PKDGRAV and ChaNGa that pynbody
+// and other N-body analysis tools read (options "-snapshot-format tipsy" and "-initial file.tipsy").
+
+package nbody
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// A TIPSY file is a header followed by the gas, dark matter, and star particles, in this order:
+//
+//	time        float64  simulated time
+//	nbodies     int32    number of particles: nsph + ndark + nstar
+//	ndim        int32    3
+//	nsph        int32    number of gas particles
+//	ndark       int32    number of dark matter particles
+//	nstar       int32    number of star particles
+//	pad         int32    0 (absent in some files)
+//	gas         nsph times mass, x, y, z, vx, vy, vz, rho, temp, hsmooth, metals, phi as float32
+//	dark        ndark times mass, x, y, z, vx, vy, vz, eps, phi as float32
+//	stars       nstar times mass, x, y, z, vx, vy, vz, metals, tform, eps, phi as float32
+//
+// Files are written in big endian ("standard" TIPSY); the reader also takes little endian files, told apart by ndim.
+// Quantities are in the units of the simulation codes with G = 1: lengths in kpc and masses in solar masses, as
+// with dKpcUnit = 1 and dMsolUnit = 1 in a ChaNGa parameter file, so velocities are in units of tipsyVelocity and
+// times in units of tipsyTime.
+//
+// Every star of a universe is written as a star particle in the plane z = 0, with its position relative to the
+// center of the universe, its softening length as eps (see Star.Softening), and the time it was created as tform.
+// The radii, colors, accelerations, galaxies, and IDs of the stars and the size of the universe are not kept; the
+// stars read from a file are numbered in the order of the file.
+
+// tipsyLength, tipsyMass, tipsyVelocity, and tipsyTime are one unit of a TIPSY file in SI units.
+var (
+	tipsyLength   = LengthUnits["kpc"]
+	tipsyMass     = SolarMass
+	tipsyVelocity = math.Sqrt(G * tipsyMass / tipsyLength)
+	tipsyTime     = tipsyLength / tipsyVelocity
+)
+
+// tipsyMargin is the width of a universe read from a TIPSY file relative to the smallest square centered on the
+// origin of the file that holds every particle.
+const tipsyMargin = 1.2
+
+// tipsyStarRadius is the radius of the stars read from a TIPSY file, which has none: that of the sun, as for the
+// stars of a galaxy (see InitializeGalaxy).
+const tipsyStarRadius = 696340000
+
+// tipsyHeader is the header of a TIPSY file without its padding.
+type tipsyHeader struct {
+	Time    float64
+	NBodies int32
+	NDim    int32
+	NSph    int32
+	NDark   int32
+	NStar   int32
+}
+
+// tipsyStar is the record of a star particle.
+type tipsyStar struct {
+	Mass          float32
+	X, Y, Z       float32
+	VX, VY, VZ    float32
+	Metals, TForm float32
+	Eps, Phi      float32
+}
+
+// tipsyGasSize and tipsyDarkSize are the sizes of the records of gas and dark matter particles in bytes.
+const (
+	tipsyGasSize  = 12 * 4
+	tipsyDarkSize = 9 * 4
+)
+
+
+// WriteTipsy writes a universe to w as a TIPSY file.
+// Input:
+//   - w: destination of the file.
+//   - u: pointer to the Universe.
+// Output:
+//   - an error if writing failed.
+func WriteTipsy(w io.Writer, u *Universe) error {
+	header := tipsyHeader{
+		Time:    u.Time / tipsyTime,
+		NBodies: int32(len(u.Stars)),
+		NDim:    3,
+		NStar:   int32(len(u.Stars)),
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, header)
+	binary.Write(&buf, binary.BigEndian, int32(0))
+
+	center := u.Center()
+	for _, s := range u.Stars {
+		record := tipsyStar{
+			Mass:  float32(s.Mass / tipsyMass),
+			X:     float32((s.Position.X - center.X) / tipsyLength),
+			Y:     float32((s.Position.Y - center.Y) / tipsyLength),
+			VX:    float32(s.Velocity.X / tipsyVelocity),
+			VY:    float32(s.Velocity.Y / tipsyVelocity),
+			TForm: float32((u.Time - s.Age) / tipsyTime),
+			Eps:   float32(s.Softening / tipsyLength),
+		}
+		binary.Write(&buf, binary.BigEndian, record)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+
+// ReadTipsy reads a TIPSY file. Every particle becomes a white star in the plane of x and y; z and the velocity
+// along it are dropped. Dark matter and star particles keep their eps as softening length, and star particles the
+// time since tform as age. The universe is square and centered on the origin of the file, wide enough for every
+// particle (see tipsyMargin).
+// Input:
+//   - r: source of the file.
+// Output:
+//   - pointer to the Universe in SI units, or an error if the data is not a TIPSY file.
+func ReadTipsy(r io.Reader) (*Universe, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var header tipsyHeader
+	headerSize := binary.Size(header)
+	if len(data) < headerSize {
+		return nil, errors.New("not a TIPSY file (too short for a header)")
+	}
+	// the dimension tells the byte order
+	var order binary.ByteOrder = binary.BigEndian
+	binary.Read(bytes.NewReader(data), order, &header)
+	if header.NDim < 1 || header.NDim > 3 {
+		order = binary.LittleEndian
+		binary.Read(bytes.NewReader(data), order, &header)
+	}
+	if header.NDim < 1 || header.NDim > 3 {
+		return nil, errors.New("not a TIPSY file (bad number of dimensions)")
+	}
+	if header.NSph < 0 || header.NDark < 0 || header.NStar < 0 || int64(header.NSph)+int64(header.NDark)+int64(header.NStar) != int64(header.NBodies) {
+		return nil, fmt.Errorf("particles do not add up: %d gas, %d dark matter, and %d stars, but %d in total",
+			header.NSph, header.NDark, header.NStar, header.NBodies)
+	}
+
+	// the padding is told by the size of the file
+	starSize := binary.Size(tipsyStar{})
+	body := int64(header.NSph)*tipsyGasSize + int64(header.NDark)*tipsyDarkSize + int64(header.NStar)*int64(starSize)
+	switch int64(len(data)) {
+	case int64(headerSize) + 4 + body:
+		data = data[headerSize+4:]
+	case int64(headerSize) + body:
+		data = data[headerSize:]
+	default:
+		return nil, fmt.Errorf("expected %d bytes of particles after the header for %d particles, got %d",
+			body, header.NBodies, len(data)-headerSize)
+	}
+
+	time := header.Time * tipsyTime
+	u := &Universe{Time: time}
+	values := make([]float32, tipsyGasSize/4)
+	reader := bytes.NewReader(data)
+	for i := 0; i < int(header.NBodies); i++ {
+		var s *Star
+		switch {
+		case i < int(header.NSph):
+			binary.Read(reader, order, values)
+			s = tipsyParticle(values)
+		case i < int(header.NSph+header.NDark):
+			binary.Read(reader, order, values[:tipsyDarkSize/4])
+			s = tipsyParticle(values)
+			s.Softening = float64(values[7]) * tipsyLength
+		default:
+			var record tipsyStar
+			binary.Read(reader, order, &record)
+			s = tipsyParticle([]float32{record.Mass, record.X, record.Y, record.Z, record.VX, record.VY, record.VZ})
+			s.Softening = float64(record.Eps) * tipsyLength
+			if tform := float64(record.TForm) * tipsyTime; tform >= 0 && tform <= time {
+				s.Age = time - tform
+			}
+		}
+		if !(s.Mass >= 0 && s.Softening >= 0) || math.IsInf(s.Mass, 0) || math.IsInf(s.Softening, 0) {
+			return nil, fmt.Errorf("particle %d has mass %v and eps %v", i, s.Mass, s.Softening)
+		}
+		u.Stars = append(u.Stars, s)
+	}
+	u.AssignStarIDs()
+
+	if err := CheckFinite(u, 0); err != nil {
+		return nil, err
+	}
+
+	// center the particles in the universe
+	extent := 0.0
+	for _, s := range u.Stars {
+		extent = math.Max(extent, math.Max(math.Abs(s.Position.X), math.Abs(s.Position.Y)))
+	}
+	if extent == 0 {
+		extent = tipsyLength
+	}
+	u.Width = 2 * tipsyMargin * extent
+	for _, s := range u.Stars {
+		s.Position.X += u.Width / 2
+		s.Position.Y += u.Width / 2
+	}
+	return u, nil
+}
+
+
+// tipsyParticle returns the star of a particle from its mass, position, and velocity in the units of a TIPSY file.
+func tipsyParticle(values []float32) *Star {
+	return NewStar(OrderedPair{X: float64(values[1]) * tipsyLength, Y: float64(values[2]) * tipsyLength},
+		OrderedPair{X: float64(values[4]) * tipsyVelocity, Y: float64(values[5]) * tipsyVelocity},
+		float64(values[0])*tipsyMass, tipsyStarRadius)
+}
+
+
+// WriteTipsyFile saves a universe to a TIPSY file.
+// Input:
+//   - u: pointer to the Universe.
+//   - fileName: path of the file to create.
+// Output:
+//   - an error if the file cannot be written.
+func WriteTipsyFile(u *Universe, fileName string) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	err = WriteTipsy(file, u)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+
+// LoadTipsy reads a TIPSY file, e.g. as the initial universe of a run.
+// Input:
+//   - fileName: path of the file.
+// Output:
+//   - pointer to the Universe, or an error naming the file if it is not a valid TIPSY file.
+func LoadTipsy(fileName string) (*Universe, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	u, err := ReadTipsy(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	return u, nil
+}
+
+
+// tipsyGeneration returns the generation of a TIPSY snapshot from its file name (see SnapshotFileName), since the
+// file only keeps the time, or 0 for another name.
+func tipsyGeneration(fileName string) int {
+	var generation int
+	if _, err := fmt.Sscanf(filepath.Base(fileName), "snapshot_%d.tipsy", &generation); err != nil {
+		return 0
+	}
+	return generation
+}
@@ -0,0 +1,219 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for reading and writing TIPSY files in tipsy.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package nbody
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type ReadTipsyTestCases struct {
+	id         string
+	order      binary.ByteOrder
+	padding    bool
+	counts     [3]int // gas, dark matter, and star particles
+	nBodies    int
+	extraBytes int
+	isError    bool
+	numStars   int
+	width      float64 // in kpc
+}
+
+
+// ReadReadTipsy reads test data for ReadTipsy from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of ReadTipsyTestCases structs containing the files to build and the expected universes.
+func ReadReadTipsy(fileName string) []ReadTipsyTestCases {
+	file, err := os.Open(fileName)
+	Check(err)
+	defer file.Close()
+
+	var tests []ReadTipsyTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 9 {
+			continue
+		}
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+
+		test := ReadTipsyTestCases{id: parts[0], order: binary.BigEndian, padding: parts[2] == "yes"}
+		if parts[1] == "little" {
+			test.order = binary.LittleEndian
+		}
+		for i := range test.counts {
+			test.counts[i], err = strconv.Atoi(parts[3+i])
+			Check(err)
+		}
+		test.nBodies, err = strconv.Atoi(parts[6])
+		Check(err)
+		test.extraBytes, err = strconv.Atoi(parts[7])
+		Check(err)
+
+		if parts[8] == "error" {
+			test.isError = true
+		} else {
+			fields := strings.Fields(parts[8])
+			if len(fields) != 2 {
+				continue
+			}
+			test.numStars, err = strconv.Atoi(fields[0])
+			Check(err)
+			test.width, err = strconv.ParseFloat(fields[1], 64)
+			Check(err)
+		}
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// BuildTipsy returns the TIPSY file of a test case of ReadTipsy.
+func BuildTipsy(test ReadTipsyTestCases) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, test.order, tipsyHeader{
+		Time: 1, NBodies: int32(test.nBodies), NDim: 3,
+		NSph: int32(test.counts[0]), NDark: int32(test.counts[1]), NStar: int32(test.counts[2]),
+	})
+	if test.padding {
+		binary.Write(&buf, test.order, int32(0))
+	}
+
+	k := 0
+	for kind, count := range test.counts {
+		for i := 0; i < count; i++ {
+			p := float32(k + 1)
+			values := []float32{p, p, -p / 2, 5, 1, 2, 3}
+			switch kind {
+			case 0:
+				values = append(values, 1, 1e4, 0.5, 0, 0)
+			case 1:
+				values = append(values, 0.5, 0)
+			case 2:
+				values = append(values, 0, 0, 0.5, 0)
+			}
+			binary.Write(&buf, test.order, values)
+			k++
+		}
+	}
+
+	data := buf.Bytes()
+	if test.extraBytes < 0 {
+		return data[:len(data)+test.extraBytes]
+	}
+	return append(data, make([]byte, test.extraBytes)...)
+}
+
+
+// TestReadTipsy tests ReadTipsy on files of every kind of particle, in both byte orders, with and without padding.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestReadTipsy(t *testing.T) {
+	tests := ReadReadTipsy("../Tests/ReadTipsy.txt")
+
+	for _, test := range tests {
+		u, err := ReadTipsy(bytes.NewReader(BuildTipsy(test)))
+		if test.isError {
+			if err == nil {
+				t.Errorf("TestReadTipsy(test %v) returned no error, want one", test.id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestReadTipsy(test %v) failed: %v", test.id, err)
+			continue
+		}
+
+		if len(u.Stars) != test.numStars || math.Abs(u.Width/tipsyLength-test.width) > 1e-9*test.width || u.Height != 0 || u.Time != tipsyTime {
+			t.Errorf("TestReadTipsy(test %v) = %d stars, width %v kpc, height %v, time %v, want %d stars, width %v kpc, height 0, time %v",
+				test.id, len(u.Stars), u.Width/tipsyLength, u.Height, u.Time, test.numStars, test.width, tipsyTime)
+			continue
+		}
+		for k, s := range u.Stars {
+			p := float64(k + 1)
+			softening, age := 0.5*tipsyLength, 0.0
+			if k < test.counts[0] {
+				softening = 0
+			} else if k >= test.counts[0]+test.counts[1] {
+				age = tipsyTime
+			}
+			want := []float64{p * tipsyMass, (test.width/2 + p) * tipsyLength, (test.width/2 - p/2) * tipsyLength,
+				tipsyVelocity, 2 * tipsyVelocity, softening, age}
+			got := []float64{s.Mass, s.Position.X, s.Position.Y, s.Velocity.X, s.Velocity.Y, s.Softening, s.Age}
+			for i := range want {
+				if math.Abs(got[i]-want[i]) > 1e-9*math.Abs(want[i]) {
+					t.Errorf("TestReadTipsy(test %v): particle %d has mass, x, y, vx, vy, softening, and age %v, want %v", test.id, k, got, want)
+					break
+				}
+			}
+		}
+	}
+}
+
+
+// TestTipsySnapshots checks that universes written as TIPSY snapshots are read back in single precision, with their
+// generation, and centered in a square universe.
+// The universes of Tests/TreeBuild.txt are used, with velocities, softening lengths, and ages added.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if a loaded universe differs.
+func TestTipsySnapshots(t *testing.T) {
+	tests := ReadTreeBuild("../Tests/TreeBuild.txt")
+
+	for n, test := range tests {
+		u := test.Universe
+		u.Time = 1.5e17
+		for i, s := range u.Stars {
+			s.Velocity = OrderedPair{X: s.Position.Y / 3, Y: -s.Position.X / 7}
+			s.Softening = 0.1 / float64(i+1)
+			s.Age = 1e15 * float64(i)
+		}
+		generation := 1000 * n
+
+		dir := t.TempDir()
+		Check(WriteSnapshot(u, generation, SnapshotFileName(dir, generation, "tipsy")))
+		universes, generations, err := ReadSnapshotDir(dir)
+		if err != nil {
+			t.Errorf("TestTipsySnapshots(test %v): ReadSnapshotDir failed: %v", test.ID, err)
+			continue
+		}
+		loaded := universes[0]
+		if len(universes) != 1 || generations[0] != generation || len(loaded.Stars) != len(u.Stars) || loaded.Height != 0 {
+			t.Errorf("TestTipsySnapshots(test %v) = generations %v with %d stars, want [%d] with %d stars",
+				test.ID, generations, len(loaded.Stars), generation, len(u.Stars))
+			continue
+		}
+
+		center, loadedCenter := u.Center(), loaded.Center()
+		for i, s := range u.Stars {
+			l := loaded.Stars[i]
+			want := []float64{s.Mass, s.Position.X - center.X, s.Position.Y - center.Y, s.Velocity.X, s.Velocity.Y, s.Softening, s.Age, u.Time}
+			got := []float64{l.Mass, l.Position.X - loadedCenter.X, l.Position.Y - loadedCenter.Y, l.Velocity.X, l.Velocity.Y, l.Softening, l.Age, loaded.Time}
+			for j := range want {
+				// single precision, and the age and the time relative to the time of the universe
+				if math.Abs(got[j]-want[j]) > 1e-6*math.Max(math.Abs(want[j]), u.Time*float64(j/6)) {
+					t.Errorf("TestTipsySnapshots(test %v): star %d has mass, x, y, vx, vy, softening, age, and time %v, want %v", test.ID, i, got, want)
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Massless tracers (option "-tracers"), which follow the flow of a simulation without pulling on it.
+
+package nbody
+
+import "fmt"
+
+// A tracer is a star of zero mass. It is pulled by the other stars like any star, but it pulls on none: tracers are
+// not inserted in the quadtree, so they add nothing to the mass and the center of mass of its nodes and do not
+// deepen it, and they never merge. Many tracers therefore cost one force walk each and nothing more, which makes
+// them a cheap way to draw tidal tails and flow fields. Initial universes may contain tracers (mass 0), and
+// -tracers adds them to the galaxies of the "galaxy" and "collision" scenarios.
+
+// NumTracers is the number of tracers added to every galaxy of the "galaxy" and "collision" scenarios,
+// set from the -tracers option.
+var NumTracers = 0
+
+// tracerRed, tracerGreen, and tracerBlue are the color of the tracers of GalaxyTracers.
+const (
+	tracerRed   = 120
+	tracerGreen = 200
+	tracerBlue  = 255
+)
+
+
+// IsTracer reports whether a star is a massless tracer.
+func IsTracer(s *Star) bool {
+	return s.Mass == 0
+}
+
+
+// CheckTracers returns an error if n is not a valid number of tracers.
+func CheckTracers(n int) error {
+	if n < 0 {
+		return fmt.Errorf("number of tracers must not be negative, got %d", n)
+	}
+	return nil
+}
+
+
+// GalaxyTracers returns tracers spread and spinning like the stars of InitializeGalaxy around the black hole of a
+// galaxy, and moving with it, so they trace the stars of the galaxy.
+// Input:
+//   - n: number of tracers.
+//   - r: radius of the galaxy.
+//   - blackhole: pointer to the black hole of the galaxy, the last star of InitializeGalaxy.
+// Output:
+//   - the tracers as a Galaxy, without a black hole.
+func GalaxyTracers(n int, r float64, blackhole *Star) Galaxy {
+	if n == 0 {
+		return nil
+	}
+	tracers := InitializeGalaxy(n, r, blackhole.Position.X, blackhole.Position.Y)
+	tracers = tracers[:n]
+	for _, s := range tracers {
+		s.Mass = 0
+		s.Velocity.X += blackhole.Velocity.X
+		s.Velocity.Y += blackhole.Velocity.Y
+		s.Red, s.Green, s.Blue = tracerRed, tracerGreen, tracerBlue
+	}
+	return tracers
+}
+
+
+// UnitProbe returns a copy of a tracer with unit mass: the force on it is the acceleration of the tracer.
+func UnitProbe(s *Star) *Star {
+	probe := *s
+	probe.Mass = 1
+	return &probe
+}
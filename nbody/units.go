@@ -0,0 +1,103 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Unit declarations for input files, converted to SI units when loading.
+
+package nbody
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LengthUnits, velocityUnits, and MassUnits give the SI value of one unit of each name
+// that can be used in a "units:" line of an input file.
+var LengthUnits = map[string]float64{
+	"m":    1.0,
+	"km":   1.0e3,
+	"Rsun": 6.9634e8,
+	"AU":   1.495978707e11,
+	"ly":   9.4607304725808e15,
+	"pc":   3.0856775814913673e16,
+	"kpc":  3.0856775814913673e19,
+}
+
+var velocityUnits = map[string]float64{
+	"m/s":    1.0,
+	"km/s":   1.0e3,
+	"AU/day": 1.495978707e11 / 86400.0,
+	"AU/yr":  1.495978707e11 / 3.15576e7,
+}
+
+var MassUnits = map[string]float64{
+	"kg":     1.0,
+	"Mearth": 5.9722e24,
+	"Mjup":   1.89813e27,
+	"Msun":   SolarMass,
+}
+
+// Units holds the factors converting the quantities of an input file into SI units.
+// Positions (and the universe width) use the position factor.
+type Units struct {
+	Position float64
+	Velocity float64
+	Mass     float64
+	Radius   float64
+}
+
+
+// SIUnits returns the units of a file without a "units:" line, i.e. everything already in SI.
+func SIUnits() Units {
+	return Units{Position: 1.0, Velocity: 1.0, Mass: 1.0, Radius: 1.0}
+}
+
+
+// IsUnitsLine reports whether a line of an input file is a unit declaration.
+func IsUnitsLine(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "units:")
+}
+
+
+// ParseUnitsLine parses a unit declaration such as
+//
+//	units: position=AU velocity=km/s mass=Msun radius=km
+//
+// Quantities that are not listed stay in SI units.
+// Input:
+//   - line: the declaration, starting with "units:".
+// Output:
+//   - Units with the conversion factors, and an error for unknown quantities or unit names.
+func ParseUnitsLine(line string) (Units, error) {
+	units := SIUnits()
+	line = strings.TrimPrefix(strings.TrimSpace(line), "units:")
+
+	for _, field := range strings.Fields(line) {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			return units, fmt.Errorf("unit declaration %q should look like quantity=unit", field)
+		}
+		quantity, name := parts[0], parts[1]
+
+		var table map[string]float64
+		var target *float64
+		switch quantity {
+		case "position":
+			table, target = LengthUnits, &units.Position
+		case "radius":
+			table, target = LengthUnits, &units.Radius
+		case "velocity":
+			table, target = velocityUnits, &units.Velocity
+		case "mass":
+			table, target = MassUnits, &units.Mass
+		default:
+			return units, fmt.Errorf("unknown quantity %q in unit declaration (use position, velocity, mass, or radius)", quantity)
+		}
+
+		factor, ok := table[name]
+		if !ok {
+			return units, fmt.Errorf("unknown unit %q for %s", name, quantity)
+		}
+		*target = factor
+	}
+
+	return units, nil
+}
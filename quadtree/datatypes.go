@@ -0,0 +1,40 @@
+// Author: Yu-Lun Chen
+// Date: 2025-10-24
+// Description: Definition of the quadtree datatypes using in the BarnesHut project.
+
+package quadtree
+
+import (
+	"BarnesHut/nbody"
+	"sync"
+)
+
+// QuadTree simply contains a pointer to the root.
+// Another way of doing this would be type QuadTree *Node
+type QuadTree struct {
+	root    *Node
+	Order   []int       // indices of the stars in the order they were inserted with -morton (see MortonOrder), or nil
+	Linear  *LinearTree // the tree of -tree-build linear, or nil; root is then converted from it by Root
+	convert sync.Once
+}
+
+// Node object contains a slice of children (this could just as easily be an array of length 4).
+// A node refers to a star. Sometimes, the star will be a "dummy" star, sometimes it is a star in the
+// universe, and sometimes it is nil. Every internal node points to a dummy star.
+// A leaf holding several stars (a bucket, see InsertStar) also points to a dummy star at their center of mass,
+// and lists the stars themselves in bucket.
+type Node struct {
+	Children []*Node
+	Star     *nbody.Star
+	Bucket   []*nbody.Star // stars of a leaf holding more than one star, in the order of the universe (see LeafStars)
+	Sector   Quadrant
+	bmax     float64 // largest distance of the node's stars from its center of mass (see ComputeMoments)
+	b2       float64 // second moment of the node's mass around its center of mass (see ComputeMoments)
+}
+
+// Quadrant is an object representing a sub-square within a larger universe.
+type Quadrant struct {
+	X     float64 //bottom left corner x coordinate
+	Y     float64 //bottom left corner y coordinate
+	Width float64
+}
@@ -0,0 +1,117 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Debugging helpers for inspecting the quadtree during a simulation.
+
+package quadtree
+
+import (
+	"BarnesHut/nbody"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseGenerationList parses a comma-separated list of generations such as "0,10,250".
+// Input:
+//   - list: the comma-separated string (an empty string gives an empty set).
+// Output:
+//   - set of generations and an error if an entry is not a non-negative integer.
+func ParseGenerationList(list string) (map[int]bool, error) {
+	generations := make(map[int]bool)
+
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		gen, err := strconv.Atoi(field)
+		if err != nil || gen < 0 {
+			return nil, fmt.Errorf("invalid generation %q in list %q", field, list)
+		}
+		generations[gen] = true
+	}
+
+	return generations, nil
+}
+
+
+// PrintTree writes the quadtree depth-indented, one node per line, with its sector, mass, and center of mass.
+// Input:
+//   - w: destination of the dump.
+//   - tree: pointer to the QuadTree to print.
+//   - generation: generation the tree was built from, printed as a header.
+// Output:
+//   - None (the dump is written to w).
+func PrintTree(w io.Writer, tree *QuadTree, generation int) {
+	fmt.Fprintf(w, "=== quadtree of generation %d ===\n", generation)
+	PrintNode(w, tree.Root(), 0, "root")
+}
+
+
+// PrintNode recursively writes a node and its children, indenting two spaces per depth level.
+// Leaves holding a real star are marked "star", leaves holding several "bucket", internal nodes "internal",
+// and empty leaves "empty".
+func PrintNode(w io.Writer, node *Node, depth int, label string) {
+	if node == nil {
+		return
+	}
+
+	indent := strings.Repeat("  ", depth)
+	q := node.Sector
+	kind := "empty"
+	if !IsLeaf(node) {
+		kind = "internal"
+	} else if node.Bucket != nil {
+		kind = fmt.Sprintf("bucket of %d stars", len(node.Bucket))
+	} else if node.Star != nil {
+		kind = "star"
+	}
+
+	fmt.Fprintf(w, "%s%s [%s] sector (x %.4e, y %.4e, width %.4e)", indent, label, kind, q.X, q.Y, q.Width)
+	if node.Star != nil {
+		fmt.Fprintf(w, " mass %.4e com (%.4e, %.4e)", node.Star.Mass, node.Star.Position.X, node.Star.Position.Y)
+	}
+	fmt.Fprintln(w)
+
+	labels := []string{"NW", "NE", "SW", "SE"}
+	for i, child := range node.Children {
+		PrintNode(w, child, depth+1, labels[i])
+	}
+}
+
+
+// TraceNetForce writes every leaf and approximated node visited while computing the net force on one star,
+// with the partial force each contributed, followed by the total force.
+// Input:
+//   - w: destination of the trace.
+//   - tree: pointer to the QuadTree of the generation.
+//   - s: pointer to the traced Star.
+//   - index: index of s in the universe, printed in the header.
+//   - generation: generation of the tree, printed in the header.
+//   - walk: the WalkOptions of the run (theta, softening, and opening criterion).
+// Output:
+//   - OrderedPair representing the net force on s (identical to CalculateNetForce).
+func TraceNetForce(w io.Writer, tree *QuadTree, s *nbody.Star, index, generation int, walk WalkOptions) nbody.OrderedPair {
+	fmt.Fprintf(w, "=== force trace of star %d at (%.4e, %.4e) in generation %d, theta %v ===\n",
+		index, s.Position.X, s.Position.Y, generation, walk.Theta)
+
+	force := WalkNetForce(tree.Root(), s, walk, w, 0)
+
+	fmt.Fprintf(w, "total force (%.4e, %.4e)\n", force.X, force.Y)
+	return force
+}
+
+
+// CountNodes returns the number of nodes in a subtree, including node itself.
+func CountNodes(node *Node) int {
+	if node == nil {
+		return 0
+	}
+	count := 1
+	for _, child := range node.Children {
+		count += CountNodes(child)
+	}
+	return count
+}
@@ -0,0 +1,437 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: A flattened quadtree stored in one slice of nodes, built concurrently without locks.
+
+package quadtree
+
+import (
+	"BarnesHut/nbody"
+	"sort"
+	"sync/atomic"
+)
+
+// A FlatTree stores all nodes of a quadtree in one slice. The four children of an internal node
+// are stored next to each other, so a node only needs the index of its first child.
+// The child field of a FlatNode encodes the state of the node:
+//   - emptyLeaf: a leaf without a star.
+//   - a value <= starLeaf: a leaf holding the star with index starLeaf - child.
+//   - a value > 0: an internal node whose children start at that index.
+// The root is always nodes[0], so no child index is 0.
+// A leaf may hold several stars (a bucket, see InsertStar): its star is the first of a list continued by next,
+// which holds the index of the next star in the same leaf, or noStar.
+type FlatTree struct {
+	nodes []FlatNode
+	stars []*nbody.Star
+	next  []int32
+}
+
+type FlatNode struct {
+	sector Quadrant
+	child  int32
+	center nbody.OrderedPair
+	mass   float64
+}
+
+const (
+	emptyLeaf int32 = -1
+	starLeaf  int32 = -2
+	noStar    int32 = -1
+)
+
+
+// GenerateFlatTree builds the quadtree of a universe as a FlatTree on several goroutines.
+// Stars are inserted concurrently. Nodes are taken from a preallocated slice by bumping an
+// atomic counter, and a node changes state only by compare-and-swap, so no goroutine ever waits
+// for another:
+//   - an empty leaf takes a star with a CAS from emptyLeaf to the star.
+//   - a leaf with a star is split by allocating four children, moving the old star into one of them,
+//     and publishing the children with a CAS. A goroutine that loses the CAS retries from the same
+//     node; its unused children stay unreachable in the slice.
+//   - a leaf with a star at MaxTreeDepth takes the star at the head of its list with a CAS.
+//
+// If the slice runs out of nodes the build is retried with twice the capacity.
+// Leaves are split as soon as they hold two stars; with leafSize > 1 the subtrees holding at most leafSize stars
+// are collapsed into leaves afterwards, which gives the tree InsertStar builds.
+// Centers of mass are computed afterwards, one goroutine per level-2 subtree.
+// Input:
+//   - currentUniverse: pointer to the Universe to build the tree for.
+//   - workers: number of goroutines.
+//   - leafSize: largest number of stars of a leaf.
+// Output:
+//   - pointer to the constructed FlatTree.
+func GenerateFlatTree(currentUniverse *nbody.Universe, workers int, leafSize int) *FlatTree {
+	capacity := 8*len(currentUniverse.Stars) + 64
+
+	for {
+		tree, ok := buildFlatTree(currentUniverse, workers, capacity)
+		if ok {
+			if leafSize > 1 {
+				tree.Collapse(0, leafSize)
+			}
+			tree.ComputeCenterAndMass(0, partitionLevels, workers)
+			return tree
+		}
+		capacity *= 2
+	}
+}
+
+
+// buildFlatTree inserts every star of the universe into a FlatTree with room for capacity nodes.
+// It returns false if the nodes ran out.
+func buildFlatTree(currentUniverse *nbody.Universe, workers int, capacity int) (*FlatTree, bool) {
+	tree := &FlatTree{
+		nodes: make([]FlatNode, capacity),
+		stars: currentUniverse.Stars,
+		next:  NoStars(len(currentUniverse.Stars)),
+	}
+	tree.nodes[0] = FlatNode{sector: Quadrant{X: 0, Y: 0, Width: currentUniverse.RootWidth()}, child: emptyLeaf}
+	width, height := currentUniverse.Bounds()
+
+	var next int64 = 1
+	var full int32
+
+	// split the stars into chunks so that goroutines do not hand over single stars
+	numChunks := 8 * workers
+	if numChunks < 1 {
+		numChunks = 1
+	}
+	chunk := (len(tree.stars) + numChunks - 1) / numChunks
+
+	nbody.ParallelFor(numChunks, workers, func(c int) {
+		for i := c * chunk; i < (c+1)*chunk && i < len(tree.stars); i++ {
+			if atomic.LoadInt32(&full) != 0 {
+				return
+			}
+			s := tree.stars[i]
+			if nbody.IsInsideUniverse(s, width, height) && !nbody.IsTracer(s) && !tree.insert(int32(i), &next) {
+				atomic.StoreInt32(&full, 1)
+				return
+			}
+		}
+	})
+
+	return tree, full == 0
+}
+
+
+// insert adds the star with index i to the tree, allocating children from next.
+// It returns false if there was no room for new nodes.
+func (tree *FlatTree) insert(i int32, next *int64) bool {
+	s := tree.stars[i]
+	node := int32(0)
+	depth := 0
+
+	for {
+		state := atomic.LoadInt32(&tree.nodes[node].child)
+
+		// internal node: go down to the child containing the star
+		if state > 0 {
+			node = state + int32(FindQuadrant(tree.nodes[node].sector, s))
+			depth++
+			continue
+		}
+
+		// empty leaf: take it
+		if state == emptyLeaf {
+			if atomic.CompareAndSwapInt32(&tree.nodes[node].child, emptyLeaf, starLeaf-i) {
+				return true
+			}
+			continue
+		}
+
+		// leaf with a star at the maximum depth: put the star in front of its list
+		if depth >= MaxTreeDepth {
+			atomic.StoreInt32(&tree.next[i], starLeaf-state)
+			if atomic.CompareAndSwapInt32(&tree.nodes[node].child, state, starLeaf-i) {
+				return true
+			}
+			continue
+		}
+
+		// leaf with a star: split it and try again from the same node
+		first := atomic.AddInt64(next, 4) - 4
+		if first+4 > int64(len(tree.nodes)) {
+			return false
+		}
+
+		// the children are not reachable before the CAS, so they can be filled in without atomics
+		sector := tree.nodes[node].sector
+		half := sector.Width / 2.0
+		children := tree.nodes[first : first+4]
+		children[0] = FlatNode{sector: Quadrant{X: sector.X, Y: sector.Y + half, Width: half}, child: emptyLeaf}
+		children[1] = FlatNode{sector: Quadrant{X: sector.X + half, Y: sector.Y + half, Width: half}, child: emptyLeaf}
+		children[2] = FlatNode{sector: Quadrant{X: sector.X, Y: sector.Y, Width: half}, child: emptyLeaf}
+		children[3] = FlatNode{sector: Quadrant{X: sector.X + half, Y: sector.Y, Width: half}, child: emptyLeaf}
+
+		old := starLeaf - state
+		children[FindQuadrant(sector, tree.stars[old])].child = state
+
+		atomic.CompareAndSwapInt32(&tree.nodes[node].child, state, int32(first))
+	}
+}
+
+
+// ComputeCenterAndMass computes the total mass and center of mass of a node and all nodes below it.
+// The subtrees of the first levels below the node are handled on separate goroutines.
+// Input:
+//   - node: index of the node.
+//   - parallelLevels: number of levels below node whose subtrees run in parallel.
+//   - workers: number of goroutines.
+// Output:
+//   - None (modifies the nodes in place).
+func (tree *FlatTree) ComputeCenterAndMass(node int32, parallelLevels int, workers int) {
+	n := &tree.nodes[node]
+
+	if n.child == emptyLeaf {
+		return
+	}
+	if n.child <= starLeaf {
+		stars := tree.LeafStars(node)
+		n.center = stars[0].Position
+		n.mass = stars[0].Mass
+		// a bucket pulls like one body at the center of mass of its stars, as in the pointer based tree
+		if len(stars) > 1 {
+			n.center = nbody.CenterOfMass(stars)
+			n.mass = 0
+			for _, s := range stars {
+				n.mass += s.Mass
+			}
+		}
+		return
+	}
+
+	if parallelLevels > 0 {
+		nbody.ParallelFor(4, workers, func(i int) {
+			tree.ComputeCenterAndMass(n.child+int32(i), parallelLevels-1, workers)
+		})
+	} else {
+		for i := int32(0); i < 4; i++ {
+			tree.ComputeCenterAndMass(n.child+i, 0, workers)
+		}
+	}
+
+	totalMass := 0.0
+	xCm, yCm := 0.0, 0.0
+	for i := int32(0); i < 4; i++ {
+		child := &tree.nodes[n.child+i]
+		if child.child == emptyLeaf {
+			continue
+		}
+		totalMass += child.mass
+		xCm += child.mass * child.center.X
+		yCm += child.mass * child.center.Y
+	}
+
+	n.mass = totalMass
+	if totalMass > 0 {
+		n.center = nbody.OrderedPair{X: xCm / totalMass, Y: yCm / totalMass}
+	}
+}
+
+
+// Collapse turns every subtree below a node that holds at most leafSize stars into one leaf.
+// Input:
+//   - node: index of the node.
+//   - leafSize: largest number of stars of a leaf.
+// Output:
+//   - the number of stars below node.
+func (tree *FlatTree) Collapse(node int32, leafSize int) int {
+	n := &tree.nodes[node]
+	switch {
+	case n.child == emptyLeaf:
+		return 0
+	case n.child <= starLeaf:
+		return len(tree.LeafIndices(node))
+	}
+
+	count := 0
+	for i := int32(0); i < 4; i++ {
+		count += tree.Collapse(n.child+i, leafSize)
+	}
+	if count > leafSize {
+		return count
+	}
+
+	// all children are leaves now; their blocks stay unreachable in the slice
+	var indices []int32
+	for i := int32(0); i < 4; i++ {
+		if tree.nodes[n.child+i].child != emptyLeaf {
+			indices = append(indices, tree.LeafIndices(n.child+i)...)
+		}
+	}
+	if len(indices) == 0 {
+		n.child = emptyLeaf
+		return 0
+	}
+	sort.Slice(indices, func(a, b int) bool { return indices[a] < indices[b] })
+	tree.LinkStars(node, indices)
+	return count
+}
+
+
+// LeafIndices returns the indices of the stars of a leaf in increasing order.
+func (tree *FlatTree) LeafIndices(node int32) []int32 {
+	var indices []int32
+	for i := starLeaf - tree.nodes[node].child; i != noStar; i = tree.next[i] {
+		indices = append(indices, i)
+	}
+	sort.Slice(indices, func(a, b int) bool { return indices[a] < indices[b] })
+	return indices
+}
+
+
+// LeafStars returns the stars of a leaf in the order of the universe, like the bucket of a Node.
+func (tree *FlatTree) LeafStars(node int32) []*nbody.Star {
+	indices := tree.LeafIndices(node)
+	stars := make([]*nbody.Star, len(indices))
+	for k, i := range indices {
+		stars[k] = tree.stars[i]
+	}
+	return stars
+}
+
+
+// NoStars returns the next links of n stars that are not in any list.
+func NoStars(n int) []int32 {
+	next := make([]int32, n)
+	for i := range next {
+		next[i] = noStar
+	}
+	return next
+}
+
+
+// QuadTree converts a FlatTree into the pointer based QuadTree used by the force calculation.
+// Leaves point to the original stars and internal nodes get a dummy star at their center of mass,
+// exactly as GenerateQuadTree builds them.
+// Output:
+//   - pointer to the equivalent QuadTree.
+func (tree *FlatTree) QuadTree() *QuadTree {
+	return &QuadTree{root: tree.toNode(0)}
+}
+
+
+// toNode converts the subtree below a flat node into Nodes.
+func (tree *FlatTree) toNode(node int32) *Node {
+	n := &tree.nodes[node]
+	result := &Node{Sector: n.sector}
+
+	switch {
+	case n.child == emptyLeaf:
+	case n.child <= starLeaf:
+		result.Star = tree.stars[starLeaf-n.child]
+		if tree.next[starLeaf-n.child] != noStar {
+			result.Bucket = tree.LeafStars(node)
+			result.Star = &nbody.Star{Position: n.center, Mass: n.mass}
+		}
+	default:
+		result.Children = make([]*Node, 4)
+		for i := int32(0); i < 4; i++ {
+			result.Children[i] = tree.toNode(n.child + i)
+		}
+		if n.mass > 0 {
+			result.Star = &nbody.Star{Position: n.center, Mass: n.mass}
+		}
+	}
+
+	return result
+}
+
+
+// Compact returns a copy of the tree without the unreachable nodes left by lost CAS races,
+// with every block of children stored right after the subtree of its parent's earlier siblings
+// and the stars of every bucket listed in increasing order.
+// Output:
+//   - pointer to the compact FlatTree (sharing the stars of tree).
+func (tree *FlatTree) Compact() *FlatTree {
+	compact := &FlatTree{
+		nodes: []FlatNode{tree.nodes[0]},
+		stars: tree.stars,
+		next:  NoStars(len(tree.stars)),
+	}
+	if tree.nodes[0].child <= starLeaf {
+		compact.LinkStars(0, tree.LeafIndices(0))
+	}
+	compact.copyChildren(tree, 0, 0)
+	return compact
+}
+
+
+// copyChildren appends the children of tree.nodes[from] to compact, as the children of compact.nodes[to],
+// and continues with their subtrees.
+func (compact *FlatTree) copyChildren(tree *FlatTree, from, to int32) {
+	first := tree.nodes[from].child
+	if first <= 0 {
+		return
+	}
+
+	block := int32(len(compact.nodes))
+	compact.nodes = append(compact.nodes, tree.nodes[first:first+4]...)
+	compact.nodes[to].child = block
+
+	for i := int32(0); i < 4; i++ {
+		if tree.nodes[first+i].child <= starLeaf {
+			compact.LinkStars(block+i, tree.LeafIndices(first+i))
+		}
+		compact.copyChildren(tree, first+i, block+i)
+	}
+}
+
+
+// LinkStars makes the stars with the given indices, in this order, the stars of a leaf.
+func (flat *FlatTree) LinkStars(node int32, indices []int32) {
+	flat.nodes[node].child = starLeaf - indices[0]
+	for k := 1; k < len(indices); k++ {
+		flat.next[indices[k-1]] = indices[k]
+	}
+	flat.next[indices[len(indices)-1]] = noStar
+}
+
+
+// FlattenQuadTree converts a pointer based QuadTree into a compact FlatTree.
+// Input:
+//   - tree: pointer to the QuadTree, built from u.
+//   - u: the Universe whose stars are referenced by the leaves of tree.
+// Output:
+//   - pointer to the equivalent FlatTree.
+func FlattenQuadTree(tree *QuadTree, u *nbody.Universe) *FlatTree {
+	index := make(map[*nbody.Star]int32, len(u.Stars))
+	for i, s := range u.Stars {
+		index[s] = int32(i)
+	}
+
+	flat := &FlatTree{stars: u.Stars, next: NoStars(len(u.Stars))}
+	flat.nodes = append(flat.nodes, FlatNode{})
+	flat.flattenNode(tree.root, 0, index)
+	return flat
+}
+
+
+// flattenNode fills in flat.nodes[at] from node and appends the nodes of its subtree.
+func (flat *FlatTree) flattenNode(node *Node, at int32, index map[*nbody.Star]int32) {
+	flat.nodes[at].sector = node.Sector
+
+	switch {
+	case IsLeaf(node) && node.Star == nil:
+		flat.nodes[at].child = emptyLeaf
+	case IsLeaf(node):
+		var indices []int32
+		for _, s := range LeafStars(node) {
+			indices = append(indices, index[s])
+		}
+		flat.LinkStars(at, indices)
+		flat.nodes[at].center = node.Star.Position
+		flat.nodes[at].mass = node.Star.Mass
+	default:
+		block := int32(len(flat.nodes))
+		flat.nodes = append(flat.nodes, make([]FlatNode, 4)...)
+		flat.nodes[at].child = block
+		if node.Star != nil {
+			flat.nodes[at].center = node.Star.Position
+			flat.nodes[at].mass = node.Star.Mass
+		}
+		for i := int32(0); i < 4; i++ {
+			flat.flattenNode(node.Children[i], block+i, index)
+		}
+	}
+}
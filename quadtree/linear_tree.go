@@ -0,0 +1,290 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: A linear (pointer-free) quadtree keyed by Morton codes, with its own force walk ("-tree-build linear").
+
+package quadtree
+
+import (
+	"BarnesHut/nbody"
+	"math"
+	"sort"
+)
+
+// A LinearTree stores a quadtree without pointers between its nodes: the stars of the tree are sorted by their
+// Morton codes (see MortonCode), so the stars of every node are a contiguous range of LinearTree.stars, and the
+// children of a node split that range by the two bits of the codes at the node's level. All nodes are stored in one
+// slice, with the four children of a node next to each other in Morton order (SW, SE, NW, NE), so the child in
+// quadrant q of a node is nodes[child+q]: finding a child is index arithmetic, and the whole tree is three slices
+// that the garbage collector does not need to scan node by node.
+//
+// The force walk (NetForce) runs on the slices directly and gives the forces of WalkNetForce up to rounding.
+// Code that walks a pointer tree (the debug dump and trace, the potential energy, collisions, adaptive softening)
+// uses a copy converted on demand by QuadTree.Root.
+//
+// Morton codes resolve 2^mortonBits cells per axis, so a node at that depth is not subdivided even if it holds
+// more than leafSize stars. Such stars are within 2^-32 of the universe width of each other, and like the stars
+// sharing a leaf at MaxTreeDepth they pull as a bucket, pairwise when close.
+type LinearTree struct {
+	stars []*nbody.Star      // stars of the tree in Morton order
+	codes []uint64     // Morton code of every star of stars
+	nodes []LinearNode // nodes[0] is the root
+
+	leafSize int // largest number of stars of a leaf
+}
+
+type LinearNode struct {
+	sector   Quadrant
+	center   nbody.OrderedPair // center of mass of the stars of the node
+	mass     float64
+	bmax, b2 float64 // moments for the salmon-warren criterion, as in ComputeMoments
+	first    int32   // index of the first star of the node in LinearTree.stars
+	count    int32   // number of stars of the node
+	child    int32   // index of the first of the four children, or 0 for a leaf (the root is nobody's child)
+}
+
+
+// GenerateLinearTree builds the LinearTree of the stars inside the universe, except the tracers (see IsTracer),
+// like GenerateQuadTree. The stars are sorted by their Morton codes, and every node with more than leafSize stars
+// is split into four children at the boundaries of the bits of its level, found by binary search.
+// Input:
+//   - currentUniverse: pointer to the Universe to build the tree for.
+//   - leafSize: largest number of stars of a leaf.
+// Output:
+//   - pointer to the constructed LinearTree.
+func GenerateLinearTree(currentUniverse *nbody.Universe, leafSize int) *LinearTree {
+	width, height := currentUniverse.Bounds()
+	rootWidth := currentUniverse.RootWidth()
+	tree := &LinearTree{leafSize: leafSize}
+	for _, s := range currentUniverse.Stars {
+		if nbody.IsInsideUniverse(s, width, height) && !nbody.IsTracer(s) {
+			tree.stars = append(tree.stars, s)
+		}
+	}
+	// stars with the same code keep the order of the universe
+	order := MortonOrder(tree.stars, rootWidth)
+	sorted := make([]*nbody.Star, len(order))
+	tree.codes = make([]uint64, len(order))
+	for k, i := range order {
+		sorted[k] = tree.stars[i]
+		tree.codes[k] = MortonCode(sorted[k].Position, rootWidth)
+	}
+	tree.stars = sorted
+
+	// the slice of nodes grows with append, so the build needs no bound on the number of nodes
+	tree.nodes = make([]LinearNode, 1, 2*len(sorted)+1)
+	tree.nodes[0] = LinearNode{sector: Quadrant{X: 0, Y: 0, Width: rootWidth}, count: int32(len(sorted))}
+	tree.build(0, 0)
+	return tree
+}
+
+
+// build splits node n at the given level into its children, recursively, and computes the center of mass and
+// the moments of every node below n and of n itself.
+func (tree *LinearTree) build(n int32, level int) {
+	node := tree.nodes[n]
+	if int(node.count) <= tree.leafSize || level == mortonBits {
+		tree.summarizeLeaf(n)
+		return
+	}
+
+	// the codes of the stars of the node share their top 2 level bits; the next two give the quadrant
+	shift := uint(2 * (mortonBits - 1 - level))
+	half := node.sector.Width / 2
+	child := int32(len(tree.nodes))
+	first, end := node.first, node.first+node.count
+	for q := 0; q < 4; q++ {
+		stop := first + int32(sort.Search(int(end-first), func(k int) bool {
+			return int(tree.codes[first+int32(k)]>>shift&3) > q
+		}))
+		tree.nodes = append(tree.nodes, LinearNode{
+			sector: Quadrant{X: node.sector.X + half*float64(q&1), Y: node.sector.Y + half*float64(q>>1), Width: half},
+			first:  first,
+			count:  stop - first,
+		})
+		first = stop
+	}
+	tree.nodes[n].child = child
+
+	for q := int32(0); q < 4; q++ {
+		tree.build(child+q, level+1)
+	}
+	tree.summarizeInternal(n)
+}
+
+
+// summarizeLeaf sets the center of mass and the moments of a leaf from its stars.
+func (tree *LinearTree) summarizeLeaf(n int32) {
+	node := &tree.nodes[n]
+	stars := tree.stars[node.first : node.first+node.count]
+	// a single star pulls as itself; the stars of a bucket are its children
+	if len(stars) < 2 {
+		if len(stars) == 1 {
+			node.center, node.mass = stars[0].Position, stars[0].Mass
+		}
+		return
+	}
+	for _, s := range stars {
+		node.mass += s.Mass
+	}
+	node.center = nbody.CenterOfMass(stars)
+	for _, s := range stars {
+		_, _, d := nbody.Distance(s.Position, node.center)
+		node.b2 += s.Mass * d * d
+		node.bmax = math.Max(node.bmax, d)
+	}
+}
+
+
+// summarizeInternal sets the center of mass and the moments of an internal node from its children,
+// merged like ComputeCenterAndMass and ComputeMoments.
+func (tree *LinearTree) summarizeInternal(n int32) {
+	node := &tree.nodes[n]
+	children := tree.nodes[node.child : node.child+4]
+	var xCm, yCm float64
+	for _, c := range children {
+		node.mass += c.mass
+		xCm += c.mass * c.center.X
+		yCm += c.mass * c.center.Y
+	}
+	if node.mass == 0 {
+		return
+	}
+	node.center = nbody.OrderedPair{X: xCm / node.mass, Y: yCm / node.mass}
+
+	for _, c := range children {
+		if c.count == 0 {
+			continue
+		}
+		_, _, d := nbody.Distance(c.center, node.center)
+		node.b2 += c.b2 + c.mass*d*d
+		node.bmax = math.Max(node.bmax, d+c.bmax)
+	}
+}
+
+
+// NetForce computes the net force on a star like CalculateNetForce, walking the linear tree.
+// Input:
+//   - s: pointer to the Star for which to calculate the force.
+//   - walk: the WalkOptions of the run (theta, softening, and opening criterion).
+// Output:
+//   - OrderedPair representing the net force vector.
+func (tree *LinearTree) NetForce(s *nbody.Star, walk WalkOptions) nbody.OrderedPair {
+	return tree.walk(0, s, walk)
+}
+
+
+// walk returns the force of the subtree below node n on s, following WalkNetForce.
+func (tree *LinearTree) walk(n int32, s *nbody.Star, walk WalkOptions) nbody.OrderedPair {
+	var force nbody.OrderedPair
+	node := &tree.nodes[n]
+	if node.count == 0 || node.mass == 0 {
+		return force
+	}
+
+	// a leaf with one star pulls as that star
+	if node.child == 0 && node.count == 1 {
+		return tree.leafForce(node, s, walk)
+	}
+
+	// an internal node or a bucket that passes the opening criterion pulls like one body at its center of mass
+	dX, dY, d := nbody.Distance(node.center, s.Position)
+	if d != 0 && AcceptSector(node.sector, node.center, node.bmax, node.b2, d, walk) {
+		// the center of mass has no softening length of its own
+		return nbody.PointMassForce(walk.Law(), s.Mass, node.mass, dX, dY, d, nbody.PairSoftening(s, &nbody.Star{}, walk.Softening))
+	}
+
+	// a bucket that is too close pulls with each of its stars
+	if node.child == 0 {
+		return tree.leafForce(node, s, walk)
+	}
+
+	for q := int32(0); q < 4; q++ {
+		f := tree.walk(node.child+q, s, walk)
+		force.X += f.X
+		force.Y += f.Y
+	}
+	return force
+}
+
+
+// leafForce returns the force of the stars of a leaf on s, pairwise, like LeafForce.
+func (tree *LinearTree) leafForce(node *LinearNode, s *nbody.Star, walk WalkOptions) nbody.OrderedPair {
+	var force nbody.OrderedPair
+	for _, other := range tree.stars[node.first : node.first+node.count] {
+		if other == s {
+			continue
+		}
+		f := nbody.ComputeForce(other, s, walk.Softening, walk.Law())
+		force.X += f.X
+		force.Y += f.Y
+	}
+	return force
+}
+
+
+// NumNodes returns the number of nodes of the tree, including the empty leaves.
+func (tree *LinearTree) NumNodes() int {
+	return len(tree.nodes)
+}
+
+
+// linearQuadrants lists the children of a linear node in the order of Subdivide (NW, NE, SW, SE).
+var linearQuadrants = [4]int32{2, 3, 0, 1}
+
+
+// toNode converts the subtree below node n into Nodes, with the children in the order of Subdivide.
+// Leaves point to the original stars and internal nodes and buckets get a dummy star at their center of mass,
+// as GenerateQuadTree builds them; buckets list their stars in Morton order.
+func (tree *LinearTree) toNode(n int32) *Node {
+	node := &tree.nodes[n]
+	result := &Node{Sector: node.sector, bmax: node.bmax, b2: node.b2}
+
+	switch {
+	case node.count == 0:
+	case node.child == 0 && node.count == 1:
+		result.Star = tree.stars[node.first]
+	case node.child == 0:
+		result.Bucket = append([]*nbody.Star(nil), tree.stars[node.first:node.first+node.count]...)
+		result.Star = &nbody.Star{Position: node.center, Mass: node.mass}
+	default:
+		result.Children = make([]*Node, 4)
+		for i, q := range linearQuadrants {
+			result.Children[i] = tree.toNode(node.child + q)
+		}
+		if node.mass > 0 {
+			result.Star = &nbody.Star{Position: node.center, Mass: node.mass}
+		}
+	}
+	return result
+}
+
+
+// Root returns the root Node of the tree. The pointer tree of a LinearTree is converted on the first call;
+// Root may be called from several goroutines.
+func (tree *QuadTree) Root() *Node {
+	if tree.Linear != nil {
+		tree.convert.Do(func() {
+			tree.root = tree.Linear.toNode(0)
+		})
+	}
+	return tree.root
+}
+
+
+// NumNodes returns the number of nodes of the tree, including the empty leaves.
+func (tree *QuadTree) NumNodes() int {
+	if tree.Linear != nil {
+		return tree.Linear.NumNodes()
+	}
+	return CountNodes(tree.root)
+}
+
+
+// NetForce computes the net force on a star from the tree: with CalculateNetForce for a pointer tree, and with
+// LinearTree.NetForce for a linear one.
+func (tree *QuadTree) NetForce(s *nbody.Star, walk WalkOptions) nbody.OrderedPair {
+	if tree.Linear != nil {
+		return tree.Linear.NetForce(s, walk)
+	}
+	return CalculateNetForce(tree.root, s, walk)
+}
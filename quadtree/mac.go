@@ -0,0 +1,90 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Multipole acceptance criteria deciding when a quadtree node is far enough to act as one body.
+
+package quadtree
+
+import (
+	"BarnesHut/nbody"
+	"fmt"
+	"math"
+)
+
+// CheckMAC returns an error if name is not a known opening criterion or its tolerance is invalid.
+func CheckMAC(name string, tolerance float64) error {
+	switch name {
+	case "bh", "bmax":
+		return nil
+	case "salmon-warren":
+		if tolerance <= 0 {
+			return fmt.Errorf("the salmon-warren criterion needs a positive -mac-tolerance (acceleration error in m/s^2), got %v", tolerance)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown opening criterion %q (use bh, bmax, or salmon-warren)", name)
+}
+
+
+// AcceptNode reports whether an internal node is far enough from a star, at distance d from the node's
+// center of mass, to be replaced by its center of mass under the opening criterion of the walk (see
+// Options.WithMAC).
+// Input:
+//   - node: pointer to the internal Node (its star is the dummy star at the center of mass).
+//   - d: distance from the star to the center of mass.
+//   - walk: the WalkOptions, with theta of the "bh" and "bmax" criteria and the tolerance of "salmon-warren".
+// Output:
+//   - true if the node is accepted.
+func AcceptNode(node *Node, d float64, walk WalkOptions) bool {
+	return AcceptSector(node.Sector, node.Star.Position, node.bmax, node.b2, d, walk)
+}
+
+
+// AcceptSector is AcceptNode for a node given by its sector, center of mass, and moments (see ComputeMoments),
+// for trees that do not store Nodes (see LinearTree).
+func AcceptSector(sector Quadrant, center nbody.OrderedPair, bmax, b2, d float64, walk WalkOptions) bool {
+	switch walk.MAC {
+	case "bmax":
+		centerX := sector.X + sector.Width/2
+		centerY := sector.Y + sector.Width/2
+		_, _, delta := nbody.Distance(center, nbody.OrderedPair{X: centerX, Y: centerY})
+		return walk.Theta > 0 && d > sector.Width/walk.Theta+delta
+	case "salmon-warren":
+		if b2 == 0 {
+			return true
+		}
+		return d > bmax/2+math.Sqrt(bmax*bmax/4+math.Sqrt(3*nbody.G*b2/walk.Tolerance))
+	}
+	return sector.Width/d < walk.Theta
+}
+
+
+// ComputeMoments sets bmax and b2, used by the salmon-warren criterion, of every node below node.
+// They are merged from the children like the center of mass: b2 with the parallel axis theorem,
+// and bmax as the largest distance to a child's center of mass plus the child's bmax, an upper bound.
+// Input:
+//   - node: pointer to a Node whose centers of mass are already computed.
+// Output:
+//   - None (modifies the nodes in place).
+func ComputeMoments(node *Node) {
+	if node == nil || node.Star == nil {
+		return
+	}
+
+	node.bmax, node.b2 = 0, 0
+	// the stars of a bucket are its children
+	for _, s := range node.Bucket {
+		_, _, d := nbody.Distance(s.Position, node.Star.Position)
+		node.b2 += s.Mass * d * d
+		node.bmax = math.Max(node.bmax, d)
+	}
+	for _, child := range node.Children {
+		if child == nil || child.Star == nil {
+			continue
+		}
+		ComputeMoments(child)
+
+		_, _, d := nbody.Distance(child.Star.Position, node.Star.Position)
+		node.b2 += child.b2 + child.Star.Mass*d*d
+		node.bmax = math.Max(node.bmax, d+child.bmax)
+	}
+}
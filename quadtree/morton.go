@@ -0,0 +1,86 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Morton (Z-curve) order of the stars for the tree build and the force walk (option "-morton").
+
+package quadtree
+
+import (
+	"BarnesHut/nbody"
+	"math"
+	"sort"
+)
+
+// The Morton code of a position interleaves the bits of its x and y coordinates, so sorting by it orders the
+// stars along the Z-shaped curve that visits the quadrants of the quadtree one after the other: stars close in the
+// order are close in space and share most of the path from the root. Building the tree in this order allocates
+// the nodes of a subtree together, and walking the forces in this order visits nearly the same nodes for
+// consecutive stars, which then are still in the cache.
+//
+// The stars keep their places in Universe.Stars, where their index is their identity (for -trace-star, the
+// monitors, and the snapshots): only the order in which they are inserted and their forces are computed changes.
+
+// mortonBits is the number of bits of each coordinate in a Morton code.
+const mortonBits = 32
+
+
+// MortonCode returns the Morton code of a position in a universe of the given width. Positions outside the
+// universe are clamped to its edge.
+func MortonCode(p nbody.OrderedPair, width float64) uint64 {
+	return spreadBits(mortonCell(p.X, width)) | spreadBits(mortonCell(p.Y, width))<<1
+}
+
+
+// mortonCell returns the cell of a coordinate on a grid of 2^mortonBits cells across the width.
+func mortonCell(v, width float64) uint32 {
+	cell := math.Floor(v / width * (1 << mortonBits))
+	switch {
+	case !(cell > 0): // also NaN
+		return 0
+	case cell >= 1<<mortonBits:
+		return 1<<mortonBits - 1
+	}
+	return uint32(cell)
+}
+
+
+// spreadBits moves bit k of v to bit 2k.
+func spreadBits(v uint32) uint64 {
+	x := uint64(v)
+	x = (x | x<<16) & 0x0000ffff0000ffff
+	x = (x | x<<8) & 0x00ff00ff00ff00ff
+	x = (x | x<<4) & 0x0f0f0f0f0f0f0f0f
+	x = (x | x<<2) & 0x3333333333333333
+	x = (x | x<<1) & 0x5555555555555555
+	return x
+}
+
+
+// MortonOrder returns the indices of the stars sorted by the Morton codes of their positions; stars with the same
+// code keep their order.
+// Input:
+//   - stars: the stars, such as Universe.Stars.
+//   - width: width of the universe.
+// Output:
+//   - the permutation of the indices of stars.
+func MortonOrder(stars []*nbody.Star, width float64) []int {
+	codes := make([]uint64, len(stars))
+	order := make([]int, len(stars))
+	for i, s := range stars {
+		codes[i] = MortonCode(s.Position, width)
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return codes[order[a]] < codes[order[b]]
+	})
+	return order
+}
+
+
+// mortonUniverse returns a universe sharing the stars of u, listed in the given order.
+func mortonUniverse(u *nbody.Universe, order []int) *nbody.Universe {
+	sorted := &nbody.Universe{Stars: make([]*nbody.Star, len(order)), Width: u.Width, Height: u.Height, Time: u.Time}
+	for k, i := range order {
+		sorted.Stars[k] = u.Stars[i]
+	}
+	return sorted
+}
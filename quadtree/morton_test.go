@@ -0,0 +1,78 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the Morton order of the stars in morton.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package quadtree
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type MortonCodeTestCases struct {
+	id       string
+	position nbody.OrderedPair
+	width    float64
+	expected uint64
+}
+
+
+// ReadMortonCode reads test data for MortonCode from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of MortonCodeTestCases structs containing the positions and expected codes.
+func ReadMortonCode(fileName string) []MortonCodeTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []MortonCodeTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 3 {
+			continue
+		}
+		fields := strings.Fields(parts[1])
+		if len(fields) != 3 {
+			continue
+		}
+
+		test := MortonCodeTestCases{id: strings.TrimSpace(parts[0])}
+		test.position.X, err = strconv.ParseFloat(fields[0], 64)
+		nbody.Check(err)
+		test.position.Y, err = strconv.ParseFloat(fields[1], 64)
+		nbody.Check(err)
+		test.width, err = strconv.ParseFloat(fields[2], 64)
+		nbody.Check(err)
+		test.expected, err = strconv.ParseUint(strings.TrimSpace(parts[2]), 10, 64)
+		nbody.Check(err)
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestMortonCode tests MortonCode.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestMortonCode(t *testing.T) {
+	tests := ReadMortonCode("../Tests/MortonCode.txt")
+	for _, test := range tests {
+		if result := MortonCode(test.position, test.width); result != test.expected {
+			t.Errorf("TestMortonCode(test %v) = %d, want %d", test.id, result, test.expected)
+		}
+	}
+}
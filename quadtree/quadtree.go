@@ -0,0 +1,348 @@
+// Author: Yu-Lun Chen
+// Date: 2025-10-24
+// Description: Construction of the Barnes-Hut quadtree and the recursive walk computing the net force on a star.
+
+package quadtree
+
+import (
+	"BarnesHut/nbody"
+	"fmt"
+	"io"
+	"strings"
+)
+
+//// Functions for Preprocessing the universe: GeneraQuadTree and its subroutines ////
+
+// GenerateQuadTree constructs a QuadTree representation of the given universe.
+// It initializes the root node covering the entire universe, inserts all stars
+// that are within the universe bounds except the tracers (see IsTracer), and computes the mass and center of mass for each internal node recursively.
+// Input: current_universe is a pointer to a Universe struct containing the width and stars, and leafSize the largest
+// number of stars of a leaf (see InsertStar).
+// Output: a pointer to the constructed QuadTree with the root node.
+func GenerateQuadTree(currentUniverse *nbody.Universe, leafSize int) *QuadTree {
+	// Create root (type: pointer)
+	root := &Node{Sector: Quadrant{X: 0, Y: 0, Width: currentUniverse.RootWidth()}}
+
+	// Insert stars to root (recursively)
+	width, height := currentUniverse.Bounds()
+	for _, s := range currentUniverse.Stars {
+		// check if the star s is in the universe
+		// Only insert the star if it is in the universe; tracers pull on nothing
+		if nbody.IsInsideUniverse(s, width, height) && !nbody.IsTracer(s) {
+			InsertStar(root, s, 0, leafSize)
+		}	
+	}
+
+	// After completing building the quadtree, calculate the mass and center position for each internal node
+	// This is a recursive function
+	ComputeCenterAndMass(root)
+
+    // Create a QuadTree and return the address (type: pointer)
+	return &QuadTree{root: root}
+}
+
+
+// MaxTreeDepth is the deepest level a node is subdivided to. Sectors at this depth are 2^-48 of the universe
+// width, about the resolution of float64 positions, so stars that share a leaf there are (nearly) coincident.
+// They are kept together in a bucket instead of subdividing forever.
+const MaxTreeDepth = 48
+
+
+// InsertStar inserts a star into the given node of the QuadTree, subdividing the node if necessary.
+// A leaf holds up to leafSize stars in a bucket before it is subdivided, and a leaf at MaxTreeDepth is never
+// subdivided. A bucket pulls like an internal node when it is far enough, and pairwise otherwise (see WalkNetForce).
+// Input:
+//   - node: pointer to the Node in the QuadTree where the star should be inserted.
+//   - s: pointer to the Star to be inserted.
+//   - depth: depth of node in the tree (0 for the root).
+//   - leafSize: largest number of stars of a leaf (see Options.WithLeafSize).
+// Output:
+//   - None (the function modifies the QuadTree in place).
+func InsertStar(node *Node, s *nbody.Star, depth int, leafSize int) {
+	// Case 1: no star in this node
+	if node.Star == nil && len(node.Children) == 0 {
+		node.Star = s
+
+		return
+	}
+
+	// Case 2: a leaf with room left, or at the maximum depth, add the star to its bucket
+	if len(node.Children) == 0 && (len(LeafStars(node)) < leafSize || depth >= MaxTreeDepth) {
+		if node.Bucket == nil {
+			node.Bucket = []*nbody.Star{node.Star}
+		}
+		node.Bucket = append(node.Bucket, s)
+
+		return
+	}
+
+	// Case 3: The node is a full leaf, need to subdivide
+	if len(node.Children) == 0 {
+		Subdivide(node)
+		
+		// Copy the old stars and insert both old stars and new star
+		old_stars := LeafStars(node)
+		node.Star = nil
+		node.Bucket = nil
+
+		for _, old_star := range old_stars {
+			InsertStar(node.Children[FindQuadrant(node.Sector, old_star)], old_star, depth+1, leafSize)
+		}
+		InsertStar(node.Children[FindQuadrant(node.Sector, s)], s, depth+1, leafSize)
+
+		return
+	}
+
+	// Case 4: The node has children
+	// Directly find the quadrant for the new star and insert it
+	idx := FindQuadrant(node.Sector, s)
+	InsertStar(node.Children[idx], s, depth+1, leafSize)
+}
+
+
+// LeafStars returns the stars of the universe held by a leaf: its bucket, its star, or none.
+func LeafStars(node *Node) []*nbody.Star {
+	if node.Bucket != nil {
+		return node.Bucket
+	}
+	if node.Star == nil {
+		return nil
+	}
+	return []*nbody.Star{node.Star}
+}
+
+
+// Subdivide divide the square into four quadrant(NW, NE, SW, SE) and creates child nodes for each sub-quadrant.
+// Input:
+//   - node: pointer to the Node to be subdivided. The node's sector is split into four quadrants,
+//           and its children field is populated with four new Nodes representing these quadrants.
+// Output:
+//   - None (modifies the node in place by adding its children).
+func Subdivide(node *Node) {
+	half := node.Sector.Width / 2.0
+	x := node.Sector.X
+	y := node.Sector.Y
+
+	node.Children = []*Node{
+		&Node{Sector: Quadrant{X: x, Y: y + half, Width: half}},
+		&Node{Sector: Quadrant{X: x + half, Y: y + half, Width: half}},
+		&Node{Sector: Quadrant{X: x, Y: y, Width: half}},
+		&Node{Sector: Quadrant{X: x + half, Y: y, Width: half}},
+	}
+}
+
+
+// FindQuadrant determines which quadrant of a sector a given star belongs to.
+// Input:
+//   - sector: Quadrant representing the current node's region.
+//   - s: pointer to the Star to be located.
+// Output:
+//   - Integer index (0: NW, 1: NE, 2: SW, 3: SE) indicating the quadrant.
+func FindQuadrant(sector Quadrant, s *nbody.Star) int {
+	midX := sector.X + sector.Width / 2.0
+	midY := sector.Y + sector.Width / 2.0
+	sX := s.Position.X 
+	sY := s.Position.Y 
+
+	// NW
+	if sX < midX && sY >= midY {
+		return 0
+	}
+	// NE
+	if sX >= midX && sY >= midY {
+		return 1
+	}
+	// SW
+	if sX < midX && sY < midY {
+		return 2
+	}
+	// SE
+	return 3
+}
+
+
+// ComputeCenterAndMass recursively computes the total mass and center of mass for each internal node in the QuadTree.
+// Input:
+//   - node: pointer to the Node for which to compute mass and center of mass.
+// Output:
+//   - None (modifies the node in place).
+func ComputeCenterAndMass(node *Node) {
+	totalMass := 0.0
+	xCm, yCm := 0.0, 0.0
+
+	if node == nil {
+		return
+	}
+
+	// a bucket pulls like one body at the center of mass of its stars
+	if len(node.Children) == 0 {
+		if node.Bucket != nil {
+			for _, s := range node.Bucket {
+				totalMass += s.Mass
+			}
+			node.Star = &nbody.Star{Position: nbody.CenterOfMass(node.Bucket), Mass: totalMass}
+		}
+		return
+	}
+
+	for _, child := range node.Children {
+		// Calculate for all children node before calculate for parent nodes
+		ComputeCenterAndMass(child)
+
+		// Calculate for parent node (current node) with results from children nodes
+		if child.Star != nil {
+			m := child.Star.Mass
+			totalMass += m 
+			xCm += m * child.Star.Position.X 
+			yCm += m * child.Star.Position.Y
+		}
+	}
+
+
+	if totalMass > 0 {
+		node.Star = &nbody.Star{
+			Position: nbody.OrderedPair{X: xCm / totalMass, Y: yCm / totalMass},
+			Mass: totalMass,
+		}
+	}
+}
+
+
+// CalculateNetForce computes the net force on a star using the Barnes-Hut approximation, walking the tree as
+// selected by walk.TreeWalk: with an explicit stack (StackNetForce) or recursively (WalkNetForce), which give the same force.
+// Input:
+//   - node: pointer to the current Node in the QuadTree.
+//   - curr_star: pointer to the Star for which to calculate the force.
+//   - walk: the WalkOptions of the run (theta, softening, opening criterion, and tree walk).
+// Output:
+//   - OrderedPair representing the net force vector.
+func CalculateNetForce(node *Node, currStar *nbody.Star, walk WalkOptions) nbody.OrderedPair {
+	if walk.TreeWalk == "recursive" {
+		return WalkNetForce(node, currStar, walk, nil, 0)
+	}
+	return StackNetForce(node, currStar, walk)
+}
+
+
+// WalkNetForce is the recursive tree walk behind CalculateNetForce.
+// When trace is not nil, every leaf contributing a force and every node passing the opening criterion
+// is written to trace (indented by depth) together with the partial force it contributed.
+// Input:
+//   - node: pointer to the current Node in the QuadTree.
+//   - currStar: pointer to the Star for which to calculate the force.
+//   - walk: the WalkOptions of the run (theta, softening, and opening criterion).
+//   - trace: destination of the trace, or nil to disable tracing.
+//   - depth: depth of node in the tree (0 for the root).
+// Output:
+//   - OrderedPair representing the net force vector from the subtree.
+func WalkNetForce(node *Node, currStar *nbody.Star, walk WalkOptions, trace io.Writer, depth int) nbody.OrderedPair {
+	force, open := NodeForce(node, currStar, walk, trace, depth)
+	if !open {
+		return force
+	}
+
+	// expand the node and run recursively on their children
+	for _, child := range node.Children {
+		if child != nil {
+			f := WalkNetForce(child, currStar, walk, trace, depth+1)
+			force.X += f.X
+			force.Y += f.Y
+		}
+	}
+
+	return force
+}
+
+
+// NodeForce computes the force of one node of a tree walk on a star, or reports that the walk has to open the node.
+// Input:
+//   - node: pointer to the Node.
+//   - currStar: pointer to the Star for which to calculate the force.
+//   - walk: the WalkOptions of the run (theta, softening, and opening criterion).
+//   - trace: destination of the trace (see WalkNetForce), or nil to disable tracing.
+//   - depth: depth of node in the tree (0 for the root).
+// Output:
+//   - the force of the node if it is not opened: the force of a leaf, or the pull of its center of mass.
+//   - true if the walk has to add the forces of the children of the node instead.
+func NodeForce(node *Node, currStar *nbody.Star, walk WalkOptions, trace io.Writer, depth int) (nbody.OrderedPair, bool) {
+    var force nbody.OrderedPair
+
+	// no force cases
+	if node == nil || node.Star == nil || node.Star.Mass == 0 {
+		return force, false
+	}
+
+	// if it is a leaf with one real star: calculate the force
+	if IsLeaf(node) && node.Bucket == nil {
+		return LeafForce(node, currStar, walk, trace, depth), false
+	}
+
+	
+	// an internal node or a bucket that passes the opening criterion pulls like one body at its center of mass
+	if node.Star != currStar && node.Star != nil {
+		dX, dY, d := nbody.Distance(node.Star.Position, currStar.Position)
+
+		if d != 0 && AcceptNode(node, d, walk) {
+			force = nbody.PointMassForce(walk.Law(), currStar.Mass, node.Star.Mass, dX, dY, d, nbody.PairSoftening(currStar, node.Star, walk.Softening))
+
+			if trace != nil {
+				fmt.Fprintf(trace, "%sfar node at (%.4e, %.4e) mass %.4e s/d %.4f accepted by %s: force (%.4e, %.4e)\n",
+					strings.Repeat("  ", depth), node.Star.Position.X, node.Star.Position.Y, node.Star.Mass, node.Sector.Width/d,
+					walk.Criterion(), force.X, force.Y)
+			}
+			return force, false
+		}
+	}
+
+	// a bucket that is too close pulls with each of its stars
+	if node.Bucket != nil {
+		return LeafForce(node, currStar, walk, trace, depth), false
+	}
+
+	// if d is too small, indicating the node should be expanded
+	return force, node.Children != nil
+}
+
+
+// LeafForce computes the force of the real stars of a leaf on a star, pairwise, tracing every star like WalkNetForce.
+// Input:
+//   - node: pointer to a leaf.
+//   - currStar: pointer to the Star for which to calculate the force; it does not pull itself.
+//   - walk: the WalkOptions of the run (softening and force law).
+//   - trace: destination of the trace, or nil to disable tracing.
+//   - depth: depth of node in the tree.
+// Output:
+//   - OrderedPair representing the net force vector from the stars of the leaf.
+func LeafForce(node *Node, currStar *nbody.Star, walk WalkOptions, trace io.Writer, depth int) nbody.OrderedPair {
+	var force nbody.OrderedPair
+	for _, s := range LeafStars(node) {
+		if s == currStar {
+			continue
+		}
+		_, _, d := nbody.Distance(s.Position, currStar.Position)
+		f := nbody.ComputeForce(s, currStar, walk.Softening, walk.Law())
+		force.X += f.X
+		force.Y += f.Y
+
+		if trace != nil {
+			fmt.Fprintf(trace, "%sleaf star at (%.4e, %.4e) mass %.4e distance %.4e force (%.4e, %.4e)\n",
+				strings.Repeat("  ", depth), s.Position.X, s.Position.Y, s.Mass, d, f.X, f.Y)
+		}
+	}
+	return force
+}
+
+
+// IsLeaf checks if a node is a leaf node (has no children).
+// Input:
+//   - node: pointer to the Node to check.
+// Output:
+//   - Boolean indicating if the node is a leaf.
+func IsLeaf(node *Node) bool {
+	for _, child := range node.Children {
+		if child != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,438 @@
+// Author: Yu-Lun Chen
+// Date: 2025-10-24
+// Description: Testing functions for the quadtree subroutines in quadtree.go.
+// Each txt file contains input testing cases and the expected output for each cases.
+
+package quadtree
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+//// Difinition for some struct used in testing ////
+
+type SubdivideTestCase struct {
+    node *Node
+    expected   [4]Quadrant
+}
+
+
+type ComputeCenterAndMassTestCase struct {
+	node          *Node
+	expectedX     float64
+	expectedY     float64
+	expectedMass  float64
+}
+
+type IsLeafTestCases struct {
+	id string
+	children []*Node
+	expected bool
+}
+
+
+// ReadFindQuadrantData reads test data for the FindQuadrant function from a file.
+// Input: filename (string) - path to the test data file.
+// Output: slice of pointers to Star, width (float64), and slice of expected quadrant indices ([]int).
+func ReadFindQuadrantData(fileName string) ([]*nbody.Star, float64, []int) {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+    scanner := bufio.NewScanner(file)
+	var stars []*nbody.Star
+    var width float64
+    var expected []int
+    var lineCount int
+    readingExpected := false
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lineCount ++
+
+		// read width
+		if width == 0.0 {
+			width, _ = strconv.ParseFloat(line, 64)
+			continue
+		}
+
+		// we are in to expected result when reading_expected is True
+		if readingExpected {
+			val, _ := strconv.Atoi(line)
+			expected = append(expected, val)
+			continue
+		}
+
+		parts := strings.Fields(line)
+		if len(parts) == 1 && (parts[0] == "0" || parts[0] == "1" || parts[0] == "2" || parts[0] == "3") {
+			// we go in to expected result region
+			readingExpected = true
+            val, _ := strconv.Atoi(parts[0])
+            expected = append(expected, val)
+            continue
+
+		}
+
+		// reading star information
+		if len(parts) == 9 {
+			x, _ := strconv.ParseFloat(parts[0], 64)
+        	y, _ := strconv.ParseFloat(parts[1], 64)
+        	vx, _ := strconv.ParseFloat(parts[2], 64)
+        	vy, _ := strconv.ParseFloat(parts[3], 64)
+        	m, _ := strconv.ParseFloat(parts[4], 64)
+        	r, _ := strconv.ParseFloat(parts[5], 64)
+        	red, _ := strconv.Atoi(parts[6])
+        	green, _ := strconv.Atoi(parts[7])
+        	blue, _ := strconv.Atoi(parts[8])
+
+        	s := &nbody.Star{
+            	Position: nbody.OrderedPair{X: x, Y: y},
+            	Velocity: nbody.OrderedPair{X: vx, Y: vy},
+            	Mass:     m,
+            	Radius:   r,
+            	Red:      uint8(red),
+            	Green:    uint8(green),
+            	Blue:     uint8(blue),
+        	}
+        	stars = append(stars, s)
+		}
+	}	
+
+	return stars, width, expected
+}
+
+
+// ReadSubdivideData reads test data for the Subdivide function from a file.
+// Input: filename (string) - path to the test data file.
+// Output: slice of SubdivideTestCase structs containing nodes and expected quadrants.
+func ReadSubdivideData(fileName string) []SubdivideTestCase {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	var tests []SubdivideTestCase
+	var n *Node
+	var expected [4]Quadrant
+	childIndex := 0
+	readingExpected := false
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Fields(line)
+		if ! readingExpected {
+			// read node sector for current node
+			if len(parts) == 3 {
+				x, _ := strconv.ParseFloat(parts[0], 64)
+                y, _ := strconv.ParseFloat(parts[1], 64)
+                width, _ := strconv.ParseFloat(parts[2], 64)
+
+				n = &Node{
+					Sector: Quadrant{x, y, width},
+					Children: nil,
+				}
+                readingExpected = true
+				childIndex = 0
+			}
+		} else {
+			// read expected result
+			if len(parts) == 3 && childIndex < 4 {
+				x, _ := strconv.ParseFloat(parts[0], 64)
+                y, _ := strconv.ParseFloat(parts[1], 64)
+                width, _ := strconv.ParseFloat(parts[2], 64)
+                expected[childIndex] = Quadrant{x, y, width}
+                childIndex ++
+			}
+		}
+
+		// finish reading all expected results
+		if childIndex == 4 {
+			tests = append(tests, SubdivideTestCase{
+				node: n,
+				expected: expected,
+			})
+			// set reading_expected back to false to read next test data
+			readingExpected = false
+		}
+	}
+
+	return tests
+}
+
+
+// ReadComputeCenterAndMass reads test data for the ComputeCenterAndMass function from a file.
+// Input: file_name (string) - path to the test data file.
+// Output: slice of ComputeCenterAndMassTestCase structs containing node and expected center/mass values.
+func ReadComputeCenterAndMass(fileName string) []ComputeCenterAndMassTestCase {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	var tests []ComputeCenterAndMassTestCase
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// to check if it is leaf or internal node
+		if strings.Contains(line, "|") {
+			// is internal node
+			parts := strings.Split(line, "|")
+
+			// first, get the expected values
+			expectedParts := strings.Fields(parts[len(parts) - 1])
+			expectedX, _ := strconv.ParseFloat(expectedParts[0], 64)
+			expectedY, _ := strconv.ParseFloat(expectedParts[1], 64)
+			expectedMass, _ := strconv.ParseFloat(expectedParts[2], 64)
+
+			var children []*Node
+
+			// extract information for children nodes
+			for _, childPart := range parts[: len(parts) - 1] {
+				fields := strings.Fields(childPart)
+
+				if len(fields) != 3 {
+					continue
+				}
+
+				x, _ := strconv.ParseFloat(fields[0], 64)
+				y, _ := strconv.ParseFloat(fields[1], 64)
+				mass, _ := strconv.ParseFloat(fields[2], 64)
+
+				child := &Node{
+					Star: &nbody.Star{
+						Position: nbody.OrderedPair{X: x, Y: y},
+						Mass: mass,
+					},
+				}
+				children = append(children, child)
+			}
+
+			root := &Node{Children: children}
+
+			tests = append(tests, ComputeCenterAndMassTestCase{
+				node: root,
+				expectedX: expectedX,
+				expectedY: expectedY,
+				expectedMass: expectedMass,
+			})
+		} else {
+			// is leaf
+			parts := strings.Fields(line)
+			x, _ := strconv.ParseFloat(parts[0], 64)
+			y, _ := strconv.ParseFloat(parts[1], 64)
+			mass, _ := strconv.ParseFloat(parts[2], 64)
+
+			// first, get the expected value
+			expectedX, _ := strconv.ParseFloat(parts[3], 64)
+			expectedY, _ := strconv.ParseFloat(parts[4], 64)
+			expectedMass, _ := strconv.ParseFloat(parts[5], 64)
+
+			// extract value for node itself
+			tests = append(tests, ComputeCenterAndMassTestCase{
+				node: &Node{
+					Star: &nbody.Star{
+							Position: nbody.OrderedPair{X: x, Y: y},
+							Mass: mass,}},
+				expectedX: expectedX,
+				expectedY: expectedY,
+				expectedMass: expectedMass,
+			})
+		}
+	}
+
+	return tests
+}
+
+
+// ReadIsLeaf reads test data for the IsLeaf function from a file.
+// Input: file_name (string) - path to the test data file.
+// Output: slice of IsLeafTestCases structs containing node children and expected boolean result.
+func ReadIsLeaf(fileName string) []IsLeafTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []IsLeafTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+
+		if len(parts) != 3 {
+			continue
+		}
+
+		id := strings.TrimSpace(parts[0])
+		childrenStr := strings.TrimSpace(parts[1])
+		expectedStr := strings.TrimSpace(parts[2])
+
+		// make children slice []*Node
+		children := make([]*Node, 4)
+		if strings.Contains(childrenStr, "Node") {
+			items := strings.Split(strings.Trim(childrenStr, "[]"), ",")
+			for i, item := range items {
+				item = strings.TrimSpace(item)
+				if item == "Node" {
+					 // give a non-nil Node if string is Node
+					children[i] = &Node{}
+				} else {
+					children[i] = nil
+				}
+			}
+		}
+
+		expected := false
+		if strings.Contains(expectedStr, "true") {
+			expected = true
+		}
+
+		tests = append(tests, IsLeafTestCases{
+			id:       id,
+			children: children,
+			expected: expected,
+		})
+
+	}
+
+	return tests
+}
+
+
+// TestFindQuadrant tests the FindQuadrant function using data from a file.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestFindQuadrant(t *testing.T) {
+	stars, width, expected := ReadFindQuadrantData("../Tests/FindQuadrant.txt")
+
+	q := Quadrant{X: 0.0, Y:0.0, Width: width}
+
+	for i, s := range stars {
+		result := FindQuadrant(q, s)
+		expectedResult := expected[i]
+
+		if result != expectedResult {
+			t.Errorf("TestFindQuadrant(test %v) = %v, want %v",
+        		i, result, expectedResult)
+		}
+	}
+}
+
+
+// TestSubdivide tests the Subdivide function using data from a file.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestSubdivide(t *testing.T) {
+	tests := ReadSubdivideData("../Tests/Subdivide.txt")
+
+	for i, test := range tests {
+		Subdivide(test.node)
+
+		for j, child := range test.node.Children {
+			result := child.Sector
+			expectedResult := test.expected[j]
+
+			if result != expectedResult {
+				t.Errorf("TestSubdivide(test %v, children %v) = %v, want %v",
+        			i, j, result, expectedResult)	
+			}
+
+		}
+	}
+}
+
+
+// TestComputeCenterAndMass tests the ComputeCenterAndMass function using data from a file.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestComputeCenterAndMass(t *testing.T) {
+	tests := ReadComputeCenterAndMass("../Tests/ComputeCenterAndMass.txt")
+
+	for i, test := range tests {
+
+		ComputeCenterAndMass(test.node)
+		result := test.node.Star
+
+		if math.Abs(result.Position.X - test.expectedX) > 1e-3 ||
+			math.Abs(result.Position.Y - test.expectedY) > 1e-3 ||
+			math.Abs(result.Mass - test.expectedMass) > 1e-3 {
+				t.Errorf("TestComputeCenterAndMass (test %v) = (x: %v, y: %v, mass: %v), want (x: %v, y: %v, mass: %v)",
+					i, result.Position.X, result.Position.Y, result.Mass, test.expectedX, test.expectedY, test.expectedMass)
+			}
+	}
+}
+
+
+// TestIsLeaf tests the IsLeaf function using data from a file.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestIsLeaf(t *testing.T) {
+	tests := ReadIsLeaf("../Tests/IsLeaf.txt")
+
+	for _, test := range tests {
+		node := &Node{Children: test.children}
+		result := IsLeaf(node)
+
+		if result != test.expected {
+			t.Errorf("TestIsLeaf (test %v) = %v, want %v",
+				test.id, result, test.expected)
+		}
+	}
+}
+
+
+// BenchmarkGenerateQuadTree times the serial build of the quadtree of a galaxy for every size of nbody.BenchmarkSizes.
+// Run it with: go test -run '^$' -bench GenerateQuadTree
+func BenchmarkGenerateQuadTree(b *testing.B) {
+	for _, n := range nbody.BenchmarkSizes {
+		b.Run(fmt.Sprintf("N=%d", n), func(b *testing.B) {
+			u := nbody.BenchmarkUniverse(n)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				GenerateQuadTree(u, 1)
+			}
+		})
+	}
+}
+
+
+// BenchmarkCalculateNetForce times the tree walks for the forces on all stars of a galaxy, one after the other,
+// with theta 0.5 for every size of nbody.BenchmarkSizes.
+func BenchmarkCalculateNetForce(b *testing.B) {
+	for _, n := range nbody.BenchmarkSizes {
+		b.Run(fmt.Sprintf("N=%d", n), func(b *testing.B) {
+			u := nbody.BenchmarkUniverse(n)
+			tree := GenerateQuadTree(u, 1)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, s := range u.Stars {
+					CalculateNetForce(tree.root, s, WalkOptions{Theta: 0.5})
+				}
+			}
+		})
+	}
+}
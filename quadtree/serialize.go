@@ -0,0 +1,139 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Saving and loading a flat quadtree in a compact binary format.
+
+package quadtree
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// treeMagic starts every binary quadtree.
+var treeMagic = [4]byte{'B', 'H', 'Q', 'T'}
+
+// A binary quadtree is a compact FlatTree, in little endian:
+//
+//	magic       4 bytes  "BHQT"
+//	numStars    uint64   number of stars of the universe the tree was built from
+//	numNodes    uint64
+//	nodes       numNodes times flatNodeRecord (52 bytes each)
+//	next        numStars times int32, the next star in the bucket of every star (see FlatTree)
+//
+// Leaves refer to stars by their index in the universe, so a tree is shipped together with its snapshot.
+
+// flatNodeRecord is the binary layout of one FlatNode.
+type flatNodeRecord struct {
+	X, Y, Width float64
+	Child       int32
+	CenterX     float64
+	CenterY     float64
+	Mass        float64
+}
+
+
+// Save writes a compact copy of the tree to w in binary.
+// Input:
+//   - w: destination of the tree.
+// Output:
+//   - an error if writing failed.
+func (tree *FlatTree) Save(w io.Writer) error {
+	compact := tree.Compact()
+
+	bw := bufio.NewWriter(w)
+	header := struct {
+		Magic    [4]byte
+		NumStars uint64
+		NumNodes uint64
+	}{treeMagic, uint64(len(compact.stars)), uint64(len(compact.nodes))}
+	if err := binary.Write(bw, binary.LittleEndian, header); err != nil {
+		return err
+	}
+
+	for _, n := range compact.nodes {
+		record := flatNodeRecord{
+			X: n.sector.X, Y: n.sector.Y, Width: n.sector.Width,
+			Child:   n.child,
+			CenterX: n.center.X,
+			CenterY: n.center.Y,
+			Mass:    n.mass,
+		}
+		if err := binary.Write(bw, binary.LittleEndian, record); err != nil {
+			return err
+		}
+	}
+	if err := binary.Write(bw, binary.LittleEndian, compact.next); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+
+// LoadFlatTree reads a tree written by FlatTree.Save.
+// Input:
+//   - r: source of the tree.
+//   - u: the Universe the tree was built from (the same stars in the same order).
+// Output:
+//   - pointer to the FlatTree, or an error if the data is not a valid tree for u.
+func LoadFlatTree(r io.Reader, u *nbody.Universe) (*FlatTree, error) {
+	br := bufio.NewReader(r)
+	var header struct {
+		Magic    [4]byte
+		NumStars uint64
+		NumNodes uint64
+	}
+	if err := binary.Read(br, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if header.Magic != treeMagic {
+		return nil, errors.New("not a binary quadtree (bad magic)")
+	}
+	if header.NumStars != uint64(len(u.Stars)) {
+		return nil, fmt.Errorf("tree was built from %d stars, universe has %d", header.NumStars, len(u.Stars))
+	}
+
+	tree := &FlatTree{stars: u.Stars, next: make([]int32, len(u.Stars))}
+	for i := uint64(0); i < header.NumNodes; i++ {
+		var record flatNodeRecord
+		if err := binary.Read(br, binary.LittleEndian, &record); err != nil {
+			return nil, fmt.Errorf("node %d of %d: %w", i, header.NumNodes, err)
+		}
+		tree.nodes = append(tree.nodes, FlatNode{
+			sector: Quadrant{X: record.X, Y: record.Y, Width: record.Width},
+			child:  record.Child,
+			center: nbody.OrderedPair{X: record.CenterX, Y: record.CenterY},
+			mass:   record.Mass,
+		})
+	}
+	if err := binary.Read(br, binary.LittleEndian, tree.next); err != nil {
+		return nil, fmt.Errorf("bucket links: %w", err)
+	}
+
+	// check the references, so that walking the tree can not go out of range
+	if len(tree.nodes) == 0 {
+		return nil, errors.New("tree has no root")
+	}
+	for i, n := range tree.nodes {
+		switch {
+		case n.child == emptyLeaf:
+		case n.child <= starLeaf:
+			if int(starLeaf-n.child) >= len(u.Stars) {
+				return nil, fmt.Errorf("node %d refers to star %d of %d", i, starLeaf-n.child, len(u.Stars))
+			}
+		case n.child <= int32(i) || int(n.child)+4 > len(tree.nodes):
+			return nil, fmt.Errorf("node %d has children at invalid index %d", i, n.child)
+		}
+	}
+	// buckets are listed in increasing order (see Compact), which also rules out cycles
+	for i, next := range tree.next {
+		if next != noStar && (next <= int32(i) || int(next) >= len(u.Stars)) {
+			return nil, fmt.Errorf("star %d is followed by invalid star %d", i, next)
+		}
+	}
+
+	return tree, nil
+}
@@ -0,0 +1,40 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for saving and loading flat quadtrees in serialize.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package quadtree
+
+import (
+	"BarnesHut/nbody"
+	"bytes"
+	"testing"
+)
+
+// TestFlatTreeSaveLoad checks that a saved and loaded tree is the tree GenerateQuadTree built.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if a loaded tree differs.
+func TestFlatTreeSaveLoad(t *testing.T) {
+	tests := nbody.ReadTreeBuild("../Tests/TreeBuild.txt")
+
+	for _, test := range tests {
+		want := GenerateQuadTree(test.Universe, 1)
+
+		for _, flat := range []*FlatTree{FlattenQuadTree(want, test.Universe), GenerateFlatTree(test.Universe, 4, 1)} {
+			var buf bytes.Buffer
+			if err := flat.Save(&buf); err != nil {
+				t.Errorf("TestFlatTreeSaveLoad(test %v): Save failed: %v", test.ID, err)
+				continue
+			}
+
+			loaded, err := LoadFlatTree(&buf, test.Universe)
+			if err != nil {
+				t.Errorf("TestFlatTreeSaveLoad(test %v): LoadFlatTree failed: %v", test.ID, err)
+				continue
+			}
+			if !SameTree(loaded.QuadTree().root, want.root) {
+				t.Errorf("TestFlatTreeSaveLoad(test %v): loaded tree does not match GenerateQuadTree", test.ID)
+			}
+		}
+	}
+}
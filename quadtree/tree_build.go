@@ -0,0 +1,239 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Alternative quadtree construction strategies that use several goroutines.
+
+package quadtree
+
+import (
+	"BarnesHut/nbody"
+	"fmt"
+	"sort"
+)
+
+// partitionLevels is the number of levels that are subdivided up front by GenerateQuadTreePartitioned.
+// Two levels give 16 independent subtrees, enough to keep a few cores busy on clustered inputs.
+const partitionLevels = 2
+
+
+// BuildOptions are the Options of a run that the construction of its quadtree uses (see Options.Build), as
+// WalkOptions are those of the force walk. The zero value builds the tree serially with one star per leaf.
+type BuildOptions struct {
+	TreeBuild string // "serial" or "", "partitioned", "flat", or "linear" (see Options.WithTreeBuild)
+	LeafSize  int    // largest number of stars in a leaf before it is subdivided (see InsertStar)
+	Morton    bool   // insert the stars in their Morton order (see MortonOrder)
+	MAC       string // opening criterion of the walk, whose moments the tree computes (see AcceptSector)
+	Workers   int    // goroutines of the partitioned and flat builds, or 0 for nbody.NumWorkers
+}
+
+
+// LeafCapacity returns the largest number of stars in a leaf of the build, 1 if LeafSize is not set.
+func (build BuildOptions) LeafCapacity() int {
+	return max(build.LeafSize, 1)
+}
+
+
+// BuildQuadTree builds the quadtree of a generation with the tree build and leaf size of the run (see
+// Options.WithTreeBuild), with the moments needed by its opening criterion. In Morton order (see Options.WithMorton)
+// the stars are inserted in that order, which the tree keeps for the force walk.
+// Input:
+//   - currentUniverse: pointer to the Universe to build the tree for.
+//   - build: the BuildOptions of the run.
+// Output:
+//   - pointer to the constructed QuadTree.
+func BuildQuadTree(currentUniverse *nbody.Universe, build BuildOptions) *QuadTree {
+	var order []int
+	if build.Morton {
+		order = MortonOrder(currentUniverse.Stars, currentUniverse.RootWidth())
+		currentUniverse = mortonUniverse(currentUniverse, order)
+	}
+
+	workers := build.Workers
+	if workers <= 0 {
+		workers = nbody.NumWorkers
+	}
+
+	var tree *QuadTree
+	switch build.TreeBuild {
+	case "partitioned":
+		tree = GenerateQuadTreePartitioned(currentUniverse, workers, build.LeafCapacity())
+	case "flat":
+		tree = GenerateFlatTree(currentUniverse, workers, build.LeafCapacity()).QuadTree()
+	case "linear":
+		// the linear tree has its moments already
+		return &QuadTree{Linear: GenerateLinearTree(currentUniverse, build.LeafCapacity()), Order: order}
+	default:
+		tree = GenerateQuadTree(currentUniverse, build.LeafCapacity())
+	}
+	tree.Order = order
+
+	// the salmon-warren criterion also needs the size and second moment of every node
+	if build.MAC == "salmon-warren" {
+		ComputeMoments(tree.root)
+	}
+	return tree
+}
+
+
+// CheckLeafSize returns an error if k is not a valid leaf size.
+func CheckLeafSize(k int) error {
+	if k < 1 {
+		return fmt.Errorf("leaf size must be at least 1, got %d", k)
+	}
+	return nil
+}
+
+
+// CheckTreeBuild returns an error if name is not a known tree build strategy.
+func CheckTreeBuild(name string) error {
+	switch name {
+	case "serial", "partitioned", "flat", "linear":
+		return nil
+	}
+	return fmt.Errorf("unknown tree build %q (use serial, partitioned, flat, or linear)", name)
+}
+
+
+// GenerateQuadTreePartitioned builds the same tree as GenerateQuadTree, but in parallel.
+// The top partitionLevels levels are subdivided first and every star is binned by the
+// subtree it falls in. Each bin is then inserted into its own subtree on a separate goroutine,
+// so no locks are needed, and the subtree masses are computed the same way.
+// Finally the top levels are collapsed where they hold at most leafSize stars (the serial build
+// would never have subdivided them) and their centers of mass are merged from the subtrees.
+// Input:
+//   - currentUniverse: pointer to the Universe to build the tree for.
+//   - workers: number of goroutines used for the subtrees.
+//   - leafSize: largest number of stars of a leaf (see InsertStar).
+// Output:
+//   - pointer to the constructed QuadTree.
+func GenerateQuadTreePartitioned(currentUniverse *nbody.Universe, workers int, leafSize int) *QuadTree {
+	root := &Node{Sector: Quadrant{X: 0, Y: 0, Width: currentUniverse.RootWidth()}}
+	width, height := currentUniverse.Bounds()
+
+	// subdivide the top levels; subtrees holds the leaves of this fixed part in bin order
+	subtrees := []*Node{root}
+	for level := 0; level < partitionLevels; level++ {
+		next := make([]*Node, 0, 4*len(subtrees))
+		for _, node := range subtrees {
+			Subdivide(node)
+			next = append(next, node.Children...)
+		}
+		subtrees = next
+	}
+
+	// bin the stars by subtree, keeping the input order inside each bin
+	bins := make([][]*nbody.Star, len(subtrees))
+	for _, s := range currentUniverse.Stars {
+		if nbody.IsInsideUniverse(s, width, height) && !nbody.IsTracer(s) {
+			b := PartitionIndex(root, s)
+			bins[b] = append(bins[b], s)
+		}
+	}
+
+	// every goroutine only touches its own subtree
+	nbody.ParallelFor(len(subtrees), workers, func(i int) {
+		for _, s := range bins[i] {
+			InsertStar(subtrees[i], s, partitionLevels, leafSize)
+		}
+		ComputeCenterAndMass(subtrees[i])
+	})
+
+	// collapsed leaves list their stars in the order of the universe, like the serial build
+	var index map[*nbody.Star]int
+	if leafSize > 1 {
+		index = make(map[*nbody.Star]int, len(currentUniverse.Stars))
+		for i, s := range currentUniverse.Stars {
+			index[s] = i
+		}
+	}
+	MergePartitionedNode(root, partitionLevels, leafSize, index)
+
+	return &QuadTree{root: root}
+}
+
+
+// PartitionIndex returns the index of the level-partitionLevels subtree that a star falls in,
+// numbering the subtrees in the order GenerateQuadTreePartitioned creates them.
+func PartitionIndex(root *Node, s *nbody.Star) int {
+	index := 0
+	node := root
+	for level := 0; level < partitionLevels; level++ {
+		q := FindQuadrant(node.Sector, s)
+		index = 4*index + q
+		node = node.Children[q]
+	}
+	return index
+}
+
+
+// MergePartitionedNode finishes the pre-subdivided top of a tree whose subtrees are already built.
+// Nodes whose children are all leaves holding at most leafSize stars together are turned back into leaves,
+// and every other node gets the center of mass of its children.
+// Input:
+//   - node: pointer to a pre-subdivided Node.
+//   - levels: number of pre-subdivided levels below node.
+//   - leafSize: largest number of stars of a leaf.
+//   - index: index of every star in the universe, to order the stars of a merged leaf (only needed if leafSize > 1).
+// Output:
+//   - None (modifies the node in place).
+func MergePartitionedNode(node *Node, levels int, leafSize int, index map[*nbody.Star]int) {
+	if levels == 0 {
+		return
+	}
+
+	for _, child := range node.Children {
+		MergePartitionedNode(child, levels-1, leafSize, index)
+	}
+
+	var stars []*nbody.Star
+	for _, child := range node.Children {
+		if len(child.Children) != 0 {
+			SummarizeChildren(node)
+			return
+		}
+		stars = append(stars, LeafStars(child)...)
+	}
+
+	if len(stars) <= leafSize {
+		node.Children = nil
+		node.Star, node.Bucket = nil, nil
+		if len(stars) == 1 {
+			node.Star = stars[0]
+		} else if len(stars) > 1 {
+			sort.Slice(stars, func(a, b int) bool { return index[stars[a]] < index[stars[b]] })
+			node.Bucket = stars
+			ComputeCenterAndMass(node)
+		}
+		return
+	}
+
+	SummarizeChildren(node)
+}
+
+
+// SummarizeChildren sets the dummy star of an internal node from the stars of its children,
+// which must already hold their own centers of mass.
+// Input:
+//   - node: pointer to an internal Node.
+// Output:
+//   - None (modifies the node in place).
+func SummarizeChildren(node *Node) {
+	totalMass := 0.0
+	xCm, yCm := 0.0, 0.0
+
+	for _, child := range node.Children {
+		if child.Star != nil {
+			m := child.Star.Mass
+			totalMass += m
+			xCm += m * child.Star.Position.X
+			yCm += m * child.Star.Position.Y
+		}
+	}
+
+	node.Star = nil
+	if totalMass > 0 {
+		node.Star = &nbody.Star{
+			Position: nbody.OrderedPair{X: xCm / totalMass, Y: yCm / totalMass},
+			Mass:     totalMass,
+		}
+	}
+}
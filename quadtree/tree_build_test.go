@@ -0,0 +1,102 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the quadtree build strategies in tree_build.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package quadtree
+
+import (
+	"BarnesHut/nbody"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// SameTree reports whether two subtrees have the same shape, sectors, (dummy) stars, and buckets.
+func SameTree(a, b *Node) bool {
+	if a.Sector != b.Sector || len(a.Children) != len(b.Children) || !reflect.DeepEqual(a.Bucket, b.Bucket) {
+		return false
+	}
+	if (a.Star == nil) != (b.Star == nil) {
+		return false
+	}
+	if a.Star != nil && (a.Star.Position != b.Star.Position || a.Star.Mass != b.Star.Mass) {
+		return false
+	}
+	for i := range a.Children {
+		if !SameTree(a.Children[i], b.Children[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+
+// TestGenerateQuadTreePartitioned checks that the partitioned build gives exactly the serial tree.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if the trees differ.
+func TestGenerateQuadTreePartitioned(t *testing.T) {
+	tests := nbody.ReadTreeBuild("../Tests/TreeBuild.txt")
+
+	for _, test := range tests {
+		want := GenerateQuadTree(test.Universe, 1)
+		for _, workers := range []int{1, 4} {
+			got := GenerateQuadTreePartitioned(test.Universe, workers, 1)
+			if !SameTree(got.root, want.root) {
+				t.Errorf("TestGenerateQuadTreePartitioned(test %v, %d workers) does not match GenerateQuadTree", test.ID, workers)
+			}
+		}
+	}
+}
+
+
+// TestGenerateFlatTree checks that the lock-free flat build gives exactly the serial tree.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if the trees differ.
+func TestGenerateFlatTree(t *testing.T) {
+	tests := nbody.ReadTreeBuild("../Tests/TreeBuild.txt")
+
+	for _, test := range tests {
+		want := GenerateQuadTree(test.Universe, 1)
+		for _, workers := range []int{1, 4} {
+			got := GenerateFlatTree(test.Universe, workers, 1).QuadTree()
+			if !SameTree(got.root, want.root) {
+				t.Errorf("TestGenerateFlatTree(test %v, %d workers) does not match GenerateQuadTree", test.ID, workers)
+			}
+		}
+	}
+}
+
+
+// TestGenerateLinearTree checks that the linear tree gives the forces of the serial tree for every opening
+// criterion, and that its converted pointer tree gives the same forces as its own walk.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if a force differs.
+func TestGenerateLinearTree(t *testing.T) {
+	tests := nbody.ReadTreeBuild("../Tests/TreeBuild.txt")
+
+	for _, test := range tests {
+		for _, mac := range []string{"bh", "bmax", "salmon-warren"} {
+			want := GenerateQuadTree(test.Universe, 1)
+			ComputeMoments(want.root)
+			got := &QuadTree{Linear: GenerateLinearTree(test.Universe, 1)}
+			if n := CountNodes(got.Root()); n != got.NumNodes() {
+				t.Errorf("TestGenerateLinearTree(test %v): converted tree has %d nodes, want %d", test.ID, n, got.NumNodes())
+			}
+
+			for _, theta := range []float64{0, 0.5, 1} {
+				walk := WalkOptions{Theta: theta, MAC: mac}
+				for i, s := range test.Universe.Stars {
+					expected := CalculateNetForce(want.root, s, walk)
+					scale := math.Hypot(expected.X, expected.Y)
+					for _, result := range []nbody.OrderedPair{got.NetForce(s, walk), CalculateNetForce(got.Root(), s, walk)} {
+						if math.Hypot(result.X-expected.X, result.Y-expected.Y) > 1e-9*scale {
+							t.Errorf("TestGenerateLinearTree(test %v, %s, theta %v, star %d) = %v, want %v",
+								test.ID, mac, theta, i, result, expected)
+						}
+					}
+				}
+			}
+		}
+	}
+}
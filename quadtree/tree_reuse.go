@@ -0,0 +1,248 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Reuse of the quadtree between time steps, moving only the stars that left their leaves (option "-tree-reuse").
+
+package quadtree
+
+import (
+	"BarnesHut/nbody"
+	"fmt"
+	"math"
+)
+
+// With tree reuse (see Options.WithTreeReuse), the quadtree of the previous step is refitted to the stars of the
+// next one (RefitQuadTree) instead of being built from scratch. Most stars move much less than the width of their
+// leaf in one step, so they stay where they are; only the stars that crossed into another sector are taken out and
+// inserted again, starting from the smallest node that still contains them, and the centers of mass are summed
+// again from the leaves. No node is allocated for the stars that stay.
+
+
+// CheckTreeReuse returns an error if the tree of the tree build cannot be refitted.
+func CheckTreeReuse(reuse bool, build string) error {
+	if reuse && build == "linear" {
+		return fmt.Errorf("-tree-reuse needs a pointer tree; the linear tree of -tree-build linear is rebuilt every step")
+	}
+	return nil
+}
+
+
+// sectorBounds are the bounds of the positions routed into a node by FindQuadrant from the root: x in [minX, maxX)
+// and y in [minY, maxY). They are the midpoints of the node's ancestors, so a position is inside them exactly when
+// FindQuadrant would send it into the node.
+type sectorBounds struct {
+	minX, maxX, minY, maxY float64
+}
+
+
+// contains reports whether a position is inside the bounds.
+func (b sectorBounds) contains(p nbody.OrderedPair) bool {
+	return p.X >= b.minX && p.X < b.maxX && p.Y >= b.minY && p.Y < b.maxY
+}
+
+
+// child returns the bounds of the child of a node in quadrant q (see FindQuadrant).
+func (b sectorBounds) child(sector Quadrant, q int) sectorBounds {
+	midX := sector.X + sector.Width/2.0
+	midY := sector.Y + sector.Width/2.0
+	if q == 0 || q == 2 {
+		b.maxX = midX
+	} else {
+		b.minX = midX
+	}
+	if q == 0 || q == 1 {
+		b.minY = midY
+	} else {
+		b.maxY = midY
+	}
+	return b
+}
+
+
+// treeRefit holds the state of one RefitQuadTree.
+type treeRefit struct {
+	width  float64 // width and height of the universe
+	height float64
+	index  map[*nbody.Star]int // index of every star of the previous step
+	stars  []*nbody.Star       // the stars of the next step, taking the places of the previous ones by index
+	inTree []bool        // whether the star of every index of stars is in the tree
+
+	leafSize int // largest number of stars of a leaf
+}
+
+
+// RefitQuadTree updates the quadtree of the previous step for the stars of the next one, and returns it.
+// The star with index i of u takes the place of the star with index i of the previous step (a copy of it, or the
+// star itself if the step was made in place). Every star still inside the sector of its leaf stays there; the
+// others are taken out and inserted again into the smallest node containing them (with InsertStar), and stars that
+// left the universe or became tracers are dropped, while stars that entered the universe or were added are
+// inserted from the root. Nodes left with at most leafSize stars are merged back into leaves, so the tree has the
+// nodes GenerateQuadTree builds; buckets may list their stars in another order. Finally the centers of mass (and
+// the moments of -mac salmon-warren) are computed again.
+// A tree built for a universe of another size, or a linear tree, is built again with BuildQuadTree.
+// Input:
+//   - tree: pointer to the QuadTree of the previous step; it is modified.
+//   - previous: the stars of the previous step, in the order they had in its universe.
+//   - u: pointer to the Universe of the next step.
+//   - build: the BuildOptions of the run, which built the tree.
+// Output:
+//   - pointer to the QuadTree of u.
+func RefitQuadTree(tree *QuadTree, previous []*nbody.Star, u *nbody.Universe, build BuildOptions) *QuadTree {
+	if tree.Linear != nil || tree.root == nil || tree.root.Sector != (Quadrant{X: 0, Y: 0, Width: u.RootWidth()}) {
+		return BuildQuadTree(u, build)
+	}
+
+	width, height := u.Bounds()
+	refit := &treeRefit{
+		width:  width,
+		height: height,
+		index:  make(map[*nbody.Star]int, len(previous)),
+		stars:  u.Stars,
+		inTree: make([]bool, len(u.Stars)),
+
+		leafSize: build.LeafCapacity(),
+	}
+	for i, s := range previous {
+		refit.index[s] = i
+	}
+
+	// the root takes every star inside the universe, so nothing escapes it
+	all := sectorBounds{minX: math.Inf(-1), maxX: math.Inf(1), minY: math.Inf(-1), maxY: math.Inf(1)}
+	refit.refitNode(tree.root, 0, all)
+	for i, s := range u.Stars {
+		if !refit.inTree[i] && refit.keeps(s) {
+			InsertStar(tree.root, s, 0, build.LeafCapacity())
+		}
+	}
+
+	ComputeCenterAndMass(tree.root)
+	if build.MAC == "salmon-warren" {
+		ComputeMoments(tree.root)
+	}
+	tree.Order = nil
+	if build.Morton {
+		tree.Order = MortonOrder(u.Stars, u.RootWidth())
+	}
+	return tree
+}
+
+
+// keeps reports whether a star belongs in the tree at all (see GenerateQuadTree).
+func (refit *treeRefit) keeps(s *nbody.Star) bool {
+	return nbody.IsInsideUniverse(s, refit.width, refit.height) && !nbody.IsTracer(s)
+}
+
+
+// take returns the star of the next step taking the place of a star of the previous one, and marks it as in the
+// tree, or returns nil if that star does not belong in the tree (or there is none).
+func (refit *treeRefit) take(old *nbody.Star) *nbody.Star {
+	i, ok := refit.index[old]
+	if !ok || i >= len(refit.stars) {
+		return nil
+	}
+	s := refit.stars[i]
+	if !refit.keeps(s) {
+		return nil
+	}
+	refit.inTree[i] = true
+	return s
+}
+
+
+// refitNode refits the subtree below node, whose positions are bounded by bounds. Stars that moved out of the
+// bounds are removed from the subtree and returned, for an ancestor of node to insert them.
+// Input:
+//   - node: pointer to the Node.
+//   - depth: depth of node in the tree (0 for the root).
+//   - bounds: the bounds of node.
+// Output:
+//   - the stars that left node.
+//   - the number of stars left in the subtree.
+func (refit *treeRefit) refitNode(node *Node, depth int, bounds sectorBounds) ([]*nbody.Star, int) {
+	if len(node.Children) == 0 {
+		// a leaf with one star, the most common, is refitted without allocating
+		if node.Bucket == nil {
+			if node.Star == nil {
+				return nil, 0
+			}
+			s := refit.take(node.Star)
+			node.Star = nil
+			switch {
+			case s == nil:
+				return nil, 0
+			case !bounds.contains(s.Position):
+				return []*nbody.Star{s}, 0
+			}
+			node.Star = s
+			return nil, 1
+		}
+
+		// the stars staying in a bucket are kept in its slice
+		var escaped []*nbody.Star
+		kept := node.Bucket[:0]
+		for _, old := range node.Bucket {
+			s := refit.take(old)
+			switch {
+			case s == nil:
+			case !bounds.contains(s.Position):
+				escaped = append(escaped, s)
+			default:
+				kept = append(kept, s)
+			}
+		}
+		setLeafStars(node, kept)
+		return escaped, len(kept)
+	}
+
+	// stars that left a child but not node are inserted again below node, once every child is refitted
+	var escaped, moved []*nbody.Star
+	count := 0
+	for q, child := range node.Children {
+		out, n := refit.refitNode(child, depth+1, bounds.child(node.Sector, q))
+		count += n
+		for _, s := range out {
+			if bounds.contains(s.Position) {
+				moved = append(moved, s)
+			} else {
+				escaped = append(escaped, s)
+			}
+		}
+	}
+
+	// a subtree with few stars left is a leaf again, as InsertStar would have made it
+	if count <= refit.leafSize {
+		var stars []*nbody.Star
+		CollectStars(node, &stars)
+		node.Children = nil
+		setLeafStars(node, stars)
+	}
+	for _, s := range moved {
+		InsertStar(node, s, depth, refit.leafSize)
+	}
+	return escaped, count + len(moved)
+}
+
+
+// setLeafStars makes node a leaf holding the stars, as InsertStar leaves it: empty, with one star, or with a bucket.
+func setLeafStars(node *Node, stars []*nbody.Star) {
+	node.Star, node.Bucket = nil, nil
+	switch len(stars) {
+	case 0:
+	case 1:
+		node.Star = stars[0]
+	default:
+		node.Star = stars[0]
+		node.Bucket = stars
+	}
+}
+
+
+// CollectStars appends the stars of the leaves below node to stars.
+func CollectStars(node *Node, stars *[]*nbody.Star) {
+	if len(node.Children) == 0 {
+		*stars = append(*stars, LeafStars(node)...)
+		return
+	}
+	for _, child := range node.Children {
+		CollectStars(child, stars)
+	}
+}
@@ -0,0 +1,109 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Tree walk of the force calculation with an explicit stack instead of recursion (option "-tree-walk").
+
+package quadtree
+
+import (
+	"BarnesHut/nbody"
+	"fmt"
+)
+
+// WalkOptions are the Options of a run that the force walk of a tree passes down to every node (see Options.Walk),
+// instead of all the Options. The zero value walks with an explicit stack under the "bh" criterion, with theta 0,
+// no softening, and Newtonian gravity.
+type WalkOptions struct {
+	Theta     float64  // opening threshold of the "bh" and "bmax" criteria
+	Softening float64  // Plummer softening length (0 for none)
+	MAC       string   // opening criterion (see Options.WithMAC); "" is "bh"
+	Tolerance float64  // acceleration error in m/s^2 accepted per node by "salmon-warren"
+	TreeWalk  string   // "recursive" for WalkNetForce, or "stack" or "" for StackNetForce (see CalculateNetForce)
+	ForceLaw       nbody.ForceLaw // law of the force between stars; nil is Newtonian{}
+}
+
+
+// Criterion returns the name of the opening criterion of the walk.
+func (walk WalkOptions) Criterion() string {
+	if walk.MAC == "" {
+		return "bh"
+	}
+	return walk.MAC
+}
+
+
+// Law returns the ForceLaw of the walk.
+func (walk WalkOptions) Law() nbody.ForceLaw {
+	if walk.ForceLaw == nil {
+		return nbody.Newtonian{}
+	}
+	return walk.ForceLaw
+}
+
+
+// CheckTreeWalk returns an error if name is not a known tree walk.
+func CheckTreeWalk(name string) error {
+	switch name {
+	case "stack", "recursive":
+		return nil
+	}
+	return fmt.Errorf("unknown tree walk %q (use stack or recursive)", name)
+}
+
+
+// walkFrame is a node opened by StackNetForce: the sum of the forces of its children visited so far, and the
+// index of the next child to visit.
+type walkFrame struct {
+	node  *Node
+	next  int
+	force nbody.OrderedPair
+}
+
+
+// StackNetForce computes the net force on a star like WalkNetForce, but in one loop: the opened nodes are kept on
+// an explicit stack, whose frames sum the forces of their children like the calls of WalkNetForce do, so the
+// forces are identical. The walk never grows the goroutine stack, and for trees no deeper than MaxTreeDepth the
+// stack of frames does not leave the stack frame of the function either.
+// Input:
+//   - root: pointer to the root Node of the subtree.
+//   - currStar: pointer to the Star for which to calculate the force.
+//   - walk: the WalkOptions of the run (theta, softening, and opening criterion).
+// Output:
+//   - OrderedPair representing the net force vector from the subtree.
+func StackNetForce(root *Node, currStar *nbody.Star, walk WalkOptions) nbody.OrderedPair {
+	force, open := NodeForce(root, currStar, walk, nil, 0)
+	if !open {
+		return force
+	}
+
+	stack := make([]walkFrame, 1, MaxTreeDepth+1)
+	stack[0] = walkFrame{node: root}
+	for {
+		top := &stack[len(stack)-1]
+
+		// all children visited: the frame's sum is the force of its node on the frame below
+		if top.next == len(top.node.Children) {
+			f := top.force
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return f
+			}
+			parent := &stack[len(stack)-1]
+			parent.force.X += f.X
+			parent.force.Y += f.Y
+			continue
+		}
+
+		child := top.node.Children[top.next]
+		top.next++
+		if child == nil {
+			continue
+		}
+		f, open := NodeForce(child, currStar, walk, nil, len(stack))
+		if open {
+			stack = append(stack, walkFrame{node: child})
+			continue
+		}
+		top.force.X += f.X
+		top.force.Y += f.Y
+	}
+}
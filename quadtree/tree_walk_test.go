@@ -0,0 +1,33 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the stack-based tree walk in tree_walk.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package quadtree
+
+import (
+	"BarnesHut/nbody"
+	"fmt"
+	"testing"
+)
+
+// BenchmarkTreeWalk times the stack walk and the recursive walk for the forces on all stars of a galaxy, one
+// after the other, with theta 0.5 for every size of nbody.BenchmarkSizes.
+// Run it with: go test -run '^$' -bench TreeWalk
+func BenchmarkTreeWalk(b *testing.B) {
+	for _, n := range nbody.BenchmarkSizes {
+		for _, walk := range []string{"recursive", "stack"} {
+			b.Run(fmt.Sprintf("%s/N=%d", walk, n), func(b *testing.B) {
+				u := nbody.BenchmarkUniverse(n)
+				tree := GenerateQuadTree(u, 1)
+				options := WalkOptions{Theta: 0.5, TreeWalk: walk}
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					for _, s := range u.Stars {
+						CalculateNetForce(tree.root, s, options)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Star colors that evolve with the age of every star (option "-age-colors").
+
+package render
+
+import (
+	"BarnesHut/nbody"
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// AgeColorTime is the age in seconds at which a star is drawn fully red, set from the -age-colors option.
+// 0 (the default) draws every star with its own color.
+var AgeColorTime = 0.0
+
+// ageColors are the colors of a star at ages 0, 1/3, 2/3, and 1 times AgeColorTime; ages in between
+// are interpolated and older stars stay red. Young stars are blue like hot, newly formed stars.
+var ageColors = []color.RGBA{
+	{110, 150, 255, 255}, // blue
+	{255, 255, 255, 255}, // white
+	{255, 210, 120, 255}, // yellow
+	{255, 90, 60, 255},   // red
+}
+
+
+// CheckAgeColors returns an error if the age color time is negative.
+func CheckAgeColors(t float64) error {
+	if t < 0 {
+		return fmt.Errorf("age color time must not be negative, got %v", t)
+	}
+	return nil
+}
+
+
+// AgeColor returns the color of a star of the given age on the ramp ageColors.
+// Input:
+//   - age: age of the star in seconds.
+//   - timescale: age at which the star is fully red.
+// Output:
+//   - the color.
+func AgeColor(age, timescale float64) color.RGBA {
+	x := math.Min(math.Max(age/timescale, 0), 1) * float64(len(ageColors)-1)
+	i := min(int(x), len(ageColors)-2)
+	f := x - float64(i)
+
+	a, b := ageColors[i], ageColors[i+1]
+	mix := func(p, q uint8) uint8 {
+		return uint8(math.Round(float64(p) + f*(float64(q)-float64(p))))
+	}
+	return color.RGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: 255}
+}
+
+
+// StarColor returns the red, green, and blue value a star is drawn with: its age color if AgeColorTime
+// is set, and its own color otherwise.
+func StarColor(s *nbody.Star) (uint8, uint8, uint8) {
+	if AgeColorTime <= 0 {
+		return s.Red, s.Green, s.Blue
+	}
+	c := AgeColor(s.Age, AgeColorTime)
+	return c.R, c.G, c.B
+}
@@ -0,0 +1,84 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the age colors in age.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package render
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"image/color"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type AgeColorTestCases struct {
+	id        string
+	age       float64
+	timescale float64
+	expected  color.RGBA
+}
+
+
+// ReadAgeColor reads test data for the AgeColor function from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of AgeColorTestCases structs containing the age, the timescale, and the expected color.
+func ReadAgeColor(fileName string) []AgeColorTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []AgeColorTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		age, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		nbody.Check(err)
+		timescale, err := strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+		nbody.Check(err)
+
+		var rgb [3]uint8
+		for i, field := range strings.Split(parts[3], ",") {
+			v, err := strconv.ParseUint(strings.TrimSpace(field), 10, 8)
+			nbody.Check(err)
+			rgb[i] = uint8(v)
+		}
+
+		tests = append(tests, AgeColorTestCases{
+			id:        strings.TrimSpace(parts[0]),
+			age:       age,
+			timescale: timescale,
+			expected:  color.RGBA{R: rgb[0], G: rgb[1], B: rgb[2], A: 255},
+		})
+	}
+
+	return tests
+}
+
+
+// TestAgeColor tests the AgeColor function using data from a file.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestAgeColor(t *testing.T) {
+	tests := ReadAgeColor("../Tests/AgeColor.txt")
+
+	for _, test := range tests {
+		result := AgeColor(test.age, test.timescale)
+		if result != test.expected {
+			t.Errorf("TestAgeColor(test %v) = %v, want %v", test.id, result, test.expected)
+		}
+	}
+}
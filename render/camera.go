@@ -0,0 +1,206 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: The part of the universe drawn on every frame, fixed or following the stars (options "-camera", "-track-star", "-track-galaxy").
+
+package render
+
+import (
+	"BarnesHut/nbody"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Camera selects the part of the universe DrawToCanvas draws, set from the -camera option.
+//   - "fixed": the whole universe, the same rectangle on every frame.
+//   - "auto": a rectangle centered on the center of mass of the stars and zoomed on every frame to the stars
+//     holding most of the mass (see AutoView), so a system that drifts, contracts, or expands stays in view.
+// Either way the view has the proportions of the universe, and so do the frames (see CanvasHeight).
+var Camera = "fixed"
+
+// CurrentTrack is the target followed with -track-star or -track-galaxy, or nil.
+var CurrentTrack *CameraTrack
+
+// cameraMassFraction is the fraction of the mass an automatic view is zoomed to, and cameraMargin the ratio of
+// half the smaller side of the view to the radius holding that mass. A few escaping stars do not change the zoom.
+const (
+	cameraMassFraction = 0.9
+	cameraMargin       = 1.5
+)
+
+
+// CheckCamera returns an error if name is not a known camera.
+func CheckCamera(name string) error {
+	switch name {
+	case "fixed", "auto":
+		return nil
+	}
+	return fmt.Errorf("unknown camera %q (use fixed or auto)", name)
+}
+
+
+// A View is the rectangle of the universe drawn on a frame: its lower corner, its width, and its height.
+type View struct {
+	X, Y          float64
+	Width, Height float64
+}
+
+
+// FixedView returns the view of the whole universe of the given width and height.
+func FixedView(width, height float64) View {
+	return View{X: 0, Y: 0, Width: width, Height: height}
+}
+
+
+// UniverseView returns the view of the universe drawn by DrawToCanvas, following Camera, and centered on the target of
+// CurrentTrack if there is one.
+func UniverseView(u *nbody.Universe) View {
+	width, height := u.Bounds()
+	view := FixedView(width, height)
+	if Camera == "auto" {
+		view = AutoView(u.Stars, width, height)
+	}
+	if CurrentTrack != nil {
+		if center, ok := CurrentTrack.Center(u); ok {
+			view = view.CenteredOn(center)
+		}
+	}
+	return view
+}
+
+
+// AutoView returns the view centered on the center of mass of the stars, with the proportions of the universe,
+// whose smaller side is twice cameraMargin times the distance from the center within which the stars hold
+// cameraMassFraction of the mass. Tracers have no mass and do not move the view. Without mass, or with all of it
+// at one point, the view is the whole universe.
+// Input:
+//   - stars: the stars to follow.
+//   - width: width of the universe.
+//   - height: height of the universe (see Bounds).
+// Output:
+//   - the View.
+func AutoView(stars []*nbody.Star, width, height float64) View {
+	center := nbody.CenterOfMass(stars)
+
+	type shell struct {
+		d, mass float64
+	}
+	shells := make([]shell, 0, len(stars))
+	totalMass := 0.0
+	for _, s := range stars {
+		if s.Mass <= 0 {
+			continue
+		}
+		_, _, d := nbody.Distance(s.Position, center)
+		shells = append(shells, shell{d: d, mass: s.Mass})
+		totalMass += s.Mass
+	}
+	sort.Slice(shells, func(i, j int) bool {
+		return shells[i].d < shells[j].d
+	})
+
+	radius, mass := 0.0, 0.0
+	for _, s := range shells {
+		mass += s.mass
+		radius = s.d
+		if mass >= cameraMassFraction*totalMass {
+			break
+		}
+	}
+	if radius == 0 {
+		return FixedView(width, height)
+	}
+
+	side := 2 * cameraMargin * radius
+	view := View{Width: side * width / math.Min(width, height), Height: side * height / math.Min(width, height)}
+	return view.CenteredOn(center)
+}
+
+
+// CenteredOn returns the view of the same size centered on a position.
+func (v View) CenteredOn(center nbody.OrderedPair) View {
+	return View{X: center.X - v.Width/2, Y: center.Y - v.Height/2, Width: v.Width, Height: v.Height}
+}
+
+
+// CanvasHeight returns the height in pixels of a canvas of canvasWidth pixels showing the view, so the universe
+// is drawn at the same scale along both axes: canvasWidth for a square view.
+func (v View) CanvasHeight(canvasWidth int) int {
+	return int(math.Max(1, math.Round(float64(canvasWidth)*v.Height/v.Width)))
+}
+
+
+// CanvasPoint returns the coordinates of a position on a canvas of canvasWidth pixels (and CanvasHeight) showing
+// the view.
+func (v View) CanvasPoint(p nbody.OrderedPair, canvasWidth int) (float64, float64) {
+	return ((p.X - v.X) / v.Width) * float64(canvasWidth), ((p.Y - v.Y) / v.Width) * float64(canvasWidth)
+}
+
+
+// A CameraTrack is a target the view follows: a star, such as the central black hole of a galaxy, or a group of
+// stars tagged in the initial universe, whose center of mass is followed. Stars are identified by their IDs (see
+// Star.ID), so the target stays the same when stars are removed by -remove-escaped or merged by -merge.
+type CameraTrack struct {
+	star    int64      // ID of the followed star, or -1 for a group
+	members nbody.StarFilter // the stars of the followed group
+}
+
+
+// NewCameraTrack returns the target following a star or a galaxy of the initial universe.
+// Input:
+//   - u: pointer to the initial Universe.
+//   - star: ID of the star to follow (its index in the initial universe, see AssignStarIDs), or -1.
+//   - galaxy: galaxy of origin (see TagOrigins) whose stars are followed, or -1.
+// Output:
+//   - pointer to the CameraTrack, or nil if neither is given.
+//   - an error if both are given, or the star or galaxy does not exist.
+func NewCameraTrack(u *nbody.Universe, star, galaxy int) (*CameraTrack, error) {
+	switch {
+	case star >= 0 && galaxy >= 0:
+		return nil, fmt.Errorf("-track-star and -track-galaxy can not be combined")
+	case star >= 0 && u.StarIndex(int64(star)) < 0:
+		return nil, fmt.Errorf("-track-star %d: no star of the universe has this ID", star)
+	case star >= 0:
+		return &CameraTrack{star: int64(star)}, nil
+	case galaxy < 0:
+		return nil, nil
+	}
+
+	origins, numGalaxies := nbody.TagOrigins(u)
+	if galaxy >= numGalaxies {
+		return nil, fmt.Errorf("-track-galaxy %d: the galaxies of the universe are numbered 0 to %d", galaxy, numGalaxies-1)
+	}
+	var ids []int64
+	for i, origin := range origins {
+		if origin.Galaxy == galaxy {
+			ids = append(ids, u.Stars[i].ID)
+		}
+	}
+	return &CameraTrack{star: -1, members: nbody.WithIDs(ids...)}, nil
+}
+
+
+// Center returns the position of the target in a universe, and false if it is gone: the star no longer exists or
+// no star of the group with mass is left.
+func (track *CameraTrack) Center(u *nbody.Universe) (nbody.OrderedPair, bool) {
+	if track.star >= 0 {
+		i := u.StarIndex(track.star)
+		if i < 0 {
+			return nbody.OrderedPair{}, false
+		}
+		return u.Stars[i].Position, true
+	}
+
+	var group []*nbody.Star
+	mass := 0.0
+	for _, s := range u.Stars {
+		if track.members(s) {
+			group = append(group, s)
+			mass += s.Mass
+		}
+	}
+	if mass <= 0 {
+		return nbody.OrderedPair{}, false
+	}
+	return nbody.CenterOfMass(group), true
+}
@@ -0,0 +1,255 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the automatic camera and the tracking of stars in camera.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package render
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type AutoViewTestCases struct {
+	id       string
+	width    float64
+	height   float64
+	center   nbody.OrderedPair
+	stars    []*nbody.Star
+	expected View
+}
+
+
+// ReadAutoView reads test data for AutoView from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of AutoViewTestCases structs containing the stars, the universe size, and the expected view.
+func ReadAutoView(fileName string) []AutoViewTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []AutoViewTestCases
+	scanner := bufio.NewScanner(file)
+
+	parse := func(fields []string) []float64 {
+		values := make([]float64, len(fields))
+		for i, f := range fields {
+			values[i], err = strconv.ParseFloat(f, 64)
+			nbody.Check(err)
+		}
+		return values
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+		// a universe or a view without a height is a square
+		size, center, view := parse(strings.Fields(parts[1])), parse(strings.Fields(parts[2])), parse(strings.Fields(parts[4]))
+		if len(size) == 1 {
+			size = append(size, size[0])
+		}
+		if len(view) == 3 {
+			view = append(view, view[2])
+		}
+		if len(size) != 2 || len(center) != 2 || len(view) != 4 {
+			continue
+		}
+
+		test := AutoViewTestCases{
+			id:       strings.TrimSpace(parts[0]),
+			width:    size[0],
+			height:   size[1],
+			center:   nbody.OrderedPair{X: center[0], Y: center[1]},
+			expected: View{X: view[0], Y: view[1], Width: view[2], Height: view[3]},
+		}
+
+		// every ring is count radius mass, with its stars evenly spaced around the center
+		for _, ring := range strings.Split(parts[3], ";") {
+			fields := strings.Fields(ring)
+			if len(fields) != 3 {
+				continue
+			}
+			values := parse(fields)
+			count := int(values[0])
+			for k := 0; k < count; k++ {
+				angle := 2 * math.Pi * float64(k) / float64(count)
+				test.stars = append(test.stars, &nbody.Star{
+					Position: nbody.OrderedPair{X: test.center.X + values[1]*math.Cos(angle), Y: test.center.Y + values[1]*math.Sin(angle)},
+					Mass:     values[2],
+				})
+			}
+		}
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestAutoView tests that the automatic view is centered on the center of mass of the stars and zoomed to the
+// radius holding most of their mass, ignoring tracers and a few distant stars.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestAutoView(t *testing.T) {
+	tests := ReadAutoView("../Tests/AutoView.txt")
+
+	for _, test := range tests {
+		result := AutoView(test.stars, test.width, test.height)
+		tolerance := 1e-9 * test.expected.Width
+		if math.Abs(result.X-test.expected.X) > tolerance || math.Abs(result.Y-test.expected.Y) > tolerance ||
+			math.Abs(result.Width-test.expected.Width) > tolerance || math.Abs(result.Height-test.expected.Height) > tolerance {
+			t.Errorf("TestAutoView(test %v) = %+v, want %+v", test.id, result, test.expected)
+		}
+
+		// the center of the view is drawn at the center of the canvas
+		canvasHeight := result.CanvasHeight(200)
+		x, y := result.CanvasPoint(nbody.OrderedPair{X: result.X + result.Width/2, Y: result.Y + result.Height/2}, 200)
+		if math.Abs(x-100) > 1e-6 || math.Abs(y-float64(canvasHeight)/2) > 1e-6 {
+			t.Errorf("TestAutoView(test %v): the center of the view is drawn at (%v, %v) on a canvas of height %d, want (100, %v)",
+				test.id, x, y, canvasHeight, float64(canvasHeight)/2)
+		}
+	}
+}
+
+
+type CameraTrackTestCases struct {
+	id       string
+	universe *nbody.Universe
+	star     int
+	galaxy   int
+	removed  int
+	expected string
+}
+
+
+// ReadCameraTrack reads test data for CameraTrack from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of CameraTrackTestCases structs containing the universe, the target, and the expected center.
+func ReadCameraTrack(fileName string) []CameraTrackTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []CameraTrackTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+		target := strings.Fields(parts[3])
+		if len(target) != 3 {
+			continue
+		}
+
+		width, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		nbody.Check(err)
+		test := CameraTrackTestCases{
+			id:       strings.TrimSpace(parts[0]),
+			universe: nbody.NewUniverse(width),
+			expected: strings.TrimSpace(parts[4]),
+		}
+		for _, text := range strings.Split(parts[2], ";") {
+			var values [3]float64
+			for i, f := range strings.Fields(text) {
+				values[i], err = strconv.ParseFloat(f, 64)
+				nbody.Check(err)
+			}
+			test.universe.AddStars(nbody.NewStar(nbody.OrderedPair{X: values[0], Y: values[1]}, nbody.OrderedPair{}, values[2], 1))
+		}
+		test.star, err = strconv.Atoi(target[0])
+		nbody.Check(err)
+		test.galaxy, err = strconv.Atoi(target[1])
+		nbody.Check(err)
+		test.removed, err = strconv.Atoi(target[2])
+		nbody.Check(err)
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestCameraTrack tests that the target of -track-star and -track-galaxy is found in the universe, that the view
+// is centered on it, and that a view without its target falls back to the view of the camera.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestCameraTrack(t *testing.T) {
+	tests := ReadCameraTrack("../Tests/CameraTrack.txt")
+	oldTrack := CurrentTrack
+	defer func() {
+		CurrentTrack = oldTrack
+	}()
+
+	for _, test := range tests {
+		u := test.universe
+		track, err := NewCameraTrack(u, test.star, test.galaxy)
+		switch {
+		case err != nil || track == nil:
+			result := "none"
+			if err != nil {
+				result = "error"
+			}
+			if result != test.expected {
+				t.Errorf("TestCameraTrack(test %v) = %v (error %v), want %v", test.id, result, err, test.expected)
+			}
+			continue
+		case test.expected == "error" || test.expected == "none":
+			t.Errorf("TestCameraTrack(test %v) = a target, want %v", test.id, test.expected)
+			continue
+		}
+
+		if test.removed >= 0 {
+			u.Stars = u.Stars[:len(u.Stars)-test.removed]
+		} else {
+			u.Stars = u.Stars[-test.removed:]
+		}
+		CurrentTrack = track
+		center, ok := track.Center(u)
+		view := UniverseView(u)
+		if !ok {
+			if test.expected != "gone" {
+				t.Errorf("TestCameraTrack(test %v) = gone, want %v", test.id, test.expected)
+			} else if view != FixedView(u.Bounds()) {
+				t.Errorf("TestCameraTrack(test %v): the view without its target is %+v, want %+v", test.id, view, FixedView(u.Bounds()))
+			}
+			continue
+		}
+
+		fields := strings.Fields(test.expected)
+		if len(fields) != 2 {
+			t.Errorf("TestCameraTrack(test %v) = %v, want %v", test.id, center, test.expected)
+			continue
+		}
+		x, err := strconv.ParseFloat(fields[0], 64)
+		nbody.Check(err)
+		y, err := strconv.ParseFloat(fields[1], 64)
+		nbody.Check(err)
+		if math.Abs(center.X-x) > 1e-9*u.Width || math.Abs(center.Y-y) > 1e-9*u.Width {
+			t.Errorf("TestCameraTrack(test %v) = %v, want (%v, %v)", test.id, center, x, y)
+		}
+		if math.Abs(view.X+view.Width/2-center.X) > 1e-9*u.Width || math.Abs(view.Y+view.Height/2-center.Y) > 1e-9*u.Width || view.Width != u.Width {
+			t.Errorf("TestCameraTrack(test %v): the view %+v is not centered on %v", test.id, view, center)
+		}
+	}
+}
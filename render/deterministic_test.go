@@ -0,0 +1,18 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the deterministic mode in nbody/deterministic.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package render
+
+import (
+	"BarnesHut/nbody"
+	"os"
+	"testing"
+)
+
+// TestMain runs every test of the package in deterministic mode, so results are stable between runs.
+func TestMain(m *testing.M) {
+	nbody.SetDeterministic()
+	os.Exit(m.Run())
+}
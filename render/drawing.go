@@ -0,0 +1,325 @@
+// Author: Yu-Lun Chen
+// Date: 2025-10-24
+// Description: Drawing functions for visualization.
+
+package render
+
+import (
+	"BarnesHut/nbody"
+	"canvas"
+	"fmt"
+	"image"
+	"math"
+	"os"
+)
+
+//AnimateSystem takes a slice of Universe objects along with a canvas width
+//parameter and a frequency parameter.
+//Every frequency steps, it generates a slice of images corresponding to drawing each Universe
+//on a canvasWidth x canvasWidth canvas.
+//A scaling factor is a final input that is used to scale the stars big enough to see them.
+func AnimateSystem(timePoints []*nbody.Universe, canvasWidth, frequency int, scalingFactor float64) []image.Image {
+	if len(timePoints) == 0 {
+		panic("Error: no Universe objects present in AnimateSystem.")
+	}
+
+	return AnimateFrames(timePoints, FrameIndices(len(timePoints), frequency), canvasWidth, scalingFactor)
+}
+
+//AnimationOutput describes how the drawn images are written: the format ("gif", "webp", or "png" for
+//numbered PNG files only), whether GIFs use delta frames, the output name without extension, the
+//directory receiving every frame as a PNG file ("" for none), and the frames per second (0 for the
+//shortest delay of every format, as in a run).
+type AnimationOutput struct {
+	Format   string
+	Delta    bool
+	FileName string
+	PNGDir   string
+	FPS      float64
+}
+
+//GIFDelay returns the delay of every GIF frame of output in 100ths of a second, at least 1.
+func (output AnimationOutput) GIFDelay() int {
+	if output.FPS <= 0 {
+		return 1
+	}
+	return max(1, int(math.Round(100/output.FPS)))
+}
+
+//WebPDelay returns the delay of every WebP frame of output in milliseconds, at least 10.
+func (output AnimationOutput) WebPDelay() int {
+	if output.FPS <= 0 {
+		return 10
+	}
+	return max(10, int(math.Round(1000/output.FPS)))
+}
+
+//Path returns the name of the animation file written for output, or the PNG directory for "png".
+func (output AnimationOutput) Path() string {
+	if output.Format == "png" {
+		return output.PNGDir
+	}
+	return output.FileName + ".out." + output.Format
+}
+
+//Paths returns the animation file and the PNG directory written for output, without duplicates.
+func (output AnimationOutput) Paths() []string {
+	if output.PNGDir == "" || output.Format == "png" {
+		return []string{output.Path()}
+	}
+	return []string{output.Path(), output.PNGDir}
+}
+
+//WritePNG writes the index-th frame into the PNG directory of output, if there is one.
+//Runs that draw their frames one by one call it for every frame; the others let WriteAnimation write them.
+func (output AnimationOutput) WritePNG(img image.Image, index int) error {
+	if output.PNGDir == "" {
+		return nil
+	}
+	return WritePNGFrame(img, output.PNGDir, index)
+}
+
+//WriteAnimation writes the images as an animation described by output, and as PNG files if requested.
+//It returns the first error writing a file.
+func WriteAnimation(images []image.Image, output AnimationOutput) error {
+	if output.PNGDir != "" {
+		if err := WritePNGFrames(images, output.PNGDir); err != nil {
+			return err
+		}
+		fmt.Fprintln(nbody.StatusOutput, "PNG frames written to", output.PNGDir)
+	}
+
+	var err error
+	switch {
+	case output.Format == "png":
+		// the PNG files are the whole output
+		return nil
+	case output.Format == "webp":
+		fmt.Fprintln(nbody.StatusOutput, "Images drawn. Now generating WebP.")
+		if err = WriteAnimatedWebP(images, output.FileName, output.WebPDelay()); err == nil {
+			fmt.Fprintln(nbody.StatusOutput, "WebP drawn.")
+		}
+	case output.Delta:
+		fmt.Fprintln(nbody.StatusOutput, "Images drawn. Now generating GIF.")
+		if err = WriteDeltaGIF(images, output.FileName, output.GIFDelay()); err == nil {
+			fmt.Fprintln(nbody.StatusOutput, "GIF drawn.")
+		}
+	default:
+		fmt.Fprintln(nbody.StatusOutput, "Images drawn. Now generating GIF.")
+		if err = WriteParallelGIF(images, output.FileName, output.GIFDelay()); err == nil {
+			fmt.Fprintln(nbody.StatusOutput, "GIF drawn.")
+		}
+	}
+	return err
+}
+
+//CreateGIFStream creates the GIF file of output and returns a GIFStreamWriter appending frames to it,
+//with delta frames if output.Delta is set, or an error if the file cannot be created.
+//The caller closes the writer, then the file.
+func (output AnimationOutput) CreateGIFStream() (*GIFStreamWriter, *os.File, error) {
+	file, err := os.Create(output.Path())
+	if err != nil {
+		return nil, nil, err
+	}
+	if output.Delta {
+		return NewDeltaGIFStreamWriter(file, output.GIFDelay()), file, nil
+	}
+	return NewGIFStreamWriter(file, output.GIFDelay()), file, nil
+}
+
+//DrawAnimation draws the Universe objects at the given indices of timePoints, in order, and writes them as
+//described by output while drawing: frames are drawn nbody.NumWorkers at a time, and every batch is written as PNG
+//files and appended to the GIF before the next one is drawn, so a GIF or PNG animation never holds more than
+//nbody.NumWorkers images however many frames it has. WebP animations are written from all the images at the end.
+//It returns the first error writing a file.
+func DrawAnimation(timePoints []*nbody.Universe, indices []int, canvasWidth int, scalingFactor float64, output AnimationOutput) error {
+	if output.Format == "webp" {
+		return WriteAnimation(AnimateFrames(timePoints, indices, canvasWidth, scalingFactor), output)
+	}
+
+	var stream *GIFStreamWriter
+	if output.Format == "gif" {
+		var file *os.File
+		var err error
+		if stream, file, err = output.CreateGIFStream(); err != nil {
+			return err
+		}
+		defer file.Close()
+	}
+
+	batch := nbody.NumWorkers
+	if batch < 1 {
+		batch = 1
+	}
+	for first := 0; first < len(indices); first += batch {
+		last := first + batch
+		if last > len(indices) {
+			last = len(indices)
+		}
+		images := AnimateFrames(timePoints, indices[first:last], canvasWidth, scalingFactor)
+		for k, img := range images {
+			if err := output.WritePNG(img, first+k); err != nil {
+				return err
+			}
+		}
+		if stream != nil {
+			if err := stream.WriteImages(images); err != nil {
+				return err
+			}
+		}
+	}
+
+	if output.PNGDir != "" {
+		fmt.Fprintln(nbody.StatusOutput, "PNG frames written to", output.PNGDir)
+	}
+	if stream != nil {
+		if err := stream.Close(); err != nil {
+			return err
+		}
+		fmt.Fprintln(nbody.StatusOutput, "GIF drawn.")
+	}
+	return nil
+}
+
+//AnimateFrames draws the Universe objects at the given indices of timePoints, in order,
+//on a canvasWidth x canvasWidth canvas and returns the images.
+//Frames are drawn on nbody.NumWorkers goroutines; a failing frame stops the others and panics on the calling goroutine.
+func AnimateFrames(timePoints []*nbody.Universe, indices []int, canvasWidth int, scalingFactor float64) []image.Image {
+	images := make([]image.Image, len(indices))
+
+	// for every selected universe, draw to canvas and grab the image
+	nbody.ParallelFor(len(indices), nbody.NumWorkers, func(k int) {
+		images[k] = DrawToCanvas(timePoints[indices[k]], canvasWidth, scalingFactor)
+		fmt.Fprintln(nbody.StatusOutput, indices[k])
+	})
+
+	return images
+}
+
+//FrameIndices returns the generations drawn with a fixed frequency: 0, frequency, 2*frequency, ...
+//below numTimePoints.
+func FrameIndices(numTimePoints, frequency int) []int {
+	indices := make([]int, 0, numTimePoints/frequency+1)
+	for i := 0; i < numTimePoints; i += frequency {
+		indices = append(indices, i)
+	}
+	return indices
+}
+
+//SavedFrameIndices returns the indices, in timePoints kept by BarnesHut for the given generations
+//(see SavedGenerations), of the generations drawn with a fixed frequency.
+func SavedFrameIndices(generations []int, frequency int) []int {
+	var indices []int
+	for k, g := range generations {
+		if g%frequency == 0 {
+			indices = append(indices, k)
+		}
+	}
+	return indices
+}
+
+//AdaptiveFrameIndices chooses the generations to draw from how much the system moved instead of a fixed count.
+//A generation is selected when some star moved more than threshold * width since the last selected generation,
+//so quiescent phases produce few frames and fast phases many.
+//If maxGap is positive, a generation is also selected when maxGap generations passed without a frame.
+//The first and last generations are always selected.
+func AdaptiveFrameIndices(timePoints []*nbody.Universe, threshold float64, maxGap int) []int {
+	selector := &FrameSelector{Threshold: threshold, MaxGap: maxGap, FinalGeneration: len(timePoints) - 1}
+
+	var indices []int
+	for i, u := range timePoints {
+		if selector.Select(i, u) {
+			indices = append(indices, i)
+		}
+	}
+
+	return indices
+}
+
+//FrameSelector decides generation by generation whether a Universe is saved and drawn,
+//so frames can be chosen while the simulation runs without keeping every generation.
+//With a positive threshold it selects like AdaptiveFrameIndices, otherwise every frequency generations.
+type FrameSelector struct {
+	Frequency       int
+	Threshold       float64
+	MaxGap          int
+	FinalGeneration int
+
+	last           *nbody.Universe
+	lastGeneration int
+}
+
+//Select reports whether generation (with Universe u) is selected. Generations must be passed in order.
+func (fs *FrameSelector) Select(generation int, u *nbody.Universe) bool {
+	if fs.Threshold <= 0 {
+		return generation%fs.Frequency == 0
+	}
+
+	selected := fs.last == nil ||
+		generation == fs.FinalGeneration ||
+		MaxDisplacement(fs.last, u) > fs.Threshold*u.Width ||
+		(fs.MaxGap > 0 && generation-fs.lastGeneration >= fs.MaxGap)
+
+	if selected {
+		fs.last = u
+		fs.lastGeneration = generation
+	}
+	return selected
+}
+
+//MaxDisplacement returns the largest distance any star moved between two Universe objects.
+//Stars are matched by their index. If stars were added or removed in between, the indices no longer match
+//and the population change itself is worth a frame, so the result is +Inf.
+func MaxDisplacement(u1, u2 *nbody.Universe) float64 {
+	if len(u1.Stars) != len(u2.Stars) {
+		return math.Inf(1)
+	}
+
+	maxD := 0.0
+	for i := range u1.Stars {
+		_, _, d := nbody.Distance(u1.Stars[i].Position, u2.Stars[i].Position)
+		if d > maxD {
+			maxD = d
+		}
+	}
+	return maxD
+}
+
+//DrawToCanvas generates the image corresponding to a canvas after drawing a Universe
+//object's bodies on a canvas that is canvasWidth pixels wide, and as high as the View needs
+//(see CanvasHeight): a square canvasWidth pixels x canvasWidth pixels for a square universe.
+//A scaling factor is needed to make the stars big enough to see them.
+//The canvas shows the View of the universe chosen by Camera, with the annotations of SelectedOverlays on top.
+//Unless Brightness is "solid", the stars are drawn with DrawAccumulated instead.
+func DrawToCanvas(u *nbody.Universe, canvasWidth int, scalingFactor float64) image.Image {
+	if u == nil {
+		panic("Can't Draw a nil Universe.")
+	}
+
+	if Brightness != "solid" {
+		return DrawOverlays(DrawAccumulated(u, canvasWidth, scalingFactor, Brightness), u, UniverseView(u))
+	}
+
+	// fmt.Println("u.width =", u.Width)
+
+	// set a new canvas with the proportions of the view
+	view := UniverseView(u)
+	canvasHeight := view.CanvasHeight(canvasWidth)
+	c := canvas.CreateNewCanvas(canvasWidth, canvasHeight)
+
+	// create a black background
+	c.SetFillColor(canvas.MakeColor(0, 0, 0))
+	c.ClearRect(0, 0, canvasWidth, canvasHeight)
+	c.Fill()
+
+	// range over all the bodies and draw them; stars keep their size whatever the view
+	for _, b := range u.Stars {
+		c.SetFillColor(canvas.MakeColor(StarColor(b)))
+		cx, cy := view.CanvasPoint(b.Position, canvasWidth)
+		r := scalingFactor * (b.Radius / u.Width) * float64(canvasWidth)
+		c.Circle(cx, cy, r)
+		c.Fill()
+	}
+	// we want to return an image, with the annotations of -overlays
+	return DrawOverlays(c.GetImage(), u, view)
+}
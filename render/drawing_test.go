@@ -0,0 +1,114 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for choosing the frames of an animation in drawing.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package render
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type AdaptiveFrameIndicesTestCases struct {
+	id        string
+	universes []*nbody.Universe
+	threshold float64
+	maxGap    int
+	frequency int
+	expected  []int
+}
+
+
+// ReadAdaptiveFrameIndices reads test data for AdaptiveFrameIndices and FrameSelector from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of AdaptiveFrameIndicesTestCases structs containing the generations and the expected frames.
+func ReadAdaptiveFrameIndices(fileName string) []AdaptiveFrameIndicesTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []AdaptiveFrameIndicesTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 6 {
+			continue
+		}
+
+		var universes []*nbody.Universe
+		for _, generation := range strings.Fields(parts[1]) {
+			u := &nbody.Universe{Width: 100}
+			for _, field := range strings.Split(generation, ",") {
+				x, err := strconv.ParseFloat(field, 64)
+				nbody.Check(err)
+				u.Stars = append(u.Stars, &nbody.Star{Position: nbody.OrderedPair{X: x, Y: 50}, Mass: 1})
+			}
+			universes = append(universes, u)
+		}
+		threshold, err := strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+		nbody.Check(err)
+		maxGap, err := strconv.Atoi(strings.TrimSpace(parts[3]))
+		nbody.Check(err)
+		frequency, err := strconv.Atoi(strings.TrimSpace(parts[4]))
+		nbody.Check(err)
+		var expected []int
+		for _, field := range strings.Fields(parts[5]) {
+			index, err := strconv.Atoi(field)
+			nbody.Check(err)
+			expected = append(expected, index)
+		}
+
+		tests = append(tests, AdaptiveFrameIndicesTestCases{
+			id:        strings.TrimSpace(parts[0]),
+			universes: universes,
+			threshold: threshold,
+			maxGap:    maxGap,
+			frequency: frequency,
+			expected:  expected,
+		})
+	}
+
+	return tests
+}
+
+
+// TestAdaptiveFrameIndices tests that the frames are chosen from the motion of the stars, with the frames forced
+// by the largest gap and the last generation, and that FrameSelector chooses the same frames one generation at a
+// time.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestAdaptiveFrameIndices(t *testing.T) {
+	tests := ReadAdaptiveFrameIndices("../Tests/AdaptiveFrameIndices.txt")
+
+	for _, test := range tests {
+		if test.threshold > 0 {
+			if result := AdaptiveFrameIndices(test.universes, test.threshold, test.maxGap); !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("TestAdaptiveFrameIndices(test %v) = %v, want %v", test.id, result, test.expected)
+			}
+		}
+
+		selector := &FrameSelector{Frequency: test.frequency, Threshold: test.threshold, MaxGap: test.maxGap,
+			FinalGeneration: len(test.universes) - 1}
+		var selected []int
+		for generation, u := range test.universes {
+			if selector.Select(generation, u) {
+				selected = append(selected, generation)
+			}
+		}
+		if !reflect.DeepEqual(selected, test.expected) {
+			t.Errorf("TestAdaptiveFrameIndices(test %v) FrameSelector chose %v, want %v", test.id, selected, test.expected)
+		}
+	}
+}
@@ -0,0 +1,649 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: GIF encoding with per-frame palettes, delta frames, and parallel frame compression.
+
+package render
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"bytes"
+	"compress/lzw"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"os"
+)
+
+// DeltaPalette returns the palette used by WriteDeltaGIF: the 216 web-safe colors
+// plus a fully transparent color, which marks pixels that did not change since the previous frame.
+func DeltaPalette() color.Palette {
+	p := make(color.Palette, 0, len(palette.WebSafe)+1)
+	p = append(p, palette.WebSafe...)
+	p = append(p, color.RGBA{0, 0, 0, 0})
+	return p
+}
+
+
+// ImageToPaletted converts an image into a paletted image using the colors of p.
+func ImageToPaletted(img image.Image, p color.Palette) *image.Paletted {
+	bounds := img.Bounds()
+	paletted := image.NewPaletted(bounds, p)
+	draw.Draw(paletted, bounds, img, bounds.Min, draw.Src)
+	return paletted
+}
+
+
+// ChangedBounds returns the smallest rectangle containing every pixel that differs between two paletted
+// images of the same size, and false if the images are identical.
+func ChangedBounds(previous, current *image.Paletted) (image.Rectangle, bool) {
+	bounds := current.Bounds()
+	minX, minY := bounds.Max.X, bounds.Max.Y
+	maxX, maxY := bounds.Min.X-1, bounds.Min.Y-1
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if previous.ColorIndexAt(x, y) != current.ColorIndexAt(x, y) {
+				if x < minX {
+					minX = x
+				}
+				if x > maxX {
+					maxX = x
+				}
+				if y < minY {
+					minY = y
+				}
+				if y > maxY {
+					maxY = y
+				}
+			}
+		}
+	}
+
+	if maxX < minX {
+		return image.Rectangle{}, false
+	}
+	return image.Rect(minX, minY, maxX+1, maxY+1), true
+}
+
+
+// DeltaFrames converts a sequence of images into GIF frames that only contain what changed.
+// The first frame is stored in full. Every later frame is cropped to the bounding box of the pixels that
+// changed since the previous image, and unchanged pixels inside that box are transparent; the frame is
+// drawn on top of the previous one (no disposal). Images identical to the previous one are not stored,
+// instead the delay of the previous frame is extended.
+// Input:
+//   - images: slice of images of the same size.
+//   - delay: delay of each image in 100ths of a second.
+// Output:
+//   - a gif.GIF ready to be encoded.
+func DeltaFrames(images []image.Image, delay int) *gif.GIF {
+	p := DeltaPalette()
+	g := &gif.GIF{}
+	if len(images) == 0 {
+		return g
+	}
+
+	bounds := images[0].Bounds()
+	g.Config = image.Config{ColorModel: p, Width: bounds.Dx(), Height: bounds.Dy()}
+
+	// quantizing is independent for every image; only the differences need the previous frame
+	paletted := make([]*image.Paletted, len(images))
+	nbody.ParallelFor(len(images), nbody.NumWorkers, func(i int) {
+		paletted[i] = ImageToPaletted(images[i], p)
+	})
+
+	var previous *image.Paletted
+	for _, current := range paletted {
+		if previous == nil {
+			g.Image = append(g.Image, current)
+			g.Delay = append(g.Delay, delay)
+			g.Disposal = append(g.Disposal, gif.DisposalNone)
+			previous = current
+			continue
+		}
+
+		frame, ok := DeltaFrame(previous, current)
+		if !ok {
+			g.Delay[len(g.Delay)-1] += delay
+			continue
+		}
+
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, delay)
+		g.Disposal = append(g.Disposal, gif.DisposalNone)
+		previous = current
+	}
+
+	return g
+}
+
+
+// DeltaFrame returns the frame that turns previous into current when drawn on top of it: current cropped to
+// the bounding box of the changed pixels, with the unchanged pixels inside the box set to the transparent color
+// (the last color of the palette), and false if the images are identical.
+// Input:
+//   - previous, current: paletted images of the same size with the palette of DeltaPalette.
+// Output:
+//   - the delta frame, and whether any pixel changed.
+func DeltaFrame(previous, current *image.Paletted) (*image.Paletted, bool) {
+	changed, ok := ChangedBounds(previous, current)
+	if !ok {
+		return nil, false
+	}
+
+	transparent := uint8(len(current.Palette) - 1)
+	frame := image.NewPaletted(changed, current.Palette)
+	for y := changed.Min.Y; y < changed.Max.Y; y++ {
+		for x := changed.Min.X; x < changed.Max.X; x++ {
+			idx := current.ColorIndexAt(x, y)
+			if idx == previous.ColorIndexAt(x, y) {
+				idx = transparent
+			}
+			frame.SetColorIndex(x, y, idx)
+		}
+	}
+	return frame, true
+}
+
+
+// WriteDeltaGIF writes a sequence of images as an animated GIF using delta frames (see DeltaFrames).
+// Like gifhelper.ImagesToGIF, the output file is named fileName + ".out.gif".
+// Input:
+//   - images: slice of images of the same size.
+//   - fileName: output name without extension.
+//   - delay: delay of each image in 100ths of a second.
+// Output:
+//   - an error if the file cannot be written.
+func WriteDeltaGIF(images []image.Image, fileName string, delay int) error {
+	return writeGIFFile(DeltaFrames(images, delay), fileName)
+}
+
+
+// WriteParallelGIF writes a sequence of images as an animated GIF named fileName + ".out.gif".
+// Every frame gets its own palette (see QuantizeFrame), and palettes and compression are computed
+// for several frames at once on nbody.NumWorkers goroutines.
+// Input:
+//   - images: slice of images of the same size.
+//   - fileName: output name without extension.
+//   - delay: delay of each image in 100ths of a second.
+// Output:
+//   - an error if the file cannot be written.
+func WriteParallelGIF(images []image.Image, fileName string, delay int) error {
+	return writeGIFFile(PaletteFrames(images, delay), fileName)
+}
+
+
+// writeGIFFile encodes g into fileName + ".out.gif".
+func writeGIFFile(g *gif.GIF, fileName string) error {
+	file, err := os.Create(fileName + ".out.gif")
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	err = EncodeGIF(w, g, nbody.NumWorkers)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+
+// FramePalette returns the exact colors of an image if there are at most GIFColors of them
+// (renders of the universe usually have only a handful), and the Plan9 palette otherwise.
+func FramePalette(img image.Image) color.Palette {
+	bounds := img.Bounds()
+	seen := make(map[color.RGBA]bool)
+	var p color.Palette
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			if !seen[c] {
+				if len(p) == GIFColors {
+					return palette.Plan9
+				}
+				seen[c] = true
+				p = append(p, c)
+			}
+		}
+	}
+
+	return p
+}
+
+
+// PaletteFrames quantizes every image with its own palette (see QuantizeFrame), in parallel.
+// Input:
+//   - images: slice of images of the same size.
+//   - delay: delay of each image in 100ths of a second.
+// Output:
+//   - a gif.GIF ready to be encoded.
+func PaletteFrames(images []image.Image, delay int) *gif.GIF {
+	g := &gif.GIF{
+		Image: make([]*image.Paletted, len(images)),
+		Delay: make([]int, len(images)),
+	}
+
+	nbody.ParallelFor(len(images), nbody.NumWorkers, func(i int) {
+		g.Image[i] = QuantizeFrame(images[i])
+		g.Delay[i] = delay
+	})
+
+	if len(images) > 0 {
+		bounds := images[0].Bounds()
+		g.Config = image.Config{Width: bounds.Dx(), Height: bounds.Dy()}
+	}
+
+	return g
+}
+
+
+
+
+//// GIF writer with parallel frame compression ////
+
+// gifFrame is one frame whose pixels are already compressed, ready to be appended to a GIF file.
+type gifFrame struct {
+	rect        image.Rectangle
+	palette     color.Palette // local color table, nil when the global palette is used
+	transparent int           // index of the transparent color, -1 if none
+	delay       int
+	disposal    byte
+	data        []byte // LZW minimum code size followed by the data sub-blocks
+}
+
+
+// paletteBits returns the number of bits needed to index a palette (at least 1).
+func paletteBits(p color.Palette) int {
+	bits := 1
+	for 1<<uint(bits) < len(p) {
+		bits++
+	}
+	return bits
+}
+
+
+// writeColorTable writes a palette padded to 2^bits entries.
+func writeColorTable(w io.Writer, p color.Palette, bits int) error {
+	table := make([]byte, 3<<uint(bits))
+	for i, c := range p {
+		r, g, b, _ := c.RGBA()
+		table[3*i] = byte(r >> 8)
+		table[3*i+1] = byte(g >> 8)
+		table[3*i+2] = byte(b >> 8)
+	}
+	_, err := w.Write(table)
+	return err
+}
+
+
+// transparentIndex returns the index of the first fully transparent color of a palette, or -1.
+func transparentIndex(p color.Palette) int {
+	for i, c := range p {
+		if _, _, _, a := c.RGBA(); a == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+
+// blockWriter splits a byte stream into GIF data sub-blocks of at most 255 bytes.
+type blockWriter struct {
+	out *bytes.Buffer
+	buf []byte
+}
+
+
+func (b *blockWriter) Write(p []byte) (int, error) {
+	for _, c := range p {
+		b.buf = append(b.buf, c)
+		if len(b.buf) == 255 {
+			b.flush()
+		}
+	}
+	return len(p), nil
+}
+
+
+func (b *blockWriter) flush() {
+	if len(b.buf) == 0 {
+		return
+	}
+	b.out.WriteByte(byte(len(b.buf)))
+	b.out.Write(b.buf)
+	b.buf = b.buf[:0]
+}
+
+
+// CompressGIFFrame LZW-compresses one paletted frame into its GIF data sub-blocks.
+// Input:
+//   - p: the frame.
+//   - global: the global palette; when p uses another palette it is stored as a local color table.
+//   - delay: delay of the frame in 100ths of a second.
+//   - disposal: GIF disposal method of the frame.
+// Output:
+//   - the compressed frame, or an error if a pixel refers to a color outside the palette.
+func CompressGIFFrame(p *image.Paletted, global color.Palette, delay int, disposal byte) (gifFrame, error) {
+	frame := gifFrame{rect: p.Rect, delay: delay, disposal: disposal}
+
+	if !samePalette(p.Palette, global) {
+		frame.palette = p.Palette
+	}
+	frame.transparent = transparentIndex(p.Palette)
+
+	litWidth := paletteBits(p.Palette)
+	if litWidth < 2 {
+		litWidth = 2
+	}
+
+	var out bytes.Buffer
+	out.WriteByte(byte(litWidth))
+
+	bw := &blockWriter{out: &out}
+	lw := lzw.NewWriter(bw, lzw.LSB, litWidth)
+	width := p.Rect.Dx()
+	for y := 0; y < p.Rect.Dy(); y++ {
+		row := p.Pix[y*p.Stride : y*p.Stride+width]
+		// the LZW writer only rejects indices that do not fit its code width, decoders any outside the palette
+		for x, index := range row {
+			if int(index) >= len(p.Palette) {
+				return frame, fmt.Errorf("compressing GIF frame: pixel (%d, %d) has color index %d outside a palette of %d colors",
+					p.Rect.Min.X+x, p.Rect.Min.Y+y, index, len(p.Palette))
+			}
+		}
+		if _, err := lw.Write(row); err != nil {
+			return frame, fmt.Errorf("compressing GIF frame: %w", err)
+		}
+	}
+	if err := lw.Close(); err != nil {
+		return frame, fmt.Errorf("compressing GIF frame: %w", err)
+	}
+	bw.flush()
+	out.WriteByte(0)
+
+	frame.data = out.Bytes()
+	return frame, nil
+}
+
+
+// samePalette reports whether two palettes hold the same colors in the same order.
+func samePalette(a, b color.Palette) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		r1, g1, b1, a1 := a[i].RGBA()
+		r2, g2, b2, a2 := b[i].RGBA()
+		if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+			return false
+		}
+	}
+	return true
+}
+
+
+// EncodeGIF writes an animated GIF, compressing the frames on several goroutines
+// and then writing them in order, so the output does not depend on the number of workers.
+// The global palette is g.Config.ColorModel when it is a color.Palette; frames using another
+// palette get a local color table. The animation loops forever.
+// Input:
+//   - w: destination.
+//   - g: frames, delays, and disposal methods (as for gif.EncodeAll).
+//   - workers: number of goroutines compressing frames.
+// Output:
+//   - an error if writing fails.
+func EncodeGIF(w io.Writer, g *gif.GIF, workers int) error {
+	if len(g.Image) == 0 {
+		return nil
+	}
+
+	global, _ := g.Config.ColorModel.(color.Palette)
+	width, height := g.Config.Width, g.Config.Height
+	if width == 0 || height == 0 {
+		width, height = g.Image[0].Rect.Dx(), g.Image[0].Rect.Dy()
+	}
+
+	// the first failing frame stops the other workers and its error is returned
+	frames := make([]gifFrame, len(g.Image))
+	err := nbody.ParallelForErr(len(g.Image), workers, func(i int) error {
+		var disposal byte
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		var err error
+		frames[i], err = CompressGIFFrame(g.Image[i], global, g.Delay[i], disposal)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return WriteGIFFrames(w, width, height, global, frames)
+}
+
+
+// WriteGIFFrames writes the GIF header, the already compressed frames, and the trailer.
+func WriteGIFFrames(w io.Writer, width, height int, global color.Palette, frames []gifFrame) error {
+	if err := writeGIFHeader(w, width, height, global); err != nil {
+		return err
+	}
+
+	for _, f := range frames {
+		if err := writeGIFFrame(w, f); err != nil {
+			return err
+		}
+	}
+
+	_, err := w.Write([]byte{0x3b})
+	return err
+}
+
+
+// writeGIFHeader writes the signature, the logical screen descriptor with the optional global palette,
+// and the extension that makes the animation loop forever.
+func writeGIFHeader(w io.Writer, width, height int, global color.Palette) error {
+	var header bytes.Buffer
+	header.WriteString("GIF89a")
+	binary.Write(&header, binary.LittleEndian, uint16(width))
+	binary.Write(&header, binary.LittleEndian, uint16(height))
+
+	if len(global) > 0 {
+		bits := paletteBits(global)
+		header.WriteByte(0x80 | byte(bits-1)<<4 | byte(bits-1))
+		header.WriteByte(0)
+		header.WriteByte(0)
+		writeColorTable(&header, global, bits)
+	} else {
+		header.WriteByte(0)
+		header.WriteByte(0)
+		header.WriteByte(0)
+	}
+
+	// NETSCAPE2.0 application extension: loop forever
+	header.Write([]byte{0x21, 0xff, 0x0b})
+	header.WriteString("NETSCAPE2.0")
+	header.Write([]byte{0x03, 0x01, 0x00, 0x00, 0x00})
+
+	_, err := w.Write(header.Bytes())
+	return err
+}
+
+
+// GIFStreamWriter encodes an animated GIF one image at a time, so frames can be written as soon as
+// they are drawn instead of keeping the whole animation in memory. Every frame gets its own palette,
+// or, for a delta writer, the frames are the delta frames of WriteDeltaGIF. Either way the file is the
+// same as the one WriteParallelGIF or WriteDeltaGIF writes from all the images at once.
+type GIFStreamWriter struct {
+	w       io.Writer
+	delay   int
+	started bool
+
+	// delta frames need the previous image, and the last frame waits for the next image in case
+	// that one is identical and only extends its delay
+	delta    bool
+	global   color.Palette
+	previous *image.Paletted
+	pending  *gifFrame
+}
+
+
+// NewGIFStreamWriter returns a GIFStreamWriter writing to w with the given delay (in 100ths of a second).
+func NewGIFStreamWriter(w io.Writer, delay int) *GIFStreamWriter {
+	return &GIFStreamWriter{w: w, delay: delay}
+}
+
+
+// NewDeltaGIFStreamWriter returns a GIFStreamWriter writing delta frames (see DeltaFrames) to w
+// with the given delay (in 100ths of a second).
+func NewDeltaGIFStreamWriter(w io.Writer, delay int) *GIFStreamWriter {
+	return &GIFStreamWriter{w: w, delay: delay, delta: true, global: DeltaPalette()}
+}
+
+
+// WriteImage quantizes, compresses, and appends one image. The first image sets the size of the animation.
+func (s *GIFStreamWriter) WriteImage(img image.Image) error {
+	return s.WriteImages([]image.Image{img})
+}
+
+
+// WriteImages appends several images in order, like calling WriteImage for each of them. The images are
+// quantized, and without delta frames also compressed, on nbody.NumWorkers goroutines.
+func (s *GIFStreamWriter) WriteImages(images []image.Image) error {
+	if len(images) == 0 {
+		return nil
+	}
+	if !s.started {
+		bounds := images[0].Bounds()
+		if err := writeGIFHeader(s.w, bounds.Dx(), bounds.Dy(), s.global); err != nil {
+			return err
+		}
+		s.started = true
+	}
+
+	if !s.delta {
+		frames := make([]gifFrame, len(images))
+		err := nbody.ParallelForErr(len(images), nbody.NumWorkers, func(i int) error {
+			var err error
+			frames[i], err = CompressGIFFrame(QuantizeFrame(images[i]), nil, s.delay, 0)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		for _, f := range frames {
+			if err := writeGIFFrame(s.w, f); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	paletted := make([]*image.Paletted, len(images))
+	nbody.ParallelFor(len(images), nbody.NumWorkers, func(i int) {
+		paletted[i] = ImageToPaletted(images[i], s.global)
+	})
+	for _, current := range paletted {
+		if err := s.appendDelta(current); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+
+// appendDelta appends the delta frame of one quantized image. The previous frame is written once it is
+// known that current differs from it.
+func (s *GIFStreamWriter) appendDelta(current *image.Paletted) error {
+	frame := current
+	if s.previous != nil {
+		var ok bool
+		frame, ok = DeltaFrame(s.previous, current)
+		if !ok {
+			s.pending.delay += s.delay
+			return nil
+		}
+	}
+
+	compressed, err := CompressGIFFrame(frame, s.global, s.delay, gif.DisposalNone)
+	if err != nil {
+		return err
+	}
+	if err := s.flush(); err != nil {
+		return err
+	}
+	s.pending = &compressed
+	s.previous = current
+	return nil
+}
+
+
+// flush writes the frame waiting for the next image, if there is one.
+func (s *GIFStreamWriter) flush() error {
+	if s.pending == nil {
+		return nil
+	}
+	err := writeGIFFrame(s.w, *s.pending)
+	s.pending = nil
+	return err
+}
+
+
+// Close writes the last frame and the GIF trailer. It does not close the underlying writer.
+func (s *GIFStreamWriter) Close() error {
+	if !s.started {
+		return nil
+	}
+	if err := s.flush(); err != nil {
+		return err
+	}
+	_, err := s.w.Write([]byte{0x3b})
+	return err
+}
+
+
+// writeGIFFrame writes the graphic control extension, image descriptor, and data of one frame.
+func writeGIFFrame(w io.Writer, f gifFrame) error {
+	var b bytes.Buffer
+
+	// graphic control extension
+	flags := f.disposal << 2
+	transparent := byte(0)
+	if f.transparent >= 0 {
+		flags |= 1
+		transparent = byte(f.transparent)
+	}
+	b.Write([]byte{0x21, 0xf9, 0x04, flags})
+	binary.Write(&b, binary.LittleEndian, uint16(f.delay))
+	b.Write([]byte{transparent, 0x00})
+
+	// image descriptor
+	b.WriteByte(0x2c)
+	binary.Write(&b, binary.LittleEndian, uint16(f.rect.Min.X))
+	binary.Write(&b, binary.LittleEndian, uint16(f.rect.Min.Y))
+	binary.Write(&b, binary.LittleEndian, uint16(f.rect.Dx()))
+	binary.Write(&b, binary.LittleEndian, uint16(f.rect.Dy()))
+	if f.palette != nil {
+		bits := paletteBits(f.palette)
+		b.WriteByte(0x80 | byte(bits-1))
+		writeColorTable(&b, f.palette, bits)
+	} else {
+		b.WriteByte(0)
+	}
+
+	if _, err := w.Write(b.Bytes()); err != nil {
+		return err
+	}
+	_, err := w.Write(f.data)
+	return err
+}
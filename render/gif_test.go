@@ -0,0 +1,398 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for GIF encoding in gif.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package render
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type GIFStreamWriterTestCases struct {
+	id        string
+	numFrames int
+	width     int
+	height    int
+	hold      int
+	delta     bool
+	batch     int
+}
+
+type EncodeGIFTestCases struct {
+	id        string
+	noise     bool
+	numFrames int
+	width     int
+	height    int
+	delta     bool
+	valid     bool
+	workers   []int
+}
+
+type ChangedBoundsTestCases struct {
+	id       string
+	bounds   image.Rectangle
+	changed  []image.Point
+	expected image.Rectangle
+	differ   bool
+}
+
+
+// ReadGIFStreamWriter reads test data for GIFStreamWriter from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of GIFStreamWriterTestCases structs containing the frames and how they are written.
+func ReadGIFStreamWriter(fileName string) []GIFStreamWriterTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []GIFStreamWriterTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 7 {
+			continue
+		}
+
+		var numbers [4]int
+		for i := range numbers {
+			numbers[i], err = strconv.Atoi(strings.TrimSpace(parts[i+1]))
+			nbody.Check(err)
+		}
+		test := GIFStreamWriterTestCases{
+			id:        strings.TrimSpace(parts[0]),
+			numFrames: numbers[0],
+			width:     numbers[1],
+			height:    numbers[2],
+			hold:      numbers[3],
+		}
+		test.delta, err = strconv.ParseBool(strings.TrimSpace(parts[5]))
+		nbody.Check(err)
+		test.batch, err = strconv.Atoi(strings.TrimSpace(parts[6]))
+		nbody.Check(err)
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// gifTestFrames returns numFrames images of a square moving over a background, each repeated hold times.
+func gifTestFrames(numFrames, width, height, hold int) []image.Image {
+	images := make([]image.Image, 0, numFrames*hold)
+	for k := 0; k < numFrames; k++ {
+		img := image.NewRGBA(image.Rect(0, 0, width, height))
+		for x := 0; x < width; x++ {
+			for y := 0; y < height; y++ {
+				c := color.RGBA{0, 0, 51, 255}
+				if (x-k)%width < 3 && (y+k)%height < 3 {
+					c = color.RGBA{255, 204, uint8(k * 51), 255}
+				}
+				img.Set(x, y, c)
+			}
+		}
+		for i := 0; i < hold; i++ {
+			images = append(images, img)
+		}
+	}
+	return images
+}
+
+
+// TestGIFStreamWriter tests that a GIF written image by image, in batches of several images, is byte for byte the
+// file EncodeGIF writes from all the images at once, with per-frame palettes and with delta frames, and that it
+// decodes to the same number of frames and delays.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestGIFStreamWriter(t *testing.T) {
+	tests := ReadGIFStreamWriter("../Tests/GIFStreamWriter.txt")
+
+	for _, test := range tests {
+		images := gifTestFrames(test.numFrames, test.width, test.height, test.hold)
+
+		var expected bytes.Buffer
+		g := PaletteFrames(images, 1)
+		if test.delta {
+			g = DeltaFrames(images, 1)
+		}
+		nbody.Check(EncodeGIF(&expected, g, nbody.NumWorkers))
+
+		var result bytes.Buffer
+		stream := NewGIFStreamWriter(&result, 1)
+		if test.delta {
+			stream = NewDeltaGIFStreamWriter(&result, 1)
+		}
+		for first := 0; first < len(images); first += test.batch {
+			last := first + test.batch
+			if last > len(images) {
+				last = len(images)
+			}
+			nbody.Check(stream.WriteImages(images[first:last]))
+		}
+		nbody.Check(stream.Close())
+
+		if !bytes.Equal(result.Bytes(), expected.Bytes()) {
+			at := 0
+			for at < result.Len() && at < expected.Len() && result.Bytes()[at] == expected.Bytes()[at] {
+				at++
+			}
+			t.Errorf("TestGIFStreamWriter(test %v) differs from EncodeGIF at byte %d (%d bytes, want %d)", test.id, at, result.Len(), expected.Len())
+			continue
+		}
+		if len(images) == 0 {
+			continue
+		}
+
+		decoded, err := gif.DecodeAll(&result)
+		if err != nil {
+			t.Errorf("TestGIFStreamWriter(test %v) = error %v decoding, want none", test.id, err)
+			continue
+		}
+		total := 0
+		for _, delay := range decoded.Delay {
+			total += delay
+		}
+		if total != len(images) || len(decoded.Image) != len(g.Image) {
+			t.Errorf("TestGIFStreamWriter(test %v) = %d frames lasting %d, want %d frames lasting %d", test.id, len(decoded.Image), total, len(g.Image), len(images))
+		}
+	}
+}
+
+
+// ReadEncodeGIF reads test data for PaletteFrames and EncodeGIF from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of EncodeGIFTestCases structs containing the animations and the numbers of workers.
+func ReadEncodeGIF(fileName string) []EncodeGIFTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []EncodeGIFTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 8 {
+			continue
+		}
+
+		size := ReadIntFields(strings.Join(parts[2:5], "|"), "|")
+		test := EncodeGIFTestCases{
+			id:        strings.TrimSpace(parts[0]),
+			noise:     strings.TrimSpace(parts[1]) == "noise",
+			numFrames: size[0],
+			width:     size[1],
+			height:    size[2],
+			workers:   ReadIntFields(strings.Join(strings.Fields(parts[7]), ","), ","),
+		}
+		test.delta, err = strconv.ParseBool(strings.TrimSpace(parts[5]))
+		nbody.Check(err)
+		test.valid, err = strconv.ParseBool(strings.TrimSpace(parts[6]))
+		nbody.Check(err)
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// gifNoiseFrames returns numFrames images of pseudo-random pixels in 16 colors, which compress poorly.
+func gifNoiseFrames(numFrames, width, height int) []image.Image {
+	images := make([]image.Image, numFrames)
+	v := uint32(1)
+	for k := range images {
+		img := image.NewRGBA(image.Rect(0, 0, width, height))
+		for i := 0; i < len(img.Pix); i += 4 {
+			v = v*1103515245 + 12345
+			c := uint8(v >> 16 % 16)
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c*16, 255-c*16, c*8, 255
+		}
+		images[k] = img
+	}
+	return images
+}
+
+
+// TestEncodeGIF tests that the frames quantized by PaletteFrames and compressed by EncodeGIF on several goroutines
+// give the same bytes for every number of workers, that the file decodes to the images with their delays, and that
+// a pixel outside the palette of a frame is an error for every number of workers.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestEncodeGIF(t *testing.T) {
+	tests := ReadEncodeGIF("../Tests/EncodeGIF.txt")
+	oldWorkers := nbody.NumWorkers
+	defer func() {
+		nbody.NumWorkers = oldWorkers
+	}()
+
+	for _, test := range tests {
+		images := gifTestFrames(test.numFrames, test.width, test.height, 1)
+		if test.noise {
+			images = gifNoiseFrames(test.numFrames, test.width, test.height)
+		}
+
+		var expected []byte
+		var g *gif.GIF
+		for _, workers := range test.workers {
+			// the palettes of PaletteFrames are quantized in parallel too
+			nbody.NumWorkers = workers
+			g = PaletteFrames(images, 1)
+			if test.delta {
+				g = DeltaFrames(images, 1)
+			}
+			if !test.valid {
+				last := g.Image[len(g.Image)-1]
+				last.Pix[len(last.Pix)/2] = uint8(len(last.Palette))
+			}
+
+			var output bytes.Buffer
+			err := EncodeGIF(&output, g, workers)
+			switch {
+			case !test.valid && err == nil:
+				t.Errorf("TestEncodeGIF(test %v, %d workers) = no error, want one for a pixel outside the palette", test.id, workers)
+			case test.valid && err != nil:
+				t.Errorf("TestEncodeGIF(test %v, %d workers) failed: %v", test.id, workers, err)
+			case test.valid && expected == nil:
+				expected = output.Bytes()
+			case test.valid && !bytes.Equal(output.Bytes(), expected):
+				t.Errorf("TestEncodeGIF(test %v, %d workers) wrote %d bytes different from %d workers (%d bytes)",
+					test.id, workers, output.Len(), test.workers[0], len(expected))
+			}
+		}
+		if expected == nil {
+			continue
+		}
+
+		decoded, err := gif.DecodeAll(bytes.NewReader(expected))
+		if err != nil {
+			t.Errorf("TestEncodeGIF(test %v) = error %v decoding, want none", test.id, err)
+			continue
+		}
+		if len(decoded.Image) != len(g.Image) || !reflect.DeepEqual(decoded.Delay, g.Delay) {
+			t.Errorf("TestEncodeGIF(test %v) decodes to %d frames with delays %v, want %d with %v",
+				test.id, len(decoded.Image), decoded.Delay, len(g.Image), g.Delay)
+			continue
+		}
+
+		// every frame is drawn over the previous ones and shows the image of its time, in the web-safe colors of
+		// DeltaPalette for delta frames
+		screen := image.NewRGBA(images[0].Bounds())
+		index := 0
+		for i, frame := range decoded.Image {
+			draw.Draw(screen, frame.Bounds(), frame, frame.Bounds().Min, draw.Over)
+			want := images[index]
+			if test.delta {
+				want = ImageToPaletted(want, DeltaPalette())
+			}
+			if !reflect.DeepEqual(screen.Pix, RGBAPixels(want)) {
+				t.Errorf("TestEncodeGIF(test %v, frame %d) differs from image %d", test.id, i, index)
+			}
+			index += decoded.Delay[i]
+		}
+	}
+}
+
+
+// RGBAPixels returns the pixels of an image in the layout of image.RGBA.
+func RGBAPixels(img image.Image) []uint8 {
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	return rgba.Pix
+}
+
+
+// readRect parses a rectangle given as "min_x,min_y,max_x,max_y".
+func readRect(text string) image.Rectangle {
+	values := ReadIntFields(text, ",")
+	return image.Rect(values[0], values[1], values[2], values[3])
+}
+
+
+// ReadChangedBounds reads test data for ChangedBounds from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of ChangedBoundsTestCases structs containing the images, their changed pixels, and the expected bounds.
+func ReadChangedBounds(fileName string) []ChangedBoundsTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []ChangedBoundsTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		test := ChangedBoundsTestCases{id: strings.TrimSpace(parts[0]), bounds: readRect(parts[1])}
+		if changed := strings.TrimSpace(parts[2]); changed != "none" {
+			for _, pixel := range strings.Split(changed, ";") {
+				values := ReadIntFields(pixel, ",")
+				test.changed = append(test.changed, image.Pt(values[0], values[1]))
+			}
+		}
+		if expected := strings.TrimSpace(parts[3]); expected != "none" {
+			test.expected, test.differ = readRect(expected), true
+		}
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestChangedBounds tests that ChangedBounds returns the smallest rectangle holding the changed pixels, also at
+// the edges of the images and for images not starting at the origin, and reports identical images.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestChangedBounds(t *testing.T) {
+	tests := ReadChangedBounds("../Tests/ChangedBounds.txt")
+	palette := color.Palette{color.Black, color.White}
+
+	for _, test := range tests {
+		previous := image.NewPaletted(test.bounds, palette)
+		current := image.NewPaletted(test.bounds, palette)
+		for _, pixel := range test.changed {
+			current.SetColorIndex(pixel.X, pixel.Y, 1)
+		}
+
+		result, differ := ChangedBounds(previous, current)
+		if result != test.expected || differ != test.differ {
+			t.Errorf("TestChangedBounds(test %v) = (%v, %v), want (%v, %v)", test.id, result, differ, test.expected, test.differ)
+		}
+		// the comparison is symmetric
+		if reverse, _ := ChangedBounds(current, previous); reverse != result {
+			t.Errorf("TestChangedBounds(test %v) = %v with the images swapped, want %v", test.id, reverse, result)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Annotations drawn on top of every frame: simulated time, scale bar, and color legend (option "-overlays").
+
+package render
+
+import (
+	"BarnesHut/nbody"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strings"
+)
+
+// Overlays selects the annotations DrawToCanvas adds to every frame, set from the -overlays option.
+//   - time: the simulated time of the frame in the top left corner, e.g. "t = 1.25 Myr".
+//   - scale: a bar of a round length, e.g. "200 kpc", in the bottom left corner, measured in the view of the frame.
+//   - legend: the meaning of the colors in the top right corner, for -age-colors and -color-origin.
+type Overlays struct {
+	time, scale, legend bool
+}
+
+var SelectedOverlays Overlays
+
+// LegendGalaxies is the number of galaxies colored by -color-origin, listed in the legend (0 for none).
+var LegendGalaxies = 0
+
+
+// ParseOverlays parses a comma separated list of overlays, e.g. "time,scale,legend".
+func ParseOverlays(list string) (Overlays, error) {
+	var o Overlays
+	for _, name := range strings.Split(list, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "time":
+			o.time = true
+		case "scale":
+			o.scale = true
+		case "legend":
+			o.legend = true
+		default:
+			return o, fmt.Errorf("unknown overlay %q (use time, scale, or legend)", name)
+		}
+	}
+	return o, nil
+}
+
+
+// overlayUnit is a unit a time or length is printed in.
+type overlayUnit struct {
+	name  string
+	value float64 // in seconds or meters
+}
+
+var timeUnits = []overlayUnit{{"s", 1}, {"d", 86400}, {"yr", 3.15576e7}, {"kyr", 3.15576e10}, {"Myr", 3.15576e13}, {"Gyr", 3.15576e16}}
+var scaleUnits = []overlayUnit{{"m", 1}, {"km", nbody.LengthUnits["km"]}, {"AU", nbody.LengthUnits["AU"]}, {"pc", nbody.LengthUnits["pc"]}, {"kpc", nbody.LengthUnits["kpc"]}}
+
+
+// largestUnit returns the largest unit that is at most x, or the smallest unit.
+func largestUnit(units []overlayUnit, x float64) overlayUnit {
+	best := units[0]
+	for _, u := range units {
+		if u.value <= x {
+			best = u
+		}
+	}
+	return best
+}
+
+
+// FormatTime prints a time in seconds with three significant digits in the largest unit it is at least one of,
+// from seconds to gigayears, e.g. "12.5 Myr".
+func FormatTime(seconds float64) string {
+	if seconds == 0 {
+		return "0 s"
+	}
+	u := largestUnit(timeUnits, math.Abs(seconds))
+	v := seconds / u.value
+	switch {
+	case math.Abs(v) < 10:
+		return fmt.Sprintf("%.2f %s", v, u.name)
+	case math.Abs(v) < 100:
+		return fmt.Sprintf("%.1f %s", v, u.name)
+	}
+	return fmt.Sprintf("%.0f %s", v, u.name)
+}
+
+
+// ScaleBar returns the length of the scale bar of a view of the given width: the largest length of 1, 2, or 5
+// times a power of ten of a unit (from meters to kiloparsecs) that is at most a fifth of the width, and its label.
+// Input:
+//   - viewWidth: width of the view in meters.
+// Output:
+//   - the length of the bar in meters, and its label, e.g. "500 kpc".
+func ScaleBar(viewWidth float64) (float64, string) {
+	target := viewWidth / 5
+	u := largestUnit(scaleUnits, target)
+
+	v := target / u.value
+	power := math.Pow(10, math.Floor(math.Log10(v)))
+	nice := power
+	for _, step := range []float64{2, 5} {
+		if step*power <= v*(1+1e-12) {
+			nice = step * power
+		}
+	}
+	return nice * u.value, fmt.Sprintf("%g %s", nice, u.name)
+}
+
+
+// DrawOverlays draws the annotations selected by SelectedOverlays on a frame of a universe drawn in a view.
+// Input:
+//   - img: the frame; it is drawn on if it is an *image.RGBA, and copied otherwise.
+//   - u: pointer to the Universe of the frame.
+//   - view: the View of the universe shown by the frame.
+// Output:
+//   - the frame with its annotations.
+func DrawOverlays(img image.Image, u *nbody.Universe, view View) image.Image {
+	if SelectedOverlays == (Overlays{}) {
+		return img
+	}
+	rgba, ok := img.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(img.Bounds())
+		draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	}
+
+	bounds := rgba.Bounds()
+	size := max(1, min(bounds.Dx(), bounds.Dy())/300)
+	margin := 3 * glyphWidth * size
+	white := color.RGBA{255, 255, 255, 255}
+
+	if SelectedOverlays.time {
+		DrawText(rgba, bounds.Min.X+margin, bounds.Min.Y+margin, "t = "+FormatTime(u.Time), size, white)
+	}
+
+	if SelectedOverlays.scale {
+		length, label := ScaleBar(view.Width)
+		pixels := int(math.Round(length / view.Width * float64(bounds.Dx())))
+		x, y := bounds.Min.X+margin, bounds.Max.Y-margin
+		fillRect(rgba, x, y-size, pixels, size, white)
+		fillRect(rgba, x, y-3*size, size, 3*size, white)
+		fillRect(rgba, x+pixels-size, y-3*size, size, 3*size, white)
+		DrawText(rgba, x, y-4*size-glyphHeight*size, label, size, white)
+	}
+
+	if SelectedOverlays.legend {
+		drawLegend(rgba, bounds.Max.X-margin, bounds.Min.Y+margin, size)
+	}
+	return rgba
+}
+
+
+// drawLegend draws the legend of the colors of the stars with its top right corner at (right, top): the age ramp
+// of -age-colors, or else the colors of the galaxies of -color-origin. Stars keeping their own colors have none.
+func drawLegend(img *image.RGBA, right, top, size int) {
+	white := color.RGBA{255, 255, 255, 255}
+	line := (glyphHeight + 3) * size
+
+	if AgeColorTime > 0 {
+		label := "age 0 - " + FormatTime(AgeColorTime)
+		width := TextWidth(label, size)
+		DrawText(img, right-width, top, label, size, white)
+
+		// the ramp from new stars on the left to stars of AgeColorTime on the right
+		ramp := top + line
+		for x := 0; x < width; x++ {
+			c := AgeColor(AgeColorTime*float64(x)/float64(max(width-1, 1)), AgeColorTime)
+			fillRect(img, right-width+x, ramp, 1, glyphHeight*size, c)
+		}
+		return
+	}
+
+	for g := 0; g < LegendGalaxies; g++ {
+		label := fmt.Sprintf("galaxy %d", g)
+		y := top + g*line
+		width := TextWidth(label, size)
+		swatch := glyphHeight * size
+		fillRect(img, right-width-swatch-glyphWidth*size, y, swatch, swatch, nbody.OriginColors[g%len(nbody.OriginColors)])
+		DrawText(img, right-width, y, label, size, white)
+	}
+}
+
+
+// fillRect fills the rectangle of width w and height h with its top left corner at (x, y), clipped to the image.
+func fillRect(img *image.RGBA, x, y, w, h int, c color.Color) {
+	r := image.Rect(x, y, x+w, y+h).Intersect(img.Bounds())
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+
+//// Bitmap font ////
+
+// glyphWidth and glyphHeight are the size of a character of overlayFont in font pixels; characters are drawn
+// glyphWidth+1 font pixels apart.
+const (
+	glyphWidth  = 5
+	glyphHeight = 7
+)
+
+// overlayFont holds the rows of every character, top to bottom, with the leftmost pixel in bit 4.
+// Characters without a glyph are drawn as spaces.
+var overlayFont = map[rune][glyphHeight]uint8{
+	'0': {0b01110, 0b10001, 0b10011, 0b10101, 0b11001, 0b10001, 0b01110},
+	'1': {0b00100, 0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'2': {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b01000, 0b11111},
+	'3': {0b11110, 0b00001, 0b00001, 0b01110, 0b00001, 0b00001, 0b11110},
+	'4': {0b00010, 0b00110, 0b01010, 0b10010, 0b11111, 0b00010, 0b00010},
+	'5': {0b11111, 0b10000, 0b11110, 0b00001, 0b00001, 0b10001, 0b01110},
+	'6': {0b00110, 0b01000, 0b10000, 0b11110, 0b10001, 0b10001, 0b01110},
+	'7': {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b01000, 0b01000},
+	'8': {0b01110, 0b10001, 0b10001, 0b01110, 0b10001, 0b10001, 0b01110},
+	'9': {0b01110, 0b10001, 0b10001, 0b01111, 0b00001, 0b00010, 0b01100},
+	'.': {0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b01100, 0b01100},
+	'-': {0b00000, 0b00000, 0b00000, 0b11111, 0b00000, 0b00000, 0b00000},
+	'+': {0b00000, 0b00100, 0b00100, 0b11111, 0b00100, 0b00100, 0b00000},
+	'=': {0b00000, 0b00000, 0b11111, 0b00000, 0b11111, 0b00000, 0b00000},
+	'A': {0b01110, 0b10001, 0b10001, 0b11111, 0b10001, 0b10001, 0b10001},
+	'G': {0b01110, 0b10001, 0b10000, 0b10111, 0b10001, 0b10001, 0b01111},
+	'M': {0b10001, 0b11011, 0b10101, 0b10101, 0b10001, 0b10001, 0b10001},
+	'U': {0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b10001, 0b01110},
+	'a': {0b00000, 0b00000, 0b01110, 0b00001, 0b01111, 0b10001, 0b01111},
+	'b': {0b10000, 0b10000, 0b10110, 0b11001, 0b10001, 0b10001, 0b11110},
+	'c': {0b00000, 0b00000, 0b01110, 0b10000, 0b10000, 0b10001, 0b01110},
+	'd': {0b00001, 0b00001, 0b01101, 0b10011, 0b10001, 0b10001, 0b01111},
+	'e': {0b00000, 0b00000, 0b01110, 0b10001, 0b11111, 0b10000, 0b01110},
+	'f': {0b00110, 0b01001, 0b01000, 0b11100, 0b01000, 0b01000, 0b01000},
+	'g': {0b00000, 0b01111, 0b10001, 0b10001, 0b01111, 0b00001, 0b01110},
+	'h': {0b10000, 0b10000, 0b10110, 0b11001, 0b10001, 0b10001, 0b10001},
+	'i': {0b00100, 0b00000, 0b01100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'j': {0b00010, 0b00000, 0b00110, 0b00010, 0b00010, 0b10010, 0b01100},
+	'k': {0b10000, 0b10000, 0b10010, 0b10100, 0b11000, 0b10100, 0b10010},
+	'l': {0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'm': {0b00000, 0b00000, 0b11010, 0b10101, 0b10101, 0b10001, 0b10001},
+	'n': {0b00000, 0b00000, 0b10110, 0b11001, 0b10001, 0b10001, 0b10001},
+	'o': {0b00000, 0b00000, 0b01110, 0b10001, 0b10001, 0b10001, 0b01110},
+	'p': {0b00000, 0b00000, 0b11110, 0b10001, 0b11110, 0b10000, 0b10000},
+	'q': {0b00000, 0b00000, 0b01101, 0b10011, 0b01111, 0b00001, 0b00001},
+	'r': {0b00000, 0b00000, 0b10110, 0b11001, 0b10000, 0b10000, 0b10000},
+	's': {0b00000, 0b00000, 0b01110, 0b10000, 0b01110, 0b00001, 0b11110},
+	't': {0b01000, 0b01000, 0b11100, 0b01000, 0b01000, 0b01001, 0b00110},
+	'u': {0b00000, 0b00000, 0b10001, 0b10001, 0b10001, 0b10011, 0b01101},
+	'v': {0b00000, 0b00000, 0b10001, 0b10001, 0b10001, 0b01010, 0b00100},
+	'w': {0b00000, 0b00000, 0b10001, 0b10001, 0b10101, 0b10101, 0b01010},
+	'x': {0b00000, 0b00000, 0b10001, 0b01010, 0b00100, 0b01010, 0b10001},
+	'y': {0b00000, 0b00000, 0b10001, 0b10001, 0b01111, 0b00001, 0b01110},
+	'z': {0b00000, 0b00000, 0b11111, 0b00010, 0b00100, 0b01000, 0b11111},
+}
+
+
+// TextWidth returns the width in pixels of a text drawn by DrawText with font pixels of size x size pixels.
+func TextWidth(text string, size int) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return (n*(glyphWidth+1) - 1) * size
+}
+
+
+// DrawText draws a text with overlayFont, with its top left corner at (x, y) and every font pixel drawn as a
+// square of size x size pixels.
+func DrawText(img *image.RGBA, x, y int, text string, size int, c color.Color) {
+	for _, r := range text {
+		glyph := overlayFont[r]
+		for row, bits := range glyph {
+			for col := 0; col < glyphWidth; col++ {
+				if bits&(1<<uint(glyphWidth-1-col)) != 0 {
+					fillRect(img, x+col*size, y+row*size, size, size, c)
+				}
+			}
+		}
+		x += (glyphWidth + 1) * size
+	}
+}
@@ -0,0 +1,130 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the annotations of the frames in overlays.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package render
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type FormatTimeTestCases struct {
+	id       string
+	seconds  float64
+	expected string
+}
+
+
+// ReadFormatTime reads test data for FormatTime from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of FormatTimeTestCases structs containing the times and their expected text.
+func ReadFormatTime(fileName string) []FormatTimeTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []FormatTimeTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 3 {
+			continue
+		}
+
+		test := FormatTimeTestCases{id: strings.TrimSpace(parts[0]), expected: strings.TrimSpace(parts[2])}
+		test.seconds, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		nbody.Check(err)
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestFormatTime tests that simulated times are printed in the unit that fits them.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestFormatTime(t *testing.T) {
+	tests := ReadFormatTime("../Tests/FormatTime.txt")
+
+	for _, test := range tests {
+		if result := FormatTime(test.seconds); result != test.expected {
+			t.Errorf("TestFormatTime(test %v) = %q, want %q", test.id, result, test.expected)
+		}
+	}
+}
+
+
+type ScaleBarTestCases struct {
+	id             string
+	viewWidth      float64
+	expectedLength float64
+	expectedLabel  string
+}
+
+
+// ReadScaleBar reads test data for ScaleBar from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of ScaleBarTestCases structs containing the widths of the views and the expected bars.
+func ReadScaleBar(fileName string) []ScaleBarTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []ScaleBarTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		test := ScaleBarTestCases{id: strings.TrimSpace(parts[0]), expectedLabel: strings.TrimSpace(parts[3])}
+		test.viewWidth, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		nbody.Check(err)
+		test.expectedLength, err = strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+		nbody.Check(err)
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestScaleBar tests that the scale bar has a round length in a fitting unit and is at most a fifth of the view.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestScaleBar(t *testing.T) {
+	tests := ReadScaleBar("../Tests/ScaleBar.txt")
+
+	for _, test := range tests {
+		length, label := ScaleBar(test.viewWidth)
+		if label != test.expectedLabel || math.Abs(length-test.expectedLength) > 1e-12*test.expectedLength {
+			t.Errorf("TestScaleBar(test %v) = %v %q, want %v %q", test.id, length, label, test.expectedLength, test.expectedLabel)
+		}
+		if length > test.viewWidth/5*(1+1e-12) {
+			t.Errorf("TestScaleBar(test %v) = %v, longer than a fifth of the view %v", test.id, length, test.viewWidth)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Frame sequences written as numbered PNG files (options "-format png" and "-png-frames").
+
+package render
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+)
+
+// PNGFrameName returns the name of the PNG file of the index-th frame inside a directory.
+// Frames are numbered from 0 in the order of the animation, without gaps, as tools such as ffmpeg expect.
+func PNGFrameName(dir string, index int) string {
+	return filepath.Join(dir, fmt.Sprintf("frame_%06d.png", index))
+}
+
+
+// WritePNGFrame writes one frame as a PNG file.
+// Input:
+//   - img: the frame.
+//   - dir: directory of the frame sequence, which must exist.
+//   - index: number of the frame in the animation.
+// Output:
+//   - an error if the file cannot be written.
+func WritePNGFrame(img image.Image, dir string, index int) error {
+	file, err := os.Create(PNGFrameName(dir, index))
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	err = png.Encode(w, img)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+
+// WritePNGFrames writes all frames as PNG files, encoding them on nbody.NumWorkers goroutines.
+// Input:
+//   - images: the frames in the order of the animation.
+//   - dir: directory of the frame sequence, created if it does not exist.
+// Output:
+//   - an error if the directory or a file cannot be written.
+func WritePNGFrames(images []image.Image, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return nbody.ParallelForErr(len(images), nbody.NumWorkers, func(k int) error {
+		return WritePNGFrame(images[k], dir, k)
+	})
+}
@@ -0,0 +1,125 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for PNG frame sequences in png.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package render
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type WritePNGFramesTestCases struct {
+	id        string
+	numFrames int
+	width     int
+	height    int
+}
+
+
+// ReadWritePNGFrames reads test data for WritePNGFrames from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of WritePNGFramesTestCases structs containing the number and size of the frames.
+func ReadWritePNGFrames(fileName string) []WritePNGFramesTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []WritePNGFramesTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		var numbers [3]int
+		for i := range numbers {
+			numbers[i], err = strconv.Atoi(strings.TrimSpace(parts[i+1]))
+			nbody.Check(err)
+		}
+
+		tests = append(tests, WritePNGFramesTestCases{
+			id:        strings.TrimSpace(parts[0]),
+			numFrames: numbers[0],
+			width:     numbers[1],
+			height:    numbers[2],
+		})
+	}
+
+	return tests
+}
+
+
+// TestWritePNGFrames tests that every frame is written as a numbered PNG file with exactly its pixels.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestWritePNGFrames(t *testing.T) {
+	tests := ReadWritePNGFrames("../Tests/WritePNGFrames.txt")
+
+	for _, test := range tests {
+		images := make([]image.Image, test.numFrames)
+		for k := range images {
+			img := image.NewRGBA(image.Rect(0, 0, test.width, test.height))
+			for x := 0; x < test.width; x++ {
+				for y := 0; y < test.height; y++ {
+					img.Set(x, y, color.RGBA{uint8(x * 7), uint8(y * 11), uint8(k * 13), 255})
+				}
+			}
+			images[k] = img
+		}
+
+		dir := filepath.Join(t.TempDir(), "frames")
+		if err := WritePNGFrames(images, dir); err != nil {
+			t.Errorf("TestWritePNGFrames(test %v) = error %v, want none", test.id, err)
+			continue
+		}
+
+		names, err := filepath.Glob(filepath.Join(dir, "*.png"))
+		nbody.Check(err)
+		if len(names) != test.numFrames {
+			t.Errorf("TestWritePNGFrames(test %v) wrote %d files, want %d", test.id, len(names), test.numFrames)
+		}
+
+		for k, expected := range images {
+			file, err := os.Open(PNGFrameName(dir, k))
+			if err != nil {
+				t.Errorf("TestWritePNGFrames(test %v): frame %d missing: %v", test.id, k, err)
+				continue
+			}
+			result, err := png.Decode(file)
+			file.Close()
+			nbody.Check(err)
+
+			if result.Bounds() != expected.Bounds() {
+				t.Errorf("TestWritePNGFrames(test %v): frame %d has bounds %v, want %v", test.id, k, result.Bounds(), expected.Bounds())
+				continue
+			}
+			for x := 0; x < test.width; x++ {
+				for y := 0; y < test.height; y++ {
+					r1, g1, b1, a1 := result.At(x, y).RGBA()
+					r2, g2, b2, a2 := expected.At(x, y).RGBA()
+					if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+						t.Errorf("TestWritePNGFrames(test %v): frame %d differs at (%d, %d)", test.id, k, x, y)
+						x, y = test.width, test.height
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,236 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Color quantization and dithering of GIF frames (options "-gif-colors", "-gif-quantizer", "-gif-dither").
+
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"sort"
+)
+
+// GIFColors, GIFQuantizer, and GIFDither control the palettes of GIF frames (except -delta-gif frames,
+// which share one palette with a transparent color). They are set from the command line.
+//   - GIFColors: largest number of colors of a frame palette (2 to 256).
+//   - GIFQuantizer: "auto" uses the exact colors of a frame when they fit and Plan9 otherwise,
+//     "median-cut" builds a palette adapted to the frame, "plan9" and "websafe" use fixed palettes.
+//   - GIFDither: use Floyd-Steinberg error diffusion instead of the nearest palette color.
+var (
+	GIFColors    = 256
+	GIFQuantizer = "auto"
+	GIFDither    = false
+)
+
+
+// CheckGIFOptions returns an error if the GIF palette settings are invalid.
+func CheckGIFOptions(colors int, quantizer string) error {
+	if colors < 2 || colors > 256 {
+		return fmt.Errorf("number of GIF colors must be between 2 and 256, got %d", colors)
+	}
+	switch quantizer {
+	case "auto", "median-cut", "plan9", "websafe":
+		return nil
+	}
+	return fmt.Errorf("unknown GIF quantizer %q (use auto, median-cut, plan9, or websafe)", quantizer)
+}
+
+
+// QuantizeFrame converts one frame into a paletted image using the palette chosen by GIFQuantizer
+// and GIFColors, dithered if GIFDither is set.
+func QuantizeFrame(img image.Image) *image.Paletted {
+	var p color.Palette
+	switch GIFQuantizer {
+	case "median-cut":
+		p = MedianCutPalette(ColorHistogram(img), GIFColors)
+	case "plan9":
+		p = palette.Plan9
+	case "websafe":
+		p = palette.WebSafe
+	default:
+		p = FramePalette(img)
+	}
+
+	if !GIFDither {
+		return ImageToPaletted(img, p)
+	}
+	bounds := img.Bounds()
+	paletted := image.NewPaletted(bounds, p)
+	draw.FloydSteinberg.Draw(paletted, bounds, img, bounds.Min)
+	return paletted
+}
+
+
+// ColorHistogram counts how many pixels of an image have each color.
+func ColorHistogram(img image.Image) map[color.RGBA]int {
+	bounds := img.Bounds()
+	histogram := make(map[color.RGBA]int)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			histogram[color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)]++
+		}
+	}
+
+	return histogram
+}
+
+
+// colorBox is a set of colors of a histogram, split by MedianCutPalette.
+type colorBox struct {
+	colors []color.RGBA
+	counts []int
+}
+
+
+// channel returns the red, green, or blue value of a color (c = 0, 1, 2).
+func channel(col color.RGBA, c int) uint8 {
+	switch c {
+	case 0:
+		return col.R
+	case 1:
+		return col.G
+	}
+	return col.B
+}
+
+
+// widest returns the channel with the largest range of values in the box, and that range.
+func (b colorBox) widest() (int, int) {
+	best, bestRange := 0, -1
+	for c := 0; c < 3; c++ {
+		lo, hi := uint8(255), uint8(0)
+		for _, col := range b.colors {
+			v := channel(col, c)
+			lo, hi = min(lo, v), max(hi, v)
+		}
+		if int(hi)-int(lo) > bestRange {
+			best, bestRange = c, int(hi)-int(lo)
+		}
+	}
+	return best, bestRange
+}
+
+
+// MedianCutPalette builds a palette of at most n colors for a color histogram with the median cut algorithm.
+// All colors start in one box. The box with the widest range in one channel is repeatedly sorted along that
+// channel and split where half of its pixels are on each side, until there are n boxes or no box can be split.
+// Each box becomes the pixel-weighted mean of its colors, so a histogram with at most n colors keeps them exactly.
+// Input:
+//   - histogram: number of pixels of every color.
+//   - n: largest number of colors.
+// Output:
+//   - the palette, ordered by the boxes' position in the split order.
+func MedianCutPalette(histogram map[color.RGBA]int, n int) color.Palette {
+	first := colorBox{}
+	for col := range histogram {
+		first.colors = append(first.colors, col)
+	}
+	// map order is random; sort so the palette does not change between runs
+	sort.Slice(first.colors, func(i, j int) bool {
+		a, b := first.colors[i], first.colors[j]
+		if a.R != b.R {
+			return a.R < b.R
+		}
+		if a.G != b.G {
+			return a.G < b.G
+		}
+		if a.B != b.B {
+			return a.B < b.B
+		}
+		return a.A < b.A
+	})
+	for _, col := range first.colors {
+		first.counts = append(first.counts, histogram[col])
+	}
+
+	boxes := []colorBox{first}
+	for len(boxes) < n {
+		split, splitRange := -1, 0
+		for i, b := range boxes {
+			if len(b.colors) < 2 {
+				continue
+			}
+			if _, r := b.widest(); split < 0 || r > splitRange {
+				split, splitRange = i, r
+			}
+		}
+		if split < 0 {
+			break
+		}
+
+		left, right := splitBox(boxes[split])
+		boxes[split] = left
+		boxes = append(boxes, right)
+	}
+
+	p := make(color.Palette, 0, len(boxes))
+	for _, b := range boxes {
+		var sum [4]int
+		total := 0
+		for i, col := range b.colors {
+			w := b.counts[i]
+			sum[0] += w * int(col.R)
+			sum[1] += w * int(col.G)
+			sum[2] += w * int(col.B)
+			sum[3] += w * int(col.A)
+			total += w
+		}
+		if total == 0 {
+			continue
+		}
+		p = append(p, color.RGBA{
+			R: uint8((sum[0] + total/2) / total),
+			G: uint8((sum[1] + total/2) / total),
+			B: uint8((sum[2] + total/2) / total),
+			A: uint8((sum[3] + total/2) / total),
+		})
+	}
+
+	return p
+}
+
+
+// splitBox sorts a box along its widest channel and splits it at the pixel-weighted median.
+// Both halves keep at least one color.
+func splitBox(b colorBox) (colorBox, colorBox) {
+	c, _ := b.widest()
+
+	order := make([]int, len(b.colors))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return channel(b.colors[order[i]], c) < channel(b.colors[order[j]], c)
+	})
+
+	total := 0
+	for _, w := range b.counts {
+		total += w
+	}
+
+	// the first index where at least half of the pixels are on the left
+	k, cumulative := 0, 0
+	for k = 0; k < len(order)-1; k++ {
+		cumulative += b.counts[order[k]]
+		if 2*cumulative >= total {
+			break
+		}
+	}
+	k = min(k, len(order)-2)
+
+	var left, right colorBox
+	for i, idx := range order {
+		if i <= k {
+			left.colors = append(left.colors, b.colors[idx])
+			left.counts = append(left.counts, b.counts[idx])
+		} else {
+			right.colors = append(right.colors, b.colors[idx])
+			right.counts = append(right.counts, b.counts[idx])
+		}
+	}
+	return left, right
+}
@@ -0,0 +1,105 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the color quantization in quantize.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package render
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"image/color"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type MedianCutPaletteTestCases struct {
+	id        string
+	n         int
+	histogram map[color.RGBA]int
+	expected  []color.RGBA
+}
+
+
+// parseColor parses the first three fields of a test line as an opaque color.
+func parseColor(fields []string) color.RGBA {
+	var c [3]uint8
+	for i := range c {
+		v, err := strconv.Atoi(fields[i])
+		nbody.Check(err)
+		c[i] = uint8(v)
+	}
+	return color.RGBA{c[0], c[1], c[2], 255}
+}
+
+
+// ReadMedianCutPalette reads test data for the MedianCutPalette function from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of MedianCutPaletteTestCases structs containing the size, the histogram, and the expected palette.
+func ReadMedianCutPalette(fileName string) []MedianCutPaletteTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []MedianCutPaletteTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+
+		histogram := make(map[color.RGBA]int)
+		for _, entry := range strings.Split(parts[2], ",") {
+			fields := strings.Fields(entry)
+			count, err := strconv.Atoi(fields[3])
+			nbody.Check(err)
+			histogram[parseColor(fields)] += count
+		}
+
+		var expected []color.RGBA
+		for _, entry := range strings.Split(parts[3], ",") {
+			expected = append(expected, parseColor(strings.Fields(entry)))
+		}
+
+		tests = append(tests, MedianCutPaletteTestCases{
+			id:        strings.TrimSpace(parts[0]),
+			n:         n,
+			histogram: histogram,
+			expected:  expected,
+		})
+	}
+
+	return tests
+}
+
+
+// TestMedianCutPalette tests the MedianCutPalette function using data from a file.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestMedianCutPalette(t *testing.T) {
+	tests := ReadMedianCutPalette("../Tests/MedianCutPalette.txt")
+
+	for _, test := range tests {
+		p := MedianCutPalette(test.histogram, test.n)
+
+		same := len(p) == len(test.expected)
+		for i := 0; same && i < len(p); i++ {
+			same = p[i] == test.expected[i]
+		}
+		if !same {
+			t.Errorf("TestMedianCutPalette(test %v) = %v, want %v", test.id, p, test.expected)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Rendering with accumulated per-pixel intensity and a brightness transfer function.
+
+package render
+
+import (
+	"BarnesHut/nbody"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+// Brightness selects how DrawToCanvas turns stars into pixels.
+// It is set from the -brightness command line option.
+//   - "solid": every star is an opaque disc, later stars cover earlier ones (the original drawing).
+//   - "linear", "log", "asinh": the colors of overlapping stars add up per pixel, and the summed intensity
+//     is mapped to the screen with Transfer, so dense cores do not all clip to white.
+var Brightness = "solid"
+
+
+// CheckBrightness returns an error if name is not a known brightness transfer function.
+func CheckBrightness(name string) error {
+	switch name {
+	case "solid", "linear", "log", "asinh":
+		return nil
+	}
+	return fmt.Errorf("unknown brightness %q (use solid, linear, log, or asinh)", name)
+}
+
+
+// Transfer maps an accumulated intensity to a screen value between 0 and 1.
+// The brightest pixel of a frame (intensity maxIntensity) is always 1.
+//   - "linear": intensity / maxIntensity. The core is right, but single stars are dim.
+//   - "log": log(1 + intensity) / log(1 + maxIntensity).
+//   - "asinh": asinh(intensity) / asinh(maxIntensity), linear for faint pixels and logarithmic for bright ones.
+//
+// Input:
+//   - name: the transfer function.
+//   - intensity: accumulated intensity of a pixel (1 for one white star).
+//   - maxIntensity: largest intensity of the frame.
+// Output:
+//   - the screen value.
+func Transfer(name string, intensity, maxIntensity float64) float64 {
+	if intensity <= 0 || maxIntensity <= 0 {
+		return 0
+	}
+
+	var v float64
+	switch name {
+	case "log":
+		v = math.Log1p(intensity) / math.Log1p(maxIntensity)
+	case "asinh":
+		v = math.Asinh(intensity) / math.Asinh(maxIntensity)
+	default:
+		v = intensity / maxIntensity
+	}
+	return math.Min(v, 1)
+}
+
+
+// DrawAccumulated draws a universe by adding up the colors of all stars covering a pixel and mapping the
+// summed intensity with Transfer. The hue of a pixel is kept: its brightest channel is mapped and the
+// other channels are scaled with it. Stars are placed and sized like in DrawToCanvas.
+// Input:
+//   - u: pointer to the Universe to draw.
+//   - canvasWidth: width of the image in pixels; its height is the CanvasHeight of the view.
+//   - scalingFactor: factor enlarging the radius of the stars.
+//   - transfer: name of the transfer function ("linear", "log", or "asinh").
+// Output:
+//   - the image.
+func DrawAccumulated(u *nbody.Universe, canvasWidth int, scalingFactor float64, transfer string) image.Image {
+	view := UniverseView(u)
+	canvasHeight := view.CanvasHeight(canvasWidth)
+
+	// red, green, and blue intensity of every pixel
+	sum := make([][3]float64, canvasWidth*canvasHeight)
+
+	for _, b := range u.Stars {
+		cx, cy := view.CanvasPoint(b.Position, canvasWidth)
+		r := scalingFactor * (b.Radius / u.Width) * float64(canvasWidth)
+		red, green, blue := StarColor(b)
+		c := [3]float64{float64(red) / 255, float64(green) / 255, float64(blue) / 255}
+
+		reach := int(math.Ceil(r))
+		for dy := -reach; dy <= reach; dy++ {
+			for dx := -reach; dx <= reach; dx++ {
+				if float64(dx*dx+dy*dy) > r*r+0.5 {
+					continue
+				}
+				x, y := int(math.Floor(cx))+dx, int(math.Floor(cy))+dy
+				if x < 0 || y < 0 || x >= canvasWidth || y >= canvasHeight {
+					continue
+				}
+				p := &sum[y*canvasWidth+x]
+				p[0] += c[0]
+				p[1] += c[1]
+				p[2] += c[2]
+			}
+		}
+	}
+
+	maxIntensity := 0.0
+	for _, p := range sum {
+		maxIntensity = math.Max(maxIntensity, math.Max(p[0], math.Max(p[1], p[2])))
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, canvasWidth, canvasHeight))
+	for i, p := range sum {
+		peak := math.Max(p[0], math.Max(p[1], p[2]))
+		pixel := color.RGBA{A: 255}
+		if peak > 0 {
+			scale := 255 * Transfer(transfer, peak, maxIntensity) / peak
+			pixel.R = uint8(math.Round(p[0] * scale))
+			pixel.G = uint8(math.Round(p[1] * scale))
+			pixel.B = uint8(math.Round(p[2] * scale))
+		}
+		img.SetRGBA(i%canvasWidth, i/canvasWidth, pixel)
+	}
+
+	return img
+}
@@ -0,0 +1,82 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the brightness transfer functions in render.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package render
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type TransferTestCases struct {
+	id           string
+	transfer     string
+	intensity    float64
+	maxIntensity float64
+	expected     float64
+}
+
+
+// ReadTransfer reads test data for the Transfer function from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of TransferTestCases structs containing the transfer function, the intensities, and the expected value.
+func ReadTransfer(fileName string) []TransferTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []TransferTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+
+		intensity, err := strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+		nbody.Check(err)
+		maxIntensity, err := strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
+		nbody.Check(err)
+		expected, err := strconv.ParseFloat(strings.TrimSpace(parts[4]), 64)
+		nbody.Check(err)
+
+		tests = append(tests, TransferTestCases{
+			id:           strings.TrimSpace(parts[0]),
+			transfer:     strings.TrimSpace(parts[1]),
+			intensity:    intensity,
+			maxIntensity: maxIntensity,
+			expected:     expected,
+		})
+	}
+
+	return tests
+}
+
+
+// TestTransfer tests the Transfer function using data from a file.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestTransfer(t *testing.T) {
+	tests := ReadTransfer("../Tests/Transfer.txt")
+
+	for _, test := range tests {
+		result := Transfer(test.transfer, test.intensity, test.maxIntensity)
+
+		if math.Abs(result-test.expected) > 1e-5 {
+			t.Errorf("TestTransfer(test %v) = %v, want %v", test.id, result, test.expected)
+		}
+	}
+}
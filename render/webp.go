@@ -0,0 +1,646 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Animated WebP output using the lossless (VP8L) format.
+
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"io"
+	"os"
+	"sort"
+)
+
+// The WebP container and the VP8L bitstream are described in
+// https://developers.google.com/speed/webp/docs/riff_container and
+// https://developers.google.com/speed/webp/docs/webp_lossless_bitstream_specification.
+// The encoder below is deliberately simple: no transforms and no color cache, one set of prefix codes
+// per frame, and backward references that only copy the pixel to the left (run lengths) or the row above.
+// Renders of the universe are mostly flat background, which these two references compress very well.
+
+// codeLengthCodeOrder is the order in which the code length code lengths are written.
+var codeLengthCodeOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+const (
+	vp8lNumLiterals     = 256
+	vp8lNumLengthCodes  = 24
+	vp8lNumDistCodes    = 40
+	vp8lMaxCopyLength   = 4096
+	vp8lMinCopyLength   = 3
+	vp8lMaxCodeLength   = 15
+	vp8lMaxCLCodeLength = 7
+)
+
+// vp8lToken is one entry of the entropy-coded image: a literal pixel or a backward reference.
+type vp8lToken struct {
+	literal  bool
+	argb     uint32
+	length   int
+	distCode int
+}
+
+// bitWriter writes bits least significant bit first, as required by VP8L.
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nBits uint
+}
+
+
+// WriteBits appends the n low bits of v.
+func (bw *bitWriter) WriteBits(v uint32, n uint) {
+	bw.acc |= uint64(v) << bw.nBits
+	bw.nBits += n
+	for bw.nBits >= 8 {
+		bw.buf = append(bw.buf, byte(bw.acc))
+		bw.acc >>= 8
+		bw.nBits -= 8
+	}
+}
+
+
+// Bytes flushes the remaining bits (padded with zeros) and returns the written bytes.
+func (bw *bitWriter) Bytes() []byte {
+	if bw.nBits > 0 {
+		bw.buf = append(bw.buf, byte(bw.acc))
+		bw.acc = 0
+		bw.nBits = 0
+	}
+	return bw.buf
+}
+
+
+// prefixCode is a canonical prefix code: the length and the bit-reversed code of every symbol.
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint32
+}
+
+
+// WriteSymbol writes the code of a symbol (nothing for a code with a single symbol).
+func (c *prefixCode) WriteSymbol(bw *bitWriter, symbol int) {
+	bw.WriteBits(c.codes[symbol], uint(c.lengths[symbol]))
+}
+
+
+// HuffmanLengths computes code lengths no longer than maxLength for the given symbol frequencies.
+// Symbols with zero frequency get length zero. If the ideal code is too deep, small frequencies
+// are raised and the code rebuilt, which flattens the tree until it fits.
+func HuffmanLengths(freq []int, maxLength int) []uint8 {
+	counts := make([]int, len(freq))
+	copy(counts, freq)
+
+	for minCount := 1; ; minCount *= 2 {
+		for i, f := range freq {
+			if f > 0 && counts[i] < minCount {
+				counts[i] = minCount
+			}
+		}
+
+		lengths := huffmanDepths(counts)
+		fits := true
+		for _, l := range lengths {
+			if int(l) > maxLength {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			return lengths
+		}
+	}
+}
+
+
+// huffmanDepths builds an unrestricted Huffman tree and returns the depth of every used symbol.
+func huffmanDepths(freq []int) []uint8 {
+	type node struct {
+		weight      int
+		symbol      int
+		left, right int
+	}
+
+	var nodes []node
+	var active []int
+	for s, f := range freq {
+		if f > 0 {
+			nodes = append(nodes, node{weight: f, symbol: s, left: -1, right: -1})
+			active = append(active, len(nodes)-1)
+		}
+	}
+
+	lengths := make([]uint8, len(freq))
+	if len(active) == 1 {
+		lengths[nodes[0].symbol] = 1
+		return lengths
+	}
+
+	// repeatedly merge the two lightest nodes; ties are broken by position to stay deterministic
+	for len(active) > 1 {
+		sort.SliceStable(active, func(a, b int) bool {
+			return nodes[active[a]].weight < nodes[active[b]].weight
+		})
+		a, b := active[0], active[1]
+		nodes = append(nodes, node{weight: nodes[a].weight + nodes[b].weight, symbol: -1, left: a, right: b})
+		active = append(active[2:], len(nodes)-1)
+	}
+
+	var walk func(n, depth int)
+	walk = func(n, depth int) {
+		if nodes[n].symbol >= 0 {
+			lengths[nodes[n].symbol] = uint8(depth)
+			return
+		}
+		walk(nodes[n].left, depth+1)
+		walk(nodes[n].right, depth+1)
+	}
+	walk(active[0], 0)
+
+	return lengths
+}
+
+
+// CanonicalCode assigns canonical codes to code lengths, bit-reversed so they can be written LSB first.
+func CanonicalCode(lengths []uint8) *prefixCode {
+	var lengthCount [vp8lMaxCodeLength + 1]uint32
+	for _, l := range lengths {
+		lengthCount[l]++
+	}
+	lengthCount[0] = 0
+
+	var nextCode [vp8lMaxCodeLength + 2]uint32
+	code := uint32(0)
+	for l := 1; l <= vp8lMaxCodeLength; l++ {
+		code = (code + lengthCount[l-1]) << 1
+		nextCode[l] = code
+	}
+
+	c := &prefixCode{lengths: lengths, codes: make([]uint32, len(lengths))}
+	for s, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		v := nextCode[l]
+		nextCode[l]++
+
+		reversed := uint32(0)
+		for i := uint8(0); i < l; i++ {
+			reversed = (reversed << 1) | (v & 1)
+			v >>= 1
+		}
+		c.codes[s] = reversed
+	}
+	return c
+}
+
+
+// WritePrefixCode writes the prefix code for a histogram and returns it for coding the symbols.
+// Histograms with one or two symbols below 256 use the "simple" form; everything else is written as a
+// normal code whose code lengths are themselves prefix coded.
+func WritePrefixCode(bw *bitWriter, freq []int) *prefixCode {
+	var used []int
+	for s, f := range freq {
+		if f > 0 {
+			used = append(used, s)
+		}
+	}
+
+	simple := len(used) <= 2
+	for _, s := range used {
+		if s >= 256 {
+			simple = false
+		}
+	}
+
+	if simple {
+		lengths := make([]uint8, len(freq))
+		bw.WriteBits(1, 1)
+
+		switch len(used) {
+		case 0:
+			// an unused alphabet: a single zero-bit symbol 0
+			bw.WriteBits(0, 1)
+			bw.WriteBits(0, 1)
+			bw.WriteBits(0, 1)
+		case 1:
+			// a single symbol takes zero bits
+			bw.WriteBits(0, 1)
+			bw.WriteBits(1, 1)
+			bw.WriteBits(uint32(used[0]), 8)
+		case 2:
+			bw.WriteBits(1, 1)
+			bw.WriteBits(1, 1)
+			bw.WriteBits(uint32(used[0]), 8)
+			bw.WriteBits(uint32(used[1]), 8)
+			lengths[used[0]] = 1
+			lengths[used[1]] = 1
+		}
+		return CanonicalCode(lengths)
+	}
+
+	// a normal code needs at least two symbols to form a complete tree
+	if len(used) == 1 {
+		freq = append([]int(nil), freq...)
+		if used[0] == 0 {
+			freq[1] = 1
+		} else {
+			freq[0] = 1
+		}
+	}
+
+	lengths := HuffmanLengths(freq, vp8lMaxCodeLength)
+
+	// code the lengths: 0..15 are literal lengths, 17 and 18 are runs of zeros
+	type clToken struct {
+		symbol int
+		extra  uint32
+		nExtra uint
+	}
+	var clTokens []clToken
+	for i := 0; i < len(lengths); {
+		if lengths[i] != 0 {
+			clTokens = append(clTokens, clToken{symbol: int(lengths[i])})
+			i++
+			continue
+		}
+
+		run := 0
+		for i+run < len(lengths) && lengths[i+run] == 0 {
+			run++
+		}
+		i += run
+
+		for run > 0 {
+			switch {
+			case run >= 11:
+				n := run
+				if n > 138 {
+					n = 138
+				}
+				clTokens = append(clTokens, clToken{symbol: 18, extra: uint32(n - 11), nExtra: 7})
+				run -= n
+			case run >= 3:
+				clTokens = append(clTokens, clToken{symbol: 17, extra: uint32(run - 3), nExtra: 3})
+				run = 0
+			default:
+				clTokens = append(clTokens, clToken{symbol: 0})
+				run--
+			}
+		}
+	}
+
+	clFreq := make([]int, 19)
+	for _, t := range clTokens {
+		clFreq[t.symbol]++
+	}
+	clUsed := 0
+	for _, f := range clFreq {
+		if f > 0 {
+			clUsed++
+		}
+	}
+	if clUsed == 1 {
+		// keep the code length code a complete tree as well
+		if clFreq[0] == 0 {
+			clFreq[0] = 1
+		} else {
+			clFreq[1] = 1
+		}
+	}
+	clCode := CanonicalCode(HuffmanLengths(clFreq, vp8lMaxCLCodeLength))
+
+	bw.WriteBits(0, 1)
+	bw.WriteBits(19-4, 4)
+	for _, s := range codeLengthCodeOrder {
+		bw.WriteBits(uint32(clCode.lengths[s]), 3)
+	}
+	// all symbols of the alphabet are coded
+	bw.WriteBits(0, 1)
+
+	for _, t := range clTokens {
+		clCode.WriteSymbol(bw, t.symbol)
+		if t.nExtra > 0 {
+			bw.WriteBits(t.extra, t.nExtra)
+		}
+	}
+
+	return CanonicalCode(lengths)
+}
+
+
+// PrefixEncode splits a length or distance code (>= 1) into its prefix symbol and extra bits.
+func PrefixEncode(value int) (symbol int, extra uint32, nExtra uint) {
+	d := value - 1
+	if d < 4 {
+		return d, 0, 0
+	}
+
+	highBit := 0
+	for (d >> uint(highBit+1)) != 0 {
+		highBit++
+	}
+	second := (d >> uint(highBit-1)) & 1
+	nExtra = uint(highBit - 1)
+
+	return 2*highBit + second, uint32(d) & ((1 << nExtra) - 1), nExtra
+}
+
+
+// EncodeVP8L encodes an image as a VP8L (lossless WebP) bitstream.
+func EncodeVP8L(img image.Image) []byte {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	argb := make([]uint32, width*height)
+	alphaUsed := false
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			argb[y*width+x] = uint32(c.A)<<24 | uint32(c.R)<<16 | uint32(c.G)<<8 | uint32(c.B)
+			if c.A != 255 {
+				alphaUsed = true
+			}
+		}
+	}
+
+	// LZ77 with two candidate distances: the pixel above (distance code 1) and the pixel to the left (code 2)
+	var tokens []vp8lToken
+	for i := 0; i < len(argb); {
+		bestLength, bestCode := 0, 0
+		candidates := []struct{ offset, code int }{{width, 1}, {1, 2}}
+		for _, c := range candidates {
+			if i < c.offset {
+				continue
+			}
+			length := 0
+			for i+length < len(argb) && length < vp8lMaxCopyLength && argb[i+length] == argb[i+length-c.offset] {
+				length++
+			}
+			if length > bestLength {
+				bestLength, bestCode = length, c.code
+			}
+		}
+
+		if bestLength >= vp8lMinCopyLength {
+			tokens = append(tokens, vp8lToken{length: bestLength, distCode: bestCode})
+			i += bestLength
+		} else {
+			tokens = append(tokens, vp8lToken{literal: true, argb: argb[i]})
+			i++
+		}
+	}
+
+	green := make([]int, vp8lNumLiterals+vp8lNumLengthCodes)
+	red := make([]int, 256)
+	blue := make([]int, 256)
+	alpha := make([]int, 256)
+	dist := make([]int, vp8lNumDistCodes)
+	for _, t := range tokens {
+		if t.literal {
+			green[(t.argb>>8)&0xff]++
+			red[(t.argb>>16)&0xff]++
+			blue[t.argb&0xff]++
+			alpha[t.argb>>24]++
+			continue
+		}
+		lengthSymbol, _, _ := PrefixEncode(t.length)
+		distSymbol, _, _ := PrefixEncode(t.distCode)
+		green[vp8lNumLiterals+lengthSymbol]++
+		dist[distSymbol]++
+	}
+
+	bw := &bitWriter{}
+	bw.WriteBits(0x2f, 8)
+	bw.WriteBits(uint32(width-1), 14)
+	bw.WriteBits(uint32(height-1), 14)
+	if alphaUsed {
+		bw.WriteBits(1, 1)
+	} else {
+		bw.WriteBits(0, 1)
+	}
+	bw.WriteBits(0, 3) // version
+	bw.WriteBits(0, 1) // no transform
+	bw.WriteBits(0, 1) // no color cache
+	bw.WriteBits(0, 1) // a single group of prefix codes
+
+	greenCode := WritePrefixCode(bw, green)
+	redCode := WritePrefixCode(bw, red)
+	blueCode := WritePrefixCode(bw, blue)
+	alphaCode := WritePrefixCode(bw, alpha)
+	distCode := WritePrefixCode(bw, dist)
+
+	for _, t := range tokens {
+		if t.literal {
+			greenCode.WriteSymbol(bw, int((t.argb>>8)&0xff))
+			redCode.WriteSymbol(bw, int((t.argb>>16)&0xff))
+			blueCode.WriteSymbol(bw, int(t.argb&0xff))
+			alphaCode.WriteSymbol(bw, int(t.argb>>24))
+			continue
+		}
+
+		symbol, extra, nExtra := PrefixEncode(t.length)
+		greenCode.WriteSymbol(bw, vp8lNumLiterals+symbol)
+		bw.WriteBits(extra, nExtra)
+
+		symbol, extra, nExtra = PrefixEncode(t.distCode)
+		distCode.WriteSymbol(bw, symbol)
+		bw.WriteBits(extra, nExtra)
+	}
+
+	return bw.Bytes()
+}
+
+
+// writeChunk writes a RIFF chunk, padding odd-sized payloads with a zero byte.
+func writeChunk(w io.Writer, fourCC string, payload []byte) error {
+	header := make([]byte, 8)
+	copy(header, fourCC)
+	binary.LittleEndian.PutUint32(header[4:], uint32(len(payload)))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(payload); err != nil {
+		return err
+	}
+	if len(payload)%2 == 1 {
+		_, err := w.Write([]byte{0})
+		return err
+	}
+	return nil
+}
+
+
+// putUint24 stores the low 24 bits of v little endian.
+func putUint24(b []byte, v int) {
+	b[0] = byte(v)
+	b[1] = byte(v >> 8)
+	b[2] = byte(v >> 16)
+}
+
+
+// EncodeAnimatedWebP encodes a sequence of images of the same size as an animated lossless WebP.
+// Like DeltaFrames, only the (even-aligned) bounding box of the pixels that changed is stored for each
+// frame, with unchanged pixels transparent and blended over the previous frame; identical images extend
+// the duration of the previous frame.
+// Input:
+//   - w: destination.
+//   - images: slice of images.
+//   - delay: display time of each image in milliseconds.
+// Output:
+//   - an error if writing fails.
+func EncodeAnimatedWebP(w io.Writer, images []image.Image, delay int) error {
+	if len(images) == 0 {
+		return nil
+	}
+
+	bounds := images[0].Bounds()
+	canvasWidth, canvasHeight := bounds.Dx(), bounds.Dy()
+
+	type frame struct {
+		rect     image.Rectangle
+		data     []byte
+		duration int
+	}
+	var frames []*frame
+
+	var previous *image.NRGBA
+	for _, img := range images {
+		current := image.NewNRGBA(image.Rect(0, 0, canvasWidth, canvasHeight))
+		for y := 0; y < canvasHeight; y++ {
+			for x := 0; x < canvasWidth; x++ {
+				current.Set(x, y, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+			}
+		}
+
+		if previous == nil {
+			frames = append(frames, &frame{rect: current.Bounds(), data: EncodeVP8L(current), duration: delay})
+			previous = current
+			continue
+		}
+
+		changed, ok := ChangedNRGBABounds(previous, current)
+		if !ok {
+			frames[len(frames)-1].duration += delay
+			continue
+		}
+
+		// frame offsets are stored divided by two
+		changed.Min.X -= changed.Min.X % 2
+		changed.Min.Y -= changed.Min.Y % 2
+
+		sub := image.NewNRGBA(image.Rect(0, 0, changed.Dx(), changed.Dy()))
+		for y := changed.Min.Y; y < changed.Max.Y; y++ {
+			for x := changed.Min.X; x < changed.Max.X; x++ {
+				c := current.NRGBAAt(x, y)
+				if c == previous.NRGBAAt(x, y) {
+					c = color.NRGBA{}
+				}
+				sub.SetNRGBA(x-changed.Min.X, y-changed.Min.Y, c)
+			}
+		}
+
+		frames = append(frames, &frame{rect: changed, data: EncodeVP8L(sub), duration: delay})
+		previous = current
+	}
+
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+
+	vp8x := make([]byte, 10)
+	vp8x[0] = 0x10 | 0x02 // alpha and animation
+	putUint24(vp8x[4:], canvasWidth-1)
+	putUint24(vp8x[7:], canvasHeight-1)
+	if err := writeChunk(&body, "VP8X", vp8x); err != nil {
+		return err
+	}
+
+	// black background, loop forever
+	anim := []byte{0, 0, 0, 255, 0, 0}
+	if err := writeChunk(&body, "ANIM", anim); err != nil {
+		return err
+	}
+
+	for _, f := range frames {
+		var payload bytes.Buffer
+		header := make([]byte, 16)
+		putUint24(header[0:], f.rect.Min.X/2)
+		putUint24(header[3:], f.rect.Min.Y/2)
+		putUint24(header[6:], f.rect.Dx()-1)
+		putUint24(header[9:], f.rect.Dy()-1)
+		putUint24(header[12:], f.duration)
+		header[15] = 0 // alpha blending, no disposal
+		payload.Write(header)
+
+		if err := writeChunk(&payload, "VP8L", f.data); err != nil {
+			return err
+		}
+		if err := writeChunk(&body, "ANMF", payload.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return writeChunk(w, "RIFF", body.Bytes())
+}
+
+
+// ChangedNRGBABounds returns the smallest rectangle containing every pixel that differs between
+// two images of the same size, and false if the images are identical.
+func ChangedNRGBABounds(previous, current *image.NRGBA) (image.Rectangle, bool) {
+	bounds := current.Bounds()
+	minX, minY := bounds.Max.X, bounds.Max.Y
+	maxX, maxY := bounds.Min.X-1, bounds.Min.Y-1
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if previous.NRGBAAt(x, y) != current.NRGBAAt(x, y) {
+				if x < minX {
+					minX = x
+				}
+				if x > maxX {
+					maxX = x
+				}
+				if y < minY {
+					minY = y
+				}
+				if y > maxY {
+					maxY = y
+				}
+			}
+		}
+	}
+
+	if maxX < minX {
+		return image.Rectangle{}, false
+	}
+	return image.Rect(minX, minY, maxX+1, maxY+1), true
+}
+
+
+// WriteAnimatedWebP writes a sequence of images as an animated WebP named fileName + ".out.webp".
+// Input:
+//   - images: slice of images of the same size.
+//   - fileName: output name without extension.
+//   - delay: display time of each image in milliseconds.
+// Output:
+//   - an error if the file cannot be written.
+func WriteAnimatedWebP(images []image.Image, fileName string, delay int) error {
+	file, err := os.Create(fileName + ".out.webp")
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	err = EncodeAnimatedWebP(w, images, delay)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
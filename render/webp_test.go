@@ -0,0 +1,333 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the animated lossless WebP encoder in webp.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package render
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type PrefixEncodeTestCases struct {
+	id        string
+	value     int
+	symbol    int
+	extra     uint32
+	extraBits uint
+}
+
+type HuffmanLengthsTestCases struct {
+	id          string
+	frequencies []int
+	maxLength   int
+	lengths     []uint8
+	codes       []uint32
+}
+
+type AnimatedWebPTestCases struct {
+	id      string
+	width   int
+	height  int
+	delay   int
+	changed [][]int // x, y of every pixel changed by the second frame
+	frames  [][]int // x, y, width, height, duration of every expected ANMF chunk
+}
+
+
+// ReadIntFields parses a list of integers separated by sep, such as "1,2,3".
+// Input: text (string) - the list; sep (string) - the separator.
+// Output: slice of the integers.
+func ReadIntFields(text, sep string) []int {
+	var values []int
+	for _, field := range strings.Split(text, sep) {
+		v, err := strconv.Atoi(strings.TrimSpace(field))
+		nbody.Check(err)
+		values = append(values, v)
+	}
+	return values
+}
+
+
+// ReadPrefixEncode reads test data for PrefixEncode from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of PrefixEncodeTestCases structs containing the values and their expected prefix coding.
+func ReadPrefixEncode(fileName string) []PrefixEncodeTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []PrefixEncodeTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+
+		values := ReadIntFields(strings.Join(parts[1:], "|"), "|")
+		tests = append(tests, PrefixEncodeTestCases{
+			id:        strings.TrimSpace(parts[0]),
+			value:     values[0],
+			symbol:    values[1],
+			extra:     uint32(values[2]),
+			extraBits: uint(values[3]),
+		})
+	}
+
+	return tests
+}
+
+
+// TestPrefixEncode tests that PrefixEncode gives the prefix symbol and extra bits of the WebP lossless
+// specification, and that decoding them as the specification does gives the value back.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestPrefixEncode(t *testing.T) {
+	tests := ReadPrefixEncode("../Tests/PrefixEncode.txt")
+
+	for _, test := range tests {
+		symbol, extra, extraBits := PrefixEncode(test.value)
+		if symbol != test.symbol || extra != test.extra || extraBits != test.extraBits {
+			t.Errorf("TestPrefixEncode(test %v) = (%d, %d, %d), want (%d, %d, %d)",
+				test.id, symbol, extra, extraBits, test.symbol, test.extra, test.extraBits)
+		}
+
+		// the decoder of the specification
+		decoded := symbol + 1
+		if symbol >= 4 {
+			bits := (symbol - 2) >> 1
+			decoded = (2+symbol&1)<<bits + int(extra) + 1
+		}
+		if decoded != test.value {
+			t.Errorf("TestPrefixEncode(test %v) decodes to %d, want %d", test.id, decoded, test.value)
+		}
+	}
+}
+
+
+// ReadHuffmanLengths reads test data for HuffmanLengths and CanonicalCode from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of HuffmanLengthsTestCases structs containing the frequencies and the expected lengths and codes.
+func ReadHuffmanLengths(fileName string) []HuffmanLengthsTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []HuffmanLengthsTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+
+		maxLength, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+		nbody.Check(err)
+		var lengths []uint8
+		for _, l := range ReadIntFields(parts[3], ",") {
+			lengths = append(lengths, uint8(l))
+		}
+		var codes []uint32
+		for _, c := range ReadIntFields(parts[4], ",") {
+			codes = append(codes, uint32(c))
+		}
+
+		tests = append(tests, HuffmanLengthsTestCases{
+			id:          strings.TrimSpace(parts[0]),
+			frequencies: ReadIntFields(parts[1], ","),
+			maxLength:   maxLength,
+			lengths:     lengths,
+			codes:       codes,
+		})
+	}
+
+	return tests
+}
+
+
+// TestHuffmanLengths tests that HuffmanLengths gives the expected code lengths within the largest length,
+// and that CanonicalCode assigns the expected bit-reversed canonical codes to them.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestHuffmanLengths(t *testing.T) {
+	tests := ReadHuffmanLengths("../Tests/HuffmanLengths.txt")
+
+	for _, test := range tests {
+		lengths := HuffmanLengths(test.frequencies, test.maxLength)
+		if !reflect.DeepEqual(lengths, test.lengths) {
+			t.Errorf("TestHuffmanLengths(test %v) = %v, want %v", test.id, lengths, test.lengths)
+			continue
+		}
+		if code := CanonicalCode(lengths); !reflect.DeepEqual(code.codes, test.codes) {
+			t.Errorf("TestHuffmanLengths(test %v) codes = %v, want %v", test.id, code.codes, test.codes)
+		}
+	}
+}
+
+
+// ReadAnimatedWebP reads test data for EncodeAnimatedWebP from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of AnimatedWebPTestCases structs containing the two frames and the expected ANMF chunks.
+func ReadAnimatedWebP(fileName string) []AnimatedWebPTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []AnimatedWebPTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 6 {
+			continue
+		}
+
+		size := ReadIntFields(strings.Join(parts[1:4], "|"), "|")
+		test := AnimatedWebPTestCases{
+			id:     strings.TrimSpace(parts[0]),
+			width:  size[0],
+			height: size[1],
+			delay:  size[2],
+		}
+		if changed := strings.TrimSpace(parts[4]); changed != "none" {
+			for _, pixel := range strings.Split(changed, ";") {
+				test.changed = append(test.changed, ReadIntFields(pixel, ","))
+			}
+		}
+		for _, frame := range strings.Split(parts[5], ";") {
+			test.frames = append(test.frames, ReadIntFields(frame, ","))
+		}
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// readWebPChunk returns the FourCC and the payload of the RIFF chunk at the start of data, and the data after it,
+// including the padding byte of an odd payload.
+func readWebPChunk(data []byte) (string, []byte, []byte, bool) {
+	if len(data) < 8 {
+		return "", nil, nil, false
+	}
+	size := int(binary.LittleEndian.Uint32(data[4:8]))
+	end := 8 + size + size%2
+	if end > len(data) {
+		return "", nil, nil, false
+	}
+	return string(data[:4]), data[8 : 8+size], data[end:], true
+}
+
+
+// uint24 reads a little endian 24-bit value.
+func uint24(b []byte) int {
+	return int(b[0]) | int(b[1])<<8 | int(b[2])<<16
+}
+
+
+// TestEncodeAnimatedWebP tests the container written by EncodeAnimatedWebP for two frames: the RIFF size, the
+// VP8X and ANIM chunks, and the offset, size, and duration of every ANMF chunk and of its VP8L bitstream.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestEncodeAnimatedWebP(t *testing.T) {
+	tests := ReadAnimatedWebP("../Tests/AnimatedWebP.txt")
+
+	for _, test := range tests {
+		first := image.NewNRGBA(image.Rect(0, 0, test.width, test.height))
+		for i := 3; i < len(first.Pix); i += 4 {
+			first.Pix[i] = 255
+		}
+		first.SetNRGBA(1, 1, color.NRGBA{R: 255, A: 255})
+		second := image.NewNRGBA(first.Bounds())
+		copy(second.Pix, first.Pix)
+		for _, pixel := range test.changed {
+			second.SetNRGBA(pixel[0], pixel[1], color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+		}
+
+		var output bytes.Buffer
+		nbody.Check(EncodeAnimatedWebP(&output, []image.Image{first, second}, test.delay))
+
+		fourCC, body, rest, ok := readWebPChunk(output.Bytes())
+		if !ok || fourCC != "RIFF" || len(rest) != 0 || string(body[:4]) != "WEBP" {
+			t.Errorf("TestEncodeAnimatedWebP(test %v) is not one RIFF WEBP chunk of %d bytes", test.id, output.Len())
+			continue
+		}
+
+		var names []string
+		var frames [][]int
+		for data := body[4:]; len(data) > 0; {
+			var payload []byte
+			fourCC, payload, data, ok = readWebPChunk(data)
+			if !ok {
+				t.Errorf("TestEncodeAnimatedWebP(test %v) has a chunk larger than the file", test.id)
+				break
+			}
+			names = append(names, fourCC)
+
+			switch fourCC {
+			case "VP8X":
+				if len(payload) != 10 || payload[0] != 0x12 || uint24(payload[4:]) != test.width-1 || uint24(payload[7:]) != test.height-1 {
+					t.Errorf("TestEncodeAnimatedWebP(test %v) VP8X = %v, want flags 0x12 and canvas %dx%d", test.id, payload, test.width, test.height)
+				}
+			case "ANIM":
+				if !bytes.Equal(payload, []byte{0, 0, 0, 255, 0, 0}) {
+					t.Errorf("TestEncodeAnimatedWebP(test %v) ANIM = %v, want a black background and an endless loop", test.id, payload)
+				}
+			case "ANMF":
+				frame := []int{2 * uint24(payload[0:]), 2 * uint24(payload[3:]), uint24(payload[6:]) + 1, uint24(payload[9:]) + 1, uint24(payload[12:])}
+				frames = append(frames, frame)
+
+				// the frame holds exactly one VP8L bitstream of its size
+				name, bitstream, after, ok := readWebPChunk(payload[16:])
+				if !ok || name != "VP8L" || len(after) != 0 || len(bitstream) < 5 || bitstream[0] != 0x2f {
+					t.Errorf("TestEncodeAnimatedWebP(test %v, frame %d) does not hold one VP8L chunk", test.id, len(frames))
+					continue
+				}
+				header := binary.LittleEndian.Uint32(bitstream[1:5])
+				if width, height := int(header&0x3fff)+1, int(header>>14&0x3fff)+1; width != frame[2] || height != frame[3] {
+					t.Errorf("TestEncodeAnimatedWebP(test %v, frame %d) VP8L is %dx%d, want %dx%d",
+						test.id, len(frames), width, height, frame[2], frame[3])
+				}
+			}
+		}
+
+		expectedNames := []string{"VP8X", "ANIM"}
+		for range test.frames {
+			expectedNames = append(expectedNames, "ANMF")
+		}
+		if !reflect.DeepEqual(names, expectedNames) {
+			t.Errorf("TestEncodeAnimatedWebP(test %v) chunks = %v, want %v", test.id, names, expectedNames)
+		}
+		if !reflect.DeepEqual(frames, test.frames) {
+			t.Errorf("TestEncodeAnimatedWebP(test %v) frames = %v, want %v", test.id, frames, test.frames)
+		}
+	}
+}
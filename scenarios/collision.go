@@ -0,0 +1,402 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Geometry of the "collision" scenario (options "-galaxies", "-positions", "-separation", "-impact",
+// "-approach", "-push", and "-push-angle").
+
+package scenarios
+
+import (
+	"BarnesHut/nbody"
+	"flag"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CollisionGeometry places the galaxies of the "collision" scenario and sets them in motion.
+// With positions, the galaxies start there and are pushed toward their common center; two galaxies are pushed
+// with GalaxyPush, which also takes the impact parameter and the push angle. Without positions, the
+// galaxies start on a ring around the center of the universe, every one turned by 360 / numGalaxies degrees from
+// the previous one: galaxy 0 starts separation / 2 behind the center along the approach direction and impact / 2
+// to its right, and moves along the approach direction. Two galaxies then move on parallel lines impact apart
+// (0 is head-on), and more galaxies fall in like the spokes of a wheel, passing the center on the same side.
+// The push angle turns the motion of every galaxy clockwise, as for GalaxyPush.
+type CollisionGeometry struct {
+	numGalaxies int
+	positions   []nbody.OrderedPair // centers of the galaxies; nil places them on the ring
+	separation  float64       // diameter of the ring: the starting distance of two head-on galaxies
+	impact      float64       // distance between the approach lines of two galaxies
+	approach    float64       // direction in which galaxy 0 moves, in degrees counterclockwise from the x axis
+	push        float64       // speed of every galaxy in m/s
+	pushAngle   float64       // angle between the push and the approach direction, in degrees clockwise
+}
+
+// collisionGeometry is the geometry of the "collision" scenario, set from the options of AddCollisionFlags.
+var collisionGeometry = DefaultCollisionGeometry()
+
+// collisionWidth is the width of the universe of the scenario, and collisionStars and collisionRadius are the
+// number of stars and the radius of every galaxy.
+const (
+	collisionWidth  = 1.0e23
+	collisionStars  = 500
+	collisionRadius = 4e21
+)
+
+
+// DefaultCollisionGeometry returns the geometry of the original "collision" scenario: two galaxies at
+// (7e22, 2e22) and (3e22, 7e22) pushed toward each other at 5e3 m/s, a speed found after multiple tests.
+// If you push them too fast, they just fly through each other; too slow and the black holes at the center
+// collide and hilarity ensues. The ring values are those of the same pair: 6.4e22 apart and head-on.
+func DefaultCollisionGeometry() CollisionGeometry {
+	return CollisionGeometry{
+		numGalaxies: 2,
+		positions:   []nbody.OrderedPair{{X: 7e22, Y: 2e22}, {X: 3e22, Y: 7e22}},
+		separation:  6.4e22,
+		push:        5e3,
+	}
+}
+
+
+// AddCollisionFlags defines the options of the collision geometry on a flag set.
+// Their defaults are only placeholders: ApplyCollisionFlags uses the options given on the command line.
+func AddCollisionFlags(flags *flag.FlagSet) {
+	flags.Int("galaxies", 2, "number of galaxies of the collision command, placed on a ring")
+	flags.String("positions", "", "centers x0,y0,x1,y1,... of the galaxies of the collision command in m")
+	flags.Float64("separation", 6.4e22, "starting distance between two galaxies of the collision command across the ring in m")
+	flags.Float64("impact", 0, "impact parameter of the galaxies of the collision command in m (0 for head-on)")
+	flags.Float64("approach", 0, "direction of approach of the first galaxy of the collision command in degrees from the x axis")
+	flags.Float64("push", 5e3, "speed of the galaxies of the collision command in m/s")
+	flags.Float64("push-angle", 0, "angle between the push of the galaxies of the collision command and their approach line in degrees")
+}
+
+
+// ApplyCollisionFlags sets collisionGeometry from the options of AddCollisionFlags given on the command line.
+// Giving -galaxies, -separation, or -approach places the galaxies on the ring instead of the default positions;
+// -positions cannot be combined with them.
+// Input:
+//   - flags: the parsed flag set.
+// Output:
+//   - an error describing the first invalid value, or nil.
+func ApplyCollisionFlags(flags *flag.FlagSet) error {
+	geometry := DefaultCollisionGeometry()
+	ring, given := false, false
+	var err error
+
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "galaxies", "separation", "approach":
+			ring = true
+		case "positions":
+			given = true
+			geometry.positions, err = ParsePositions(f.Value.String())
+			geometry.numGalaxies = len(geometry.positions)
+		}
+		getter, ok := f.Value.(flag.Getter)
+		if !ok {
+			return
+		}
+		switch value := getter.Get().(type) {
+		case int:
+			if f.Name == "galaxies" {
+				geometry.numGalaxies = value
+			}
+		case float64:
+			switch f.Name {
+			case "separation":
+				geometry.separation = value
+			case "impact":
+				geometry.impact = value
+			case "approach":
+				geometry.approach = value
+			case "push":
+				geometry.push = value
+			case "push-angle":
+				geometry.pushAngle = value
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if ring && given {
+		return fmt.Errorf("-positions places the galaxies itself; do not combine it with -galaxies, -separation, or -approach")
+	}
+	if ring {
+		geometry.positions = nil
+	}
+	if err := geometry.Validate(collisionWidth); err != nil {
+		return err
+	}
+
+	collisionGeometry = geometry
+	return nil
+}
+
+
+// ParsePositions parses a list x0,y0,x1,y1,... of the centers of galaxies.
+func ParsePositions(list string) ([]nbody.OrderedPair, error) {
+	fields := strings.Split(list, ",")
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("positions %q must be pairs of coordinates x,y", list)
+	}
+
+	positions := make([]nbody.OrderedPair, len(fields)/2)
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid coordinate %q in positions %q", field, list)
+		}
+		if i%2 == 0 {
+			positions[i/2].X = value
+		} else {
+			positions[i/2].Y = value
+		}
+	}
+	return positions, nil
+}
+
+
+// Validate returns an error if the geometry has fewer than two galaxies, a negative speed, or a galaxy center
+// outside a universe of the given width.
+func (c CollisionGeometry) Validate(width float64) error {
+	switch {
+	case c.numGalaxies < 2:
+		return fmt.Errorf("a collision needs at least 2 galaxies, got %d", c.numGalaxies)
+	case c.push < 0:
+		return fmt.Errorf("push speed must not be negative, got %v", c.push)
+	case c.positions == nil && c.separation <= 0:
+		return fmt.Errorf("separation must be positive, got %v", c.separation)
+	case c.impact < 0:
+		return fmt.Errorf("impact parameter must not be negative, got %v", c.impact)
+	case math.Abs(c.pushAngle) >= 90:
+		return fmt.Errorf("push angle must be between -90 and 90 degrees, got %v", c.pushAngle)
+	case c.positions != nil && len(c.positions) != 2 && (c.impact != 0 || c.pushAngle != 0):
+		return fmt.Errorf("the impact parameter and the push angle of galaxies at given positions need exactly 2 galaxies")
+	}
+
+	for k, p := range c.Centers(width) {
+		if p.X < 0 || p.X > width || p.Y < 0 || p.Y > width {
+			return fmt.Errorf("galaxy %d starts at (%g, %g), outside the universe of width %g", k, p.X, p.Y, width)
+		}
+	}
+	return nil
+}
+
+
+// Centers returns the starting centers of the galaxies in a universe of the given width.
+func (c CollisionGeometry) Centers(width float64) []nbody.OrderedPair {
+	if c.positions != nil {
+		return c.positions
+	}
+	centers := make([]nbody.OrderedPair, c.numGalaxies)
+	for k := range centers {
+		start, _ := c.ringStart(k)
+		centers[k] = nbody.OrderedPair{X: width/2 + start.X, Y: width/2 + start.Y}
+	}
+	return centers
+}
+
+
+// ringStart returns the position of galaxy k on the ring, relative to its center, and the direction it is pushed in.
+func (c CollisionGeometry) ringStart(k int) (nbody.OrderedPair, nbody.OrderedPair) {
+	angle := c.approach*math.Pi/180 + 2*math.Pi*float64(k)/float64(c.numGalaxies)
+	cos, sin := math.Cos(angle), math.Sin(angle)
+	push := angle - c.pushAngle*math.Pi/180
+	// behind the center along the direction of approach, and to its right
+	return nbody.OrderedPair{X: -c.separation/2*cos + c.impact/2*sin, Y: -c.separation/2*sin - c.impact/2*cos},
+		nbody.OrderedPair{X: math.Cos(push), Y: math.Sin(push)}
+}
+
+
+// Galaxies returns the galaxies of the geometry in a universe of the given width, in motion.
+// Input:
+//   - numStars: number of stars of every galaxy.
+//   - radius: radius of every galaxy.
+//   - width: width of the universe.
+// Output:
+//   - the galaxies, in the order of their centers.
+func (c CollisionGeometry) Galaxies(numStars int, radius, width float64) []nbody.Galaxy {
+	centers := c.Centers(width)
+	galaxies := make([]nbody.Galaxy, len(centers))
+	for k, p := range centers {
+		galaxies[k] = nbody.InitializeGalaxy(numStars, radius, p.X, p.Y)
+	}
+
+	switch {
+	case c.positions == nil:
+		for k, g := range galaxies {
+			_, direction := c.ringStart(k)
+			for _, s := range g {
+				s.Velocity.X += c.push * direction.X
+				s.Velocity.Y += c.push * direction.Y
+			}
+		}
+	case len(galaxies) == 2:
+		GalaxyPush(galaxies[0], galaxies[1], c.push, c.impact, c.pushAngle)
+	default:
+		PushTogether(galaxies, c.push)
+	}
+	return galaxies
+}
+
+
+// PushTogether pushes every galaxy with speed v toward the mean of the centers of all galaxies (GalaxyCenter),
+// which for two galaxies is what GalaxyPush does head-on.
+func PushTogether(galaxies []nbody.Galaxy, v float64) {
+	centers := make([]nbody.OrderedPair, len(galaxies))
+	var mean nbody.OrderedPair
+	for k, g := range galaxies {
+		centers[k] = GalaxyCenter(g)
+		mean.X += centers[k].X / float64(len(galaxies))
+		mean.Y += centers[k].Y / float64(len(galaxies))
+	}
+
+	for k, g := range galaxies {
+		dx, dy, d := nbody.Distance(mean, centers[k])
+		if d == 0 {
+			continue
+		}
+		for _, s := range g {
+			s.Velocity.X += v * dx / d
+			s.Velocity.Y += v * dy / d
+		}
+	}
+}
+
+
+// GalaxyPush applies a velocity "push" to two galaxies in opposite directions along the line connecting their centers.
+// With an impact parameter or an angle, the galaxies pass each other off center instead of colliding head-on:
+//   - impact moves g0 by impact / 2 to the right of the line of centers (seen from g0) and g1 by impact / 2 to the
+//     left, so the galaxies approach on parallel lines impact apart.
+//   - angle turns the push of g0 clockwise and that of g1 with it, giving the push a tangential component.
+//
+// Positive values make the galaxies go around each other counterclockwise, the sense in which the galaxies of
+// InitializeGalaxy spin, so the encounter is prograde. Pushes above ParabolicSpeed put the galaxies on a
+// hyperbolic orbit, and slower ones on a bound orbit.
+// Input:
+//   - g0: first Galaxy (slice of *Star).
+//   - g1: second Galaxy (slice of *Star).
+//   - v: magnitude of the velocity to apply.
+//   - impact: impact parameter in m (0 for head-on).
+//   - angle: angle between the push and the line of centers in degrees (0 for head-on).
+// Output:
+//   - None (modifies the positions and velocities of the stars in place).
+func GalaxyPush(g0, g1 nbody.Galaxy, v, impact, angle float64) {
+	// center of the galaxies is needed for computing the distance
+	center_0 := GalaxyCenter(g0)
+	center_1 := GalaxyCenter(g1)
+
+	d_x := center_1.X - center_0.X
+	d_y := center_1.Y - center_0.Y 
+	distance := math.Sqrt(d_x * d_x + d_y * d_y)
+
+	// if two galaxies are at same position
+	if distance == 0 {
+		// slightly change the position
+		d_x, d_y = 1e-3, 0
+		distance = 1e-3
+	}
+
+	// else, simply calculate the pushing direction and velocity
+	// the pushing directions for two galaxies are opposite.
+	dir_0 := nbody.OrderedPair{X: d_x / distance, Y: d_y / distance}
+	dir_1 := nbody.OrderedPair{X: -d_x / distance, Y: -d_y / distance}
+
+	// move the galaxies apart across the line of centers, g0 to its right
+	if impact != 0 {
+		shift := nbody.OrderedPair{X: impact / 2 * dir_0.Y, Y: -impact / 2 * dir_0.X}
+		for _, s := range g0 {
+			s.Position.X += shift.X
+			s.Position.Y += shift.Y
+		}
+		for _, s := range g1 {
+			s.Position.X -= shift.X
+			s.Position.Y -= shift.Y
+		}
+	}
+
+	// turn the pushes clockwise by the angle
+	if angle != 0 {
+		cos, sin := math.Cos(angle * math.Pi / 180), math.Sin(angle * math.Pi / 180)
+		dir_0 = nbody.OrderedPair{X: cos * dir_0.X + sin * dir_0.Y, Y: cos * dir_0.Y - sin * dir_0.X}
+		dir_1 = nbody.OrderedPair{X: -dir_0.X, Y: -dir_0.Y}
+	}
+
+	// update the velocities
+	for _, s := range g0 {
+		s.Velocity.X += v * dir_0.X
+		s.Velocity.Y += v * dir_0.Y
+	}
+
+	for _, s := range g1 {
+		s.Velocity.X += v * dir_1.X
+		s.Velocity.Y += v * dir_1.Y
+	}
+
+}
+
+
+// ParabolicSpeed returns the push of GalaxyPush that puts two galaxies, seen as point masses at their centers of
+// mass, on a parabolic orbit: half their escape speed sqrt(2 G (M0 + M1) / r) from each other.
+// The impact shift of GalaxyPush moves the galaxies slightly apart, so with an impact parameter the orbit is
+// slightly hyperbolic.
+// Input:
+//   - g0, g1: the Galaxies before the push.
+// Output:
+//   - the speed in m/s.
+func ParabolicSpeed(g0, g1 nbody.Galaxy) float64 {
+	mass := 0.0
+	for _, s := range append(append([]*nbody.Star(nil), g0...), g1...) {
+		mass += s.Mass
+	}
+	_, _, r := nbody.Distance(nbody.CenterOfMass(g0), nbody.CenterOfMass(g1))
+	return 0.5 * math.Sqrt(2 * nbody.G * mass / r)
+}
+
+
+// EncounterEccentricity returns the eccentricity of the relative orbit of two galaxies seen as point masses at their
+// centers of mass: below 1 they are bound, 1 is parabolic, and above 1 hyperbolic.
+// Input:
+//   - g0, g1: the Galaxies.
+// Output:
+//   - the eccentricity.
+func EncounterEccentricity(g0, g1 nbody.Galaxy) float64 {
+	var m0, m1 float64
+	var v0, v1 nbody.OrderedPair
+	for _, s := range g0 {
+		m0 += s.Mass
+		v0.X += s.Mass * s.Velocity.X
+		v0.Y += s.Mass * s.Velocity.Y
+	}
+	for _, s := range g1 {
+		m1 += s.Mass
+		v1.X += s.Mass * s.Velocity.X
+		v1.Y += s.Mass * s.Velocity.Y
+	}
+
+	mu := nbody.G * (m0 + m1)
+	rx, ry, r := nbody.Distance(nbody.CenterOfMass(g1), nbody.CenterOfMass(g0))
+	vx, vy := v1.X / m1 - v0.X / m0, v1.Y / m1 - v0.Y / m0
+	energy := 0.5 * (vx * vx + vy * vy) - mu / r
+	h := rx * vy - ry * vx
+	return math.Sqrt(math.Max(0, 1 + 2 * energy * h * h / (mu * mu)))
+}
+
+
+// GalaxyCenter computes the center (average position) of a galaxy.
+// Input:
+//   - g: Galaxy (slice of *Star).
+// Output:
+//   - OrderedPair representing the center position.
+func GalaxyCenter(g nbody.Galaxy) nbody.OrderedPair {
+	var c_x, c_y float64
+
+	for _, s := range g {
+		c_x += s.Position.X 
+		c_y += s.Position.Y 
+	}
+	n := float64(len(g))
+
+	return nbody.OrderedPair{X: c_x / n, Y: c_y / n}
+}
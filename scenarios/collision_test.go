@@ -0,0 +1,235 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the geometry of the collision scenario in collision.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package scenarios
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"flag"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type CollisionGeometryTestCases struct {
+	id          string
+	args        []string
+	numGalaxies int     // -1 if the options are invalid
+	impact      float64 // expected closest approach of galaxies 0 and 1 on straight lines, -1 to skip
+}
+
+
+// ReadCollisionGeometry reads test data for ApplyCollisionFlags and CollisionGeometry.Galaxies from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of CollisionGeometryTestCases structs containing the options and the expected galaxies.
+func ReadCollisionGeometry(fileName string) []CollisionGeometryTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []CollisionGeometryTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 3 {
+			continue
+		}
+
+		test := CollisionGeometryTestCases{id: strings.TrimSpace(parts[0]), args: strings.Fields(parts[1]), numGalaxies: -1}
+		if expected := strings.TrimSpace(parts[2]); expected != "error" {
+			fields := strings.Split(expected, ",")
+			test.numGalaxies, err = strconv.Atoi(strings.TrimSpace(fields[0]))
+			nbody.Check(err)
+			test.impact = -1
+			if field := strings.TrimSpace(fields[1]); field != "-" {
+				test.impact, err = strconv.ParseFloat(field, 64)
+				nbody.Check(err)
+			}
+		}
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestCollisionGeometry tests that the options of the collision scenario place the galaxies so that the first two
+// approach each other with the requested impact parameter, that invalid options are rejected, and that without
+// options the scenario is the original one: two galaxies pushed toward each other with GalaxyPush.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestCollisionGeometry(t *testing.T) {
+	tests := ReadCollisionGeometry("../Tests/CollisionGeometry.txt")
+	defer func() {
+		collisionGeometry = DefaultCollisionGeometry()
+	}()
+
+	for _, test := range tests {
+		collisionGeometry = DefaultCollisionGeometry()
+		flags := flag.NewFlagSet("collision", flag.ContinueOnError)
+		flags.SetOutput(io.Discard)
+		AddCollisionFlags(flags)
+		nbody.Check(flags.Parse(test.args))
+		err := ApplyCollisionFlags(flags)
+
+		if test.numGalaxies < 0 {
+			if err == nil {
+				t.Errorf("TestCollisionGeometry(test %v) error = nil, want an error", test.id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestCollisionGeometry(test %v) error = %v, want nil", test.id, err)
+			continue
+		}
+
+		nbody.SetDeterministic()
+		galaxies := collisionGeometry.Galaxies(20, collisionRadius, collisionWidth)
+		if len(galaxies) != test.numGalaxies {
+			t.Errorf("TestCollisionGeometry(test %v) has %d galaxies, want %d", test.id, len(galaxies), test.numGalaxies)
+			continue
+		}
+
+		// the black hole, the last star of a galaxy, starts at its center with the push of the galaxy;
+		// galaxies at given positions are pushed from their GalaxyCenter instead
+		b0, b1 := galaxies[0][len(galaxies[0])-1], galaxies[1][len(galaxies[1])-1]
+		c0, c1 := b0.Position, b1.Position
+		if collisionGeometry.positions != nil {
+			c0, c1 = GalaxyCenter(galaxies[0]), GalaxyCenter(galaxies[1])
+		}
+		rx, ry := c1.X-c0.X, c1.Y-c0.Y
+		wx, wy := b1.Velocity.X-b0.Velocity.X, b1.Velocity.Y-b0.Velocity.Y
+		closest := math.Abs(rx*wy-ry*wx) / math.Hypot(wx, wy)
+		if test.impact >= 0 && math.Abs(closest-test.impact) > 1e-6*collisionWidth {
+			t.Errorf("TestCollisionGeometry(test %v) closest approach = %v, want %v", test.id, closest, test.impact)
+		}
+
+		if len(test.args) == 0 {
+			nbody.SetDeterministic()
+			g0 := nbody.InitializeGalaxy(20, collisionRadius, 7e22, 2e22)
+			g1 := nbody.InitializeGalaxy(20, collisionRadius, 3e22, 7e22)
+			GalaxyPush(g0, g1, 5e3, 0, 0)
+			for i, s := range append(g0, g1...) {
+				if got := append(galaxies[0], galaxies[1]...)[i]; *got != *s {
+					t.Errorf("TestCollisionGeometry(test %v) star %d = %+v, want %+v", test.id, i, *got, *s)
+					break
+				}
+			}
+		}
+	}
+}
+
+
+type GalaxyPushTestCases struct {
+	id           string
+	factor       float64 // push in units of ParabolicSpeed
+	impact       float64
+	angle        float64
+	closest      float64 // closest approach of the galaxies if they moved on straight lines
+	eccentricity float64
+	sense        int // 1 if the galaxies go around each other counterclockwise, -1 clockwise, 0 head-on
+}
+
+
+// ReadGalaxyPush reads test data for GalaxyPush from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of GalaxyPushTestCases structs containing the push and the expected encounter.
+func ReadGalaxyPush(fileName string) []GalaxyPushTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []GalaxyPushTestCases
+	scanner := bufio.NewScanner(file)
+	senses := map[string]int{"ccw": 1, "cw": -1, "none": 0}
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 3 {
+			continue
+		}
+
+		push := strings.Fields(parts[1])
+		expected := strings.Fields(parts[2])
+		if len(push) != 3 || len(expected) != 3 {
+			continue
+		}
+
+		test := GalaxyPushTestCases{id: strings.TrimSpace(parts[0])}
+		test.factor, err = strconv.ParseFloat(push[0], 64)
+		nbody.Check(err)
+		test.impact, err = strconv.ParseFloat(push[1], 64)
+		nbody.Check(err)
+		test.angle, err = strconv.ParseFloat(push[2], 64)
+		nbody.Check(err)
+		test.closest, err = strconv.ParseFloat(expected[0], 64)
+		nbody.Check(err)
+		test.eccentricity, err = strconv.ParseFloat(expected[1], 64)
+		nbody.Check(err)
+		sense, ok := senses[expected[2]]
+		if !ok {
+			panic("unknown sense " + expected[2])
+		}
+		test.sense = sense
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestGalaxyPush tests that GalaxyPush sets two one-star galaxies 4e22 m apart on the encounter its impact parameter
+// and angle ask for, with ParabolicSpeed and EncounterEccentricity.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestGalaxyPush(t *testing.T) {
+	tests := ReadGalaxyPush("../Tests/GalaxyPush.txt")
+
+	for _, test := range tests {
+		g0 := nbody.Galaxy{nbody.NewStar(nbody.OrderedPair{X: 0, Y: 0}, nbody.OrderedPair{}, 1e36, 1)}
+		g1 := nbody.Galaxy{nbody.NewStar(nbody.OrderedPair{X: 4e22, Y: 0}, nbody.OrderedPair{}, 1e36, 1)}
+		GalaxyPush(g0, g1, test.factor*ParabolicSpeed(g0, g1), test.impact, test.angle)
+
+		s0, s1 := g0[0], g1[0]
+		rx, ry := s1.Position.X-s0.Position.X, s1.Position.Y-s0.Position.Y
+		wx, wy := s1.Velocity.X-s0.Velocity.X, s1.Velocity.Y-s0.Velocity.Y
+		h := rx*wy - ry*wx
+		closest := math.Abs(h) / math.Hypot(wx, wy)
+		sense := 0
+		if math.Abs(h) > 1e-9*math.Hypot(rx, ry)*math.Hypot(wx, wy) {
+			sense = int(math.Copysign(1, h))
+		}
+
+		if math.Abs(closest-test.closest) > 1e-9*4e22 {
+			t.Errorf("TestGalaxyPush(test %v) closest approach = %v, want %v", test.id, closest, test.closest)
+		}
+		if e := EncounterEccentricity(g0, g1); math.Abs(e-test.eccentricity) > 1e-6 {
+			t.Errorf("TestGalaxyPush(test %v) eccentricity = %v, want %v", test.id, e, test.eccentricity)
+		}
+		if sense != test.sense {
+			t.Errorf("TestGalaxyPush(test %v) sense = %d, want %d", test.id, sense, test.sense)
+		}
+		if s0.Velocity.X*1e36+s1.Velocity.X*1e36 != 0 || s0.Velocity.Y*1e36+s1.Velocity.Y*1e36 != 0 {
+			t.Errorf("TestGalaxyPush(test %v) total momentum is not zero", test.id)
+		}
+	}
+}
@@ -0,0 +1,452 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Scenario definitions read from TOML configuration files (option "-config").
+
+package scenarios
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/simulation"
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A configuration file defines a whole scenario, for example
+//
+//	width = 1e23           # universe width in meters
+//	height = 5e22          # optional: height of a rectangular universe in meters (default: the width)
+//	generations = 100000
+//	dt = 2e14              # seconds per generation
+//	theta = 0.5
+//	push = 5e3             # optional: push the first two galaxies toward each other (m/s, see GalaxyPush)
+//	impact = 1e22          # optional: let them pass each other this far apart (m, default 0 for head-on)
+//	push_angle = 20        # optional: turn their push clockwise by this angle (degrees, default 0)
+//
+//	[output]
+//	canvas = 1000          # frame width in pixels
+//	frequency = 1000       # generations between frames
+//	scale = 1e11           # star scaling factor
+//
+//	[[galaxy]]             # one table per galaxy
+//	stars = 500
+//	radius = 4e21
+//	x = 7e22
+//	y = 2e22
+//	vx = 0                 # optional velocity of the whole galaxy
+//	vy = 0
+//	kind = "galaxy"        # optional: "galaxy" (default), "disk", "king" (a star cluster, radius is its tidal
+//	                       # radius), or "composite" (disk, bulge, and halo, see GalaxyModel; stars and radius
+//	                       # are those of the disk)
+//	toomre_q = 1.5         # optional, for disks and composite galaxies (default: -toomre-q)
+//	w0 = 6                 # optional, for King clusters (default: -king-w0)
+//	mass = 2e35            # optional, total mass of a King cluster (default: 1e5 suns) or mass of the disk of a
+//	                       # composite galaxy (default: the black hole mass) in kg
+//	bulge_stars = 125      # optional, for composite galaxies (defaults: see DefaultGalaxyModel)
+//	bulge_mass = 2e36
+//	bulge_scale = 2e20
+//	halo_stars = 0         # 0 (default) makes the halo analytic; such galaxies cannot move
+//	halo_mass = 4e37
+//	halo_scale = 4e21
+//	halo_radius = 8e21     # halo stars are placed inside this radius
+//	black_hole_mass = 8e36
+//
+//	[[star]]               # one table per single star
+//	x = 5e22
+//	y = 5e22
+//	vx = 0
+//	vy = 0
+//	mass = 2e30
+//	radius = 7e8
+//	pinned = false         # optional: true for a star that pulls on the others but never moves
+//
+// The file is read with a small TOML subset: comments, "key = value" lines with numbers, strings, and booleans,
+// tables ("[name]"), and arrays of tables ("[[name]]"). Unknown keys are errors, so typos do not go unnoticed.
+
+// TOMLTable is a parsed TOML table. Its values are float64, string, bool, TOMLTable, or []TOMLTable.
+type TOMLTable map[string]any
+
+
+// ParseTOML parses the TOML subset used by configuration files.
+// Input:
+//   - r: reader of the file.
+// Output:
+//   - the top-level TOMLTable, or an error naming the offending line.
+func ParseTOML(r io.Reader) (TOMLTable, error) {
+	root := TOMLTable{}
+	current := root
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(stripTOMLComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		// array of tables: every header appends a new table
+		if strings.HasPrefix(line, "[[") {
+			name, ok := strings.CutSuffix(strings.TrimPrefix(line, "[["), "]]")
+			name = strings.TrimSpace(name)
+			if !ok || !isTOMLKey(name) {
+				return nil, fmt.Errorf("line %d: invalid array of tables %q", lineNumber, line)
+			}
+			tables, isArray := root[name].([]TOMLTable)
+			if _, exists := root[name]; exists && !isArray {
+				return nil, fmt.Errorf("line %d: %q is already defined", lineNumber, name)
+			}
+			current = TOMLTable{}
+			root[name] = append(tables, current)
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			name, ok := strings.CutSuffix(strings.TrimPrefix(line, "["), "]")
+			name = strings.TrimSpace(name)
+			if !ok || !isTOMLKey(name) {
+				return nil, fmt.Errorf("line %d: invalid table %q", lineNumber, line)
+			}
+			if _, exists := root[name]; exists {
+				return nil, fmt.Errorf("line %d: %q is already defined", lineNumber, name)
+			}
+			current = TOMLTable{}
+			root[name] = current
+			continue
+		}
+
+		key, text, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !isTOMLKey(key) {
+			return nil, fmt.Errorf("line %d: expected key = value, got %q", lineNumber, line)
+		}
+		if _, exists := current[key]; exists {
+			return nil, fmt.Errorf("line %d: duplicate key %q", lineNumber, key)
+		}
+		value, err := parseTOMLValue(strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		current[key] = value
+	}
+
+	return root, scanner.Err()
+}
+
+
+// stripTOMLComment removes a comment starting with "#" outside of a string.
+func stripTOMLComment(line string) string {
+	inString, escaped := false, false
+	for i, c := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\' && inString:
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case c == '#' && !inString:
+			return line[:i]
+		}
+	}
+	return line
+}
+
+
+// isTOMLKey reports whether s is a bare key: letters, digits, "_", and "-".
+func isTOMLKey(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+
+// parseTOMLValue parses a string, boolean, or number (with optional "_" separators).
+func parseTOMLValue(text string) (any, error) {
+	switch {
+	case text == "true":
+		return true, nil
+	case text == "false":
+		return false, nil
+	case strings.HasPrefix(text, `"`):
+		s, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", text)
+		}
+		return s, nil
+	}
+
+	v, err := strconv.ParseFloat(strings.ReplaceAll(text, "_", ""), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q (use a number, a quoted string, true, or false)", text)
+	}
+	return v, nil
+}
+
+
+// configSection reads the values of one table of a configuration file and remembers the first error.
+type configSection struct {
+	name  string
+	table TOMLTable
+	err   error
+}
+
+
+// number returns the number at key, or def if the key is missing and not required.
+func (c *configSection) number(key string, def float64, required bool) float64 {
+	value, ok := c.table[key]
+	if !ok {
+		if required && c.err == nil {
+			c.err = fmt.Errorf("%s: missing %q", c.name, key)
+		}
+		return def
+	}
+	v, ok := value.(float64)
+	if !ok && c.err == nil {
+		c.err = fmt.Errorf("%s: %q must be a number", c.name, key)
+	}
+	return v
+}
+
+
+// boolean returns the boolean at key, or def if the key is missing.
+func (c *configSection) boolean(key string, def bool) bool {
+	value, ok := c.table[key]
+	if !ok {
+		return def
+	}
+	b, ok := value.(bool)
+	if !ok && c.err == nil {
+		c.err = fmt.Errorf("%s: %q must be true or false", c.name, key)
+	}
+	return b
+}
+
+
+// text returns the string at key, or def if the key is missing.
+func (c *configSection) text(key, def string) string {
+	value, ok := c.table[key]
+	if !ok {
+		return def
+	}
+	s, ok := value.(string)
+	if !ok && c.err == nil {
+		c.err = fmt.Errorf("%s: %q must be a string", c.name, key)
+	}
+	return s
+}
+
+
+// allow records an error if the table has keys other than the given ones.
+func (c *configSection) allow(keys ...string) {
+	var unknown []string
+	for key := range c.table {
+		known := false
+		for _, k := range keys {
+			known = known || k == key
+		}
+		if !known {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	if len(unknown) > 0 && c.err == nil {
+		c.err = fmt.Errorf("%s: unknown key %q", c.name, unknown[0])
+	}
+}
+
+
+// ScenarioFromConfig builds a Scenario from a parsed configuration file.
+// Input:
+//   - doc: the top-level TOMLTable (see the format above).
+// Output:
+//   - the Scenario, or an error describing the first missing, unknown, or invalid value.
+func ScenarioFromConfig(doc TOMLTable) (Scenario, error) {
+	top := &configSection{name: "configuration", table: doc}
+	top.allow("width", "height", "generations", "dt", "theta", "push", "impact", "push_angle", "output", "galaxy", "star")
+
+	scenario := Scenario{
+		Width:   top.number("width", 0, true),
+		NumGens: int(top.number("generations", 0, true)),
+		Time:    top.number("dt", 0, true),
+		Theta:   top.number("theta", 0.5, false),
+	}
+	height := top.number("height", 0, false)
+	push := top.number("push", 0, false)
+	impact, pushAngle := top.number("impact", 0, false), top.number("push_angle", 0, false)
+	if top.err != nil {
+		return Scenario{}, top.err
+	}
+
+	outputTable, ok := doc["output"].(TOMLTable)
+	if _, exists := doc["output"]; exists && !ok {
+		return Scenario{}, fmt.Errorf("configuration: [output] must be a table")
+	}
+	output := &configSection{name: "[output]", table: outputTable}
+	output.allow("canvas", "frequency", "scale")
+	scenario.CanvasWidth = int(output.number("canvas", 1000, false))
+	scenario.Frequency = int(output.number("frequency", 1000, false))
+	scenario.ScalingFactor = output.number("scale", 1, false)
+	if output.err != nil {
+		return Scenario{}, output.err
+	}
+
+	galaxyTables, ok := doc["galaxy"].([]TOMLTable)
+	if _, exists := doc["galaxy"]; exists && !ok {
+		return Scenario{}, fmt.Errorf("configuration: galaxies must be given as [[galaxy]] tables")
+	}
+	var galaxies []nbody.Galaxy
+	for i, table := range galaxyTables {
+		c := &configSection{name: fmt.Sprintf("galaxy %d", i+1), table: table}
+		c.allow("stars", "radius", "x", "y", "vx", "vy", "kind", "toomre_q", "w0", "mass",
+			"bulge_stars", "bulge_mass", "bulge_scale", "halo_stars", "halo_mass", "halo_scale", "halo_radius", "black_hole_mass")
+		numStars := int(c.number("stars", 0, true))
+		r, x, y := c.number("radius", 0, true), c.number("x", 0, true), c.number("y", 0, true)
+		vx, vy := c.number("vx", 0, false), c.number("vy", 0, false)
+		kind := c.text("kind", "galaxy")
+		q := c.number("toomre_q", ToomreQ, false)
+		defaultMass := 1e5 * nbody.SolarMass
+		if kind == "composite" {
+			defaultMass = nbody.BlackHoleMass
+		}
+		w0, mass := c.number("w0", KingW0, false), c.number("mass", defaultMass, false)
+		model := DefaultGalaxyModel(numStars, r, q)
+		model.diskMass = mass
+		model.bulgeStars = int(c.number("bulge_stars", float64(model.bulgeStars), false))
+		model.bulgeMass = c.number("bulge_mass", model.bulgeMass, false)
+		model.bulgeScale = c.number("bulge_scale", model.bulgeScale, false)
+		model.haloStars = int(c.number("halo_stars", 0, false))
+		model.haloMass = c.number("halo_mass", model.haloMass, false)
+		model.haloScale = c.number("halo_scale", model.haloScale, false)
+		model.haloRadius = c.number("halo_radius", model.haloRadius, false)
+		model.blackHoleMass = c.number("black_hole_mass", model.blackHoleMass, false)
+		if c.err != nil {
+			return Scenario{}, c.err
+		}
+		if numStars < 1 || r <= 0 {
+			return Scenario{}, fmt.Errorf("%s: needs at least one star and a positive radius", c.name)
+		}
+
+		var g nbody.Galaxy
+		switch kind {
+		case "galaxy":
+			g = nbody.InitializeGalaxy(numStars, r, x, y)
+		case "disk":
+			g = InitializeDisk(numStars, r, x, y, q)
+		case "king":
+			if err := CheckKingW0(w0); err != nil {
+				return Scenario{}, fmt.Errorf("%s: %w", c.name, err)
+			}
+			if mass <= 0 {
+				return Scenario{}, fmt.Errorf("%s: mass must be positive", c.name)
+			}
+			g = KingModel(numStars, w0, mass, r, nbody.OrderedPair{X: x, Y: y}, 0)
+		case "composite":
+			if err := model.Validate(); err != nil {
+				return Scenario{}, fmt.Errorf("%s: %w", c.name, err)
+			}
+			var halos []simulation.Halo
+			g, halos = InitializeGalaxyModel(model, x, y)
+			// an analytic halo stays where it is, so its galaxy must not move
+			if len(halos) > 0 && (vx != 0 || vy != 0 || (push != 0 || impact != 0) && i < 2) {
+				return Scenario{}, fmt.Errorf("%s: an analytic halo cannot move; give the halo stars (halo_stars)", c.name)
+			}
+			scenario.Halos = append(scenario.Halos, halos...)
+		default:
+			return Scenario{}, fmt.Errorf("%s: unknown kind %q (use galaxy, disk, king, or composite)", c.name, kind)
+		}
+		for _, s := range g {
+			s.Velocity.X += vx
+			s.Velocity.Y += vy
+		}
+		galaxies = append(galaxies, g)
+	}
+
+	if height < 0 {
+		return Scenario{}, fmt.Errorf("configuration: height must be positive, got %v", height)
+	}
+	if (impact != 0 || pushAngle != 0) && push == 0 {
+		return Scenario{}, fmt.Errorf("configuration: impact and push_angle need a push")
+	}
+	if math.Abs(pushAngle) >= 90 {
+		return Scenario{}, fmt.Errorf("configuration: push_angle must be between -90 and 90 degrees, got %v", pushAngle)
+	}
+	if push != 0 {
+		if len(galaxies) < 2 {
+			return Scenario{}, fmt.Errorf("configuration: push needs two galaxies")
+		}
+		GalaxyPush(galaxies[0], galaxies[1], push, impact, pushAngle)
+	}
+
+	u := nbody.NewUniverse(scenario.Width)
+	u.Height = height
+	for _, g := range galaxies {
+		u.AddGalaxy(g)
+	}
+
+	starTables, ok := doc["star"].([]TOMLTable)
+	if _, exists := doc["star"]; exists && !ok {
+		return Scenario{}, fmt.Errorf("configuration: stars must be given as [[star]] tables")
+	}
+	for i, table := range starTables {
+		c := &configSection{name: fmt.Sprintf("star %d", i+1), table: table}
+		c.allow("x", "y", "vx", "vy", "mass", "radius", "pinned")
+		position := nbody.OrderedPair{X: c.number("x", 0, true), Y: c.number("y", 0, true)}
+		velocity := nbody.OrderedPair{X: c.number("vx", 0, false), Y: c.number("vy", 0, false)}
+		mass, radius := c.number("mass", 0, true), c.number("radius", 0, true)
+		pinned := c.boolean("pinned", false)
+		if c.err != nil {
+			return Scenario{}, c.err
+		}
+		if mass <= 0 {
+			return Scenario{}, fmt.Errorf("%s: mass must be positive", c.name)
+		}
+		s := nbody.NewStar(position, velocity, mass, radius)
+		s.Pinned = pinned
+		u.AddStars(s)
+	}
+
+	if len(u.Stars) == 0 {
+		return Scenario{}, fmt.Errorf("configuration: no [[galaxy]] or [[star]] defined")
+	}
+	scenario.InitialUniverse = u
+
+	return scenario, nil
+}
+
+
+// LoadConfig reads a configuration file and builds its Scenario.
+func LoadConfig(fileName string) (Scenario, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return Scenario{}, err
+	}
+	defer file.Close()
+
+	doc, err := ParseTOML(file)
+	if err != nil {
+		return Scenario{}, fmt.Errorf("%s: %w", fileName, err)
+	}
+	scenario, err := ScenarioFromConfig(doc)
+	if err != nil {
+		return Scenario{}, fmt.Errorf("%s: %w", fileName, err)
+	}
+	return scenario, nil
+}
+
+
+// ConfigName returns the name of a configuration file without directory and extension,
+// which replaces the scenario command in output names.
+func ConfigName(fileName string) string {
+	return strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+}
@@ -0,0 +1,106 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for configuration files in config.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package scenarios
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type ScenarioFromConfigTestCases struct {
+	id       string
+	config   string
+	numStars int
+	height   float64   // expected height of the universe
+	expected *Scenario // nil if the configuration is invalid
+}
+
+
+// ReadScenarioFromConfig reads test data for the ScenarioFromConfig function from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of ScenarioFromConfigTestCases structs containing the configuration and the expected scenario.
+func ReadScenarioFromConfig(fileName string) []ScenarioFromConfigTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []ScenarioFromConfigTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 3 {
+			continue
+		}
+
+		test := ScenarioFromConfigTestCases{
+			id:     strings.TrimSpace(parts[0]),
+			config: strings.ReplaceAll(parts[1], ";", "\n"),
+		}
+		if expected := strings.TrimSpace(parts[2]); expected != "error" {
+			var v [9]float64
+			for i, field := range strings.Split(expected, ",") {
+				v[i], err = strconv.ParseFloat(strings.TrimSpace(field), 64)
+				nbody.Check(err)
+			}
+			test.numStars, test.height = int(v[0]), v[8]
+			test.expected = &Scenario{Width: v[1], NumGens: int(v[2]), Time: v[3], Theta: v[4],
+				CanvasWidth: int(v[5]), Frequency: int(v[6]), ScalingFactor: v[7]}
+		}
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestScenarioFromConfig tests the ParseTOML and ScenarioFromConfig functions using data from a file.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestScenarioFromConfig(t *testing.T) {
+	tests := ReadScenarioFromConfig("../Tests/ScenarioFromConfig.txt")
+
+	for _, test := range tests {
+		doc, err := ParseTOML(strings.NewReader(test.config))
+		var scenario Scenario
+		if err == nil {
+			scenario, err = ScenarioFromConfig(doc)
+		}
+
+		if test.expected == nil {
+			if err == nil {
+				t.Errorf("TestScenarioFromConfig(test %v) error = nil, want an error", test.id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestScenarioFromConfig(test %v) error = %v, want nil", test.id, err)
+			continue
+		}
+
+		u := scenario.InitialUniverse
+		if len(u.Stars) != test.numStars || u.Width != test.expected.Width || u.Height != test.height {
+			t.Errorf("TestScenarioFromConfig(test %v) universe = %d stars of %v x %v, want %d stars of %v x %v",
+				test.id, len(u.Stars), u.Width, u.Height, test.numStars, test.expected.Width, test.height)
+		}
+		// the analytic halos of composite galaxies are tested with InitializeGalaxyModel
+		test.expected.InitialUniverse, test.expected.Halos = u, scenario.Halos
+		if !reflect.DeepEqual(scenario, *test.expected) {
+			t.Errorf("TestScenarioFromConfig(test %v) = %+v, want %+v", test.id, scenario, *test.expected)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for seeded scenarios in the deterministic mode (see nbody/deterministic.go).
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package scenarios
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestMain runs every test of the package in deterministic mode, so results are stable between runs.
+func TestMain(m *testing.M) {
+	nbody.SetDeterministic()
+	os.Exit(m.Run())
+}
+
+type SeedTestCases struct {
+	id      string
+	command string
+	seed    int64
+	other   int64 // a second seed, which must give other stars
+}
+
+
+// ReadSeed reads test data for SetSeed from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of SeedTestCases structs containing the scenarios and the seeds.
+func ReadSeed(fileName string) []SeedTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []SeedTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		var test SeedTestCases
+		test.id = strings.TrimSpace(parts[0])
+		test.command = strings.TrimSpace(parts[1])
+		test.seed, err = strconv.ParseInt(strings.TrimSpace(parts[2]), 10, 64)
+		nbody.Check(err)
+		test.other, err = strconv.ParseInt(strings.TrimSpace(parts[3]), 10, 64)
+		nbody.Check(err)
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// SeededScenario returns the initial universe of a scenario command generated after SetSeed(s).
+func SeededScenario(command string, s int64) *nbody.Universe {
+	nbody.SetSeed(s)
+	scenario, err := LoadScenario(command)
+	nbody.Check(err)
+	return scenario.InitialUniverse
+}
+
+
+// TestSeed checks that a scenario generated twice with the same seed has identical stars, and that another seed
+// gives other stars.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if the universes do not match expected.
+func TestSeed(t *testing.T) {
+	tests := ReadSeed("../Tests/Seed.txt")
+	defer nbody.SetDeterministic()
+
+	for _, test := range tests {
+		want := SeededScenario(test.command, test.seed)
+		got := SeededScenario(test.command, test.seed)
+		other := SeededScenario(test.command, test.other)
+
+		same := true
+		for i := range want.Stars {
+			if got.Stars[i].Position != want.Stars[i].Position || got.Stars[i].Velocity != want.Stars[i].Velocity {
+				t.Errorf("TestSeed(test %v): star %d differs between two universes of seed %d", test.id, i, test.seed)
+				break
+			}
+			same = same && other.Stars[i].Position == want.Stars[i].Position
+		}
+		if same {
+			t.Errorf("TestSeed(test %v): seeds %d and %d give the same universe", test.id, test.seed, test.other)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: A self-gravitating exponential disk initialized at a chosen Toomre Q (command "disk").
+
+package scenarios
+
+import (
+	"BarnesHut/nbody"
+	"math"
+)
+
+// ToomreQ is the Toomre stability parameter of the "disk" scenario, set from the -toomre-q option.
+// Disks with Q below about 1 fragment into clumps, disks with Q around 1-2 grow rings and spirals,
+// and disks with a larger Q stay smooth.
+var ToomreQ = 1.5
+
+
+// InitializeDisk returns an exponential disk of stars around a central black hole, whose velocity
+// dispersion is chosen so that the disk has the Toomre parameter q at every radius.
+// The disk is as heavy as the black hole, so its own gravity matters. Its scale length is a quarter of r,
+// and stars are placed between r/10 and r.
+// At radius R the disk has surface density Sigma, the total enclosed mass M, and the epicyclic frequency
+// kappa^2 = G M / R^3 + 2 pi G Sigma / R, and the radial velocity dispersion is set to
+// sigma_R = q * 3.36 G Sigma / kappa. The tangential dispersion and the mean rotation speed follow from
+// the epicyclic approximation and the asymmetric drift.
+// Input:
+//   - numOfStars: number of disk stars.
+//   - r: outer radius of the disk.
+//   - x, y: center of the disk.
+//   - q: Toomre parameter.
+// Output:
+//   - the disk as a Galaxy, with the black hole as its last star.
+func InitializeDisk(numOfStars int, r, x, y, q float64) nbody.Galaxy {
+	diskMass := nbody.BlackHoleMass
+	scaleLength := r / 4.0
+	rMin := r / 10.0
+
+	// fraction of an infinite exponential disk that lies inside radius R
+	inside := func(R float64) float64 {
+		return 1.0 - (1.0+R/scaleLength)*math.Exp(-R/scaleLength)
+	}
+	// the masses are rescaled so that the truncated disk still has diskMass
+	norm := diskMass / (inside(r) - inside(rMin))
+
+	g := make(nbody.Galaxy, numOfStars)
+
+	for i := range g {
+		// the radius of an exponential disk follows a Gamma(2) distribution; retry outside [rMin, r]
+		R := 0.0
+		for R < rMin || R > r {
+			R = -scaleLength * math.Log((1.0-nbody.RNG.Float64())*(1.0-nbody.RNG.Float64()))
+		}
+		angle := nbody.RNG.Float64() * 2 * math.Pi
+
+		sigma := norm / (2 * math.Pi * scaleLength * scaleLength) * math.Exp(-R/scaleLength)
+		enclosed := nbody.BlackHoleMass + norm*(inside(R)-inside(rMin))
+
+		omega2 := nbody.G * enclosed / (R * R * R)
+		kappa := math.Sqrt(omega2 + 2*math.Pi*nbody.G*sigma/R)
+		circular2 := omega2 * R * R
+
+		sigmaR := q * 3.36 * nbody.G * sigma / kappa
+		sigmaPhi := sigmaR * kappa / (2 * math.Sqrt(omega2))
+		rotation2 := circular2 + sigmaR*sigmaR*(1-kappa*kappa/(4*omega2)-2*R/scaleLength)
+		rotation := math.Sqrt(math.Max(rotation2, 0))
+
+		vR := sigmaR * nbody.RNG.NormFloat64()
+		vPhi := rotation + sigmaPhi*nbody.RNG.NormFloat64()
+
+		cos, sin := math.Cos(angle), math.Sin(angle)
+		position := nbody.OrderedPair{X: x + R*cos, Y: y + R*sin}
+		velocity := nbody.OrderedPair{X: vR*cos - vPhi*sin, Y: vR*sin + vPhi*cos}
+		g[i] = nbody.NewStar(position, velocity, diskMass/float64(numOfStars), 696340000)
+	}
+
+	blackhole := nbody.NewStar(nbody.OrderedPair{X: x, Y: y}, nbody.OrderedPair{}, nbody.BlackHoleMass, 6963400000)
+	blackhole.Red, blackhole.Green = 0, 0
+
+	return append(g, blackhole)
+}
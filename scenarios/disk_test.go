@@ -0,0 +1,87 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the disk scenario in disk.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package scenarios
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type InitializeDiskTestCases struct {
+	id        string
+	numStars  int
+	q         float64
+	tolerance float64
+}
+
+
+// ReadInitializeDisk reads test data for the InitializeDisk function from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of InitializeDiskTestCases structs containing the number of stars, the Toomre Q, and the tolerance.
+func ReadInitializeDisk(fileName string) []InitializeDiskTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []InitializeDiskTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		numStars, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		q, err := strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+		nbody.Check(err)
+		tolerance, err := strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
+		nbody.Check(err)
+
+		tests = append(tests, InitializeDiskTestCases{
+			id:        strings.TrimSpace(parts[0]),
+			numStars:  numStars,
+			q:         q,
+			tolerance: tolerance,
+		})
+	}
+
+	return tests
+}
+
+
+// TestInitializeDisk tests that InitializeDisk sets up a disk at the requested Toomre Q,
+// by comparing the median Q measured by MeasureToomreQ in the rings outside the inner one.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestInitializeDisk(t *testing.T) {
+	tests := ReadInitializeDisk("../Tests/InitializeDisk.txt")
+
+	for _, test := range tests {
+		g := InitializeDisk(test.numStars, 2e22, 5e22, 5e22, test.q)
+		u := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+
+		q := nbody.MeasureToomreQ(u, nbody.OrderedPair{X: 5e22, Y: 5e22}, 2e22, 8)[1:]
+		sort.Float64s(q)
+		median := q[len(q)/2]
+
+		if math.Abs(median-test.q) > test.tolerance*test.q {
+			t.Errorf("TestInitializeDisk(test %v) = median Q %v, want %v", test.id, median, test.q)
+		}
+	}
+}
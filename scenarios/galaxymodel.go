@@ -0,0 +1,280 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Galaxies composed of an exponential disk, a bulge, and a dark matter halo (configuration kind "composite").
+
+package scenarios
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/simulation"
+	"fmt"
+	"math"
+)
+
+// A composite galaxy has a central black hole, an exponential disk, and two spheroids with Hernquist profiles,
+// M(<r) = M r^2 / (r + a)^2: a small bulge and a large dark matter halo. The halo is made of stars like the other
+// components or, when it has no stars, is an analytic Halo whose force is added to that of the stars (see
+// Options.potentials), which costs nothing per star. Every component is placed in the plane of the universe at the
+// radii of its enclosed mass, and all velocities come from the rotation curve of the components together, so the
+// disk starts in balance with the mass that holds it instead of unwinding.
+
+// hernquistFraction returns the fraction of the mass of a Hernquist profile of scale a that lies inside radius r.
+func hernquistFraction(r, a float64) float64 {
+	return r * r / ((r + a) * (r + a))
+}
+
+
+// hernquistRadius returns the radius inside which the fraction f of the mass of a Hernquist profile of scale a lies.
+func hernquistRadius(f, a float64) float64 {
+	s := math.Sqrt(f)
+	return a * s / (1 - s)
+}
+
+
+// GalaxyModel describes a composite galaxy, with masses in kg and lengths in m.
+type GalaxyModel struct {
+	diskStars  int
+	diskMass   float64
+	diskRadius float64 // outer radius of the disk; its scale length is a quarter of it, as for InitializeDisk
+	toomreQ    float64 // Toomre parameter of the disk
+
+	bulgeStars int
+	bulgeMass  float64 // total mass of the bulge profile; its stars carry the part inside diskRadius
+	bulgeScale float64
+
+	haloStars  int     // 0 makes the halo analytic
+	haloMass   float64 // total mass of the halo profile; its stars carry the part inside haloRadius
+	haloScale  float64
+	haloRadius float64 // the stars of a halo are placed inside this radius
+
+	blackHoleMass float64
+}
+
+
+// DefaultGalaxyModel returns the GalaxyModel of a galaxy of radius r with numStars disk stars, at the Toomre
+// parameter q: a disk as heavy as the black hole of the other galaxies, a bulge of a quarter of its mass with
+// numStars / 4 stars and the scale r / 20, and an analytic halo of 5 times its mass with the scale r.
+func DefaultGalaxyModel(numStars int, r, q float64) GalaxyModel {
+	return GalaxyModel{
+		diskStars:  numStars,
+		diskMass:   nbody.BlackHoleMass,
+		diskRadius: r,
+		toomreQ:    q,
+
+		bulgeStars: numStars / 4,
+		bulgeMass:  nbody.BlackHoleMass / 4,
+		bulgeScale: r / 20,
+
+		haloMass:   5 * nbody.BlackHoleMass,
+		haloScale:  r,
+		haloRadius: 2 * r,
+
+		blackHoleMass: nbody.BlackHoleMass,
+	}
+}
+
+
+// Validate returns an error describing the first invalid field of the GalaxyModel, or nil.
+func (m GalaxyModel) Validate() error {
+	switch {
+	case m.diskStars < 1 || m.diskMass <= 0 || m.diskRadius <= 0:
+		return fmt.Errorf("the disk needs at least one star, a positive mass, and a positive radius")
+	case m.toomreQ <= 0:
+		return fmt.Errorf("the Toomre parameter of the disk must be positive, got %v", m.toomreQ)
+	case m.bulgeStars < 0 || m.bulgeMass < 0 || m.haloStars < 0 || m.haloMass < 0 || m.blackHoleMass < 0:
+		return fmt.Errorf("numbers of stars and masses must not be negative")
+	case m.bulgeMass > 0 && (m.bulgeStars == 0 || m.bulgeScale <= 0):
+		return fmt.Errorf("a bulge needs stars and a positive scale")
+	case m.bulgeMass == 0 && m.bulgeStars > 0:
+		return fmt.Errorf("the stars of the bulge need a bulge mass")
+	case m.haloMass > 0 && m.haloScale <= 0:
+		return fmt.Errorf("a halo needs a positive scale")
+	case m.haloStars > 0 && (m.haloMass == 0 || m.haloRadius <= 0):
+		return fmt.Errorf("the stars of the halo need a halo mass and a positive halo radius")
+	}
+	return nil
+}
+
+
+// spheroid is a Hernquist component of a GalaxyModel whose stars lie inside cutoff.
+type spheroid struct {
+	stars  int
+	mass   float64
+	scale  float64
+	cutoff float64
+}
+
+
+// spheroids returns the bulge and the halo of the model; an analytic halo has no stars and no cutoff.
+func (m GalaxyModel) spheroids() (spheroid, spheroid) {
+	halo := spheroid{stars: m.haloStars, mass: m.haloMass, scale: m.haloScale, cutoff: math.Inf(1)}
+	if m.haloStars > 0 {
+		halo.cutoff = m.haloRadius
+	}
+	return spheroid{stars: m.bulgeStars, mass: m.bulgeMass, scale: m.bulgeScale, cutoff: m.diskRadius}, halo
+}
+
+
+// enclosed returns the mass of the spheroid inside radius r and its derivative dM/dr.
+func (s spheroid) enclosed(r float64) (float64, float64) {
+	if s.mass == 0 {
+		return 0, 0
+	}
+	if r >= s.cutoff {
+		return s.mass * hernquistFraction(s.cutoff, s.scale), 0
+	}
+	return s.mass * hernquistFraction(r, s.scale), s.mass * 2 * r * s.scale / math.Pow(r+s.scale, 3)
+}
+
+
+// EnclosedMass returns the mass of all components inside radius R and its derivative dM/dR.
+func (m GalaxyModel) EnclosedMass(R float64) (float64, float64) {
+	h := m.diskRadius / 4
+	// fraction of an infinite exponential disk inside R, rescaled so that the disk ends at diskRadius
+	inside := func(R float64) float64 {
+		return 1.0 - (1.0+R/h)*math.Exp(-R/h)
+	}
+	norm := m.diskMass / inside(m.diskRadius)
+
+	mass, derivative := m.blackHoleMass+m.diskMass, 0.0
+	if R < m.diskRadius {
+		mass = m.blackHoleMass + norm*inside(R)
+		derivative = norm * R / (h * h) * math.Exp(-R/h)
+	}
+	bulge, halo := m.spheroids()
+	for _, s := range []spheroid{bulge, halo} {
+		sm, sd := s.enclosed(R)
+		mass += sm
+		derivative += sd
+	}
+	return mass, derivative
+}
+
+
+// CircularSpeed returns the speed sqrt(G M(<R) / R) of a circular orbit of radius R in the model.
+func (m GalaxyModel) CircularSpeed(R float64) float64 {
+	mass, _ := m.EnclosedMass(R)
+	return math.Sqrt(nbody.G * mass / R)
+}
+
+
+// jeansDispersion returns the velocity dispersion, in every direction, of the stars of a non-rotating spheroid at
+// radius R in the plane: the isotropic Jeans equation d(n sigma^2) / dR = -n v_c^2 / R, with the surface density
+// n ~ 1 / (R + a)^3 of a Hernquist profile placed in the plane, integrated from the cutoff inward by Simpson's rule
+// in log R.
+func (m GalaxyModel) jeansDispersion(s spheroid, R float64) float64 {
+	if R >= s.cutoff {
+		return 0
+	}
+	integrand := func(r float64) float64 {
+		v := m.CircularSpeed(r)
+		return v * v / math.Pow(r+s.scale, 3)
+	}
+
+	const steps = 100 // even
+	h := math.Log(s.cutoff/R) / steps
+	sum := 0.0
+	for i := 0; i <= steps; i++ {
+		weight := 2.0
+		switch {
+		case i == 0 || i == steps:
+			weight = 1
+		case i%2 == 1:
+			weight = 4
+		}
+		// dr / r = d(log r), so the factor 1 / r of the integrand cancels
+		sum += weight * integrand(R*math.Exp(float64(i)*h))
+	}
+	return math.Sqrt(math.Pow(R+s.scale, 3) * sum * h / 3)
+}
+
+
+// InitializeGalaxyModel returns the stars of a composite galaxy centered at (x, y) and its analytic halo, if any.
+// The disk stars have the mean rotation and the radial dispersion that give the Toomre parameter of the model at
+// every radius (as for InitializeDisk, with kappa^2 = G dM/dR / R^2 + G M / R^3 from the combined rotation curve),
+// the bulge and the halo stars have no mean rotation and the dispersion of the Jeans equation, and the galaxy is
+// at rest as a whole. The black hole, if the model has one, is the last star.
+// Input:
+//   - m: the GalaxyModel (see Validate).
+//   - x, y: center of the galaxy.
+// Output:
+//   - the stars as a Galaxy, and the analytic halo (none if the halo has stars or no mass).
+func InitializeGalaxyModel(m GalaxyModel, x, y float64) (nbody.Galaxy, []simulation.Halo) {
+	center := nbody.OrderedPair{X: x, Y: y}
+	scaleLength := m.diskRadius / 4
+	g := make(nbody.Galaxy, 0, m.diskStars+m.bulgeStars+m.haloStars+1)
+
+	place := func(R, vR, vPhi, mass, radius float64) *nbody.Star {
+		angle := nbody.RNG.Float64() * 2 * math.Pi
+		cos, sin := math.Cos(angle), math.Sin(angle)
+		position := nbody.OrderedPair{X: x + R*cos, Y: y + R*sin}
+		velocity := nbody.OrderedPair{X: vR*cos - vPhi*sin, Y: vR*sin + vPhi*cos}
+		return nbody.NewStar(position, velocity, mass, radius)
+	}
+
+	norm := m.diskMass / (1 - (1+m.diskRadius/scaleLength)*math.Exp(-m.diskRadius/scaleLength))
+	for i := 0; i < m.diskStars; i++ {
+		// the radius of an exponential disk follows a Gamma(2) distribution; retry outside the disk
+		R := 0.0
+		for R == 0 || R > m.diskRadius {
+			R = -scaleLength * math.Log((1.0-nbody.RNG.Float64())*(1.0-nbody.RNG.Float64()))
+		}
+
+		sigma := norm / (2 * math.Pi * scaleLength * scaleLength) * math.Exp(-R/scaleLength)
+		mass, derivative := m.EnclosedMass(R)
+		omega2 := nbody.G * mass / (R * R * R)
+		kappa := math.Sqrt(nbody.G*derivative/(R*R) + omega2)
+
+		sigmaR := m.toomreQ * 3.36 * nbody.G * sigma / kappa
+		sigmaPhi := sigmaR * kappa / (2 * math.Sqrt(omega2))
+		rotation2 := omega2*R*R + sigmaR*sigmaR*(1-kappa*kappa/(4*omega2)-2*R/scaleLength)
+		rotation := math.Sqrt(math.Max(rotation2, 0))
+
+		g = append(g, place(R, sigmaR*nbody.RNG.NormFloat64(), rotation+sigmaPhi*nbody.RNG.NormFloat64(),
+			m.diskMass/float64(m.diskStars), 696340000))
+	}
+
+	bulge, halo := m.spheroids()
+	for k, s := range []spheroid{bulge, halo} {
+		if s.stars == 0 {
+			continue
+		}
+		inside := hernquistFraction(s.cutoff, s.scale)
+		for i := 0; i < s.stars; i++ {
+			R := hernquistRadius(inside*nbody.RNG.Float64(), s.scale)
+			dispersion := m.jeansDispersion(s, R)
+			star := place(R, dispersion*nbody.RNG.NormFloat64(), dispersion*nbody.RNG.NormFloat64(),
+				s.mass*inside/float64(s.stars), 696340000)
+			if k == 0 {
+				star.Blue = 170 // bulge stars are yellowish
+			} else {
+				star.Red, star.Green, star.Blue = 90, 90, 140 // dark matter is drawn dim
+			}
+			g = append(g, star)
+		}
+	}
+
+	// the galaxy as a whole is at rest
+	var momentum nbody.OrderedPair
+	total := m.blackHoleMass
+	for _, s := range g {
+		momentum.X += s.Mass * s.Velocity.X
+		momentum.Y += s.Mass * s.Velocity.Y
+		total += s.Mass
+	}
+	for _, s := range g {
+		s.Velocity.X -= momentum.X / total
+		s.Velocity.Y -= momentum.Y / total
+	}
+	if m.blackHoleMass > 0 {
+		blackhole := nbody.NewStar(center, nbody.OrderedPair{X: -momentum.X / total, Y: -momentum.Y / total}, m.blackHoleMass, 6963400000)
+		blackhole.Red, blackhole.Green = 0, 0
+		g = append(g, blackhole)
+	}
+
+	var halos []simulation.Halo
+	if m.haloStars == 0 && m.haloMass > 0 {
+		halos = append(halos, simulation.Halo{Center: center, Mass: m.haloMass, Scale: m.haloScale})
+	}
+	return g, halos
+}
@@ -0,0 +1,141 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for composite galaxies in galaxymodel.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package scenarios
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/simulation"
+	"bufio"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type GalaxyModelTestCases struct {
+	id          string
+	diskStars   int
+	haloStars   int
+	numGens     int
+	maxChange   float64 // largest relative change of the half-mass radius of the disk
+	minRotation float64 // smallest fraction of the initial mean rotation speed of the disk
+}
+
+
+// ReadGalaxyModel reads test data for the InitializeGalaxyModel function from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of GalaxyModelTestCases structs containing the galaxies, the runs, and the expected stability.
+func ReadGalaxyModel(fileName string) []GalaxyModelTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []GalaxyModelTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 6 {
+			continue
+		}
+
+		var test GalaxyModelTestCases
+		test.id = strings.TrimSpace(parts[0])
+		test.diskStars, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		test.haloStars, err = strconv.Atoi(strings.TrimSpace(parts[2]))
+		nbody.Check(err)
+		test.numGens, err = strconv.Atoi(strings.TrimSpace(parts[3]))
+		nbody.Check(err)
+		test.maxChange, err = strconv.ParseFloat(strings.TrimSpace(parts[4]), 64)
+		nbody.Check(err)
+		test.minRotation, err = strconv.ParseFloat(strings.TrimSpace(parts[5]), 64)
+		nbody.Check(err)
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// diskShape returns the half-mass radius and the mean rotation speed of the first n stars of a universe,
+// measured around their center of mass.
+func diskShape(u *nbody.Universe, n int) (float64, float64) {
+	center := nbody.CenterOfMass(u.Stars[:n])
+	radii := make([]float64, n)
+	rotation := 0.0
+	for i, s := range u.Stars[:n] {
+		dx, dy, r := nbody.Distance(s.Position, center)
+		radii[i] = r
+		rotation += (dx*s.Velocity.Y - dy*s.Velocity.X) / r / float64(n)
+	}
+	sort.Float64s(radii)
+	return radii[n/2], rotation
+}
+
+
+// TestGalaxyModel tests that InitializeGalaxyModel makes the stars and the analytic halo of the model, with the mass
+// of the model and at rest, and that the disk keeps its size and most of its rotation during a run.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestGalaxyModel(t *testing.T) {
+	tests := ReadGalaxyModel("../Tests/GalaxyModel.txt")
+
+	for _, test := range tests {
+		nbody.SetDeterministic()
+		m := DefaultGalaxyModel(test.diskStars, 2e22, 1.5)
+		m.haloStars = test.haloStars
+		g, halos := InitializeGalaxyModel(m, 5e22, 5e22)
+
+		if n := test.diskStars + m.bulgeStars + test.haloStars + 1; len(g) != n {
+			t.Fatalf("TestGalaxyModel(test %v) has %d stars, want %d", test.id, len(g), n)
+		}
+		if (len(halos) == 1) != (test.haloStars == 0) {
+			t.Errorf("TestGalaxyModel(test %v) has %d analytic halos with %d halo stars", test.id, len(halos), test.haloStars)
+		}
+
+		// the stars carry the mass of the model inside the disk and, for a live halo, inside the halo radius
+		want := m.blackHoleMass + m.diskMass + m.bulgeMass*hernquistFraction(m.diskRadius, m.bulgeScale)
+		if test.haloStars > 0 {
+			want += m.haloMass * hernquistFraction(m.haloRadius, m.haloScale)
+		}
+		total := 0.0
+		var momentum nbody.OrderedPair
+		for _, s := range g {
+			total += s.Mass
+			momentum.X += s.Mass * s.Velocity.X
+			momentum.Y += s.Mass * s.Velocity.Y
+		}
+		if math.Abs(total-want) > 1e-9*want {
+			t.Errorf("TestGalaxyModel(test %v) mass = %v, want %v", test.id, total, want)
+		}
+		if math.Hypot(momentum.X, momentum.Y) > 1e-9*total*m.CircularSpeed(m.diskRadius) {
+			t.Errorf("TestGalaxyModel(test %v) moves as a whole, momentum %v", test.id, momentum)
+		}
+
+		u := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+		options := simulation.DefaultOptions(test.numGens, 1e17, 0.5).WithHalos(halos...)
+		options.Softening = 4e20
+		r0, v0 := diskShape(u, test.diskStars)
+		generations := simulation.BarnesHut(u, options)
+		r, v := diskShape(generations[test.numGens], test.diskStars)
+
+		if math.Abs(r/r0-1) > test.maxChange {
+			t.Errorf("TestGalaxyModel(test %v) half-mass radius of the disk changed from %v to %v", test.id, r0, r)
+		}
+		if v < test.minRotation*v0 {
+			t.Errorf("TestGalaxyModel(test %v) rotation of the disk fell from %v to %v", test.id, v0, v)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Author: Yu-Lun Chen
+// Date: 2025-10-24
+// Description: The moons of Jupiter (command "jupiter"), read from a file of their positions and velocities.
+
+package scenarios
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/simulation"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//// Load data from jupiterMoons.txt ////
+
+// A Jupiter moons file starts with the width of the universe and the gravitational constant (which is ignored,
+// the simulation uses G), each on its own line, followed by the bodies. A body is a line ">name" followed by
+// five lines: its color "r, g, b", its mass, its radius, its position "x, y", and its velocity "vx, vy".
+// Lines starting with "#" and empty lines are ignored, and a unit declaration line (see ParseUnitsLine), e.g.
+// "units: position=km velocity=km/s mass=Mjup radius=km", converts the values to SI units while loading.
+// All values must be finite numbers. A body outside the universe is loaded with a warning: it is left out of
+// the quadtree, so it neither pulls nor is pulled until it enters the universe.
+
+// jupiterBodyFields are the lines of a body in a Jupiter moons file, in order, with the number of values of each.
+var jupiterBodyFields = []struct {
+	name      string
+	numValues int
+}{{"color", 3}, {"mass", 1}, {"radius", 1}, {"position", 2}, {"velocity", 2}}
+
+
+// ReadJupiterMoons reads a universe in the Jupiter moons format described above.
+// Input:
+//   - r: reader of the file.
+// Output:
+//   - the Universe in SI units and a warning naming the line of every body outside the universe, or an error
+//     naming the offending line and the expected format.
+func ReadJupiterMoons(r io.Reader) (*nbody.Universe, []string, error) {
+	scanner := bufio.NewScanner(r)
+	units := nbody.SIUnits()
+
+	var u *nbody.Universe
+	var warnings []string
+	headerLines := 0   // lines of the header read so far (the width, then G)
+	var currStar *nbody.Star // the body being read
+	var name string    // its name
+	field := 0         // its next line, an index into jupiterBodyFields
+	bodyLine := 0      // the line of its name
+
+	// the last body must have all its lines
+	finishBody := func() error {
+		if currStar != nil && field < len(jupiterBodyFields) {
+			return fmt.Errorf("line %d: body %q is missing its %s (expected %d lines after the name: color, mass, radius, position, velocity)",
+				bodyLine, name, jupiterBodyFields[field].name, len(jupiterBodyFields))
+		}
+		if currStar != nil {
+			u.AddStars(currStar)
+		}
+		return nil
+	}
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		// comments and the unit declaration are not part of the data
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if nbody.IsUnitsLine(line) {
+			if headerLines > 0 {
+				return nil, nil, fmt.Errorf("line %d: the unit declaration must come before the width", lineNumber)
+			}
+			var err error
+			units, err = nbody.ParseUnitsLine(line)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			continue
+		}
+
+		// the width of the universe and the gravitational constant
+		if headerLines < 2 {
+			value, err := strconv.ParseFloat(line, 64)
+			if err != nil || !nbody.IsFinite(value) || (headerLines == 0 && value <= 0) {
+				return nil, nil, fmt.Errorf("line %d: expected the %s as a finite %snumber, got %q",
+					lineNumber, []string{"width of the universe", "gravitational constant"}[headerLines],
+					[]string{"positive ", ""}[headerLines], line)
+			}
+			if headerLines == 0 {
+				u = nbody.NewUniverse(value * units.Position)
+			}
+			headerLines++
+			continue
+		}
+
+		// the name of a body starts it
+		if strings.HasPrefix(line, ">") {
+			if err := finishBody(); err != nil {
+				return nil, nil, err
+			}
+			currStar, name, field, bodyLine = &nbody.Star{}, strings.TrimSpace(line[1:]), 0, lineNumber
+			continue
+		}
+		if currStar == nil {
+			return nil, nil, fmt.Errorf("line %d: expected a body name \">name\", got %q", lineNumber, line)
+		}
+		if field == len(jupiterBodyFields) {
+			return nil, nil, fmt.Errorf("line %d: body %q has more than %d lines, got %q", lineNumber, name, len(jupiterBodyFields), line)
+		}
+
+		// the values of the next line of the body
+		expected := jupiterBodyFields[field]
+		parts := strings.Split(line, ",")
+		if len(parts) != expected.numValues {
+			return nil, nil, fmt.Errorf("line %d: expected the %s of body %q as %d comma-separated numbers, got %q",
+				lineNumber, expected.name, name, expected.numValues, line)
+		}
+		values := make([]float64, len(parts))
+		for i, part := range parts {
+			v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil || !nbody.IsFinite(v) {
+				return nil, nil, fmt.Errorf("line %d: the %s of body %q must be finite numbers, got %q", lineNumber, expected.name, name, line)
+			}
+			values[i] = v
+		}
+
+		switch expected.name {
+		case "color":
+			for _, v := range values {
+				if v < 0 || v > 255 || v != math.Trunc(v) {
+					return nil, nil, fmt.Errorf("line %d: the color of body %q must be integers from 0 to 255, got %q", lineNumber, name, line)
+				}
+			}
+			currStar.Red, currStar.Green, currStar.Blue = uint8(values[0]), uint8(values[1]), uint8(values[2])
+		case "mass":
+			if values[0] <= 0 {
+				return nil, nil, fmt.Errorf("line %d: the mass of body %q must be positive, got %q", lineNumber, name, line)
+			}
+			currStar.Mass = values[0] * units.Mass
+		case "radius":
+			if values[0] < 0 {
+				return nil, nil, fmt.Errorf("line %d: the radius of body %q must not be negative, got %q", lineNumber, name, line)
+			}
+			currStar.Radius = values[0] * units.Radius
+		case "position":
+			currStar.Position = nbody.OrderedPair{X: values[0] * units.Position, Y: values[1] * units.Position}
+			if width, height := u.Bounds(); !nbody.IsInsideUniverse(currStar, width, height) {
+				warnings = append(warnings, fmt.Sprintf("line %d: body %q at %v is outside the universe of width %v",
+					lineNumber, name, currStar.Position, width))
+			}
+		case "velocity":
+			currStar.Velocity = nbody.OrderedPair{X: values[0] * units.Velocity, Y: values[1] * units.Velocity}
+		}
+		field++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if headerLines < 2 {
+		return nil, nil, errors.New("expected the width of the universe and the gravitational constant before the bodies")
+	}
+	if err := finishBody(); err != nil {
+		return nil, nil, err
+	}
+	if len(u.Stars) == 0 {
+		return nil, nil, errors.New("no bodies")
+	}
+	return u, warnings, nil
+}
+
+
+// LoadJupiterMoons loads a Jupiter moons file (see ReadJupiterMoons) and constructs a Universe.
+// Its warnings are written to simulation.WarningOutput.
+// Input:
+//   - fileName: string path to the data file.
+// Output:
+//   - Pointer to the constructed Universe, or an error naming the file and the offending line.
+func LoadJupiterMoons(fileName string) (*nbody.Universe, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	u, warnings, err := ReadJupiterMoons(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(simulation.WarningOutput, "Warning: %s: %s\n", fileName, warning)
+	}
+	return u, nil
+}
@@ -0,0 +1,158 @@
+// Author: Yu-Lun Chen
+// Date: 2025-10-24
+// Description: Testing functions for reading the moons of Jupiter in jupiter.go.
+// Each txt file contains input testing cases and the expected output for each cases.
+
+package scenarios
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestLoadJupiterMoonsUnits tests that LoadJupiterMoons converts a file with a unit declaration into SI units.
+// Tests/LoadJupiterMoonsUnits.txt holds the first two bodies of Data/jupiterMoons.txt in km, km/s, and Mjup.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestLoadJupiterMoonsUnits(t *testing.T) {
+	result, err := LoadJupiterMoons("../Tests/LoadJupiterMoonsUnits.txt")
+	nbody.Check(err)
+	expected, err := LoadJupiterMoons("../Data/jupiterMoons.txt")
+	nbody.Check(err)
+
+	relativeError := func(a, b float64) float64 {
+		if b == 0 {
+			return math.Abs(a)
+		}
+		return math.Abs((a - b) / b)
+	}
+
+	if relativeError(result.Width, expected.Width) > 1e-6 {
+		t.Errorf("TestLoadJupiterMoonsUnits(width) = %v, want %v", result.Width, expected.Width)
+	}
+
+	if len(result.Stars) != 2 {
+		t.Fatalf("TestLoadJupiterMoonsUnits loaded %v stars, want 2", len(result.Stars))
+	}
+
+	for i, s := range result.Stars {
+		e := expected.Stars[i]
+		values := []float64{s.Position.X, s.Position.Y, s.Velocity.X, s.Velocity.Y, s.Mass, s.Radius}
+		expectedValues := []float64{e.Position.X, e.Position.Y, e.Velocity.X, e.Velocity.Y, e.Mass, e.Radius}
+
+		for j := range values {
+			if relativeError(values[j], expectedValues[j]) > 1e-6 {
+				t.Errorf("TestLoadJupiterMoonsUnits(star %v, value %v) = %v, want %v",
+					i, j, values[j], expectedValues[j])
+			}
+		}
+	}
+}
+
+
+type ReadJupiterMoonsTestCases struct {
+	id        string
+	file      string
+	numBodies int
+	errorLine int   // line the error must name, 0 for an error without a line, -1 if the file is valid
+	warnings  []int // lines the warnings must name
+}
+
+
+// ReadReadJupiterMoons reads test data for ReadJupiterMoons from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of ReadJupiterMoonsTestCases structs containing the file and the expected bodies, error, and warnings.
+func ReadReadJupiterMoons(fileName string) []ReadJupiterMoonsTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []ReadJupiterMoonsTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		test := ReadJupiterMoonsTestCases{id: strings.TrimSpace(parts[0]), errorLine: -1}
+		var lines []string
+		for _, l := range strings.Split(parts[1], ";") {
+			lines = append(lines, strings.TrimSpace(l))
+		}
+		test.file = strings.Join(lines, "\n")
+
+		fields := strings.Fields(parts[2])
+		if fields[0] == "error" {
+			test.errorLine = 0
+			if len(fields) > 1 {
+				test.errorLine, err = strconv.Atoi(fields[1])
+				nbody.Check(err)
+			}
+		} else {
+			test.numBodies, err = strconv.Atoi(fields[0])
+			nbody.Check(err)
+		}
+		for _, field := range strings.Fields(parts[3]) {
+			if field == "-" {
+				continue
+			}
+			n, err := strconv.Atoi(field)
+			nbody.Check(err)
+			test.warnings = append(test.warnings, n)
+		}
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestReadJupiterMoons tests that ReadJupiterMoons reads valid files and rejects invalid ones with the line at fault,
+// and that it warns about the bodies outside the universe.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestReadJupiterMoons(t *testing.T) {
+	tests := ReadReadJupiterMoons("../Tests/ReadJupiterMoons.txt")
+
+	for _, test := range tests {
+		u, warnings, err := ReadJupiterMoons(strings.NewReader(test.file))
+
+		if test.errorLine >= 0 {
+			if err == nil {
+				t.Errorf("TestReadJupiterMoons(test %v) = no error, want an error", test.id)
+			} else if test.errorLine > 0 && !strings.HasPrefix(err.Error(), "line "+strconv.Itoa(test.errorLine)+":") {
+				t.Errorf("TestReadJupiterMoons(test %v) = error %q, want an error on line %d", test.id, err, test.errorLine)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestReadJupiterMoons(test %v) = error %v, want none", test.id, err)
+			continue
+		}
+		if len(u.Stars) != test.numBodies {
+			t.Errorf("TestReadJupiterMoons(test %v) = %d bodies, want %d", test.id, len(u.Stars), test.numBodies)
+		}
+		if len(warnings) != len(test.warnings) {
+			t.Errorf("TestReadJupiterMoons(test %v) warnings = %q, want warnings on lines %v", test.id, warnings, test.warnings)
+			continue
+		}
+		for i, warning := range warnings {
+			if !strings.HasPrefix(warning, "line "+strconv.Itoa(test.warnings[i])+":") {
+				t.Errorf("TestReadJupiterMoons(test %v) warning = %q, want a warning on line %d", test.id, warning, test.warnings[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,235 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Two bodies on a known elliptical orbit (command "kepler", option "-kepler-e"), with the simulated
+// positions and energy compared to the analytic solution as a benchmark of the accuracy of a run.
+
+package scenarios
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/simulation"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Two bodies of masses m1 and m2 move on ellipses around their center of mass, and their separation r = x2 - x1
+// moves on an ellipse of semi-major axis a and eccentricity e around the origin, with the mean motion
+// n = sqrt(G (m1 + m2) / a^3) and the energy -G m1 m2 / (2 a). At the time t after the pericenter, the eccentric
+// anomaly E solves Kepler's equation E - e sin E = n t, and r = a (cos E - e, sqrt(1 - e^2) sin E).
+// Every deviation of a simulation from this orbit is an error of the integration: the step, the integrator, the
+// softening, or an approximated force.
+
+// KeplerE is the eccentricity of the orbit of the "kepler" scenario, set from the -kepler-e option.
+var KeplerE = 0.5
+
+// keplerStepsPerOrbit and keplerOrbits are the steps per orbit and the number of orbits of the "kepler" scenario.
+const (
+	keplerStepsPerOrbit = 1000
+	keplerOrbits        = 10
+)
+
+
+// CheckKeplerEccentricity returns an error if e is not the eccentricity of an elliptical orbit.
+func CheckKeplerEccentricity(e float64) error {
+	if e < 0 || e >= 1 {
+		return fmt.Errorf("eccentricity of the kepler orbit must be in [0, 1), got %v", e)
+	}
+	return nil
+}
+
+
+// A KeplerOrbit is the relative orbit of two bodies, starting at the pericenter.
+type KeplerOrbit struct {
+	m1 float64 // mass of the first body in kg
+	m2 float64 // mass of the second body in kg
+	a  float64 // semi-major axis of the relative orbit in m
+	e  float64 // eccentricity of the orbit
+}
+
+
+// DefaultKeplerOrbit returns the orbit of the "kepler" scenario: a star of one solar mass and a companion of half a
+// solar mass, 1 AU apart on average, which go around each other in about 298 days.
+func DefaultKeplerOrbit(e float64) KeplerOrbit {
+	return KeplerOrbit{m1: nbody.SolarMass, m2: 0.5 * nbody.SolarMass, a: nbody.LengthUnits["AU"], e: e}
+}
+
+
+// MeanMotion returns the mean angular speed 2 pi / period of the orbit in rad/s.
+func (k KeplerOrbit) MeanMotion() float64 {
+	return math.Sqrt(nbody.G * (k.m1 + k.m2) / (k.a * k.a * k.a))
+}
+
+
+// Period returns the orbital period in s.
+func (k KeplerOrbit) Period() float64 {
+	return 2 * math.Pi / k.MeanMotion()
+}
+
+
+// Energy returns the total energy of the two bodies in their center-of-mass frame.
+func (k KeplerOrbit) Energy() float64 {
+	return -nbody.G * k.m1 * k.m2 / (2 * k.a)
+}
+
+
+// SolveKepler returns the eccentric anomaly E with E - e sin E = meanAnomaly, by Newton's method.
+// E grows with the mean anomaly, also over many orbits: a mean anomaly of 2 pi k + M gives 2 pi k + E(M).
+// Input:
+//   - meanAnomaly: n t, in radians.
+//   - e: eccentricity in [0, 1).
+// Output:
+//   - the eccentric anomaly in radians.
+func SolveKepler(meanAnomaly, e float64) float64 {
+	turns := math.Floor(meanAnomaly/(2*math.Pi) + 0.5)
+	m := meanAnomaly - 2*math.Pi*turns // in [-pi, pi)
+
+	// E - e sin E is increasing, and convex on (0, pi): Newton's method from pi converges also for e close to 1
+	E := m
+	if e > 0.8 {
+		E = math.Copysign(math.Pi, m)
+	}
+	for i := 0; i < 50; i++ {
+		step := (E - e*math.Sin(E) - m) / (1 - e*math.Cos(E))
+		E -= step
+		if math.Abs(step) < 1e-15 {
+			break
+		}
+	}
+	return E + 2*math.Pi*turns
+}
+
+
+// Relative returns the analytic separation x2 - x1 of the bodies at the time t after the pericenter, with the
+// pericenter on the x axis and the bodies going around counterclockwise.
+func (k KeplerOrbit) Relative(t float64) nbody.OrderedPair {
+	E := SolveKepler(k.MeanMotion()*t, k.e)
+	return nbody.OrderedPair{X: k.a * (math.Cos(E) - k.e), Y: k.a * math.Sqrt(1-k.e*k.e) * math.Sin(E)}
+}
+
+
+// TrueAnomaly returns the angle of the separation from the pericenter at the time t, counting the full orbits:
+// the true anomaly E + 2 atan(beta sin E / (1 - beta cos E)) with beta = e / (1 + sqrt(1 - e^2)) grows with E.
+func (k KeplerOrbit) TrueAnomaly(t float64) float64 {
+	E := SolveKepler(k.MeanMotion()*t, k.e)
+	beta := k.e / (1 + math.Sqrt(1-k.e*k.e))
+	return E + 2*math.Atan(beta*math.Sin(E)/(1-beta*math.Cos(E)))
+}
+
+
+// Universe returns a universe with the two bodies at the pericenter, and their center of mass at rest in the center.
+// The integrators treat the starting velocity as that of half a step earlier: velocity Verlet adds half a step of
+// the acceleration in its first step (see IntegrateInPlace), and semi-implicit Euler drifts with the velocity half a
+// step ahead of the positions. The bodies therefore start with the velocity of the orbit less half a step of their
+// acceleration.
+// Input:
+//   - width: width of the universe in m (at least 2 (1 + e) a to keep the bodies inside).
+//   - step: the integration step of the run in s.
+// Output:
+//   - pointer to the Universe; star 0 is the first body and star 1 the second.
+func (k KeplerOrbit) Universe(width, step float64) *nbody.Universe {
+	u := nbody.NewUniverse(width)
+	center := nbody.OrderedPair{X: width / 2, Y: width / 2}
+	mass := k.m1 + k.m2
+
+	rp := k.a * (1 - k.e)
+	vp := math.Sqrt(nbody.G * mass / k.a * (1 + k.e) / (1 - k.e))
+	// the relative acceleration at the pericenter points from the second body to the first
+	relativeVelocity := nbody.OrderedPair{X: nbody.G * mass / (rp * rp) * step / 2, Y: vp}
+
+	first := nbody.NewStar(nbody.OrderedPair{X: center.X - k.m2/mass*rp, Y: center.Y},
+		nbody.OrderedPair{X: -k.m2 / mass * relativeVelocity.X, Y: -k.m2 / mass * relativeVelocity.Y}, k.m1, 6e9)
+	first.Red, first.Green, first.Blue = 255, 220, 80
+	second := nbody.NewStar(nbody.OrderedPair{X: center.X + k.m1/mass*rp, Y: center.Y},
+		nbody.OrderedPair{X: k.m1 / mass * relativeVelocity.X, Y: k.m1 / mass * relativeVelocity.Y}, k.m2, 4e9)
+	second.Red, second.Green, second.Blue = 255, 130, 70
+	u.AddStars(first, second)
+
+	return u
+}
+
+
+// KeplerMonitor compares the bodies of a kepler run to the analytic orbit in every generation: the distance of
+// their separation from the analytic one, the phase (how far the simulated bodies went around each other ahead
+// of the analytic ones), and the relative error of their energy.
+type KeplerMonitor struct {
+	orbit       KeplerOrbit
+	started     bool
+	angle       float64 // angle of the simulated separation in the last generation, in radians
+	phase       float64 // angle the simulated separation turned since the pericenter, in radians
+	time        float64 // simulated time of the last generation
+	maxPosition float64 // largest distance from the analytic separation, in units of a
+	phaseError  float64 // simulated minus analytic angle in the last generation
+	maxPhase    float64 // largest absolute phase error
+	energyError float64 // relative energy error in the last generation
+	maxEnergy   float64 // largest absolute relative energy error
+}
+
+
+// NewKeplerMonitor returns a monitor comparing a run to the orbit.
+func NewKeplerMonitor(orbit KeplerOrbit) *KeplerMonitor {
+	return &KeplerMonitor{orbit: orbit}
+}
+
+
+// Hook returns the hook for Options.WithHook that follows the bodies. Star 0 must be the first body and star 1
+// the second, as KeplerOrbit.Universe makes them, and the run must start at the pericenter at time 0.
+// The velocity Verlet integrator stores the velocities one step behind the positions, so the energy is taken at
+// the positions of that step, x - v dt - a dt^2 / 2; with -adaptive-dt or -block-levels it is approximate.
+// Input:
+//   - options: the Options of the run (its integrator and substeps).
+// Output:
+//   - the hook.
+func (m *KeplerMonitor) Hook(options simulation.Options) func(generation int, u *nbody.Universe) {
+	return func(generation int, u *nbody.Universe) {
+		if len(u.Stars) != 2 {
+			return
+		}
+		s1, s2 := u.Stars[0], u.Stars[1]
+		angle := math.Atan2(s2.Position.Y-s1.Position.Y, s2.Position.X-s1.Position.X)
+		if !m.started {
+			m.started = true
+			m.angle, m.phase, m.time = angle, angle, u.Time
+			return
+		}
+
+		// a pair turns by less than half an orbit per generation
+		m.phase += math.Remainder(angle-m.angle, 2*math.Pi)
+		m.angle = angle
+
+		relative := m.orbit.Relative(u.Time)
+		_, _, d := nbody.Distance(nbody.OrderedPair{X: s2.Position.X - s1.Position.X, Y: s2.Position.Y - s1.Position.Y}, relative)
+		m.maxPosition = math.Max(m.maxPosition, d/m.orbit.a)
+		m.phaseError = m.phase - m.orbit.TrueAnomaly(u.Time)
+		m.maxPhase = math.Max(m.maxPhase, math.Abs(m.phaseError))
+
+		p1, p2 := s1.Position, s2.Position
+		if options.Integrator == "verlet" {
+			dt := (u.Time - m.time) / float64(max(options.Substeps, 1))
+			p1 = nbody.OrderedPair{X: p1.X - s1.Velocity.X*dt - s1.Acceleration.X*dt*dt/2, Y: p1.Y - s1.Velocity.Y*dt - s1.Acceleration.Y*dt*dt/2}
+			p2 = nbody.OrderedPair{X: p2.X - s2.Velocity.X*dt - s2.Acceleration.X*dt*dt/2, Y: p2.Y - s2.Velocity.Y*dt - s2.Acceleration.Y*dt*dt/2}
+		}
+		_, _, r := nbody.Distance(p1, p2)
+		kinetic := 0.5*s1.Mass*(s1.Velocity.X*s1.Velocity.X+s1.Velocity.Y*s1.Velocity.Y) +
+			0.5*s2.Mass*(s2.Velocity.X*s2.Velocity.X+s2.Velocity.Y*s2.Velocity.Y)
+		m.energyError = (kinetic - nbody.G*s1.Mass*s2.Mass/r - m.orbit.Energy()) / math.Abs(m.orbit.Energy())
+		m.maxEnergy = math.Max(m.maxEnergy, math.Abs(m.energyError))
+		m.time = u.Time
+	}
+}
+
+
+// WriteReport writes the orbit, the number of orbits simulated, and the largest and the final errors.
+// Input:
+//   - w: destination of the report.
+// Output:
+//   - an error if writing failed.
+func (m *KeplerMonitor) WriteReport(w io.Writer) error {
+	fmt.Fprintf(w, "Kepler orbit: e = %g, a = %g m, period %.2f days, %.2f orbits simulated\n",
+		m.orbit.e, m.orbit.a, m.orbit.Period()/secondsPerDay, m.time/m.orbit.Period())
+	fmt.Fprintf(w, "%-26s %12s %12s\n", "error", "largest", "final")
+	fmt.Fprintf(w, "%-26s %12.3e %12s\n", "position (in units of a)", m.maxPosition, "")
+	fmt.Fprintf(w, "%-26s %12.3e %12.3e\n", "phase (rad)", m.maxPhase, m.phaseError)
+	_, err := fmt.Fprintf(w, "%-26s %12.3e %12.3e\n", "energy (relative)", m.maxEnergy, m.energyError)
+	return err
+}
@@ -0,0 +1,183 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the two-body Kepler orbit in kepler.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package scenarios
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/simulation"
+	"bufio"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type SolveKeplerTestCases struct {
+	id          string
+	meanAnomaly float64
+	e           float64
+	expected    float64 // eccentric anomaly
+}
+
+
+// ReadSolveKepler reads test data for SolveKepler from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of SolveKeplerTestCases structs containing the anomalies.
+func ReadSolveKepler(fileName string) []SolveKeplerTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []SolveKeplerTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		var test SolveKeplerTestCases
+		test.id = strings.TrimSpace(parts[0])
+		test.meanAnomaly, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		nbody.Check(err)
+		test.e, err = strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+		nbody.Check(err)
+		test.expected, err = strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
+		nbody.Check(err)
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestSolveKepler tests that SolveKepler returns the eccentric anomaly of a mean anomaly.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestSolveKepler(t *testing.T) {
+	tests := ReadSolveKepler("../Tests/SolveKepler.txt")
+
+	for _, test := range tests {
+		if E := SolveKepler(test.meanAnomaly, test.e); math.Abs(E-test.expected) > 1e-9 {
+			t.Errorf("SolveKepler(test %v) = %v, want %v", test.id, E, test.expected)
+		}
+	}
+}
+
+
+type KeplerTestCases struct {
+	id            string
+	e             float64
+	stepsPerOrbit int
+	orbits        int
+	integrator    string
+	substeps      int
+	maxPosition   float64 // largest position error in units of a, -1 if the eccentricity is invalid
+	maxPhase      float64 // largest phase error in radians
+	maxEnergy     float64 // largest relative energy error
+}
+
+
+// ReadKepler reads test data for the Kepler orbit and its monitor from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of KeplerTestCases structs containing the runs and the largest errors.
+func ReadKepler(fileName string) []KeplerTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []KeplerTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+		run := strings.Fields(parts[2])
+		if len(run) != 4 {
+			continue
+		}
+
+		test := KeplerTestCases{id: strings.TrimSpace(parts[0]), integrator: run[2], maxPosition: -1}
+		test.e, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		nbody.Check(err)
+		test.stepsPerOrbit, err = strconv.Atoi(run[0])
+		nbody.Check(err)
+		test.orbits, err = strconv.Atoi(run[1])
+		nbody.Check(err)
+		test.substeps, err = strconv.Atoi(run[3])
+		nbody.Check(err)
+
+		if expected := strings.Fields(parts[3]); len(expected) == 3 {
+			var nums [3]float64
+			for i, field := range expected {
+				nums[i], err = strconv.ParseFloat(field, 64)
+				nbody.Check(err)
+			}
+			test.maxPosition, test.maxPhase, test.maxEnergy = nums[0], nums[1], nums[2]
+		}
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestKepler tests that the two bodies of DefaultKeplerOrbit follow the analytic orbit as closely as expected, as
+// measured by the KeplerMonitor, and that invalid eccentricities are rejected.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestKepler(t *testing.T) {
+	tests := ReadKepler("../Tests/Kepler.txt")
+
+	for _, test := range tests {
+		err := CheckKeplerEccentricity(test.e)
+		if test.maxPosition < 0 {
+			if err == nil {
+				t.Errorf("TestKepler(test %v) error = nil, want an error", test.id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestKepler(test %v) error = %v, want nil", test.id, err)
+			continue
+		}
+
+		orbit := DefaultKeplerOrbit(test.e)
+		dt := orbit.Period() / float64(test.stepsPerOrbit)
+		u := orbit.Universe(4*nbody.LengthUnits["AU"], dt/float64(test.substeps))
+		options := simulation.DefaultOptions(test.orbits*test.stepsPerOrbit, dt, 0.5)
+		options.Integrator = test.integrator
+		options.Substeps = test.substeps
+
+		monitor := NewKeplerMonitor(orbit)
+		nbody.Check(simulation.RunGenerations(u, options.WithHook(monitor.Hook(options))))
+
+		if orbits := monitor.time / orbit.Period(); math.Abs(orbits-float64(test.orbits)) > 1e-9 {
+			t.Errorf("TestKepler(test %v) followed %v orbits, want %d", test.id, orbits, test.orbits)
+		}
+		if monitor.maxPosition > test.maxPosition || monitor.maxPhase > test.maxPhase || monitor.maxEnergy > test.maxEnergy {
+			t.Errorf("TestKepler(test %v) errors = position %v, phase %v, energy %v, want at most %v, %v, %v",
+				test.id, monitor.maxPosition, monitor.maxPhase, monitor.maxEnergy,
+				test.maxPosition, test.maxPhase, test.maxEnergy)
+		}
+	}
+}
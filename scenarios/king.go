@@ -0,0 +1,207 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: King-profile star clusters (command "cluster" and configuration kind "king").
+
+package scenarios
+
+import (
+	"BarnesHut/nbody"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// A King model (King 1966) is a lowered isothermal sphere: its stars have the distribution function
+// f(E) ~ exp(-E / sigma^2) - 1 below the escape energy and 0 above, so the cluster ends at a tidal radius.
+// Its shape depends only on the dimensionless central potential W0 = psi(0) / sigma^2: clusters with W0 around 3
+// are loose, globular clusters have W0 from about 5 to 9, and W0 above 10 gives a dense core with an extended halo.
+// In units of the King radius r0 = sqrt(9 sigma^2 / (4 pi G rho0)), the potential W(r) solves
+//
+//	W'' + 2 W' / r = -9 rho(W) / rho0,  W(0) = W0,  W'(0) = 0,
+//
+// and the tidal radius is where W reaches 0.
+
+// KingW0 is the central potential W0 of the "cluster" scenario, set from the -king-w0 option.
+var KingW0 = 6.0
+
+
+// CheckKingW0 returns an error if w0 is not a usable central potential of a King model.
+func CheckKingW0(w0 float64) error {
+	if w0 <= 0 || w0 > 16 {
+		return fmt.Errorf("King central potential W0 must be in (0, 16], got %v", w0)
+	}
+	return nil
+}
+
+
+// kingDensity returns the density of a King model at potential w, up to the factor that makes it rho0 at the center:
+// exp(w) erf(sqrt(w)) - sqrt(4 w / pi) (1 + 2 w / 3), the integral of the distribution function over the velocities.
+func kingDensity(w float64) float64 {
+	if w <= 0 {
+		return 0
+	}
+	return math.Exp(w)*math.Erf(math.Sqrt(w)) - math.Sqrt(4*w/math.Pi)*(1+2*w/3)
+}
+
+
+// KingProfile is the solution of the King equation for one W0, tabulated from the center to the tidal radius
+// in units of the King radius r0 and of rho0 r0^3.
+type KingProfile struct {
+	radius []float64 // radii in increasing order, the last one the tidal radius
+	w      []float64 // the potential W at every radius
+	mass   []float64 // the mass inside every radius, -(4 pi / 9) r^2 W'(r) by the Poisson equation
+}
+
+
+// SolveKing integrates the King equation for a central potential w0 with a fourth order Runge-Kutta method, in steps
+// of 1% of the radius, until the potential reaches 0.
+// Input:
+//   - w0: the central potential W0 (see CheckKingW0).
+// Output:
+//   - the KingProfile.
+func SolveKing(w0 float64) KingProfile {
+	rho0 := kingDensity(w0)
+	// derivative of (W, W') at radius r
+	derivative := func(r, w, dw float64) (float64, float64) {
+		return dw, -9*kingDensity(w)/rho0 - 2*dw/r
+	}
+
+	// near the center W = W0 - 3 r^2 / 2
+	r := 1e-4
+	w, dw := w0-1.5*r*r, -3*r
+	profile := KingProfile{radius: []float64{0}, w: []float64{w0}, mass: []float64{0}}
+
+	for w > 0 {
+		step := 0.01*r + 1e-4
+		h := step
+		k1w, k1d := derivative(r, w, dw)
+		k2w, k2d := derivative(r+h/2, w+h/2*k1w, dw+h/2*k1d)
+		k3w, k3d := derivative(r+h/2, w+h/2*k2w, dw+h/2*k2d)
+		k4w, k4d := derivative(r+h, w+h*k3w, dw+h*k3d)
+		nextW := w + h/6*(k1w+2*k2w+2*k3w+k4w)
+		nextDW := dw + h/6*(k1d+2*k2d+2*k3d+k4d)
+
+		// the last step ends at the tidal radius, where W crosses 0
+		if nextW <= 0 {
+			h *= w / (w - nextW)
+			nextDW = dw + (nextDW-dw)*h/step
+			nextW = 0
+		}
+		r, w, dw = r+h, nextW, nextDW
+
+		profile.radius = append(profile.radius, r)
+		profile.w = append(profile.w, w)
+		profile.mass = append(profile.mass, -4*math.Pi/9*r*r*dw)
+	}
+
+	return profile
+}
+
+
+// TidalRadius returns the tidal radius of the profile in units of the King radius.
+func (p KingProfile) TidalRadius() float64 {
+	return p.radius[len(p.radius)-1]
+}
+
+
+// TotalMass returns the mass of the profile in units of rho0 r0^3.
+func (p KingProfile) TotalMass() float64 {
+	return p.mass[len(p.mass)-1]
+}
+
+
+// Sample returns the radius inside which a fraction of the mass lies and the potential W there,
+// interpolating linearly between the tabulated radii.
+func (p KingProfile) Sample(fraction float64) (float64, float64) {
+	m := fraction * p.TotalMass()
+	i := sort.SearchFloat64s(p.mass, m)
+	if i == 0 {
+		return 0, p.w[0]
+	}
+	if i == len(p.mass) {
+		return p.TidalRadius(), 0
+	}
+	t := (m - p.mass[i-1]) / (p.mass[i] - p.mass[i-1])
+	return p.radius[i-1] + t*(p.radius[i]-p.radius[i-1]), p.w[i-1] + t*(p.w[i]-p.w[i-1])
+}
+
+
+// kingSpeed draws the speed, in units of sigma, of a star at potential w of a King model: the speeds v below the
+// escape speed sqrt(2 w) have the density v^2 (exp(w - v^2 / 2) - 1). They are drawn by rejection under the bound
+// min(2 exp(w - 1), 2 w (exp(w) - 1)) of the density.
+func kingSpeed(w float64) float64 {
+	if w <= 0 {
+		return 0
+	}
+	vMax := math.Sqrt(2 * w)
+	bound := math.Min(2*math.Exp(w-1), 2*w*math.Expm1(w))
+	for {
+		v := vMax * nbody.RNG.Float64()
+		if nbody.RNG.Float64()*bound <= v*v*math.Expm1(w-v*v/2) {
+			return v
+		}
+	}
+}
+
+
+// KingModel returns a star cluster drawn from a King model, seen in the plane of the universe.
+// Every star gets a radius and a potential from the mass profile of SolveKing and a speed from the distribution
+// function at that potential; its position and velocity point in random directions in space, and the simulation
+// keeps their x and y components. As for PlummerModel, the velocities are then scaled so that the projected
+// cluster is in virial equilibrium in the plane (2 K = -W, with the potential softened by softening) and the
+// cluster is at rest as a whole.
+// Input:
+//   - numStars: number of stars, all of the same mass.
+//   - w0: the central potential W0 (see CheckKingW0).
+//   - mass: total mass of the cluster in kg.
+//   - radius: tidal radius of the cluster in m.
+//   - center: center of the cluster.
+//   - softening: softening length used for the potential.
+// Output:
+//   - the cluster as a Galaxy.
+func KingModel(numStars int, w0, mass, radius float64, center nbody.OrderedPair, softening float64) nbody.Galaxy {
+	profile := SolveKing(w0)
+	r0 := radius / profile.TidalRadius()
+	// from r0^2 = 9 sigma^2 / (4 pi G rho0) and mass = rho0 r0^3 TotalMass
+	sigma := math.Sqrt(4 * math.Pi * nbody.G * mass / (9 * profile.TotalMass() * r0))
+
+	// a random direction in space, of which the x and y components are kept
+	direction := func() (float64, float64) {
+		cosTheta := 2*nbody.RNG.Float64() - 1
+		sinTheta := math.Sqrt(1 - cosTheta*cosTheta)
+		phi := 2 * math.Pi * nbody.RNG.Float64()
+		return sinTheta * math.Cos(phi), sinTheta * math.Sin(phi)
+	}
+
+	g := make(nbody.Galaxy, numStars)
+	var mean nbody.OrderedPair
+	for i := range g {
+		r, w := profile.Sample(nbody.RNG.Float64())
+		dx, dy := direction()
+		v := kingSpeed(w) * sigma
+		vx, vy := direction()
+
+		position := nbody.OrderedPair{X: center.X + r*r0*dx, Y: center.Y + r*r0*dy}
+		velocity := nbody.OrderedPair{X: v * vx, Y: v * vy}
+		mean.X += velocity.X / float64(numStars)
+		mean.Y += velocity.Y / float64(numStars)
+		g[i] = nbody.NewStar(position, velocity, mass/float64(numStars), 696340000)
+	}
+
+	for _, s := range g {
+		s.Velocity.X -= mean.X
+		s.Velocity.Y -= mean.Y
+	}
+	if numStars > 1 {
+		u := &nbody.Universe{Stars: g}
+		if kinetic := nbody.KineticEnergy(u); kinetic > 0 {
+			scale := math.Sqrt(-nbody.SoftenedPotentialEnergy(u, softening) / (2 * kinetic))
+			for _, s := range g {
+				s.Velocity.X *= scale
+				s.Velocity.Y *= scale
+			}
+		}
+	}
+
+	return g
+}
@@ -0,0 +1,108 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for King-profile star clusters in king.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package scenarios
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type KingModelTestCases struct {
+	id            string
+	w0            float64
+	numStars      int
+	concentration float64 // expected log10 of the tidal radius over the King radius
+}
+
+
+// ReadKingModel reads test data for SolveKing and KingModel from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of KingModelTestCases structs containing the models and their expected concentrations.
+func ReadKingModel(fileName string) []KingModelTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []KingModelTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		var test KingModelTestCases
+		test.id = strings.TrimSpace(parts[0])
+		test.w0, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		nbody.Check(err)
+		test.numStars, err = strconv.Atoi(strings.TrimSpace(parts[2]))
+		nbody.Check(err)
+		test.concentration, err = strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
+		nbody.Check(err)
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestKingModel tests that SolveKing gives the known concentrations of King models, and that the clusters of
+// KingModel have the requested mass, lie inside the tidal radius, are at rest, and are in virial equilibrium.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestKingModel(t *testing.T) {
+	tests := ReadKingModel("../Tests/KingModel.txt")
+	mass, radius := 1e5*nbody.SolarMass, 30*nbody.LengthUnits["pc"]
+	center := nbody.OrderedPair{X: 50 * nbody.LengthUnits["pc"], Y: 50 * nbody.LengthUnits["pc"]}
+
+	for _, test := range tests {
+		if c := math.Log10(SolveKing(test.w0).TidalRadius()); math.Abs(c-test.concentration) > 0.02 {
+			t.Errorf("TestKingModel(test %v) concentration = %.3f, want %.3f", test.id, c, test.concentration)
+		}
+
+		nbody.SetDeterministic()
+		g := KingModel(test.numStars, test.w0, mass, radius, center, 0)
+		if len(g) != test.numStars {
+			t.Fatalf("TestKingModel(test %v) has %d stars, want %d", test.id, len(g), test.numStars)
+		}
+
+		total := 0.0
+		var momentum nbody.OrderedPair
+		for _, s := range g {
+			total += s.Mass
+			momentum.X += s.Mass * s.Velocity.X
+			momentum.Y += s.Mass * s.Velocity.Y
+			if _, _, d := nbody.Distance(s.Position, center); d > radius*(1+1e-9) {
+				t.Errorf("TestKingModel(test %v) has a star at %v, outside the tidal radius %v", test.id, d, radius)
+				break
+			}
+		}
+		if math.Abs(total-mass) > 1e-9*mass {
+			t.Errorf("TestKingModel(test %v) mass = %v, want %v", test.id, total, mass)
+		}
+
+		u := &nbody.Universe{Stars: g}
+		kinetic := nbody.KineticEnergy(u)
+		if drift := math.Hypot(momentum.X, momentum.Y) / (total * math.Sqrt(2*kinetic/total)); drift > 1e-9 {
+			t.Errorf("TestKingModel(test %v) moves as a whole, momentum %v", test.id, momentum)
+		}
+		if ratio := 2 * kinetic / -nbody.SoftenedPotentialEnergy(u, 0); math.Abs(ratio-1) > 1e-9 {
+			t.Errorf("TestKingModel(test %v) virial ratio 2K/-W = %v, want 1", test.id, ratio)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Command line overrides of the parameters of a scenario (options "-gens", "-dt", "-theta",
+// "-width", "-height", "-canvas", "-frequency", and "-scale").
+
+package scenarios
+
+import (
+	"flag"
+	"fmt"
+)
+
+// AddScenarioFlags defines the options overriding the parameters of a scenario on a flag set.
+// Their defaults are only placeholders: ApplyOverrides uses the options given on the command line.
+func AddScenarioFlags(flags *flag.FlagSet) {
+	flags.Int("gens", 0, "number of generations (default: the scenario's)")
+	flags.Float64("dt", 0, "time step of one generation in seconds (default: the scenario's)")
+	flags.Float64("theta", 0, "opening threshold of the Barnes-Hut approximation (default: the scenario's)")
+	flags.Float64("width", 0, "width of the universe in meters; the stars keep their positions (default: the scenario's)")
+	flags.Float64("height", 0, "height of a rectangular universe in meters, 0 for a square one; the frames get its proportions (default: the scenario's)")
+	flags.Int("canvas", 0, "width of the frames in pixels (default: the scenario's)")
+	flags.Int("frequency", 0, "generations between two drawn frames (default: the scenario's)")
+	flags.Float64("scale", 0, "scaling factor of the star discs when drawing (default: the scenario's)")
+}
+
+
+// ApplyOverrides replaces the parameters of a scenario by the options of AddScenarioFlags that were given
+// on the command line, and checks the drawing parameters (the others are checked by Options.Validate).
+// Input:
+//   - flags: the parsed flag set.
+// Output:
+//   - an error describing the first invalid value, or nil.
+func (scenario *Scenario) ApplyOverrides(flags *flag.FlagSet) error {
+	flags.Visit(func(f *flag.Flag) {
+		getter, ok := f.Value.(flag.Getter)
+		if !ok {
+			return
+		}
+		switch value := getter.Get().(type) {
+		case int:
+			switch f.Name {
+			case "gens":
+				scenario.NumGens = value
+			case "canvas":
+				scenario.CanvasWidth = value
+			case "frequency":
+				scenario.Frequency = value
+			}
+		case float64:
+			switch f.Name {
+			case "dt":
+				scenario.Time = value
+			case "theta":
+				scenario.Theta = value
+			case "width":
+				scenario.Width = value
+				scenario.InitialUniverse.Width = value
+			case "height":
+				scenario.InitialUniverse.Height = value
+			case "scale":
+				scenario.ScalingFactor = value
+			}
+		}
+	})
+
+	switch {
+	case scenario.Width <= 0:
+		return fmt.Errorf("width of the universe must be positive, got %v", scenario.Width)
+	case scenario.InitialUniverse.Height < 0:
+		return fmt.Errorf("height of the universe must be positive, or 0 for a square universe, got %v", scenario.InitialUniverse.Height)
+	case scenario.CanvasWidth < 1:
+		return fmt.Errorf("canvas width must be at least 1 pixel, got %d", scenario.CanvasWidth)
+	case scenario.Frequency < 1:
+		return fmt.Errorf("frame frequency must be at least 1, got %d", scenario.Frequency)
+	case scenario.ScalingFactor <= 0:
+		return fmt.Errorf("scaling factor must be positive, got %v", scenario.ScalingFactor)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the scenario overrides in overrides.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package scenarios
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"flag"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type ApplyOverridesTestCases struct {
+	id       string
+	args     []string
+	expected *Scenario // nil if the options are invalid
+	height   float64   // expected height of the initial universe
+}
+
+
+// ReadApplyOverrides reads test data for the ApplyOverrides function from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of ApplyOverridesTestCases structs containing the arguments and the expected scenario.
+func ReadApplyOverrides(fileName string) []ApplyOverridesTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []ApplyOverridesTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 3 {
+			continue
+		}
+
+		test := ApplyOverridesTestCases{
+			id:   strings.TrimSpace(parts[0]),
+			args: strings.Fields(parts[1]),
+		}
+		if expected := strings.TrimSpace(parts[2]); expected != "error" {
+			var v [8]float64
+			for i, field := range strings.Split(expected, ",") {
+				v[i], err = strconv.ParseFloat(strings.TrimSpace(field), 64)
+				nbody.Check(err)
+			}
+			test.expected = &Scenario{NumGens: int(v[0]), Time: v[1], Theta: v[2], Width: v[3],
+				CanvasWidth: int(v[4]), Frequency: int(v[5]), ScalingFactor: v[6]}
+			test.height = v[7]
+		}
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestApplyOverrides tests the ApplyOverrides function using data from a file.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestApplyOverrides(t *testing.T) {
+	tests := ReadApplyOverrides("../Tests/ApplyOverrides.txt")
+
+	for _, test := range tests {
+		scenario := Scenario{NumGens: 100, Time: 10, Theta: 0.5, Width: 1000, CanvasWidth: 200, Frequency: 10,
+			ScalingFactor: 5, InitialUniverse: nbody.NewUniverse(1000)}
+
+		flags := flag.NewFlagSet("test", flag.ContinueOnError)
+		flags.SetOutput(io.Discard)
+		AddScenarioFlags(flags)
+		nbody.Check(flags.Parse(test.args))
+		err := scenario.ApplyOverrides(flags)
+
+		if test.expected == nil {
+			if err == nil {
+				t.Errorf("TestApplyOverrides(test %v) error = nil, want an error", test.id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestApplyOverrides(test %v) error = %v, want nil", test.id, err)
+			continue
+		}
+
+		test.expected.InitialUniverse = scenario.InitialUniverse
+		if !reflect.DeepEqual(scenario, *test.expected) {
+			t.Errorf("TestApplyOverrides(test %v) = %+v, want %+v", test.id, scenario, *test.expected)
+		}
+		if scenario.InitialUniverse.Width != scenario.Width {
+			t.Errorf("TestApplyOverrides(test %v) universe width = %v, want %v", test.id, scenario.InitialUniverse.Width, scenario.Width)
+		}
+		if scenario.InitialUniverse.Height != test.height {
+			t.Errorf("TestApplyOverrides(test %v) universe height = %v, want %v", test.id, scenario.InitialUniverse.Height, test.height)
+		}
+	}
+}
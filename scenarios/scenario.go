@@ -0,0 +1,191 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: The built-in scenarios of the simulation commands, shared by the command line and the WebAssembly build.
+
+package scenarios
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/simulation"
+	"fmt"
+)
+
+// Scenario holds the parameters and the initial universe of one of the simulation commands.
+type Scenario struct {
+	Width         float64
+	NumGens       int
+	Time          float64
+	Theta         float64
+	CanvasWidth   int
+	Frequency     int
+	ScalingFactor float64
+
+	InitialUniverse *nbody.Universe
+	Halos           []simulation.Halo // analytic halos of the galaxies (see InitializeGalaxyModel)
+}
+
+
+// LoadScenario sets up the parameters and the initial universe of a simulation command.
+// Input:
+//   - command: "jupiter", "galaxy", "collision", "disk", "cluster", "solar", or "kepler".
+// Output:
+//   - the Scenario, or an error if the command is unknown or its data file can not be read.
+func LoadScenario(command string) (Scenario, error) {
+	// initialize parameters, will be customerized for each command
+	width := 0.0
+	numGens := 0
+	time := 0.0
+	theta := 0.0
+
+	canvasWidth := 0
+	frequency := 0
+	scalingFactor := 0.0
+
+	var initialUniverse *nbody.Universe
+
+	// set different parameters for different command
+	switch command {
+
+	// set parameters for argument "jupiter"
+	case "jupiter":
+		// The "jupiter" scenario uses much smaller parameters (such as width, time, and scaling factors) 
+		// because Jupiter's moons occur on a much smaller spatial and temporal scale than galactic interactions.
+		width = 1.0e23
+		numGens = 100000
+		time = 1e1
+		theta = 0.5
+
+		canvasWidth = 1000
+		frequency = 1000
+		scalingFactor = 5.0
+
+		// "Data/jupiterMoons.txt" is copy from "ProgrammingforScientists2025Grad/Starter_Code/gravity/data"
+		u, err := LoadJupiterMoons("Data/jupiterMoons.txt")
+		if err != nil {
+			return Scenario{}, err
+		}
+		initialUniverse = u
+		fmt.Fprintln(nbody.StatusOutput, "Loaded", len(initialUniverse.Stars), "bodies from file.")
+		for _, s := range initialUniverse.Stars {
+    		fmt.Fprintf(nbody.StatusOutput, "star at (%.2f, %.2f)\n", s.Position.X, s.Position.Y)
+			fmt.Fprintf(nbody.StatusOutput, "star velocity (%.2f, %.2f)\n", s.Velocity.X, s.Velocity.Y)
+			fmt.Fprintf(nbody.StatusOutput, "star mass (%.2f)\n", s.Mass)
+			fmt.Fprintf(nbody.StatusOutput, "star radius (%.2f)\n", s.Radius)
+		}
+		
+
+	// set parameters for argument "galaxy"
+	case "galaxy":
+		width = 1.0e23
+		numGens = 100000
+		time = 2e15
+		theta = 0.5
+
+		canvasWidth = 1000
+		frequency = 1000
+		scalingFactor = 5e11
+
+		g := nbody.InitializeGalaxy(500, 1e22, 5e22, 5e22)
+		g = append(g, nbody.GalaxyTracers(nbody.NumTracers, 1e22, g[len(g)-1])...)
+		initialUniverse = nbody.InitializeUniverse([]nbody.Galaxy{g}, width)
+
+	// set parameters for argument "collision"
+	case "collision":
+		width = collisionWidth
+		numGens = 100000
+		time = 2e14
+		theta = 0.5
+
+		canvasWidth = 1000
+		frequency = 1000
+		scalingFactor = 1e11
+
+		// the galaxies and their push are set by -galaxies, -positions, -impact, ... (see CollisionGeometry)
+		galaxies := collisionGeometry.Galaxies(collisionStars, collisionRadius, width)
+		for k, g := range galaxies {
+			galaxies[k] = append(g, nbody.GalaxyTracers(nbody.NumTracers, collisionRadius, g[len(g)-1])...)
+		}
+		initialUniverse = nbody.InitializeUniverse(galaxies, width)
+		if len(galaxies) == 2 {
+			fmt.Fprintf(nbody.StatusOutput, "Encounter eccentricity %.3g (below 1 bound, 1 parabolic, above 1 hyperbolic)\n",
+				EncounterEccentricity(galaxies[0], galaxies[1]))
+		}
+
+	// set parameters for argument "cluster": a globular cluster drawn from a King model with W0 given by -king-w0
+	case "cluster":
+		width = 80 * nbody.LengthUnits["pc"]
+		numGens = 20000
+		time = 3e11 // about a hundredth of the crossing time of the half-mass radius
+		theta = 0.5
+
+		canvasWidth = 1000
+		frequency = 100
+		scalingFactor = 5e6
+
+		g := KingModel(1000, KingW0, 1e5*nbody.SolarMass, 30*nbody.LengthUnits["pc"], nbody.OrderedPair{X: width / 2, Y: width / 2}, 0)
+		initialUniverse = nbody.InitializeUniverse([]nbody.Galaxy{g}, width)
+
+	// set parameters for argument "solar": the Sun and the eight planets, whose periods are compared to the known ones
+	case "solar":
+		width = 70 * nbody.LengthUnits["AU"]
+		numGens = 120000 // one orbit of Neptune
+		time = 0.5 * secondsPerDay
+		theta = 0.5
+
+		canvasWidth = 1000
+		frequency = 400
+		scalingFactor = 1.0
+
+		initialUniverse = InitializeSolarSystem(width)
+
+	// set parameters for argument "kepler": two bodies on the orbit with eccentricity -kepler-e, compared to the
+	// analytic solution
+	case "kepler":
+		orbit := DefaultKeplerOrbit(KeplerE)
+		width = 4 * nbody.LengthUnits["AU"]
+		numGens = keplerOrbits * keplerStepsPerOrbit
+		time = orbit.Period() / keplerStepsPerOrbit
+		theta = 0.5
+
+		canvasWidth = 1000
+		frequency = 20
+		scalingFactor = 1.0
+
+		initialUniverse = orbit.Universe(width, time)
+
+	// set parameters for argument "disk": a self-gravitating disk at the Toomre Q given by -toomre-q
+	case "disk":
+		width = 1.0e23
+		numGens = 100000
+		time = 5e15
+		theta = 0.5
+
+		canvasWidth = 1000
+		frequency = 1000
+		scalingFactor = 5e11
+
+		g := InitializeDisk(2000, 2e22, 5e22, 5e22, ToomreQ)
+		initialUniverse = nbody.InitializeUniverse([]nbody.Galaxy{g}, width)
+
+	default:
+		return Scenario{}, fmt.Errorf("unknown command %q", command)
+
+	}
+
+	return Scenario{
+		Width:           width,
+		NumGens:         numGens,
+		Time:            time,
+		Theta:           theta,
+		CanvasWidth:     canvasWidth,
+		Frequency:       frequency,
+		ScalingFactor:   scalingFactor,
+		InitialUniverse: initialUniverse,
+	}, nil
+}
+
+
+// Options returns the default simulation Options of the scenario, with its analytic halos.
+func (scenario Scenario) Options() simulation.Options {
+	return simulation.DefaultOptions(scenario.NumGens, scenario.Time, scenario.Theta).WithHalos(scenario.Halos...)
+}
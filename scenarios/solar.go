@@ -0,0 +1,161 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: The Sun and the eight planets (command "solar"), with the measured orbital periods compared to the
+// known ones as a sanity check of the simulation.
+
+package scenarios
+
+import (
+	"BarnesHut/nbody"
+	"fmt"
+	"io"
+	"math"
+)
+
+// secondsPerDay converts the orbital periods of solarPlanets into seconds.
+const secondsPerDay = 86400.0
+
+// A Planet is a body of the solar system scenario.
+type Planet struct {
+	name   string
+	a      float64 // semi-major axis in AU
+	mass   float64 // mass in Earth masses
+	radius float64 // drawn radius in m (see solarPlanets)
+	period float64 // known sidereal orbital period in days
+	red    uint8
+	green  uint8
+	blue   uint8
+}
+
+// solarPlanets are the planets in order from the Sun. The orbits are taken as circles of radius a: with the
+// circular speed of every planet, its period follows Kepler's third law and is close to the known one.
+// Like the black holes of the galaxies, the bodies are drawn larger than they are (the Sun about 60 times, the
+// planets hundreds to thousands of times) so that all of them are visible on the scale of Neptune's orbit;
+// only the drawing uses the radii.
+var solarPlanets = []Planet{
+	{"Mercury", 0.387, 0.0553, 1.5e10, 87.97, 183, 183, 183},
+	{"Venus", 0.723, 0.815, 2e10, 224.70, 230, 200, 140},
+	{"Earth", 1.000, 1.0, 2e10, 365.26, 80, 130, 230},
+	{"Mars", 1.524, 0.107, 1.5e10, 686.98, 210, 90, 50},
+	{"Jupiter", 5.203, 317.8, 4e10, 4332.59, 203, 145, 96},
+	{"Saturn", 9.537, 95.16, 3.5e10, 10759.22, 220, 195, 130},
+	{"Uranus", 19.19, 14.54, 3e10, 30688.5, 150, 215, 225},
+	{"Neptune", 30.07, 17.15, 3e10, 60182, 70, 100, 220},
+}
+
+// sunRadius is the drawn radius of the Sun in m.
+const sunRadius = 4e10
+
+
+// InitializeSolarSystem returns a universe with the Sun at its center and the planets of solarPlanets on circular
+// orbits around it, in the plane of the universe and counterclockwise. The planets start at angles that grow by the
+// golden angle (137.5 degrees), so they do not line up, and the Sun moves so that the total momentum is zero.
+// Input:
+//   - width: width of the universe in m (at least twice Neptune's orbit, 60 AU, to keep every planet inside).
+// Output:
+//   - pointer to the Universe; star 0 is the Sun and star k the k-th planet.
+func InitializeSolarSystem(width float64) *nbody.Universe {
+	u := nbody.NewUniverse(width)
+	center := nbody.OrderedPair{X: width / 2, Y: width / 2}
+	sun := nbody.NewStar(center, nbody.OrderedPair{}, nbody.SolarMass, sunRadius)
+	sun.Red, sun.Green, sun.Blue = 255, 220, 80
+	u.AddStars(sun)
+
+	var momentum nbody.OrderedPair
+	goldenAngle := math.Pi * (3 - math.Sqrt(5))
+	for k, p := range solarPlanets {
+		r := p.a * nbody.LengthUnits["AU"]
+		m := p.mass * nbody.MassUnits["Mearth"]
+		speed := math.Sqrt(nbody.G * (nbody.SolarMass + m) / r)
+		angle := float64(k) * goldenAngle
+
+		s := nbody.NewStar(nbody.OrderedPair{X: center.X + r*math.Cos(angle), Y: center.Y + r*math.Sin(angle)},
+			nbody.OrderedPair{X: -speed * math.Sin(angle), Y: speed * math.Cos(angle)}, m, p.radius)
+		s.Red, s.Green, s.Blue = p.red, p.green, p.blue
+		u.AddStars(s)
+
+		momentum.X += m * s.Velocity.X
+		momentum.Y += m * s.Velocity.Y
+	}
+	sun.Velocity = nbody.OrderedPair{X: -momentum.X / nbody.SolarMass, Y: -momentum.Y / nbody.SolarMass}
+
+	return u
+}
+
+
+// PeriodMonitor measures the orbital periods of the planets of a solar system run from how far they went around
+// the Sun: a planet that turned by the angle phi in the time t has the period 2 pi t / phi. The angles are
+// unwrapped every generation, so planets that have not completed an orbit are measured as well.
+type PeriodMonitor struct {
+	angles  []float64 // angle of every planet around the Sun in the last generation, in radians
+	turned  []float64 // angle every planet turned since the first generation
+	start   float64   // simulated time of the first generation
+	elapsed float64   // simulated time since the first generation
+}
+
+
+// Hook returns the hook for Options.WithHook that follows the planets. Star 0 must be the Sun and star k the
+// k-th planet of solarPlanets, as InitializeSolarSystem makes them.
+func (m *PeriodMonitor) Hook() func(generation int, u *nbody.Universe) {
+	return func(generation int, u *nbody.Universe) {
+		if len(u.Stars) != len(solarPlanets)+1 {
+			return
+		}
+		sun := u.Stars[0].Position
+		if m.angles == nil {
+			m.angles = make([]float64, len(solarPlanets))
+			m.turned = make([]float64, len(solarPlanets))
+			m.start = u.Time
+			for k := range solarPlanets {
+				p := u.Stars[k+1].Position
+				m.angles[k] = math.Atan2(p.Y-sun.Y, p.X-sun.X)
+			}
+			return
+		}
+
+		m.elapsed = u.Time - m.start
+		for k := range solarPlanets {
+			p := u.Stars[k+1].Position
+			angle := math.Atan2(p.Y-sun.Y, p.X-sun.X)
+			// a planet turns by less than half an orbit per generation
+			step := math.Remainder(angle-m.angles[k], 2*math.Pi)
+			m.turned[k] += step
+			m.angles[k] = angle
+		}
+	}
+}
+
+
+// Periods returns the measured period of every planet in days (0 before any time has passed).
+func (m *PeriodMonitor) Periods() []float64 {
+	periods := make([]float64, len(solarPlanets))
+	for k := range periods {
+		if m.turned != nil && m.turned[k] != 0 {
+			periods[k] = 2 * math.Pi * m.elapsed / math.Abs(m.turned[k]) / secondsPerDay
+		}
+	}
+	return periods
+}
+
+
+// WriteReport writes the measured and the known period of every planet, the relative error, and the number of
+// orbits it completed.
+// Input:
+//   - w: destination of the report.
+// Output:
+//   - an error if writing failed.
+func (m *PeriodMonitor) WriteReport(w io.Writer) error {
+	fmt.Fprintf(w, "%-8s %14s %14s %10s %8s\n", "planet", "period (days)", "known (days)", "error", "orbits")
+	periods := m.Periods()
+	for k, p := range solarPlanets {
+		orbits := 0.0
+		if m.turned != nil {
+			orbits = math.Abs(m.turned[k]) / (2 * math.Pi)
+		}
+		if _, err := fmt.Fprintf(w, "%-8s %14.2f %14.2f %9.3f%% %8.2f\n",
+			p.name, periods[k], p.period, 100*(periods[k]-p.period)/p.period, orbits); err != nil {
+			return err
+		}
+	}
+	return nil
+}
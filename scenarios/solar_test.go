@@ -0,0 +1,96 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the solar system scenario in solar.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package scenarios
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/simulation"
+	"bufio"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type SolarPeriodsTestCases struct {
+	id         string
+	timeStep   float64 // in days
+	numGens    int
+	theta      float64
+	numPlanets int     // the planets checked, from Mercury
+	tolerance  float64 // largest relative error of the periods
+}
+
+
+// ReadSolarPeriods reads test data for the solar system scenario from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of SolarPeriodsTestCases structs containing the runs and the tolerances.
+func ReadSolarPeriods(fileName string) []SolarPeriodsTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []SolarPeriodsTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 6 {
+			continue
+		}
+
+		var test SolarPeriodsTestCases
+		test.id = strings.TrimSpace(parts[0])
+		test.timeStep, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		nbody.Check(err)
+		test.numGens, err = strconv.Atoi(strings.TrimSpace(parts[2]))
+		nbody.Check(err)
+		test.theta, err = strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
+		nbody.Check(err)
+		test.numPlanets, err = strconv.Atoi(strings.TrimSpace(parts[4]))
+		nbody.Check(err)
+		test.tolerance, err = strconv.ParseFloat(strings.TrimSpace(parts[5]), 64)
+		nbody.Check(err)
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestSolarPeriods tests that the planets of InitializeSolarSystem, simulated for a while, go around the Sun with
+// their known orbital periods, as measured by the PeriodMonitor.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestSolarPeriods(t *testing.T) {
+	tests := ReadSolarPeriods("../Tests/SolarPeriods.txt")
+
+	for _, test := range tests {
+		u := InitializeSolarSystem(70 * nbody.LengthUnits["AU"])
+		if n := len(u.Stars); n != len(solarPlanets)+1 {
+			t.Fatalf("TestSolarPeriods(test %v) has %d bodies, want %d", test.id, n, len(solarPlanets)+1)
+		}
+
+		monitor := &PeriodMonitor{}
+		options := simulation.DefaultOptions(test.numGens, test.timeStep*secondsPerDay, test.theta)
+		nbody.Check(simulation.RunGenerations(u, options.WithHook(monitor.Hook())))
+
+		for k, period := range monitor.Periods()[:test.numPlanets] {
+			known := solarPlanets[k].period
+			if err := math.Abs(period-known) / known; err > test.tolerance {
+				t.Errorf("TestSolarPeriods(test %v, %s) = period %.2f days, want %.2f within %v",
+					test.id, solarPlanets[k].name, period, known, test.tolerance)
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: A fixed analytic mass at the center of the universe (options "-central-mass" and "-central-scale").
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"fmt"
+	"math"
+)
+
+// The central mass stands for a body that is too heavy to be moved by the stars, such as a supermassive black hole
+// or the halo of a host galaxy: it pulls every star like the analytic halos of composite galaxies (see
+// Options.potentials), but it is not a star, so it never moves and costs nothing per star. It is a Hernquist sphere of
+// scale a, whose acceleration G M / (r + a)^2 is that of a point mass for a = 0 and is finite at the center otherwise.
+
+// CheckCentralMass returns an error if mass and scale do not describe a central mass (a mass of 0 for none).
+func CheckCentralMass(mass, scale float64) error {
+	switch {
+	case mass < 0:
+		return fmt.Errorf("central mass must not be negative, got %v", mass)
+	case scale < 0:
+		return fmt.Errorf("central scale must not be negative, got %v", scale)
+	case mass == 0 && scale != 0:
+		return fmt.Errorf("-central-scale needs -central-mass")
+	}
+	return nil
+}
+
+
+// CentralMass returns the central mass of a universe as an analytic halo.
+// Input:
+//   - center: the center of the universe (see Universe.Center), where the mass is.
+//   - mass: the mass in kg.
+//   - scale: its Hernquist scale radius in m (0 for a point mass).
+// Output:
+//   - the Halo, or none if the mass is 0.
+func CentralMass(center nbody.OrderedPair, mass, scale float64) []Halo {
+	if mass == 0 {
+		return nil
+	}
+	return []Halo{{Center: center, Mass: mass, Scale: scale}}
+}
+
+
+// CircularSpeed returns the speed of a circular orbit of radius r around the halo, sqrt(G M r) / (r + a).
+func (h Halo) CircularSpeed(r float64) float64 {
+	return math.Sqrt(nbody.G*h.Mass*r) / (r + h.Scale)
+}
+
+
+// A Halo is an analytic dark matter halo with a Hernquist profile, fixed at its center, and an ExternalPotential.
+// With scale 0 it is a point mass (see CentralMass).
+type Halo struct {
+	Center nbody.OrderedPair
+	Mass   float64 // total mass in kg
+	Scale  float64 // scale radius a in m
+}
+
+
+// Acceleration returns the acceleration G M(<r) / r^2 = G M / (r + a)^2 toward the center of the halo at a point.
+// The halo does not change with time.
+func (h Halo) Acceleration(p nbody.OrderedPair, t float64) nbody.OrderedPair {
+	dx, dy, r := nbody.Distance(p, h.Center)
+	if r == 0 {
+		return nbody.OrderedPair{}
+	}
+	a := -nbody.G * h.Mass / ((r + h.Scale) * (r + h.Scale) * r)
+	return nbody.OrderedPair{X: a * dx, Y: a * dy}
+}
+
+
+// Potential returns the potential -G M / (r + a) of the halo at a point.
+func (h Halo) Potential(p nbody.OrderedPair, t float64) float64 {
+	_, _, r := nbody.Distance(p, h.Center)
+	return -nbody.G * h.Mass / (r + h.Scale)
+}
@@ -0,0 +1,119 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the central mass in central.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type CentralMassTestCases struct {
+	id       string
+	mass     float64
+	scale    float64
+	radius   float64
+	maxError float64 // largest relative error of the radius and of the final position, -1 if the mass is invalid
+}
+
+
+// ReadCentralMass reads test data for CentralMass from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of CentralMassTestCases structs containing the central masses and the expected accuracy.
+func ReadCentralMass(fileName string) []CentralMassTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []CentralMassTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 3 {
+			continue
+		}
+
+		fields := strings.Fields(parts[1])
+		if len(fields) != 3 {
+			continue
+		}
+		var nums [3]float64
+		for i, field := range fields {
+			nums[i], err = strconv.ParseFloat(field, 64)
+			nbody.Check(err)
+		}
+
+		test := CentralMassTestCases{id: strings.TrimSpace(parts[0]), mass: nums[0], scale: nums[1], radius: nums[2], maxError: -1}
+		if expected := strings.TrimSpace(parts[2]); expected != "error" {
+			test.maxError, err = strconv.ParseFloat(expected, 64)
+			nbody.Check(err)
+		}
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestCentralMass tests that a star on a circular orbit around the central mass, with the speed of
+// Halo.CircularSpeed, keeps its radius and is back at its start after one period, and that invalid masses are
+// rejected.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestCentralMass(t *testing.T) {
+	tests := ReadCentralMass("../Tests/CentralMass.txt")
+	const width, numGens = 1e12, 2000
+
+	for _, test := range tests {
+		err := CheckCentralMass(test.mass, test.scale)
+		if test.maxError < 0 {
+			if err == nil {
+				t.Errorf("TestCentralMass(test %v) error = nil, want an error", test.id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestCentralMass(test %v) error = %v, want nil", test.id, err)
+			continue
+		}
+
+		halos := CentralMass(nbody.OrderedPair{X: width / 2, Y: width / 2}, test.mass, test.scale)
+		speed := halos[0].CircularSpeed(test.radius)
+		period := 2 * math.Pi * test.radius / speed
+		start := nbody.OrderedPair{X: width/2 + test.radius, Y: width / 2}
+		u := nbody.NewUniverse(width)
+		// the first step of velocity Verlet kicks the star by half of its acceleration first (see IntegrateInPlace)
+		a := halos[0].Acceleration(start, 0)
+		dt := period / numGens
+		u.AddStars(nbody.NewStar(start, nbody.OrderedPair{X: -a.X * dt / 2, Y: speed - a.Y*dt/2}, 1, 1))
+
+		options := DefaultOptions(numGens, dt, 0.5).WithHalos(halos...)
+		worst, end := 0.0, start
+		options = options.WithHook(func(generation int, u *nbody.Universe) {
+			end = u.Stars[0].Position
+			_, _, r := nbody.Distance(end, halos[0].Center)
+			worst = math.Max(worst, math.Abs(r-test.radius)/test.radius)
+		})
+		nbody.Check(RunGenerations(u, options))
+
+		_, _, d := nbody.Distance(end, start)
+		if worst > test.maxError || d/test.radius > test.maxError {
+			t.Errorf("TestCentralMass(test %v) radius error %v and distance from the start %v, want at most %v",
+				test.id, worst, d/test.radius, test.maxError)
+		}
+	}
+}
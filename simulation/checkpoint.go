@@ -0,0 +1,220 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Checkpoints of a running simulation (option "-checkpoint-every") and resuming from them (option "-resume").
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// checkpointMagic starts every checkpoint file.
+var checkpointMagic = [4]byte{'B', 'H', 'C', 'P'}
+
+// A checkpoint is, in little endian:
+//
+//	magic       4 bytes  "BHCP"
+//	generation  int64
+//	seed        int64    seed of nbody.RNG
+//	draws       uint64   values drawn from nbody.RNG (see RandomState)
+//	time        float64  simulated time of the universe
+//	universe    the universe as written by WriteBinaryUniverse, with the generation and the time step of the run
+//
+// Checkpoints written before binary universes had a version end with a legacy binary universe, and are still read.
+type checkpointHeader struct {
+	Magic      [4]byte
+	Generation int64
+	Seed       int64
+	Draws      uint64
+	Time       float64
+}
+
+// A Checkpoint is the state of a run after a generation: everything needed to continue it exactly.
+type Checkpoint struct {
+	generation int
+	seed       int64
+	draws      uint64
+	timeStep   float64 // time step of the run, or 0 if unknown
+	universe   *nbody.Universe
+}
+
+
+// WriteCheckpoint saves the universe of a generation and the state of nbody.RNG to a file.
+// The checkpoint is written to a temporary file that then replaces fileName, so a crash while writing
+// leaves the previous checkpoint intact.
+// Input:
+//   - fileName: the checkpoint file.
+//   - generation: the generation of u.
+//   - u: pointer to the Universe.
+//   - timeStep: time step of the run, checked when it is resumed (see CheckTimeStep).
+// Output:
+//   - an error if the file cannot be written.
+func WriteCheckpoint(fileName string, generation int, u *nbody.Universe, timeStep float64) error {
+	s, draws := nbody.RandomState()
+	return Checkpoint{generation: generation, seed: s, draws: draws, timeStep: timeStep, universe: u}.Write(fileName)
+}
+
+
+// Write saves the checkpoint to a file, through a temporary file like WriteCheckpoint.
+func (c Checkpoint) Write(fileName string) error {
+	tmp := fileName + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	header := checkpointHeader{checkpointMagic, int64(c.generation), c.seed, c.draws, c.universe.Time}
+	err = binary.Write(file, binary.LittleEndian, header)
+	if err == nil {
+		err = nbody.WriteBinaryUniverse(file, c.universe, c.generation, c.timeStep)
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, fileName)
+}
+
+
+// ReadCheckpoint reads a checkpoint written by WriteCheckpoint.
+func ReadCheckpoint(r io.Reader) (Checkpoint, error) {
+	br := bufio.NewReader(r)
+	var header checkpointHeader
+	if err := binary.Read(br, binary.LittleEndian, &header); err != nil {
+		return Checkpoint{}, err
+	}
+	if header.Magic != checkpointMagic {
+		return Checkpoint{}, errors.New("not a checkpoint (bad magic)")
+	}
+	if header.Generation < 0 {
+		return Checkpoint{}, fmt.Errorf("invalid generation %d", header.Generation)
+	}
+
+	u, _, timeStep, err := nbody.ReadBinaryUniverse(br)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	u.Time = header.Time
+
+	return Checkpoint{generation: int(header.Generation), seed: header.Seed, draws: header.Draws, timeStep: timeStep, universe: u}, nil
+}
+
+
+// LoadCheckpoint reads a checkpoint file.
+func LoadCheckpoint(fileName string) (Checkpoint, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	defer file.Close()
+
+	c, err := ReadCheckpoint(file)
+	if err != nil {
+		return Checkpoint{}, fmt.Errorf("%s: %w", fileName, err)
+	}
+	return c, nil
+}
+
+
+// Restore prepares a run to continue from a checkpoint: it restores the state of nbody.RNG and returns the
+// universe to start from and its generation (the firstGeneration of the Options).
+// The other options must be those of the interrupted run, so it continues exactly.
+func (c Checkpoint) Restore() (*nbody.Universe, int) {
+	nbody.SetRandomState(c.seed, c.draws)
+	return c.universe, c.generation
+}
+
+
+// CheckTimeStep returns an error if the checkpoint was written by a run with another time step than timeStep,
+// which could not continue it exactly. Checkpoints that do not know their time step pass.
+func (c Checkpoint) CheckTimeStep(timeStep float64) error {
+	if c.timeStep != 0 && c.timeStep != timeStep {
+		return fmt.Errorf("the checkpoint was written by a run with time step %v, not %v (-dt)", c.timeStep, timeStep)
+	}
+	return nil
+}
+
+
+// CheckpointWriter writes a checkpoint of a run every few generations (option "-checkpoint-every").
+type CheckpointWriter struct {
+	fileName string
+	interval int
+	first    int // the generation the run starts with, which is not written again
+	timeStep float64
+	err      error // the first write error; later checkpoints are dropped
+}
+
+
+// NewCheckpointWriter returns a CheckpointWriter writing every interval-th generation after first to fileName.
+// Input:
+//   - fileName: the checkpoint file, replaced by every checkpoint.
+//   - interval: generations between two checkpoints.
+//   - first: the generation the run starts with (see Options.FirstGeneration).
+//   - timeStep: time step of the run, written with every checkpoint.
+// Output:
+//   - pointer to the CheckpointWriter, whose Hook goes into the Options of the run.
+func NewCheckpointWriter(fileName string, interval, first int, timeStep float64) *CheckpointWriter {
+	return &CheckpointWriter{fileName: fileName, interval: interval, first: first, timeStep: timeStep}
+}
+
+
+// Hook returns the hook writing the checkpoints (see Options.WithHook). After a failed write the run goes on
+// without checkpoints; the error is returned by Err, and the last checkpoint written stays intact.
+func (cw *CheckpointWriter) Hook() func(generation int, u *nbody.Universe) {
+	return func(generation int, u *nbody.Universe) {
+		if cw.err != nil || generation <= cw.first || generation%cw.interval != 0 {
+			return
+		}
+		if err := WriteCheckpoint(cw.fileName, generation, u, cw.timeStep); err != nil {
+			cw.err = fmt.Errorf("checkpoint of generation %d: %w", generation, err)
+		}
+	}
+}
+
+
+// Err returns the first error of writing a checkpoint, if any, to be reported when the run ends.
+func (cw *CheckpointWriter) Err() error {
+	return cw.err
+}
+
+
+// LastGeneration remembers the last generation of a run and the state of nbody.RNG after it, so that a checkpoint
+// can be written when the run is interrupted (Ctrl+C) after any generation, not only every -checkpoint-every.
+// A run stopped through its context ends with the last generation it handed to its hook.
+type LastGeneration struct {
+	checkpoint Checkpoint
+	TimeStep   float64 // time step of the run, written with the checkpoint
+}
+
+
+// Hook returns the hook for Options.WithHook that records every generation.
+func (l *LastGeneration) Hook() func(generation int, u *nbody.Universe) {
+	return func(generation int, u *nbody.Universe) {
+		s, draws := nbody.RandomState()
+		l.checkpoint = Checkpoint{generation: generation, seed: s, draws: draws, timeStep: l.TimeStep, universe: u}
+	}
+}
+
+
+// WriteCheckpoint writes the checkpoint of the last generation recorded.
+// Output:
+//   - the generation written, and an error if no generation was recorded or the file cannot be written.
+func (l *LastGeneration) WriteCheckpoint(fileName string) (int, error) {
+	if l.checkpoint.universe == nil {
+		return 0, errors.New("no generation to write a checkpoint of")
+	}
+	return l.checkpoint.generation, l.checkpoint.Write(fileName)
+}
@@ -0,0 +1,217 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for checkpoints in checkpoint.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type CheckpointTestCases struct {
+	id             string
+	numStars       int
+	numGens        int
+	interval       int
+	lastCheckpoint int
+	events         []Event
+}
+
+
+// ReadCheckpointTests reads test data for checkpoints from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of CheckpointTestCases structs containing the run, the checkpoint interval, and the expected last checkpoint.
+func ReadCheckpointTests(fileName string) []CheckpointTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []CheckpointTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 6 {
+			continue
+		}
+
+		var numbers [4]int
+		for i := range numbers {
+			numbers[i], err = strconv.Atoi(strings.TrimSpace(parts[i+1]))
+			nbody.Check(err)
+		}
+
+		var events []Event
+		if text := strings.TrimSpace(parts[5]); text != "-" {
+			for _, eventLine := range strings.Split(text, ";") {
+				e, err := ParseEvent(strings.TrimSpace(eventLine))
+				nbody.Check(err)
+				events = append(events, e)
+			}
+		}
+
+		tests = append(tests, CheckpointTestCases{
+			id:             strings.TrimSpace(parts[0]),
+			numStars:       numbers[0],
+			numGens:        numbers[1],
+			interval:       numbers[2],
+			lastCheckpoint: numbers[3],
+			events:         events,
+		})
+	}
+
+	return tests
+}
+
+
+// TestCheckpoint tests that a run resumed from its last checkpoint ends with exactly the universe of the uninterrupted run.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestCheckpoint(t *testing.T) {
+	tests := ReadCheckpointTests("../Tests/Checkpoint.txt")
+
+	for _, test := range tests {
+		nbody.SetRandomState(nbody.DeterministicSeed, 0)
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		initialUniverse := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+
+		options := DefaultOptions(test.numGens, 2e14, 0.5).WithEventLog(io.Discard)
+		options.Events = test.events
+
+		// the uninterrupted run writes the checkpoints
+		fileName := filepath.Join(t.TempDir(), "run.checkpoint")
+		var expected *nbody.Universe
+		nbody.Check(RunGenerations(initialUniverse, options.WithHook(NewCheckpointWriter(fileName, test.interval, 0, options.TimeStep).Hook()).WithHook(
+			func(generation int, u *nbody.Universe) { expected = nbody.CopyUniverse(u) })))
+
+		checkpoint, err := LoadCheckpoint(fileName)
+		if err != nil {
+			t.Errorf("TestCheckpoint(test %v) cannot load the checkpoint: %v", test.id, err)
+			continue
+		}
+
+		if checkpoint.CheckTimeStep(options.TimeStep) != nil || checkpoint.CheckTimeStep(options.TimeStep/2) == nil {
+			t.Errorf("TestCheckpoint(test %v) does not check the time step %v of the run", test.id, options.TimeStep)
+		}
+
+		// a different random state must be replaced by the checkpoint's
+		nbody.SetRandomState(nbody.DeterministicSeed+1, 7)
+		resumed, firstGeneration := checkpoint.Restore()
+		if firstGeneration != test.lastCheckpoint {
+			t.Errorf("TestCheckpoint(test %v) resumes from generation %d, want %d", test.id, firstGeneration, test.lastCheckpoint)
+		}
+
+		options.FirstGeneration = firstGeneration
+		var result *nbody.Universe
+		nbody.Check(RunGenerations(resumed, options.WithHook(func(generation int, u *nbody.Universe) { result = nbody.CopyUniverse(u) })))
+
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("TestCheckpoint(test %v) resumed run differs from the uninterrupted run", test.id)
+		}
+	}
+}
+
+
+// TestCheckpointWriteError tests that a run whose checkpoints of Tests/Checkpoint.txt cannot be written ends
+// like the uninterrupted run, with the error of its first checkpoint returned by Err.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestCheckpointWriteError(t *testing.T) {
+	tests := ReadCheckpointTests("../Tests/Checkpoint.txt")
+
+	for _, test := range tests {
+		nbody.SetRandomState(nbody.DeterministicSeed, 0)
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		initialUniverse := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+
+		seed, draws := nbody.RandomState()
+
+		options := DefaultOptions(test.numGens, 2e14, 0.5).WithEventLog(io.Discard)
+		options.Events = test.events
+
+		var expected *nbody.Universe
+		nbody.Check(RunGenerations(nbody.CopyUniverse(initialUniverse), options.WithHook(
+			func(generation int, u *nbody.Universe) { expected = nbody.CopyUniverse(u) })))
+
+		// the directory of the checkpoint does not exist
+		nbody.SetRandomState(seed, draws)
+		writer := NewCheckpointWriter(filepath.Join(t.TempDir(), "missing", "run.checkpoint"), test.interval, 0, options.TimeStep)
+		var result *nbody.Universe
+		err := RunGenerations(initialUniverse, options.WithHook(writer.Hook()).WithHook(
+			func(generation int, u *nbody.Universe) { result = nbody.CopyUniverse(u) }))
+
+		if err != nil || !reflect.DeepEqual(result, expected) {
+			t.Errorf("TestCheckpointWriteError(test %v) = %v, want nil and the final universe of the run without checkpoints", test.id, err)
+		}
+		want := fmt.Sprintf("checkpoint of generation %d: ", test.interval)
+		if writer.Err() == nil || !strings.HasPrefix(writer.Err().Error(), want) {
+			t.Errorf("TestCheckpointWriteError(test %v) Err() = %v, want an error starting with %q", test.id, writer.Err(), want)
+		}
+	}
+}
+
+
+// TestInterruptCheckpoint tests that a run cancelled after the last_checkpoint generation of Tests/Checkpoint.txt
+// leaves a LastGeneration checkpoint from which the run ends exactly like the uninterrupted run.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestInterruptCheckpoint(t *testing.T) {
+	tests := ReadCheckpointTests("../Tests/Checkpoint.txt")
+
+	for _, test := range tests {
+		nbody.SetRandomState(nbody.DeterministicSeed, 0)
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		initialUniverse := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+
+		options := DefaultOptions(test.numGens, 2e14, 0.5).WithEventLog(io.Discard)
+		options.Events = test.events
+
+		var expected *nbody.Universe
+		nbody.Check(RunGenerations(initialUniverse, options.WithHook(func(generation int, u *nbody.Universe) { expected = nbody.CopyUniverse(u) })))
+
+		// the same run, stopped after the generation of the last checkpoint
+		nbody.SetRandomState(nbody.DeterministicSeed, 0)
+		g = nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		initialUniverse = nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+		last := &LastGeneration{}
+		ctx, stop := InterruptContext(fmt.Sprintf("cancel %d", test.lastCheckpoint))
+		err := RunGenerations(initialUniverse, options.WithContext(ctx).WithHook(last.Hook()).WithHook(stop))
+		if test.lastCheckpoint < test.numGens && !Interrupted(err) {
+			t.Errorf("TestInterruptCheckpoint(test %v) returned %v, want an interruption", test.id, err)
+		}
+
+		fileName := filepath.Join(t.TempDir(), "run.checkpoint")
+		generation, err := last.WriteCheckpoint(fileName)
+		nbody.Check(err)
+		if generation != test.lastCheckpoint {
+			t.Errorf("TestInterruptCheckpoint(test %v) wrote generation %d, want %d", test.id, generation, test.lastCheckpoint)
+		}
+
+		checkpoint, err := LoadCheckpoint(fileName)
+		nbody.Check(err)
+		nbody.SetRandomState(nbody.DeterministicSeed+1, 7)
+		resumed, firstGeneration := checkpoint.Restore()
+		options.FirstGeneration = firstGeneration
+		var result *nbody.Universe
+		nbody.Check(RunGenerations(resumed, options.WithHook(func(generation int, u *nbody.Universe) { result = nbody.CopyUniverse(u) })))
+
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("TestInterruptCheckpoint(test %v) resumed run differs from the uninterrupted run", test.id)
+		}
+	}
+}
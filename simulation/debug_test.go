@@ -0,0 +1,383 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the debugging helpers in quadtree/debug.go and the debug dumps of a run.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type TraceNetForceTestCases struct {
+	id       string
+	numStars int
+	theta    float64
+	mac      string
+	traced   []int
+}
+
+// traceForce matches the force at the end of a line of a force trace.
+var traceForce = regexp.MustCompile(`force \(([^,]+), ([^)]+)\)$`)
+
+
+// ReadTraceNetForce reads test data for TraceNetForce from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of TraceNetForceTestCases structs containing the galaxies and the traced stars.
+func ReadTraceNetForce(fileName string) []TraceNetForceTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []TraceNetForceTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+
+		numStars, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		theta, err := strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+		nbody.Check(err)
+		var traced []int
+		for _, field := range strings.Fields(parts[4]) {
+			index, err := strconv.Atoi(field)
+			nbody.Check(err)
+			traced = append(traced, index)
+		}
+
+		tests = append(tests, TraceNetForceTestCases{
+			id:       strings.TrimSpace(parts[0]),
+			numStars: numStars,
+			theta:    theta,
+			mac:      strings.TrimSpace(parts[3]),
+			traced:   traced,
+		})
+	}
+
+	return tests
+}
+
+
+// expectedTrace returns the start of every line the force trace of s must have for a subtree: a leaf line for
+// every other star of a leaf, and a far node line for every node the criterion accepts, whose children are not
+// visited.
+func expectedTrace(node *quadtree.Node, s *nbody.Star, theta float64, mac string, depth int) []string {
+	if node == nil || node.Star == nil || node.Star.Mass == 0 {
+		return nil
+	}
+	indent := strings.Repeat("  ", depth)
+
+	if !quadtree.IsLeaf(node) && node.Star != s {
+		_, _, d := nbody.Distance(node.Star.Position, s.Position)
+		accepted := d != 0 && node.Sector.Width/d < theta
+		if mac == "bmax" {
+			center := nbody.OrderedPair{X: node.Sector.X + node.Sector.Width/2, Y: node.Sector.Y + node.Sector.Width/2}
+			_, _, delta := nbody.Distance(node.Star.Position, center)
+			accepted = d != 0 && theta > 0 && d > node.Sector.Width/theta+delta
+		}
+		if accepted {
+			return []string{fmt.Sprintf("%sfar node at (%.4e, %.4e) mass %.4e", indent, node.Star.Position.X, node.Star.Position.Y, node.Star.Mass)}
+		}
+	}
+
+	var lines []string
+	if quadtree.IsLeaf(node) {
+		for _, other := range quadtree.LeafStars(node) {
+			if other != s {
+				lines = append(lines, fmt.Sprintf("%sleaf star at (%.4e, %.4e) mass %.4e", indent, other.Position.X, other.Position.Y, other.Mass))
+			}
+		}
+		return lines
+	}
+	for _, child := range node.Children {
+		lines = append(lines, expectedTrace(child, s, theta, mac, depth+1)...)
+	}
+	return lines
+}
+
+
+// TestTraceNetForce tests that the force trace of a star visits the nodes the opening criterion accepts and
+// opens the others, and that the forces of its lines add up to its total, which is the force of CalculateNetForce.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestTraceNetForce(t *testing.T) {
+	tests := ReadTraceNetForce("../Tests/TraceNetForce.txt")
+
+	for _, test := range tests {
+		nbody.SetDeterministic()
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		u := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+		options := DefaultOptions(1, 2e14, test.theta).WithMAC(test.mac, 0)
+		tree := quadtree.BuildQuadTree(u, options.Build())
+
+		for _, index := range test.traced {
+			s := u.Stars[index]
+			var trace bytes.Buffer
+			force := quadtree.TraceNetForce(&trace, tree, s, index, 0, options.Walk())
+			if expected := quadtree.CalculateNetForce(tree.Root(), s, options.Walk()); force != expected {
+				t.Errorf("TestTraceNetForce(test %v, star %d) = %v, want %v as CalculateNetForce", test.id, index, force, expected)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(trace.String(), "\n"), "\n")
+			if len(lines) < 2 || !strings.HasPrefix(lines[0], fmt.Sprintf("=== force trace of star %d ", index)) {
+				t.Errorf("TestTraceNetForce(test %v, star %d) has no header: %q", test.id, index, lines[0])
+				continue
+			}
+			body, total := lines[1:len(lines)-1], lines[len(lines)-1]
+			if want := fmt.Sprintf("total force (%.4e, %.4e)", force.X, force.Y); total != want {
+				t.Errorf("TestTraceNetForce(test %v, star %d) ends with %q, want %q", test.id, index, total, want)
+			}
+
+			// the visited nodes, in the order of the walk
+			var visited []string
+			var sum, scale nbody.OrderedPair
+			for _, line := range body {
+				visited = append(visited, line[:strings.Index(line, " mass ")+len(" mass 0.0000e+00")])
+				match := traceForce.FindStringSubmatch(line)
+				if match == nil {
+					t.Errorf("TestTraceNetForce(test %v, star %d) line %q has no force", test.id, index, line)
+					continue
+				}
+				fx, err := strconv.ParseFloat(match[1], 64)
+				nbody.Check(err)
+				fy, err := strconv.ParseFloat(match[2], 64)
+				nbody.Check(err)
+				sum.X, sum.Y = sum.X+fx, sum.Y+fy
+				scale.X, scale.Y = scale.X+math.Abs(fx), scale.Y+math.Abs(fy)
+			}
+			if expected := expectedTrace(tree.Root(), s, test.theta, test.mac, 0); !reflect.DeepEqual(visited, expected) {
+				t.Errorf("TestTraceNetForce(test %v, star %d) visited %d nodes %q, want %d %q",
+					test.id, index, len(visited), visited, len(expected), expected)
+			}
+
+			// the lines print 5 significant digits
+			if math.Abs(sum.X-force.X) > 1e-4*scale.X || math.Abs(sum.Y-force.Y) > 1e-4*scale.Y {
+				t.Errorf("TestTraceNetForce(test %v, star %d) lines add up to %v, want %v", test.id, index, sum, force)
+			}
+		}
+	}
+}
+
+
+type PrintTreeTestCases struct {
+	id         string
+	width      float64
+	leafSize   int
+	generation int
+	stars      []*nbody.Star
+	expected   string
+}
+
+
+// ReadPrintTree reads test data for PrintTree from a file. Every test is a line of input followed by the lines
+// of its expected dump, whose indentation is kept.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of PrintTreeTestCases structs containing the universes and their expected dumps.
+func ReadPrintTree(fileName string) []PrintTreeTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []PrintTreeTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			// a line of the dump of the last test
+			if len(tests) > 0 {
+				tests[len(tests)-1].expected += line + "\n"
+			}
+			continue
+		}
+
+		width, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		nbody.Check(err)
+		leafSize, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+		nbody.Check(err)
+		generation, err := strconv.Atoi(strings.TrimSpace(parts[3]))
+		nbody.Check(err)
+		var stars []*nbody.Star
+		for _, field := range strings.Split(parts[4], ";") {
+			var values []float64
+			for _, v := range strings.Split(field, ",") {
+				value, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+				nbody.Check(err)
+				values = append(values, value)
+			}
+			stars = append(stars, &nbody.Star{Position: nbody.OrderedPair{X: values[0], Y: values[1]}, Mass: values[2]})
+		}
+
+		tests = append(tests, PrintTreeTestCases{
+			id:         strings.TrimSpace(parts[0]),
+			width:      width,
+			leafSize:   leafSize,
+			generation: generation,
+			stars:      stars,
+		})
+	}
+
+	return tests
+}
+
+
+// TestPrintTree tests that PrintTree writes the expected dump of small quadtrees with every tree build.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestPrintTree(t *testing.T) {
+	tests := ReadPrintTree("../Tests/PrintTree.txt")
+
+	for _, test := range tests {
+		for _, build := range []string{"serial", "partitioned", "flat", "linear"} {
+			u := &nbody.Universe{Stars: test.stars, Width: test.width}
+			options := DefaultOptions(1, 1, 0.5).WithTreeBuild(build).WithLeafSize(test.leafSize)
+
+			var dump bytes.Buffer
+			quadtree.PrintTree(&dump, quadtree.BuildQuadTree(u, options.Build()), test.generation)
+			if dump.String() != test.expected {
+				t.Errorf("TestPrintTree(test %v, %s build) =\n%s\nwant\n%s", test.id, build, dump.String(), test.expected)
+			}
+		}
+	}
+}
+
+
+type WithDebugTestCases struct {
+	id              string
+	stars           []*nbody.Star
+	removeEscaped   float64
+	trees           map[int]bool
+	traceStar       int
+	traceGeneration int
+	expected        []string
+}
+
+
+// ReadWithDebug reads test data for WithDebug from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of WithDebugTestCases structs containing the universes, the debug settings, and the expected headers.
+func ReadWithDebug(fileName string) []WithDebugTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []WithDebugTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 7 {
+			continue
+		}
+
+		var stars []*nbody.Star
+		for _, field := range strings.Split(parts[1], ";") {
+			var values []float64
+			for _, v := range strings.Split(field, ",") {
+				value, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+				nbody.Check(err)
+				values = append(values, value)
+			}
+			stars = append(stars, &nbody.Star{Position: nbody.OrderedPair{X: values[0], Y: values[1]}, Mass: values[2], Radius: 1})
+		}
+		removeEscaped, err := strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+		nbody.Check(err)
+		trees := map[int]bool{}
+		if list := strings.TrimSpace(parts[3]); list != "-" {
+			trees, err = quadtree.ParseGenerationList(list)
+			nbody.Check(err)
+		}
+		traceStar, err := strconv.Atoi(strings.TrimSpace(parts[4]))
+		nbody.Check(err)
+		traceGeneration, err := strconv.Atoi(strings.TrimSpace(parts[5]))
+		nbody.Check(err)
+		var expected []string
+		if list := strings.TrimSpace(parts[6]); list != "-" {
+			for _, header := range strings.Split(list, ";") {
+				expected = append(expected, strings.TrimSpace(header))
+			}
+		}
+
+		tests = append(tests, WithDebugTestCases{
+			id:              strings.TrimSpace(parts[0]),
+			stars:           stars,
+			removeEscaped:   removeEscaped,
+			trees:           trees,
+			traceStar:       traceStar,
+			traceGeneration: traceGeneration,
+			expected:        expected,
+		})
+	}
+
+	return tests
+}
+
+
+// debugHeader matches the header of a quadtree dump or of a force trace.
+var debugHeader = regexp.MustCompile(`^=== (?:quadtree of generation (\d+)|force trace of star (\d+) .* in generation (\d+),)`)
+
+
+// TestWithDebug tests that a simulation dumps the quadtrees and traces the star chosen with WithDebug in the
+// chosen generations, following the traced star when the stars before it are removed.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestWithDebug(t *testing.T) {
+	tests := ReadWithDebug("../Tests/WithDebug.txt")
+
+	for _, test := range tests {
+		u := &nbody.Universe{Stars: test.stars, Width: 100}
+		var dump bytes.Buffer
+		options := DefaultOptions(2, 1, 0.5).WithEventLog(io.Discard).WithDebug(&dump, test.trees, test.traceStar, test.traceGeneration)
+		options.RemoveEscaped = test.removeEscaped
+
+		sim, err := NewSimulation(u, options)
+		nbody.Check(err)
+		nbody.Check(sim.Run(2))
+
+		var headers []string
+		for _, line := range strings.Split(dump.String(), "\n") {
+			match := debugHeader.FindStringSubmatch(line)
+			switch {
+			case match == nil:
+			case match[1] != "":
+				headers = append(headers, "tree "+match[1])
+			default:
+				headers = append(headers, "trace "+match[2]+" "+match[3])
+			}
+		}
+		if !reflect.DeepEqual(headers, test.expected) {
+			t.Errorf("TestWithDebug(test %v) dumped %q, want %q", test.id, headers, test.expected)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for deterministic runs (see nbody/deterministic.go).
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestMain runs every test of the package in deterministic mode, so results are stable between runs.
+func TestMain(m *testing.M) {
+	nbody.SetDeterministic()
+	os.Exit(m.Run())
+}
+
+type DeterministicRunTestCases struct {
+	id        string
+	numStars  int
+	numGens   int
+	treeBuild string
+	workers   int
+}
+
+
+// ReadDeterministicRun reads test data for deterministic runs from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of DeterministicRunTestCases structs containing the run settings.
+func ReadDeterministicRun(fileName string) []DeterministicRunTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []DeterministicRunTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+
+		var nums [3]int
+		for i, part := range []string{parts[1], parts[2], parts[4]} {
+			nums[i], err = strconv.Atoi(strings.TrimSpace(part))
+			nbody.Check(err)
+		}
+
+		tests = append(tests, DeterministicRunTestCases{
+			id:        strings.TrimSpace(parts[0]),
+			numStars:  nums[0],
+			numGens:   nums[1],
+			treeBuild: strings.TrimSpace(parts[3]),
+			workers:   nums[2],
+		})
+	}
+
+	return tests
+}
+
+
+// DeterministicRun generates a galaxy from the deterministic seed and simulates it.
+func DeterministicRun(numStars, numGens int, build string, workers int) *nbody.Universe {
+	oldWorkers := nbody.NumWorkers
+	defer func() {
+		nbody.NumWorkers = oldWorkers
+	}()
+
+	nbody.SetDeterministic()
+	nbody.NumWorkers = workers
+
+	g := nbody.InitializeGalaxy(numStars, 4e21, 5e22, 5e22)
+	timePoints := BarnesHut(nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23), DefaultOptions(numGens, 2e14, 0.5).WithTreeBuild(build))
+
+	return timePoints[numGens]
+}
+
+
+// TestDeterministicRun checks that deterministic runs repeat exactly with every tree build and worker count.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if two runs differ.
+func TestDeterministicRun(t *testing.T) {
+	tests := ReadDeterministicRun("../Tests/DeterministicRun.txt")
+
+	for _, test := range tests {
+		want := DeterministicRun(test.numStars, test.numGens, "serial", 1)
+		got := DeterministicRun(test.numStars, test.numGens, test.treeBuild, test.workers)
+
+		for i := range want.Stars {
+			if got.Stars[i].Position != want.Stars[i].Position || got.Stars[i].Velocity != want.Stars[i].Velocity {
+				t.Errorf("TestDeterministicRun(test %v): star %d differs from the serial run", test.id, i)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,193 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Direct summation of the forces (option "-force direct"), the reference of the "compare" command.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// CheckForceMode returns an error if name is not a known force mode.
+func CheckForceMode(name string) error {
+	if name != "tree" && name != "direct" {
+		return fmt.Errorf("unknown force mode %q (use tree or direct)", name)
+	}
+	return nil
+}
+
+
+// StarAcceleration computes the acceleration of one star with the force mode of the run,
+// adding the acceleration of the external potentials of the run at the time of u and of its friction.
+// A pinned star does not move, so its acceleration is 0.
+// Input:
+//   - s: pointer to the Star.
+//   - u: pointer to the Universe containing the star.
+//   - tree: pointer to the QuadTree of u (not used by direct summation).
+//   - options: the Options of the run (force mode, tree walk, softening, force law, potentials, friction).
+// Output:
+//   - the acceleration.
+func StarAcceleration(s *nbody.Star, u *nbody.Universe, tree *quadtree.QuadTree, options Options) nbody.OrderedPair {
+	var accel nbody.OrderedPair
+	if s.Pinned {
+		return accel
+	}
+	if options.ForceMode == "direct" {
+		accel = DirectAcceleration(s, u.Stars, options.Softening, options.Law())
+	} else {
+		accel = UpdateAcceleration(s, tree, options.Walk())
+	}
+	for _, potential := range options.potentials {
+		a := potential.Acceleration(s.Position, u.Time)
+		accel.X += a.X
+		accel.Y += a.Y
+	}
+	friction := FrictionAcceleration(s, u.Stars, options.Friction)
+	accel.X += friction.X
+	accel.Y += friction.Y
+	return accel
+}
+
+
+// DirectAcceleration computes the acceleration of a star by summing the force (ComputeForce) of every other star.
+// Like UpdateAcceleration, it pulls a tracer as a star of unit mass.
+// Input:
+//   - s: pointer to the Star.
+//   - stars: all stars of the universe, which may include s.
+//   - softening: softening length of the run.
+//   - law: the ForceLaw of the run.
+// Output:
+//   - the acceleration.
+func DirectAcceleration(s *nbody.Star, stars []*nbody.Star, softening float64, law nbody.ForceLaw) nbody.OrderedPair {
+	var force nbody.OrderedPair
+	pulled := s
+	if nbody.IsTracer(s) {
+		pulled = nbody.UnitProbe(s)
+	}
+	for _, other := range stars {
+		if other == s {
+			continue
+		}
+		f := nbody.ComputeForce(other, pulled, softening, law)
+		force.X += f.X
+		force.Y += f.Y
+	}
+	return nbody.OrderedPair{X: force.X / pulled.Mass, Y: force.Y / pulled.Mass}
+}
+
+
+// DirectAccelerations computes the acceleration of every star by direct summation, on the goroutines of the run,
+// over StarArrays with the layout "soa". It is the O(N^2) reference the tree is compared with.
+// Input:
+//   - u: pointer to the Universe.
+//   - options: the Options of the run (softening, force law, workers, and layout).
+// Output:
+//   - the acceleration of every star, in the order of u.Stars.
+func DirectAccelerations(u *nbody.Universe, options Options) []nbody.OrderedPair {
+	softening, law := options.Softening, options.Law()
+	if options.Layout == "soa" {
+		return NewStarArrays(u.Stars, softening).DirectAccelerations(options.Workers(), law)
+	}
+	stars := u.Stars
+	accelerations := make([]nbody.OrderedPair, len(stars))
+	numChunks := (len(stars) + forceChunk - 1) / forceChunk
+
+	nbody.ParallelFor(numChunks, options.Workers(), func(c int) {
+		for i := c * forceChunk; i < (c+1)*forceChunk && i < len(stars); i++ {
+			accelerations[i] = DirectAcceleration(stars[i], stars, softening, law)
+		}
+	})
+
+	return accelerations
+}
+
+
+// ForceErrors are the relative errors |a_tree - a_direct| / |a_direct| of the tree accelerations of a universe.
+type ForceErrors struct {
+	Theta    float64
+	max      float64
+	Mean     float64
+	TreeTime time.Duration // time of the tree build and walk for all stars
+}
+
+
+// CompareForces measures the relative error of the tree accelerations of every star against direct summation.
+// Stars without direct acceleration (a lone star) are skipped.
+// Input:
+//   - u: pointer to the Universe.
+//   - direct: accelerations of u by direct summation (DirectAccelerations).
+//   - options: the Options of the tree (theta, softening, tree build, and opening criterion).
+// Output:
+//   - the ForceErrors.
+func CompareForces(u *nbody.Universe, direct []nbody.OrderedPair, options Options) ForceErrors {
+	result := ForceErrors{Theta: options.Theta}
+
+	start := time.Now()
+	tree := quadtree.BuildQuadTree(u, options.Build())
+	walk := options.Walk()
+	accelerations := make([]nbody.OrderedPair, len(u.Stars))
+	numChunks := (len(u.Stars) + forceChunk - 1) / forceChunk
+	nbody.ParallelFor(numChunks, options.Workers(), func(c int) {
+		for i := c * forceChunk; i < (c+1)*forceChunk && i < len(u.Stars); i++ {
+			accelerations[i] = UpdateAcceleration(u.Stars[i], tree, walk)
+		}
+	})
+	result.TreeTime = time.Since(start)
+
+	count := 0
+	for i, a := range accelerations {
+		scale := math.Hypot(direct[i].X, direct[i].Y)
+		if scale == 0 {
+			continue
+		}
+		e := math.Hypot(a.X-direct[i].X, a.Y-direct[i].Y) / scale
+		result.max = math.Max(result.max, e)
+		result.Mean += e
+		count++
+	}
+	if count > 0 {
+		result.Mean /= float64(count)
+	}
+
+	return result
+}
+
+
+// PickTheta returns the result of the largest theta whose mean relative error is at most maxError, which gives
+// the fastest forces of that accuracy, and false if there is none.
+func PickTheta(results []ForceErrors, maxError float64) (ForceErrors, bool) {
+	var best ForceErrors
+	found := false
+	for _, r := range results {
+		if r.Mean <= maxError && (!found || r.Theta > best.Theta) {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
+
+
+// Speedup returns how many times faster the tree forces were than direct summation (0 if they took no time).
+func Speedup(directTime, treeTime time.Duration) float64 {
+	if treeTime <= 0 {
+		return 0
+	}
+	return float64(directTime) / float64(treeTime)
+}
+
+
+// PrintForceErrors prints one line per theta with the maximum and mean relative force error, the time of the
+// tree forces, and their speedup over direct summation, followed by the time of direct summation.
+func PrintForceErrors(w io.Writer, results []ForceErrors, directTime time.Duration) {
+	fmt.Fprintf(w, "%8s %14s %14s %12s %10s\n", "theta", "max error", "mean error", "time", "speedup")
+	for _, r := range results {
+		fmt.Fprintf(w, "%8g %14.3e %14.3e %12v %9.1fx\n", r.Theta, r.max, r.Mean, r.TreeTime.Round(time.Microsecond),
+			Speedup(directTime, r.TreeTime))
+	}
+	fmt.Fprintf(w, "%8s %14s %14s %12v\n", "direct", "", "", directTime.Round(time.Microsecond))
+}
@@ -0,0 +1,230 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for direct summation in direct.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"bufio"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type DirectAccelerationsTestCases struct {
+	id        string
+	numStars  int
+	softening float64
+}
+
+
+// ReadDirectAccelerations reads test data for DirectAccelerations from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of DirectAccelerationsTestCases structs containing the number of stars and the softening.
+func ReadDirectAccelerations(fileName string) []DirectAccelerationsTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []DirectAccelerationsTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 3 {
+			continue
+		}
+
+		numStars, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		softening, err := strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+		nbody.Check(err)
+
+		tests = append(tests, DirectAccelerationsTestCases{
+			id:        strings.TrimSpace(parts[0]),
+			numStars:  numStars,
+			softening: softening,
+		})
+	}
+
+	return tests
+}
+
+
+// TestDirectAccelerations tests that direct summation matches the tree walk with theta 0.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestDirectAccelerations(t *testing.T) {
+	tests := ReadDirectAccelerations("../Tests/DirectAccelerations.txt")
+
+	for _, test := range tests {
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		u := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+
+		options := DefaultOptions(1, 2e14, 0)
+		options.Softening = test.softening
+		expected, err := ComputeAccelerations(u, quadtree.BuildQuadTree(u, options.Build()), options)
+		nbody.Check(err)
+		result := DirectAccelerations(u, options)
+
+		for i := range result {
+			scale := math.Hypot(expected[i].X, expected[i].Y)
+			if math.Hypot(result[i].X-expected[i].X, result[i].Y-expected[i].Y) > 1e-9*scale {
+				t.Errorf("TestDirectAccelerations(test %v, star %d) = %v, want %v", test.id, i, result[i], expected[i])
+			}
+		}
+	}
+}
+
+
+type CompareForcesTestCases struct {
+	id       string
+	numStars int
+	theta    float64
+	maxError float64
+	mean     float64
+}
+
+
+// ReadCompareForces reads test data for CompareForces from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of CompareForcesTestCases structs containing the number of stars, theta, and the largest errors.
+func ReadCompareForces(fileName string) []CompareForcesTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []CompareForcesTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+
+		numStars, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		var nums [3]float64
+		for i, part := range parts[2:] {
+			nums[i], err = strconv.ParseFloat(strings.TrimSpace(part), 64)
+			nbody.Check(err)
+		}
+
+		tests = append(tests, CompareForcesTestCases{
+			id:       strings.TrimSpace(parts[0]),
+			numStars: numStars,
+			theta:    nums[0],
+			maxError: nums[1],
+			mean:     nums[2],
+		})
+	}
+
+	return tests
+}
+
+
+// TestCompareForces tests that the tree forces of galaxies are within the expected error of direct summation.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if the errors are too large.
+func TestCompareForces(t *testing.T) {
+	tests := ReadCompareForces("../Tests/CompareForces.txt")
+
+	for _, test := range tests {
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		u := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+
+		options := DefaultOptions(1, 2e14, test.theta)
+		result := CompareForces(u, DirectAccelerations(u, options), options)
+		if result.max > test.maxError || result.Mean > test.mean {
+			t.Errorf("TestCompareForces(test %v) = max %v, mean %v, want at most %v, %v", test.id, result.max, result.Mean, test.maxError, test.mean)
+		}
+	}
+}
+
+
+type PickThetaTestCases struct {
+	id       string
+	results  []ForceErrors
+	maxError float64
+	expected float64 // theta, -1 for none
+}
+
+
+// ReadPickTheta reads test data for PickTheta from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of PickThetaTestCases structs containing the force errors and the expected theta.
+func ReadPickTheta(fileName string) []PickThetaTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []PickThetaTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		test := PickThetaTestCases{id: strings.TrimSpace(parts[0]), expected: -1}
+		for _, pair := range strings.Split(strings.TrimSpace(parts[1]), ",") {
+			theta, mean, _ := strings.Cut(pair, ":")
+			var r ForceErrors
+			r.Theta, err = strconv.ParseFloat(theta, 64)
+			nbody.Check(err)
+			r.Mean, err = strconv.ParseFloat(mean, 64)
+			nbody.Check(err)
+			test.results = append(test.results, r)
+		}
+		test.maxError, err = strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+		nbody.Check(err)
+		if expected := strings.TrimSpace(parts[3]); expected != "none" {
+			test.expected, err = strconv.ParseFloat(expected, 64)
+			nbody.Check(err)
+		}
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestPickTheta tests that PickTheta recommends the largest theta that is accurate enough.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestPickTheta(t *testing.T) {
+	tests := ReadPickTheta("../Tests/PickTheta.txt")
+
+	for _, test := range tests {
+		best, ok := PickTheta(test.results, test.maxError)
+		switch {
+		case test.expected < 0 && ok:
+			t.Errorf("PickTheta(test %v) = %v, want none", test.id, best.Theta)
+		case test.expected >= 0 && (!ok || best.Theta != test.expected):
+			t.Errorf("PickTheta(test %v) = %v (found %v), want %v", test.id, best.Theta, ok, test.expected)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Energy of a universe with the potential from the quadtree, and the energy time series of a run
+// (option "-energy-log").
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"fmt"
+	"io"
+	"math"
+)
+
+// TreePotential computes the gravitational potential energy of a star in the field of a quadtree:
+// the sum of -G m m_j / sqrt(d^2 + eps^2) over the stars of the leaves and the accepted nodes of the force walk,
+// with the same opening criterion and softening as the forces, so it costs O(log N) per star.
+// Input:
+//   - node: pointer to the current Node in the QuadTree.
+//   - s: pointer to the Star; it has no energy with itself.
+//   - walk: the WalkOptions of the run (theta, softening, and opening criterion).
+// Output:
+//   - the potential energy of s with the stars of the subtree, in joules.
+func TreePotential(node *quadtree.Node, s *nbody.Star, walk quadtree.WalkOptions) float64 {
+	softening := walk.Softening
+	if node == nil || node.Star == nil || node.Star.Mass == 0 || node.Star == s {
+		return 0
+	}
+
+	_, _, d := nbody.Distance(node.Star.Position, s.Position)
+	if !quadtree.IsLeaf(node) || node.Bucket != nil {
+		if d != 0 && quadtree.AcceptNode(node, d, walk) {
+			eps := nbody.PairSoftening(s, node.Star, softening)
+			return -nbody.G * s.Mass * node.Star.Mass / math.Sqrt(d*d+eps*eps)
+		}
+	}
+
+	energy := 0.0
+	if quadtree.IsLeaf(node) {
+		for _, other := range quadtree.LeafStars(node) {
+			_, _, d := nbody.Distance(other.Position, s.Position)
+			if eps := nbody.PairSoftening(s, other, softening); other != s && (d != 0 || eps != 0) {
+				energy -= nbody.G * s.Mass * other.Mass / math.Sqrt(d*d+eps*eps)
+			}
+		}
+		return energy
+	}
+
+	for _, child := range node.Children {
+		energy += TreePotential(child, s, walk)
+	}
+	return energy
+}
+
+
+// TreePotentialEnergy computes the total potential energy of a universe in O(N log N) with its quadtree,
+// as half the sum of the TreePotential of every star (every pair is counted from both sides).
+// Stars outside the universe are not in the tree; their pairs are summed directly, so with theta 0
+// the result is the softened direct sum. Tracers have no potential energy.
+// Input:
+//   - u: pointer to the Universe.
+//   - tree: pointer to the QuadTree of u.
+//   - walk: the WalkOptions of the run (theta, softening, and opening criterion).
+// Output:
+//   - the total potential energy in joules.
+func TreePotentialEnergy(u *nbody.Universe, tree *quadtree.QuadTree, walk quadtree.WalkOptions) float64 {
+	stars := u.Stars
+	softening := walk.Softening
+	numChunks := (len(stars) + forceChunk - 1) / forceChunk
+	root := tree.Root()
+	width, height := u.Bounds()
+
+	// chunks are summed in order, so the result does not depend on the number of workers
+	return nbody.ParallelSum(numChunks, nbody.NumWorkers, func(c int) float64 {
+		energy := 0.0
+		for i := c * forceChunk; i < (c+1)*forceChunk && i < len(stars); i++ {
+			s := stars[i]
+			if nbody.IsTracer(s) {
+				continue
+			}
+			if nbody.IsInsideUniverse(s, width, height) {
+				energy += 0.5 * TreePotential(root, s, walk)
+				continue
+			}
+			// pairs of an outside star with a star in the tree, or with a later outside star
+			for j, other := range stars {
+				if j == i || nbody.IsTracer(other) || (!nbody.IsInsideUniverse(other, width, height) && j < i) {
+					continue
+				}
+				_, _, d := nbody.Distance(s.Position, other.Position)
+				eps := nbody.PairSoftening(s, other, softening)
+				if d != 0 || eps != 0 {
+					energy -= nbody.G * s.Mass * other.Mass / math.Sqrt(d*d+eps*eps)
+				}
+			}
+		}
+		return energy
+	})
+}
+
+
+// EnergyRecord is the energy of one generation of a run.
+type EnergyRecord struct {
+	generation int
+	time       float64
+	kinetic    float64
+	potential  float64
+}
+
+
+// Total returns the total energy of the record.
+func (r EnergyRecord) Total() float64 {
+	return r.kinetic + r.potential
+}
+
+
+// EnergyDrift returns the relative drift (E_last - E_first) / |E_first| of the total energy of a time series,
+// or 0 if it has fewer than two records or starts with zero energy.
+func EnergyDrift(records []EnergyRecord) float64 {
+	if len(records) < 2 || records[0].Total() == 0 {
+		return 0
+	}
+	first, last := records[0].Total(), records[len(records)-1].Total()
+	return (last - first) / math.Abs(first)
+}
+
+
+// EnergyRecorder returns a hook for Options.WithHook that records the energy of every interval-th generation,
+// of the first generation, and of the last one (numGens), with the potential from a quadtree of the generation
+// and the external potentials of the run.
+// Input:
+//   - interval: generations between two records.
+//   - numGens: the last generation of the run.
+//   - options: the Options of the run (theta, softening, and external potentials).
+//   - records: slice receiving the records in the order of the generations.
+// Output:
+//   - the hook.
+func EnergyRecorder(interval, numGens int, options Options, records *[]EnergyRecord) func(generation int, u *nbody.Universe) {
+	return func(generation int, u *nbody.Universe) {
+		if len(*records) > 0 && generation%interval != 0 && generation != numGens {
+			return
+		}
+		tree := quadtree.BuildQuadTree(u, options.Build())
+		*records = append(*records, EnergyRecord{
+			generation: generation,
+			time:       u.Time,
+			kinetic:    nbody.KineticEnergy(u),
+			potential:  TreePotentialEnergy(u, tree, options.Walk()) + ExternalPotentialEnergy(u, options.potentials),
+		})
+	}
+}
+
+
+// WriteEnergySeries writes an energy time series, one line per record, and the relative drift of the total energy.
+// Input:
+//   - w: destination of the series.
+//   - records: the records of a run (see EnergyRecorder).
+// Output:
+//   - an error if writing failed.
+func WriteEnergySeries(w io.Writer, records []EnergyRecord) error {
+	fmt.Fprintln(w, "# generation time kinetic potential total")
+	for _, r := range records {
+		fmt.Fprintf(w, "%d %e %e %e %e\n", r.generation, r.time, r.kinetic, r.potential, r.Total())
+	}
+	_, err := fmt.Fprintf(w, "# relative energy drift: %e\n", EnergyDrift(records))
+	return err
+}
@@ -0,0 +1,182 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the energy diagnostics in energy.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"bufio"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type TreePotentialEnergyTestCases struct {
+	id        string
+	numStars  int
+	theta     float64
+	softening float64
+	outside   int
+	tolerance float64
+}
+
+
+// ReadTreePotentialEnergy reads test data for TreePotentialEnergy from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of TreePotentialEnergyTestCases structs containing the galaxy, theta, softening, and tolerance.
+func ReadTreePotentialEnergy(fileName string) []TreePotentialEnergyTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []TreePotentialEnergyTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 6 {
+			continue
+		}
+
+		numStars, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		theta, err := strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+		nbody.Check(err)
+		softening, err := strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
+		nbody.Check(err)
+		outside, err := strconv.Atoi(strings.TrimSpace(parts[4]))
+		nbody.Check(err)
+		tolerance, err := strconv.ParseFloat(strings.TrimSpace(parts[5]), 64)
+		nbody.Check(err)
+
+		tests = append(tests, TreePotentialEnergyTestCases{
+			id:        strings.TrimSpace(parts[0]),
+			numStars:  numStars,
+			theta:     theta,
+			softening: softening,
+			outside:   outside,
+			tolerance: tolerance,
+		})
+	}
+
+	return tests
+}
+
+
+// TestTreePotentialEnergy tests the potential energy from the quadtree against the softened direct sum.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestTreePotentialEnergy(t *testing.T) {
+	tests := ReadTreePotentialEnergy("../Tests/TreePotentialEnergy.txt")
+
+	for _, test := range tests {
+		// InitializeGalaxy adds a black hole, so the galaxy has numStars - 1 other stars
+		g := nbody.InitializeGalaxy(test.numStars-1, 4e21, 5e22, 5e22)
+		u := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+		for i := 0; i < test.outside; i++ {
+			u.Stars[i+1].Position.X += 1e23
+		}
+
+		expected := nbody.SoftenedPotentialEnergy(u, test.softening)
+		options := DefaultOptions(1, 1, test.theta).WithSoftening(test.softening)
+		result := TreePotentialEnergy(u, quadtree.BuildQuadTree(u, options.Build()), options.Walk())
+
+		if math.Abs(result-expected) > test.tolerance*math.Abs(expected) {
+			t.Errorf("TestTreePotentialEnergy(test %v) = %e, want %e within %v", test.id, result, expected, test.tolerance)
+		}
+	}
+}
+
+
+type EnergyRecorderTestCases struct {
+	id          string
+	numGens     int
+	interval    int
+	generations []int
+}
+
+
+// ReadEnergyRecorder reads test data for EnergyRecorder from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of EnergyRecorderTestCases structs containing the run, the interval, and the expected generations.
+func ReadEnergyRecorder(fileName string) []EnergyRecorderTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []EnergyRecorderTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		numGens, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		interval, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+		nbody.Check(err)
+		var generations []int
+		for _, field := range strings.Split(parts[3], ",") {
+			g, err := strconv.Atoi(strings.TrimSpace(field))
+			nbody.Check(err)
+			generations = append(generations, g)
+		}
+
+		tests = append(tests, EnergyRecorderTestCases{
+			id:          strings.TrimSpace(parts[0]),
+			numGens:     numGens,
+			interval:    interval,
+			generations: generations,
+		})
+	}
+
+	return tests
+}
+
+
+// TestEnergyRecorder tests that a run records the energy of the expected generations and that the
+// energy of a softened galaxy drifts little.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestEnergyRecorder(t *testing.T) {
+	tests := ReadEnergyRecorder("../Tests/EnergyRecorder.txt")
+
+	for _, test := range tests {
+		g := nbody.InitializeGalaxy(50, 4e21, 5e22, 5e22)
+		u := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+		options := DefaultOptions(test.numGens, 2e14, 0.5)
+		options.Softening = 1e19
+
+		var records []EnergyRecord
+		nbody.Check(RunGenerations(u, options.WithHook(EnergyRecorder(test.interval, test.numGens, options, &records))))
+
+		var generations []int
+		for _, r := range records {
+			generations = append(generations, r.generation)
+		}
+		if !reflect.DeepEqual(generations, test.generations) {
+			t.Errorf("TestEnergyRecorder(test %v) recorded generations %v, want %v", test.id, generations, test.generations)
+		}
+		if drift := EnergyDrift(records); math.Abs(drift) > 1e-3 {
+			t.Errorf("TestEnergyRecorder(test %v) energy drift = %e, want below 1e-3", test.id, drift)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Scripted events that change a universe at given generations (option "-events").
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// An events file has one event per line, applied right after the given generation is computed:
+//
+//	# generation action arguments... (SI units)
+//	5000 add-star x y vx vy mass radius
+//	8000 add-galaxy numStars radius x y vx vy
+//	6000 supernova index massFraction kickSpeed
+//	1000 supernovae minMass probability massFraction kickSpeed
+//
+// A supernova removes massFraction of a star's mass and kicks it with kickSpeed in a random direction.
+// "supernovae" is stochastic and recurring: from its generation on, every star of at least minMass
+// explodes with the given probability in every generation.
+// Lines starting with "#" and empty lines are ignored.
+
+// Event is one scripted change of the universe.
+type Event struct {
+	generation int
+	action     string
+	args       []float64
+}
+
+// eventArgs is the number of arguments of every action.
+var eventArgs = map[string]int{
+	"add-star":   6,
+	"add-galaxy": 6,
+	"supernova":  3,
+	"supernovae": 4,
+}
+
+// recurringEvents are applied in every generation from their own generation on.
+var recurringEvents = map[string]bool{
+	"supernovae": true,
+}
+
+
+// ParseEvent parses one line of an events file.
+// Input:
+//   - line: the line, without comments.
+// Output:
+//   - the Event, or an error describing what is wrong with the line.
+func ParseEvent(line string) (Event, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return Event{}, fmt.Errorf("event %q needs a generation and an action", line)
+	}
+
+	generation, err := strconv.Atoi(fields[0])
+	if err != nil || generation < 1 {
+		return Event{}, fmt.Errorf("event %q: generation must be a positive integer", line)
+	}
+
+	e := Event{generation: generation, action: fields[1]}
+	want, ok := eventArgs[e.action]
+	if !ok {
+		return Event{}, fmt.Errorf("event %q: unknown action %q", line, e.action)
+	}
+	if len(fields)-2 != want {
+		return Event{}, fmt.Errorf("event %q: %s takes %d arguments, got %d", line, e.action, want, len(fields)-2)
+	}
+
+	for _, field := range fields[2:] {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return Event{}, fmt.Errorf("event %q: %w", line, err)
+		}
+		e.args = append(e.args, v)
+	}
+
+	// the mass fraction of both supernova actions must leave some mass
+	if e.action == "supernova" || e.action == "supernovae" {
+		fraction := e.args[len(e.args)-2]
+		if fraction < 0 || fraction >= 1 {
+			return Event{}, fmt.Errorf("event %q: mass fraction must be in [0, 1), got %v", line, fraction)
+		}
+	}
+	return e, nil
+}
+
+
+// ReadEvents reads an events file, sorted by generation (events of one generation keep their order).
+// Input:
+//   - fileName: path of the events file.
+// Output:
+//   - slice of Events, or the first error.
+func ReadEvents(fileName string) ([]Event, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		e, err := ParseEvent(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", fileName, lineNumber, err)
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(events, func(a, b int) bool {
+		return events[a].generation < events[b].generation
+	})
+	return events, nil
+}
+
+
+// ApplyEvent changes the universe as described by the event and logs what it did.
+// Input:
+//   - u: pointer to the Universe of the event's generation.
+//   - e: the Event.
+//   - generation: the current generation (e.generation, or later for recurring events).
+//   - log: receives one line per applied event (see Options.WithEventLog).
+// Output:
+//   - None (the universe is modified in place).
+func ApplyEvent(u *nbody.Universe, e Event, generation int, log io.Writer) {
+	a := e.args
+
+	switch e.action {
+	case "add-star":
+		s := nbody.NewStar(nbody.OrderedPair{X: a[0], Y: a[1]}, nbody.OrderedPair{X: a[2], Y: a[3]}, a[4], a[5])
+		indices := u.AddStars(s)
+		fmt.Fprintf(log, "generation %d: added star %d at (%.3e, %.3e)\n", generation, indices[0], a[0], a[1])
+
+	case "add-galaxy":
+		g := nbody.InitializeGalaxy(int(a[0]), a[1], a[2], a[3])
+		for _, s := range g {
+			s.Velocity.X += a[4]
+			s.Velocity.Y += a[5]
+		}
+		indices := u.AddGalaxy(g)
+		fmt.Fprintf(log, "generation %d: added galaxy %d of %d stars (indices %d-%d) at (%.3e, %.3e)\n",
+			generation, g[0].Galaxy, len(g), indices[0], indices[len(indices)-1], a[2], a[3])
+
+	case "supernova":
+		index := int(a[0])
+		if index < 0 || index >= len(u.Stars) {
+			fmt.Fprintf(log, "generation %d: no star %d for the supernova, skipped\n", generation, index)
+			return
+		}
+		Supernova(u.Stars[index], index, a[1], a[2], generation, log)
+
+	case "supernovae":
+		for i, s := range u.Stars {
+			if s.Mass >= a[0] && nbody.RNG.Float64() < a[1] {
+				Supernova(s, i, a[2], a[3], generation, log)
+			}
+		}
+	}
+}
+
+
+// Supernova makes a star lose a fraction of its mass and kicks it in a random direction.
+// Input:
+//   - s: pointer to the exploding Star.
+//   - index: index of s, for the log.
+//   - fraction: fraction of the mass that is lost.
+//   - kick: speed added to the star, in m/s.
+//   - generation: the current generation, for the log.
+//   - log: receives the line of the supernova.
+// Output:
+//   - None (the star is modified in place).
+func Supernova(s *nbody.Star, index int, fraction, kick float64, generation int, log io.Writer) {
+	oldMass := s.Mass
+	s.Mass *= 1 - fraction
+
+	angle := nbody.RNG.Float64() * 2 * math.Pi
+	s.Velocity.X += kick * math.Cos(angle)
+	s.Velocity.Y += kick * math.Sin(angle)
+
+	fmt.Fprintf(log, "generation %d: supernova of star %d, mass %.3e -> %.3e kg, kick %.3e m/s at %.0f degrees\n",
+		generation, index, oldMass, s.Mass, kick, angle*180/math.Pi)
+}
@@ -0,0 +1,99 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the scripted events in events.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type ApplyEventTestCases struct {
+	id       string
+	line     string
+	expected int     // number of stars after the event, or -1 if the line is invalid
+	mass     float64 // mass of star 0 after the event
+}
+
+
+// ReadApplyEvent reads test data for the ParseEvent and ApplyEvent functions from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of ApplyEventTestCases structs containing event lines, expected star counts, and expected masses.
+func ReadApplyEvent(fileName string) []ApplyEventTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []ApplyEventTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		expected := -1
+		if field := strings.TrimSpace(parts[2]); field != "error" {
+			expected, err = strconv.Atoi(field)
+			nbody.Check(err)
+		}
+
+		mass, err := strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
+		nbody.Check(err)
+
+		tests = append(tests, ApplyEventTestCases{
+			id:       strings.TrimSpace(parts[0]),
+			line:     strings.TrimSpace(parts[1]),
+			expected: expected,
+			mass:     mass,
+		})
+	}
+
+	return tests
+}
+
+
+// TestApplyEvent tests parsing event lines and applying them to a universe holding one star.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestApplyEvent(t *testing.T) {
+	tests := ReadApplyEvent("../Tests/ApplyEvent.txt")
+
+	for _, test := range tests {
+		e, err := ParseEvent(test.line)
+		if test.expected < 0 {
+			if err == nil {
+				t.Errorf("TestApplyEvent(test %v): ParseEvent(%q) succeeded, want an error", test.id, test.line)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestApplyEvent(test %v): ParseEvent(%q) failed: %v", test.id, test.line, err)
+			continue
+		}
+
+		u := nbody.NewUniverse(1e23)
+		u.AddStars(nbody.NewStar(nbody.OrderedPair{}, nbody.OrderedPair{}, 2e30, 1))
+		ApplyEvent(u, e, e.generation, io.Discard)
+		if len(u.Stars) != test.expected {
+			t.Errorf("TestApplyEvent(test %v) = %d stars, want %d", test.id, len(u.Stars), test.expected)
+		}
+		if math.Abs(u.Stars[0].Mass-test.mass) > 1e-9*test.mass {
+			t.Errorf("TestApplyEvent(test %v): star 0 has mass %v, want %v", test.id, u.Stars[0].Mass, test.mass)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Guarding a run against non-finite positions, velocities, and accelerations (option "-finite-check").
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"fmt"
+)
+
+// GuardAccelerations applies options.FiniteCheck to the new accelerations of the stars of a universe before the
+// stars move with them.
+// Input:
+//   - u: pointer to the Universe.
+//   - accelerations: new acceleration of every star, in the order of u.Stars.
+//   - options: the Options of the run; options.clamped, if not nil, is called with every clamped star.
+// Output:
+//   - with "abort", a NumericalError naming the first star with a non-finite acceleration, and otherwise nil
+//     (with "clamp", such accelerations are set to zero).
+func GuardAccelerations(u *nbody.Universe, accelerations []nbody.OrderedPair, options Options) error {
+	if options.FiniteCheck == "off" {
+		return nil
+	}
+
+	for i, a := range accelerations {
+		if nbody.IsFinite(a.X) && nbody.IsFinite(a.Y) {
+			continue
+		}
+		s := u.Stars[i]
+		if options.FiniteCheck != "clamp" {
+			return nbody.NumericalError{Err: fmt.Errorf("star %d (ID %d) at %v has the acceleration %v (try -softening, a smaller -dt, or -finite-check clamp)",
+				i, s.ID, s.Position, a)}
+		}
+		accelerations[i] = nbody.OrderedPair{}
+		if options.clamped != nil {
+			options.clamped(s, a)
+		}
+	}
+	return nil
+}
+
+
+// RemoveNonFinite removes the stars of a universe whose position, velocity, or acceleration is not finite, and
+// writes a warning for every one of them to WarningOutput.
+// Input:
+//   - u: pointer to the Universe.
+//   - generation: the generation of u, for the warnings.
+// Output:
+//   - the new index of every star as returned by RemoveStars, or nil if no star was removed.
+func RemoveNonFinite(u *nbody.Universe, generation int) []int {
+	removed := false
+	newIndex := u.RemoveStars(func(i int, s *nbody.Star) bool {
+		if nbody.FiniteStar(s) {
+			return false
+		}
+		fmt.Fprintf(WarningOutput, "Warning: generation %d: removed star %d (ID %d) with position %v, velocity %v, and acceleration %v\n",
+			generation, i, s.ID, s.Position, s.Velocity, s.Acceleration)
+		removed = true
+		return true
+	})
+	if !removed {
+		return nil
+	}
+	return newIndex
+}
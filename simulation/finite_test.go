@@ -0,0 +1,246 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the checks of non-finite values in finite.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type GuardAccelerationsTestCases struct {
+	id            string
+	finiteCheck   string
+	accelerations []nbody.OrderedPair
+	expectedStar  int
+	expected      []nbody.OrderedPair
+	clamped       []int64
+}
+
+
+// parsePairs parses pairs x,y separated by ";".
+func parsePairs(text string) []nbody.OrderedPair {
+	var pairs []nbody.OrderedPair
+	for _, pair := range strings.Split(text, ";") {
+		fields := strings.Split(pair, ",")
+		x, err := strconv.ParseFloat(strings.TrimSpace(fields[0]), 64)
+		nbody.Check(err)
+		y, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+		nbody.Check(err)
+		pairs = append(pairs, nbody.OrderedPair{X: x, Y: y})
+	}
+	return pairs
+}
+
+
+// ReadGuardAccelerations reads test data for the GuardAccelerations function from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of GuardAccelerationsTestCases structs containing the accelerations and the expected outcome.
+func ReadGuardAccelerations(fileName string) []GuardAccelerationsTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []GuardAccelerationsTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 6 {
+			continue
+		}
+
+		expectedStar, err := strconv.Atoi(strings.TrimSpace(parts[3]))
+		nbody.Check(err)
+
+		tests = append(tests, GuardAccelerationsTestCases{
+			id:            strings.TrimSpace(parts[0]),
+			finiteCheck:   strings.TrimSpace(parts[1]),
+			accelerations: parsePairs(parts[2]),
+			expectedStar:  expectedStar,
+			expected:      parsePairs(parts[4]),
+			clamped:       parseIDList(parts[5]),
+		})
+	}
+
+	return tests
+}
+
+
+// TestGuardAccelerations tests that GuardAccelerations names the first non-finite acceleration with "abort",
+// zeroes and reports such accelerations with "clamp", and leaves them with "off".
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestGuardAccelerations(t *testing.T) {
+	tests := ReadGuardAccelerations("../Tests/GuardAccelerations.txt")
+
+	for _, test := range tests {
+		u := &nbody.Universe{Width: 16}
+		for i := range test.accelerations {
+			u.Stars = append(u.Stars, &nbody.Star{ID: int64(10 + i), Mass: 1})
+		}
+		options := DefaultOptions(1, 1, 0.5)
+		options.FiniteCheck = test.finiteCheck
+		var clamped []int64
+		options.clamped = func(s *nbody.Star, acceleration nbody.OrderedPair) {
+			clamped = append(clamped, s.ID)
+		}
+
+		err := GuardAccelerations(u, test.accelerations, options)
+
+		var numericalErr nbody.NumericalError
+		if test.expectedStar < 0 && err != nil {
+			t.Errorf("TestGuardAccelerations(test %v) = %v, want nil", test.id, err)
+		}
+		want := fmt.Sprintf("star %d (ID %d) ", test.expectedStar, 10+test.expectedStar)
+		if test.expectedStar >= 0 && (!errors.As(err, &numericalErr) || !strings.HasPrefix(err.Error(), want)) {
+			t.Errorf("TestGuardAccelerations(test %v) = %v, want a NumericalError starting with %q", test.id, err, want)
+		}
+		// NaN is not equal to itself, so the accelerations are compared as text
+		if fmt.Sprint(test.accelerations) != fmt.Sprint(test.expected) {
+			t.Errorf("TestGuardAccelerations(test %v) accelerations = %v, want %v", test.id, test.accelerations, test.expected)
+		}
+		if !reflect.DeepEqual(clamped, test.clamped) {
+			t.Errorf("TestGuardAccelerations(test %v) clamped %v, want %v", test.id, clamped, test.clamped)
+		}
+	}
+}
+
+
+type FiniteCheckTestCases struct {
+	id          string
+	finiteCheck string
+	softening   float64
+	universe    *nbody.Universe
+	expectError bool
+	numStars    int
+	numFinite   int
+}
+
+
+// ReadFiniteCheck reads test data for the finite checks of Simulation.Step from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of FiniteCheckTestCases structs containing the universes and the expected outcome of a step.
+func ReadFiniteCheck(fileName string) []FiniteCheckTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []FiniteCheckTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+
+		softening, err := strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+		nbody.Check(err)
+
+		u := &nbody.Universe{Width: 16}
+		for _, star := range strings.Split(parts[3], ",") {
+			fields := strings.Fields(star)
+			var nums [5]float64
+			for i := range nums {
+				nums[i], err = strconv.ParseFloat(fields[i], 64)
+				nbody.Check(err)
+			}
+			u.Stars = append(u.Stars, &nbody.Star{Position: nbody.OrderedPair{X: nums[0], Y: nums[1]}, Velocity: nbody.OrderedPair{X: nums[2], Y: nums[3]},
+				Mass: nums[4], Radius: 1})
+		}
+
+		test := FiniteCheckTestCases{
+			id:          strings.TrimSpace(parts[0]),
+			finiteCheck: strings.TrimSpace(parts[1]),
+			softening:   softening,
+			universe:    u,
+		}
+		if expected := strings.TrimSpace(parts[4]); expected == "error" {
+			test.expectError = true
+		} else {
+			numStars, numFinite, _ := strings.Cut(expected, "/")
+			test.numStars, err = strconv.Atoi(numStars)
+			nbody.Check(err)
+			test.numFinite, err = strconv.Atoi(numFinite)
+			nbody.Check(err)
+		}
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestFiniteCheck tests that a step stops at a non-finite force or star with "abort", keeps the stars with
+// non-finite forces and removes the others with "clamp", and checks nothing with "off".
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestFiniteCheck(t *testing.T) {
+	tests := ReadFiniteCheck("../Tests/FiniteCheck.txt")
+	WarningOutput = io.Discard
+	defer func() { WarningOutput = os.Stderr }()
+
+	for _, test := range tests {
+		options := DefaultOptions(1, 1, 0.5)
+		options.FiniteCheck = test.finiteCheck
+		options.Softening = test.softening
+		sim, err := NewSimulation(test.universe, options)
+		nbody.Check(err)
+
+		err = sim.Step()
+
+		if test.expectError {
+			var numericalErr nbody.NumericalError
+			if !errors.As(err, &numericalErr) || !strings.HasPrefix(err.Error(), "generation 1: star ") {
+				t.Errorf("TestFiniteCheck(test %v) = %v, want a NumericalError of generation 1", test.id, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestFiniteCheck(test %v) = %v, want nil", test.id, err)
+			continue
+		}
+
+		numFinite := len(sim.Universe.Select(nbody.FiniteStar))
+		if len(sim.Universe.Stars) != test.numStars || numFinite != test.numFinite {
+			t.Errorf("TestFiniteCheck(test %v) has %d stars, %d of them finite, want %d and %d",
+				test.id, len(sim.Universe.Stars), numFinite, test.numStars, test.numFinite)
+		}
+	}
+}
+
+
+// parseIDList parses space-separated star IDs, or "-" for none.
+func parseIDList(text string) []int64 {
+	var ids []int64
+	for _, field := range strings.Fields(text) {
+		if field == "-" {
+			continue
+		}
+		id, err := strconv.ParseInt(field, 10, 64)
+		nbody.Check(err)
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,95 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the accelerations under the force laws in nbody/forcelaw.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"bufio"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type ForceLawAccelerationsTestCases struct {
+	id        string
+	law       string
+	numStars  int
+	softening float64
+}
+
+
+// ReadForceLawAccelerations reads test data for the force laws in the tree walk and direct summation from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of ForceLawAccelerationsTestCases structs containing the laws and galaxies.
+func ReadForceLawAccelerations(fileName string) []ForceLawAccelerationsTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []ForceLawAccelerationsTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		numStars, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+		nbody.Check(err)
+		softening, err := strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
+		nbody.Check(err)
+
+		tests = append(tests, ForceLawAccelerationsTestCases{
+			id:        strings.TrimSpace(parts[0]),
+			law:       strings.TrimSpace(parts[1]),
+			numStars:  numStars,
+			softening: softening,
+		})
+	}
+
+	return tests
+}
+
+
+// TestForceLawAccelerations tests that the tree walk with theta 0, direct summation, and direct summation over
+// StarArrays give the same accelerations with every force law.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestForceLawAccelerations(t *testing.T) {
+	tests := ReadForceLawAccelerations("../Tests/ForceLawAccelerations.txt")
+	for _, test := range tests {
+		law, err := nbody.ParseForceLaw(test.law)
+		nbody.Check(err)
+
+		nbody.SetDeterministic()
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		u := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+
+		options := DefaultOptions(1, 1, 0).WithSoftening(test.softening).WithForceLaw(law)
+		tree, err := ComputeAccelerations(u, quadtree.BuildQuadTree(u, options.Build()), options)
+		nbody.Check(err)
+		direct := DirectAccelerations(u, options)
+		arrays := DirectAccelerations(u, options.WithLayout("soa"))
+
+		for i := range tree {
+			scale := math.Hypot(direct[i].X, direct[i].Y)
+			for _, result := range []nbody.OrderedPair{tree[i], arrays[i]} {
+				if math.Hypot(result.X-direct[i].X, result.Y-direct[i].Y) > 1e-9*scale {
+					t.Errorf("TestForceLawAccelerations(test %v, star %d) = %v, want %v", test.id, i, result, direct[i])
+				}
+			}
+		}
+	}
+}
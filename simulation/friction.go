@@ -0,0 +1,173 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Dynamical friction and drag on massive stars such as black holes (options "-friction" and
+// "-friction-mass").
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A heavy body moving through a sea of light stars pulls a wake of them behind it, which pulls it back: dynamical
+// friction. It is what lets the black holes of merging galaxies sink to the center and meet, but a simulation
+// with far fewer and heavier stars than a real galaxy resolves the wake poorly, so the black holes keep orbiting
+// each other for much longer than they should. Friction adds the missing deceleration to the stars at least as
+// heavy as Friction.Mass, from the k nearest lighter stars around each of them (frictionNeighbors):
+//
+//	drag           a = -gamma (v - u), with u the mean velocity of the neighbors
+//	chandrasekhar  a = -4 pi G^2 M rho lnL / |v - u|^3 (erf(X) - 2X / sqrt(pi) e^(-X^2)) (v - u),
+//	               X = |v - u| / (sqrt(2) sigma)
+//
+// Chandrasekhar's formula takes the density rho and the one-dimensional velocity dispersion sigma of the
+// neighbors, rho as if their mass were spread over the sphere of the distance to the farthest of them. Only the
+// heavy stars slow down, so momentum and energy are not conserved; -momentum-check and -energy-log show by how
+// much. The neighbors are found by going through all stars, once per heavy star and force computation, which
+// costs little as long as only a few stars, such as the black holes, are heavy.
+
+// frictionNeighbors is the number of nearest lighter stars whose density and velocities give the friction.
+const frictionNeighbors = 32
+
+// frictionLogLambda is the Coulomb logarithm ln(Lambda) of -friction chandrasekhar when none is given.
+const frictionLogLambda = 3
+
+
+// Friction is the friction of a run (see FrictionAcceleration); the zero value has none.
+type Friction struct {
+	kind        string  // "drag" or "chandrasekhar", or "" for none
+	coefficient float64 // gamma in 1/s for drag, the Coulomb logarithm for chandrasekhar
+	mass        float64 // stars at least this heavy, in kg, feel the friction
+}
+
+
+// ParseFriction parses the value of -friction: "drag:gamma" (gamma in 1/s), "chandrasekhar", or
+// "chandrasekhar:lnL" (the Coulomb logarithm, 3 by default).
+// Input:
+//   - text: the value of -friction, "" for none.
+//   - mass: the value of -friction-mass, the mass in kg from which stars feel the friction.
+// Output:
+//   - the Friction, or an error if the name is unknown or its parameters are invalid.
+func ParseFriction(text string, mass float64) (Friction, error) {
+	if text == "" {
+		return Friction{}, nil
+	}
+	if !(mass > 0) || !nbody.IsFinite(mass) {
+		return Friction{}, fmt.Errorf("-friction-mass must be a finite positive mass in kg, got %v", mass)
+	}
+
+	name, param, hasParam := strings.Cut(text, ":")
+	value := 0.0
+	if hasParam {
+		var err error
+		value, err = strconv.ParseFloat(strings.TrimSpace(param), 64)
+		if err != nil || !nbody.IsFinite(value) {
+			return Friction{}, fmt.Errorf("invalid friction %q: %q is not a number", text, param)
+		}
+	}
+
+	switch name {
+	case "drag":
+		if !hasParam || value <= 0 {
+			return Friction{}, fmt.Errorf("invalid friction %q: expected drag:gamma with a positive gamma in 1/s", text)
+		}
+		return Friction{kind: "drag", coefficient: value, mass: mass}, nil
+	case "chandrasekhar":
+		if !hasParam {
+			value = frictionLogLambda
+		}
+		if value <= 0 {
+			return Friction{}, fmt.Errorf("invalid friction %q: the Coulomb logarithm must be positive", text)
+		}
+		return Friction{kind: "chandrasekhar", coefficient: value, mass: mass}, nil
+	}
+	return Friction{}, fmt.Errorf("unknown friction %q (use drag:gamma or chandrasekhar[:lnL])", text)
+}
+
+
+// FrictionAcceleration returns the acceleration of a star by the friction of a run.
+// Input:
+//   - s: pointer to the Star.
+//   - stars: all stars of the universe, which may include s.
+//   - f: the Friction of the run.
+// Output:
+//   - the acceleration, 0 for a star lighter than f.Mass or without lighter stars around it.
+func FrictionAcceleration(s *nbody.Star, stars []*nbody.Star, f Friction) nbody.OrderedPair {
+	if f.kind == "" || s.Mass < f.mass {
+		return nbody.OrderedPair{}
+	}
+
+	// the nearest lighter stars, tracers aside, sorted by distance and in the order of the universe for equal
+	// distances; a star is inserted only if it is closer than the farthest one kept so far
+	type neighbor struct {
+		star     *nbody.Star
+		distance float64
+	}
+	neighbors := make([]neighbor, 0, frictionNeighbors)
+	for _, other := range stars {
+		if other == s || other.Mass >= f.mass || nbody.IsTracer(other) {
+			continue
+		}
+		_, _, d := nbody.Distance(s.Position, other.Position)
+		if len(neighbors) == frictionNeighbors {
+			if d >= neighbors[len(neighbors)-1].distance {
+				continue
+			}
+			neighbors = neighbors[:len(neighbors)-1]
+		}
+		i := sort.Search(len(neighbors), func(i int) bool { return neighbors[i].distance > d })
+		neighbors = append(neighbors, neighbor{})
+		copy(neighbors[i+1:], neighbors[i:])
+		neighbors[i] = neighbor{star: other, distance: d}
+	}
+	if len(neighbors) == 0 {
+		return nbody.OrderedPair{}
+	}
+
+	// mass-weighted mean velocity and one-dimensional dispersion of the neighbors
+	var mass float64
+	var mean nbody.OrderedPair
+	for _, n := range neighbors {
+		mass += n.star.Mass
+		mean.X += n.star.Mass * n.star.Velocity.X
+		mean.Y += n.star.Mass * n.star.Velocity.Y
+	}
+	mean.X /= mass
+	mean.Y /= mass
+	v := nbody.OrderedPair{X: s.Velocity.X - mean.X, Y: s.Velocity.Y - mean.Y}
+
+	if f.kind == "drag" {
+		return nbody.OrderedPair{X: -f.coefficient * v.X, Y: -f.coefficient * v.Y}
+	}
+
+	speed := math.Hypot(v.X, v.Y)
+	if speed == 0 {
+		return nbody.OrderedPair{}
+	}
+	variance := 0.0
+	for _, n := range neighbors {
+		dx, dy := n.star.Velocity.X-mean.X, n.star.Velocity.Y-mean.Y
+		variance += n.star.Mass * (dx*dx + dy*dy)
+	}
+	sigma := math.Sqrt(variance / (2 * mass))
+	radius := neighbors[len(neighbors)-1].distance
+	density := mass / (4.0 / 3 * math.Pi * radius * radius * radius)
+
+	// the fraction of the neighbors slower than the star, for a Maxwellian distribution of their velocities
+	fraction := 1.0
+	if sigma > 0 {
+		x := speed / (math.Sqrt2 * sigma)
+		if x < 1e-3 {
+			// the leading term of the series, where the difference below loses its digits
+			fraction = 4 * x * x * x / (3 * math.Sqrt(math.Pi))
+		} else {
+			fraction = math.Erf(x) - 2*x/math.Sqrt(math.Pi)*math.Exp(-x*x)
+		}
+	}
+	a := -4 * math.Pi * nbody.G * nbody.G * s.Mass * density * f.coefficient * fraction / (speed * speed * speed)
+	return nbody.OrderedPair{X: a * v.X, Y: a * v.Y}
+}
@@ -0,0 +1,215 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the friction of the heaviest stars in friction.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type FrictionTestCases struct {
+	id           string
+	friction     string
+	frictionMass float64
+	star         *nbody.Star
+	field        []*nbody.Star
+	expected     nbody.OrderedPair
+	expectError  bool
+}
+
+
+// parseStar parses a star given as "x y vx vy mass".
+func parseStar(text string) *nbody.Star {
+	v := parseFloats(text)
+	return nbody.NewStar(nbody.OrderedPair{X: v[0], Y: v[1]}, nbody.OrderedPair{X: v[2], Y: v[3]}, v[4], 1)
+}
+
+
+// ReadFriction reads test data for the ParseFriction and FrictionAcceleration functions from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of FrictionTestCases structs containing the frictions, the stars, and the expected accelerations.
+func ReadFriction(fileName string) []FrictionTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []FrictionTestCases
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<20)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+
+		friction := strings.Fields(parts[1])
+		frictionMass, err := strconv.ParseFloat(friction[1], 64)
+		nbody.Check(err)
+
+		test := FrictionTestCases{
+			id:           strings.TrimSpace(parts[0]),
+			friction:     friction[0],
+			frictionMass: frictionMass,
+			star:         parseStar(parts[2]),
+		}
+		for _, star := range strings.Split(parts[3], ",") {
+			test.field = append(test.field, parseStar(star))
+		}
+		if expected := strings.TrimSpace(parts[4]); expected == "error" {
+			test.expectError = true
+		} else {
+			values := parseFloats(expected)
+			test.expected = nbody.OrderedPair{X: values[0], Y: values[1]}
+		}
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestFriction tests that ParseFriction accepts the valid values of -friction and -friction-mass and rejects the
+// others, and that FrictionAcceleration slows heavy stars down relative to their lighter neighbors.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestFriction(t *testing.T) {
+	tests := ReadFriction("../Tests/Friction.txt")
+
+	for _, test := range tests {
+		friction, err := ParseFriction(test.friction, test.frictionMass)
+
+		if test.expectError {
+			if err == nil {
+				t.Errorf("TestFriction(test %v) = %v, want an error", test.id, friction)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestFriction(test %v) = error %v, want none", test.id, err)
+			continue
+		}
+
+		// the star is among the stars of the universe, as in a run
+		stars := append([]*nbody.Star{test.star}, test.field...)
+		result := FrictionAcceleration(test.star, stars, friction)
+		if !closeTo(result.X, test.expected.X, 1e-6) || !closeTo(result.Y, test.expected.Y, 1e-6) {
+			t.Errorf("TestFriction(test %v) = %v, want %v", test.id, result, test.expected)
+		}
+	}
+}
+
+
+type FrictionRunTestCases struct {
+	id               string
+	friction         string
+	timeStep         float64
+	substeps         int
+	numGens          int
+	expectedFraction float64
+	expectError      bool
+}
+
+
+// ReadFrictionRun reads test data for friction during a run from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of FrictionRunTestCases structs containing the runs and the expected fractions of the speed.
+func ReadFrictionRun(fileName string) []FrictionRunTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []FrictionRunTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		run := parseFloats(parts[2])
+		test := FrictionRunTestCases{
+			id:       strings.TrimSpace(parts[0]),
+			friction: strings.TrimSpace(parts[1]),
+			timeStep: run[0],
+			substeps: int(run[1]),
+			numGens:  int(run[2]),
+		}
+		if expected := strings.TrimSpace(parts[3]); expected == "error" {
+			test.expectError = true
+		} else {
+			test.expectedFraction, err = strconv.ParseFloat(expected, 64)
+			nbody.Check(err)
+		}
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestFrictionRun tests that drag slows a heavy star down exponentially during a run, that Validate rejects a
+// drag too strong for the substeps, and that a star without a field to move through is not slowed down.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestFrictionRun(t *testing.T) {
+	tests := ReadFrictionRun("../Tests/FrictionRun.txt")
+
+	for _, test := range tests {
+		friction, err := ParseFriction(test.friction, 1e36)
+		nbody.Check(err)
+		options := DefaultOptions(test.numGens, test.timeStep, 0.5).WithFriction(friction)
+		options.Substeps = test.substeps
+
+		err = options.Validate()
+		if test.expectError {
+			if err == nil {
+				t.Errorf("TestFrictionRun(test %v) Validate() = nil, want an error", test.id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestFrictionRun(test %v) Validate() = %v, want nil", test.id, err)
+			continue
+		}
+
+		u := nbody.NewUniverse(1e24)
+		heavy := nbody.NewStar(u.Center(), nbody.OrderedPair{X: 1e5}, 8e36, 1)
+		u.AddStars(heavy)
+		for i := 0; i < 8; i++ {
+			angle := float64(i) * math.Pi / 4
+			s := nbody.NewStar(nbody.OrderedPair{X: 5e23 + 1e23*math.Cos(angle), Y: 5e23 + 1e23*math.Sin(angle)}, nbody.OrderedPair{}, 1, 1)
+			s.Pinned = true
+			u.AddStars(s)
+		}
+
+		var speed float64
+		options = options.WithHook(func(generation int, u *nbody.Universe) {
+			speed = math.Hypot(u.Stars[0].Velocity.X, u.Stars[0].Velocity.Y)
+		})
+		nbody.Check(RunGenerations(u, options))
+
+		if fraction := speed / 1e5; math.Abs(fraction-test.expectedFraction) > 0.01*test.expectedFraction {
+			t.Errorf("TestFrictionRun(test %v) = %v of the speed, want %v", test.id, fraction, test.expectedFraction)
+		}
+	}
+}
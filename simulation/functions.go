@@ -0,0 +1,331 @@
+// Author: Yu-Lun Chen
+// Date: 2025-10-24
+// Description: Functions advancing a universe by one generation and running the BarnesHut simulation.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+)
+
+//BarnesHut is our highest level function.
+//Input: initial Universe object and the Options of the run (number of generations, time interval, theta, ...).
+//Output: collection of Universe objects corresponding to updating the system
+//over indicated number of generations every given time interval (panics if the run fails, see RunGenerations).
+//With options.SaveInterval > 1 only the generations SavedGenerations lists are kept, in that order;
+//the others are discarded as soon as the next one is computed.
+//If options.ctx is cancelled (see Options.WithContext), the generations kept so far are returned.
+func BarnesHut(initialUniverse *nbody.Universe, options Options) []*nbody.Universe {
+	interval := max(options.SaveInterval, 1)
+	kept := func(generation int) bool {
+		return generation == options.FirstGeneration || generation%interval == 0 || generation == options.NumGens
+	}
+	timePoints := make([]*nbody.Universe, 0, len(SavedGenerations(options.FirstGeneration, options.NumGens, interval)))
+	if interval > 1 {
+		// the generations in between are updated in place
+		options.Retain = kept
+	}
+
+	err := RunGenerations(initialUniverse, options.WithHook(func(generation int, u *nbody.Universe) {
+		if kept(generation) {
+			timePoints = append(timePoints, u)
+		}
+	}))
+	if !Interrupted(err) {
+		nbody.Check(err)
+	}
+
+    return timePoints
+}
+
+
+//SavedGenerations returns the generations BarnesHut keeps with a save interval: the first generation,
+//the multiples of the interval after it, and the last generation numGens if it is not such a multiple.
+//A run that is not resumed (see RunGenerations) starts at generation 0.
+//An interval of 0 or 1 keeps every generation.
+func SavedGenerations(first, numGens, interval int) []int {
+	interval = max(interval, 1)
+	generations := []int{first}
+	for g := (first/interval + 1) * interval; g <= numGens; g += interval {
+		generations = append(generations, g)
+	}
+	if numGens > first && numGens%interval != 0 {
+		generations = append(generations, numGens)
+	}
+	return generations
+}
+
+
+//RunGenerations runs the simulation like BarnesHut, but instead of keeping every Universe
+//it hands each generation (starting with a copy of the initial Universe as generation 0) to options.onGeneration.
+//The hook decides what to keep, so callers that only need some generations can run in little memory.
+//A run resumed from a checkpoint starts with the initial Universe as generation options.FirstGeneration;
+//the events of earlier generations are not applied again, except that recurring ones stay active.
+//If a parallel worker fails, the run stops after the current generation and the error is returned.
+//The generations are computed by a Simulation (see Simulation.Step).
+//Input: initial Universe object and the Options of the run.
+//Output: the first error of the run, or nil.
+func RunGenerations(initialUniverse *nbody.Universe, options Options) error {
+	sim, err := NewSimulation(initialUniverse, options)
+	if err != nil {
+		return err
+	}
+	return sim.Run(options.NumGens - options.FirstGeneration)
+}
+
+
+// UpdateUniverse updates the positions, velocities, and accelerations of all stars in the universe for one timestep.
+// Input:
+//   - current_universe: pointer to the current Universe.
+//   - tree: pointer to the QuadTree representing the current universe.
+//   - options: the Options of the run (time step, theta, integrator, softening, boundary).
+// Output:
+//   - Pointer to the updated Universe, or an error if a force worker failed.
+func UpdateUniverse(currentUniverse *nbody.Universe, tree *quadtree.QuadTree, options Options) (*nbody.Universe, error) {
+	accelerations, err := ComputeAccelerations(currentUniverse, tree, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// with an adaptive time step, the step is chosen from the new accelerations
+	if options.AdaptiveStep > 0 {
+		options.TimeStep = AdaptiveTimeStep(currentUniverse, accelerations, options)
+	}
+
+	return IntegrateUniverse(currentUniverse, accelerations, options), nil
+}
+
+
+// UpdateUniverseInPlace advances the universe by one time step like UpdateUniverse, but overwrites its stars
+// instead of copying them, which saves the allocation of a new Universe when the current one is not kept.
+// All accelerations are computed before any star moves, so the result is identical to UpdateUniverse.
+// Input:
+//   - u: pointer to the Universe to advance.
+//   - tree: pointer to the QuadTree representing u.
+//   - options: the Options of the run (time step, theta, softening, integrator, boundary).
+// Output:
+//   - the first error of the force workers, or nil.
+func UpdateUniverseInPlace(u *nbody.Universe, tree *quadtree.QuadTree, options Options) error {
+	accelerations, err := ComputeAccelerations(u, tree, options)
+	if err != nil {
+		return err
+	}
+
+	if options.AdaptiveStep > 0 {
+		options.TimeStep = AdaptiveTimeStep(u, accelerations, options)
+	}
+
+	IntegrateInPlace(u, accelerations, options)
+	return nil
+}
+
+
+// StepInPlace advances the universe by one step like Step, in place unless block time steps are used.
+// It returns the advanced universe, which is u itself when the update was in place.
+func StepInPlace(u *nbody.Universe, tree *quadtree.QuadTree, options Options) (*nbody.Universe, error) {
+	if options.BlockLevels > 0 {
+		return BlockStep(u, tree, options)
+	}
+	return u, UpdateUniverseInPlace(u, tree, options)
+}
+
+
+// Step advances the universe by one step, with block time steps (BlockStep) if options.BlockLevels is set
+// and with one step for all stars (UpdateUniverse) otherwise.
+func Step(currentUniverse *nbody.Universe, tree *quadtree.QuadTree, options Options) (*nbody.Universe, error) {
+	if options.BlockLevels > 0 {
+		return BlockStep(currentUniverse, tree, options)
+	}
+	return UpdateUniverse(currentUniverse, tree, options)
+}
+
+
+// forceChunk is the number of stars whose forces one work item of ComputeAccelerations computes.
+const forceChunk = 64
+
+
+// ComputeAccelerations computes the new acceleration of every star from the tree (or by direct summation,
+// see Options.WithForceMode, over StarArrays with the layout "soa"), on options.Workers() goroutines. The stars
+// of a tree built in Morton order are visited in that order.
+// Every star's force is computed exactly as on one goroutine, so the result does not depend on the workers.
+// Non-finite accelerations are handled as options.FiniteCheck says (see GuardAccelerations).
+// Input:
+//   - current_universe: pointer to the current Universe.
+//   - tree: pointer to the QuadTree representing the current universe.
+//   - options: the Options of the run (theta, softening, finiteCheck).
+// Output:
+//   - the acceleration of every star, in the order of current_universe.Stars, or the first worker error or
+//     NumericalError.
+func ComputeAccelerations(currentUniverse *nbody.Universe, tree *quadtree.QuadTree, options Options) ([]nbody.OrderedPair, error) {
+	if options.ForceMode == "direct" && options.Layout == "soa" {
+		accelerations := ArrayAccelerations(currentUniverse, options)
+		return accelerations, GuardAccelerations(currentUniverse, accelerations, options)
+	}
+	stars := currentUniverse.Stars
+	accelerations := make([]nbody.OrderedPair, len(stars))
+
+	// a tree built in Morton order is walked in that order, so consecutive stars open nearly the same nodes
+	var order []int
+	if tree != nil && len(tree.Order) == len(stars) {
+		order = tree.Order
+	}
+
+	numChunks := (len(stars) + forceChunk - 1) / forceChunk
+	err := nbody.ParallelForErr(numChunks, options.Workers(), func(c int) error {
+		for k := c * forceChunk; k < (c+1)*forceChunk && k < len(stars); k++ {
+			i := k
+			if order != nil {
+				i = order[k]
+			}
+			accelerations[i] = StarAcceleration(stars[i], currentUniverse, tree, options)
+		}
+		return nil
+	})
+	if err == nil {
+		err = GuardAccelerations(currentUniverse, accelerations, options)
+	}
+
+	return accelerations, err
+}
+
+
+// IntegrateUniverse advances a copy of the universe by one time step given the new accelerations of its stars.
+// Input:
+//   - current_universe: pointer to the current Universe.
+//   - accelerations: new acceleration of every star, in the order of current_universe.Stars.
+//   - options: the Options of the run (time step, integrator, boundary).
+// Output:
+//   - Pointer to the updated Universe.
+func IntegrateUniverse(currentUniverse *nbody.Universe, accelerations []nbody.OrderedPair, options Options) *nbody.Universe {
+	newUniverse := nbody.CopyUniverse(currentUniverse)
+	IntegrateInPlace(newUniverse, accelerations, options)
+	return newUniverse
+}
+
+
+// IntegrateInPlace advances the stars of a universe by one time step like IntegrateUniverse, overwriting them.
+// Pinned stars only age: their position and velocity are never updated.
+// Input:
+//   - newUniverse: pointer to the Universe to advance.
+//   - accelerations: new acceleration of every star, in the order of newUniverse.Stars.
+//   - options: the Options of the run (time step, integrator, boundary).
+// Output:
+//   - None (modifies the universe in place).
+func IntegrateInPlace(newUniverse *nbody.Universe, accelerations []nbody.OrderedPair, options Options) {
+	time := options.TimeStep
+	newUniverse.Time += time
+	width, height := newUniverse.Bounds()
+
+	for i, b := range newUniverse.Stars {
+		oldAcceleration := b.Acceleration
+
+		newUniverse.Stars[i].Acceleration = accelerations[i]
+		b.Age += time
+		if b.Pinned {
+			continue
+		}
+
+		if options.Integrator == "euler" {
+			// semi-implicit Euler: kick with the new acceleration, then drift with the new velocity
+			b.Velocity.X += b.Acceleration.X * time
+			b.Velocity.Y += b.Acceleration.Y * time
+			b.Position.X += b.Velocity.X * time
+			b.Position.Y += b.Velocity.Y * time
+		} else {
+			// velocity Verlet: the accelerations belong to the current positions, so they finish the velocity
+			// of the previous step and start the drift of this one (the stored velocity lags one step behind)
+			newUniverse.Stars[i].Velocity = UpdateVelocity(newUniverse.Stars[i], oldAcceleration, time)
+			newUniverse.Stars[i].Position = UpdatePosition(newUniverse.Stars[i], b.Acceleration, b.Velocity, time)
+		}
+
+		if options.Boundary == "reflect" {
+			ReflectStar(b, width, height)
+		}
+	}
+}
+
+
+// ReflectStar mirrors a star that left the universe back inside and reverses the matching velocity component.
+// Input:
+//   - s: pointer to the Star.
+//   - width: width of the universe.
+//   - height: height of the universe (see Bounds).
+// Output:
+//   - None (modifies the star in place).
+func ReflectStar(s *nbody.Star, width, height float64) {
+	if s.Position.X < 0 {
+		s.Position.X = -s.Position.X
+		s.Velocity.X = -s.Velocity.X
+	} else if s.Position.X > width {
+		s.Position.X = 2 * width - s.Position.X
+		s.Velocity.X = -s.Velocity.X
+	}
+
+	if s.Position.Y < 0 {
+		s.Position.Y = -s.Position.Y
+		s.Velocity.Y = -s.Velocity.Y
+	} else if s.Position.Y > height {
+		s.Position.Y = 2 * height - s.Position.Y
+		s.Velocity.Y = -s.Velocity.Y
+	}
+}
+
+
+// UpdateAcceleration computes the new acceleration for a star based on the net force from the QuadTree.
+// A tracer has no mass, so its acceleration is the force on a star of unit mass in its place.
+// Input:
+//   - s: pointer to the Star.
+//   - tree: pointer to the QuadTree.
+//   - walk: the WalkOptions of the run (theta, softening, opening criterion, and tree walk).
+// Output:
+//   - OrderedPair representing the new acceleration.
+func UpdateAcceleration(s *nbody.Star, tree *quadtree.QuadTree, walk quadtree.WalkOptions) nbody.OrderedPair {
+	var accel nbody.OrderedPair
+
+	if nbody.IsTracer(s) {
+		return tree.NetForce(nbody.UnitProbe(s), walk)
+	}
+
+	// calculate the net force with QuadTree and the given theta
+	force := tree.NetForce(s, walk)
+	accel.X = force.X / s.Mass
+	accel.Y = force.Y / s.Mass
+
+	return accel
+}
+
+
+// UpdateVelocity updates the velocity of a star using the previous and current acceleration.
+// Input:
+//   - s: pointer to the Star.
+//   - old_acceleration: OrderedPair of the previous acceleration.
+//   - time: time interval for the update.
+// Output:
+//   - OrderedPair representing the new velocity.
+func UpdateVelocity(s *nbody.Star, oldAcceleration nbody.OrderedPair, time float64) nbody.OrderedPair {
+	var velo nbody.OrderedPair
+
+	velo.X = s.Velocity.X + 0.5 * (s.Acceleration.X + oldAcceleration.X) * time
+	velo.Y = s.Velocity.Y + 0.5 * (s.Acceleration.Y + oldAcceleration.Y) * time
+
+	return velo
+}
+
+
+// UpdatePosition updates the position of a star using its previous acceleration and velocity.
+// Input:
+//   - s: pointer to the Star.
+//   - old_acceleration: OrderedPair of the previous acceleration.
+//   - old_velocity: OrderedPair of the previous velocity.
+//   - time: time interval for the update.
+// Output:
+//   - OrderedPair representing the new position.
+func UpdatePosition(s *nbody.Star, oldAcceleration, oldVelocity nbody.OrderedPair, time float64) nbody.OrderedPair {
+	var pos nbody.OrderedPair
+
+	pos.X = s.Position.X + oldVelocity.X * time + 0.5 * oldAcceleration.X * time * time
+	pos.Y = s.Position.Y + oldVelocity.Y * time + 0.5 * oldAcceleration.Y * time * time
+
+	return pos
+}
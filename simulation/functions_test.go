@@ -0,0 +1,673 @@
+// Author: Yu-Lun Chen
+// Date: 2025-10-24
+// Description: Testing functions for the subroutines in functions.go.
+// Each txt file contains input testing cases and the expected output for each cases.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type VelocityTestCases struct {
+	id string
+	star nbody.Star
+	oldAcceleration nbody.OrderedPair
+	time float64
+	expected nbody.OrderedPair
+}
+
+type PositionTestCases struct {
+	id string
+	star nbody.Star
+	oldAcceleration nbody.OrderedPair
+	oldVelocity nbody.OrderedPair
+	time float64
+	expected nbody.OrderedPair
+}
+
+
+// ReadVelocity reads test data for the UpdateVelocity function from a file.
+// Input: file_name (string) - path to the test data file.
+// Output: slice of VelocityTestCases structs containing star, old acceleration, time, and expected velocity.
+func ReadVelocity(fileName string) []VelocityTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []VelocityTestCases
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+        if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+            continue
+        }
+		
+		parts := strings.Fields(line)
+
+		id := strings.TrimSpace(parts[0])
+		vx, err := strconv.ParseFloat(parts[1], 64)
+		nbody.Check(err)
+		vy, err := strconv.ParseFloat(parts[2], 64)
+		nbody.Check(err)
+		ax, err := strconv.ParseFloat(parts[3], 64)
+		nbody.Check(err)
+		ay, err := strconv.ParseFloat(parts[4], 64)
+		nbody.Check(err)
+		oldAx, err := strconv.ParseFloat(parts[5], 64)
+		nbody.Check(err)
+		oldAy, err := strconv.ParseFloat(parts[6], 64)
+		nbody.Check(err)
+		t, err := strconv.ParseFloat(parts[7], 64)
+		nbody.Check(err)
+		expVx, err := strconv.ParseFloat(parts[8], 64)
+		nbody.Check(err)
+		expVy, err := strconv.ParseFloat(parts[9], 64)
+		nbody.Check(err)
+
+		test := VelocityTestCases{
+			id: id,
+			star: nbody.Star{
+				Velocity: nbody.OrderedPair{X: vx, Y: vy},
+				Acceleration: nbody.OrderedPair{X: ax, Y: ay},
+			},
+			oldAcceleration: nbody.OrderedPair{X: oldAx, Y: oldAy},
+			time: t,
+			expected: nbody.OrderedPair{X: expVx, Y: expVy},
+		}
+		tests = append(tests, test)
+	}
+	return tests
+}
+
+
+// ReadPosition reads test data for the UpdatePosition function from a file.
+// Input: file_name (string) - path to the test data file.
+// Output: slice of PositionTestCases structs containing star, old acceleration, old velocity, time, and expected position.
+func ReadPosition(fileName string) []PositionTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []PositionTestCases
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+        if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+            continue
+        }
+		
+		parts := strings.Fields(line)
+
+		id := strings.TrimSpace(parts[0])
+		px, err := strconv.ParseFloat(parts[1], 64)
+		nbody.Check(err)
+		py, err := strconv.ParseFloat(parts[2], 64)
+		nbody.Check(err)
+		oldVx, err := strconv.ParseFloat(parts[3], 64)
+		nbody.Check(err)
+		oldVy, err := strconv.ParseFloat(parts[4], 64)
+		nbody.Check(err)
+		oldAx, err := strconv.ParseFloat(parts[5], 64)
+		nbody.Check(err)
+		oldAy, err := strconv.ParseFloat(parts[6], 64)
+		nbody.Check(err)
+		t, err := strconv.ParseFloat(parts[7], 64)
+		nbody.Check(err)
+		expPx, err := strconv.ParseFloat(parts[8], 64)
+		nbody.Check(err)
+		expPy, err := strconv.ParseFloat(parts[9], 64)
+		nbody.Check(err)
+
+		test := PositionTestCases{
+			id: id,
+			star: nbody.Star{
+				Position: nbody.OrderedPair{X: px, Y: py},
+			},
+			oldAcceleration: nbody.OrderedPair{X: oldAx, Y: oldAy},
+			oldVelocity: nbody.OrderedPair{X: oldVx, Y: oldVy},
+			time: t,
+			expected: nbody.OrderedPair{X: expPx, Y: expPy},
+		}
+		tests = append(tests, test)
+	}
+	return tests
+}
+
+
+// TestVelocity tests the UpdateVelocity function using data from a file.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestVelocity(t *testing.T) {
+	tests := ReadVelocity("../Tests/UpdateVelocity.txt")
+
+	for _, test := range tests {
+		// need an address for the star!!!
+		result := UpdateVelocity(&test.star, test.oldAcceleration, test.time)
+
+		if math.Abs(result.X - test.expected.X) > 1e-3 ||
+			math.Abs(result.Y - test.expected.Y) > 1e-3 {
+				t.Errorf("TestVelocity(test %v) = (x: %v, y: %v), want (x: %v, y: %v)",
+					test.id, result.X, result.Y, test.expected.X, test.expected.Y)
+			}
+	}
+}
+
+
+// TestPosition tests the UpdatePosition function using data from a file.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestPosition(t *testing.T) {
+	tests := ReadPosition("../Tests/UpdatePosition.txt")
+
+	for _, test := range tests {
+		result := UpdatePosition(&test.star, test.oldAcceleration, test.oldVelocity, test.time)
+
+		if math.Abs(result.X - test.expected.X) > 1e-3 ||
+			math.Abs(result.Y - test.expected.Y) > 1e-3 {
+				t.Errorf("TestPosition(test %v) = (x: %v, y: %v), want (x: %v, y: %v)",
+					test.id, result.X, result.Y, test.expected.X, test.expected.Y)
+			}
+	}
+}
+
+
+type SubstepsTestCases struct {
+	id         string
+	numStars   int
+	numGens    int
+	substeps   int
+	integrator string
+}
+
+
+// ReadSubsteps reads test data for substeps in RunGenerations from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of SubstepsTestCases structs containing the size of the run, the substeps, and the integrator.
+func ReadSubsteps(fileName string) []SubstepsTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []SubstepsTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+
+		numStars, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		numGens, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+		nbody.Check(err)
+		substeps, err := strconv.Atoi(strings.TrimSpace(parts[3]))
+		nbody.Check(err)
+
+		tests = append(tests, SubstepsTestCases{
+			id:         strings.TrimSpace(parts[0]),
+			numStars:   numStars,
+			numGens:    numGens,
+			substeps:   substeps,
+			integrator: strings.TrimSpace(parts[4]),
+		})
+	}
+
+	return tests
+}
+
+
+// TestSubsteps tests that a run with substeps stores every substeps-th generation of a run with a smaller time step.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestSubsteps(t *testing.T) {
+	tests := ReadSubsteps("../Tests/Substeps.txt")
+
+	for _, test := range tests {
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		u := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+
+		options := DefaultOptions(test.numGens, 2e14, 0.5)
+		options.Integrator = test.integrator
+		options.Substeps = test.substeps
+		result := BarnesHut(u, options)
+
+		fine := DefaultOptions(test.numGens*test.substeps, 2e14/float64(test.substeps), 0.5)
+		fine.Integrator = test.integrator
+		expected := BarnesHut(u, fine)
+
+		for gen := range result {
+			for i, s := range result[gen].Stars {
+				if s.Position != expected[gen*test.substeps].Stars[i].Position {
+					t.Errorf("TestSubsteps(test %v, generation %d, star %d) = %v, want %v",
+						test.id, gen, i, s.Position, expected[gen*test.substeps].Stars[i].Position)
+				}
+			}
+		}
+	}
+}
+
+
+type SaveIntervalTestCases struct {
+	id       string
+	numStars int
+	numGens  int
+	interval int
+	expected []int
+}
+
+
+// ReadSaveInterval reads test data for the save interval of BarnesHut from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of SaveIntervalTestCases structs containing the size of the run, the interval, and the kept generations.
+func ReadSaveInterval(fileName string) []SaveIntervalTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []SaveIntervalTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+
+		var nums [3]int
+		for i, part := range parts[1:4] {
+			nums[i], err = strconv.Atoi(strings.TrimSpace(part))
+			nbody.Check(err)
+		}
+		var expected []int
+		for _, field := range strings.Split(parts[4], ",") {
+			g, err := strconv.Atoi(strings.TrimSpace(field))
+			nbody.Check(err)
+			expected = append(expected, g)
+		}
+
+		tests = append(tests, SaveIntervalTestCases{
+			id:       strings.TrimSpace(parts[0]),
+			numStars: nums[0],
+			numGens:  nums[1],
+			interval: nums[2],
+			expected: expected,
+		})
+	}
+
+	return tests
+}
+
+
+// TestSaveInterval tests that BarnesHut with a save interval keeps the generations SavedGenerations lists,
+// equal to the same generations of a run keeping every generation.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestSaveInterval(t *testing.T) {
+	tests := ReadSaveInterval("../Tests/SaveInterval.txt")
+
+	for _, test := range tests {
+		generations := SavedGenerations(0, test.numGens, test.interval)
+		if !reflect.DeepEqual(generations, test.expected) {
+			t.Errorf("TestSaveInterval(test %v) SavedGenerations = %v, want %v", test.id, generations, test.expected)
+			continue
+		}
+
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		u := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+
+		full := BarnesHut(u, DefaultOptions(test.numGens, 2e14, 0.5))
+		options := DefaultOptions(test.numGens, 2e14, 0.5)
+		options.SaveInterval = test.interval
+		result := BarnesHut(u, options)
+
+		if len(result) != len(generations) {
+			t.Errorf("TestSaveInterval(test %v) kept %d generations, want %d", test.id, len(result), len(generations))
+			continue
+		}
+		for k, gen := range generations {
+			if !reflect.DeepEqual(result[k], full[gen]) {
+				t.Errorf("TestSaveInterval(test %v) universe %d differs from generation %d", test.id, k, gen)
+			}
+		}
+	}
+}
+
+
+type UpdateUniverseInPlaceTestCases struct {
+	id         string
+	numStars   int
+	integrator string
+	boundary   string
+	softening  float64
+}
+
+
+// ReadUpdateUniverseInPlace reads test data for UpdateUniverseInPlace from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of UpdateUniverseInPlaceTestCases structs containing the number of stars and the options.
+func ReadUpdateUniverseInPlace(fileName string) []UpdateUniverseInPlaceTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []UpdateUniverseInPlaceTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+
+		numStars, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		softening, err := strconv.ParseFloat(strings.TrimSpace(parts[4]), 64)
+		nbody.Check(err)
+
+		tests = append(tests, UpdateUniverseInPlaceTestCases{
+			id:         strings.TrimSpace(parts[0]),
+			numStars:   numStars,
+			integrator: strings.TrimSpace(parts[2]),
+			boundary:   strings.TrimSpace(parts[3]),
+			softening:  softening,
+		})
+	}
+
+	return tests
+}
+
+
+// TestUpdateUniverseInPlace tests that updating a universe in place gives the same universes as UpdateUniverse.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestUpdateUniverseInPlace(t *testing.T) {
+	tests := ReadUpdateUniverseInPlace("../Tests/UpdateUniverseInPlace.txt")
+
+	for _, test := range tests {
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		expected := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+		result := nbody.CopyUniverse(expected)
+
+		options := DefaultOptions(3, 2e14, 0.5)
+		options.Integrator = test.integrator
+		options.Boundary = test.boundary
+		options.Softening = test.softening
+
+		for step := 1; step <= options.NumGens; step++ {
+			var err error
+			expected, err = UpdateUniverse(expected, quadtree.BuildQuadTree(expected, options.Build()), options)
+			nbody.Check(err)
+			nbody.Check(UpdateUniverseInPlace(result, quadtree.BuildQuadTree(result, options.Build()), options))
+
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("TestUpdateUniverseInPlace(test %v, step %d) differs from UpdateUniverse", test.id, step)
+			}
+		}
+	}
+}
+
+
+type CoincidentStarsTestCases struct {
+	id        string
+	universe  *nbody.Universe
+	softening float64
+	buckets   []int
+}
+
+
+// ReadCoincidentStars reads test data for universes with coincident stars from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of CoincidentStarsTestCases structs containing the universes and the expected bucket sizes.
+func ReadCoincidentStars(fileName string) []CoincidentStarsTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []CoincidentStarsTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+
+		width, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		nbody.Check(err)
+		softening, err := strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+		nbody.Check(err)
+
+		u := &nbody.Universe{Width: width}
+		for _, star := range strings.Split(parts[3], ",") {
+			fields := strings.Fields(star)
+			var nums [3]float64
+			for i := range nums {
+				nums[i], err = strconv.ParseFloat(fields[i], 64)
+				nbody.Check(err)
+			}
+			u.Stars = append(u.Stars, &nbody.Star{Position: nbody.OrderedPair{X: nums[0], Y: nums[1]}, Mass: nums[2]})
+		}
+
+		var buckets []int
+		if text := strings.TrimSpace(parts[4]); text != "-" {
+			for _, field := range strings.Split(text, ",") {
+				n, err := strconv.Atoi(strings.TrimSpace(field))
+				nbody.Check(err)
+				buckets = append(buckets, n)
+			}
+		}
+
+		tests = append(tests, CoincidentStarsTestCases{
+			id:        strings.TrimSpace(parts[0]),
+			universe:  u,
+			softening: softening,
+			buckets:   buckets,
+		})
+	}
+
+	return tests
+}
+
+
+// TreeBuckets returns the size of every bucket below node in depth-first order and the depth of the deepest node.
+func TreeBuckets(node *quadtree.Node, depth int) ([]int, int) {
+	var buckets []int
+	if node.Bucket != nil {
+		buckets = append(buckets, len(node.Bucket))
+	}
+	deepest := depth
+	for _, child := range node.Children {
+		b, d := TreeBuckets(child, depth+1)
+		buckets = append(buckets, b...)
+		deepest = max(deepest, d)
+	}
+	return buckets, deepest
+}
+
+
+// TestCoincidentStars tests that coincident stars end in a bucket at the maximum depth instead of subdividing forever,
+// and that their forces are computed pairwise.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestCoincidentStars(t *testing.T) {
+	tests := ReadCoincidentStars("../Tests/CoincidentStars.txt")
+
+	for _, test := range tests {
+		tree := quadtree.GenerateQuadTree(test.universe, 1)
+
+		buckets, deepest := TreeBuckets(tree.Root(), 0)
+		if !reflect.DeepEqual(buckets, test.buckets) || deepest > quadtree.MaxTreeDepth {
+			t.Errorf("TestCoincidentStars(test %v) has buckets %v and depth %d, want %v and at most %d",
+				test.id, buckets, deepest, test.buckets, quadtree.MaxTreeDepth)
+		}
+
+		options := DefaultOptions(1, 1, 0)
+		options.Softening = test.softening
+		result, err := ComputeAccelerations(test.universe, tree, options)
+		nbody.Check(err)
+		expected := DirectAccelerations(test.universe, options)
+
+		for i := range result {
+			scale := math.Hypot(expected[i].X, expected[i].Y)
+			if math.Hypot(result[i].X-expected[i].X, result[i].Y-expected[i].Y) > 1e-9*scale {
+				t.Errorf("TestCoincidentStars(test %v, star %d) = %v, want %v", test.id, i, result[i], expected[i])
+			}
+		}
+	}
+}
+
+
+type PinnedStarsTestCases struct {
+	id         string
+	csv        string
+	integrator string
+	force      string
+	numGens    int
+	period     float64
+	maxError   float64 // largest distance of a moving star from its start after one period, relative to its orbit
+}
+
+
+// ReadPinnedStars reads test data for pinned stars from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of PinnedStarsTestCases structs containing the universes, the runs, and the expected accuracy.
+func ReadPinnedStars(fileName string) []PinnedStarsTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []PinnedStarsTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		run := strings.Fields(parts[2])
+		if len(run) != 4 {
+			continue
+		}
+		test := PinnedStarsTestCases{
+			id:         strings.TrimSpace(parts[0]),
+			csv:        strings.ReplaceAll(parts[1], ";", "\n"),
+			integrator: run[0],
+			force:      run[1],
+		}
+		test.numGens, err = strconv.Atoi(run[2])
+		nbody.Check(err)
+		test.period, err = strconv.ParseFloat(run[3], 64)
+		nbody.Check(err)
+		test.maxError, err = strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
+		nbody.Check(err)
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestPinnedStars tests that pinned stars never move, whatever their velocity, and that they pull the other stars:
+// a star on a circular orbit around a pinned star of the same mass must be back at its start after one period of
+// the orbit around a fixed mass.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestPinnedStars(t *testing.T) {
+	tests := ReadPinnedStars("../Tests/PinnedStars.txt")
+	for _, test := range tests {
+		u, err := nbody.ReadUniverseCSV(strings.NewReader(test.csv))
+		nbody.Check(err)
+		dt := test.period / float64(test.numGens)
+
+		// the first step kicks the moving stars by half of their acceleration first (see IntegrateInPlace)
+		start := nbody.CopyUniverse(u)
+		for _, s := range u.Stars {
+			if !s.Pinned {
+				a := DirectAcceleration(s, u.Stars, 0, nbody.Newtonian{})
+				s.Velocity.X -= a.X * dt / 2
+				s.Velocity.Y -= a.Y * dt / 2
+			}
+		}
+
+		options := DefaultOptions(test.numGens, dt, 0.5).WithForceMode(test.force)
+		options.Integrator = test.integrator
+		final := BarnesHut(u, options)[test.numGens]
+
+		for i, s := range final.Stars {
+			want := start.Stars[i]
+			if s.Pinned != want.Pinned {
+				t.Errorf("TestPinnedStars(test %v): star %d pinned = %v, want %v", test.id, i, s.Pinned, want.Pinned)
+				continue
+			}
+			if s.Pinned {
+				if s.Position != want.Position || s.Velocity != want.Velocity {
+					t.Errorf("TestPinnedStars(test %v): pinned star %d moved to %v with velocity %v", test.id, i, s.Position, s.Velocity)
+				}
+				continue
+			}
+			_, _, orbit := nbody.Distance(want.Position, start.Stars[0].Position)
+			if _, _, d := nbody.Distance(s.Position, want.Position); d > test.maxError*orbit {
+				t.Errorf("TestPinnedStars(test %v): star %d is %v from its start, want at most %v", test.id, i, d, test.maxError*orbit)
+			}
+		}
+	}
+}
+
+
+// BenchmarkUpdateUniverse times one generation of a galaxy, the tree build and the update of all stars with the
+// default options and theta 0.5, for every size of nbody.BenchmarkSizes. Every generation starts from the same universe.
+func BenchmarkUpdateUniverse(b *testing.B) {
+	options := DefaultOptions(1, 2e14, 0.5)
+	for _, n := range nbody.BenchmarkSizes {
+		b.Run(fmt.Sprintf("N=%d", n), func(b *testing.B) {
+			u := nbody.BenchmarkUniverse(n)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, err := UpdateUniverse(u, quadtree.BuildQuadTree(u, options.Build()), options)
+				nbody.Check(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the opening criteria in quadtree/mac.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"bufio"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type OpeningCriterionTestCases struct {
+	id        string
+	mac       string
+	theta     float64
+	tolerance float64
+	numStars  int
+	maxError  float64
+}
+
+
+// ReadOpeningCriterion reads test data for the opening criteria from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of OpeningCriterionTestCases structs containing the criterion, its parameters, and the largest error.
+func ReadOpeningCriterion(fileName string) []OpeningCriterionTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []OpeningCriterionTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 6 {
+			continue
+		}
+
+		var nums [3]float64
+		for i, part := range []string{parts[2], parts[3], parts[5]} {
+			nums[i], err = strconv.ParseFloat(strings.TrimSpace(part), 64)
+			nbody.Check(err)
+		}
+		numStars, err := strconv.Atoi(strings.TrimSpace(parts[4]))
+		nbody.Check(err)
+
+		tests = append(tests, OpeningCriterionTestCases{
+			id:        strings.TrimSpace(parts[0]),
+			mac:       strings.TrimSpace(parts[1]),
+			theta:     nums[0],
+			tolerance: nums[1],
+			numStars:  numStars,
+			maxError:  nums[2],
+		})
+	}
+
+	return tests
+}
+
+
+// TestOpeningCriterion tests that the tree forces under every opening criterion stay close to direct summation.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestOpeningCriterion(t *testing.T) {
+	tests := ReadOpeningCriterion("../Tests/OpeningCriterion.txt")
+
+	for _, test := range tests {
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		u := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+
+		direct := DirectAccelerations(u, DefaultOptions(1, 2e14, 0))
+		mean := 0.0
+		for _, a := range direct {
+			mean += math.Hypot(a.X, a.Y) / float64(len(direct))
+		}
+
+		options := DefaultOptions(1, 2e14, test.theta).WithMAC(test.mac, test.tolerance*mean)
+		result, err := ComputeAccelerations(u, quadtree.BuildQuadTree(u, options.Build()), options)
+		nbody.Check(err)
+
+		sum := 0.0
+		for i := range result {
+			dx, dy := result[i].X-direct[i].X, result[i].Y-direct[i].Y
+			sum += dx*dx + dy*dy
+		}
+		rms := math.Sqrt(sum/float64(len(result))) / mean
+
+		if rms > test.maxError {
+			t.Errorf("TestOpeningCriterion(test %v) = error %.3e, want at most %.0e", test.id, rms, test.maxError)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Merging of colliding stars (option "-merge").
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"fmt"
+	"math"
+)
+
+// Two stars collide when their distance is below mergeScale times the sum of their radii (Options.MergeScale).
+// The radii of the scenarios are the real ones, far too small for stars to ever touch at galactic distances,
+// so the scale must be large: with the drawing scale (-scale), stars merge when their drawn discs touch.
+// A collision merges the stars into one (see MergeStars); several stars touching each other merge at once.
+
+// StarsNear calls visit for every star of a quadtree within distance r of a point.
+// Subtrees whose sector is farther than r from the point are skipped.
+// Input:
+//   - node: pointer to the root Node of the tree.
+//   - p: the point.
+//   - r: the distance.
+//   - visit: function called with every star found.
+// Output:
+//   - None.
+func StarsNear(node *quadtree.Node, p nbody.OrderedPair, r float64, visit func(s *nbody.Star)) {
+	if node == nil || node.Star == nil {
+		return
+	}
+
+	// distance from the point to the sector of the node
+	dx := math.Max(0, math.Max(node.Sector.X-p.X, p.X-(node.Sector.X+node.Sector.Width)))
+	dy := math.Max(0, math.Max(node.Sector.Y-p.Y, p.Y-(node.Sector.Y+node.Sector.Width)))
+	if dx*dx+dy*dy > r*r {
+		return
+	}
+
+	if quadtree.IsLeaf(node) {
+		for _, s := range quadtree.LeafStars(node) {
+			if _, _, d := nbody.Distance(s.Position, p); d <= r {
+				visit(s)
+			}
+		}
+		return
+	}
+	for _, child := range node.Children {
+		StarsNear(child, p, r, visit)
+	}
+}
+
+
+// FindCollisions groups the stars of a universe that collide, directly or through other stars.
+// Only stars inside the universe, which are in the tree, can collide; tracers are not in the tree and never do.
+// Input:
+//   - u: pointer to the Universe.
+//   - tree: pointer to the QuadTree of u.
+//   - scale: stars collide when closer than scale times the sum of their radii.
+// Output:
+//   - the groups of colliding stars as indices into u.Stars, every group in increasing order and the groups
+//     ordered by their first index.
+func FindCollisions(u *nbody.Universe, tree *quadtree.QuadTree, scale float64) [][]int {
+	index := make(map[*nbody.Star]int, len(u.Stars))
+	maxRadius := 0.0
+	for i, s := range u.Stars {
+		index[s] = i
+		maxRadius = math.Max(maxRadius, s.Radius)
+	}
+
+	// union-find of the colliding pairs, with the smallest index as the root of every group
+	parent := make([]int, len(u.Stars))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	root := tree.Root()
+	width, height := u.Bounds()
+	for i, s := range u.Stars {
+		if !nbody.IsInsideUniverse(s, width, height) || nbody.IsTracer(s) {
+			continue
+		}
+		StarsNear(root, s.Position, scale*(s.Radius+maxRadius), func(other *nbody.Star) {
+			j, ok := index[other]
+			if !ok || j <= i {
+				return
+			}
+			if _, _, d := nbody.Distance(s.Position, other.Position); d < scale*(s.Radius+other.Radius) {
+				a, b := find(i), find(j)
+				parent[max(a, b)] = min(a, b)
+			}
+		})
+	}
+
+	groups := map[int][]int{}
+	var roots []int
+	for i := range u.Stars {
+		root := find(i)
+		if root == i {
+			roots = append(roots, i)
+		}
+		groups[root] = append(groups[root], i)
+	}
+
+	var collisions [][]int
+	for _, root := range roots {
+		if len(groups[root]) > 1 {
+			collisions = append(collisions, groups[root])
+		}
+	}
+	return collisions
+}
+
+
+// MergeStars merges stars into one, conserving their mass and momentum.
+// The merged star is at their center of mass with the velocity of their center of mass, and its acceleration is
+// the mass-weighted mean, so the force on the stars is kept until the next force computation. Its radius keeps
+// the volume of the stars (r^3 adds up), and its color and age are the mass-weighted means. It keeps the
+// largest softening length and the galaxy and ID of the heaviest star (see Star.Galaxy and Star.ID). If one of the stars is pinned, the merged star is pinned in its place and at rest,
+// as a pinned star absorbs what falls into it.
+// Input:
+//   - stars: the Stars to merge (at least one).
+// Output:
+//   - pointer to a new Star.
+func MergeStars(stars []*nbody.Star) *nbody.Star {
+	merged := &nbody.Star{ID: stars[0].ID}
+	var red, green, blue, volume, heaviest float64
+	for _, s := range stars {
+		if s.Mass > heaviest {
+			merged.Galaxy, merged.ID, heaviest = s.Galaxy, s.ID, s.Mass
+		}
+		merged.Mass += s.Mass
+		merged.Position.X += s.Mass * s.Position.X
+		merged.Position.Y += s.Mass * s.Position.Y
+		merged.Velocity.X += s.Mass * s.Velocity.X
+		merged.Velocity.Y += s.Mass * s.Velocity.Y
+		merged.Acceleration.X += s.Mass * s.Acceleration.X
+		merged.Acceleration.Y += s.Mass * s.Acceleration.Y
+		merged.Age += s.Mass * s.Age
+		merged.Softening = math.Max(merged.Softening, s.Softening)
+		red += s.Mass * float64(s.Red)
+		green += s.Mass * float64(s.Green)
+		blue += s.Mass * float64(s.Blue)
+		volume += s.Radius * s.Radius * s.Radius
+	}
+
+	m := merged.Mass
+	merged.Position = nbody.OrderedPair{X: merged.Position.X / m, Y: merged.Position.Y / m}
+	merged.Velocity = nbody.OrderedPair{X: merged.Velocity.X / m, Y: merged.Velocity.Y / m}
+	merged.Acceleration = nbody.OrderedPair{X: merged.Acceleration.X / m, Y: merged.Acceleration.Y / m}
+	merged.Age /= m
+	merged.Radius = math.Cbrt(volume)
+	merged.Red = uint8(math.Round(red / m))
+	merged.Green = uint8(math.Round(green / m))
+	merged.Blue = uint8(math.Round(blue / m))
+
+	for _, s := range stars {
+		if s.Pinned {
+			merged.Pinned = true
+			merged.Position, merged.Velocity, merged.Acceleration = s.Position, s.Velocity, nbody.OrderedPair{}
+			break
+		}
+	}
+
+	return merged
+}
+
+
+// MergeCollisions merges every group of colliding stars of a universe into one star, which takes the place of the
+// star with the smallest index; the other stars are removed. Every merger is written to the event log of the run.
+// Input:
+//   - u: pointer to the Universe.
+//   - options: the Options of the run; stars collide when closer than options.MergeScale times the sum of their
+//     radii, the tree finding them is built like the trees of the forces, and the mergers are written to
+//     options.Log().
+//   - generation: the generation of u, for the log.
+// Output:
+//   - the new index of every old star, as returned by Universe.RemoveStars (nil if no stars merged).
+func MergeCollisions(u *nbody.Universe, options Options, generation int) []int {
+	collisions := FindCollisions(u, quadtree.BuildQuadTree(u, options.Build()), options.MergeScale)
+	if len(collisions) == 0 {
+		return nil
+	}
+
+	merged := make(map[int]bool)
+	for _, group := range collisions {
+		stars := make([]*nbody.Star, len(group))
+		for k, i := range group {
+			stars[k] = u.Stars[i]
+			if k > 0 {
+				merged[i] = true
+			}
+		}
+		u.Stars[group[0]] = MergeStars(stars)
+		fmt.Fprintf(options.Log(), "generation %d: merged stars %v into one star of mass %.3e kg\n",
+			generation, group, u.Stars[group[0]].Mass)
+	}
+
+	return u.RemoveStars(func(i int, s *nbody.Star) bool { return merged[i] })
+}
@@ -0,0 +1,165 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for merging colliding stars in merge.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"bufio"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type MergeCollisionsTestCases struct {
+	id     string
+	scale  float64
+	stars  []*nbody.Star
+	groups [][]int
+}
+
+
+// ReadMergeCollisions reads test data for FindCollisions and MergeCollisions from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of MergeCollisionsTestCases structs containing the stars and the expected groups.
+func ReadMergeCollisions(fileName string) []MergeCollisionsTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []MergeCollisionsTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		scale, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		nbody.Check(err)
+
+		var stars []*nbody.Star
+		for _, text := range strings.Split(parts[2], ";") {
+			fields := strings.Fields(text)
+			var nums [6]float64
+			for i := range nums {
+				nums[i], err = strconv.ParseFloat(fields[i], 64)
+				nbody.Check(err)
+			}
+			stars = append(stars, &nbody.Star{
+				Position: nbody.OrderedPair{X: nums[0], Y: nums[1]},
+				Velocity: nbody.OrderedPair{X: nums[2], Y: nums[3]},
+				Mass:     nums[4],
+				Radius:   nums[5],
+				ID:       int64(100 + len(stars)),
+			})
+		}
+
+		var groups [][]int
+		if text := strings.TrimSpace(parts[3]); text != "-" {
+			for _, groupText := range strings.Split(text, ";") {
+				var group []int
+				for _, field := range strings.Split(groupText, ",") {
+					i, err := strconv.Atoi(strings.TrimSpace(field))
+					nbody.Check(err)
+					group = append(group, i)
+				}
+				groups = append(groups, group)
+			}
+		}
+
+		tests = append(tests, MergeCollisionsTestCases{
+			id:     strings.TrimSpace(parts[0]),
+			scale:  scale,
+			stars:  stars,
+			groups: groups,
+		})
+	}
+
+	return tests
+}
+
+
+// TestMergeCollisions tests that FindCollisions finds the expected groups and that MergeCollisions replaces
+// every group by one star, conserving the total mass and momentum, with the ID of the heaviest star.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestMergeCollisions(t *testing.T) {
+	tests := ReadMergeCollisions("../Tests/MergeCollisions.txt")
+
+	for _, test := range tests {
+		u := &nbody.Universe{Width: 100, Stars: append([]*nbody.Star(nil), test.stars...)}
+
+		options := DefaultOptions(1, 1, 0).WithEventLog(io.Discard)
+		options.MergeScale = test.scale
+		groups := FindCollisions(u, quadtree.BuildQuadTree(u, options.Build()), test.scale)
+		if !reflect.DeepEqual(groups, test.groups) {
+			t.Errorf("FindCollisions(test %v) = %v, want %v", test.id, groups, test.groups)
+			continue
+		}
+
+		var mass, px, py float64
+		for _, s := range u.Stars {
+			mass += s.Mass
+			px += s.Mass * s.Velocity.X
+			py += s.Mass * s.Velocity.Y
+		}
+
+		numStars := len(u.Stars)
+		for _, group := range groups {
+			numStars -= len(group) - 1
+		}
+		newIndex := MergeCollisions(u, options, 0)
+		if (newIndex == nil) != (len(groups) == 0) || len(u.Stars) != numStars {
+			t.Errorf("MergeCollisions(test %v) left %d stars, want %d", test.id, len(u.Stars), numStars)
+			continue
+		}
+
+		var newMass, newPx, newPy float64
+		for _, s := range u.Stars {
+			newMass += s.Mass
+			newPx += s.Mass * s.Velocity.X
+			newPy += s.Mass * s.Velocity.Y
+		}
+		if math.Abs(newMass-mass) > 1e-12*mass || math.Abs(newPx-px) > 1e-12 || math.Abs(newPy-py) > 1e-12 {
+			t.Errorf("MergeCollisions(test %v) changed mass and momentum from %v (%v, %v) to %v (%v, %v)",
+				test.id, mass, px, py, newMass, newPx, newPy)
+		}
+
+		// a merged star keeps the volume of its stars
+		for _, group := range groups {
+			volume := 0.0
+			for _, i := range group {
+				volume += math.Pow(test.stars[i].Radius, 3)
+			}
+			merged := u.Stars[newIndex[group[0]]]
+			if math.Abs(merged.Radius-math.Cbrt(volume)) > 1e-12 {
+				t.Errorf("MergeCollisions(test %v) gave radius %v, want %v", test.id, merged.Radius, math.Cbrt(volume))
+			}
+
+			// and the ID of its heaviest star
+			heaviest := test.stars[group[0]]
+			for _, i := range group {
+				if test.stars[i].Mass > heaviest.Mass {
+					heaviest = test.stars[i]
+				}
+			}
+			if merged.ID != heaviest.ID {
+				t.Errorf("MergeCollisions(test %v) gave ID %d, want %d", test.id, merged.ID, heaviest.ID)
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Linear and angular momentum of the saved generations of a run, with warnings when they drift
+// (option "-momentum-check").
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// WarningOutput receives the warnings of a run, such as momentum drifts.
+var WarningOutput io.Writer = os.Stderr
+
+// Momentum holds the total linear and angular momentum of a universe and their natural scales,
+// the sums of m|v| and of m|r x v|, which the drifts are measured against.
+type Momentum struct {
+	linear       nbody.OrderedPair
+	angular      float64
+	linearScale  float64
+	angularScale float64
+}
+
+
+// MeasureMomentum computes the total linear momentum and the total angular momentum around a fixed point.
+// Without external forces and events both are conserved, whatever point is chosen.
+// Input:
+//   - u: pointer to the Universe.
+//   - center: the point the angular momentum is measured around (usually the initial center of mass).
+// Output:
+//   - the Momentum.
+func MeasureMomentum(u *nbody.Universe, center nbody.OrderedPair) Momentum {
+	var m Momentum
+	for _, s := range u.Stars {
+		rx, ry := s.Position.X-center.X, s.Position.Y-center.Y
+		l := s.Mass * (rx*s.Velocity.Y - ry*s.Velocity.X)
+		m.linear.X += s.Mass * s.Velocity.X
+		m.linear.Y += s.Mass * s.Velocity.Y
+		m.angular += l
+		m.linearScale += s.Mass * math.Hypot(s.Velocity.X, s.Velocity.Y)
+		m.angularScale += math.Abs(l)
+	}
+	return m
+}
+
+
+// MomentumDrift returns the drifts of the linear and angular momentum from a reference,
+// relative to the scales of the reference (0 if a scale is 0).
+func MomentumDrift(reference, current Momentum) (float64, float64) {
+	linear, angular := 0.0, 0.0
+	if reference.linearScale > 0 {
+		linear = math.Hypot(current.linear.X-reference.linear.X, current.linear.Y-reference.linear.Y) / reference.linearScale
+	}
+	if reference.angularScale > 0 {
+		angular = math.Abs(current.angular-reference.angular) / reference.angularScale
+	}
+	return linear, angular
+}
+
+
+// MomentumRecord is the momentum of one saved generation and its drifts from the first generation.
+type MomentumRecord struct {
+	generation   int
+	momentum     Momentum
+	linearDrift  float64
+	angularDrift float64
+}
+
+
+// MomentumMonitor tracks the momentum of the saved generations of a run and warns when a drift exceeds threshold.
+// Every quantity is warned about once, when its drift first exceeds the threshold.
+type MomentumMonitor struct {
+	interval  int     // generations between two records (the first and the last generation are always recorded)
+	numGens   int     // the last generation of the run
+	threshold float64 // largest relative drift without a warning
+
+	center    nbody.OrderedPair // the initial center of mass
+	reference Momentum    // momentum of the first generation
+	records   []MomentumRecord
+	Warnings  []string
+	warned    [2]bool // whether the linear and the angular momentum were warned about
+}
+
+
+// NewMomentumMonitor returns a MomentumMonitor recording every interval-th generation of a run of numGens generations.
+func NewMomentumMonitor(interval, numGens int, threshold float64) *MomentumMonitor {
+	return &MomentumMonitor{interval: interval, numGens: numGens, threshold: threshold}
+}
+
+
+// Hook returns the hook for Options.WithHook that records the momentum and writes the warnings to WarningOutput.
+func (m *MomentumMonitor) Hook() func(generation int, u *nbody.Universe) {
+	return func(generation int, u *nbody.Universe) {
+		if len(m.records) == 0 {
+			m.center = nbody.CenterOfMass(u.Stars)
+			m.reference = MeasureMomentum(u, m.center)
+		} else if generation%m.interval != 0 && generation != m.numGens {
+			return
+		}
+
+		r := MomentumRecord{generation: generation, momentum: MeasureMomentum(u, m.center)}
+		r.linearDrift, r.angularDrift = MomentumDrift(m.reference, r.momentum)
+		m.records = append(m.records, r)
+
+		for i, drift := range []float64{r.linearDrift, r.angularDrift} {
+			if drift > m.threshold && !m.warned[i] {
+				m.warned[i] = true
+				warning := fmt.Sprintf("generation %d: %s drifted by %.3e, more than %.3e",
+					generation, []string{"linear momentum", "angular momentum"}[i], drift, m.threshold)
+				m.Warnings = append(m.Warnings, warning)
+				fmt.Fprintln(WarningOutput, "Warning:", warning)
+			}
+		}
+	}
+}
+
+
+// MaxDrift returns the largest drifts of the linear and the angular momentum recorded so far.
+func (m *MomentumMonitor) MaxDrift() (float64, float64) {
+	linear, angular := 0.0, 0.0
+	for _, r := range m.records {
+		linear = math.Max(linear, r.linearDrift)
+		angular = math.Max(angular, r.angularDrift)
+	}
+	return linear, angular
+}
+
+
+// WriteReport writes the momentum of every recorded generation and the largest drifts.
+// Input:
+//   - w: destination of the report.
+// Output:
+//   - an error if writing failed.
+func (m *MomentumMonitor) WriteReport(w io.Writer) error {
+	fmt.Fprintln(w, "# generation px py angular linear_drift angular_drift")
+	for _, r := range m.records {
+		fmt.Fprintf(w, "%d %e %e %e %e %e\n", r.generation, r.momentum.linear.X, r.momentum.linear.Y,
+			r.momentum.angular, r.linearDrift, r.angularDrift)
+	}
+	linear, angular := m.MaxDrift()
+	_, err := fmt.Fprintf(w, "# largest drift: linear momentum %e, angular momentum %e\n", linear, angular)
+	return err
+}
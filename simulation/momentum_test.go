@@ -0,0 +1,138 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the momentum diagnostics in momentum.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type MomentumMonitorTestCases struct {
+	id          string
+	numStars    int
+	numGens     int
+	interval    int
+	threshold   float64
+	events      []Event
+	generations []int
+	warnings    []string
+}
+
+
+// ReadMomentumMonitor reads test data for the MomentumMonitor from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of MomentumMonitorTestCases structs containing the run and the expected records and warnings.
+func ReadMomentumMonitor(fileName string) []MomentumMonitorTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []MomentumMonitorTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 8 {
+			continue
+		}
+
+		var numbers [3]int
+		for i := range numbers {
+			numbers[i], err = strconv.Atoi(strings.TrimSpace(parts[i+1]))
+			nbody.Check(err)
+		}
+		threshold, err := strconv.ParseFloat(strings.TrimSpace(parts[4]), 64)
+		nbody.Check(err)
+
+		var events []Event
+		if text := strings.TrimSpace(parts[5]); text != "-" {
+			for _, eventLine := range strings.Split(text, ";") {
+				e, err := ParseEvent(strings.TrimSpace(eventLine))
+				nbody.Check(err)
+				events = append(events, e)
+			}
+		}
+
+		var generations []int
+		for _, field := range strings.Split(parts[6], ",") {
+			g, err := strconv.Atoi(strings.TrimSpace(field))
+			nbody.Check(err)
+			generations = append(generations, g)
+		}
+
+		var warnings []string
+		if text := strings.TrimSpace(parts[7]); text != "-" {
+			for _, field := range strings.Split(text, ",") {
+				warnings = append(warnings, strings.TrimSpace(field))
+			}
+		}
+
+		tests = append(tests, MomentumMonitorTestCases{
+			id:          strings.TrimSpace(parts[0]),
+			numStars:    numbers[0],
+			numGens:     numbers[1],
+			interval:    numbers[2],
+			threshold:   threshold,
+			events:      events,
+			generations: generations,
+			warnings:    warnings,
+		})
+	}
+
+	return tests
+}
+
+
+// TestMomentumMonitor tests that the monitor records the expected generations and warns exactly about the
+// quantities that events change.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestMomentumMonitor(t *testing.T) {
+	tests := ReadMomentumMonitor("../Tests/MomentumMonitor.txt")
+	WarningOutput = io.Discard
+	defer func() { WarningOutput = os.Stderr }()
+
+	for _, test := range tests {
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		u := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+		options := DefaultOptions(test.numGens, 2e14, 0.5).WithEventLog(io.Discard)
+		options.Events = test.events
+
+		monitor := NewMomentumMonitor(test.interval, test.numGens, test.threshold)
+		nbody.Check(RunGenerations(u, options.WithHook(monitor.Hook())))
+
+		var generations []int
+		for _, r := range monitor.records {
+			generations = append(generations, r.generation)
+		}
+		if !reflect.DeepEqual(generations, test.generations) {
+			t.Errorf("TestMomentumMonitor(test %v) recorded generations %v, want %v", test.id, generations, test.generations)
+		}
+
+		var warnings []string
+		for _, w := range monitor.Warnings {
+			if strings.Contains(w, "linear momentum") {
+				warnings = append(warnings, "linear")
+			} else if strings.Contains(w, "angular momentum") {
+				warnings = append(warnings, "angular")
+			}
+		}
+		if !reflect.DeepEqual(warnings, test.warnings) {
+			t.Errorf("TestMomentumMonitor(test %v) warned about %v, want %v (%v)", test.id, warnings, test.warnings, monitor.Warnings)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the Morton order of the stars in quadtree/morton.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type MortonOrderTestCases struct {
+	id          string
+	numStars    int
+	treeBuild   string
+	leafSize    int
+	generations int
+}
+
+
+// ReadMortonOrder reads test data for the -morton option from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of MortonOrderTestCases structs containing the runs.
+func ReadMortonOrder(fileName string) []MortonOrderTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []MortonOrderTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+		build := strings.Fields(parts[2])
+		if len(build) != 2 {
+			continue
+		}
+
+		test := MortonOrderTestCases{id: strings.TrimSpace(parts[0]), treeBuild: build[0]}
+		test.numStars, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		test.leafSize, err = strconv.Atoi(build[1])
+		nbody.Check(err)
+		test.generations, err = strconv.Atoi(strings.TrimSpace(parts[3]))
+		nbody.Check(err)
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestMortonOrder tests that MortonOrder sorts the stars by their codes, and that runs with -morton match runs
+// without it for every tree build.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestMortonOrder(t *testing.T) {
+	tests := ReadMortonOrder("../Tests/MortonOrder.txt")
+	for _, test := range tests {
+		nbody.SetDeterministic()
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		u := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+		options := DefaultOptions(1, 2e14, 0.5).WithTreeBuild(test.treeBuild).WithLeafSize(test.leafSize)
+
+		// the order is a permutation of the stars with increasing codes
+		order := quadtree.MortonOrder(u.Stars, u.Width)
+		seen := make([]bool, len(u.Stars))
+		for k, i := range order {
+			if seen[i] {
+				t.Errorf("TestMortonOrder(test %v): star %d appears twice in the order", test.id, i)
+				continue
+			}
+			seen[i] = true
+			if k > 0 && quadtree.MortonCode(u.Stars[order[k-1]].Position, u.Width) > quadtree.MortonCode(u.Stars[i].Position, u.Width) {
+				t.Errorf("TestMortonOrder(test %v): codes decrease at position %d of the order", test.id, k)
+			}
+		}
+
+		expected, result := u, nbody.CopyUniverse(u)
+		for gen := 0; gen < test.generations; gen++ {
+			var err error
+			expected, err = UpdateUniverse(expected, quadtree.BuildQuadTree(expected, options.Build()), options)
+			nbody.Check(err)
+			tree := quadtree.BuildQuadTree(result, options.WithMorton(true).Build())
+			if fmt.Sprint(tree.Order) != fmt.Sprint(quadtree.MortonOrder(result.Stars, result.Width)) {
+				t.Errorf("TestMortonOrder(test %v, generation %d): the tree does not keep the Morton order", test.id, gen)
+			}
+			result, err = UpdateUniverse(result, tree, options)
+			nbody.Check(err)
+		}
+
+		for i := range expected.Stars {
+			got, want := result.Stars[i].Position, expected.Stars[i].Position
+			if math.Hypot(got.X-want.X, got.Y-want.Y) > 1e-12*u.Width {
+				t.Errorf("TestMortonOrder(test %v, star %d) = %v, want %v", test.id, i, got, want)
+			}
+		}
+	}
+}
+
+// BenchmarkMortonOrder times a generation of UpdateUniverse with the stars in the order of the universe and in
+// Morton order, for every size of nbody.BenchmarkSizes.
+// Run it with: go test -run '^$' -bench MortonOrder
+func BenchmarkMortonOrder(b *testing.B) {
+	for _, n := range nbody.BenchmarkSizes {
+		for _, morton := range []bool{false, true} {
+			b.Run(fmt.Sprintf("morton=%v/N=%d", morton, n), func(b *testing.B) {
+				u := nbody.BenchmarkUniverse(n)
+				options := DefaultOptions(1, 2e14, 0.5).WithMorton(morton)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					_, err := UpdateUniverse(u, quadtree.BuildQuadTree(u, options.Build()), options)
+					nbody.Check(err)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,504 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Options controlling a simulation run, passed to BarnesHut and RunGenerations.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Options collects everything that controls how a universe is simulated, so that new features
+// add a field instead of another parameter to BarnesHut and the functions it calls.
+// Every setting is an exported field whose zero value is its default, so Options{NumGens: n, TimeStep: dt} is a
+// valid run (DefaultOptions also logs the events to os.Stdout); the With methods set them on a copy.
+// Only what is attached to a run rather than set is unexported: its hooks, its context, and its external
+// potentials, which the With methods chain or append.
+type Options struct {
+	NumGens  int     // number of generations to simulate
+	// if > 1, BarnesHut keeps only every saveInterval-th generation and the last one (see SavedGenerations)
+	SaveInterval int
+	// generation of the initial universe, > 0 when a run is resumed from a checkpoint (see RunGenerations)
+	FirstGeneration int
+	TimeStep float64 // time interval of one generation, in seconds
+	Theta    float64 // opening threshold of the Barnes-Hut approximation
+	Substeps int     // number of integration steps of timeStep / substeps per generation (0 for 1)
+
+	// if > 0, the factor eta of the adaptive time step eta * sqrt(eps / a_max) (see AdaptiveTimeStep);
+	// timeStep / substeps is then the largest step
+	AdaptiveStep float64
+	BlockLevels  int // if > 0, stars use block time steps timeStep / 2^k with k up to blockLevels (see BlockStep)
+
+	Integrator string  // "verlet" (default) or "euler" (semi-implicit)
+	Softening  float64 // Plummer softening length in meters; 0 uses the bare 1/d^2 force
+	Boundary   string  // "open" (default): stars leave the universe; "reflect": stars bounce off the walls
+
+	AdaptiveSoftening int // if > 0, every star's softening is the distance to its adaptiveSoftening-th nearest neighbor
+	SofteningInterval int // number of generations between two updates of the adaptive softening lengths (0 for 10)
+
+	RemoveEscaped float64 // remove stars this fraction of the width outside the universe (0 keeps them)
+	MergeScale    float64 // merge stars closer than mergeScale times the sum of their radii (0 for none, see MergeCollisions)
+	Events        []Event // scripted events, sorted by generation
+	// receives one line per applied event and merger, none if nil (see Log, ApplyEvent, and MergeCollisions)
+	EventLog io.Writer
+	// external potentials whose forces are added to those of the stars, e.g. analytic halos (see StarAcceleration)
+	potentials []ExternalPotential
+	// dynamical friction or drag on the heaviest stars, such as black holes (see FrictionAcceleration)
+	Friction Friction
+
+	// goroutines computing the forces, or 0 for nbody.NumWorkers (see Workers)
+	NumWorkers int
+
+	// how the quadtree of every step is built and walked (see BuildQuadTree and CalculateNetForce)
+	TreeBuild    string   // "serial" (default), "partitioned", "flat", or "linear"
+	LeafSize     int      // largest number of stars in a leaf before it is subdivided (see InsertStar; 0 for 1)
+	TreeWalk     string   // "stack" (default) or "recursive"
+	TreeReuse    bool     // refit the tree of the previous step instead of building it again (see RefitQuadTree)
+	Morton       bool     // build and walk the tree in the Morton order of the stars (see MortonOrder)
+	MAC          string   // opening criterion of the walk: "bh" (default), "bmax", or "salmon-warren" (see AcceptSector)
+	MACTolerance float64  // acceleration error in m/s^2 accepted per node by "salmon-warren"
+	ForceMode    string   // "tree" (default) or "direct" summation over all pairs (see StarAcceleration)
+	Layout       string   // memory layout of direct summation: "aos" (default) or "soa" (see StarArrays)
+	ForceLaw     nbody.ForceLaw // law of the force between stars, Newtonian{} if nil (see Law)
+
+	// what a step does with non-finite values: "abort" (default), "clamp", or "off" (see GuardAccelerations)
+	FiniteCheck string
+	// clamped, if not nil, is called with every star whose non-finite acceleration was set to zero by "clamp"
+	clamped func(s *nbody.Star, acceleration nbody.OrderedPair)
+
+	// onGeneration, if not nil, is called with every generation, starting with a copy of the
+	// initial universe as generation 0.
+	onGeneration func(generation int, u *nbody.Universe)
+
+	// retain, if not nil, reports whether the hook keeps the universe of a generation after it returns.
+	// The universes of other generations are advanced in place (see UpdateUniverseInPlace) instead of copied.
+	Retain func(generation int) bool
+
+	// progress, if not nil, is called after every simulated generation (see ProgressFunc).
+	progress ProgressFunc
+
+	// ctx, if not nil, interrupts the run when it is cancelled: no generation is started after that.
+	ctx context.Context
+
+	// DebugOutput, if not nil, receives the quadtrees of the generations in DebugTrees (see PrintTree) and the
+	// force walk of star TraceStar in generation TraceGeneration (see TraceNetForce); a negative TraceStar traces none
+	DebugOutput     io.Writer
+	DebugTrees      map[int]bool
+	TraceStar       int
+	TraceGeneration int
+}
+
+// ProgressFunc reports the progress of a run to a host application (a GUI, a server, or the
+// command line), so that it does not have to parse the standard output.
+// generation counts the generations simulated so far, from 1 to total.
+type ProgressFunc func(generation, total int, stats ProgressStats)
+
+// ProgressStats describes the state of a run when a ProgressFunc is called. Its fields are exported, so a
+// ProgressFunc of a program embedding the simulator can read them.
+type ProgressStats struct {
+	Elapsed      time.Duration // time since the run started
+	LastStep     time.Duration // time taken by the last generation
+	Remaining    time.Duration // estimate of the time left, from the average time per generation
+	NumStars     int           // number of stars in the universe
+	NumTreeNodes int           // number of nodes in the quadtree of the last generation
+	Time         float64       // simulated time of the last generation, in seconds
+}
+
+
+// DefaultOptions returns the Options of a run with the given length, time step, and theta,
+// using one step per generation, the velocity Verlet integrator, no softening, open boundaries, and runs that stop
+// at the first non-finite value. The forces come from a serially built quadtree with one star per leaf, walked with
+// an explicit stack under the Barnes-Hut opening criterion.
+// Adaptive softening, when enabled, is updated every 10 generations.
+// Input:
+//   - numGens: number of generations.
+//   - timeStep: time interval of one generation.
+//   - theta: threshold parameter for Barnes-Hut approximation.
+// Output:
+//   - the Options.
+func DefaultOptions(numGens int, timeStep, theta float64) Options {
+	return Options{
+		NumGens:    numGens,
+		TimeStep:   timeStep,
+		Theta:      theta,
+		Substeps:   1,
+		Integrator: "verlet",
+		Boundary:   "open",
+
+		SofteningInterval: 10,
+		FiniteCheck:       "abort",
+
+		TreeBuild: "serial",
+		LeafSize:  1,
+		TreeWalk:  "stack",
+		MAC:       "bh",
+		ForceMode: "tree",
+		Layout:    "aos",
+		ForceLaw:  nbody.Newtonian{},
+		EventLog:  os.Stdout,
+	}
+}
+
+
+// withDefaults returns a copy of the Options whose zero settings are replaced by their defaults, as DefaultOptions
+// sets them. The event log and the force law keep nil, which Log and Law read as none and Newtonian{}.
+func (o Options) withDefaults() Options {
+	o.Substeps = cmp.Or(o.Substeps, 1)
+	o.SofteningInterval = cmp.Or(o.SofteningInterval, 10)
+	o.LeafSize = cmp.Or(o.LeafSize, 1)
+	o.Integrator = cmp.Or(o.Integrator, "verlet")
+	o.Boundary = cmp.Or(o.Boundary, "open")
+	o.FiniteCheck = cmp.Or(o.FiniteCheck, "abort")
+	o.TreeBuild = cmp.Or(o.TreeBuild, "serial")
+	o.TreeWalk = cmp.Or(o.TreeWalk, "stack")
+	o.MAC = cmp.Or(o.MAC, "bh")
+	o.ForceMode = cmp.Or(o.ForceMode, "tree")
+	o.Layout = cmp.Or(o.Layout, "aos")
+	return o
+}
+
+
+// Validate returns an error describing the first invalid field of the Options, or nil.
+// Zero settings stand for their defaults (see Options).
+func (o Options) Validate() error {
+	o = o.withDefaults()
+	switch {
+	case o.NumGens < 0:
+		return fmt.Errorf("number of generations must not be negative, got %d", o.NumGens)
+	case o.TimeStep <= 0:
+		return fmt.Errorf("time step must be positive, got %v", o.TimeStep)
+	case o.FirstGeneration < 0 || o.FirstGeneration > o.NumGens:
+		return fmt.Errorf("first generation must be between 0 and the number of generations %d, got %d", o.NumGens, o.FirstGeneration)
+	case o.SaveInterval < 0:
+		return fmt.Errorf("save interval must not be negative, got %d", o.SaveInterval)
+	case o.Substeps < 1:
+		return fmt.Errorf("number of substeps must be at least 1, got %d", o.Substeps)
+	case o.AdaptiveStep < 0:
+		return fmt.Errorf("adaptive time step factor must not be negative, got %v", o.AdaptiveStep)
+	case o.AdaptiveStep > 0 && o.Softening == 0 && o.AdaptiveSoftening == 0:
+		return fmt.Errorf("the adaptive time step needs a softening length (-softening or -adaptive-softening)")
+	case o.BlockLevels < 0 || o.BlockLevels > 20:
+		return fmt.Errorf("number of block time step levels must be between 0 and 20, got %d", o.BlockLevels)
+	case o.BlockLevels > 0 && o.AdaptiveStep == 0:
+		return fmt.Errorf("block time steps need the factor eta of -adaptive-dt")
+	case o.Theta < 0:
+		return fmt.Errorf("theta must not be negative, got %v", o.Theta)
+	case o.Softening < 0:
+		return fmt.Errorf("softening must not be negative, got %v", o.Softening)
+	case o.Integrator != "verlet" && o.Integrator != "euler":
+		return fmt.Errorf("unknown integrator %q (use verlet or euler)", o.Integrator)
+	case o.Boundary != "open" && o.Boundary != "reflect":
+		return fmt.Errorf("unknown boundary %q (use open or reflect)", o.Boundary)
+	case o.AdaptiveSoftening < 0:
+		return fmt.Errorf("number of softening neighbors must not be negative, got %d", o.AdaptiveSoftening)
+	case o.AdaptiveSoftening > 0 && o.SofteningInterval < 1:
+		return fmt.Errorf("softening interval must be at least 1, got %d", o.SofteningInterval)
+	case o.RemoveEscaped < 0:
+		return fmt.Errorf("escape margin must not be negative, got %v", o.RemoveEscaped)
+	case o.MergeScale < 0:
+		return fmt.Errorf("merge scale must not be negative, got %v", o.MergeScale)
+	case o.NumWorkers < 0:
+		return fmt.Errorf("number of workers must not be negative, got %d", o.NumWorkers)
+	case o.FiniteCheck != "abort" && o.FiniteCheck != "clamp" && o.FiniteCheck != "off":
+		return fmt.Errorf("unknown finite check %q (use abort, clamp, or off)", o.FiniteCheck)
+	case o.Friction.kind == "drag" && o.Friction.coefficient*o.TimeStep/float64(o.Substeps) > 1:
+		// a larger drag would reverse the velocities of the stars instead of slowing them down
+		return fmt.Errorf("drag of %v/s over the time step %v s of a substep exceeds 1 (use a smaller gamma, -dt, or more -substeps)",
+			o.Friction.coefficient, o.TimeStep/float64(o.Substeps))
+	}
+
+	// the tree and force settings have the checks of their command line options
+	for _, err := range []error{
+		quadtree.CheckTreeBuild(o.TreeBuild), quadtree.CheckLeafSize(o.LeafSize), quadtree.CheckTreeWalk(o.TreeWalk), quadtree.CheckTreeReuse(o.TreeReuse, o.TreeBuild),
+		quadtree.CheckMAC(o.MAC, o.MACTolerance), CheckForceMode(o.ForceMode), CheckLayout(o.Layout, o.ForceMode),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+
+// The With methods configure a run without adding parameters to DefaultOptions, e.g.
+//
+//	options := DefaultOptions(1000, 2e14, 0.5).WithSoftening(1e19).WithIntegrator("euler").WithWorkers(4)
+//
+// They return a copy, so one Options can be the start of several runs. Invalid values are reported by Validate
+// when the run starts.
+
+// WithTheta returns a copy of the Options with the opening threshold theta of the Barnes-Hut approximation.
+func (o Options) WithTheta(theta float64) Options {
+	o.Theta = theta
+	return o
+}
+
+
+// WithSoftening returns a copy of the Options with a Plummer softening length in meters (0 for the bare force).
+func (o Options) WithSoftening(softening float64) Options {
+	o.Softening = softening
+	return o
+}
+
+
+// WithIntegrator returns a copy of the Options integrating with "verlet" or "euler".
+func (o Options) WithIntegrator(integrator string) Options {
+	o.Integrator = integrator
+	return o
+}
+
+
+// WithSaveInterval returns a copy of the Options whose BarnesHut keeps only every interval-th generation and the
+// last one (see SavedGenerations); 0 or 1 keeps every generation.
+func (o Options) WithSaveInterval(interval int) Options {
+	o.SaveInterval = interval
+	return o
+}
+
+
+// WithWorkers returns a copy of the Options whose forces, parallel tree builds, and adaptive softening lengths are
+// computed on the given number of goroutines instead of nbody.NumWorkers (0 keeps nbody.NumWorkers).
+// The forces do not depend on it.
+func (o Options) WithWorkers(workers int) Options {
+	o.NumWorkers = workers
+	return o
+}
+
+
+// WithTreeBuild returns a copy of the Options whose quadtrees are built with the given strategy:
+//   - "serial": GenerateQuadTree, one star at a time.
+//   - "partitioned": GenerateQuadTreePartitioned, one goroutine per level-2 quadrant.
+//   - "flat": GenerateFlatTree, all goroutines insert into one flat tree without locks.
+//   - "linear": GenerateLinearTree, a pointer-free tree of the stars sorted by Morton code, with its own force walk.
+func (o Options) WithTreeBuild(build string) Options {
+	o.TreeBuild = build
+	return o
+}
+
+
+// WithLeafSize returns a copy of the Options whose quadtree leaves hold up to size stars before they are subdivided.
+// Larger leaves give shallower trees with fewer nodes, which are faster to build and to walk for clustered stars;
+// a leaf too close to accept pulls with each of its stars.
+func (o Options) WithLeafSize(size int) Options {
+	o.LeafSize = size
+	return o
+}
+
+
+// WithTreeWalk returns a copy of the Options whose force walk is "stack" (StackNetForce, a loop over an explicit
+// stack of the opened nodes) or "recursive" (WalkNetForce, one call per opened node). Both add the forces in the
+// same order, so they give identical forces; the trace of -trace-star always uses WalkNetForce.
+func (o Options) WithTreeWalk(walk string) Options {
+	o.TreeWalk = walk
+	return o
+}
+
+
+// WithTreeReuse returns a copy of the Options that refits the quadtree of the previous step to the stars of the
+// next one (RefitQuadTree) instead of building it from scratch, if reuse is true.
+func (o Options) WithTreeReuse(reuse bool) Options {
+	o.TreeReuse = reuse
+	return o
+}
+
+
+// WithMorton returns a copy of the Options that builds the quadtrees and walks the forces in the Morton order of
+// the stars (see MortonOrder), if morton is true.
+func (o Options) WithMorton(morton bool) Options {
+	o.Morton = morton
+	return o
+}
+
+
+// WithMAC returns a copy of the Options with the multipole acceptance criterion (MAC) of the force walk.
+// A node passing it pulls with its total mass at its center of mass; otherwise its children are visited.
+//   - "bh": the classic Barnes-Hut criterion s / d < theta, with s the width of the node and d the distance
+//     to its center of mass.
+//   - "bmax": Barnes' modified criterion d > s / theta + delta, with delta the distance between the center
+//     of mass and the center of the node, which guards against centers of mass close to a node's edge.
+//   - "salmon-warren": the error bound of Salmon and Warren. The node is accepted when the error of the
+//     monopole, 3 G B2 / (d^2 (d - bmax)^2), is below tolerance (in m/s^2), with B2 the second moment
+//     of the node's mass and bmax the largest distance of its stars from the center of mass.
+func (o Options) WithMAC(criterion string, tolerance float64) Options {
+	o.MAC, o.MACTolerance = criterion, tolerance
+	return o
+}
+
+
+// WithForceMode returns a copy of the Options computing the forces with "tree", the Barnes-Hut tree walk in
+// O(N log N), or "direct", the sum over all pairs of stars in O(N^2). Direct summation is exact up to rounding
+// and is used to validate the tree. Unlike the tree, it includes stars that have left the universe.
+func (o Options) WithForceMode(mode string) Options {
+	o.ForceMode = mode
+	return o
+}
+
+
+// WithLayout returns a copy of the Options whose direct summation reads the stars ("aos") or parallel arrays of
+// their positions and masses ("soa", see StarArrays).
+func (o Options) WithLayout(layout string) Options {
+	o.Layout = layout
+	return o
+}
+
+
+// WithForceLaw returns a copy of the Options whose stars pull each other with the given ForceLaw, such as a law
+// of ParseForceLaw or one of the program's own, in the tree walk and in direct summation.
+func (o Options) WithForceLaw(law nbody.ForceLaw) Options {
+	o.ForceLaw = law
+	return o
+}
+
+
+// WithForceSettings returns a copy of the Options that builds and walks its trees and computes its forces like
+// other: with its tree build, leaf size, tree walk and reuse, Morton order, opening criterion, force mode,
+// layout, and force law. Commands that set up runs of their own, such as selftest, apply their command line options with it.
+func (o Options) WithForceSettings(other Options) Options {
+	o.TreeBuild, o.LeafSize, o.TreeWalk, o.TreeReuse, o.Morton = other.TreeBuild, other.LeafSize, other.TreeWalk, other.TreeReuse, other.Morton
+	o.MAC, o.MACTolerance = other.MAC, other.MACTolerance
+	o.ForceMode, o.Layout, o.ForceLaw = other.ForceMode, other.Layout, other.ForceLaw
+	return o
+}
+
+
+// Walk returns the WalkOptions of the force walk of the run.
+func (o Options) Walk() quadtree.WalkOptions {
+	return quadtree.WalkOptions{Theta: o.Theta, Softening: o.Softening, MAC: o.MAC, Tolerance: o.MACTolerance, TreeWalk: o.TreeWalk,
+		ForceLaw: o.ForceLaw}
+}
+
+
+// Build returns the BuildOptions of the run, the settings of its quadtree construction.
+func (o Options) Build() quadtree.BuildOptions {
+	return quadtree.BuildOptions{TreeBuild: o.TreeBuild, LeafSize: o.LeafSize, Morton: o.Morton, MAC: o.MAC, Workers: o.NumWorkers}
+}
+
+
+// Law returns the ForceLaw of the run, Newtonian{} if none is set.
+func (o Options) Law() nbody.ForceLaw {
+	if o.ForceLaw == nil {
+		return nbody.Newtonian{}
+	}
+	return o.ForceLaw
+}
+
+
+// Log returns the writer of the event log of the run, io.Discard if none is set.
+func (o Options) Log() io.Writer {
+	if o.EventLog == nil {
+		return io.Discard
+	}
+	return o.EventLog
+}
+
+
+// Workers returns the number of goroutines computing the forces, parallel tree builds, and softening lengths of the run.
+func (o Options) Workers() int {
+	if o.NumWorkers > 0 {
+		return o.NumWorkers
+	}
+	return nbody.NumWorkers
+}
+
+
+// WithProgress returns a copy of the Options that reports progress to f.
+func (o Options) WithProgress(f ProgressFunc) Options {
+	o.progress = f
+	return o
+}
+
+
+// WithContext returns a copy of the Options whose run stops when ctx is cancelled or its deadline passes.
+// The generations simulated so far are kept (see Interrupted).
+func (o Options) WithContext(ctx context.Context) Options {
+	o.ctx = ctx
+	return o
+}
+
+
+// WithPotentials returns a copy of the Options whose stars also feel the forces of the given external potentials.
+func (o Options) WithPotentials(potentials ...ExternalPotential) Options {
+	o.potentials = append(append([]ExternalPotential(nil), o.potentials...), potentials...)
+	return o
+}
+
+
+// WithFriction returns a copy of the Options whose heaviest stars feel the given friction (see ParseFriction).
+func (o Options) WithFriction(f Friction) Options {
+	o.Friction = f
+	return o
+}
+
+
+// WithHalos returns a copy of the Options whose stars also feel the forces of the given analytic halos.
+func (o Options) WithHalos(halos ...Halo) Options {
+	potentials := make([]ExternalPotential, len(halos))
+	for i, h := range halos {
+		potentials[i] = h
+	}
+	return o.WithPotentials(potentials...)
+}
+
+
+// WithEventLog returns a copy of the Options writing the applied events and the mergers of its run to w,
+// e.g. io.Discard for a quiet run.
+func (o Options) WithEventLog(w io.Writer) Options {
+	o.EventLog = w
+	return o
+}
+
+
+// WithDebug returns a copy of the Options that writes debug dumps to w: the quadtree of every generation in trees,
+// and the force walk of the star with index traceStar in generation traceGeneration (a negative traceStar for none).
+// The traced index follows the star when stars are removed or merged before traceGeneration.
+func (o Options) WithDebug(w io.Writer, trees map[int]bool, traceStar, traceGeneration int) Options {
+	o.DebugOutput, o.DebugTrees, o.TraceStar, o.TraceGeneration = w, trees, traceStar, traceGeneration
+	return o
+}
+
+
+// WithHook returns a copy of the Options whose onGeneration calls the existing hook (if any) and then visit.
+// Input:
+//   - visit: function called with every generation.
+// Output:
+//   - the new Options.
+func (o Options) WithHook(visit func(generation int, u *nbody.Universe)) Options {
+	hook := o.onGeneration
+	o.onGeneration = func(generation int, u *nbody.Universe) {
+		if hook != nil {
+			hook(generation, u)
+		}
+		visit(generation, u)
+	}
+	return o
+}
+
+
+// ProgressPrinter returns a ProgressFunc that writes one progress line to w every total/steps generations
+// and after the last generation.
+// Input:
+//   - w: destination of the progress lines (usually os.Stderr).
+//   - steps: number of lines printed over the run.
+// Output:
+//   - the ProgressFunc.
+func ProgressPrinter(w io.Writer, steps int) ProgressFunc {
+	return func(generation, total int, stats ProgressStats) {
+		every := total / steps
+		if every < 1 {
+			every = 1
+		}
+		if generation%every != 0 && generation != total {
+			return
+		}
+		fmt.Fprintf(w, "generation %d/%d (%.0f%%), t = %.3e s, %d stars, %d tree nodes, %v/generation, elapsed %v, about %v left\n",
+			generation, total, 100*float64(generation)/float64(total), stats.Time, stats.NumStars, stats.NumTreeNodes,
+			stats.LastStep.Round(time.Microsecond), stats.Elapsed.Round(time.Second), stats.Remaining.Round(time.Second))
+	}
+}
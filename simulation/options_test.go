@@ -0,0 +1,319 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the Options of a run in options.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type WithOptionsTestCases struct {
+	id           string
+	theta        float64
+	softening    float64
+	integrator   string
+	saveInterval int
+	workers      int
+	valid        bool
+}
+
+type ZeroOptionsTestCases struct {
+	id         string
+	numStars   int
+	numGens    int
+	theta      float64
+	mergeScale float64
+}
+
+type ProgressPrinterTestCases struct {
+	id      string
+	total   int
+	steps   int
+	printed []int
+	line    string
+}
+
+
+// ReadWithOptions reads test data for the With methods of Options from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of WithOptionsTestCases structs containing the options and whether they are valid.
+func ReadWithOptions(fileName string) []WithOptionsTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []WithOptionsTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 7 {
+			continue
+		}
+
+		theta, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		nbody.Check(err)
+		softening, err := strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+		nbody.Check(err)
+		saveInterval, err := strconv.Atoi(strings.TrimSpace(parts[4]))
+		nbody.Check(err)
+		workers, err := strconv.Atoi(strings.TrimSpace(parts[5]))
+		nbody.Check(err)
+
+		tests = append(tests, WithOptionsTestCases{
+			id:           strings.TrimSpace(parts[0]),
+			theta:        theta,
+			softening:    softening,
+			integrator:   strings.TrimSpace(parts[3]),
+			saveInterval: saveInterval,
+			workers:      workers,
+			valid:        strings.TrimSpace(parts[6]) == "ok",
+		})
+	}
+
+	return tests
+}
+
+
+// TestWithOptions tests that the With methods set their options on a copy, that Validate checks them, and that
+// the worker count of the forces does not change a run.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestWithOptions(t *testing.T) {
+	tests := ReadWithOptions("../Tests/WithOptions.txt")
+
+	for _, test := range tests {
+		base := DefaultOptions(6, 2e14, 0.5)
+		options := base.WithTheta(test.theta).WithSoftening(test.softening).WithIntegrator(test.integrator).
+			WithSaveInterval(test.saveInterval).WithWorkers(test.workers)
+
+		if options.Theta != test.theta || options.Softening != test.softening || options.Integrator != test.integrator ||
+			options.SaveInterval != test.saveInterval || options.NumWorkers != test.workers {
+			t.Errorf("TestWithOptions(test %v) = %+v, want the options of the test", test.id, options)
+		}
+		if !reflect.DeepEqual(base, DefaultOptions(6, 2e14, 0.5)) {
+			t.Errorf("TestWithOptions(test %v) changed the Options it was called on", test.id)
+		}
+		if err := options.Validate(); (err == nil) != test.valid {
+			t.Errorf("TestWithOptions(test %v) Validate() = %v, want valid %v", test.id, err, test.valid)
+		}
+		if !test.valid {
+			continue
+		}
+
+		nbody.SetDeterministic()
+		g := nbody.InitializeGalaxy(200, 4e21, 5e22, 5e22)
+		initial := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+		timePoints := BarnesHut(initial, options)
+		expected := BarnesHut(initial, options.WithWorkers(1))
+
+		kept := SavedGenerations(0, 6, max(test.saveInterval, 1))
+		if len(timePoints) != len(kept) {
+			t.Errorf("TestWithOptions(test %v) kept %d generations, want %d", test.id, len(timePoints), len(kept))
+			continue
+		}
+		for i, s := range timePoints[len(timePoints)-1].Stars {
+			if s.Position != expected[len(expected)-1].Stars[i].Position {
+				t.Errorf("TestWithOptions(test %v, star %d) = %v on %d workers, want %v as on one",
+					test.id, i, s.Position, options.Workers(), expected[len(expected)-1].Stars[i].Position)
+				break
+			}
+		}
+	}
+}
+
+
+// ReadZeroOptions reads test data for the zero value of Options from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of ZeroOptionsTestCases structs containing the galaxies and the settings of their runs.
+func ReadZeroOptions(fileName string) []ZeroOptionsTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []ZeroOptionsTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+
+		numStars, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		numGens, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+		nbody.Check(err)
+		theta, err := strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
+		nbody.Check(err)
+		mergeScale, err := strconv.ParseFloat(strings.TrimSpace(parts[4]), 64)
+		nbody.Check(err)
+
+		tests = append(tests, ZeroOptionsTestCases{
+			id:         strings.TrimSpace(parts[0]),
+			numStars:   numStars,
+			numGens:    numGens,
+			theta:      theta,
+			mergeScale: mergeScale,
+		})
+	}
+
+	return tests
+}
+
+
+// TestZeroOptions tests that Options with only the length, time step, theta, and merge scale set are valid, and
+// run like DefaultOptions, whose settings are the defaults the zero values stand for.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestZeroOptions(t *testing.T) {
+	tests := ReadZeroOptions("../Tests/ZeroOptions.txt")
+
+	for _, test := range tests {
+		options := Options{NumGens: test.numGens, TimeStep: 2e14, Theta: test.theta, MergeScale: test.mergeScale}
+		if err := options.Validate(); err != nil {
+			t.Errorf("TestZeroOptions(test %v) Validate() = %v, want nil", test.id, err)
+			continue
+		}
+		defaults := DefaultOptions(test.numGens, 2e14, test.theta).WithEventLog(io.Discard)
+		defaults.MergeScale = test.mergeScale
+
+		nbody.SetDeterministic()
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		initial := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+		timePoints := BarnesHut(initial, options)
+		expected := BarnesHut(initial, defaults)
+
+		last, want := timePoints[len(timePoints)-1], expected[len(expected)-1]
+		if len(timePoints) != len(expected) || len(last.Stars) != len(want.Stars) {
+			t.Errorf("TestZeroOptions(test %v) = %d generations of %d stars, want %d of %d",
+				test.id, len(timePoints), len(last.Stars), len(expected), len(want.Stars))
+			continue
+		}
+		for i, s := range last.Stars {
+			if s.Position != want.Stars[i].Position {
+				t.Errorf("TestZeroOptions(test %v, star %d) = %v, want %v as with DefaultOptions", test.id, i, s.Position, want.Stars[i].Position)
+				break
+			}
+		}
+	}
+}
+
+
+// ReadProgressPrinter reads test data for ProgressPrinter from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of ProgressPrinterTestCases structs containing the runs, the generations printed, and the first line.
+func ReadProgressPrinter(fileName string) []ProgressPrinterTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []ProgressPrinterTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+
+		total, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		steps, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+		nbody.Check(err)
+		var printed []int
+		for _, field := range strings.Split(parts[3], ",") {
+			generation, err := strconv.Atoi(strings.TrimSpace(field))
+			nbody.Check(err)
+			printed = append(printed, generation)
+		}
+
+		tests = append(tests, ProgressPrinterTestCases{
+			id:      strings.TrimSpace(parts[0]),
+			total:   total,
+			steps:   steps,
+			printed: printed,
+			line:    strings.TrimSpace(parts[4]),
+		})
+	}
+
+	return tests
+}
+
+
+// TestProgressPrinter tests that ProgressPrinter prints the expected generations and lines, and that a run
+// reports its stars and simulated time to it through its ProgressStats.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestProgressPrinter(t *testing.T) {
+	tests := ReadProgressPrinter("../Tests/ProgressPrinter.txt")
+
+	for _, test := range tests {
+		var output bytes.Buffer
+		printer := ProgressPrinter(&output, test.steps)
+		var printed []int
+		for generation := 1; generation <= test.total; generation++ {
+			before := output.Len()
+			printer(generation, test.total, ProgressStats{
+				Elapsed:      90 * time.Second,
+				LastStep:     1500 * time.Microsecond,
+				Remaining:    30 * time.Second,
+				NumStars:     100,
+				NumTreeNodes: 133,
+				Time:         2e14 * float64(generation),
+			})
+			if output.Len() > before {
+				printed = append(printed, generation)
+			}
+		}
+		if !reflect.DeepEqual(printed, test.printed) {
+			t.Errorf("TestProgressPrinter(test %v) printed generations %v, want %v", test.id, printed, test.printed)
+		}
+		if first, _, _ := strings.Cut(output.String(), "\n"); first != test.line {
+			t.Errorf("TestProgressPrinter(test %v) = %q, want %q", test.id, first, test.line)
+		}
+
+		// a run calls the printer with its own stats
+		nbody.SetDeterministic()
+		g := nbody.InitializeGalaxy(50, 4e21, 5e22, 5e22)
+		initial := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+		output.Reset()
+		printer = ProgressPrinter(&output, test.steps)
+		BarnesHut(initial, DefaultOptions(test.total, 2e14, 0.5).WithProgress(func(generation, total int, stats ProgressStats) {
+			if stats.NumStars != len(initial.Stars) || stats.Time != 2e14*float64(generation) || stats.NumTreeNodes < 1 {
+				t.Errorf("TestProgressPrinter(test %v, generation %d) reported %+v, want %d stars at t = %v",
+					test.id, generation, stats, len(initial.Stars), 2e14*float64(generation))
+			}
+			printer(generation, total, stats)
+		}))
+		if lines := strings.Count(output.String(), "\n"); lines != len(test.printed) {
+			t.Errorf("TestProgressPrinter(test %v) run printed %d lines, want %d", test.id, lines, len(test.printed))
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: External potentials whose forces are added to those of the stars (option "-potential"): a point
+// mass, a logarithmic halo, and an NFW halo, besides the Hernquist halos of galaxymodel.go.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// An ExternalPotential is a fixed field of force, such as the halo of a host galaxy or a cluster the stars move
+// through, that pulls every star but is not made of stars, so it costs nothing per star and is not pulled back.
+// StarAcceleration and ArrayAccelerations add the acceleration of every potential of the run (see
+// Options.WithPotentials) to that of the tree or of direct summation.
+type ExternalPotential interface {
+	// Acceleration returns the acceleration of a star at p at the simulated time t (see Universe.Time).
+	Acceleration(p nbody.OrderedPair, t float64) nbody.OrderedPair
+}
+
+// An EnergyPotential is an ExternalPotential that also gives its potential, the potential energy per unit mass,
+// so -energy-log includes it (see ExternalPotentialEnergy). All potentials of the simulator are.
+type EnergyPotential interface {
+	ExternalPotential
+	// Potential returns the potential at p at the simulated time t.
+	Potential(p nbody.OrderedPair, t float64) float64
+}
+
+
+// PointMass is a fixed point mass, with the acceleration G M / r^2.
+type PointMass struct {
+	center nbody.OrderedPair
+	mass   float64 // in kg
+}
+
+// Acceleration returns the acceleration toward the point mass (see ExternalPotential).
+func (m PointMass) Acceleration(p nbody.OrderedPair, t float64) nbody.OrderedPair {
+	dx, dy, r := nbody.Distance(p, m.center)
+	if r == 0 {
+		return nbody.OrderedPair{}
+	}
+	a := -nbody.G * m.mass / (r * r * r)
+	return nbody.OrderedPair{X: a * dx, Y: a * dy}
+}
+
+// Potential returns the potential -G M / r of the point mass.
+func (m PointMass) Potential(p nbody.OrderedPair, t float64) float64 {
+	_, _, r := nbody.Distance(p, m.center)
+	return -nbody.G * m.mass / r
+}
+
+
+// LogarithmicHalo is the logarithmic potential v0^2 / 2 ln(r^2 + rc^2), whose circular speed
+// v0 r / sqrt(r^2 + rc^2) rises inside the core radius rc and is the constant v0 beyond it: the flat rotation
+// curve of a galaxy halo.
+type LogarithmicHalo struct {
+	center nbody.OrderedPair
+	speed  float64 // circular speed v0 far from the center, in m/s
+	core   float64 // core radius rc in m
+}
+
+// Acceleration returns the acceleration v0^2 r / (r^2 + rc^2) toward the center (see ExternalPotential).
+func (h LogarithmicHalo) Acceleration(p nbody.OrderedPair, t float64) nbody.OrderedPair {
+	dx, dy, r := nbody.Distance(p, h.center)
+	if r == 0 {
+		return nbody.OrderedPair{}
+	}
+	a := -h.speed * h.speed / (r*r + h.core*h.core)
+	return nbody.OrderedPair{X: a * dx, Y: a * dy}
+}
+
+// Potential returns the potential v0^2 / 2 ln(r^2 + rc^2) of the halo.
+func (h LogarithmicHalo) Potential(p nbody.OrderedPair, t float64) float64 {
+	_, _, r := nbody.Distance(p, h.center)
+	return h.speed * h.speed / 2 * math.Log(r*r+h.core*h.core)
+}
+
+
+// NFWHalo is a dark matter halo with the profile of Navarro, Frenk, and White, rho0 / (x (1 + x)^2) with
+// x = r / rs, whose mass M(<r) = Ms (ln(1 + x) - x / (1 + x)) grows without limit. Ms = 4 pi rho0 rs^3 is its
+// characteristic mass; about a fifth of it lies within the scale radius rs.
+type NFWHalo struct {
+	center nbody.OrderedPair
+	mass   float64 // characteristic mass Ms in kg
+	scale  float64 // scale radius rs in m
+}
+
+// Acceleration returns the acceleration G M(<r) / r^2 toward the center (see ExternalPotential).
+func (h NFWHalo) Acceleration(p nbody.OrderedPair, t float64) nbody.OrderedPair {
+	dx, dy, r := nbody.Distance(p, h.center)
+	if r == 0 {
+		return nbody.OrderedPair{}
+	}
+	x := r / h.scale
+	a := -nbody.G * h.mass * (math.Log1p(x) - x/(1+x)) / (r * r * r)
+	return nbody.OrderedPair{X: a * dx, Y: a * dy}
+}
+
+// Potential returns the potential -G Ms ln(1 + x) / r of the halo, -G Ms / rs at its center.
+func (h NFWHalo) Potential(p nbody.OrderedPair, t float64) float64 {
+	_, _, r := nbody.Distance(p, h.center)
+	if r == 0 {
+		return -nbody.G * h.mass / h.scale
+	}
+	return -nbody.G * h.mass * math.Log1p(r/h.scale) / r
+}
+
+
+// ParsePotential parses the value of -potential, an external potential at the center of the universe:
+// "point:mass" (in kg), "log:v0,rc" (circular speed in m/s and core radius in m), or "nfw:mass,scale"
+// (characteristic mass in kg and scale radius in m).
+// Input:
+//   - text: the value of -potential, "" for none.
+//   - center: the center of the universe (see Universe.Center).
+// Output:
+//   - the potentials (none for ""), or an error if the name is unknown or its parameters are invalid.
+func ParsePotential(text string, center nbody.OrderedPair) ([]ExternalPotential, error) {
+	if text == "" {
+		return nil, nil
+	}
+	name, params, _ := strings.Cut(text, ":")
+	var values []float64
+	for _, field := range strings.Split(params, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || !nbody.IsFinite(v) {
+			return nil, fmt.Errorf("invalid potential %q: %q is not a number", text, field)
+		}
+		values = append(values, v)
+	}
+
+	switch name {
+	case "point":
+		if len(values) != 1 || values[0] <= 0 {
+			return nil, fmt.Errorf("invalid potential %q: expected point:mass with a positive mass in kg", text)
+		}
+		return []ExternalPotential{PointMass{center: center, mass: values[0]}}, nil
+	case "log":
+		if len(values) != 2 || values[0] <= 0 || values[1] <= 0 {
+			return nil, fmt.Errorf("invalid potential %q: expected log:v0,rc with a positive speed in m/s and core radius in m", text)
+		}
+		return []ExternalPotential{LogarithmicHalo{center: center, speed: values[0], core: values[1]}}, nil
+	case "nfw":
+		if len(values) != 2 || values[0] <= 0 || values[1] <= 0 {
+			return nil, fmt.Errorf("invalid potential %q: expected nfw:mass,scale with a positive mass in kg and scale radius in m", text)
+		}
+		return []ExternalPotential{NFWHalo{center: center, mass: values[0], scale: values[1]}}, nil
+	}
+	return nil, fmt.Errorf("unknown potential %q (use point:mass, log:v0,rc, or nfw:mass,scale)", text)
+}
+
+
+// ExternalPotentialEnergy returns the potential energy of the stars of a universe in the field of external
+// potentials, at the time of the universe. Potentials that are not EnergyPotentials add nothing.
+// Input:
+//   - u: pointer to the Universe.
+//   - potentials: the external potentials of the run.
+// Output:
+//   - the sum of the mass of every star times every potential at its position.
+func ExternalPotentialEnergy(u *nbody.Universe, potentials []ExternalPotential) float64 {
+	energy := 0.0
+	for _, potential := range potentials {
+		p, ok := potential.(EnergyPotential)
+		if !ok {
+			continue
+		}
+		for _, s := range u.Stars {
+			energy += s.Mass * p.Potential(s.Position, u.Time)
+		}
+	}
+	return energy
+}
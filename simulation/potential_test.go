@@ -0,0 +1,231 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the external potentials in potential.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type ExternalPotentialTestCases struct {
+	id                string
+	potential         string
+	point             nbody.OrderedPair
+	time              float64
+	expected          nbody.OrderedPair
+	expectedPotential float64
+	expectError       bool
+}
+
+
+// parseFloats parses numbers separated by spaces.
+func parseFloats(text string) []float64 {
+	var values []float64
+	for _, field := range strings.Fields(text) {
+		v, err := strconv.ParseFloat(field, 64)
+		nbody.Check(err)
+		values = append(values, v)
+	}
+	return values
+}
+
+
+// ReadExternalPotential reads test data for the built-in external potentials from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of ExternalPotentialTestCases structs containing the potentials, the points, and the expected values.
+func ReadExternalPotential(fileName string) []ExternalPotentialTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []ExternalPotentialTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		input := parseFloats(parts[2])
+		test := ExternalPotentialTestCases{
+			id:        strings.TrimSpace(parts[0]),
+			potential: strings.TrimSpace(parts[1]),
+			point:     nbody.OrderedPair{X: input[0], Y: input[1]},
+			time:      input[2],
+		}
+		if expected := strings.TrimSpace(parts[3]); expected == "error" {
+			test.expectError = true
+		} else {
+			values := parseFloats(expected)
+			test.expected = nbody.OrderedPair{X: values[0], Y: values[1]}
+			test.expectedPotential = values[2]
+		}
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// closeTo reports whether x is within a relative tolerance of the expected value, or equal to it.
+func closeTo(x, expected, tolerance float64) bool {
+	return x == expected || math.Abs(x-expected) <= tolerance*math.Abs(expected)
+}
+
+
+// TestExternalPotential tests that ParsePotential accepts the valid values of -potential and rejects the others,
+// and that every built-in potential gives the expected acceleration and potential, the acceleration being minus
+// the gradient of the potential.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestExternalPotential(t *testing.T) {
+	tests := ReadExternalPotential("../Tests/ExternalPotential.txt")
+
+	for _, test := range tests {
+		potentials, err := ParsePotential(test.potential, nbody.OrderedPair{})
+
+		if test.expectError {
+			if err == nil {
+				t.Errorf("TestExternalPotential(test %v) = %v, want an error", test.id, potentials)
+			}
+			continue
+		}
+		if err != nil || len(potentials) != 1 {
+			t.Errorf("TestExternalPotential(test %v) = %v, error %v, want one potential", test.id, potentials, err)
+			continue
+		}
+
+		p := potentials[0].(EnergyPotential)
+		a := p.Acceleration(test.point, test.time)
+		potential := p.Potential(test.point, test.time)
+		if !closeTo(a.X, test.expected.X, 1e-12) || !closeTo(a.Y, test.expected.Y, 1e-12) ||
+			!closeTo(potential, test.expectedPotential, 1e-12) {
+			t.Errorf("TestExternalPotential(test %v) = %v and potential %v, want %v and %v",
+				test.id, a, potential, test.expected, test.expectedPotential)
+		}
+
+		// central differences of the potential over a millionth of the radius
+		r := math.Hypot(test.point.X, test.point.Y)
+		if r == 0 {
+			continue
+		}
+		h := r * 1e-6
+		gradient := nbody.OrderedPair{
+			X: (p.Potential(nbody.OrderedPair{X: test.point.X + h, Y: test.point.Y}, test.time) -
+				p.Potential(nbody.OrderedPair{X: test.point.X - h, Y: test.point.Y}, test.time)) / (2 * h),
+			Y: (p.Potential(nbody.OrderedPair{X: test.point.X, Y: test.point.Y + h}, test.time) -
+				p.Potential(nbody.OrderedPair{X: test.point.X, Y: test.point.Y - h}, test.time)) / (2 * h),
+		}
+		if math.Hypot(a.X+gradient.X, a.Y+gradient.Y) > 1e-6*math.Hypot(a.X, a.Y) {
+			t.Errorf("TestExternalPotential(test %v) acceleration %v, want minus the gradient %v", test.id, a, gradient)
+		}
+	}
+}
+
+
+// timedField is a uniform field of 1 m/s^2 along x that records the times it is asked for its acceleration.
+type timedField struct {
+	lock  *sync.Mutex
+	times map[float64]bool
+}
+
+// Acceleration records the time t and returns the field (see ExternalPotential).
+func (f timedField) Acceleration(p nbody.OrderedPair, t float64) nbody.OrderedPair {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.times[t] = true
+	return nbody.OrderedPair{X: 1}
+}
+
+
+type PotentialTimeTestCases struct {
+	id            string
+	timeStep      float64
+	numGens       int
+	blockLevels   int
+	eta           float64
+	expectedTimes []float64
+}
+
+
+// ReadPotentialTime reads test data for the time given to external potentials from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of PotentialTimeTestCases structs containing the runs and the expected times.
+func ReadPotentialTime(fileName string) []PotentialTimeTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []PotentialTimeTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 3 {
+			continue
+		}
+
+		input := parseFloats(parts[1])
+		tests = append(tests, PotentialTimeTestCases{
+			id:            strings.TrimSpace(parts[0]),
+			timeStep:      input[0],
+			numGens:       int(input[1]),
+			blockLevels:   int(input[2]),
+			eta:           input[3],
+			expectedTimes: parseFloats(parts[2]),
+		})
+	}
+
+	return tests
+}
+
+
+// TestPotentialTime tests that external potentials pull the stars at the simulated time of their positions, with
+// one step for all stars and at every tick of block time steps.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestPotentialTime(t *testing.T) {
+	tests := ReadPotentialTime("../Tests/PotentialTime.txt")
+
+	for _, test := range tests {
+		field := timedField{lock: &sync.Mutex{}, times: make(map[float64]bool)}
+		u := nbody.NewUniverse(1e6)
+		u.AddStars(nbody.NewStar(nbody.OrderedPair{X: 5e5, Y: 5e5}, nbody.OrderedPair{}, 1, 1))
+
+		options := DefaultOptions(test.numGens, test.timeStep, 0.5).WithSoftening(1).WithPotentials(field)
+		options.BlockLevels = test.blockLevels
+		options.AdaptiveStep = test.eta
+		nbody.Check(RunGenerations(u, options))
+
+		var times []float64
+		for time := range field.times {
+			times = append(times, time)
+		}
+		sort.Float64s(times)
+		if !reflect.DeepEqual(times, test.expectedTimes) {
+			t.Errorf("TestPotentialTime(test %v) = %v, want %v", test.id, times, test.expectedTimes)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: A Simulation owning a universe and its Options, advanced one generation at a time.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Simulation owns a universe and the Options of its run (theta, time step, integrator, boundary, events, hooks),
+// so a program can drive the simulation interactively: advance it with Step or Run and look at it with Snapshot.
+// RunGenerations and BarnesHut run a whole Simulation at once.
+type Simulation struct {
+	Universe   *nbody.Universe
+	options    Options
+	substep    Options // the Options of one of the options.Substeps steps of a generation
+	generation int
+
+	nextEvent int     // index of the first event in options.Events that is not applied yet
+	recurring []Event // recurring events that are active
+
+	tree      *quadtree.QuadTree // tree of the last step, refitted by the next one with -tree-reuse
+	treeStars []*nbody.Star   // stars the tree was built or refitted for, in the order of their universe
+
+	clamped []int64 // IDs of the stars whose non-finite accelerations were set to zero in the current generation
+
+	traceStar int // index of the star traced with options.TraceStar in the current universe, or negative
+
+	start time.Time // time of the first step, for the progress reports
+}
+
+
+// NewSimulation returns a Simulation of a copy of a universe, at generation options.FirstGeneration,
+// and hands this first generation to options.onGeneration. Stars of the copy without IDs are numbered
+// (see AssignStarIDs). Zero settings of the Options stand for their defaults.
+// options.NumGens is the length of the run reported to options.progress; the simulation can be stepped beyond it.
+// Input:
+//   - initialUniverse: pointer to the initial Universe (not modified).
+//   - options: the Options of the run.
+// Output:
+//   - pointer to the Simulation, or an error if the Options are invalid.
+func NewSimulation(initialUniverse *nbody.Universe, options Options) (*Simulation, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	options = options.withDefaults()
+
+	sim := &Simulation{
+		Universe:   nbody.CopyUniverse(initialUniverse),
+		options:    options,
+		substep:    options,
+		generation: options.FirstGeneration,
+		traceStar:  options.TraceStar,
+	}
+	sim.Universe.AssignStarIDs()
+	// every generation advances the physics by options.Substeps steps of this Options
+	sim.substep.TimeStep = options.TimeStep / float64(options.Substeps)
+	// the stars clamped by the steps are reported with their generation
+	if options.FiniteCheck == "clamp" {
+		sim.substep.clamped = func(s *nbody.Star, acceleration nbody.OrderedPair) {
+			sim.clamped = append(sim.clamped, s.ID)
+		}
+	}
+
+	if options.onGeneration != nil {
+		options.onGeneration(sim.generation, sim.Universe)
+	}
+	return sim, nil
+}
+
+
+// Generation returns the generation of the current universe of the simulation.
+func (sim *Simulation) Generation() int {
+	return sim.generation
+}
+
+
+// Snapshot returns a copy of the current universe, which later steps do not change.
+func (sim *Simulation) Snapshot() *nbody.Universe {
+	return nbody.CopyUniverse(sim.Universe)
+}
+
+
+// Run advances the simulation by n generations, or until options.ctx is cancelled.
+// Input:
+//   - n: number of generations.
+// Output:
+//   - the first error of a step, or nil (the simulation stops at the failed generation).
+//     If the context was cancelled, the error wraps ctx.Err() (see Interrupted) and the simulation stays at
+//     the last generation it completed.
+func (sim *Simulation) Run(n int) error {
+	for k := 0; k < n; k++ {
+		if sim.options.ctx != nil {
+			if err := sim.options.ctx.Err(); err != nil {
+				return fmt.Errorf("interrupted after generation %d: %w", sim.generation, err)
+			}
+		}
+		if err := sim.Step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+
+// Step advances the simulation by one generation: it builds the quadtree, integrates options.Substeps steps,
+// checks that the stars are finite (see options.FiniteCheck), removes escaped stars, merges colliding ones,
+// applies the events of the generation, and hands the new universe to options.onGeneration and the progress
+// to options.progress.
+// The universe of the previous generation is copied unless options.Retain reports that the hook does not keep it.
+// Output:
+//   - an error if a parallel worker failed or, with the finite check "abort", a NumericalError if a value of a
+//     star is not finite; the simulation should then not be stepped further.
+func (sim *Simulation) Step() error {
+	options := sim.options
+	if sim.start.IsZero() {
+		sim.start = time.Now()
+	}
+	stepStart := time.Now()
+	previous := sim.generation
+	i := previous + 1
+	currentUniverse := sim.Universe
+
+	// first, build a QuadTree
+	tree := sim.quadTree(currentUniverse)
+
+	// recompute the adaptive softening lengths from the new tree every few generations
+	if options.AdaptiveSoftening > 0 && previous%options.SofteningInterval == 0 {
+		SetAdaptiveSoftening(currentUniverse, tree, options.AdaptiveSoftening, options.Softening, options.Workers())
+	}
+
+	if options.DebugOutput != nil {
+		// dump the tree of the generations selected with -debug-tree
+		if options.DebugTrees[previous] {
+			quadtree.PrintTree(options.DebugOutput, tree, previous)
+		}
+
+		// trace the force walk of the star selected with -trace-star and -trace-gen
+		if options.TraceGeneration == previous && sim.traceStar >= 0 && sim.traceStar < len(currentUniverse.Stars) {
+			quadtree.TraceNetForce(options.DebugOutput, tree, currentUniverse.Stars[sim.traceStar], sim.traceStar, previous, options.Walk())
+		}
+	}
+
+	// then we can update the universe, in substeps smaller steps that are not stored;
+	// the current universe is only copied if the hook keeps it, and the substeps never are
+	step := Step
+	if options.Retain != nil && !options.Retain(previous) {
+		step = StepInPlace
+	}
+	newUniverse, err := step(currentUniverse, tree, sim.substep)
+	if err != nil {
+		return fmt.Errorf("generation %d: %w", i, err)
+	}
+	for k := 1; k < options.Substeps; k++ {
+		tree = sim.quadTree(newUniverse)
+		newUniverse, err = StepInPlace(newUniverse, tree, sim.substep)
+		if err != nil {
+			return fmt.Errorf("generation %d, substep %d: %w", i, k, err)
+		}
+	}
+
+	// stop at the first star that is no longer finite, or clamp and remove such stars
+	switch options.FiniteCheck {
+	case "abort":
+		if err := nbody.CheckFinite(newUniverse, i); err != nil {
+			return err
+		}
+	case "clamp":
+		if len(sim.clamped) > 0 {
+			fmt.Fprintf(WarningOutput, "Warning: generation %d: set the non-finite accelerations of %d stars to zero, the first of them star ID %d\n",
+				i, len(sim.clamped), sim.clamped[0])
+			sim.clamped = sim.clamped[:0]
+		}
+		if newIndex := RemoveNonFinite(newUniverse, i); newIndex != nil {
+			sim.traceStar = nbody.RemapIndex(newIndex, sim.traceStar)
+		}
+	}
+
+	// drop the stars that escaped, keeping the traced star's index consistent
+	if options.RemoveEscaped > 0 {
+		width, height := newUniverse.Bounds()
+		newIndex := newUniverse.RemoveStars(nbody.EscapedStars(width, height, options.RemoveEscaped))
+		sim.traceStar = nbody.RemapIndex(newIndex, sim.traceStar)
+	}
+
+	// merge the stars that collided
+	if options.MergeScale > 0 {
+		if newIndex := MergeCollisions(newUniverse, options, i); newIndex != nil {
+			sim.traceStar = nbody.RemapIndex(newIndex, sim.traceStar)
+		}
+	}
+
+	// apply the scripted events of this generation; added stars are in the next tree
+	for sim.nextEvent < len(options.Events) && options.Events[sim.nextEvent].generation <= i {
+		e := options.Events[sim.nextEvent]
+		if recurringEvents[e.action] {
+			sim.recurring = append(sim.recurring, e)
+		} else if e.generation == i {
+			ApplyEvent(newUniverse, e, i, options.Log())
+		}
+		sim.nextEvent++
+	}
+	for _, e := range sim.recurring {
+		ApplyEvent(newUniverse, e, i, options.Log())
+	}
+
+	sim.Universe = newUniverse
+	sim.generation = i
+	if options.onGeneration != nil {
+		options.onGeneration(i, newUniverse)
+	}
+
+	// report the progress to the embedding application
+	if options.progress != nil && i <= options.NumGens {
+		elapsed := time.Since(sim.start)
+		options.progress(i, options.NumGens, ProgressStats{
+			Elapsed:      elapsed,
+			LastStep:     time.Since(stepStart),
+			Remaining:    elapsed / time.Duration(i-options.FirstGeneration) * time.Duration(options.NumGens-i),
+			NumStars:     len(newUniverse.Stars),
+			NumTreeNodes: tree.NumNodes(),
+			Time:         newUniverse.Time,
+		})
+	}
+
+	return nil
+}
+
+
+// quadTree returns the quadtree of a universe of the simulation: built with BuildQuadTree, or with tree reuse
+// refitted from the tree of the previous step (see RefitQuadTree).
+func (sim *Simulation) quadTree(u *nbody.Universe) *quadtree.QuadTree {
+	if !sim.options.TreeReuse {
+		return quadtree.BuildQuadTree(u, sim.options.Build())
+	}
+	if sim.tree == nil {
+		sim.tree = quadtree.BuildQuadTree(u, sim.options.Build())
+	} else {
+		sim.tree = quadtree.RefitQuadTree(sim.tree, sim.treeStars, u, sim.options.Build())
+	}
+	// the universe may remove stars in place, so the tree keeps its own list
+	sim.treeStars = append(sim.treeStars[:0], u.Stars...)
+	return sim.tree
+}
+
+
+// Interrupted reports whether an error of a run only means that its context was cancelled or timed out,
+// so the generations handed to the hook so far are valid and may be written out.
+func Interrupted(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
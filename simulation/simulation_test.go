@@ -0,0 +1,235 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the Simulation type and interrupted runs in simulation.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type SimulationTestCases struct {
+	id         string
+	numStars   int
+	chunks     []int
+	integrator string
+	substeps   int
+	events     []Event
+}
+
+
+// ReadSimulation reads test data for the Simulation type from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of SimulationTestCases structs containing the runs.
+func ReadSimulation(fileName string) []SimulationTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []SimulationTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 6 {
+			continue
+		}
+
+		numStars, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		var chunks []int
+		for _, field := range strings.Split(parts[2], ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(field))
+			nbody.Check(err)
+			chunks = append(chunks, n)
+		}
+		substeps, err := strconv.Atoi(strings.TrimSpace(parts[4]))
+		nbody.Check(err)
+
+		var events []Event
+		if text := strings.TrimSpace(parts[5]); text != "-" {
+			for _, eventLine := range strings.Split(text, ";") {
+				e, err := ParseEvent(strings.TrimSpace(eventLine))
+				nbody.Check(err)
+				events = append(events, e)
+			}
+		}
+
+		tests = append(tests, SimulationTestCases{
+			id:         strings.TrimSpace(parts[0]),
+			numStars:   numStars,
+			chunks:     chunks,
+			integrator: strings.TrimSpace(parts[3]),
+			substeps:   substeps,
+			events:     events,
+		})
+	}
+
+	return tests
+}
+
+
+// TestSimulation tests that a Simulation run in chunks ends like BarnesHut, and that its snapshots are copies.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestSimulation(t *testing.T) {
+	tests := ReadSimulation("../Tests/Simulation.txt")
+
+	for _, test := range tests {
+		numGens := 0
+		for _, n := range test.chunks {
+			numGens += n
+		}
+		options := DefaultOptions(numGens, 2e14, 0.5).WithEventLog(io.Discard)
+		options.Integrator = test.integrator
+		options.Substeps = test.substeps
+		options.Events = test.events
+
+		nbody.SetDeterministic()
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		expected := BarnesHut(nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23), options)[numGens]
+
+		nbody.SetDeterministic()
+		g = nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		sim, err := NewSimulation(nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23), options)
+		nbody.Check(err)
+		first := sim.Snapshot()
+		for _, n := range test.chunks {
+			nbody.Check(sim.Run(n))
+		}
+
+		if sim.Generation() != numGens {
+			t.Errorf("TestSimulation(test %v) is at generation %d, want %d", test.id, sim.Generation(), numGens)
+		}
+		if result := sim.Snapshot(); !reflect.DeepEqual(result, expected) {
+			t.Errorf("TestSimulation(test %v) differs from BarnesHut after %d generations", test.id, numGens)
+		}
+		if numGens > 0 && reflect.DeepEqual(first, sim.Snapshot()) {
+			t.Errorf("TestSimulation(test %v) snapshot of generation 0 changed with the simulation", test.id)
+		}
+	}
+}
+
+
+type InterruptTestCases struct {
+	id           string
+	numStars     int
+	numGens      int
+	saveInterval int
+	stop         string // "cancel k" cancels the context in the hook of generation k, "timeout" passes an expired deadline
+	generation   int    // expected generation of the interrupted Simulation
+	numSaved     int    // expected number of universes returned by BarnesHut
+}
+
+
+// ReadInterrupt reads test data for interrupted runs from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of InterruptTestCases structs containing the runs and the expected results.
+func ReadInterrupt(fileName string) []InterruptTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []InterruptTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 7 {
+			continue
+		}
+
+		var nums [5]int
+		for i, k := range []int{1, 2, 3, 5, 6} {
+			nums[i], err = strconv.Atoi(strings.TrimSpace(parts[k]))
+			nbody.Check(err)
+		}
+
+		tests = append(tests, InterruptTestCases{
+			id:           strings.TrimSpace(parts[0]),
+			numStars:     nums[0],
+			numGens:      nums[1],
+			saveInterval: nums[2],
+			stop:         strings.TrimSpace(parts[4]),
+			generation:   nums[3],
+			numSaved:     nums[4],
+		})
+	}
+
+	return tests
+}
+
+
+// InterruptContext returns the context of an InterruptTestCases run and the hook that cancels it.
+func InterruptContext(stop string) (context.Context, func(generation int, u *nbody.Universe)) {
+	if stop == "timeout" {
+		// the deadline has passed, so the context is already done and cancel does not change its error
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		cancel()
+		return ctx, func(int, *nbody.Universe) {}
+	}
+
+	k, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(stop, "cancel")))
+	nbody.Check(err)
+	ctx, cancel := context.WithCancel(context.Background())
+	return ctx, func(generation int, u *nbody.Universe) {
+		if generation == k {
+			cancel()
+		}
+	}
+}
+
+
+// TestInterrupt tests that a cancelled run stops at the generation it was cancelled in, with an error reported
+// by Interrupted, and that BarnesHut then returns the generations kept so far instead of panicking.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestInterrupt(t *testing.T) {
+	tests := ReadInterrupt("../Tests/Interrupt.txt")
+
+	for _, test := range tests {
+		options := DefaultOptions(test.numGens, 2e14, 0.5)
+		options.SaveInterval = test.saveInterval
+
+		nbody.SetDeterministic()
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		u := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+
+		ctx, hook := InterruptContext(test.stop)
+		sim, err := NewSimulation(u, options.WithContext(ctx).WithHook(hook))
+		nbody.Check(err)
+		err = sim.Run(test.numGens)
+		if !Interrupted(err) {
+			t.Errorf("TestInterrupt(test %v) returned %v, want an interruption", test.id, err)
+		}
+		if sim.Generation() != test.generation {
+			t.Errorf("TestInterrupt(test %v) stopped at generation %d, want %d", test.id, sim.Generation(), test.generation)
+		}
+
+		ctx, hook = InterruptContext(test.stop)
+		timePoints := BarnesHut(u, options.WithContext(ctx).WithHook(hook))
+		if len(timePoints) != test.numSaved {
+			t.Errorf("TestInterrupt(test %v) kept %d universes, want %d", test.id, len(timePoints), test.numSaved)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Structure-of-arrays layout of the stars for the direct force loop (option "-layout").
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"fmt"
+	"math"
+)
+
+// The layout of a run (see Options.WithLayout) selects how the direct summation of -force direct reads the stars.
+//   - "aos": every pair reads both stars through Universe.Stars, one *Star each (an array of structures), so the
+//     loop follows a pointer per star and pulls all fields of the star into the cache.
+//   - "soa": the positions, velocities, masses, and softening lengths of the stars are first gathered into
+//     parallel float64 slices (StarArrays, a structure of arrays), which the loop reads in order without
+//     following pointers, so every cache line it loads holds only values it uses.
+//
+// Universe.Stars stays the layout of the universe that all other code reads and writes; StarArrays are gathered
+// from it every generation, which takes O(N) against the O(N^2) of the pairs. The forces of the active stars of
+// block time steps (-block-levels) are summed over the stars.
+
+
+// CheckLayout returns an error if name is not a known layout, or if it is "soa" without direct summation.
+func CheckLayout(name, force string) error {
+	switch {
+	case name != "aos" && name != "soa":
+		return fmt.Errorf("unknown layout %q (use aos or soa)", name)
+	case name == "soa" && force != "direct":
+		return fmt.Errorf("-layout soa needs -force direct; the tree walk reads the stars of its nodes")
+	}
+	return nil
+}
+
+
+// StarArrays holds the stars of a universe as parallel slices: star i is at (x[i], y[i]).
+type StarArrays struct {
+	x, y   []float64 // positions
+	vx, vy []float64 // velocities
+	mass   []float64
+	soft2  []float64 // square of the softening length of every star (its own, or the run's)
+}
+
+
+// NewStarArrays gathers the stars into StarArrays.
+// Input:
+//   - stars: the stars, such as Universe.Stars.
+//   - softening: softening length of the run, used by the stars without their own.
+// Output:
+//   - pointer to the StarArrays, in the order of stars.
+func NewStarArrays(stars []*nbody.Star, softening float64) *StarArrays {
+	n := len(stars)
+	a := &StarArrays{
+		x: make([]float64, n), y: make([]float64, n),
+		vx: make([]float64, n), vy: make([]float64, n),
+		mass: make([]float64, n), soft2: make([]float64, n),
+	}
+	for i, s := range stars {
+		a.x[i], a.y[i] = s.Position.X, s.Position.Y
+		a.vx[i], a.vy[i] = s.Velocity.X, s.Velocity.Y
+		a.mass[i] = s.Mass
+		eps := s.Softening
+		if eps == 0 {
+			eps = softening
+		}
+		a.soft2[i] = eps * eps
+	}
+	return a
+}
+
+
+// Len returns the number of stars.
+func (a *StarArrays) Len() int {
+	return len(a.x)
+}
+
+
+// Star returns a copy of the position, velocity, and mass of star i as a Star, a view for code written for stars.
+func (a *StarArrays) Star(i int) nbody.Star {
+	return nbody.Star{
+		Position: nbody.OrderedPair{X: a.x[i], Y: a.y[i]},
+		Velocity: nbody.OrderedPair{X: a.vx[i], Y: a.vy[i]},
+		Mass:     a.mass[i],
+	}
+}
+
+
+// Acceleration computes the acceleration of star i by summing the pull G m_j (x_j - x_i) / r^3 of every other
+// star, with r^2 = d^2 + eps^2 and the softening of the pair as in PairSoftening. It equals DirectAcceleration
+// up to rounding, also for tracers, which it pulls like any star. Other force laws than Newtonian gravity are
+// summed with LawAcceleration.
+func (a *StarArrays) Acceleration(i int, law nbody.ForceLaw) nbody.OrderedPair {
+	if _, newtonian := law.(nbody.Newtonian); !newtonian {
+		return a.LawAcceleration(i, law)
+	}
+	xi, yi, soft2i := a.x[i], a.y[i], a.soft2[i]
+	// slices of the same length let the compiler drop the bounds checks of the loop
+	x, y, mass, soft2 := a.x, a.y[:len(a.x)], a.mass[:len(a.x)], a.soft2[:len(a.x)]
+
+	var ax, ay float64
+	for j := range x {
+		dx, dy := x[j]-xi, y[j]-yi
+		d2 := dx*dx + dy*dy
+		// the star itself, and stars at its position, do not pull
+		if d2 == 0 {
+			continue
+		}
+		// one division per pair: the cube of 1 / r is faster than dividing by r^3
+		inv := 1 / math.Sqrt(d2+(soft2i+soft2[j])*0.5)
+		f := nbody.G * mass[j] * inv * inv * inv
+		ax += f * dx
+		ay += f * dy
+	}
+	return nbody.OrderedPair{X: ax, Y: ay}
+}
+
+
+// LawAcceleration computes the acceleration of star i like Acceleration, with a ForceLaw on a unit mass.
+func (a *StarArrays) LawAcceleration(i int, law nbody.ForceLaw) nbody.OrderedPair {
+	xi, yi, soft2i := a.x[i], a.y[i], a.soft2[i]
+
+	var ax, ay float64
+	for j := range a.x {
+		dx, dy := a.x[j]-xi, a.y[j]-yi
+		d := math.Sqrt(dx*dx + dy*dy)
+		if d == 0 {
+			continue
+		}
+		f := law.Force(1, a.mass[j], d, math.Sqrt((soft2i+a.soft2[j])*0.5)) / d
+		ax += f * dx
+		ay += f * dy
+	}
+	return nbody.OrderedPair{X: ax, Y: ay}
+}
+
+
+// DirectAccelerations computes the acceleration of every star by direct summation with a ForceLaw, on the given
+// number of goroutines.
+func (a *StarArrays) DirectAccelerations(workers int, law nbody.ForceLaw) []nbody.OrderedPair {
+	accelerations := make([]nbody.OrderedPair, a.Len())
+	numChunks := (a.Len() + forceChunk - 1) / forceChunk
+	nbody.ParallelFor(numChunks, workers, func(c int) {
+		for i := c * forceChunk; i < (c+1)*forceChunk && i < a.Len(); i++ {
+			accelerations[i] = a.Acceleration(i, law)
+		}
+	})
+	return accelerations
+}
+
+
+// ArrayAccelerations computes the accelerations of a generation like ComputeAccelerations with -force direct,
+// from StarArrays of the universe: pinned stars do not move, and the external potentials and the friction of
+// the run act on every star.
+// Input:
+//   - u: pointer to the Universe.
+//   - options: the Options of the run (softening, force law, potentials, friction).
+// Output:
+//   - the acceleration of every star, in the order of u.Stars.
+func ArrayAccelerations(u *nbody.Universe, options Options) []nbody.OrderedPair {
+	accelerations := NewStarArrays(u.Stars, options.Softening).DirectAccelerations(options.Workers(), options.Law())
+	for i, s := range u.Stars {
+		if s.Pinned {
+			accelerations[i] = nbody.OrderedPair{}
+			continue
+		}
+		for _, potential := range options.potentials {
+			a := potential.Acceleration(s.Position, u.Time)
+			accelerations[i].X += a.X
+			accelerations[i].Y += a.Y
+		}
+		friction := FrictionAcceleration(s, u.Stars, options.Friction)
+		accelerations[i].X += friction.X
+		accelerations[i].Y += friction.Y
+	}
+	return accelerations
+}
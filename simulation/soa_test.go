@@ -0,0 +1,137 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the structure-of-arrays layout in soa.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type StarArraysTestCases struct {
+	id           string
+	numStars     int
+	softening    float64
+	ownSoftening float64 // softening length of every second star, 0 for none
+	numTracers   int
+	numPinned    int
+	centralMass  float64
+}
+
+// ReadStarArrays reads test data for the structure-of-arrays layout from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of StarArraysTestCases structs containing the universes.
+func ReadStarArrays(fileName string) []StarArraysTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []StarArraysTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+		softenings, stars := strings.Fields(parts[2]), strings.Fields(parts[3])
+		if len(softenings) != 2 || len(stars) != 2 {
+			continue
+		}
+
+		test := StarArraysTestCases{id: strings.TrimSpace(parts[0])}
+		test.numStars, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		test.softening, err = strconv.ParseFloat(softenings[0], 64)
+		nbody.Check(err)
+		test.ownSoftening, err = strconv.ParseFloat(softenings[1], 64)
+		nbody.Check(err)
+		test.numTracers, err = strconv.Atoi(stars[0])
+		nbody.Check(err)
+		test.numPinned, err = strconv.Atoi(stars[1])
+		nbody.Check(err)
+		test.centralMass, err = strconv.ParseFloat(strings.TrimSpace(parts[4]), 64)
+		nbody.Check(err)
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+// TestStarArrays tests that the accelerations of -layout soa match those of direct summation over the stars,
+// with per-star softening, tracers, pinned stars, and a central mass, and that DirectAccelerations uses the layout.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestStarArrays(t *testing.T) {
+	tests := ReadStarArrays("../Tests/StarArrays.txt")
+	for _, test := range tests {
+		nbody.SetDeterministic()
+		g := nbody.InitializeGalaxy(test.numStars-1, 4e21, 5e22, 5e22)
+		u := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+		for i, s := range u.Stars {
+			switch {
+			case i < test.numTracers:
+				s.Mass = 0
+			case i < test.numTracers+test.numPinned:
+				s.Pinned = true
+			}
+			if i%2 == 1 {
+				s.Softening = test.ownSoftening
+			}
+		}
+		options := DefaultOptions(1, 2e14, 0.5).WithHalos(CentralMass(u.Center(), test.centralMass, 0)...).WithForceMode("direct")
+		options.Softening = test.softening
+
+		expected, err := ComputeAccelerations(u, nil, options)
+		nbody.Check(err)
+		expectedDirect := DirectAccelerations(u, options)
+		arrays := options.WithLayout("soa")
+		result, err := ComputeAccelerations(u, nil, arrays)
+		nbody.Check(err)
+		resultDirect := DirectAccelerations(u, arrays)
+
+		for i := range u.Stars {
+			for _, pair := range [][2]nbody.OrderedPair{{result[i], expected[i]}, {resultDirect[i], expectedDirect[i]}} {
+				got, want := pair[0], pair[1]
+				scale := math.Hypot(want.X, want.Y)
+				if math.Hypot(got.X-want.X, got.Y-want.Y) > 1e-12*scale {
+					t.Errorf("TestStarArrays(test %v, star %d) = %v, want %v", test.id, i, got, want)
+				}
+			}
+			if s := NewStarArrays(u.Stars, test.softening).Star(i); s.Position != u.Stars[i].Position || s.Velocity != u.Stars[i].Velocity || s.Mass != u.Stars[i].Mass {
+				t.Errorf("TestStarArrays(test %v): star %d of the arrays = %v, want %v", test.id, i, s, *u.Stars[i])
+			}
+		}
+	}
+}
+
+// BenchmarkDirectAccelerations times direct summation over the stars (aos) and over StarArrays (soa) for
+// galaxies of 1e2 to 1e4 stars.
+func BenchmarkDirectAccelerations(b *testing.B) {
+	for _, n := range nbody.BenchmarkSizes[:3] {
+		for _, name := range []string{"aos", "soa"} {
+			b.Run(fmt.Sprintf("%s/N=%d", name, n), func(b *testing.B) {
+				u := nbody.BenchmarkUniverse(n)
+				options := DefaultOptions(1, 2e14, 0).WithLayout(name)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					DirectAccelerations(u, options)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Adaptive per-star softening lengths from the distance to the k-th nearest neighbor.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"math"
+)
+
+// KthNeighborDistance returns the distance from a star to its k-th nearest neighbor in a quadtree.
+// Subtrees whose sector is farther away than the k-th nearest star found so far are skipped.
+// Input:
+//   - node: pointer to the root Node of the tree.
+//   - s: pointer to the Star; it is not its own neighbor.
+//   - k: number of neighbors (at least 1).
+// Output:
+//   - the distance to the k-th nearest star, to the farthest star if the tree holds fewer, or 0 if it holds none.
+func KthNeighborDistance(node *quadtree.Node, s *nbody.Star, k int) float64 {
+	// best holds the distances of the nearest stars found so far, in increasing order
+	best := make([]float64, 0, k)
+
+	var walk func(node *quadtree.Node)
+	walk = func(node *quadtree.Node) {
+		if node == nil {
+			return
+		}
+
+		if quadtree.IsLeaf(node) {
+			for _, other := range quadtree.LeafStars(node) {
+				if other == s {
+					continue
+				}
+				_, _, d := nbody.Distance(other.Position, s.Position)
+				if len(best) == k {
+					if d >= best[k-1] {
+						continue
+					}
+					best = best[:k-1]
+				}
+				// insert d keeping best sorted
+				i := len(best)
+				best = append(best, d)
+				for i > 0 && best[i-1] > d {
+					best[i] = best[i-1]
+					i--
+				}
+				best[i] = d
+			}
+			return
+		}
+
+		if len(best) == k && SectorDistance(node.Sector, s.Position) >= best[k-1] {
+			return
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+
+	walk(node)
+
+	if len(best) == 0 {
+		return 0
+	}
+	return best[len(best)-1]
+}
+
+
+// SectorDistance returns the distance from a point to the closest point of a sector (0 inside it).
+func SectorDistance(q quadtree.Quadrant, p nbody.OrderedPair) float64 {
+	dx := math.Max(math.Max(q.X-p.X, p.X-(q.X+q.Width)), 0)
+	dy := math.Max(math.Max(q.Y-p.Y, p.Y-(q.Y+q.Width)), 0)
+	return math.Sqrt(dx*dx + dy*dy)
+}
+
+
+// SetAdaptiveSoftening sets the softening length of every star of a universe to the distance to its
+// k-th nearest neighbor, but at least floor. Stars in dense cores get a small softening, so their
+// structure is resolved, and isolated stars a large one, so a single close neighbor does not kick them.
+// The stars are handled on the given number of goroutines.
+// Input:
+//   - u: pointer to the Universe.
+//   - tree: pointer to the QuadTree of u.
+//   - k: number of neighbors.
+//   - floor: smallest softening length (the softening of the run).
+//   - workers: number of goroutines.
+// Output:
+//   - None (modifies the stars in place).
+func SetAdaptiveSoftening(u *nbody.Universe, tree *quadtree.QuadTree, k int, floor float64, workers int) {
+	stars := u.Stars
+	numChunks := (len(stars) + forceChunk - 1) / forceChunk
+	root := tree.Root()
+
+	nbody.ParallelFor(numChunks, workers, func(c int) {
+		for i := c * forceChunk; i < (c+1)*forceChunk && i < len(stars); i++ {
+			stars[i].Softening = math.Max(KthNeighborDistance(root, stars[i], k), floor)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the adaptive softening in softening.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"bufio"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type KthNeighborDistanceTestCases struct {
+	id       string
+	universe *nbody.Universe
+	k        int
+	expected []float64
+}
+
+
+// ReadKthNeighborDistance reads test data for the KthNeighborDistance function from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of KthNeighborDistanceTestCases structs containing the universe, k, and the expected distances.
+func ReadKthNeighborDistance(fileName string) []KthNeighborDistanceTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []KthNeighborDistanceTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+
+		width, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		nbody.Check(err)
+		k, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+		nbody.Check(err)
+
+		u := &nbody.Universe{Width: width}
+		for _, point := range strings.Split(parts[3], ",") {
+			fields := strings.Fields(point)
+			x, err := strconv.ParseFloat(fields[0], 64)
+			nbody.Check(err)
+			y, err := strconv.ParseFloat(fields[1], 64)
+			nbody.Check(err)
+			u.Stars = append(u.Stars, &nbody.Star{Position: nbody.OrderedPair{X: x, Y: y}, Mass: 1})
+		}
+
+		var expected []float64
+		for _, field := range strings.Fields(parts[4]) {
+			d, err := strconv.ParseFloat(field, 64)
+			nbody.Check(err)
+			expected = append(expected, d)
+		}
+
+		tests = append(tests, KthNeighborDistanceTestCases{
+			id:       strings.TrimSpace(parts[0]),
+			universe: u,
+			k:        k,
+			expected: expected,
+		})
+	}
+
+	return tests
+}
+
+
+// TestKthNeighborDistance tests the KthNeighborDistance function using data from a file.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestKthNeighborDistance(t *testing.T) {
+	tests := ReadKthNeighborDistance("../Tests/KthNeighborDistance.txt")
+
+	for _, test := range tests {
+		tree := quadtree.GenerateQuadTree(test.universe, 1)
+
+		for i, s := range test.universe.Stars {
+			d := KthNeighborDistance(tree.Root(), s, test.k)
+			if math.Abs(d-test.expected[i]) > 1e-5 {
+				t.Errorf("TestKthNeighborDistance(test %v, star %d) = %v, want %v", test.id, i, d, test.expected[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Time steps chosen from the accelerations of the stars (options "-adaptive-dt" and "-block-levels").
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"math"
+)
+
+// AdaptiveTimeStep chooses the time step of one generation with the Courant-like condition
+// dt = eta * sqrt(eps / a) of the star that needs the smallest step, where a is the star's acceleration and
+// eps its softening length (its own length with adaptive softening, the run's softening otherwise).
+// A star travels about eps in that time when starting at rest, so close encounters, where the accelerations
+// are large, are integrated with small steps and quiet phases with large ones.
+// Input:
+//   - u: pointer to the Universe.
+//   - accelerations: acceleration of every star, in the order of u.Stars.
+//   - options: the Options of the run; adaptiveStep is eta and timeStep the largest step.
+// Output:
+//   - the time step, at most options.TimeStep.
+func AdaptiveTimeStep(u *nbody.Universe, accelerations []nbody.OrderedPair, options Options) float64 {
+	dt := options.TimeStep
+
+	for i, s := range u.Stars {
+		a := math.Hypot(accelerations[i].X, accelerations[i].Y)
+		eps := s.Softening
+		if eps == 0 {
+			eps = options.Softening
+		}
+		if a == 0 || eps == 0 {
+			continue
+		}
+		dt = math.Min(dt, options.AdaptiveStep*math.Sqrt(eps/a))
+	}
+
+	return dt
+}
+
+
+// BlockLevel returns the level of a star for block time steps: the smallest k between 0 and maxLevel for
+// which the step timeStep / 2^k is at most the star's own step eta * sqrt(eps / a) (see AdaptiveTimeStep).
+// Stars without acceleration or softening length stay at level 0.
+func BlockLevel(s *nbody.Star, acceleration nbody.OrderedPair, options Options, maxLevel int) int {
+	a := math.Hypot(acceleration.X, acceleration.Y)
+	eps := s.Softening
+	if eps == 0 {
+		eps = options.Softening
+	}
+	if a == 0 || eps == 0 {
+		return 0
+	}
+
+	own := options.AdaptiveStep * math.Sqrt(eps/a)
+	level := int(math.Ceil(math.Log2(options.TimeStep / own)))
+	return min(max(level, 0), maxLevel)
+}
+
+
+// BlockStep advances a copy of the universe by options.TimeStep with hierarchical block time steps.
+// Every star gets a level k from BlockLevel and is integrated with the step timeStep / 2^k, so stars in
+// dense regions take many small steps while stars in the outskirts take one. The step is split into
+// 2^blockLevels ticks of the smallest step: all stars drift every tick, but the forces (a tree walk) are
+// only computed for the stars whose step ends, which kick their velocities (kick-drift-kick leapfrog).
+// The tree is rebuilt every tick from the drifted positions. The levels are chosen again every step,
+// when all stars are synchronized.
+// Input:
+//   - currentUniverse: pointer to the current Universe.
+//   - tree: pointer to the QuadTree of currentUniverse.
+//   - options: the Options of the run (time step, theta, softening, adaptiveStep as eta, blockLevels, boundary).
+// Output:
+//   - Pointer to the updated Universe, or an error if a force worker failed.
+func BlockStep(currentUniverse *nbody.Universe, tree *quadtree.QuadTree, options Options) (*nbody.Universe, error) {
+	u := nbody.CopyUniverse(currentUniverse)
+	stars := u.Stars
+	width, height := u.Bounds()
+
+	accelerations, err := ComputeAccelerations(u, tree, options)
+	if err != nil {
+		return nil, err
+	}
+
+	levels := make([]int, len(stars))
+	for i, s := range stars {
+		levels[i] = BlockLevel(s, accelerations[i], options, options.BlockLevels)
+	}
+
+	ticks := 1 << options.BlockLevels
+	h := options.TimeStep / float64(ticks)
+
+	for n := 0; n < ticks; n++ {
+		// the stars whose step starts at this tick kick by half their step with their current acceleration
+		for i, s := range stars {
+			period := ticks >> levels[i]
+			if n%period == 0 {
+				dt := h * float64(period)
+				s.Velocity.X += 0.5 * accelerations[i].X * dt
+				s.Velocity.Y += 0.5 * accelerations[i].Y * dt
+			}
+		}
+
+		// every star drifts, except the pinned ones
+		for _, s := range stars {
+			if s.Pinned {
+				continue
+			}
+			s.Position.X += s.Velocity.X * h
+			s.Position.Y += s.Velocity.Y * h
+			if options.Boundary == "reflect" {
+				ReflectStar(s, width, height)
+			}
+		}
+
+		// the stars whose step ends after this tick get a new acceleration and kick by the other half
+		var active []int
+		for i := range stars {
+			if (n+1)%(ticks>>levels[i]) == 0 {
+				active = append(active, i)
+			}
+		}
+		// the external potentials pull the drifted stars at the time of the tick
+		u.Time = currentUniverse.Time + h*float64(n+1)
+		tickTree := quadtree.BuildQuadTree(u, options.Build())
+		err := nbody.ParallelForErr((len(active)+forceChunk-1)/forceChunk, options.Workers(), func(c int) error {
+			for k := c * forceChunk; k < (c+1)*forceChunk && k < len(active); k++ {
+				i := active[k]
+				accelerations[i] = StarAcceleration(stars[i], u, tickTree, options)
+			}
+			return nil
+		})
+		if err == nil {
+			err = GuardAccelerations(u, accelerations, options)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range active {
+			dt := h * float64(ticks>>levels[i])
+			stars[i].Velocity.X += 0.5 * accelerations[i].X * dt
+			stars[i].Velocity.Y += 0.5 * accelerations[i].Y * dt
+		}
+	}
+
+	for i, s := range stars {
+		s.Acceleration = accelerations[i]
+		s.Age += options.TimeStep
+	}
+	u.Time = currentUniverse.Time + options.TimeStep
+
+	return u, nil
+}
@@ -0,0 +1,174 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the adaptive and block time steps in timestep.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type AdaptiveTimeStepTestCases struct {
+	id            string
+	options       Options
+	universe      *nbody.Universe
+	accelerations []nbody.OrderedPair
+	expected      float64
+}
+
+
+// ReadAdaptiveTimeStep reads test data for the AdaptiveTimeStep function from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of AdaptiveTimeStepTestCases structs containing the options, the stars, and the expected step.
+func ReadAdaptiveTimeStep(fileName string) []AdaptiveTimeStepTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []AdaptiveTimeStepTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 6 {
+			continue
+		}
+
+		var nums [4]float64
+		for i, part := range []string{parts[1], parts[2], parts[3], parts[5]} {
+			nums[i], err = strconv.ParseFloat(strings.TrimSpace(part), 64)
+			nbody.Check(err)
+		}
+
+		options := DefaultOptions(1, nums[1], 0.5)
+		options.Softening = nums[0]
+		options.AdaptiveStep = nums[2]
+
+		test := AdaptiveTimeStepTestCases{
+			id:       strings.TrimSpace(parts[0]),
+			options:  options,
+			universe: &nbody.Universe{Width: 1},
+			expected: nums[3],
+		}
+		for _, star := range strings.Split(parts[4], ";") {
+			var v [3]float64
+			for i, field := range strings.Split(star, ",") {
+				v[i], err = strconv.ParseFloat(strings.TrimSpace(field), 64)
+				nbody.Check(err)
+			}
+			test.universe.Stars = append(test.universe.Stars, &nbody.Star{Mass: 1, Softening: v[0]})
+			test.accelerations = append(test.accelerations, nbody.OrderedPair{X: v[1], Y: v[2]})
+		}
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestAdaptiveTimeStep tests the AdaptiveTimeStep function using data from a file.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestAdaptiveTimeStep(t *testing.T) {
+	tests := ReadAdaptiveTimeStep("../Tests/AdaptiveTimeStep.txt")
+
+	for _, test := range tests {
+		result := AdaptiveTimeStep(test.universe, test.accelerations, test.options)
+		if math.Abs(result-test.expected) > 1e-6*test.expected {
+			t.Errorf("TestAdaptiveTimeStep(test %v) = %v, want %v", test.id, result, test.expected)
+		}
+	}
+}
+
+
+type BlockLevelTestCases struct {
+	id           string
+	options      Options
+	maxLevel     int
+	star         *nbody.Star
+	acceleration nbody.OrderedPair
+	expected     int
+}
+
+
+// ReadBlockLevel reads test data for the BlockLevel function from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of BlockLevelTestCases structs containing the options, the star, and the expected level.
+func ReadBlockLevel(fileName string) []BlockLevelTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []BlockLevelTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 7 {
+			continue
+		}
+
+		var nums [3]float64
+		for i, part := range parts[1:4] {
+			nums[i], err = strconv.ParseFloat(strings.TrimSpace(part), 64)
+			nbody.Check(err)
+		}
+		maxLevel, err := strconv.Atoi(strings.TrimSpace(parts[4]))
+		nbody.Check(err)
+		var v [3]float64
+		for i, field := range strings.Split(parts[5], ",") {
+			v[i], err = strconv.ParseFloat(strings.TrimSpace(field), 64)
+			nbody.Check(err)
+		}
+		expected, err := strconv.Atoi(strings.TrimSpace(parts[6]))
+		nbody.Check(err)
+
+		options := DefaultOptions(1, nums[1], 0.5)
+		options.Softening = nums[0]
+		options.AdaptiveStep = nums[2]
+
+		tests = append(tests, BlockLevelTestCases{
+			id:           strings.TrimSpace(parts[0]),
+			options:      options,
+			maxLevel:     maxLevel,
+			star:         &nbody.Star{Mass: 1, Softening: v[0]},
+			acceleration: nbody.OrderedPair{X: v[1], Y: v[2]},
+			expected:     expected,
+		})
+	}
+
+	return tests
+}
+
+
+// TestBlockLevel tests the BlockLevel function using data from a file.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestBlockLevel(t *testing.T) {
+	tests := ReadBlockLevel("../Tests/BlockLevel.txt")
+
+	for _, test := range tests {
+		result := BlockLevel(test.star, test.acceleration, test.options, test.maxLevel)
+		if result != test.expected {
+			t.Errorf("TestBlockLevel(test %v) = %v, want %v", test.id, result, test.expected)
+		}
+	}
+}
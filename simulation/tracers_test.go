@@ -0,0 +1,134 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the massless tracers in nbody/tracers.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"bufio"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type TracersTestCases struct {
+	id         string
+	treeBuild  string
+	force      string
+	theta      float64
+	numStars   int
+	numTracers int
+	numGens    int
+	tolerance  float64 // largest relative error of the acceleration of a tracer against direct summation
+}
+
+
+// ReadTracers reads test data for tracers from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of TracersTestCases structs containing the runs and the tolerance of the tracer accelerations.
+func ReadTracers(fileName string) []TracersTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []TracersTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+
+		settings := strings.Fields(parts[1])
+		counts := strings.Fields(parts[2])
+		if len(settings) != 3 || len(counts) != 3 {
+			continue
+		}
+
+		test := TracersTestCases{id: strings.TrimSpace(parts[0]), treeBuild: settings[0], force: settings[1]}
+		test.theta, err = strconv.ParseFloat(settings[2], 64)
+		nbody.Check(err)
+		var nums [3]int
+		for i, field := range counts {
+			nums[i], err = strconv.Atoi(field)
+			nbody.Check(err)
+		}
+		test.numStars, test.numTracers, test.numGens = nums[0], nums[1], nums[2]
+		test.tolerance, err = strconv.ParseFloat(strings.TrimSpace(parts[3]), 64)
+		nbody.Check(err)
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TracerRun simulates a deterministic galaxy with numTracers tracers and returns the last generation.
+func TracerRun(test TracersTestCases, numTracers int) *nbody.Universe {
+	nbody.SetDeterministic()
+	g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+	g = append(g, nbody.GalaxyTracers(numTracers, 4e21, g[len(g)-1])...)
+	timePoints := BarnesHut(nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23), TracerOptions(test))
+	return timePoints[test.numGens]
+}
+
+
+// TracerOptions returns the Options of the runs of a test, with its tree build and force mode.
+func TracerOptions(test TracersTestCases) Options {
+	return DefaultOptions(test.numGens, 2e14, test.theta).WithTreeBuild(test.treeBuild).WithForceMode(test.force)
+}
+
+
+// TestTracers tests that tracers do not change the motion of the stars with every tree build and force mode,
+// that they add no mass to the tree, and that they are pulled like stars.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestTracers(t *testing.T) {
+	tests := ReadTracers("../Tests/Tracers.txt")
+	for _, test := range tests {
+		want := TracerRun(test, 0)
+		got := TracerRun(test, test.numTracers)
+
+		if len(got.Stars) != len(want.Stars)+test.numTracers {
+			t.Errorf("TestTracers(test %v) has %d stars, want %d", test.id, len(got.Stars), len(want.Stars)+test.numTracers)
+			continue
+		}
+		for i := range want.Stars {
+			if got.Stars[i].Position != want.Stars[i].Position || got.Stars[i].Velocity != want.Stars[i].Velocity {
+				t.Errorf("TestTracers(test %v): star %d moves differently with tracers", test.id, i)
+				break
+			}
+		}
+
+		options := TracerOptions(test)
+		tree := quadtree.BuildQuadTree(got, options.Build())
+		if mass, wantMass := tree.Root().Star.Mass, quadtree.BuildQuadTree(want, options.Build()).Root().Star.Mass; mass != wantMass {
+			t.Errorf("TestTracers(test %v) tree mass = %v, want %v", test.id, mass, wantMass)
+		}
+
+		for _, s := range got.Stars[len(want.Stars):] {
+			if !nbody.IsTracer(s) {
+				t.Errorf("TestTracers(test %v): tracer has mass %v", test.id, s.Mass)
+				break
+			}
+			a := StarAcceleration(s, got, tree, options)
+			direct := DirectAcceleration(s, got.Stars, 0, nbody.Newtonian{})
+			if err := math.Hypot(a.X-direct.X, a.Y-direct.Y) / math.Hypot(direct.X, direct.Y); !(err <= test.tolerance) {
+				t.Errorf("TestTracers(test %v) tracer acceleration %v, want %v (relative error %v)", test.id, a, direct, err)
+				break
+			}
+		}
+	}
+}
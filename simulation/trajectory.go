@@ -0,0 +1,132 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: The histories of stars written to a CSV file while a simulation runs (options "-trajectories",
+// "-trajectory-stars", "-trajectory-galaxy", and "-trajectory-region").
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// A trajectory file has one line per recorded generation and star, in SI units:
+//
+//	star,generation,t,x,y,vx,vy
+//	0,0,0,2.5e+22,2.5e+22,0,0
+//	1,0,0,2.61e+22,2.5e+22,0,152000
+//
+// Stars are numbered by their ID (see Star.ID), so every star keeps its number for the whole run, also when stars
+// are removed, merged, or added, and the lines of a star can be picked out by its number.
+
+// trajectoryHeader is the first line of a trajectory file.
+const trajectoryHeader = "star,generation,t,x,y,vx,vy"
+
+// TrajectoryWriter writes the position and velocity of some or all stars of a run to a CSV file, every few
+// generations, as the run goes, so the histories of all stars of a large run need not fit in memory.
+type TrajectoryWriter struct {
+	w        *bufio.Writer
+	selected nbody.StarFilter // the written stars, or nil for all
+	interval int
+	numGens  int
+	written  bool  // whether a generation was written, so the first one always is
+	err      error // the first write error; later generations are dropped
+}
+
+
+// NewTrajectoryWriter returns a TrajectoryWriter writing the first generation, every interval-th generation, and
+// the last one, and writes the header of the file.
+// Input:
+//   - w: destination of the file.
+//   - ids: IDs of the stars to write (see TrajectoryStars), or nil for all.
+//   - interval: generations between two written ones.
+//   - numGens: last generation of the run.
+// Output:
+//   - pointer to the TrajectoryWriter, whose Hook goes into the Options of the run.
+func NewTrajectoryWriter(w io.Writer, ids []int64, interval, numGens int) *TrajectoryWriter {
+	tw := &TrajectoryWriter{w: bufio.NewWriter(w), interval: interval, numGens: numGens}
+	if ids != nil {
+		tw.selected = nbody.WithIDs(ids...)
+	}
+	_, tw.err = fmt.Fprintln(tw.w, trajectoryHeader)
+	return tw
+}
+
+
+// Hook returns the hook writing the selected generations (see Options.WithHook), with the selected stars in the
+// order of the universe. Stars that are no longer in the universe are skipped.
+func (tw *TrajectoryWriter) Hook() func(generation int, u *nbody.Universe) {
+	return func(generation int, u *nbody.Universe) {
+		if tw.err != nil || (tw.written && generation%tw.interval != 0 && generation != tw.numGens) {
+			return
+		}
+		tw.written = true
+
+		for _, s := range u.Stars {
+			if tw.selected != nil && !tw.selected(s) {
+				continue
+			}
+			_, err := fmt.Fprintf(tw.w, "%d,%d,%v,%v,%v,%v,%v\n", s.ID, generation, u.Time,
+				s.Position.X, s.Position.Y, s.Velocity.X, s.Velocity.Y)
+			if tw.err == nil {
+				tw.err = err
+			}
+		}
+	}
+}
+
+
+// Close writes out what is buffered; it does not close the destination.
+// Output:
+//   - the first error of writing the file, if any.
+func (tw *TrajectoryWriter) Close() error {
+	if err := tw.w.Flush(); tw.err == nil {
+		tw.err = err
+	}
+	return tw.err
+}
+
+
+// TrajectoryStars returns the IDs of the stars of the initial universe selected by -trajectory-stars,
+// -trajectory-galaxy, and -trajectory-region; a star must match all of the options given.
+// Input:
+//   - u: pointer to the initial Universe.
+//   - stars: comma-separated IDs of stars (see ParseStarList), or "" for any star.
+//   - galaxy: galaxy whose stars are selected (see Star.Galaxy), from 0 for the first, or -1 for any galaxy.
+//   - region: corners of the rectangle where the stars are (see ParseRegion), or "" for anywhere.
+// Output:
+//   - the IDs, or nil for all stars if no option is given, and an error if an option is invalid or no star matches.
+func TrajectoryStars(u *nbody.Universe, stars string, galaxy int, region string) ([]int64, error) {
+	var filters []nbody.StarFilter
+	if stars != "" {
+		ids, err := nbody.ParseStarList(stars, u)
+		if err != nil {
+			return nil, fmt.Errorf("-trajectory-stars: %w", err)
+		}
+		filters = append(filters, nbody.WithIDs(ids...))
+	}
+	if galaxy >= 0 {
+		if galaxy >= u.NumGalaxies() {
+			return nil, fmt.Errorf("-trajectory-galaxy %d: the universe has %d tagged galaxies", galaxy, u.NumGalaxies())
+		}
+		filters = append(filters, nbody.InGalaxy(int32(galaxy+1)))
+	}
+	if region != "" {
+		corner0, corner1, err := nbody.ParseRegion(region)
+		if err != nil {
+			return nil, fmt.Errorf("-trajectory-region: %w", err)
+		}
+		filters = append(filters, nbody.InRegion(corner0, corner1))
+	}
+	if len(filters) == 0 {
+		return nil, nil
+	}
+
+	ids := u.SelectIDs(nbody.AllOf(filters...))
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no star of the universe matches -trajectory-stars, -trajectory-galaxy, and -trajectory-region")
+	}
+	return ids, nil
+}
@@ -0,0 +1,147 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the trajectory files in trajectory.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type TrajectoryWriterTestCases struct {
+	id          string
+	numGens     int
+	interval    int
+	stars       string
+	galaxy      int
+	region      string
+	generations []int
+	written     []int64 // IDs of the written stars, or nil for all
+	hasError    bool
+}
+
+
+// ReadTrajectoryWriter reads test data for TrajectoryWriter from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of TrajectoryWriterTestCases structs containing the run, the selection, and the expected lines.
+func ReadTrajectoryWriter(fileName string) []TrajectoryWriterTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []TrajectoryWriterTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 6 {
+			continue
+		}
+
+		test := TrajectoryWriterTestCases{id: strings.TrimSpace(parts[0])}
+		test.numGens, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		test.interval, err = strconv.Atoi(strings.TrimSpace(parts[2]))
+		nbody.Check(err)
+		selection := strings.Fields(parts[3])
+		test.stars, test.region = selection[0], selection[2]
+		test.galaxy, err = strconv.Atoi(selection[1])
+		nbody.Check(err)
+		if test.stars == "all" {
+			test.stars = ""
+		}
+		if test.region == "-" {
+			test.region = ""
+		}
+		if strings.TrimSpace(parts[4]) == "error" {
+			test.hasError = true
+			tests = append(tests, test)
+			continue
+		}
+
+		for _, g := range strings.Split(parts[4], ",") {
+			generation, err := strconv.Atoi(strings.TrimSpace(g))
+			nbody.Check(err)
+			test.generations = append(test.generations, generation)
+		}
+		if strings.TrimSpace(parts[5]) != "all" {
+			for _, field := range strings.Split(parts[5], ",") {
+				id, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+				nbody.Check(err)
+				test.written = append(test.written, id)
+			}
+		}
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+
+// TestTrajectoryWriter tests that a run writes the lines of the expected generations and stars, with the positions
+// and velocities of the stars, and that invalid selections are rejected.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestTrajectoryWriter(t *testing.T) {
+	tests := ReadTrajectoryWriter("../Tests/TrajectoryWriter.txt")
+
+	for _, test := range tests {
+		g0 := nbody.InitializeGalaxy(10, 4e21, 2.5e22, 5e22)
+		g1 := nbody.InitializeGalaxy(10, 4e21, 7.5e22, 5e22)
+		u := nbody.InitializeUniverse([]nbody.Galaxy{g0, g1}, 1e23)
+		ids, err := TrajectoryStars(u, test.stars, test.galaxy, test.region)
+		if test.hasError {
+			if err == nil {
+				t.Errorf("TestTrajectoryWriter(test %v) selection %q %d %q gave no error", test.id, test.stars, test.galaxy, test.region)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("TestTrajectoryWriter(test %v) selection %q %d %q: %v", test.id, test.stars, test.galaxy, test.region, err)
+			continue
+		}
+
+		// the expected lines, from the universes the writer is given
+		var buf bytes.Buffer
+		writer := NewTrajectoryWriter(&buf, ids, test.interval, test.numGens)
+		expected := []string{trajectoryHeader}
+		recorded := map[int]bool{}
+		for _, generation := range test.generations {
+			recorded[generation] = true
+		}
+		record := func(generation int, u *nbody.Universe) {
+			if !recorded[generation] {
+				return
+			}
+			for _, s := range u.Stars {
+				if test.written == nil || nbody.WithIDs(test.written...)(s) {
+					expected = append(expected, fmt.Sprintf("%d,%d,%v,%v,%v,%v,%v", s.ID, generation, u.Time,
+						s.Position.X, s.Position.Y, s.Velocity.X, s.Velocity.Y))
+				}
+			}
+		}
+
+		options := DefaultOptions(test.numGens, 2e14, 0.5)
+		nbody.Check(RunGenerations(u, options.WithHook(writer.Hook()).WithHook(record)))
+		nbody.Check(writer.Close())
+
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		if !reflect.DeepEqual(lines, expected) {
+			t.Errorf("TestTrajectoryWriter(test %v) wrote\n%s\nwant\n%s", test.id, strings.Join(lines, "\n"), strings.Join(expected, "\n"))
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the quadtree build strategies in quadtree/tree_build.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// SameTree reports whether two subtrees have the same shape, sectors, (dummy) stars, and buckets.
+func SameTree(a, b *quadtree.Node) bool {
+	if a.Sector != b.Sector || len(a.Children) != len(b.Children) || !reflect.DeepEqual(a.Bucket, b.Bucket) {
+		return false
+	}
+	if (a.Star == nil) != (b.Star == nil) {
+		return false
+	}
+	if a.Star != nil && (a.Star.Position != b.Star.Position || a.Star.Mass != b.Star.Mass) {
+		return false
+	}
+	for i := range a.Children {
+		if !SameTree(a.Children[i], b.Children[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+
+type LeafSizeTestCases struct {
+	id       string
+	leafSize int
+	universe *nbody.Universe
+	numNodes int
+}
+
+
+// ReadLeafSize reads test data for trees with several stars per leaf from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of LeafSizeTestCases structs containing the leaf size, the universe, and the expected number of nodes.
+func ReadLeafSize(fileName string) []LeafSizeTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []LeafSizeTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+
+		size, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		width, err := strconv.ParseFloat(strings.TrimSpace(parts[2]), 64)
+		nbody.Check(err)
+		numNodes, err := strconv.Atoi(strings.TrimSpace(parts[4]))
+		nbody.Check(err)
+
+		u := &nbody.Universe{Width: width}
+		for _, star := range strings.Split(parts[3], ",") {
+			fields := strings.Fields(star)
+			var nums [3]float64
+			for i := range nums {
+				nums[i], err = strconv.ParseFloat(fields[i], 64)
+				nbody.Check(err)
+			}
+			u.Stars = append(u.Stars, &nbody.Star{Position: nbody.OrderedPair{X: nums[0], Y: nums[1]}, Mass: nums[2]})
+		}
+
+		tests = append(tests, LeafSizeTestCases{
+			id:       strings.TrimSpace(parts[0]),
+			leafSize: size,
+			universe: u,
+			numNodes: numNodes,
+		})
+	}
+
+	return tests
+}
+
+
+// TestLeafSize checks the trees built with several stars per leaf and the forces computed from them.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if a tree or a force differs.
+func TestLeafSize(t *testing.T) {
+	tests := ReadLeafSize("../Tests/LeafSize.txt")
+
+	for _, test := range tests {
+		want := quadtree.GenerateQuadTree(test.universe, test.leafSize)
+		if n := quadtree.CountNodes(want.Root()); n != test.numNodes {
+			t.Errorf("TestLeafSize(test %v) built %d nodes, want %d", test.id, n, test.numNodes)
+		}
+
+		for _, workers := range []int{1, 4} {
+			if !SameTree(quadtree.GenerateQuadTreePartitioned(test.universe, workers, test.leafSize).Root(), want.Root()) {
+				t.Errorf("TestLeafSize(test %v, %d workers): partitioned build does not match GenerateQuadTree", test.id, workers)
+			}
+			if !SameTree(quadtree.GenerateFlatTree(test.universe, workers, test.leafSize).QuadTree().Root(), want.Root()) {
+				t.Errorf("TestLeafSize(test %v, %d workers): flat build does not match GenerateQuadTree", test.id, workers)
+			}
+		}
+
+		options := DefaultOptions(1, 1, 0).WithLeafSize(test.leafSize)
+		expected := DirectAccelerations(test.universe, options)
+		for _, tree := range []*quadtree.QuadTree{want, {Linear: quadtree.GenerateLinearTree(test.universe, test.leafSize)}} {
+			result, err := ComputeAccelerations(test.universe, tree, options)
+			nbody.Check(err)
+			for i := range result {
+				scale := math.Hypot(expected[i].X, expected[i].Y)
+				if math.Hypot(result[i].X-expected[i].X, result[i].Y-expected[i].Y) > 1e-9*scale {
+					t.Errorf("TestLeafSize(test %v, linear %v, star %d) = %v, want %v", test.id, tree.Linear != nil, i, result[i], expected[i])
+				}
+			}
+		}
+	}
+}
+
+
+// BenchmarkTreeBuild times a generation of UpdateUniverse, building and walking the tree, with the pointer tree of
+// the serial build and with the linear tree, for every size of nbody.BenchmarkSizes.
+// Run it with: go test -run '^$' -bench TreeBuild
+func BenchmarkTreeBuild(b *testing.B) {
+	for _, n := range nbody.BenchmarkSizes {
+		for _, build := range []string{"serial", "linear"} {
+			b.Run(fmt.Sprintf("%s/N=%d", build, n), func(b *testing.B) {
+				u := nbody.BenchmarkUniverse(n)
+				options := DefaultOptions(1, 2e14, 0.5).WithTreeBuild(build)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					_, err := UpdateUniverse(u, quadtree.BuildQuadTree(u, options.Build()), options)
+					nbody.Check(err)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,191 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the reuse of the quadtree between time steps in quadtree/tree_reuse.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type RefitQuadTreeTestCases struct {
+	id          string
+	numStars    int
+	leafSize    int
+	generations int
+	timeStep    float64
+	inPlace     bool
+	removed     int
+	added       int
+}
+
+// ReadRefitQuadTree reads test data for RefitQuadTree from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of RefitQuadTreeTestCases structs containing the runs.
+func ReadRefitQuadTree(fileName string) []RefitQuadTreeTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []RefitQuadTreeTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 5 {
+			continue
+		}
+		run, changes := strings.Fields(parts[3]), strings.Fields(parts[4])
+		if len(run) != 3 || len(changes) != 2 {
+			continue
+		}
+
+		test := RefitQuadTreeTestCases{id: strings.TrimSpace(parts[0])}
+		test.numStars, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		test.leafSize, err = strconv.Atoi(strings.TrimSpace(parts[2]))
+		nbody.Check(err)
+		test.generations, err = strconv.Atoi(run[0])
+		nbody.Check(err)
+		test.timeStep, err = strconv.ParseFloat(run[1], 64)
+		nbody.Check(err)
+		test.inPlace, err = strconv.ParseBool(run[2])
+		nbody.Check(err)
+		test.removed, err = strconv.Atoi(changes[0])
+		nbody.Check(err)
+		test.added, err = strconv.Atoi(changes[1])
+		nbody.Check(err)
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+// SimilarTree reports whether two subtrees have the same shape, sectors, and stars in their leaves (in any order),
+// and the same masses and centers of mass up to a relative tolerance.
+func SimilarTree(a, b *quadtree.Node, tolerance float64) bool {
+	if a.Sector != b.Sector || len(a.Children) != len(b.Children) || (a.Star == nil) != (b.Star == nil) {
+		return false
+	}
+	if a.Star != nil {
+		_, _, d := nbody.Distance(a.Star.Position, b.Star.Position)
+		if d > tolerance*a.Sector.Width || math.Abs(a.Star.Mass-b.Star.Mass) > tolerance*b.Star.Mass {
+			return false
+		}
+	}
+	if len(a.Children) == 0 {
+		stars := make(map[*nbody.Star]int)
+		for _, s := range quadtree.LeafStars(a) {
+			stars[s]++
+		}
+		for _, s := range quadtree.LeafStars(b) {
+			stars[s]--
+		}
+		for _, n := range stars {
+			if n != 0 {
+				return false
+			}
+		}
+		return true
+	}
+	for i := range a.Children {
+		if !SimilarTree(a.Children[i], b.Children[i], tolerance) {
+			return false
+		}
+	}
+	return true
+}
+
+// TestRefitQuadTree tests that the trees refitted from the previous generation match the trees built from
+// scratch, also when stars are removed and added, and that a simulation with -tree-reuse matches one without.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestRefitQuadTree(t *testing.T) {
+	tests := ReadRefitQuadTree("../Tests/RefitQuadTree.txt")
+	for _, test := range tests {
+		nbody.SetDeterministic()
+		g := nbody.InitializeGalaxy(test.numStars, 4e21, 5e22, 5e22)
+		initial := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+		options := DefaultOptions(test.generations, test.timeStep, 0.5).WithLeafSize(test.leafSize)
+
+		u := nbody.CopyUniverse(initial)
+		tree := quadtree.BuildQuadTree(u, options.Build())
+		for gen := 1; gen <= test.generations; gen++ {
+			previous := append([]*nbody.Star(nil), u.Stars...)
+			var err error
+			if test.inPlace {
+				err = UpdateUniverseInPlace(u, tree, options)
+			} else {
+				u, err = UpdateUniverse(u, tree, options)
+			}
+			nbody.Check(err)
+			u.RemoveStars(func(i int, s *nbody.Star) bool {
+				return i < test.removed
+			})
+			if test.added > 0 {
+				u.AddStars(nbody.InitializeGalaxy(test.added, 4e21, 5e22, 5e22)...)
+			}
+
+			tree = quadtree.RefitQuadTree(tree, previous, u, options.Build())
+			if !SimilarTree(tree.Root(), quadtree.GenerateQuadTree(u, test.leafSize).Root(), 1e-12) {
+				t.Errorf("TestRefitQuadTree(test %v, generation %d): the refitted tree does not match GenerateQuadTree", test.id, gen)
+			}
+		}
+
+		// whole runs only differ by the rounding of the centers of mass
+		var results [2]*nbody.Universe
+		for k, reuse := range []bool{false, true} {
+			sim, err := NewSimulation(initial, options.WithTreeReuse(reuse))
+			nbody.Check(err)
+			nbody.Check(sim.Run(test.generations))
+			results[k] = sim.Snapshot()
+		}
+		for i, s := range results[1].Stars {
+			_, _, d := nbody.Distance(s.Position, results[0].Stars[i].Position)
+			if d > 1e-9*initial.Width {
+				t.Errorf("TestRefitQuadTree(test %v, star %d) = %v with -tree-reuse, want %v", test.id, i, s.Position, results[0].Stars[i].Position)
+			}
+		}
+	}
+}
+
+// BenchmarkRefitQuadTree times building the tree of a galaxy after one step from scratch and refitting the tree
+// of the step before, for every size of nbody.BenchmarkSizes. The refits go back and forth between the two steps.
+// Run it with: go test -run '^$' -bench RefitQuadTree
+func BenchmarkRefitQuadTree(b *testing.B) {
+	options := DefaultOptions(1, 2e14, 0.5)
+	for _, n := range nbody.BenchmarkSizes {
+		u := nbody.BenchmarkUniverse(n)
+		next, err := UpdateUniverse(u, quadtree.BuildQuadTree(u, options.Build()), options)
+		nbody.Check(err)
+
+		b.Run(fmt.Sprintf("build/N=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				quadtree.BuildQuadTree(next, options.Build())
+			}
+		})
+		b.Run(fmt.Sprintf("refit/N=%d", n), func(b *testing.B) {
+			tree := quadtree.BuildQuadTree(u, options.Build())
+			universes := [2]*nbody.Universe{u, next}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				tree = quadtree.RefitQuadTree(tree, universes[i%2].Stars, universes[(i+1)%2], options.Build())
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Author: Yu-Lun Chen
+// Date: 2026-10-16
+// Description: Testing functions for the stack-based tree walk in quadtree/tree_walk.go.
+// Each txt file in Tests/ contains input testing cases and the expected output for each case.
+
+package simulation
+
+import (
+	"BarnesHut/nbody"
+	"BarnesHut/quadtree"
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type TreeWalkTestCases struct {
+	id        string
+	numStars  int
+	leafSize  int
+	mac       string
+	theta     float64
+	softening float64
+}
+
+// ReadTreeWalk reads test data for the tree walks from a file.
+// Input: fileName (string) - path to the test data file.
+// Output: slice of TreeWalkTestCases structs containing the galaxies and the walks.
+func ReadTreeWalk(fileName string) []TreeWalkTestCases {
+	file, err := os.Open(fileName)
+	nbody.Check(err)
+	defer file.Close()
+
+	var tests []TreeWalkTestCases
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Split(line, "|")
+		if len(parts) != 4 {
+			continue
+		}
+		tree, walk := strings.Fields(parts[2]), strings.Fields(parts[3])
+		if len(tree) != 2 || len(walk) != 2 {
+			continue
+		}
+
+		test := TreeWalkTestCases{id: strings.TrimSpace(parts[0]), mac: tree[1]}
+		test.numStars, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+		nbody.Check(err)
+		test.leafSize, err = strconv.Atoi(tree[0])
+		nbody.Check(err)
+		test.theta, err = strconv.ParseFloat(walk[0], 64)
+		nbody.Check(err)
+		test.softening, err = strconv.ParseFloat(walk[1], 64)
+		nbody.Check(err)
+
+		tests = append(tests, test)
+	}
+
+	return tests
+}
+
+// TestStackNetForce tests that the stack walk gives exactly the forces of the recursive walk.
+// Input: t (*testing.T) - testing context.
+// Output: None. Reports errors via t.Errorf if results do not match expected.
+func TestStackNetForce(t *testing.T) {
+	tests := ReadTreeWalk("../Tests/TreeWalk.txt")
+	for _, test := range tests {
+		nbody.SetDeterministic()
+		g := nbody.InitializeGalaxy(test.numStars-1, 4e21, 5e22, 5e22)
+		u := nbody.InitializeUniverse([]nbody.Galaxy{g}, 1e23)
+		// coincident stars end in a bucket at quadtree.MaxTreeDepth
+		for i := 1; i < len(u.Stars) && i < 4; i++ {
+			u.Stars[i].Position = u.Stars[0].Position
+		}
+		options := DefaultOptions(1, 1, test.theta).WithSoftening(test.softening).WithLeafSize(test.leafSize).WithMAC(test.mac, 1e-12)
+		tree := quadtree.BuildQuadTree(u, options.Build())
+		walk := options.Walk()
+
+		for i, s := range u.Stars {
+			expected := quadtree.WalkNetForce(tree.Root(), s, walk, nil, 0)
+			if result := quadtree.StackNetForce(tree.Root(), s, walk); result != expected {
+				t.Errorf("TestStackNetForce(test %v, star %d) = %v, want %v", test.id, i, result, expected)
+			}
+		}
+	}
+}